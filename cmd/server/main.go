@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
@@ -12,9 +13,12 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/api"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/buildinfo"
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/config"
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
-	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/eventarchive"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/mcp"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/mdns"
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/queue"
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/sync"
@@ -42,6 +46,14 @@ func main() {
 		log.Fatal("Failed to connect to database:", err)
 	}
 
+	// Set the database up for incremental vacuum while nothing else uses it
+	start := time.Now()
+	if switched, err := db.EnableIncrementalVacuum(context.Background(), sqlDB); err != nil {
+		log.Fatal("Failed to enable incremental vacuum:", err)
+	} else if switched {
+		log.Printf("Switched database to incremental auto-vacuum in %v", time.Since(start).Round(time.Millisecond))
+	}
+
 	// Run migrations
 	log.Println("Running database migrations...")
 	if err := db.Migrate(sqlDB); err != nil {
@@ -51,27 +63,23 @@ func main() {
 
 	// Create store
 	st := store.New(sqlDB)
+	st.SetTrashRetention(cfg.TrashRetention)
 
-	// Create OpenClaw config reader
-	configReader := openclaw.NewConfigReader(cfg.OpenClawConfigPath)
-	log.Printf("Using OpenClaw config: %s", configReader.GetConfigPath())
-
-	// Create sync service
-	syncService := sync.NewSyncService(st, configReader)
-
-	// Sync on startup if enabled
-	if cfg.SyncOnStartup {
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-		
-		if err := syncService.SyncOnce(ctx); err != nil {
-			log.Printf("Warning: Initial sync failed: %v", err)
-		}
+	// Archive events outside SQLite, if enabled
+	archiveCfg := eventarchive.Config{
+		Dir:         cfg.EventArchiveDir,
+		MaxFileSize: int64(cfg.EventArchiveMaxSizeMB) << 20,
+		URL:         cfg.EventArchiveURL,
+		Token:       cfg.EventArchiveToken,
+	}
+	var archiver *eventarchive.Archiver
+	if archiveCfg.Enabled() {
+		archiver = eventarchive.New(archiveCfg)
+		st.SetEventSink(archiver.Record)
+		archiver.Start(context.Background())
 	}
 
-	// Start periodic sync
 	ctx := context.Background()
-	syncService.StartPeriodicSync(ctx, cfg.SyncInterval)
 
 	// Setup graceful shutdown
 	sigChan := make(chan os.Signal, 1)
@@ -80,6 +88,11 @@ func main() {
 	// Create and start server
 	server := api.NewServer(cfg, st)
 
+	// Create sync service, reading the OpenClaw config through the server's reader
+	configReader := server.ConfigReader()
+	log.Printf("Using OpenClaw config: %s", configReader.GetConfigPath())
+	syncService := sync.NewSyncService(st, configReader)
+
 	// Serve embedded UI
 	assets, err := ui.Assets()
 	if err != nil {
@@ -88,14 +101,115 @@ func main() {
 		server.ServeUI(assets)
 	}
 
-	// Start task queue processor (checks every 10 minutes for queued tasks)
+	// Background work (sync, queue processor, scheduler, watchdog) only runs
+	// on the leader of the instances sharing the database
+	elector := server.Leader()
+	syncService.SetLeader(elector)
+
+	// Task queue processor (checks every 10 minutes for queued tasks)
 	queueProcessor := queue.NewProcessor(st, server.AgentSender(), server.Hub(), server.TaskHandler())
-	queueProcessor.Start(ctx, 10*time.Minute)
+	queueProcessor.SetMaintenance(server.Maintenance())
+	queueProcessor.SetLeader(elector)
 
-	// Start stuck-task watchdog (re-notifies or resets tasks stuck in active states)
+	// Stuck-task watchdog (re-notifies or resets tasks stuck in active states)
 	watchdog := queue.NewWatchdog(st, server.Hub(), server.TaskHandler(), cfg.WatchdogStaleThreshold, cfg.WatchdogMaxRetries)
+	watchdog.SetMaintenance(server.Maintenance())
+	watchdog.SetLeader(elector)
+	watchdog.SetGateway(server.Gateway())
+
+	// Reported on by GET /api/v1/status
+	server.AddWorker("agent_sync", syncService)
+	server.AddWorker("queue", queueProcessor)
+	server.AddWorker("watchdog", watchdog)
+
+	// On becoming leader, at startup or when the previous leader went away,
+	// take over what was left in flight
+	elector.OnElected(func(ctx context.Context) {
+		// Finish agent removals a failure or restart left halfway
+		server.AgentHandler().ResumeDeletions(ctx)
+		// Sync on startup if enabled
+		if cfg.SyncOnStartup {
+			syncCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			if err := syncService.SyncOnce(syncCtx); err != nil {
+				log.Printf("Warning: Initial sync failed: %v", err)
+			}
+			cancel()
+		}
+		// Pick up notifications the previous process left in flight
+		server.TaskHandler().Resume(ctx)
+		// Settle active tasks whose agent lost its session in a crash or restart
+		watchdog.Reconcile(ctx)
+	})
+	elector.Start(ctx)
+
+	syncService.StartPeriodicSync(ctx, cfg.SyncInterval)
+	queueProcessor.Start(ctx, 10*time.Minute)
 	watchdog.Start(ctx, cfg.WatchdogInterval)
 
+	// The processor checks at once when it starts; a later leader does so
+	// when elected, to catch up on overdue scheduled tasks
+	elector.OnElected(queueProcessor.ProcessOnce)
+
+	// Push task status changes to linked JIRA issues, if the bridge is enabled
+	jiraSyncer := server.JiraSyncer()
+	if jiraSyncer != nil {
+		jiraSyncer.Start(ctx, cfg.JiraSyncInterval)
+	}
+
+	// Export analytics snapshots nightly, if a destination is set
+	exporter := server.AnalyticsExporter()
+	if exporter != nil {
+		exporter.Start(ctx)
+	}
+
+	// Check GitHub for newer releases, if UPDATE_CHECK_ENABLED is set
+	updates := server.UpdateChecker()
+	if updates != nil {
+		updates.Start(ctx)
+	}
+
+	// Optimize the database on a schedule, if DB_OPTIMIZE_INTERVAL is set
+	optimizer := server.DBOptimizer()
+	optimizer.Start(ctx)
+
+	// Post the daily standup, if STANDUP_ENABLED is set
+	standup := server.Standup()
+	standup.Start(ctx)
+
+	// Probe the configured models on a schedule, if MODEL_PROBE_INTERVAL is set
+	modelProber := server.ModelProber()
+	modelProber.Start(ctx)
+
+	// Take task commands from chat, if the bot is enabled
+	chatBot := server.ChatBot()
+	if chatBot != nil {
+		chatBot.Start(ctx)
+	}
+
+	// Serve MCP on its own port, if enabled
+	mcpServer := server.MCPServer()
+	if mcpServer != nil {
+		go func() {
+			addr := fmt.Sprintf("%s:%d", cfg.Host, cfg.MCPPort)
+			log.Printf("Starting MCP server on %s%s", addr, mcp.Path)
+			if err := mcpServer.Start(addr); err != nil {
+				log.Fatal("MCP server error:", err)
+			}
+		}()
+	}
+
+	// Serve the gRPC API on its own port, if enabled
+	grpcServer := server.GRPCServer()
+	if grpcServer != nil {
+		go func() {
+			addr := fmt.Sprintf("%s:%d", cfg.Host, cfg.GRPCPort)
+			log.Printf("Starting gRPC API on %s", addr)
+			if err := grpcServer.Start(addr); err != nil {
+				log.Fatal("gRPC server error:", err)
+			}
+		}()
+	}
+
 	// Start server in goroutine
 	go func() {
 		log.Printf("Starting Claw Agent Mission Control on %s:%d", cfg.Host, cfg.Port)
@@ -104,6 +218,24 @@ func main() {
 		}
 	}()
 
+	// Advertise the API on the LAN, if enabled
+	var advertiser *mdns.Advertiser
+	if cfg.MDNSEnabled {
+		scheme := "http"
+		if cfg.TLSCertFile != "" && cfg.TLSKeyFile != "" {
+			scheme = "https"
+		}
+		txt := []string{"version=" + buildinfo.Version, "path=" + cfg.BasePath + "/api/v1", "scheme=" + scheme}
+		advertiser, err = mdns.New(cfg.MDNSName, cfg.Port, txt, cfg.Host)
+		if err == nil {
+			err = advertiser.Start()
+		}
+		if err != nil {
+			log.Printf("Warning: Not advertising over mDNS: %v", err)
+			advertiser = nil
+		}
+	}
+
 	// Wait for shutdown signal
 	<-sigChan
 	log.Println("Shutting down gracefully...")
@@ -111,7 +243,44 @@ func main() {
 	// Stop background services
 	watchdog.Stop()
 	queueProcessor.Stop()
+	if jiraSyncer != nil {
+		jiraSyncer.Stop()
+	}
+	if exporter != nil {
+		exporter.Stop()
+	}
+	if updates != nil {
+		updates.Stop()
+	}
+	optimizer.Stop()
+	standup.Stop()
+	modelProber.Stop()
+	if chatBot != nil {
+		chatBot.Stop()
+	}
+	if mcpServer != nil {
+		shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		if err := mcpServer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Warning: MCP server shutdown failed: %v", err)
+		}
+		cancel()
+	}
+	if grpcServer != nil {
+		shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		if err := grpcServer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Warning: gRPC server shutdown failed: %v", err)
+		}
+		cancel()
+	}
+	if advertiser != nil {
+		advertiser.Stop()
+	}
 	syncService.StopPeriodicSync()
+	// Hand leadership over now rather than when the lease runs out
+	elector.Stop()
+	if archiver != nil {
+		archiver.Stop()
+	}
 	
 	log.Println("Shutdown complete")
 }
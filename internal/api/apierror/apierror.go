@@ -0,0 +1,170 @@
+// Package apierror defines the body of every API error response:
+//
+//	{"error": {"code": "task_not_found", "message": "Task not found"}}
+//
+// The code is machine-readable and stable, so that clients such as agents can
+// branch on it; the message is for people and is translated into the request
+// locale. Handlers return errors as they always have, as echo.NewHTTPError or
+// a plain store error, and From gives each its code; New sets a code
+// explicitly where the status alone is too coarse (agent_busy,
+// invalid_transition).
+package apierror
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/validation"
+)
+
+// Codes not derived from a "<thing> not found" message.
+const (
+	BadRequest        = "bad_request"
+	ValidationFailed  = "validation_failed"
+	Unauthorized      = "unauthorized"
+	Forbidden         = "forbidden"
+	NotFound          = "not_found"
+	Conflict          = "conflict"
+	AgentBusy         = "agent_busy"
+	InvalidTransition = "invalid_transition"
+	DelegationLimit   = "delegation_limit_exceeded"
+	SessionEnded      = "session_ended"
+	LimitExceeded     = "limit_exceeded"
+	RateLimited       = "rate_limited"
+	Internal          = "internal_error"
+	NotImplemented    = "not_implemented"
+	Upstream          = "upstream_error"
+	Unavailable       = "unavailable"
+	Maintenance       = "maintenance"
+	HookVetoed        = "hook_vetoed"
+	ScriptRejected    = "script_rejected"
+)
+
+// Detail is the content of an error response.
+type Detail struct {
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Fields  validation.Errors `json:"fields,omitempty"`  // invalid fields of a validation_failed request
+	Details interface{}       `json:"details,omitempty"` // more about the error, by code
+}
+
+// Body is an error response.
+type Body struct {
+	Error Detail `json:"error"`
+}
+
+// String is the message, so that fmt prints an error made by New as it
+// would one made by echo.NewHTTPError.
+func (b Body) String() string {
+	return b.Error.Message
+}
+
+// New returns an HTTP error with an explicit code.
+func New(status int, code, message string) *echo.HTTPError {
+	return echo.NewHTTPError(status, Body{Error: Detail{Code: code, Message: message}})
+}
+
+// WithDetails returns an HTTP error with an explicit code and details.
+func WithDetails(status int, code, message string, details interface{}) *echo.HTTPError {
+	return echo.NewHTTPError(status, Body{Error: Detail{Code: code, Message: message, Details: details}})
+}
+
+// From returns the status and body of the response to err. Store errors
+// reaching a handler's caller unwrapped, or wrapped in a 500 by their text,
+// are mapped here: a missing row is 404 not_found, a constraint violation
+// 409 conflict.
+func From(err error) (int, Body) {
+	var he *echo.HTTPError
+	if !errors.As(err, &he) {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return http.StatusNotFound, body(NotFound, http.StatusText(http.StatusNotFound))
+		case isConstraint(err.Error()):
+			return http.StatusConflict, body(Conflict, err.Error())
+		}
+		return http.StatusInternalServerError, body(Internal, http.StatusText(http.StatusInternalServerError))
+	}
+
+	status := he.Code
+	switch msg := he.Message.(type) {
+	case Body:
+		if msg.Error.Code == "" {
+			msg.Error.Code = codeFor(status, msg.Error.Message)
+		}
+		return status, msg
+	case validation.Response:
+		b := body(ValidationFailed, "Invalid request")
+		b.Error.Fields = msg.Errors
+		return status, b
+	case string:
+		if status == http.StatusInternalServerError {
+			switch {
+			case strings.Contains(msg, sql.ErrNoRows.Error()):
+				return http.StatusNotFound, body(NotFound, http.StatusText(http.StatusNotFound))
+			case isConstraint(msg):
+				return http.StatusConflict, body(Conflict, msg)
+			}
+		}
+		return status, body(codeFor(status, msg), msg)
+	case nil:
+		return status, body(codeFor(status, ""), http.StatusText(status))
+	}
+	msg := fmt.Sprint(he.Message)
+	return status, body(codeFor(status, msg), msg)
+}
+
+func body(code, message string) Body {
+	return Body{Error: Detail{Code: code, Message: message}}
+}
+
+// isConstraint reports whether msg is that of a SQLite constraint violation,
+// such as a UNIQUE or FOREIGN KEY one.
+func isConstraint(msg string) bool {
+	return strings.Contains(msg, "constraint failed")
+}
+
+// codeFor returns the code of an error without one: "<thing>_not_found" for
+// a "<Thing> not found" message, else the code of the status.
+func codeFor(status int, message string) string {
+	if thing, ok := strings.CutSuffix(message, " not found"); ok && thing != "" {
+		return snake(thing) + "_not_found"
+	}
+	switch status {
+	case http.StatusBadRequest:
+		return BadRequest
+	case http.StatusUnauthorized:
+		return Unauthorized
+	case http.StatusForbidden:
+		return Forbidden
+	case http.StatusNotFound:
+		return NotFound
+	case http.StatusConflict:
+		return Conflict
+	case http.StatusRequestEntityTooLarge:
+		return LimitExceeded
+	case http.StatusUnprocessableEntity:
+		return ValidationFailed
+	case http.StatusTooManyRequests:
+		return RateLimited
+	case http.StatusNotImplemented:
+		return NotImplemented
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return Upstream
+	case http.StatusServiceUnavailable:
+		return Unavailable
+	}
+	if status >= 500 {
+		return Internal
+	}
+	return BadRequest
+}
+
+// snake turns "Change request" into "change_request".
+func snake(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), "_")
+}
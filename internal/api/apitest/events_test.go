@@ -0,0 +1,91 @@
+package apitest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// listEvents returns the seq of each task_created event from a page of
+// GET /events, keyed by event ID.
+func listEvents(t *testing.T, h *Harness, query string) (map[string]int64, []string) {
+	t.Helper()
+	code, body := h.Do(http.MethodGet, "/api/v1/events?type=task_created"+query, nil)
+	if code != http.StatusOK {
+		t.Fatalf("list events: status %d: %s", code, body)
+	}
+	var page struct {
+		Data []struct {
+			Seq int64  `json:"seq"`
+			ID  string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &page); err != nil {
+		t.Fatalf("decode events: %v", err)
+	}
+	seqs := map[string]int64{}
+	var ids []string
+	for _, e := range page.Data {
+		seqs[e.ID] = e.Seq
+		ids = append(ids, e.ID)
+	}
+	return seqs, ids
+}
+
+func TestEventSeqSurvivesVacuum(t *testing.T) {
+	h := New(t)
+	for i := 0; i < 4; i++ {
+		h.DoJSON(http.MethodPost, "/api/v1/tasks", map[string]any{"title": fmt.Sprintf("Task %d", i)})
+	}
+	before, ids := listEvents(t, h, "&after=0")
+	if len(ids) != 4 {
+		t.Fatalf("got %d task_created events, want 4", len(ids))
+	}
+
+	// A gap in the table lets VACUUM renumber its rowids
+	ctx := context.Background()
+	if _, err := h.DB.ExecContext(ctx, "DELETE FROM events WHERE id = ?", ids[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.DB.ExecContext(ctx, "VACUUM"); err != nil {
+		t.Fatal(err)
+	}
+
+	after, _ := listEvents(t, h, "&after=0")
+	for _, id := range ids[1:] {
+		if after[id] != before[id] {
+			t.Errorf("event %s: seq %d after VACUUM, was %d", id, after[id], before[id])
+		}
+	}
+	_, rest := listEvents(t, h, fmt.Sprintf("&after=%d", before[ids[1]]))
+	if len(rest) != 2 || rest[0] != ids[2] || rest[1] != ids[3] {
+		t.Errorf("events after %s: %v, want %v", ids[1], rest, ids[2:])
+	}
+
+	h.DoJSON(http.MethodPost, "/api/v1/tasks", map[string]any{"title": "Task 4"})
+	_, newer := listEvents(t, h, fmt.Sprintf("&after=%d", before[ids[3]]))
+	if len(newer) != 1 {
+		t.Errorf("got %d events after the last cursor, want the new one", len(newer))
+	}
+}
+
+func TestOptimizeKeepsEventSeq(t *testing.T) {
+	h := New(t)
+	for i := 0; i < 3; i++ {
+		h.DoJSON(http.MethodPost, "/api/v1/tasks", map[string]any{"title": fmt.Sprintf("Task %d", i)})
+	}
+	before, ids := listEvents(t, h, "&after=0")
+
+	report := h.DoJSON(http.MethodPost, "/api/v1/admin/db/optimize", nil)
+	if report["vacuum"] != "incremental" {
+		t.Fatalf("optimize ran a %v vacuum, want incremental", report["vacuum"])
+	}
+	after, _ := listEvents(t, h, "&after=0")
+	for _, id := range ids {
+		if after[id] != before[id] {
+			t.Errorf("event %s: seq %d after optimize, was %d", id, after[id], before[id])
+		}
+	}
+}
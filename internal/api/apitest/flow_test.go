@@ -0,0 +1,175 @@
+package apitest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/config"
+)
+
+// getTask returns the task as GET /tasks/:id has it.
+func getTask(t *testing.T, h *Harness, id string) map[string]any {
+	t.Helper()
+	data, _ := h.DoJSON(http.MethodGet, "/api/v1/tasks/"+id, nil)["task"].(map[string]any)
+	return data
+}
+
+// awaitAssignments waits for agentID to have been sent n task_assignment
+// notifications about taskID; they are sent in the background.
+func awaitAssignments(t *testing.T, h *Harness, agentID, taskID string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got := 0
+		for _, msg := range h.Sender.SentTo(agentID) {
+			if msg.Kind == "task_assignment" && msg.TaskID == taskID {
+				got++
+			}
+		}
+		if got == n {
+			return
+		}
+		if got > n || time.Now().After(deadline) {
+			t.Fatalf("agent %s was sent %d assignments of task %s, want %d", agentID, got, taskID, n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// countEvents returns how many events of type there are about taskID.
+func countEvents(t *testing.T, h *Harness, taskID, eventType string) int {
+	t.Helper()
+	code, body := h.Do(http.MethodGet, "/api/v1/events?task_id="+taskID+"&type="+eventType, nil)
+	if code != http.StatusOK {
+		t.Fatalf("list events: status %d: %s", code, body)
+	}
+	var page struct {
+		Data []json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &page); err != nil {
+		t.Fatalf("decode events: %v", err)
+	}
+	return len(page.Data)
+}
+
+// exec runs a statement directly on the harness database, for changes no
+// endpoint makes: time passing, updates the server missed.
+func exec(t *testing.T, h *Harness, query string, args ...any) {
+	t.Helper()
+	if _, err := h.DB.ExecContext(context.Background(), query, args...); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+}
+
+func TestDispatchQueueWatchdog(t *testing.T) {
+	h := New(t)
+	h.CreateAgent("dev")
+	ctx := context.Background()
+
+	// A free agent is notified of its task at once
+	first := h.DoJSON(http.MethodPost, "/api/v1/tasks", map[string]any{"title": "Fix the login form", "agent_id": "dev"})
+	firstID := first["id"].(string)
+	awaitAssignments(t, h, "dev", firstID, 1)
+	h.DoJSON(http.MethodPut, "/api/v1/tasks/"+firstID+"/status", map[string]any{"status": "executing"})
+
+	// A busy agent's next task waits in its queue
+	second := h.DoJSON(http.MethodPost, "/api/v1/tasks", map[string]any{"title": "Add a password reset", "agent_id": "dev"})
+	secondID := second["id"].(string)
+	if second["status"] != "queued" {
+		t.Fatalf("task for a busy agent is %v, want queued", second["status"])
+	}
+
+	// The agent finishes without telling the server, so only the queue
+	// processor notices it is free
+	exec(t, h, "UPDATE tasks SET status = 'done' WHERE id = ?", firstID)
+	h.Queue.ProcessOnce(ctx)
+	awaitAssignments(t, h, "dev", secondID, 1)
+	if status := getTask(t, h, secondID)["status"]; status == "queued" {
+		t.Fatalf("dispatched task is still queued")
+	}
+
+	// The agent goes quiet on it: the watchdog re-notifies it ...
+	h.DoJSON(http.MethodPut, "/api/v1/tasks/"+secondID+"/status", map[string]any{"status": "executing"})
+	exec(t, h, "UPDATE tasks SET updated_at = datetime('now', '-1 hour') WHERE id = ?", secondID)
+	h.Watchdog.CheckOnce(ctx)
+	awaitAssignments(t, h, "dev", secondID, 2)
+	stuck, err := h.Store.GetTask(ctx, secondID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stuck.RetryCount != 1 || stuck.Status.String != "executing" {
+		t.Fatalf("stuck task after a retry: status %s, retry_count %d", stuck.Status.String, stuck.RetryCount)
+	}
+	if n := countEvents(t, h, secondID, "task_stuck_retry"); n != 1 {
+		t.Fatalf("%d task_stuck_retry events, want 1", n)
+	}
+
+	// ... until it runs out of retries, and the task goes back to the backlog
+	exec(t, h, "UPDATE tasks SET retry_count = ?, updated_at = datetime('now', '-1 hour') WHERE id = ?", h.Config.WatchdogMaxRetries, secondID)
+	h.Watchdog.CheckOnce(ctx)
+	task := getTask(t, h, secondID)
+	if task["status"] != "backlog" || task["agent_id"] != nil {
+		t.Fatalf("task out of retries: status %v, agent %v, want backlog with no agent", task["status"], task["agent_id"])
+	}
+	if n := countEvents(t, h, secondID, "task_stuck_reset"); n != 1 {
+		t.Fatalf("%d task_stuck_reset events, want 1", n)
+	}
+	awaitAssignments(t, h, "dev", secondID, 2)
+}
+
+func TestDeleteRestoreTask(t *testing.T) {
+	h := New(t, func(cfg *config.Config) { cfg.TrashRetention = time.Hour })
+
+	task := h.DoJSON(http.MethodPost, "/api/v1/tasks", map[string]any{"title": "Write the release notes", "description": "For 2.4"})
+	id := task["id"].(string)
+	h.DoJSON(http.MethodPost, "/api/v1/tasks/"+id+"/comments", map[string]any{"author": "user", "content": "Mention the new importer."})
+
+	if code, body := h.Do(http.MethodDelete, "/api/v1/tasks/"+id, nil); code != http.StatusNoContent {
+		t.Fatalf("delete: status %d: %s", code, body)
+	}
+	if code, _ := h.Do(http.MethodGet, "/api/v1/tasks/"+id, nil); code != http.StatusNotFound {
+		t.Fatalf("deleted task: status %d, want 404", code)
+	}
+	if n := countEvents(t, h, id, "task_created"); n != 0 {
+		t.Fatalf("deleted task still has %d events", n)
+	}
+
+	var trash struct {
+		Items []struct {
+			ID     string `json:"id"`
+			Kind   string `json:"kind"`
+			ItemID string `json:"item_id"`
+		} `json:"items"`
+	}
+	_, body := h.Do(http.MethodGet, "/api/v1/trash?kind=task", nil)
+	if err := json.Unmarshal(body, &trash); err != nil {
+		t.Fatalf("decode trash: %v", err)
+	}
+	if len(trash.Items) != 1 || trash.Items[0].ItemID != id {
+		t.Fatalf("trash holds %+v, want the task", trash.Items)
+	}
+	itemID := trash.Items[0].ID
+
+	h.DoJSON(http.MethodPost, "/api/v1/trash/"+itemID+"/restore", nil)
+	restored := getTask(t, h, id)
+	if restored["title"] != "Write the release notes" || restored["description"] != "For 2.4" {
+		t.Fatalf("restored task: %v", restored)
+	}
+	code, body := h.Do(http.MethodGet, "/api/v1/tasks/"+id+"/comments", nil)
+	var comments []struct {
+		Content string `json:"content"`
+	}
+	if code != http.StatusOK || json.Unmarshal(body, &comments) != nil || len(comments) != 1 || comments[0].Content != "Mention the new importer." {
+		t.Fatalf("restored comments: status %d: %s", code, body)
+	}
+	if n := countEvents(t, h, id, "task_created"); n != 1 {
+		t.Fatalf("restored task has %d task_created events, want 1", n)
+	}
+
+	if code, _ := h.Do(http.MethodPost, "/api/v1/trash/"+itemID+"/restore", nil); code != http.StatusNotFound {
+		t.Fatalf("second restore: status %d, want 404", code)
+	}
+}
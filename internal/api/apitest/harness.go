@@ -0,0 +1,198 @@
+// Package apitest spins up the full Mission Control API against an in-memory
+// database and the fake OpenClaw backends, for end-to-end tests of the
+// dispatch, queue and watchdog flows.
+//
+// Typical use from a _test.go file:
+//
+//	h := apitest.New(t)
+//	agent := h.CreateAgent("dev")
+//	h.DoJSON(http.MethodPost, "/api/v1/tasks", map[string]any{"title": "x", "agent_id": agent.ID})
+//	...
+//	h.Queue.ProcessOnce(ctx)
+//	if got := h.Sender.SentTo(agent.ID); len(got) != 1 { ... }
+package apitest
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/api"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/config"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/queue"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+)
+
+var dbCounter atomic.Int64
+
+// Harness is a running Mission Control server wired to fake backends.
+type Harness struct {
+	TB       testing.TB
+	Config   *config.Config
+	DB       *sql.DB
+	Store    *store.Store
+	Sender   *openclaw.FakeSender
+	Gateway  *openclaw.FakeGateway
+	Server   *api.Server
+	HTTP     *httptest.Server
+	Queue    *queue.Processor
+	Watchdog *queue.Watchdog
+}
+
+// Option customises the harness configuration before the server is built.
+type Option func(cfg *config.Config)
+
+// New starts a harness and registers its cleanup with tb. The queue processor
+// and watchdog are created but not started; drive them with ProcessOnce and
+// CheckOnce so tests stay deterministic.
+func New(tb testing.TB, opts ...Option) *Harness {
+	tb.Helper()
+
+	cfg := &config.Config{
+		Host:                   "127.0.0.1",
+		Port:                   0,
+		Env:                    "test",
+		DatabasePath:           filepath.Join(tb.TempDir(), "mission-control.db"), // the database is in memory; this places object storage
+		WatchdogInterval:       time.Minute,
+		WatchdogStaleThreshold: 30 * time.Minute,
+		WatchdogMaxRetries:     3,
+		AgentRunEnabled:        true,
+		AgentRunMaxTimeout:     time.Minute,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	// Each harness gets its own shared-cache in-memory database; a single
+	// connection keeps it alive for the lifetime of the test.
+	dsn := fmt.Sprintf("file:apitest%d?mode=memory&cache=shared&_foreign_keys=on", dbCounter.Add(1))
+	sqlDB, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		tb.Fatalf("apitest: open database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	if _, err := db.EnableIncrementalVacuum(context.Background(), sqlDB); err != nil {
+		sqlDB.Close()
+		tb.Fatalf("apitest: enable incremental vacuum: %v", err)
+	}
+	if err := db.Migrate(sqlDB); err != nil {
+		sqlDB.Close()
+		tb.Fatalf("apitest: migrate: %v", err)
+	}
+
+	st := store.New(sqlDB)
+	st.SetTrashRetention(cfg.TrashRetention)
+	sender := openclaw.NewFakeSender()
+	gateway := openclaw.NewFakeGateway()
+	server := api.NewServerWithBackends(cfg, st, sender, gateway)
+	httpServer := httptest.NewServer(server.Handler())
+
+	h := &Harness{
+		TB:       tb,
+		Config:   cfg,
+		DB:       sqlDB,
+		Store:    st,
+		Sender:   sender,
+		Gateway:  gateway,
+		Server:   server,
+		HTTP:     httpServer,
+		Queue:    queue.NewProcessor(st, sender, server.Hub(), server.TaskHandler()),
+		Watchdog: queue.NewWatchdog(st, server.Hub(), server.TaskHandler(), cfg.WatchdogStaleThreshold, cfg.WatchdogMaxRetries),
+	}
+
+	tb.Cleanup(func() {
+		httpServer.Close()
+		sqlDB.Close()
+	})
+	return h
+}
+
+// CreateAgent inserts an idle agent directly into the store. POST /agents
+// provisions a workspace through the OpenClaw CLI, which is not available
+// under test.
+func (h *Harness) CreateAgent(id string) db.Agent {
+	h.TB.Helper()
+
+	agent, err := h.Store.CreateAgent(context.Background(), db.CreateAgentParams{
+		ID:     id,
+		Name:   id,
+		Status: sql.NullString{String: "idle", Valid: true},
+	})
+	if err != nil {
+		h.TB.Fatalf("apitest: create agent %s: %v", id, err)
+	}
+	return agent
+}
+
+// URL returns the absolute URL for path on the test server.
+func (h *Harness) URL(path string) string {
+	return h.HTTP.URL + path
+}
+
+// Do sends a request with an optional JSON body and returns the response
+// status and raw body.
+func (h *Harness) Do(method, path string, body interface{}) (int, []byte) {
+	h.TB.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			h.TB.Fatalf("apitest: marshal body: %v", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequest(method, h.URL(path), reader)
+	if err != nil {
+		h.TB.Fatalf("apitest: new request: %v", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := h.HTTP.Client().Do(req)
+	if err != nil {
+		h.TB.Fatalf("apitest: %s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		h.TB.Fatalf("apitest: read body: %v", err)
+	}
+	return resp.StatusCode, data
+}
+
+// DoJSON sends a request, fails the test on a non-2xx status and decodes the
+// response into a generic map.
+func (h *Harness) DoJSON(method, path string, body interface{}) map[string]interface{} {
+	h.TB.Helper()
+
+	status, data := h.Do(method, path, body)
+	if status < 200 || status >= 300 {
+		h.TB.Fatalf("apitest: %s %s: status %d: %s", method, path, status, data)
+	}
+
+	result := map[string]interface{}{}
+	if len(data) == 0 {
+		return result
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		h.TB.Fatalf("apitest: decode %s %s: %v", method, path, err)
+	}
+	return result
+}
@@ -0,0 +1,102 @@
+package apitest
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/config"
+)
+
+// withHooks writes a hooks file of shell scripts and has the server load it.
+func withHooks(t *testing.T, hooks ...map[string]any) Option {
+	t.Helper()
+	data, err := json.Marshal(map[string]any{"hooks": hooks})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "hooks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return func(cfg *config.Config) { cfg.HooksFile = path }
+}
+
+// awaitEvent waits for an event of type about taskID; hooks run with
+// dispatch, in the background.
+func awaitEvent(t *testing.T, h *Harness, taskID, eventType string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for countEvents(t, h, taskID, eventType) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("no %s event for task %s", eventType, taskID)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestHooksVetoAndChangeTasks(t *testing.T) {
+	h := New(t, withHooks(t,
+		map[string]any{
+			"name":   "release-gate",
+			"events": []string{"pre_dispatch"},
+			"command": []string{"sh", "-c", `case "$(cat)" in
+				*'"title":"Hold'*) echo '{"veto": true, "reason": "release freeze"}';;
+				*) echo '{"task": {"description": "Checked by the release gate"}}';;
+				esac`},
+		},
+		map[string]any{
+			"name":        "ci",
+			"events":      []string{"post_complete"},
+			"fail_closed": true,
+			"command":     []string{"sh", "-c", `case "$(cat)" in *'"title":"Broken'*) echo 'pipeline red' >&2; exit 1;; esac`},
+		},
+	))
+	h.CreateAgent("dev")
+	h.CreateAgent("ops")
+
+	// A veto keeps the task from its agent, in the backlog
+	held := h.DoJSON(http.MethodPost, "/api/v1/tasks", map[string]any{"title": "Hold the release", "agent_id": "dev"})
+	heldID := held["id"].(string)
+	awaitEvent(t, h, heldID, "hook_vetoed")
+	if got := getTask(t, h, heldID)["status"]; got != "backlog" {
+		t.Errorf("vetoed task is %v, want backlog", got)
+	}
+	if sent := h.Sender.SentTo("dev"); len(sent) != 0 {
+		t.Errorf("dev was sent %d messages about a vetoed task", len(sent))
+	}
+	if _, body := h.Do(http.MethodGet, "/api/v1/tasks/"+heldID+"/comments", nil); !strings.Contains(string(body), "release freeze") {
+		t.Errorf("no comment with the veto's reason: %s", body)
+	}
+
+	// Changes a hook makes are saved before the task goes out
+	shipped := h.DoJSON(http.MethodPost, "/api/v1/tasks", map[string]any{"title": "Ship the release", "agent_id": "ops"})
+	shippedID := shipped["id"].(string)
+	awaitAssignments(t, h, "ops", shippedID, 1)
+	if got := getTask(t, h, shippedID)["description"]; got != "Checked by the release gate" {
+		t.Errorf("description %v, want the hook's", got)
+	}
+	if n := countEvents(t, h, shippedID, "hook_changed_task"); n != 1 {
+		t.Errorf("%d hook_changed_task events", n)
+	}
+
+	// A fail_closed hook that fails vetoes completion
+	broken := h.DoJSON(http.MethodPost, "/api/v1/tasks", map[string]any{"title": "Broken build"})
+	brokenID := broken["id"].(string)
+	code, body := h.Do(http.MethodPut, "/api/v1/tasks/"+brokenID+"/status", map[string]any{"status": "done"})
+	if code != http.StatusConflict || !strings.Contains(string(body), "pipeline red") {
+		t.Errorf("completing a task the hook fails on: status %d: %s", code, body)
+	}
+	if got := getTask(t, h, brokenID)["status"]; got == "done" {
+		t.Error("a vetoed completion was saved")
+	}
+	// and lets it through when it passes
+	code, body = h.Do(http.MethodPut, "/api/v1/tasks/"+shippedID+"/status", map[string]any{"status": "done"})
+	if code != http.StatusOK {
+		t.Errorf("completing a task the hook passes: status %d: %s", code, body)
+	}
+}
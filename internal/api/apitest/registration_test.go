@@ -0,0 +1,55 @@
+package apitest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/config"
+)
+
+func TestRegisterAppliesAllOrNothing(t *testing.T) {
+	h := New(t, func(cfg *config.Config) { cfg.AgentRegistrationToken = "tok" })
+	ctx := context.Background()
+
+	resp := h.DoJSON(http.MethodPost, "/api/v1/agents/register", map[string]any{
+		"registration_token": "tok",
+		"id":                 "remote",
+		"name":               "Remote",
+		"locale":             "es-mx",
+		"callback_url":       "https://remote.example/cb",
+	})
+	if resp["delivery_method"] != "http_callback" || resp["locale"] != "es" || resp["callback_secret"] == nil {
+		t.Fatalf("registered as %v", resp)
+	}
+
+	// Dropping the callback URL would leave http_callback delivery with
+	// nowhere to go: nothing of the registration is applied
+	code, body := h.Do(http.MethodPost, "/api/v1/agents/register", map[string]any{
+		"registration_token": "tok",
+		"id":                 "remote",
+		"name":               "Renamed",
+	})
+	if code != http.StatusBadRequest {
+		t.Fatalf("status %d, want 400: %s", code, body)
+	}
+	agent, err := h.Store.GetAgent(ctx, "remote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if agent.Name != "Remote" || agent.CallbackURL.String != "https://remote.example/cb" || agent.DeliveryMethod.String != "http_callback" {
+		t.Errorf("a refused registration changed the agent: name %q, callback %q, delivery %q",
+			agent.Name, agent.CallbackURL.String, agent.DeliveryMethod.String)
+	}
+
+	// Switching to cli with it goes through
+	resp = h.DoJSON(http.MethodPost, "/api/v1/agents/register", map[string]any{
+		"registration_token": "tok",
+		"id":                 "remote",
+		"name":               "Renamed",
+		"delivery_method":    "cli",
+	})
+	if resp["name"] != "Renamed" || resp["delivery_method"] != "cli" {
+		t.Errorf("re-registered as %v", resp)
+	}
+}
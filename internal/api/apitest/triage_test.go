@@ -0,0 +1,99 @@
+package apitest
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/config"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
+)
+
+const triageReply = `{"priority": 2, "labels": ["ops"], "agent_id": "dev", "reason": "ops work"}`
+
+func withTriage(cfg *config.Config) {
+	cfg.TriageAgentID = "triage"
+	cfg.TriageTimeout = time.Minute
+	cfg.EmailInboundToken = "email-token"
+	cfg.EmailAllowedSenders = "@example.com"
+}
+
+// awaitTriage waits for the triage agent's answer on a task to be recorded
+// and returns the triage's status.
+func awaitTriage(t *testing.T, h *Harness, taskID string) string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		code, body := h.Do(http.MethodGet, "/api/v1/tasks/"+taskID+"/triage", nil)
+		if code != http.StatusOK {
+			t.Fatalf("triage of %s: status %d: %s", taskID, code, body)
+		}
+		var record struct {
+			Status string `json:"status"`
+		}
+		if err := json.Unmarshal(body, &record); err != nil {
+			t.Fatalf("decode triage: %v", err)
+		}
+		if record.Status != "pending" || time.Now().After(deadline) {
+			return record.Status
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestTriageOnEveryCreationPath(t *testing.T) {
+	h := New(t, withTriage)
+	h.CreateAgent("triage")
+	h.CreateAgent("dev")
+	h.Sender.Reply = func(msg openclaw.SentMessage) (string, error) {
+		return triageReply, nil
+	}
+
+	created := h.DoJSON(http.MethodPost, "/api/v1/tasks", map[string]any{"title": "Rotate the staging keys"})
+	createdID := created["id"].(string)
+	if got := awaitTriage(t, h, createdID); got != "staged" {
+		t.Fatalf("created task: triage %s, want staged", got)
+	}
+
+	cloned := h.DoJSON(http.MethodPost, "/api/v1/tasks/"+createdID+"/clone", map[string]any{"title": "Rotate the production keys"})
+	if got := awaitTriage(t, h, cloned["id"].(string)); got != "staged" {
+		t.Fatalf("clone: triage %s, want staged", got)
+	}
+
+	mailed := h.DoJSON(http.MethodPost, "/api/v1/integrations/email/inbound?token=email-token", map[string]any{
+		"from":       "ana@example.com",
+		"to":         "tasks@example.com",
+		"subject":    "Renew the staging certificate",
+		"text":       "It expires on Friday.",
+		"message_id": "cert@example.com",
+	})
+	if got := awaitTriage(t, h, mailed["task_id"].(string)); got != "staged" {
+		t.Fatalf("email task: triage %s, want staged", got)
+	}
+
+	var asked int
+	for _, msg := range h.Sender.SentTo("triage") {
+		if msg.Kind == "agent_run" {
+			asked++
+		}
+	}
+	if asked != 3 {
+		t.Errorf("triage agent asked %d times, want 3", asked)
+	}
+}
+
+func TestTriageSkipsAssignedClones(t *testing.T) {
+	h := New(t, withTriage)
+	h.CreateAgent("triage")
+	h.CreateAgent("dev")
+
+	src := h.DoJSON(http.MethodPost, "/api/v1/tasks", map[string]any{"title": "Audit dependencies", "agent_id": "dev"})
+	clone := h.DoJSON(http.MethodPost, "/api/v1/tasks/"+src["id"].(string)+"/clone", map[string]any{})
+	if code, body := h.Do(http.MethodGet, "/api/v1/tasks/"+clone["id"].(string)+"/triage", nil); code != http.StatusNotFound {
+		t.Fatalf("assigned clone triaged: status %d: %s", code, body)
+	}
+	if got := h.Sender.SentTo("triage"); len(got) != 0 {
+		t.Fatalf("triage agent asked %d times, want 0", len(got))
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
+)
+
+// CloneAgentRequest clones an agent into a new one.
+type CloneAgentRequest struct {
+	ID   string `json:"id,omitempty"` // omitted = derived from the name
+	Name string `json:"name" validate:"required"`
+	// Description and Model default to the source agent's
+	Description *string `json:"description"`
+	Model       *string `json:"model"`
+	// IncludeMemory copies the source's MEMORY.md and daily notes
+	IncludeMemory bool `json:"include_memory"`
+}
+
+// Clone - POST /api/v1/agents/:id/clone
+// Creates a new agent with a copy of the agent's workspace (identity files,
+// skills and, optionally, memory) and its settings: model, locale, timezone,
+// working hours and notification preferences and timeouts.
+func (h *AgentHandler) Clone(c echo.Context) error {
+	var req CloneAgentRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+	source, err := h.store.GetAgent(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Agent not found")
+	}
+	root, err := h.agentWorkspace(c)
+	if err != nil {
+		return err
+	}
+	if req.ID == "" {
+		req.ID = strings.ToLower(strings.ReplaceAll(req.Name, " ", "-"))
+	}
+	if _, err := h.store.GetAgent(c.Request().Context(), req.ID); err == nil {
+		return echo.NewHTTPError(http.StatusConflict, "Agent already exists")
+	}
+	description := source.Description
+	if req.Description != nil {
+		description = sql.NullString{String: *req.Description, Valid: *req.Description != ""}
+	}
+	model := source.Model
+	if req.Model != nil {
+		model = sql.NullString{String: *req.Model, Valid: *req.Model != ""}
+	}
+
+	created, err := h.agentCreator.CloneAgent(&openclaw.CloneAgentRequest{
+		SourceID:        source.ID,
+		SourceWorkspace: root,
+		ID:              req.ID,
+		Name:            req.Name,
+		Model:           model.String,
+		IncludeMemory:   req.IncludeMemory,
+	})
+	if errors.Is(err, openclaw.ErrWorkspaceExists) {
+		return echo.NewHTTPError(http.StatusConflict, "Agent already exists")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to clone agent workspace: "+err.Error())
+	}
+
+	ctx := c.Request().Context()
+	agent, err := h.store.CreateAgent(ctx, db.CreateAgentParams{
+		ID:              created.ID,
+		Name:            req.Name,
+		Description:     description,
+		Status:          sql.NullString{String: "active", Valid: true},
+		WorkspacePath:   sql.NullString{String: created.WorkspacePath, Valid: true},
+		AgentDirPath:    sql.NullString{String: created.AgentDirPath, Valid: created.AgentDirPath != ""},
+		Model:           model,
+		MentionPatterns: sql.NullString{String: "[]", Valid: true},
+		SoulMd:          sql.NullString{String: created.SoulMD, Valid: created.SoulMD != ""},
+		AgentsMd:        sql.NullString{String: created.AgentsMD, Valid: created.AgentsMD != ""},
+		IdentityMd:      sql.NullString{String: created.IdentityMD, Valid: created.IdentityMD != ""},
+		UserMd:          sql.NullString{String: created.UserMD, Valid: created.UserMD != ""},
+		ToolsMd:         sql.NullString{String: created.ToolsMD, Valid: created.ToolsMD != ""},
+		HeartbeatMd:     sql.NullString{String: created.HeartbeatMD, Valid: created.HeartbeatMD != ""},
+		MemoryMd:        sql.NullString{String: created.MemoryMD, Valid: created.MemoryMD != ""},
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	// The clone works the way the source does
+	if source.Locale.Valid {
+		if err := h.store.UpdateAgentLocale(ctx, agent.ID, source.Locale.String); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		agent.Locale = source.Locale
+	}
+	if source.Timezone.Valid {
+		if err := h.store.UpdateAgentTimezone(ctx, agent.ID, source.Timezone.String); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		agent.Timezone = source.Timezone
+	}
+	if source.WorkingHours.Valid {
+		if err := h.store.UpdateAgentWorkingHours(ctx, agent.ID, source.WorkingHours.String); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		agent.WorkingHours = source.WorkingHours
+	}
+	if source.NotificationPrefs.Valid {
+		if err := h.store.UpdateAgentNotificationPrefs(ctx, agent.ID, source.NotificationPrefs.String); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		agent.NotificationPrefs = source.NotificationPrefs
+	}
+	if source.NotificationTimeouts.Valid {
+		if err := h.store.UpdateAgentNotificationTimeouts(ctx, agent.ID, source.NotificationTimeouts.String); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		agent.NotificationTimeouts = source.NotificationTimeouts
+	}
+	if source.PublicAPIURL.Valid {
+		if err := h.store.UpdateAgentPublicAPIURL(ctx, agent.ID, source.PublicAPIURL.String); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		agent.PublicAPIURL = source.PublicAPIURL
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"source_id": source.ID, "include_memory": req.IncludeMemory,
+	})
+	h.logEvent(context.WithoutCancel(ctx), agent.ID, "agent_cloned",
+		fmt.Sprintf("Agent %s cloned from %s", agent.ID, source.ID), string(details))
+	return c.JSON(http.StatusCreated, ToAgentResponse(agent))
+}
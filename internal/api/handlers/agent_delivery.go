@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/url"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
+)
+
+// agentDelivery is how notifications reach an agent, as stored on the agent.
+type agentDelivery struct {
+	Method      string
+	CallbackURL string
+	Secret      string
+	// Issued is set when Secret was generated by this change and must be
+	// handed back to the caller, who will not see it again.
+	Issued bool
+}
+
+// resolveDelivery applies a change of delivery method and/or callback URL
+// (nil = unchanged) to an agent's current settings. A callback secret is
+// generated the first time http_callback is set up, or when rotate is set.
+// Errors are client errors, worded for the response.
+func resolveDelivery(existing db.Agent, method, callbackURL *string, rotate bool) (agentDelivery, error) {
+	d := agentDelivery{
+		Method:      existing.DeliveryMethod.String,
+		CallbackURL: existing.CallbackURL.String,
+		Secret:      existing.CallbackSecret.String,
+	}
+	if method != nil {
+		if *method != "" && !openclaw.ValidDeliveryMethod(*method) {
+			return d, errors.New("delivery_method must be cli, gateway or http_callback")
+		}
+		d.Method = *method
+	}
+	if callbackURL != nil {
+		if *callbackURL != "" && !validCallbackURL(*callbackURL) {
+			return d, errors.New("callback_url must be an http(s) URL")
+		}
+		d.CallbackURL = *callbackURL
+	}
+	if d.Method == openclaw.DeliveryCLI {
+		d.Method = "" // the default; stored as NULL
+	}
+	if d.Method != openclaw.DeliveryHTTPCallback {
+		if rotate {
+			return d, errors.New("rotate_callback_secret needs http_callback delivery")
+		}
+		return d, nil
+	}
+	if d.CallbackURL == "" {
+		return d, errors.New("http_callback delivery needs a callback_url")
+	}
+	if d.Secret == "" || rotate {
+		d.Secret, d.Issued = newCallbackSecret(), true
+	}
+	return d, nil
+}
+
+// validCallbackURL reports whether raw is an absolute http(s) URL.
+func validCallbackURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// newCallbackSecret returns a random key for signing http_callback deliveries.
+func newCallbackSecret() string {
+	b := make([]byte, 32)
+	rand.Read(b) // never fails since Go 1.24
+	return hex.EncodeToString(b)
+}
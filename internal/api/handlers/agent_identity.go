@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/textdiff"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/workspace"
+)
+
+// identityProposalTTL is how long a regenerated identity can be applied.
+const identityProposalTTL = 30 * time.Minute
+
+// identityProposal is a regenerated identity waiting to be applied.
+type identityProposal struct {
+	id          string
+	agentID     string
+	description string
+	proposed    map[string]string // by IdentityFile key
+	current     map[string]string // the workspace files the diff was made against
+	expiresAt   time.Time
+}
+
+// RegenerateIdentityRequest regenerates an agent's identity files.
+type RegenerateIdentityRequest struct {
+	// Description to generate from; omitted = the agent's
+	Description *string `json:"description"`
+	// Files to regenerate, by key (soul_md, ...); omitted = all but memory_md
+	Files []string `json:"files"`
+}
+
+// IdentityFileDiff compares an identity file of the workspace with its
+// regenerated version.
+type IdentityFileDiff struct {
+	Key      string `json:"key"`
+	Name     string `json:"name"`
+	Changed  bool   `json:"changed"`
+	Added    int    `json:"added"`
+	Removed  int    `json:"removed"`
+	Diff     string `json:"diff,omitempty"` // unified diff
+	Current  string `json:"current"`
+	Proposed string `json:"proposed"`
+}
+
+// RegenerateIdentityResponse is a regenerated identity to review before
+// applying it with its ProposalID.
+type RegenerateIdentityResponse struct {
+	ProposalID         string                       `json:"proposal_id"`
+	AgentID            string                       `json:"agent_id"`
+	Description        string                       `json:"description"`
+	ExpiresAt          time.Time                    `json:"expires_at"`
+	IdentityGeneration *openclaw.IdentityGeneration `json:"identity_generation,omitempty"`
+	Files              []IdentityFileDiff           `json:"files"`
+}
+
+// ApplyIdentityRequest applies a regenerated identity.
+type ApplyIdentityRequest struct {
+	ProposalID string `json:"proposal_id" validate:"required"`
+	// Files to apply, by key; omitted = every changed file of the proposal
+	Files []string `json:"files"`
+}
+
+// ApplyIdentityResponse is the agent with its regenerated identity applied.
+type ApplyIdentityResponse struct {
+	Agent    AgentResponse `json:"agent"`
+	Applied  []string      `json:"applied"` // file names
+	Commit   string        `json:"commit,omitempty"`
+	GitError string        `json:"git_error,omitempty"`
+}
+
+// identityFileName returns the file name of the identity file key.
+func identityFileName(key string) (string, bool) {
+	for _, f := range openclaw.IdentityFiles {
+		if f.Key == key {
+			return f.Name, true
+		}
+	}
+	return "", false
+}
+
+// readIdentityFile returns the content of the identity file name in the
+// workspace at root, "" if there is none.
+func readIdentityFile(root, name string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(root, name))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	return string(data), err
+}
+
+// RegenerateIdentity - POST /api/v1/agents/:id/regenerate-identity
+// Generates the agent's identity files again, from its description or an
+// updated one, and returns them with a diff against the workspace files.
+// Nothing changes until the proposal is applied.
+func (h *AgentHandler) RegenerateIdentity(c echo.Context) error {
+	var req RegenerateIdentityRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+	agent, err := h.store.GetAgent(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Agent not found")
+	}
+	description := agent.Description.String
+	if req.Description != nil {
+		description = strings.TrimSpace(*req.Description)
+	}
+	if description == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "description is required")
+	}
+	keys := req.Files
+	if len(keys) == 0 {
+		for _, f := range openclaw.IdentityFiles {
+			if f.Key != "memory_md" {
+				keys = append(keys, f.Key)
+			}
+		}
+	}
+	for _, key := range keys {
+		if _, ok := identityFileName(key); !ok {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("unknown identity file %q", key))
+		}
+	}
+	root, err := h.agentWorkspace(c)
+	if err != nil {
+		return err
+	}
+
+	identity, generation := h.agentCreator.GenerateIdentity(c.Request().Context(), &openclaw.GenerateIdentityRequest{
+		AgentName:   agent.Name,
+		Description: description,
+		Model:       agent.Model.String,
+	})
+	proposal := &identityProposal{
+		id:          uuid.New().String(),
+		agentID:     agent.ID,
+		description: description,
+		proposed:    make(map[string]string),
+		current:     make(map[string]string),
+		expiresAt:   time.Now().Add(identityProposalTTL).UTC(),
+	}
+	resp := RegenerateIdentityResponse{
+		ProposalID:         proposal.id,
+		AgentID:            agent.ID,
+		Description:        description,
+		ExpiresAt:          proposal.expiresAt,
+		IdentityGeneration: generation,
+		Files:              []IdentityFileDiff{},
+	}
+	for _, key := range keys {
+		name, _ := identityFileName(key)
+		current, err := readIdentityFile(root, name)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		proposed := identity.File(key)
+		proposal.current[key], proposal.proposed[key] = current, proposed
+		added, removed := textdiff.Stats(current, proposed)
+		resp.Files = append(resp.Files, IdentityFileDiff{
+			Key:      key,
+			Name:     name,
+			Changed:  current != proposed,
+			Added:    added,
+			Removed:  removed,
+			Diff:     textdiff.Unified("a/"+name, "b/"+name, current, proposed, 3),
+			Current:  current,
+			Proposed: proposed,
+		})
+	}
+
+	h.proposalMu.Lock()
+	now := time.Now()
+	for id, p := range h.proposals {
+		if now.After(p.expiresAt) {
+			delete(h.proposals, id)
+		}
+	}
+	if h.proposals == nil {
+		h.proposals = make(map[string]*identityProposal)
+	}
+	h.proposals[proposal.id] = proposal
+	h.proposalMu.Unlock()
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// ApplyIdentity - POST /api/v1/agents/:id/regenerate-identity/apply
+// Writes a regenerated identity to the workspace, commits it and stores it on
+// the agent, unless the files changed since the proposal was made.
+func (h *AgentHandler) ApplyIdentity(c echo.Context) error {
+	var req ApplyIdentityRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+	id := c.Param("id")
+	h.proposalMu.Lock()
+	proposal := h.proposals[req.ProposalID]
+	h.proposalMu.Unlock()
+	if proposal == nil || proposal.agentID != id || time.Now().After(proposal.expiresAt) {
+		return echo.NewHTTPError(http.StatusNotFound, "Identity proposal not found or expired")
+	}
+	keys := req.Files
+	if len(keys) == 0 {
+		for _, f := range openclaw.IdentityFiles {
+			if p, ok := proposal.proposed[f.Key]; ok && p != proposal.current[f.Key] {
+				keys = append(keys, f.Key)
+			}
+		}
+	}
+	for _, key := range keys {
+		if _, ok := proposal.proposed[key]; !ok {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("identity file %q is not part of the proposal", key))
+		}
+	}
+	root, err := h.agentWorkspace(c)
+	if err != nil {
+		return err
+	}
+	ctx := context.WithoutCancel(c.Request().Context())
+
+	h.memoryMu.Lock()
+	defer h.memoryMu.Unlock()
+	existing, err := h.store.GetAgent(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Agent not found")
+	}
+	var names []string
+	for _, key := range keys {
+		name, _ := identityFileName(key)
+		current, err := readIdentityFile(root, name)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		if current != proposal.current[key] {
+			return echo.NewHTTPError(http.StatusConflict, "Identity files changed since the proposal was made; regenerate again")
+		}
+		names = append(names, name)
+	}
+	for i, key := range keys {
+		if err := workspace.WriteFile(root, names[i], proposal.proposed[key]); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	resp := ApplyIdentityResponse{Applied: names}
+	if resp.Applied == nil {
+		resp.Applied = []string{}
+	}
+	if len(names) > 0 {
+		resp.Commit, err = workspace.CommitFiles(ctx, root, "Regenerate identity via Mission Control", names...)
+		if err != nil {
+			log.Printf("[AgentHandler] Committing regenerated identity of agent %s: %v", id, err)
+			resp.GitError = err.Error()
+		}
+	}
+
+	// The agent's copies of the files follow the workspace
+	params := db.UpdateAgentParams{
+		ID:               id,
+		Name:             existing.Name,
+		Description:      sql.NullString{String: proposal.description, Valid: true},
+		Status:           existing.Status,
+		Model:            existing.Model,
+		MentionPatterns:  existing.MentionPatterns,
+		SoulMd:           existing.SoulMd,
+		AgentsMd:         existing.AgentsMd,
+		IdentityMd:       existing.IdentityMd,
+		UserMd:           existing.UserMd,
+		ToolsMd:          existing.ToolsMd,
+		HeartbeatMd:      existing.HeartbeatMd,
+		ActiveSessionKey: existing.ActiveSessionKey,
+		CurrentTaskID:    existing.CurrentTaskID,
+	}
+	fields := map[string]*sql.NullString{
+		"soul_md":      &params.SoulMd,
+		"agents_md":    &params.AgentsMd,
+		"identity_md":  &params.IdentityMd,
+		"user_md":      &params.UserMd,
+		"tools_md":     &params.ToolsMd,
+		"heartbeat_md": &params.HeartbeatMd,
+	}
+	for _, key := range keys {
+		content := proposal.proposed[key]
+		if field, ok := fields[key]; ok {
+			*field = sql.NullString{String: content, Valid: content != ""}
+		} else if key == "memory_md" {
+			if err := h.store.UpdateAgentMemory(ctx, id, content); err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+			}
+		}
+	}
+	agent, err := h.store.UpdateAgent(ctx, params)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	resp.Agent = ToAgentResponse(agent)
+
+	h.proposalMu.Lock()
+	delete(h.proposals, proposal.id)
+	h.proposalMu.Unlock()
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"proposal_id": proposal.id, "files": names, "commit": resp.Commit,
+	})
+	h.logEvent(ctx, id, "agent_identity_regenerated",
+		fmt.Sprintf("Identity of agent %s regenerated (%s)", id, strings.Join(names, ", ")), string(details))
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/workspace"
+)
+
+// MemoryListResponse is an agent's memory: its MEMORY.md and daily notes.
+type MemoryListResponse struct {
+	AgentID  string             `json:"agent_id"`
+	LongTerm *workspace.Memory  `json:"long_term"` // null if there is no MEMORY.md
+	Daily    []workspace.Memory `json:"daily"`     // newest first
+}
+
+// SaveMemoryRequest replaces a memory file. Revision is that of the content
+// the edit was based on, "" to create the file.
+type SaveMemoryRequest struct {
+	Content  string `json:"content"`
+	Revision string `json:"revision"`
+	Message  string `json:"message,omitempty"` // of the workspace commit
+}
+
+// SaveMemoryResponse is a saved memory file and the workspace commit of it.
+type SaveMemoryResponse struct {
+	workspace.MemoryContent
+	Commit   string `json:"commit,omitempty"`
+	GitError string `json:"git_error,omitempty"`
+}
+
+// memoryError maps a workspace error about a memory file to an HTTP error.
+func memoryError(err error) error {
+	switch {
+	case errors.Is(err, workspace.ErrBadName):
+		return echo.NewHTTPError(http.StatusBadRequest, "memory must be MEMORY.md or a YYYY-MM-DD daily note")
+	case errors.Is(err, workspace.ErrNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "Memory file not found")
+	case errors.Is(err, workspace.ErrOutside):
+		return echo.NewHTTPError(http.StatusBadRequest, "path must be inside the workspace")
+	case errors.Is(err, workspace.ErrIsDir):
+		return echo.NewHTTPError(http.StatusBadRequest, "path is a directory")
+	case errors.Is(err, workspace.ErrConflict):
+		return echo.NewHTTPError(http.StatusConflict, "Memory file changed since it was read; reload it and edit again")
+	case errors.Is(err, workspace.ErrTooLarge):
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "content must be at most 1 MiB")
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
+
+// ListMemory - GET /api/v1/agents/:id/memory
+// Lists the agent's MEMORY.md and memory/YYYY-MM-DD.md daily notes.
+func (h *AgentHandler) ListMemory(c echo.Context) error {
+	root, err := h.agentWorkspace(c)
+	if err != nil {
+		return err
+	}
+	longTerm, daily, err := workspace.ListMemory(root)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, MemoryListResponse{AgentID: c.Param("id"), LongTerm: longTerm, Daily: daily})
+}
+
+// GetMemory - GET /api/v1/agents/:id/memory/:name
+// Returns MEMORY.md or a daily note (by its date) with its revision.
+func (h *AgentHandler) GetMemory(c echo.Context) error {
+	root, err := h.agentWorkspace(c)
+	if err != nil {
+		return err
+	}
+	memory, err := workspace.ReadMemory(root, c.Param("name"))
+	if err != nil {
+		return memoryError(err)
+	}
+	return c.JSON(http.StatusOK, memory)
+}
+
+// PutMemory - PUT /api/v1/agents/:id/memory/:name
+// Replaces MEMORY.md or a daily note, if it is still at the revision the edit
+// was based on (409 otherwise), and commits it if the workspace is a git
+// repository. A saved MEMORY.md is also stored on the agent, so the next
+// config sync finds it unchanged.
+func (h *AgentHandler) PutMemory(c echo.Context) error {
+	var req SaveMemoryRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+	rel, err := workspace.MemoryPath(c.Param("name"))
+	if err != nil {
+		return memoryError(err)
+	}
+	root, err := h.agentWorkspace(c)
+	if err != nil {
+		return err
+	}
+	ctx := context.WithoutCancel(c.Request().Context())
+	id := c.Param("id")
+
+	h.memoryMu.Lock()
+	defer h.memoryMu.Unlock()
+	memory, err := workspace.WriteMemory(root, c.Param("name"), req.Content, req.Revision)
+	if err != nil {
+		return memoryError(err)
+	}
+	resp := SaveMemoryResponse{MemoryContent: memory}
+
+	message := req.Message
+	if message == "" {
+		message = fmt.Sprintf("Edit %s from Mission Control", rel)
+	}
+	resp.Commit, err = workspace.CommitFiles(ctx, root, message, rel)
+	if err != nil {
+		log.Printf("[AgentHandler] Committing %s of agent %s: %v", rel, id, err)
+		resp.GitError = err.Error()
+	}
+	if rel == workspace.MemoryFile {
+		if err := h.store.UpdateAgentMemory(ctx, id, memory.Content); err != nil {
+			log.Printf("[AgentHandler] Storing MEMORY.md of agent %s: %v", id, err)
+		}
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"path": rel, "revision": memory.Revision, "previous_revision": req.Revision,
+		"size": memory.Size, "commit": resp.Commit,
+	})
+	h.logEvent(ctx, id, "agent_memory_updated", fmt.Sprintf("%s of agent %s edited", rel, id), string(details))
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/i18n"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+)
+
+// agentIDPattern is what a self-registering agent may call itself; the same
+// shape as IDs in openclaw.json.
+var agentIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// RegisterAgentRequest is sent by an agent that is not in openclaw.json (e.g.
+// one on a remote machine) to add itself to Mission Control.
+type RegisterAgentRequest struct {
+	RegistrationToken string `json:"registration_token"`
+	ID                string `json:"id"`
+	Name              string `json:"name"`
+	Description       string `json:"description"`
+	Model             string `json:"model"`
+	Locale            string `json:"locale"`
+	CallbackURL       string `json:"callback_url"` // where Mission Control can reach the agent
+	// DeliveryMethod is how notifications should reach the agent; with a
+	// callback_url it defaults to http_callback (unless set before), else cli.
+	DeliveryMethod string `json:"delivery_method" validate:"omitempty,oneof=cli gateway http_callback"`
+}
+
+// SetRegistrationToken enables agent self-registration for agents presenting
+// token. An empty token (the default) disables it.
+func (h *AgentHandler) SetRegistrationToken(token string) {
+	h.registrationToken = token
+}
+
+// Register - POST /api/v1/agents/register
+// Adds an externally managed agent, or refreshes one that registered before.
+// The agent is flagged managed_externally, so config sync never overwrites it.
+func (h *AgentHandler) Register(c echo.Context) error {
+	if h.registrationToken == "" {
+		return echo.NewHTTPError(http.StatusForbidden, "Agent registration is disabled")
+	}
+	var req RegisterAgentRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare([]byte(req.RegistrationToken), []byte(h.registrationToken)) != 1 {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Invalid registration token")
+	}
+	if !agentIDPattern.MatchString(req.ID) {
+		return echo.NewHTTPError(http.StatusBadRequest, "id must be lower-case letters, digits, - or _")
+	}
+	if req.Name == "" {
+		req.Name = req.ID
+	}
+	if req.Locale != "" && !i18n.Supported(req.Locale) {
+		return echo.NewHTTPError(http.StatusBadRequest, "unsupported locale")
+	}
+	if req.CallbackURL != "" && !validCallbackURL(req.CallbackURL) {
+		return echo.NewHTTPError(http.StatusBadRequest, "callback_url must be an http(s) URL")
+	}
+	if req.DeliveryMethod == openclaw.DeliveryHTTPCallback && req.CallbackURL == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "http_callback delivery needs a callback_url")
+	}
+
+	ctx := c.Request().Context()
+	// Check the delivery settings the agent would be left with before
+	// registering anything; the transaction below settles them for good
+	existing, err := h.store.GetAgent(ctx, req.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	existing.CallbackURL = sql.NullString{String: req.CallbackURL, Valid: req.CallbackURL != ""}
+	if _, err := registrationDelivery(existing, req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	var delivery agentDelivery
+	agent, created, err := h.store.RegisterExternalAgent(ctx, db.CreateAgentParams{
+		ID:          req.ID,
+		Name:        req.Name,
+		Description: sql.NullString{String: req.Description, Valid: req.Description != ""},
+		Status:      sql.NullString{String: "active", Valid: true},
+		Model:       sql.NullString{String: req.Model, Valid: req.Model != ""},
+	}, req.CallbackURL, func(tx *store.Store, agent db.Agent) error {
+		if req.Locale != "" {
+			if err := tx.UpdateAgentLocale(ctx, agent.ID, i18n.Resolve(req.Locale, "")); err != nil {
+				return err
+			}
+		}
+		var err error
+		if delivery, err = registrationDelivery(agent, req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return tx.UpdateAgentDelivery(ctx, agent.ID, delivery.Method, delivery.CallbackURL, delivery.Secret)
+	})
+	var he *echo.HTTPError
+	switch {
+	case errors.Is(err, store.ErrAgentManagedByConfig):
+		return echo.NewHTTPError(http.StatusConflict, "Agent ID is taken by an agent from the OpenClaw config")
+	case errors.As(err, &he):
+		return he
+	case err != nil:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	status := http.StatusOK
+	verb := "re-registered"
+	if created {
+		status = http.StatusCreated
+		verb = "registered"
+	}
+	log.Printf("[AgentHandler] External agent %s %s (callback %q)", agent.ID, verb, req.CallbackURL)
+	h.logEvent(ctx, agent.ID, "agent_registered", fmt.Sprintf("Agent %s %s itself", agent.Name, verb), "")
+	resp := ToAgentResponse(agent)
+	if delivery.Method == openclaw.DeliveryHTTPCallback {
+		// The agent proved itself with the registration token, so it may
+		// always fetch the key its callbacks are signed with.
+		resp.CallbackSecret = &delivery.Secret
+	}
+	return c.JSON(status, resp)
+}
+
+// registrationDelivery resolves the delivery settings a registration leaves
+// agent with, agent having the callback URL of the registration. A callback
+// URL sets up http_callback delivery unless the agent has a method already.
+func registrationDelivery(agent db.Agent, req RegisterAgentRequest) (agentDelivery, error) {
+	var method *string
+	httpCallback := openclaw.DeliveryHTTPCallback
+	switch {
+	case req.DeliveryMethod != "":
+		method = &req.DeliveryMethod
+	case req.CallbackURL != "" && !agent.DeliveryMethod.Valid:
+		method = &httpCallback
+	}
+	return resolveDelivery(agent, method, nil, false)
+}
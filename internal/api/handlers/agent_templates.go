@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
+)
+
+// AgentTemplateHandler manages the template packs new agents can be created
+// from.
+type AgentTemplateHandler struct {
+	packs *openclaw.TemplatePacks
+}
+
+func NewAgentTemplateHandler(packs *openclaw.TemplatePacks) *AgentTemplateHandler {
+	return &AgentTemplateHandler{packs: packs}
+}
+
+// SaveAgentTemplateRequest creates or replaces a template pack.
+type SaveAgentTemplateRequest struct {
+	Description string            `json:"description"`
+	Model       string            `json:"model"`
+	Skills      []string          `json:"skills"` // null = the default skills
+	Files       map[string]string `json:"files"`
+}
+
+// packError maps a template pack error to an HTTP error.
+func packError(err error) error {
+	switch {
+	case errors.Is(err, openclaw.ErrPackNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "Template pack not found")
+	case errors.Is(err, openclaw.ErrBadPackName):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
+
+// SetTemplatePacks sets the template packs agents can be created from.
+func (h *AgentHandler) SetTemplatePacks(packs *openclaw.TemplatePacks) {
+	h.templatePacks = packs
+}
+
+// applyTemplatePack fills in what req leaves out from the template pack name:
+// the workspace files, rendered for the agent, its skills and its model.
+func (h *AgentHandler) applyTemplatePack(name string, req *openclaw.CreateAgentRequest) error {
+	if h.templatePacks == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Template pack not found")
+	}
+	pack, err := h.templatePacks.Get(name)
+	if err != nil {
+		if errors.Is(err, openclaw.ErrPackNotFound) || errors.Is(err, openclaw.ErrBadPackName) {
+			return echo.NewHTTPError(http.StatusBadRequest, "Template pack not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if req.Model == "" {
+		req.Model = pack.Model
+	}
+	files, err := pack.Render(openclaw.TemplateData{ID: req.ID, Name: req.Name, Description: req.Description, Model: req.Model})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid template pack: %v", err))
+	}
+	identity := map[string]*string{
+		"SOUL.md":      &req.SoulMD,
+		"AGENTS.md":    &req.AgentsMD,
+		"IDENTITY.md":  &req.IdentityMD,
+		"USER.md":      &req.UserMD,
+		"TOOLS.md":     &req.ToolsMD,
+		"HEARTBEAT.md": &req.HeartbeatMD,
+		"MEMORY.md":    &req.MemoryMD,
+	}
+	req.ExtraFiles = make(map[string]string)
+	for file, content := range files {
+		if field, ok := identity[file]; ok {
+			if *field == "" {
+				*field = content
+			}
+			continue
+		}
+		req.ExtraFiles[file] = content
+	}
+	req.Skills = pack.Skills
+	return nil
+}
+
+// List - GET /api/v1/agent-templates
+func (h *AgentTemplateHandler) List(c echo.Context) error {
+	packs, err := h.packs.List()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": packs,
+		"meta": map[string]interface{}{
+			"total": len(packs),
+			"dir":   h.packs.Dir(),
+		},
+	})
+}
+
+// Get - GET /api/v1/agent-templates/:name
+func (h *AgentTemplateHandler) Get(c echo.Context) error {
+	pack, err := h.packs.Get(c.Param("name"))
+	if err != nil {
+		return packError(err)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": pack})
+}
+
+// Save - PUT /api/v1/agent-templates/:name
+// Creates the pack or replaces it whole.
+func (h *AgentTemplateHandler) Save(c echo.Context) error {
+	var req SaveAgentTemplateRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+	pack := openclaw.TemplatePack{
+		Name:        c.Param("name"),
+		Description: req.Description,
+		Model:       req.Model,
+		Skills:      req.Skills,
+		Files:       req.Files,
+	}
+	if err := openclaw.ValidateTemplatePack(pack); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid template pack: %v", err))
+	}
+	saved, err := h.packs.Save(pack)
+	if err != nil {
+		return packError(err)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": saved})
+}
+
+// Delete - DELETE /api/v1/agent-templates/:name
+func (h *AgentTemplateHandler) Delete(c echo.Context) error {
+	if err := h.packs.Delete(c.Param("name")); err != nil {
+		return packError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/workspace"
+)
+
+// WorkspaceResponse is an agent's workspace: its file tree and, if it is a
+// git repository, its status and latest commits.
+type WorkspaceResponse struct {
+	AgentID   string              `json:"agent_id"`
+	Path      string              `json:"path"`
+	Depth     int                 `json:"depth"`
+	Entries   []workspace.Entry   `json:"entries"`
+	Truncated bool                `json:"truncated"`
+	Git       *workspace.GitState `json:"git"`
+	GitError  string              `json:"git_error,omitempty"`
+}
+
+// agentWorkspace returns the workspace directory of the agent in the request.
+func (h *AgentHandler) agentWorkspace(c echo.Context) (string, error) {
+	agent, err := h.store.GetAgent(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return "", echo.NewHTTPError(http.StatusNotFound, "Agent not found")
+	}
+	if !agent.WorkspacePath.Valid || agent.WorkspacePath.String == "" {
+		return "", echo.NewHTTPError(http.StatusNotFound, "Agent has no workspace")
+	}
+	root, err := workspace.Resolve(agent.WorkspacePath.String)
+	if err != nil {
+		return "", echo.NewHTTPError(http.StatusNotFound, "Workspace not found on disk")
+	}
+	return root, nil
+}
+
+// queryInt reads the integer query parameter name, def if absent, which must
+// be between 1 and max.
+func queryInt(c echo.Context, name string, def, max int) (int, error) {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 || n > max {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("%s must be between 1 and %d", name, max))
+	}
+	return n, nil
+}
+
+// Workspace - GET /api/v1/agents/:id/workspace?depth=4&log=10
+// Lists the agent's workspace, down to depth levels and at most 2000
+// entries, with the git status and last log commits of its repository.
+func (h *AgentHandler) Workspace(c echo.Context) error {
+	depth, err := queryInt(c, "depth", workspace.DefaultDepth, workspace.MaxDepth)
+	if err != nil {
+		return err
+	}
+	logSize, err := queryInt(c, "log", workspace.DefaultLogSize, workspace.MaxLogSize)
+	if err != nil {
+		return err
+	}
+	root, err := h.agentWorkspace(c)
+	if err != nil {
+		return err
+	}
+
+	tree, err := workspace.List(root, depth)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	resp := WorkspaceResponse{
+		AgentID:   c.Param("id"),
+		Path:      root,
+		Depth:     depth,
+		Entries:   tree.Entries,
+		Truncated: tree.Truncated,
+	}
+	resp.Git, err = workspace.Git(context.WithoutCancel(c.Request().Context()), root, logSize)
+	if err != nil {
+		log.Printf("[AgentHandler] Reading git state of workspace %s: %v", root, err)
+		resp.GitError = err.Error()
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// WorkspaceFile - GET /api/v1/agents/:id/workspace/file?path=MEMORY.md
+// Returns a text file of the agent's workspace, up to 1 MiB of it.
+func (h *AgentHandler) WorkspaceFile(c echo.Context) error {
+	path := c.QueryParam("path")
+	if path == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "path is required")
+	}
+	root, err := h.agentWorkspace(c)
+	if err != nil {
+		return err
+	}
+
+	file, err := workspace.ReadFile(root, path)
+	switch {
+	case errors.Is(err, workspace.ErrNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "File not found")
+	case errors.Is(err, workspace.ErrOutside):
+		return echo.NewHTTPError(http.StatusBadRequest, "path must be inside the workspace")
+	case errors.Is(err, workspace.ErrIsDir):
+		return echo.NewHTTPError(http.StatusBadRequest, "path is a directory")
+	case errors.Is(err, workspace.ErrNotText):
+		return echo.NewHTTPError(http.StatusForbidden, "Only text files (.md, .txt, .json, .jsonl, .yaml, .yml, .toml, .log, .csv) can be read")
+	case err != nil:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, file)
+}
@@ -1,27 +1,84 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/i18n"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/localtime"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/notifyprefs"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/notifytimeout"
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+	ws "github.com/abelkuruvilla/claw-agent-mission-control/internal/websocket"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/workhours"
 )
 
+// defaultRunTimeout applies when a run request does not specify timeout_seconds.
+const defaultRunTimeout = 2 * time.Minute
+
 type AgentHandler struct {
-	store        *store.Store
-	agentCreator *openclaw.AgentCreator
+	store         AgentHandlerStore
+	hub           *ws.Hub
+	agentCreator  *openclaw.AgentCreator
+	agentSender   openclaw.Sender
+	runEnabled    bool
+	maxRunTimeout time.Duration
+
+	registrationToken string                  // see SetRegistrationToken
+	templatePacks     *openclaw.TemplatePacks // see SetTemplatePacks
+	configReader      *openclaw.ConfigReader  // see SetConfigReader
+	queue             AgentQueueRunner        // see SetQueueRunner
+
+	memoryMu sync.Mutex // one workspace edit at a time, see PutMemory and ApplyIdentity
+
+	proposalMu sync.Mutex
+	proposals  map[string]*identityProposal // by ID, see RegenerateIdentity
 }
 
-func NewAgentHandler(s *store.Store) *AgentHandler {
+func NewAgentHandler(s AgentHandlerStore, hub *ws.Hub, agentSender openclaw.Sender, runEnabled bool, maxRunTimeout time.Duration) *AgentHandler {
 	return &AgentHandler{
-		store:        s,
-		agentCreator: openclaw.NewAgentCreator(),
+		store:         s,
+		hub:           hub,
+		agentCreator:  openclaw.NewAgentCreator(),
+		agentSender:   agentSender,
+		runEnabled:    runEnabled,
+		maxRunTimeout: maxRunTimeout,
+	}
+}
+
+// SetIdentityGenerator has new agents' identity files written by the model.
+func (h *AgentHandler) SetIdentityGenerator(g *openclaw.IdentityGenerator) {
+	h.agentCreator.SetIdentityGenerator(g)
+}
+
+// logEvent creates a persistent event record and broadcasts it via WebSocket.
+func (h *AgentHandler) logEvent(ctx context.Context, agentID, eventType, message, details string) {
+	event, err := h.store.CreateEvent(ctx, db.CreateEventParams{
+		AgentID: sql.NullString{String: agentID, Valid: agentID != ""},
+		Type:    eventType,
+		Message: message,
+		Details: sql.NullString{String: details, Valid: details != ""},
+	})
+	if err != nil {
+		log.Printf("[AgentHandler] Failed to create event (%s): %v", eventType, err)
+		return
+	}
+	if h.hub != nil {
+		h.hub.BroadcastEvent(event)
 	}
 }
 
@@ -38,6 +95,23 @@ type CreateAgentRequest struct {
 	UserMD          string   `json:"user_md"`
 	ToolsMD         string   `json:"tools_md"`
 	HeartbeatMD     string   `json:"heartbeat_md"`
+	// Template names the template pack (see /agent-templates) the workspace
+	// files, skills and model come from, where not given explicitly
+	Template string `json:"template"`
+	Locale   string `json:"locale"`
+	Timezone string `json:"timezone"` // IANA name for rendering the agent's task times
+	// WorkingHours limits when tasks are dispatched to the agent; see
+	// workhours.Schedule. Omitted = always available.
+	WorkingHours json.RawMessage `json:"working_hours"`
+	// NotificationPrefs chooses which notifications are pushed to the agent;
+	// see notifyprefs.Prefs. Omitted = push everything.
+	NotificationPrefs json.RawMessage `json:"notification_prefs"`
+	// NotificationTimeouts overrides the system's notification timeouts for
+	// the agent; see notifytimeout.Timeouts. Omitted = the system's.
+	NotificationTimeouts json.RawMessage `json:"notification_timeouts"`
+	// PublicAPIURL is the URL the agent reaches Mission Control at, when not
+	// the server's PUBLIC_API_URL, e.g. over a VPN
+	PublicAPIURL string `json:"public_api_url"`
 }
 
 type UpdateAgentRequest struct {
@@ -51,6 +125,38 @@ type UpdateAgentRequest struct {
 	UserMD          string   `json:"user_md"`
 	ToolsMD         string   `json:"tools_md"`
 	HeartbeatMD     string   `json:"heartbeat_md"`
+	// Locale sets the notification language; nil leaves it unchanged and ""
+	// resets it to the server default.
+	Locale *string `json:"locale"`
+	// Timezone sets the IANA zone the agent's task times are rendered in;
+	// nil leaves it unchanged and "" removes it.
+	Timezone *string `json:"timezone"`
+	// WorkingHours replaces the agent's working hours; omitted leaves them
+	// unchanged and null removes them.
+	WorkingHours json.RawMessage `json:"working_hours"`
+	// DeliveryMethod and CallbackURL change how notifications reach the agent
+	// (cli, gateway or http_callback); nil leaves them unchanged.
+	DeliveryMethod *string `json:"delivery_method" validate:"omitempty,oneof=cli gateway http_callback"`
+	CallbackURL    *string `json:"callback_url"`
+	// RotateCallbackSecret issues a new http_callback signing secret.
+	RotateCallbackSecret bool `json:"rotate_callback_secret"`
+	// GatewayID moves the agent to a registered gateway; nil leaves it
+	// unchanged and "" moves it back to the default gateway.
+	GatewayID *string `json:"gateway_id"`
+	// NotificationPrefs changes the keys it names and keeps the rest;
+	// omitted leaves them unchanged and null resets them to push everything.
+	NotificationPrefs json.RawMessage `json:"notification_prefs"`
+	// NotificationTimeouts replaces the agent's notification timeouts;
+	// omitted leaves them unchanged and null removes them.
+	NotificationTimeouts json.RawMessage `json:"notification_timeouts"`
+	// PublicAPIURL sets the URL the agent reaches Mission Control at; nil
+	// leaves it unchanged and "" resets it to the server's PUBLIC_API_URL.
+	PublicAPIURL *string `json:"public_api_url"`
+}
+
+type RunAgentRequest struct {
+	Prompt         string `json:"prompt" validate:"required"`
+	TimeoutSeconds int    `json:"timeout_seconds"`
 }
 
 // Handlers
@@ -73,13 +179,31 @@ func (h *AgentHandler) Get(c echo.Context) error {
 
 func (h *AgentHandler) Create(c echo.Context) error {
 	var req CreateAgentRequest
-	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	if err := bind(c, &req); err != nil {
+		return err
 	}
 
-	// Validate required fields
-	if req.Name == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "name is required")
+	if req.Locale != "" && !i18n.Supported(req.Locale) {
+		return echo.NewHTTPError(http.StatusBadRequest, "unsupported locale")
+	}
+	if req.Timezone != "" && !localtime.Valid(req.Timezone) {
+		return echo.NewHTTPError(http.StatusBadRequest, "unknown timezone")
+	}
+	workingHours, err := normalizeWorkingHours(req.WorkingHours)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid working_hours")
+	}
+	notificationPrefs, err := normalizeNotificationPrefs("", req.NotificationPrefs)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid notification_prefs")
+	}
+	notificationTimeouts, err := normalizeNotificationTimeouts(req.NotificationTimeouts)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	publicAPIURL := normalizePublicAPIURL(req.PublicAPIURL)
+	if publicAPIURL != "" && !validCallbackURL(publicAPIURL) {
+		return echo.NewHTTPError(http.StatusBadRequest, "public_api_url must be an absolute http(s) URL")
 	}
 
 	// Generate agent ID if not provided
@@ -87,9 +211,7 @@ func (h *AgentHandler) Create(c echo.Context) error {
 		req.ID = strings.ToLower(strings.ReplaceAll(req.Name, " ", "-"))
 	}
 
-	// Create agent workspace and OpenClaw configuration
-	// This will also generate identity files if description is provided
-	createdAgent, err := h.agentCreator.CreateAgent(&openclaw.CreateAgentRequest{
+	createReq := &openclaw.CreateAgentRequest{
 		ID:              req.ID,
 		Name:            req.Name,
 		Description:     req.Description,
@@ -101,7 +223,17 @@ func (h *AgentHandler) Create(c echo.Context) error {
 		UserMD:          req.UserMD,
 		ToolsMD:         req.ToolsMD,
 		HeartbeatMD:     req.HeartbeatMD,
-	})
+	}
+	if req.Template != "" {
+		if err := h.applyTemplatePack(req.Template, createReq); err != nil {
+			return err
+		}
+		req.Model = createReq.Model
+	}
+
+	// Create agent workspace and OpenClaw configuration
+	// This will also generate identity files if description is provided
+	createdAgent, err := h.agentCreator.CreateAgent(c.Request().Context(), createReq)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create agent workspace: "+err.Error())
 	}
@@ -131,19 +263,77 @@ func (h *AgentHandler) Create(c echo.Context) error {
 		UserMd:      sql.NullString{String: createdAgent.UserMD, Valid: createdAgent.UserMD != ""},
 		ToolsMd:     sql.NullString{String: createdAgent.ToolsMD, Valid: createdAgent.ToolsMD != ""},
 		HeartbeatMd: sql.NullString{String: createdAgent.HeartbeatMD, Valid: createdAgent.HeartbeatMD != ""},
+		MemoryMd:    sql.NullString{String: createdAgent.MemoryMD, Valid: createdAgent.MemoryMD != ""},
 	})
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	return c.JSON(http.StatusCreated, ToAgentResponse(agent))
+	if req.Locale != "" {
+		locale := i18n.Resolve(req.Locale, "")
+		if err := h.store.UpdateAgentLocale(c.Request().Context(), agent.ID, locale); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		agent.Locale = sql.NullString{String: locale, Valid: true}
+	}
+
+	if req.Timezone != "" {
+		timezone := strings.TrimSpace(req.Timezone)
+		if err := h.store.UpdateAgentTimezone(c.Request().Context(), agent.ID, timezone); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		agent.Timezone = sql.NullString{String: timezone, Valid: true}
+	}
+
+	if workingHours != "" {
+		if err := h.store.UpdateAgentWorkingHours(c.Request().Context(), agent.ID, workingHours); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		agent.WorkingHours = sql.NullString{String: workingHours, Valid: true}
+	}
+
+	if notificationPrefs != "" {
+		if err := h.store.UpdateAgentNotificationPrefs(c.Request().Context(), agent.ID, notificationPrefs); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		agent.NotificationPrefs = sql.NullString{String: notificationPrefs, Valid: true}
+	}
+
+	if notificationTimeouts != "" {
+		if err := h.store.UpdateAgentNotificationTimeouts(c.Request().Context(), agent.ID, notificationTimeouts); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		agent.NotificationTimeouts = sql.NullString{String: notificationTimeouts, Valid: true}
+	}
+
+	if publicAPIURL != "" {
+		if err := h.store.UpdateAgentPublicAPIURL(c.Request().Context(), agent.ID, publicAPIURL); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		agent.PublicAPIURL = sql.NullString{String: publicAPIURL, Valid: true}
+	}
+
+	resp := ToAgentResponse(agent)
+	if gen := createdAgent.IdentityGeneration; gen != nil {
+		resp.IdentityGeneration = gen
+		details, _ := json.Marshal(gen)
+		message := fmt.Sprintf("Identity of agent %s generated by the model", agent.ID)
+		switch gen.Status {
+		case openclaw.IdentityPartial:
+			message = fmt.Sprintf("Identity of agent %s partly generated by the model, partly from templates", agent.ID)
+		case openclaw.IdentityFallback:
+			message = fmt.Sprintf("Identity of agent %s from templates: %s", agent.ID, gen.Error)
+		}
+		h.logEvent(context.WithoutCancel(c.Request().Context()), agent.ID, "agent_identity_generated", message, string(details))
+	}
+	return c.JSON(http.StatusCreated, resp)
 }
 
 func (h *AgentHandler) Update(c echo.Context) error {
 	id := c.Param("id")
 	var req UpdateAgentRequest
-	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	if err := bind(c, &req); err != nil {
+		return err
 	}
 
 	// Check if agent exists
@@ -151,6 +341,40 @@ func (h *AgentHandler) Update(c echo.Context) error {
 	if err != nil {
 		return echo.NewHTTPError(http.StatusNotFound, "Agent not found")
 	}
+	if req.Locale != nil && *req.Locale != "" && !i18n.Supported(*req.Locale) {
+		return echo.NewHTTPError(http.StatusBadRequest, "unsupported locale")
+	}
+	if req.Timezone != nil && *req.Timezone != "" && !localtime.Valid(*req.Timezone) {
+		return echo.NewHTTPError(http.StatusBadRequest, "unknown timezone")
+	}
+	workingHours, err := normalizeWorkingHours(req.WorkingHours)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid working_hours")
+	}
+	notificationPrefs, err := normalizeNotificationPrefs(existing.NotificationPrefs.String, req.NotificationPrefs)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid notification_prefs")
+	}
+	notificationTimeouts, err := normalizeNotificationTimeouts(req.NotificationTimeouts)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	var publicAPIURL string
+	if req.PublicAPIURL != nil {
+		publicAPIURL = normalizePublicAPIURL(*req.PublicAPIURL)
+		if publicAPIURL != "" && !validCallbackURL(publicAPIURL) {
+			return echo.NewHTTPError(http.StatusBadRequest, "public_api_url must be an absolute http(s) URL")
+		}
+	}
+	delivery, err := resolveDelivery(existing, req.DeliveryMethod, req.CallbackURL, req.RotateCallbackSecret)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if req.GatewayID != nil && *req.GatewayID != "" {
+		if _, err := h.store.GetGateway(c.Request().Context(), *req.GatewayID); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Gateway not found")
+		}
+	}
 
 	// Use existing values if not provided in request
 	name := req.Name
@@ -190,22 +414,377 @@ func (h *AgentHandler) Update(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	return c.JSON(http.StatusOK, ToAgentResponse(agent))
+	if req.Locale != nil {
+		locale := i18n.Resolve(*req.Locale, "")
+		if err := h.store.UpdateAgentLocale(c.Request().Context(), id, locale); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		agent.Locale = sql.NullString{String: locale, Valid: locale != ""}
+	}
+
+	if req.Timezone != nil {
+		timezone := strings.TrimSpace(*req.Timezone)
+		if err := h.store.UpdateAgentTimezone(c.Request().Context(), id, timezone); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		agent.Timezone = sql.NullString{String: timezone, Valid: timezone != ""}
+	}
+
+	if len(req.WorkingHours) > 0 {
+		if err := h.store.UpdateAgentWorkingHours(c.Request().Context(), id, workingHours); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		agent.WorkingHours = sql.NullString{String: workingHours, Valid: workingHours != ""}
+	}
+
+	if req.DeliveryMethod != nil || req.CallbackURL != nil || req.RotateCallbackSecret {
+		if err := h.store.UpdateAgentDelivery(c.Request().Context(), id, delivery.Method, delivery.CallbackURL, delivery.Secret); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		agent.DeliveryMethod = sql.NullString{String: delivery.Method, Valid: delivery.Method != ""}
+		agent.CallbackURL = sql.NullString{String: delivery.CallbackURL, Valid: delivery.CallbackURL != ""}
+		agent.CallbackSecret = sql.NullString{String: delivery.Secret, Valid: delivery.Secret != ""}
+	}
+
+	if req.GatewayID != nil {
+		if err := h.store.SetAgentGateway(c.Request().Context(), id, *req.GatewayID); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		agent.GatewayID = sql.NullString{String: *req.GatewayID, Valid: *req.GatewayID != ""}
+	}
+
+	if len(req.NotificationPrefs) > 0 {
+		if err := h.store.UpdateAgentNotificationPrefs(c.Request().Context(), id, notificationPrefs); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		agent.NotificationPrefs = sql.NullString{String: notificationPrefs, Valid: notificationPrefs != ""}
+	}
+
+	if len(req.NotificationTimeouts) > 0 {
+		if err := h.store.UpdateAgentNotificationTimeouts(c.Request().Context(), id, notificationTimeouts); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		agent.NotificationTimeouts = sql.NullString{String: notificationTimeouts, Valid: notificationTimeouts != ""}
+	}
+
+	if req.PublicAPIURL != nil {
+		if err := h.store.UpdateAgentPublicAPIURL(c.Request().Context(), id, publicAPIURL); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		agent.PublicAPIURL = sql.NullString{String: publicAPIURL, Valid: publicAPIURL != ""}
+	}
+
+	resp := ToAgentResponse(agent)
+	if delivery.Issued {
+		resp.CallbackSecret = &delivery.Secret
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// normalizeWorkingHours validates a working_hours request value and returns
+// it re-encoded for storage ("" for omitted or null).
+func normalizeWorkingHours(raw json.RawMessage) (string, error) {
+	sched, err := workhours.Parse(string(raw))
+	if err != nil || sched == nil {
+		return "", err
+	}
+	encoded, err := json.Marshal(sched)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// normalizeNotificationPrefs applies a notification_prefs request value to
+// the stored preferences and returns the result for storage ("" for push
+// everything, which null resets to).
+func normalizeNotificationPrefs(stored string, raw json.RawMessage) (string, error) {
+	if string(raw) == "null" {
+		return "", nil
+	}
+	base, err := notifyprefs.Parse(stored)
+	if err != nil {
+		base = notifyprefs.Default()
+	}
+	prefs, err := notifyprefs.Merge(base, string(raw))
+	if err != nil || prefs.IsDefault() {
+		return "", err
+	}
+	encoded, err := json.Marshal(prefs)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// normalizePublicAPIURL trims a public_api_url to the URL the API is under,
+// dropping a trailing /api/v1 as the server's PUBLIC_API_URL does.
+func normalizePublicAPIURL(raw string) string {
+	return strings.TrimSuffix(strings.TrimRight(strings.TrimSpace(raw), "/"), "/api/v1")
+}
+
+// normalizeNotificationTimeouts validates a notification_timeouts request
+// value and returns it re-encoded for storage ("" for omitted, null or
+// nothing set).
+func normalizeNotificationTimeouts(raw json.RawMessage) (string, error) {
+	timeouts, err := notifytimeout.Parse(string(raw))
+	if err != nil || timeouts.Empty() {
+		return "", err
+	}
+	encoded, err := json.Marshal(timeouts)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
 }
 
+// Delete - DELETE /api/v1/agents/:id?tasks=reassign&reassign_to=other&purge_workspace=false
+// Removes the agent in three phases: it is deactivated (status deleting),
+// then its OpenClaw config entry and state and, unless purge_workspace is
+// false, its workspace are removed, and only then is it deleted. If the
+// second phase fails the agent stays deactivated, so the config sync does
+// not bring it back, and the removal is retried by deleting it again or when
+// the server starts.
+//
+// Its open tasks are moved as tasks says when it is deactivated: block (the
+// default) refuses to remove an agent with open tasks, backlog unassigns
+// them and reassign gives them to the agent reassign_to.
 func (h *AgentHandler) Delete(c echo.Context) error {
 	id := c.Param("id")
+	purgeWorkspace := true
+	if raw := c.QueryParam("purge_workspace"); raw != "" {
+		var err error
+		if purgeWorkspace, err = strconv.ParseBool(raw); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "purge_workspace must be true or false")
+		}
+	}
+	tasks := store.AgentTaskStrategy{Mode: c.QueryParam("tasks"), ReassignTo: c.QueryParam("reassign_to")}
+	switch tasks.Mode {
+	case "":
+		tasks.Mode = store.AgentTasksBlock
+	case store.AgentTasksBlock, store.AgentTasksBacklog:
+	case store.AgentTasksReassign:
+		if tasks.ReassignTo == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "reassign_to is required to reassign tasks")
+		}
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, "tasks must be block, backlog or reassign")
+	}
+	ctx := context.WithoutCancel(c.Request().Context())
 
-	// Delete from database
-	if err := h.store.DeleteAgent(c.Request().Context(), id); err != nil {
+	agent, err := h.store.GetAgent(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.NoContent(http.StatusNoContent)
+	}
+	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	// Delete agent workspace and OpenClaw configuration
-	if err := h.agentCreator.DeleteAgent(id); err != nil {
-		// Log error but don't fail the request since DB deletion succeeded
-		c.Logger().Error("Failed to delete agent workspace:", err)
+	// 1. Deactivate, moving the open tasks
+	_, moved, err := h.store.BeginAgentDeletion(ctx, id, purgeWorkspace, tasks)
+	switch {
+	case errors.Is(err, store.ErrAgentHasOpenTasks):
+		return echo.NewHTTPError(http.StatusConflict, "Agent has open tasks; delete it with tasks=backlog or tasks=reassign")
+	case errors.Is(err, store.ErrBadReassignTarget):
+		return echo.NewHTTPError(http.StatusBadRequest, "reassign_to must be another agent that is not being deleted")
+	case err != nil:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if len(moved) > 0 {
+		h.tasksMoved(ctx, id, moved, tasks)
 	}
 
+	// 2. Remove the workspace and config entry, 3. purge
+	if err := h.removeAgent(ctx, agent, purgeWorkspace); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to remove agent workspace: "+err.Error())
+	}
 	return c.NoContent(http.StatusNoContent)
 }
+
+// tasksMoved reports the open tasks of the agent id, as they were, moved
+// away from it as tasks says, and has the agent taking them look at its queue.
+func (h *AgentHandler) tasksMoved(ctx context.Context, id string, moved []db.Task, tasks store.AgentTaskStrategy) {
+	ids := make([]string, 0, len(moved))
+	for _, t := range moved {
+		ids = append(ids, t.ID)
+		if h.hub == nil {
+			continue
+		}
+		// As ReassignOpenTasksByAgent and UnassignOpenTasksByAgent set it
+		status := "backlog"
+		if tasks.Mode == store.AgentTasksReassign {
+			status = t.Status.String
+			switch status {
+			case "planning", "discussing", "executing", "verifying":
+				status = "queued"
+			}
+		}
+		h.hub.BroadcastTaskStatus(t.ID, status, progressFraction(t))
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"tasks": ids, "strategy": tasks.Mode, "reassign_to": tasks.ReassignTo,
+	})
+	message := fmt.Sprintf("%d open tasks of agent %s moved to the backlog", len(moved), id)
+	if tasks.Mode == store.AgentTasksReassign {
+		message = fmt.Sprintf("%d open tasks of agent %s reassigned to %s", len(moved), id, tasks.ReassignTo)
+		if h.queue != nil {
+			go h.queue.ProcessAgentQueue(context.Background(), tasks.ReassignTo)
+		}
+	}
+	h.logEvent(ctx, id, "agent_tasks_moved", message, string(details))
+}
+
+// SetQueueRunner has agents that are given the tasks of a removed agent
+// dequeue them at once.
+func (h *AgentHandler) SetQueueRunner(q AgentQueueRunner) {
+	h.queue = q
+}
+
+// SetConfigReader lets agent removals skip openclaw agents delete for agents
+// no longer in the OpenClaw config.
+func (h *AgentHandler) SetConfigReader(r *openclaw.ConfigReader) {
+	h.configReader = r
+}
+
+// registered reports whether the agent is in the OpenClaw config; true if
+// that cannot be told.
+func (h *AgentHandler) registered(id string) bool {
+	if h.configReader == nil {
+		return true
+	}
+	agents, err := h.configReader.ReadAgents()
+	if err != nil {
+		return true
+	}
+	for _, a := range agents {
+		if a.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// removeAgent runs the last two phases of an agent's removal, begun with
+// BeginAgentDeletion: the agent's OpenClaw config entry, state and, if
+// purgeWorkspace, workspace are removed (self-registered agents have none),
+// then the agent is purged. A failure is recorded on the removal.
+func (h *AgentHandler) removeAgent(ctx context.Context, agent db.Agent, purgeWorkspace bool) error {
+	err := func() error {
+		if !agent.ManagedExternally {
+			if err := h.agentCreator.RemoveAgent(agent.ID, h.registered(agent.ID), purgeWorkspace); err != nil {
+				return err
+			}
+		}
+		return h.store.PurgeAgent(ctx, agent.ID)
+	}()
+	if err != nil {
+		log.Printf("[AgentHandler] Removing agent %s: %v", agent.ID, err)
+		if recordErr := h.store.RecordAgentDeletionFailure(ctx, agent.ID, err.Error()); recordErr != nil {
+			log.Printf("[AgentHandler] Recording failed removal of agent %s: %v", agent.ID, recordErr)
+		}
+		details, _ := json.Marshal(map[string]interface{}{"purge_workspace": purgeWorkspace, "error": err.Error()})
+		h.logEvent(ctx, agent.ID, "agent_deletion_failed",
+			fmt.Sprintf("Removing agent %s failed; it stays deactivated until a retry succeeds", agent.ID), string(details))
+	}
+	return err
+}
+
+// ResumeDeletions retries the agent removals a failure or restart left
+// unfinished.
+func (h *AgentHandler) ResumeDeletions(ctx context.Context) {
+	deletions, err := h.store.ListAgentDeletions(ctx)
+	if err != nil {
+		log.Printf("[AgentHandler] Listing agent removals: %v", err)
+		return
+	}
+	for _, d := range deletions {
+		agent, err := h.store.GetAgent(ctx, d.AgentID)
+		if errors.Is(err, sql.ErrNoRows) {
+			agent = db.Agent{ID: d.AgentID} // only the removal left to purge
+		} else if err != nil {
+			log.Printf("[AgentHandler] Resuming removal of agent %s: %v", d.AgentID, err)
+			continue
+		}
+		if err := h.removeAgent(ctx, agent, d.PurgeWorkspace); err == nil {
+			log.Printf("[AgentHandler] Finished removal of agent %s", d.AgentID)
+		}
+	}
+}
+
+// RunCommand - POST /api/v1/agents/:id/run
+// Sends a one-shot instruction to the agent without creating a task. The run
+// happens in the background: output lines are streamed as agent.run WebSocket
+// messages and the prompt/result are recorded as events.
+func (h *AgentHandler) RunCommand(c echo.Context) error {
+	if !h.runEnabled {
+		return echo.NewHTTPError(http.StatusForbidden, "Agent run console is disabled (set AGENT_RUN_ENABLED=true)")
+	}
+	if h.agentSender == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "Agent sender not configured")
+	}
+
+	id := c.Param("id")
+	ctx := c.Request().Context()
+
+	agent, err := h.store.GetAgent(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Agent not found")
+	}
+	if !openclaw.CanRun(agent.DeliveryMethod.String) {
+		return echo.NewHTTPError(http.StatusConflict,
+			fmt.Sprintf("Agent %s is reached through %s, which returns no reply; one-shot runs need cli or http_callback delivery", id, agent.DeliveryMethod.String))
+	}
+
+	var req RunAgentRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+
+	timeout := defaultRunTimeout
+	if req.TimeoutSeconds > 0 {
+		timeout = time.Duration(req.TimeoutSeconds) * time.Second
+	}
+	if h.maxRunTimeout > 0 && timeout > h.maxRunTimeout {
+		timeout = h.maxRunTimeout
+	}
+
+	runID := uuid.New().String()
+	startDetails, _ := json.Marshal(map[string]interface{}{
+		"run_id":          runID,
+		"prompt":          req.Prompt,
+		"timeout_seconds": int(timeout.Seconds()),
+	})
+	h.logEvent(ctx, id, "agent_run_started",
+		fmt.Sprintf("One-shot instruction sent to agent %s", id), string(startDetails))
+
+	go func() {
+		bgCtx := context.Background()
+		output, err := h.agentSender.For(ctx).RunCommand(bgCtx, id, req.Prompt, timeout, func(line string) {
+			if h.hub != nil {
+				h.hub.BroadcastAgentRun(runID, id, "running", line)
+			}
+		})
+
+		status := "completed"
+		message := fmt.Sprintf("Agent %s completed one-shot instruction", id)
+		result := map[string]interface{}{"run_id": runID, "output": output}
+		if err != nil {
+			status = "failed"
+			message = fmt.Sprintf("Agent %s one-shot instruction failed: %s", id, err.Error())
+			result["error"] = err.Error()
+		}
+		details, _ := json.Marshal(result)
+		h.logEvent(bgCtx, id, "agent_run_"+status, message, string(details))
+		if h.hub != nil {
+			h.hub.BroadcastAgentRun(runID, id, status, "")
+		}
+	}()
+
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"run_id":          runID,
+		"agent_id":        id,
+		"status":          "running",
+		"timeout_seconds": int(timeout.Seconds()),
+	})
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/availability"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/ratelimit"
+	ws "github.com/abelkuruvilla/claw-agent-mission-control/internal/websocket"
+)
+
+// AgentQueueRunner dequeues work for an agent that has become free.
+// TaskHandler implements it.
+type AgentQueueRunner interface {
+	ProcessAgentQueue(ctx context.Context, agentID string)
+	IsAgentBusy(ctx context.Context, agentID string) bool
+}
+
+// AvailabilityHandler receives agent heartbeats and reports what the
+// availability tracker knows about each agent, and which agents and models
+// are cooling down from a rate limit.
+type AvailabilityHandler struct {
+	store      AvailabilityHandlerStore
+	hub        *ws.Hub
+	tracker    *availability.Tracker
+	queue      AgentQueueRunner
+	rateLimits *ratelimit.Limiter
+}
+
+func NewAvailabilityHandler(s AvailabilityHandlerStore, hub *ws.Hub, tracker *availability.Tracker, queue AgentQueueRunner) *AvailabilityHandler {
+	return &AvailabilityHandler{
+		store:   s,
+		hub:     hub,
+		tracker: tracker,
+		queue:   queue,
+	}
+}
+
+// SetRateLimiter sets the limiter whose cool-downs are reported.
+func (h *AvailabilityHandler) SetRateLimiter(l *ratelimit.Limiter) {
+	h.rateLimits = l
+}
+
+type HeartbeatRequest struct {
+	Status string `json:"status" validate:"required,oneof=idle busy"`
+	TaskID string `json:"task_id,omitempty"` // task being worked on, when busy
+}
+
+// AvailabilityResponse is the tracker's view of an agent next to the task
+// count it overrides, so drift between the two is visible.
+type AvailabilityResponse struct {
+	availability.Status
+	ActiveTasks int64 `json:"active_tasks"`
+	Busy        bool  `json:"busy"` // the decision dispatch uses
+	// RateLimitedUntil is set while dispatch to the agent backs off from a
+	// rate limit of the agent or its model
+	RateLimitedUntil *time.Time `json:"rate_limited_until,omitempty"`
+}
+
+func (h *AvailabilityHandler) response(ctx context.Context, agentID string) AvailabilityResponse {
+	count, err := h.store.CountActiveTasksByAgent(ctx, agentID)
+	if err != nil {
+		log.Printf("[AvailabilityHandler] Error counting active tasks for agent %s: %v", agentID, err)
+	}
+	resp := AvailabilityResponse{
+		Status:      h.tracker.Status(agentID),
+		ActiveTasks: count,
+		Busy:        h.queue.IsAgentBusy(ctx, agentID),
+	}
+	if until, limited := h.rateLimits.BlockedUntil(agentID); limited {
+		resp.RateLimitedUntil = &until
+	}
+	return resp
+}
+
+// Heartbeat records an agent's report of whether it is working. An idle
+// heartbeat also dispatches the agent's next queued task, so work does not
+// wait for the periodic queue check.
+func (h *AvailabilityHandler) Heartbeat(c echo.Context) error {
+	agentID := c.Param("id")
+	ctx := c.Request().Context()
+
+	var req HeartbeatRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+	if _, err := h.store.GetAgent(ctx, agentID); err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Agent not found")
+	}
+
+	h.tracker.Heartbeat(agentID, req.Status == availability.StateBusy, req.TaskID)
+	log.Printf("[AvailabilityHandler] Heartbeat from agent %s: %s", agentID, req.Status)
+	if h.hub != nil {
+		var taskID *string
+		if req.TaskID != "" {
+			taskID = &req.TaskID
+		}
+		h.hub.BroadcastAgentHeartbeat(agentID, req.Status, taskID)
+	}
+
+	resp := h.response(ctx, agentID)
+	if !resp.Busy {
+		// Detach from the request but keep its dry-run marker for the dequeued task
+		queueCtx := context.Background()
+		if openclaw.IsDryRun(ctx) {
+			queueCtx = openclaw.WithDryRun(queueCtx)
+		}
+		go h.queue.ProcessAgentQueue(queueCtx, agentID)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Get returns the availability of one agent.
+func (h *AvailabilityHandler) Get(c echo.Context) error {
+	agentID := c.Param("id")
+	ctx := c.Request().Context()
+	if _, err := h.store.GetAgent(ctx, agentID); err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Agent not found")
+	}
+	return c.JSON(http.StatusOK, h.response(ctx, agentID))
+}
+
+// List returns the availability of every agent.
+func (h *AvailabilityHandler) List(c echo.Context) error {
+	ctx := c.Request().Context()
+	agents, err := h.store.ListAgents(ctx)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	result := make([]AvailabilityResponse, len(agents))
+	for i, a := range agents {
+		result[i] = h.response(ctx, a.ID)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// RateLimits - GET /api/v1/agents/rate-limits
+// Returns the cool-downs in effect, per agent (agent:<id>) and per model
+// (model:<name>).
+func (h *AvailabilityHandler) RateLimits(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.rateLimits.Cooldowns())
+}
+
+// ResetRateLimits - DELETE /api/v1/agents/rate-limits
+// Ends every cool-down, e.g. after the provider's limit was raised; dispatch
+// resumes with the next queue check.
+func (h *AvailabilityHandler) ResetRateLimits(c echo.Context) error {
+	h.rateLimits.Reset()
+	log.Printf("[AvailabilityHandler] Rate limit cool-downs reset")
+	return c.NoContent(http.StatusNoContent)
+}
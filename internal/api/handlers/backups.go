@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/objectstore"
+	ws "github.com/abelkuruvilla/claw-agent-mission-control/internal/websocket"
+)
+
+// backupPrefix is the object storage folder database backups are kept in.
+const backupPrefix = "backups/"
+
+// BackupHandler backs the database up to object storage.
+type BackupHandler struct {
+	store   BackupHandlerStore
+	objects objectstore.Store
+	hub     *ws.Hub
+}
+
+func NewBackupHandler(s BackupHandlerStore, objects objectstore.Store, hub *ws.Hub) *BackupHandler {
+	return &BackupHandler{store: s, objects: objects, hub: hub}
+}
+
+// Create - POST /api/v1/admin/backups
+// Stores a consistent copy of the database as
+// backups/mission-control-<time>.db and returns a link to download it.
+func (h *BackupHandler) Create(c echo.Context) error {
+	ttl, err := urlTTL(c)
+	if err != nil {
+		return err
+	}
+	ctx := c.Request().Context()
+
+	tmpDir, err := os.MkdirTemp("", "mc-backup-")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	defer os.RemoveAll(tmpDir)
+	tmp := filepath.Join(tmpDir, "backup.db")
+	if err := h.store.Backup(ctx, tmp); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Backup failed: "+err.Error())
+	}
+	data, err := os.ReadFile(tmp)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	now := time.Now().UTC()
+	key := backupPrefix + "mission-control-" + now.Format("20060102T150405Z") + ".db"
+	if err := h.objects.Put(ctx, key, data, "application/vnd.sqlite3"); err != nil {
+		return echo.NewHTTPError(http.StatusBadGateway, "Storing backup failed: "+err.Error())
+	}
+	resp, err := toObjectResponse(h.objects, objectstore.Object{Key: key, Size: int64(len(data)), ModifiedAt: now}, ttl)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	log.Printf("[BackupHandler] Backed up the database (%d bytes) to %s in %s", len(data), key, h.objects)
+	h.logEvent(ctx, "backup_created", fmt.Sprintf("Database backed up to %s (%d bytes)", key, len(data)),
+		fmt.Sprintf(`{"key":%q,"size":%d,"storage":%q}`, key, len(data), h.objects.String()))
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// List - GET /api/v1/admin/backups
+// Lists the backups in object storage, with download links.
+func (h *BackupHandler) List(c echo.Context) error {
+	ttl, err := urlTTL(c)
+	if err != nil {
+		return err
+	}
+	objects, err := h.objects.List(c.Request().Context(), backupPrefix)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadGateway, err.Error())
+	}
+	result := make([]ObjectResponse, 0, len(objects))
+	for _, o := range objects {
+		resp, err := toObjectResponse(h.objects, o, ttl)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		result = append(result, resp)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *BackupHandler) logEvent(ctx context.Context, eventType, message, details string) {
+	event, err := h.store.CreateEvent(ctx, db.CreateEventParams{
+		Type:    eventType,
+		Message: message,
+		Details: sql.NullString{String: details, Valid: details != ""},
+	})
+	if err != nil {
+		log.Printf("[BackupHandler] Failed to create event (%s): %v", eventType, err)
+		return
+	}
+	if h.hub != nil {
+		h.hub.BroadcastEvent(event)
+	}
+}
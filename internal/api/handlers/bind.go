@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// bind binds the request into i and validates it against the validate tags
+// of its fields (see the validation package). It returns a 400 for a body
+// that does not parse, a 413 for one over the body limit (see
+// middleware.BodyLimit), and the field errors of one that is invalid.
+func bind(c echo.Context, i interface{}) error {
+	if err := c.Bind(i); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return echo.ErrStatusRequestEntityTooLarge
+		}
+		var he *echo.HTTPError
+		if errors.As(err, &he) {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprint(he.Message))
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return c.Validate(i)
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/calendar"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+)
+
+// CalendarHandler serves an iCalendar feed of when agent work is due, for
+// calendar apps to subscribe to.
+type CalendarHandler struct {
+	store CalendarHandlerStore
+	token string // empty disables the feed
+}
+
+func NewCalendarHandler(s CalendarHandlerStore, token string) *CalendarHandler {
+	return &CalendarHandler{store: s, token: token}
+}
+
+// Feed - GET /api/v1/calendar.ics?token=
+// Lists tasks waiting on a scheduled start or a retry. The token goes in the
+// query because calendar apps subscribe by URL and cannot send headers.
+func (h *CalendarHandler) Feed(c echo.Context) error {
+	if h.token == "" {
+		return echo.NewHTTPError(http.StatusForbidden, "Calendar feed is disabled")
+	}
+	if subtle.ConstantTimeCompare([]byte(c.QueryParam("token")), []byte(h.token)) != 1 {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Invalid calendar token")
+	}
+
+	tasks, err := h.store.ListCalendarTasks(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	var events []calendar.Event
+	for _, task := range tasks {
+		events = append(events, calendarEvents(task)...)
+	}
+
+	c.Response().Header().Set("Content-Disposition", `inline; filename="calendar.ics"`)
+	return c.Blob(http.StatusOK, "text/calendar; charset=utf-8",
+		calendar.Render("Mission Control", events, time.Now()))
+}
+
+// calendarEvents returns the events of task: its scheduled start and its
+// next retry, whichever are set.
+func calendarEvents(task db.Task) []calendar.Event {
+	label := task.Title
+	if task.ShortID.Valid && task.ShortID.String != "" {
+		label = fmt.Sprintf("%s %s", task.ShortID.String, task.Title)
+	}
+	details := []string{"Task: " + task.ID, "Status: " + task.Status.String}
+	if task.AgentID.Valid && task.AgentID.String != "" {
+		details = append(details, "Agent: "+task.AgentID.String)
+	}
+	if task.Description.Valid && task.Description.String != "" {
+		details = append(details, "", task.Description.String)
+	}
+
+	var events []calendar.Event
+	if task.ScheduledAt.Valid {
+		events = append(events, calendar.Event{
+			UID:         task.ID + "-scheduled@mission-control",
+			Summary:     "Scheduled: " + label,
+			Description: strings.Join(details, "\n"),
+			Start:       task.ScheduledAt.Time,
+		})
+	}
+	if task.RetryAt.Valid {
+		events = append(events, calendar.Event{
+			UID:         task.ID + "-retry@mission-control",
+			Summary:     "Retry: " + label,
+			Description: strings.Join(details, "\n"),
+			Start:       task.RetryAt.Time,
+		})
+	}
+	return events
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/api/apierror"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+)
+
+// Change request statuses.
+const (
+	changeRequestOpen      = "open"
+	changeRequestAddressed = "addressed"
+)
+
+type ChangeRequestResponse struct {
+	ID          string  `json:"id"`
+	TaskID      string  `json:"task_id"`
+	Requester   string  `json:"requester"`
+	Content     string  `json:"content"`
+	Status      string  `json:"status"` // open | addressed
+	Commit      *string `json:"commit,omitempty"`
+	Resolution  *string `json:"resolution,omitempty"`
+	AddressedBy *string `json:"addressed_by,omitempty"`
+	CreatedAt   string  `json:"created_at"`
+	AddressedAt string  `json:"addressed_at,omitempty"`
+}
+
+func toChangeRequestResponse(cr db.ChangeRequest) ChangeRequestResponse {
+	return ChangeRequestResponse{
+		ID:          cr.ID,
+		TaskID:      cr.TaskID,
+		Requester:   cr.Requester,
+		Content:     cr.Content,
+		Status:      cr.Status,
+		Commit:      strPtr(cr.CommitSha.String, cr.CommitSha.Valid),
+		Resolution:  strPtr(cr.Resolution.String, cr.Resolution.Valid),
+		AddressedBy: strPtr(cr.AddressedBy.String, cr.AddressedBy.Valid),
+		CreatedAt:   nullTimeToString(cr.CreatedAt),
+		AddressedAt: nullTimeToString(cr.AddressedAt),
+	}
+}
+
+// ResolveChangeRequestRequest says how a change request was addressed.
+type ResolveChangeRequestRequest struct {
+	Commit      string `json:"commit"`
+	Resolution  string `json:"resolution"`
+	AddressedBy string `json:"addressed_by"` // defaults to the task's agent
+}
+
+// checkChangeRequestsResolved refuses to let taskID be done while it has
+// open change requests.
+func (h *TaskHandler) checkChangeRequestsResolved(ctx context.Context, taskID string) error {
+	open, err := h.store.CountOpenChangeRequests(ctx, taskID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if open > 0 {
+		return apierror.New(http.StatusConflict, apierror.InvalidTransition,
+			fmt.Sprintf("Task has %d open change request(s); resolve them before marking it done", open))
+	}
+	return nil
+}
+
+// ListChangeRequests - GET /api/v1/tasks/:id/change-requests?status=open
+// Returns the task's change requests, oldest first.
+func (h *TaskHandler) ListChangeRequests(c echo.Context) error {
+	ctx := c.Request().Context()
+	task, err := h.store.GetTask(ctx, c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+	}
+	status := c.QueryParam("status")
+	if status != "" && status != changeRequestOpen && status != changeRequestAddressed {
+		return echo.NewHTTPError(http.StatusBadRequest, "status must be open or addressed")
+	}
+
+	changeRequests, err := h.store.ListChangeRequestsByTask(ctx, task.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	responses := make([]ChangeRequestResponse, 0, len(changeRequests))
+	for _, cr := range changeRequests {
+		if status == "" || cr.Status == status {
+			responses = append(responses, toChangeRequestResponse(cr))
+		}
+	}
+	return c.JSON(http.StatusOK, responses)
+}
+
+// ResolveChangeRequest - POST /api/v1/tasks/:id/change-requests/:crId/resolve
+// Marks a change request addressed, optionally naming the commit that did.
+func (h *TaskHandler) ResolveChangeRequest(c echo.Context) error {
+	var req ResolveChangeRequestRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	task, err := h.store.GetTask(ctx, c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+	}
+	cr, err := h.store.GetChangeRequest(ctx, c.Param("crId"))
+	if err != nil || cr.TaskID != task.ID {
+		return echo.NewHTTPError(http.StatusNotFound, "Change request not found")
+	}
+	if cr.Status != changeRequestOpen {
+		return apierror.New(http.StatusConflict, apierror.InvalidTransition, "Change request is already addressed")
+	}
+	if req.AddressedBy == "" {
+		req.AddressedBy = task.AgentID.String
+	}
+
+	cr, err = h.store.ResolveChangeRequest(ctx, cr.ID, req.Commit, req.Resolution, req.AddressedBy)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	message := "Change request addressed"
+	if req.Commit != "" {
+		message = fmt.Sprintf("Change request addressed in %s", req.Commit)
+	}
+	details, _ := json.Marshal(map[string]string{"change_request_id": cr.ID, "commit": req.Commit})
+	h.logEvent(ctx, task.ID, req.AddressedBy, "change_request_addressed", message, string(details))
+
+	return c.JSON(http.StatusOK, toChangeRequestResponse(cr))
+}
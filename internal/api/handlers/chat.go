@@ -9,17 +9,17 @@ import (
 
 	"github.com/labstack/echo/v4"
 
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/api/apierror"
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
-	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
 )
 
 type ChatHandler struct {
-	store  *store.Store
-	client *openclaw.Client
+	store  ChatHandlerStore
+	client openclaw.Gateway
 }
 
-func NewChatHandler(s *store.Store, client *openclaw.Client) *ChatHandler {
+func NewChatHandler(s ChatHandlerStore, client openclaw.Gateway) *ChatHandler {
 	return &ChatHandler{
 		store:  s,
 		client: client,
@@ -67,8 +67,8 @@ func (h *ChatHandler) StartSession(c echo.Context) error {
 	}
 
 	var req StartSessionRequest
-	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	if err := bind(c, &req); err != nil {
+		return err
 	}
 
 	// The session key for the agent's direct session
@@ -244,12 +244,8 @@ func (h *ChatHandler) SendMessage(c echo.Context) error {
 	agentID := c.Param("id")
 
 	var req SendMessageRequest
-	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
-	}
-
-	if req.Content == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "content is required")
+	if err := bind(c, &req); err != nil {
+		return err
 	}
 
 	// Verify session exists and belongs to agent
@@ -263,7 +259,7 @@ func (h *ChatHandler) SendMessage(c echo.Context) error {
 	}
 
 	if session.Status != "active" {
-		return echo.NewHTTPError(http.StatusBadRequest, "Session is not active")
+		return apierror.New(http.StatusBadRequest, apierror.SessionEnded, "Session is not active")
 	}
 
 	// Save user message to database
@@ -350,7 +346,7 @@ func ToChatSessionResponse(s db.ChatSession) ChatSessionResponse {
 		ID:           s.ID,
 		AgentID:      s.AgentID,
 		Status:       s.Status,
-		StartedAt:    s.StartedAt.Time.Format("2006-01-02T15:04:05Z"),
+		StartedAt:    s.StartedAt.Time.UTC().Format("2006-01-02T15:04:05Z"),
 		MessageCount: messageCount,
 	}
 
@@ -359,7 +355,7 @@ func ToChatSessionResponse(s db.ChatSession) ChatSessionResponse {
 	}
 
 	if s.EndedAt.Valid {
-		endedAt := s.EndedAt.Time.Format("2006-01-02T15:04:05Z")
+		endedAt := s.EndedAt.Time.UTC().Format("2006-01-02T15:04:05Z")
 		resp.EndedAt = &endedAt
 	}
 
@@ -380,7 +376,7 @@ func ToChatMessageResponse(m db.ChatMessage) ChatMessageResponse {
 		SessionID: m.SessionID,
 		Role:      m.Role,
 		Content:   m.Content,
-		CreatedAt: m.CreatedAt.Time.Format("2006-01-02T15:04:05Z"),
+		CreatedAt: m.CreatedAt.Time.UTC().Format("2006-01-02T15:04:05Z"),
 	}
 }
 
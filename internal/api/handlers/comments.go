@@ -1,25 +1,37 @@
 package handlers
 
 import (
+	"fmt"
+	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/labstack/echo/v4"
 	"github.com/google/uuid"
 
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+	ws "github.com/abelkuruvilla/claw-agent-mission-control/internal/websocket"
 )
 
 type CommentHandler struct {
-	store *store.Store
+	store    CommentHandlerStore
+	hub      *ws.Hub
+	mentions MentionNotifier // nil = mentions notify no one
 }
 
-func NewCommentHandler(s *store.Store) *CommentHandler {
+func NewCommentHandler(s CommentHandlerStore, hub *ws.Hub) *CommentHandler {
 	return &CommentHandler{
 		store: s,
+		hub:   hub,
 	}
 }
 
+// SetMentionNotifier sets who tells agents mentioned in comments.
+func (h *CommentHandler) SetMentionNotifier(n MentionNotifier) {
+	h.mentions = n
+}
+
 // Request types
 type CreateCommentRequest struct {
 	Author  string `json:"author" validate:"required"`
@@ -33,26 +45,64 @@ type CommentResponse struct {
 	Author    string `json:"author"`
 	Content   string `json:"content"`
 	CreatedAt string `json:"created_at"`
+	// Truncated is set when content is only the start of a long comment;
+	// GET /comments/:id has all of it
+	Truncated bool `json:"truncated"`
 }
 
-// List all comments for a task
+// maxCommentPage caps limit on the comment list.
+const maxCommentPage = 500
+
+// List the comments for a task, oldest first; ?order=desc for newest first,
+// ?author= for one author's, ?limit= and ?offset= for a page. The total,
+// before paging, is in X-Total-Count. Long comments are listed by their
+// preview unless ?full=true.
 func (h *CommentHandler) ListByTask(c echo.Context) error {
 	taskID := c.Param("id")
 
+	page := store.CommentPage{Author: c.QueryParam("author")}
+	if raw := c.QueryParam("limit"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n < 1 || n > maxCommentPage {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxCommentPage))
+		}
+		page.Limit = n
+	}
+	if raw := c.QueryParam("offset"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "offset must be 0 or more")
+		}
+		page.Offset = n
+	}
+	switch c.QueryParam("order") {
+	case "", "asc":
+	case "desc":
+		page.NewestFirst = true
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, "order must be asc or desc")
+	}
+
 	// Verify task exists
 	_, err := h.store.GetTask(c.Request().Context(), taskID)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusNotFound, "Task not found")
 	}
 
-	comments, err := h.store.ListCommentsByTask(c.Request().Context(), taskID)
+	comments, total, err := h.store.ListCommentsPage(c.Request().Context(), taskID, page)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
+	c.Response().Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
 
+	full := c.QueryParam("full") == "true"
 	responses := make([]CommentResponse, len(comments))
 	for i, comment := range comments {
-		responses[i] = toCommentResponse(comment)
+		if full {
+			responses[i] = toCommentResponse(comment)
+		} else {
+			responses[i] = toCommentListResponse(comment)
+		}
 	}
 
 	return c.JSON(http.StatusOK, responses)
@@ -63,14 +113,14 @@ func (h *CommentHandler) Create(c echo.Context) error {
 	taskID := c.Param("id")
 	
 	// Verify task exists
-	_, err := h.store.GetTask(c.Request().Context(), taskID)
+	task, err := h.store.GetTask(c.Request().Context(), taskID)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusNotFound, "Task not found")
 	}
 
 	var req CreateCommentRequest
-	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	if err := bind(c, &req); err != nil {
+		return err
 	}
 
 	// Generate UUID for new comment
@@ -85,10 +135,24 @@ func (h *CommentHandler) Create(c echo.Context) error {
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
+	syncTaskLinks(c.Request().Context(), h.store, taskID, store.LinkFromComment, comment.ID, comment.Content)
+	broadcastComment(h.hub, comment)
+	if h.mentions != nil {
+		h.mentions.NotifyMentions(c.Request().Context(), task, store.LinkFromComment, comment.ID, comment.Author, comment.Content, "")
+	}
 
 	return c.JSON(http.StatusCreated, toCommentResponse(comment))
 }
 
+// Get a comment, whole
+func (h *CommentHandler) Get(c echo.Context) error {
+	comment, err := h.store.GetComment(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Comment not found")
+	}
+	return c.JSON(http.StatusOK, toCommentResponse(comment))
+}
+
 // Delete a comment
 func (h *CommentHandler) Delete(c echo.Context) error {
 	id := c.Param("id")
@@ -102,6 +166,9 @@ func (h *CommentHandler) Delete(c echo.Context) error {
 	if err := h.store.DeleteComment(c.Request().Context(), id); err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
+	if err := h.store.DeleteTaskLinksBySource(c.Request().Context(), store.LinkFromComment, id); err != nil {
+		log.Printf("[CommentHandler] Error removing links from comment %s: %v", id, err)
+	}
 	
 	return c.NoContent(http.StatusNoContent)
 }
@@ -116,3 +183,23 @@ func toCommentResponse(comment db.Comment) CommentResponse {
 		CreatedAt: nullTimeToString(comment.CreatedAt),
 	}
 }
+
+// broadcastComment broadcasts a new comment as it is listed.
+func broadcastComment(hub *ws.Hub, comment db.Comment) {
+	if hub == nil {
+		return
+	}
+	resp := toCommentListResponse(comment)
+	hub.BroadcastComment(resp.TaskID, resp.ID, resp.Author, resp.Content, resp.Truncated)
+}
+
+// toCommentListResponse is toCommentResponse with a long comment cut to its
+// preview, for lists.
+func toCommentListResponse(comment db.Comment) CommentResponse {
+	resp := toCommentResponse(comment)
+	if comment.Preview.Valid {
+		resp.Content = comment.Preview.String
+		resp.Truncated = true
+	}
+	return resp
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// wantsCSV reports whether the request asks for CSV (?format=csv) rather
+// than JSON. Any other format is a 400.
+func wantsCSV(c echo.Context) (bool, error) {
+	switch c.QueryParam("format") {
+	case "", "json":
+		return false, nil
+	case "csv":
+		return true, nil
+	}
+	return false, echo.NewHTTPError(http.StatusBadRequest, "format must be json or csv")
+}
+
+// writeCSV responds with the CSV write produces, as a download named after
+// name and today's date, e.g. tasks-2026-02-08.csv.
+func writeCSV(c echo.Context, name string, write func(*csv.Writer) error) error {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	err := write(w)
+	if err == nil {
+		w.Flush()
+		err = w.Error()
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	filename := fmt.Sprintf("%s-%s.csv", name, time.Now().UTC().Format("2006-01-02"))
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	return c.Blob(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// csvFloat formats a rate or average for a CSV cell.
+func csvFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/dbmaint"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+)
+
+// DBMaintHandler shows the size of the database and optimizes it.
+type DBMaintHandler struct {
+	optimizer *dbmaint.Optimizer
+}
+
+func NewDBMaintHandler(optimizer *dbmaint.Optimizer) *DBMaintHandler {
+	return &DBMaintHandler{optimizer: optimizer}
+}
+
+type DBStatusResponse struct {
+	store.DBStats
+	Schedule string `json:"schedule,omitempty"` // DB_OPTIMIZE_INTERVAL, e.g. "168h0m0s"
+}
+
+// Status - GET /api/v1/admin/db
+func (h *DBMaintHandler) Status(c echo.Context) error {
+	stats, err := h.optimizer.Stats(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	resp := DBStatusResponse{DBStats: stats}
+	if interval := h.optimizer.Interval(); interval > 0 {
+		resp.Schedule = interval.String()
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Optimize - POST /api/v1/admin/db/optimize
+// Checkpoints the WAL, analyzes and vacuums now, without waiting for the
+// schedule.
+func (h *DBMaintHandler) Optimize(c echo.Context) error {
+	report, err := h.optimizer.Run(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Database optimize failed: "+err.Error())
+	}
+	return c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+)
+
+// maxAncestors bounds the walk up a subtask's parents, in case of a cycle.
+const maxAncestors = 100
+
+// Delegation limits a subtask can hit.
+const (
+	limitDepth    = "depth"               // how deep subtasks may nest
+	limitSubtasks = "concurrent_subtasks" // how many unfinished subtasks a task may have
+)
+
+// delegationViolation is a subtask refused by a delegation limit: Max, set on
+// Source ("task", "project" or "default"), against the Current depth of the
+// subtask or number of unfinished subtasks of its parent.
+type delegationViolation struct {
+	Limit    string `json:"limit"`
+	Max      int64  `json:"max"`
+	Current  int64  `json:"current"`
+	Source   string `json:"source"`
+	SourceID string `json:"source_id,omitempty"`
+	Error    string `json:"-"` // the message of the 422
+}
+
+// SetDelegationDefaults sets the delegation limits of tasks whose project
+// sets none: how deep subtasks may nest and how many unfinished subtasks a
+// task may have. 0 means unlimited.
+func (h *TaskHandler) SetDelegationDefaults(maxDepth, maxSubtasks int) {
+	h.maxSubtaskDepth = maxDepth
+	h.maxSubtasks = maxSubtasks
+}
+
+// checkDelegationRequest validates the delegation limits of a request: each
+// must be 0 or more, or -1 to remove it if removable.
+func checkDelegationRequest(removable bool, limits ...*int) error {
+	for _, l := range limits {
+		if l != nil && *l < 0 && !(removable && *l == -1) {
+			if removable {
+				return echo.NewHTTPError(http.StatusBadRequest, "Delegation limits must be 0 or more, or -1 to remove them")
+			}
+			return echo.NewHTTPError(http.StatusBadRequest, "Delegation limits must be 0 or more")
+		}
+	}
+	return nil
+}
+
+// limitParam returns the stored value of a delegation limit given as v in a
+// request: current if v is nil, no limit if it is -1.
+func limitParam(current sql.NullInt64, v *int) sql.NullInt64 {
+	switch {
+	case v == nil:
+		return current
+	case *v < 0:
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(*v), Valid: true}
+}
+
+// nullLimit returns a stored delegation limit for a response.
+func nullLimit(l sql.NullInt64) *int {
+	if !l.Valid {
+		return nil
+	}
+	v := int(l.Int64)
+	return &v
+}
+
+// checkDelegationLimits returns the limit a new subtask of parent in
+// projectID (the parent's project if empty) would exceed, if any. Limits set
+// on a task take precedence over its project's, which take precedence over
+// the server defaults: for depth, the parent's and its ancestors' limits
+// apply, each counting from its own task; for fan-out, the parent's.
+func (h *TaskHandler) checkDelegationLimits(ctx context.Context, parent db.Task, projectID string) (*delegationViolation, error) {
+	ancestors, err := h.taskAncestors(ctx, parent)
+	if err != nil {
+		return nil, err
+	}
+	if projectID == "" {
+		projectID = parent.ProjectID.String
+	}
+	var project db.Project
+	if projectID != "" {
+		if project, err = h.store.GetProject(ctx, projectID); err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+	}
+
+	// The subtask's depth below ancestors[i] is i+1; below the top-level
+	// task, len(ancestors)
+	depthSet := false
+	for i, a := range ancestors {
+		if !a.MaxSubtaskDepth.Valid {
+			continue
+		}
+		depthSet = true
+		if depth := int64(i + 1); depth > a.MaxSubtaskDepth.Int64 {
+			return depthViolation(a.MaxSubtaskDepth.Int64, depth, "task", a.ID), nil
+		}
+	}
+	depth := int64(len(ancestors))
+	if !depthSet {
+		if project.MaxSubtaskDepth.Valid {
+			if depth > project.MaxSubtaskDepth.Int64 {
+				return depthViolation(project.MaxSubtaskDepth.Int64, depth, "project", project.ID), nil
+			}
+		} else if h.maxSubtaskDepth > 0 && depth > int64(h.maxSubtaskDepth) {
+			return depthViolation(int64(h.maxSubtaskDepth), depth, "default", ""), nil
+		}
+	}
+
+	limit, source, sourceID := int64(h.maxSubtasks), "default", ""
+	switch {
+	case parent.MaxConcurrentSubtasks.Valid:
+		limit, source, sourceID = parent.MaxConcurrentSubtasks.Int64, "task", parent.ID
+	case project.MaxConcurrentSubtasks.Valid:
+		limit, source, sourceID = project.MaxConcurrentSubtasks.Int64, "project", project.ID
+	case limit == 0:
+		return nil, nil
+	}
+	subtasks, err := h.store.ListSubtasks(ctx, sql.NullString{String: parent.ID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	var unfinished int64
+	for _, t := range subtasks {
+		if s := t.Status.String; s != "done" && s != "failed" && s != "cancelled" {
+			unfinished++
+		}
+	}
+	if unfinished >= limit {
+		return &delegationViolation{
+			Limit:    limitSubtasks,
+			Max:      limit,
+			Current:  unfinished,
+			Source:   source,
+			SourceID: sourceID,
+			Error: fmt.Sprintf("Delegation limit reached: task %s already has %d unfinished subtasks (at most %d). "+
+				"Wait for some to finish before creating more, or do the work in this task.", parent.ID, unfinished, limit),
+		}, nil
+	}
+	return nil, nil
+}
+
+// taskAncestors returns t followed by its parent, grandparent and so on up
+// to the top-level task.
+func (h *TaskHandler) taskAncestors(ctx context.Context, t db.Task) ([]db.Task, error) {
+	chain := []db.Task{t}
+	seen := map[string]bool{t.ID: true}
+	for t.ParentTaskID.Valid && len(chain) < maxAncestors {
+		next, err := h.store.GetTask(ctx, t.ParentTaskID.String)
+		if err == sql.ErrNoRows {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if seen[next.ID] {
+			break
+		}
+		seen[next.ID] = true
+		chain = append(chain, next)
+		t = next
+	}
+	return chain, nil
+}
+
+func depthViolation(limit, depth int64, source, sourceID string) *delegationViolation {
+	return &delegationViolation{
+		Limit:    limitDepth,
+		Max:      limit,
+		Current:  depth,
+		Source:   source,
+		SourceID: sourceID,
+		Error: fmt.Sprintf("Delegation limit reached: subtasks may nest at most %d levels deep here, and this one would be %d deep. "+
+			"Do the work in this task instead of delegating it further.", limit, depth),
+	}
+}
+
+// subtaskRefusedError is the error of a subtask refused by a delegation
+// limit; the API answers it with 422 delegation_limit_exceeded, detailing
+// the violation.
+type subtaskRefusedError struct {
+	violation *delegationViolation
+}
+
+func (e *subtaskRefusedError) Error() string {
+	return e.violation.Error
+}
+
+// refuseSubtask logs a delegation_limit event on parent and returns the
+// error refusing the subtask.
+func (h *TaskHandler) refuseSubtask(ctx context.Context, parent db.Task, title string, v *delegationViolation) error {
+	details, _ := json.Marshal(map[string]interface{}{
+		"limit":     v.Limit,
+		"max":       v.Max,
+		"current":   v.Current,
+		"source":    v.Source,
+		"source_id": v.SourceID,
+		"title":     title,
+	})
+	h.logEvent(ctx, parent.ID, parent.AgentID.String, "delegation_limit",
+		fmt.Sprintf("Subtask refused (%s limit of %d): %s", v.Limit, v.Max, title), string(details))
+	return &subtaskRefusedError{violation: v}
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/email"
+)
+
+const (
+	// maxEmailSize bounds inbound emails, attachments included.
+	maxEmailSize = 25 << 20
+
+	defaultInboundEmailPage = 50
+	maxInboundEmailPage     = 500
+)
+
+// EmailHandler turns emails posted by a mail provider or MTA into tasks.
+type EmailHandler struct {
+	store   EmailHandlerStore
+	gateway *email.Gateway
+	token   string // empty disables the webhook
+}
+
+func NewEmailHandler(s EmailHandlerStore, gateway *email.Gateway, token string) *EmailHandler {
+	return &EmailHandler{store: s, gateway: gateway, token: token}
+}
+
+// InboundEmailRequest is an email a provider has parsed already.
+type InboundEmailRequest struct {
+	From      string `json:"from"` // "Name <address>" or just the address
+	To        string `json:"to"`   // comma-separated
+	Subject   string `json:"subject"`
+	Text      string `json:"text"`
+	MessageID string `json:"message_id"`
+}
+
+type InboundEmailResponse struct {
+	TaskID    string `json:"task_id"`
+	MessageID string `json:"message_id,omitempty"`
+	Sender    string `json:"sender"`
+	Subject   string `json:"subject"`
+	CreatedAt string `json:"created_at"`
+}
+
+func toInboundEmailResponse(e db.InboundEmail) InboundEmailResponse {
+	return InboundEmailResponse{
+		TaskID:    e.TaskID,
+		MessageID: e.MessageID.String,
+		Sender:    e.Sender,
+		Subject:   e.Subject,
+		CreatedAt: nullTimeToString(e.CreatedAt),
+	}
+}
+
+// Inbound - POST /api/v1/integrations/email/inbound?token=
+// Takes an email as a raw message (message/rfc822), as a form with the raw
+// message in an "email" (SendGrid) or "body-mime" (Mailgun) field, or as
+// JSON. The token goes in the query because providers are set up with a URL.
+// Rejected emails get 200 too, so providers do not retry them.
+func (h *EmailHandler) Inbound(c echo.Context) error {
+	if h.token == "" {
+		return echo.NewHTTPError(http.StatusForbidden, "Email-to-task is disabled")
+	}
+	if subtle.ConstantTimeCompare([]byte(c.QueryParam("token")), []byte(h.token)) != 1 {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Invalid email token")
+	}
+
+	msg, err := readEmail(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	result, err := h.gateway.Receive(c.Request().Context(), msg)
+	var refused *echo.HTTPError
+	if errors.As(err, &refused) {
+		if refused.Code == http.StatusUnprocessableEntity {
+			// A task script rejected the email's task; like other
+			// rejections it is not worth retrying
+			return c.JSON(http.StatusOK, email.Result{Status: email.StatusRejected, Reason: fmt.Sprint(refused.Message)})
+		}
+		return refused
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	status := http.StatusOK
+	if result.Status == email.StatusCreated {
+		status = http.StatusCreated
+	}
+	return c.JSON(status, result)
+}
+
+// ListInbound - GET /api/v1/integrations/email/messages?limit=
+// Lists the emails tasks were created from, newest first.
+func (h *EmailHandler) ListInbound(c echo.Context) error {
+	limit := int64(defaultInboundEmailPage)
+	if raw := c.QueryParam("limit"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n < 1 || n > maxInboundEmailPage {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxInboundEmailPage))
+		}
+		limit = n
+	}
+	emails, err := h.store.ListInboundEmails(c.Request().Context(), limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	responses := make([]InboundEmailResponse, 0, len(emails))
+	for _, e := range emails {
+		responses = append(responses, toInboundEmailResponse(e))
+	}
+	return c.JSON(http.StatusOK, responses)
+}
+
+// readEmail reads the email of an inbound request in whichever form it came.
+func readEmail(c echo.Context) (email.Message, error) {
+	req := c.Request()
+	req.Body = http.MaxBytesReader(c.Response(), req.Body, maxEmailSize)
+	contentType := strings.ToLower(req.Header.Get(echo.HeaderContentType))
+
+	switch {
+	case strings.HasPrefix(contentType, echo.MIMEApplicationJSON):
+		var body InboundEmailRequest
+		if err := c.Bind(&body); err != nil {
+			return email.Message{}, err
+		}
+		return email.NewMessage(body.From, body.To, body.Subject, body.Text, body.MessageID)
+	case strings.HasPrefix(contentType, echo.MIMEMultipartForm), strings.HasPrefix(contentType, echo.MIMEApplicationForm):
+		raw := c.FormValue("email")
+		if raw == "" {
+			raw = c.FormValue("body-mime")
+		}
+		if raw == "" {
+			return email.Message{}, errors.New("form has no email or body-mime field with the raw message")
+		}
+		return email.Parse([]byte(raw))
+	default:
+		raw, err := io.ReadAll(req.Body)
+		if err != nil {
+			return email.Message{}, err
+		}
+		return email.Parse(raw)
+	}
+}
@@ -0,0 +1,444 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
+	ws "github.com/abelkuruvilla/claw-agent-mission-control/internal/websocket"
+)
+
+// Experiment kinds: what the two arms of an experiment are.
+const (
+	experimentKindAgents    = "agents"    // arms are agent IDs
+	experimentKindTemplates = "templates" // arms are task_assignment template text
+)
+
+// Experiment statuses. Only active experiments enroll new tasks.
+const (
+	experimentActive  = "active"
+	experimentStopped = "stopped"
+)
+
+// ExperimentHandler manages A/B experiments: new tasks are randomly split
+// between two agents, or two task_assignment templates, to compare how each
+// arm does.
+type ExperimentHandler struct {
+	store ExperimentHandlerStore
+	hub   *ws.Hub
+}
+
+func NewExperimentHandler(s ExperimentHandlerStore, hub *ws.Hub) *ExperimentHandler {
+	return &ExperimentHandler{
+		store: s,
+		hub:   hub,
+	}
+}
+
+// Request types
+type CreateExperimentRequest struct {
+	Name        string `json:"name" validate:"required"`
+	Description string `json:"description"`
+	Kind        string `json:"kind" validate:"required,oneof=agents templates"`
+	ArmA        string `json:"arm_a" validate:"required"`
+	ArmB        string `json:"arm_b" validate:"required"`
+	ProjectID   string `json:"project_id"` // only tasks of this project take part
+}
+
+type UpdateExperimentRequest struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+	Status      string  `json:"status" validate:"omitempty,oneof=active stopped"`
+}
+
+type ExperimentResponse struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Description *string `json:"description,omitempty"`
+	Kind        string  `json:"kind"`
+	ArmA        string  `json:"arm_a"`
+	ArmB        string  `json:"arm_b"`
+	ProjectID   *string `json:"project_id,omitempty"`
+	Status      string  `json:"status"`
+	CreatedAt   string  `json:"created_at"`
+	UpdatedAt   string  `json:"updated_at"`
+	StoppedAt   string  `json:"stopped_at,omitempty"`
+}
+
+func toExperimentResponse(e db.Experiment) ExperimentResponse {
+	return ExperimentResponse{
+		ID:          e.ID,
+		Name:        e.Name,
+		Description: strPtr(e.Description.String, e.Description.Valid),
+		Kind:        e.Kind,
+		ArmA:        e.ArmA,
+		ArmB:        e.ArmB,
+		ProjectID:   strPtr(e.ProjectID.String, e.ProjectID.Valid),
+		Status:      e.Status,
+		CreatedAt:   nullTimeToString(e.CreatedAt),
+		UpdatedAt:   nullTimeToString(e.UpdatedAt),
+		StoppedAt:   nullTimeToString(e.StoppedAt),
+	}
+}
+
+// ArmStats is how the tasks assigned to one arm of an experiment did.
+// Rates are over the arm's tasks that were not cancelled; cycle times over
+// its done tasks, from start (or creation, if never started) to completion.
+type ArmStats struct {
+	Arm                    string  `json:"arm"`
+	Variant                string  `json:"variant"` // the arm's agent ID or template text
+	Tasks                  int     `json:"tasks"`
+	Open                   int     `json:"open"`
+	Done                   int     `json:"done"`
+	Failed                 int     `json:"failed"`
+	Cancelled              int     `json:"cancelled"`
+	CompletionRate         float64 `json:"completion_rate"`
+	FailureRate            float64 `json:"failure_rate"`
+	AvgCycleTimeSeconds    float64 `json:"avg_cycle_time_seconds"`
+	MedianCycleTimeSeconds float64 `json:"median_cycle_time_seconds"`
+}
+
+// experimentArm returns the agent ID or template text of arm.
+func experimentArm(e db.Experiment, arm string) string {
+	if arm == "b" {
+		return e.ArmB
+	}
+	return e.ArmA
+}
+
+// validateExperimentArm checks that an arm names an existing agent, or is a
+// task_assignment template that renders.
+func (h *ExperimentHandler) validateExperimentArm(ctx context.Context, kind, arm string) error {
+	if kind == experimentKindAgents {
+		if _, err := h.store.GetAgent(ctx, arm); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Agent not found: %s", arm))
+		}
+		return nil
+	}
+	sample := openclaw.NewTemplates("").SampleData(openclaw.TemplateTaskAssignment)
+	if _, err := openclaw.RenderTemplate(openclaw.TemplateTaskAssignment, arm, sample); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return nil
+}
+
+// List all experiments, newest first
+func (h *ExperimentHandler) List(c echo.Context) error {
+	experiments, err := h.store.ListExperiments(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	responses := make([]ExperimentResponse, len(experiments))
+	for i, e := range experiments {
+		responses[i] = toExperimentResponse(e)
+	}
+	return c.JSON(http.StatusOK, responses)
+}
+
+// Get a single experiment
+func (h *ExperimentHandler) Get(c echo.Context) error {
+	experiment, err := h.store.GetExperiment(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Experiment not found")
+	}
+	return c.JSON(http.StatusOK, toExperimentResponse(experiment))
+}
+
+// Create starts an experiment; new tasks it is eligible for are split
+// between its arms from then on.
+func (h *ExperimentHandler) Create(c echo.Context) error {
+	var req CreateExperimentRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.ArmA == req.ArmB {
+		return echo.NewHTTPError(http.StatusBadRequest, "arm_a and arm_b must differ")
+	}
+
+	ctx := c.Request().Context()
+	for _, arm := range []string{req.ArmA, req.ArmB} {
+		if err := h.validateExperimentArm(ctx, req.Kind, arm); err != nil {
+			return err
+		}
+	}
+	if req.ProjectID != "" {
+		if _, err := h.store.GetProject(ctx, req.ProjectID); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Project not found")
+		}
+	}
+
+	experiment, err := h.store.CreateExperiment(ctx, db.CreateExperimentParams{
+		Name:        req.Name,
+		Description: sql.NullString{String: req.Description, Valid: req.Description != ""},
+		Kind:        req.Kind,
+		ArmA:        req.ArmA,
+		ArmB:        req.ArmB,
+		ProjectID:   sql.NullString{String: req.ProjectID, Valid: req.ProjectID != ""},
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	h.logEvent(ctx, "experiment_created", fmt.Sprintf("Experiment started: %s (%s)", experiment.Name, experiment.Kind),
+		fmt.Sprintf(`{"experiment_id":%q}`, experiment.ID))
+	return c.JSON(http.StatusCreated, toExperimentResponse(experiment))
+}
+
+// Update an experiment's name or description, or stop (or resume) it.
+// Tasks already assigned to an arm stay on it.
+func (h *ExperimentHandler) Update(c echo.Context) error {
+	var req UpdateExperimentRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	existing, err := h.store.GetExperiment(ctx, c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Experiment not found")
+	}
+
+	params := db.UpdateExperimentParams{
+		ID:          existing.ID,
+		Name:        existing.Name,
+		Description: existing.Description,
+		Status:      existing.Status,
+		StoppedAt:   existing.StoppedAt,
+	}
+	if name := strings.TrimSpace(req.Name); name != "" {
+		params.Name = name
+	}
+	if req.Description != nil {
+		params.Description = sql.NullString{String: *req.Description, Valid: *req.Description != ""}
+	}
+	switch req.Status {
+	case "", existing.Status:
+	case experimentStopped:
+		params.Status = experimentStopped
+		params.StoppedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	case experimentActive:
+		params.Status = experimentActive
+		params.StoppedAt = sql.NullTime{}
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, "status must be active or stopped")
+	}
+
+	experiment, err := h.store.UpdateExperiment(ctx, params)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if experiment.Status != existing.Status {
+		h.logEvent(ctx, "experiment_"+experiment.Status, fmt.Sprintf("Experiment %s: %s", experiment.Status, experiment.Name),
+			fmt.Sprintf(`{"experiment_id":%q}`, experiment.ID))
+	}
+	return c.JSON(http.StatusOK, toExperimentResponse(experiment))
+}
+
+// Delete an experiment and the record of which arm its tasks were on. The
+// tasks themselves are kept.
+func (h *ExperimentHandler) Delete(c echo.Context) error {
+	ctx := c.Request().Context()
+	experiment, err := h.store.GetExperiment(ctx, c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Experiment not found")
+	}
+	if err := h.store.DeleteExperiment(ctx, experiment.ID); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	h.logEvent(ctx, "experiment_deleted", fmt.Sprintf("Experiment removed: %s", experiment.Name),
+		fmt.Sprintf(`{"experiment_id":%q}`, experiment.ID))
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Analytics - GET /api/v1/analytics/experiments/:id
+// Compares the arms of an experiment: completion and failure rates and cycle
+// times of the tasks assigned to each. ?format=csv returns a row per arm.
+func (h *ExperimentHandler) Analytics(c echo.Context) error {
+	asCSV, err := wantsCSV(c)
+	if err != nil {
+		return err
+	}
+	ctx := c.Request().Context()
+	experiment, err := h.store.GetExperiment(ctx, c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Experiment not found")
+	}
+	rows, err := h.store.ListExperimentOutcomes(ctx, experiment.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	arms := []*ArmStats{
+		{Arm: "a", Variant: experiment.ArmA},
+		{Arm: "b", Variant: experiment.ArmB},
+	}
+	cycleTimes := make([][]float64, len(arms))
+	for _, row := range rows {
+		i := 0
+		if row.Arm == "b" {
+			i = 1
+		}
+		stats := arms[i]
+		stats.Tasks++
+		switch row.Status.String {
+		case "done":
+			stats.Done++
+			if row.CompletedAt.Valid {
+				start := row.CreatedAt
+				if row.StartedAt.Valid {
+					start = row.StartedAt
+				}
+				cycleTimes[i] = append(cycleTimes[i], row.CompletedAt.Time.Sub(start.Time).Seconds())
+			}
+		case "failed":
+			stats.Failed++
+		case "cancelled":
+			stats.Cancelled++
+		default:
+			stats.Open++
+		}
+	}
+	for i, stats := range arms {
+		if n := stats.Tasks - stats.Cancelled; n > 0 {
+			stats.CompletionRate = float64(stats.Done) / float64(n)
+			stats.FailureRate = float64(stats.Failed) / float64(n)
+		}
+		stats.AvgCycleTimeSeconds, stats.MedianCycleTimeSeconds = meanAndMedian(cycleTimes[i])
+	}
+
+	if asCSV {
+		return writeCSV(c, "experiment-"+experiment.ID, func(w *csv.Writer) error {
+			if err := w.Write([]string{
+				"experiment_id", "experiment_name", "arm", "variant", "tasks", "open", "done", "failed", "cancelled",
+				"completion_rate", "failure_rate", "avg_cycle_time_seconds", "median_cycle_time_seconds",
+			}); err != nil {
+				return err
+			}
+			for _, a := range arms {
+				if err := w.Write([]string{
+					experiment.ID, experiment.Name, a.Arm, a.Variant, strconv.Itoa(a.Tasks), strconv.Itoa(a.Open),
+					strconv.Itoa(a.Done), strconv.Itoa(a.Failed), strconv.Itoa(a.Cancelled),
+					csvFloat(a.CompletionRate), csvFloat(a.FailureRate),
+					csvFloat(a.AvgCycleTimeSeconds), csvFloat(a.MedianCycleTimeSeconds),
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"experiment": toExperimentResponse(experiment),
+		"arms":       arms,
+	})
+}
+
+// meanAndMedian returns the mean and median of values, 0 for none.
+func meanAndMedian(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	slices.Sort(values)
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	median := values[len(values)/2]
+	if len(values)%2 == 0 {
+		median = (values[len(values)/2-1] + median) / 2
+	}
+	return sum / float64(len(values)), median
+}
+
+// logEvent creates a persistent event record and broadcasts it via WebSocket.
+func (h *ExperimentHandler) logEvent(ctx context.Context, eventType, message, details string) {
+	event, err := h.store.CreateEvent(ctx, db.CreateEventParams{
+		Type:    eventType,
+		Message: message,
+		Details: sql.NullString{String: details, Valid: details != ""},
+	})
+	if err != nil {
+		log.Printf("[ExperimentHandler] Failed to create event (%s): %v", eventType, err)
+		return
+	}
+	if h.hub != nil {
+		h.hub.BroadcastEvent(event)
+	}
+}
+
+// pickExperimentArm picks the experiment a new task takes part in, if any —
+// the oldest active one it is eligible for — and one of its arms at random.
+// Top-level tasks of the experiment's project (of any project, if it has
+// none) are eligible: for agent experiments those not yet assigned to an
+// agent or group, which then go to the arm's agent, and for template
+// experiments those assigned to one.
+func (h *TaskHandler) pickExperimentArm(ctx context.Context, req CreateTaskRequest) (db.Experiment, string, bool) {
+	if req.ParentTaskID != "" {
+		return db.Experiment{}, "", false
+	}
+	experiments, err := h.store.ListActiveExperiments(ctx)
+	if err != nil {
+		log.Printf("[TaskHandler] Failed to list experiments: %v", err)
+		return db.Experiment{}, "", false
+	}
+	assigned := req.AgentID != "" && req.AgentID != "unassigned"
+	for _, e := range experiments {
+		if e.ProjectID.Valid && e.ProjectID.String != req.ProjectID {
+			continue
+		}
+		eligible := assigned
+		if e.Kind == experimentKindAgents {
+			eligible = !assigned && req.GroupID == ""
+		}
+		if !eligible {
+			continue
+		}
+		arm := "a"
+		if rand.IntN(2) == 1 {
+			arm = "b"
+		}
+		return e, arm, true
+	}
+	return db.Experiment{}, "", false
+}
+
+// recordExperimentArm records that taskID was assigned to arm of experiment.
+func (h *TaskHandler) recordExperimentArm(ctx context.Context, task db.Task, experiment db.Experiment, arm string) {
+	if err := h.store.AddExperimentTask(ctx, task.ID, experiment.ID, arm); err != nil {
+		log.Printf("[TaskHandler] Failed to record experiment arm of task %s: %v", task.ID, err)
+		return
+	}
+	h.logEvent(ctx, task.ID, task.AgentID.String, "experiment_assigned",
+		fmt.Sprintf("Task assigned to arm %s of experiment %s", strings.ToUpper(arm), experiment.Name),
+		fmt.Sprintf(`{"experiment_id":%q,"arm":%q}`, experiment.ID, arm))
+}
+
+// ExperimentTemplate returns the task_assignment template text taskID is to
+// be sent with: its arm's, if it takes part in a template experiment, else
+// "" for the active template.
+func (h *TaskHandler) ExperimentTemplate(taskID string) string {
+	ctx := context.Background()
+	assignment, err := h.store.GetExperimentTask(ctx, taskID)
+	if err != nil {
+		return ""
+	}
+	experiment, err := h.store.GetExperiment(ctx, assignment.ExperimentID)
+	if err != nil || experiment.Kind != experimentKindTemplates {
+		return ""
+	}
+	return experimentArm(experiment, assignment.Arm)
+}
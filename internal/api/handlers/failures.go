@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/failures"
+)
+
+// FailureResponse is why a task last failed or was reset, and what to do
+// about it.
+type FailureResponse struct {
+	TaskID        string                 `json:"task_id"`
+	Status        string                 `json:"status"`
+	FailureReason string                 `json:"failure_reason,omitempty"`
+	Remediation   []failures.Remediation `json:"remediation,omitempty"`
+}
+
+// recordFailure classifies why taskID failed, from errText (as reported, if
+// any) and the errors recorded on the task, and stores the reason.
+func (h *TaskHandler) recordFailure(ctx context.Context, taskID, errText string) string {
+	texts := failures.Evidence(ctx, h.store, taskID)
+	if errText != "" {
+		texts = append([]string{errText}, texts...)
+	}
+	reason := failures.Classify(texts...)
+	if err := h.store.SetTaskFailureReason(ctx, taskID, reason); err != nil {
+		log.Printf("[TaskHandler] Failed to record failure reason of task %s: %v", taskID, err)
+	}
+	return reason
+}
+
+// GetFailure - GET /api/v1/tasks/:id/failure
+// Returns why the task last failed or was reset, with suggested remediation.
+// Tasks that never failed have neither.
+func (h *TaskHandler) GetFailure(c echo.Context) error {
+	task, err := h.store.GetTask(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+	}
+	resp := FailureResponse{TaskID: task.ID, Status: task.Status.String}
+	if task.FailureReason.Valid {
+		resp.FailureReason = task.FailureReason.String
+		resp.Remediation = failures.Suggest(task.FailureReason.String)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// FailureAnalytics - GET /api/v1/analytics/failures?project_id=&agent_id=
+// Counts tasks by the reason they last failed or were reset, optionally only
+// those of a project or agent. Every reason is listed, with 0 if unseen.
+// ?format=csv returns a reason,count row per reason.
+func (h *TaskHandler) FailureAnalytics(c echo.Context) error {
+	asCSV, err := wantsCSV(c)
+	if err != nil {
+		return err
+	}
+	rows, err := h.store.CountTaskFailures(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	projectID, agentID := c.QueryParam("project_id"), c.QueryParam("agent_id")
+
+	reasons := make(map[string]int64, len(failures.Reasons))
+	for _, r := range failures.Reasons {
+		reasons[r] = 0
+	}
+	var total int64
+	for _, row := range rows {
+		if (projectID != "" && row.ProjectID.String != projectID) || (agentID != "" && row.AgentID.String != agentID) {
+			continue
+		}
+		reason := row.FailureReason.String
+		if !failures.Valid(reason) {
+			reason = failures.Unknown
+		}
+		reasons[reason] += row.Count
+		total += row.Count
+	}
+	if asCSV {
+		return writeCSV(c, "failures", func(w *csv.Writer) error {
+			if err := w.Write([]string{"reason", "count"}); err != nil {
+				return err
+			}
+			for _, r := range failures.Reasons {
+				if err := w.Write([]string{r, strconv.FormatInt(reasons[r], 10)}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"total":   total,
+		"reasons": reasons,
+	})
+}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
+	ws "github.com/abelkuruvilla/claw-agent-mission-control/internal/websocket"
+)
+
+// gatewayTestTimeout bounds a connection test against a gateway.
+const gatewayTestTimeout = 10 * time.Second
+
+// GatewayHandler manages the OpenClaw gateways agents can live on besides
+// the default one configured by OPENCLAW_GATEWAY_URL.
+type GatewayHandler struct {
+	store GatewayHandlerStore
+	hub   *ws.Hub
+}
+
+func NewGatewayHandler(s GatewayHandlerStore, hub *ws.Hub) *GatewayHandler {
+	return &GatewayHandler{
+		store: s,
+		hub:   hub,
+	}
+}
+
+// Request types
+type CreateGatewayRequest struct {
+	Name  string `json:"name" validate:"required"`
+	URL   string `json:"url" validate:"required"` // ws(s):// or http(s)://
+	Token string `json:"token"`
+}
+
+type UpdateGatewayRequest struct {
+	Name  string  `json:"name"`
+	URL   string  `json:"url"`
+	Token *string `json:"token"` // nil leaves it unchanged, "" removes it
+}
+
+// GatewayResponse describes a gateway. The token is never returned.
+type GatewayResponse struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	URL       string `json:"url"`
+	HasToken  bool   `json:"has_token"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+func toGatewayResponse(g db.Gateway) GatewayResponse {
+	return GatewayResponse{
+		ID:        g.ID,
+		Name:      g.Name,
+		URL:       g.URL,
+		HasToken:  g.Token.Valid && g.Token.String != "",
+		CreatedAt: nullTimeToString(g.CreatedAt),
+		UpdatedAt: nullTimeToString(g.UpdatedAt),
+	}
+}
+
+// validGatewayURL reports whether raw is an absolute ws(s) or http(s) URL.
+func validGatewayURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	switch u.Scheme {
+	case "ws", "wss", "http", "https":
+		return true
+	}
+	return false
+}
+
+// List all gateways
+func (h *GatewayHandler) List(c echo.Context) error {
+	gateways, err := h.store.ListGateways(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	responses := make([]GatewayResponse, len(gateways))
+	for i, g := range gateways {
+		responses[i] = toGatewayResponse(g)
+	}
+	return c.JSON(http.StatusOK, responses)
+}
+
+// Get a single gateway
+func (h *GatewayHandler) Get(c echo.Context) error {
+	gateway, err := h.store.GetGateway(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Gateway not found")
+	}
+	return c.JSON(http.StatusOK, toGatewayResponse(gateway))
+}
+
+// Create registers a gateway agents can then be assigned to
+func (h *GatewayHandler) Create(c echo.Context) error {
+	var req CreateGatewayRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if !validGatewayURL(req.URL) {
+		return echo.NewHTTPError(http.StatusBadRequest, "url must be a ws(s) or http(s) URL")
+	}
+
+	ctx := c.Request().Context()
+	gateway, err := h.store.CreateGateway(ctx, db.CreateGatewayParams{
+		Name:  req.Name,
+		URL:   req.URL,
+		Token: sql.NullString{String: req.Token, Valid: req.Token != ""},
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return echo.NewHTTPError(http.StatusConflict, "A gateway with this name already exists")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	h.logEvent(ctx, "gateway_created", fmt.Sprintf("Gateway added: %s (%s)", gateway.Name, gateway.URL),
+		fmt.Sprintf(`{"gateway_id":%q}`, gateway.ID))
+	return c.JSON(http.StatusCreated, toGatewayResponse(gateway))
+}
+
+// Update a gateway's name, URL or token. Agents on it use the new settings
+// from their next call.
+func (h *GatewayHandler) Update(c echo.Context) error {
+	var req UpdateGatewayRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	existing, err := h.store.GetGateway(ctx, c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Gateway not found")
+	}
+
+	params := db.UpdateGatewayParams{
+		ID:    existing.ID,
+		Name:  existing.Name,
+		URL:   existing.URL,
+		Token: existing.Token,
+	}
+	if name := strings.TrimSpace(req.Name); name != "" {
+		params.Name = name
+	}
+	if req.URL != "" {
+		if !validGatewayURL(req.URL) {
+			return echo.NewHTTPError(http.StatusBadRequest, "url must be a ws(s) or http(s) URL")
+		}
+		params.URL = req.URL
+	}
+	if req.Token != nil {
+		params.Token = sql.NullString{String: *req.Token, Valid: *req.Token != ""}
+	}
+
+	gateway, err := h.store.UpdateGateway(ctx, params)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return echo.NewHTTPError(http.StatusConflict, "A gateway with this name already exists")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, toGatewayResponse(gateway))
+}
+
+// Delete a gateway. Its agents move back to the default gateway.
+func (h *GatewayHandler) Delete(c echo.Context) error {
+	ctx := c.Request().Context()
+	gateway, err := h.store.GetGateway(ctx, c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Gateway not found")
+	}
+	if err := h.store.DeleteGateway(ctx, gateway.ID); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	h.logEvent(ctx, "gateway_deleted", fmt.Sprintf("Gateway removed: %s", gateway.Name),
+		fmt.Sprintf(`{"gateway_id":%q}`, gateway.ID))
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Test - POST /api/v1/settings/gateways/:id/test
+// Checks that the gateway answers its health endpoint.
+func (h *GatewayHandler) Test(c echo.Context) error {
+	ctx := c.Request().Context()
+	gateway, err := h.store.GetGateway(ctx, c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Gateway not found")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, gatewayTestTimeout)
+	defer cancel()
+	client := openclaw.NewClient(&openclaw.Config{GatewayURL: gateway.URL, GatewayToken: gateway.Token.String})
+	connected, err := client.GetStatus(ctx)
+	resp := map[string]interface{}{
+		"gateway_id": gateway.ID,
+		"connected":  connected,
+	}
+	if err != nil {
+		resp["error"] = err.Error()
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// logEvent creates a persistent event record and broadcasts it via WebSocket.
+func (h *GatewayHandler) logEvent(ctx context.Context, eventType, message, details string) {
+	event, err := h.store.CreateEvent(ctx, db.CreateEventParams{
+		Type:    eventType,
+		Message: message,
+		Details: sql.NullString{String: details, Valid: details != ""},
+	})
+	if err != nil {
+		log.Printf("[GatewayHandler] Failed to create event (%s): %v", eventType, err)
+		return
+	}
+	if h.hub != nil {
+		h.hub.BroadcastEvent(event)
+	}
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/github"
+)
+
+// GitHubHandler syncs projects with GitHub Issues: webhook deliveries,
+// on-demand syncs and the issues linked to each project's tasks.
+type GitHubHandler struct {
+	store         GitHubHandlerStore
+	syncer        *github.Syncer // nil when GITHUB_TOKEN is not set
+	webhookSecret string         // empty disables the webhook
+}
+
+func NewGitHubHandler(s GitHubHandlerStore, syncer *github.Syncer, webhookSecret string) *GitHubHandler {
+	return &GitHubHandler{store: s, syncer: syncer, webhookSecret: webhookSecret}
+}
+
+type GitHubIssueLinkResponse struct {
+	TaskID      string `json:"task_id"`
+	Repo        string `json:"repo"`
+	IssueNumber int64  `json:"issue_number"`
+	State       string `json:"state"`
+	CreatedAt   string `json:"created_at"`
+	SyncedAt    string `json:"synced_at"`
+}
+
+func toGitHubIssueLinkResponse(l db.GithubIssueLink) GitHubIssueLinkResponse {
+	return GitHubIssueLinkResponse{
+		TaskID:      l.TaskID,
+		Repo:        l.Repo,
+		IssueNumber: l.IssueNumber,
+		State:       l.State,
+		CreatedAt:   nullTimeToString(l.CreatedAt),
+		SyncedAt:    nullTimeToString(l.SyncedAt),
+	}
+}
+
+// Webhook - POST /api/v1/integrations/github/webhook
+// Takes GitHub's issues and issue_comment deliveries, signed with
+// GITHUB_WEBHOOK_SECRET. Other events are acknowledged and ignored.
+func (h *GitHubHandler) Webhook(c echo.Context) error {
+	if h.syncer == nil || h.webhookSecret == "" {
+		return echo.NewHTTPError(http.StatusForbidden, "GitHub webhook is disabled")
+	}
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if !github.VerifySignature(h.webhookSecret, body, c.Request().Header.Get("X-Hub-Signature-256")) {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Invalid webhook signature")
+	}
+
+	ctx := c.Request().Context()
+	event := c.Request().Header.Get("X-GitHub-Event")
+	switch event {
+	case github.EventPing:
+		return c.JSON(http.StatusOK, map[string]string{"status": "pong"})
+	case github.EventIssues:
+		var ev github.IssuesEvent
+		if err := json.Unmarshal(body, &ev); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		err = h.syncer.HandleIssues(ctx, ev)
+	case github.EventIssueComment:
+		var ev github.IssueCommentEvent
+		if err := json.Unmarshal(body, &ev); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		err = h.syncer.HandleIssueComment(ctx, ev)
+	default:
+		return c.JSON(http.StatusAccepted, map[string]string{"status": "ignored"})
+	}
+	var refused *echo.HTTPError
+	if errors.As(err, &refused) {
+		// Maintenance mode, or a task script rejected the issue's task
+		log.Printf("[GitHubHandler] Refused %s webhook: %v", event, err)
+		return refused
+	}
+	if err != nil {
+		log.Printf("[GitHubHandler] Failed to handle %s webhook: %v", event, err)
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Sync - POST /api/v1/projects/:id/github/sync
+// Creates tasks for the open labelled issues of the project's repository
+// that have none yet.
+func (h *GitHubHandler) Sync(c echo.Context) error {
+	if h.syncer == nil {
+		return echo.NewHTTPError(http.StatusForbidden, "GitHub sync is disabled")
+	}
+	project, err := h.store.GetProject(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+	}
+	if !project.GithubRepo.Valid || project.GithubRepo.String == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Project has no github_repo")
+	}
+	created, err := h.syncer.SyncProject(c.Request().Context(), project)
+	var refused *echo.HTTPError
+	if errors.As(err, &refused) {
+		return refused
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadGateway, err.Error())
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"created": created})
+}
+
+// ListIssues - GET /api/v1/projects/:id/github/issues
+// Lists the GitHub issues linked to the project's tasks.
+func (h *GitHubHandler) ListIssues(c echo.Context) error {
+	ctx := c.Request().Context()
+	if _, err := h.store.GetProject(ctx, c.Param("id")); err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+	}
+	links, err := h.store.ListGitHubIssueLinksByProject(ctx, c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	responses := make([]GitHubIssueLinkResponse, 0, len(links))
+	for _, l := range links {
+		responses = append(responses, toGitHubIssueLinkResponse(l))
+	}
+	return c.JSON(http.StatusOK, responses)
+}
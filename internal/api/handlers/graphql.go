@@ -0,0 +1,490 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/graph-gophers/dataloader/v7"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/taskrefs"
+)
+
+const (
+	graphqlMaxDepth = 10
+	graphqlMaxLimit = 500
+)
+
+// graphqlSchema is the schema GraphQLHandler serves; the resolvers are in
+// graphql_types.go.
+const graphqlSchema = `schema {
+  query: Query
+}
+
+type Query {
+  "Tasks, newest first"
+  tasks(status: String, agentId: ID, projectId: ID, limit: Int = 50): [Task!]!
+  "A task, by ID or short ID (e.g. MC-142)"
+  task(id: ID!): Task
+  agents: [Agent!]!
+  agent(id: ID!): Agent
+  projects(status: String): [Project!]!
+  project(id: ID!): Project
+  "Events, newest first, of a task or else of an agent"
+  events(taskId: ID, agentId: ID, limit: Int = 50): [Event!]!
+}
+
+"A unit of work, assigned to an agent."
+type Task {
+  id: ID!
+  shortId: String
+  title: String!
+  description: String
+  status: String!
+  priority: Int!
+  "Percentage, 0-100"
+  progress: Int
+  failureReason: String
+  gitBranch: String
+  model: String
+  createdAt: String!
+  updatedAt: String!
+  startedAt: String
+  completedAt: String
+  scheduledAt: String
+  agent: Agent
+  project: Project
+  parent: Task
+  "Oldest first"
+  subtasks: [Task!]!
+  "Newest first"
+  events(limit: Int = 20): [Event!]!
+}
+
+"An OpenClaw agent."
+type Agent {
+  id: ID!
+  name: String!
+  description: String
+  status: String!
+  model: String
+  createdAt: String!
+  updatedAt: String!
+  currentTask: Task
+  "Newest first"
+  tasks(status: String, limit: Int = 50): [Task!]!
+}
+
+"A project grouping tasks."
+type Project {
+  id: ID!
+  name: String!
+  description: String!
+  status: String!
+  color: String!
+  key: String!
+  createdAt: String!
+  updatedAt: String!
+  "By priority, then newest first"
+  tasks(status: String, limit: Int = 50): [Task!]!
+}
+
+"An activity event."
+type Event {
+  id: ID!
+  type: String!
+  message: String!
+  details: String
+  createdAt: String!
+  task: Task
+  agent: Agent
+}
+`
+
+// GraphQLHandler answers GraphQL queries over tasks, agents, projects and
+// events, so each dashboard view can fetch the shape of data it needs in
+// one request. Relations go through a dataloader per request, batched by
+// the loader functions below: the subtasks of fifty tasks take one store
+// query, not fifty.
+type GraphQLHandler struct {
+	store  GraphQLHandlerStore
+	schema *graphql.Schema
+}
+
+func NewGraphQLHandler(s GraphQLHandlerStore) *GraphQLHandler {
+	h := &GraphQLHandler{store: s}
+	h.schema = graphql.MustParseSchema(graphqlSchema, &queryResolver{h: h},
+		graphql.MaxDepth(graphqlMaxDepth),
+		// Enough for every object of a full page to wait on the same batch
+		graphql.MaxParallelism(graphqlMaxLimit),
+	)
+	return h
+}
+
+// GraphQLRequest is a query and its variables.
+type GraphQLRequest struct {
+	Query         string         `json:"query"`
+	Variables     map[string]any `json:"variables"`
+	OperationName string         `json:"operationName"`
+}
+
+// Query runs a query, POSTed as JSON or given in the query string.
+func (h *GraphQLHandler) Query(c echo.Context) error {
+	var req GraphQLRequest
+	if c.Request().Method == http.MethodGet {
+		req.Query = c.QueryParam("query")
+		req.OperationName = c.QueryParam("operationName")
+		if vars := c.QueryParam("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "variables must be a JSON object")
+			}
+		}
+	} else if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "query is required")
+	}
+	ctx := context.WithValue(c.Request().Context(), loadersKey{}, h.newLoaders())
+	return c.JSON(http.StatusOK, h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables))
+}
+
+// Schema describes the schema in the GraphQL schema definition language.
+func (h *GraphQLHandler) Schema(c echo.Context) error {
+	return c.String(http.StatusOK, graphqlSchema)
+}
+
+// loaders load the relations of one query, each batching the keys its
+// resolvers ask for into one call of its loader function.
+type loaders struct {
+	tasks        *dataloader.Loader[string, *TaskResponse]
+	agents       *dataloader.Loader[string, *AgentResponse]
+	projects     *dataloader.Loader[string, *ProjectResponse]
+	subtasks     *dataloader.Loader[listKey, []TaskResponse]
+	agentTasks   *dataloader.Loader[listKey, []TaskResponse]
+	projectTasks *dataloader.Loader[listKey, []TaskResponse]
+	taskEvents   *dataloader.Loader[listKey, []EventResponse]
+}
+
+type loadersKey struct{}
+
+func (h *GraphQLHandler) newLoaders() *loaders {
+	return &loaders{
+		tasks:        byID(h.tasksByID),
+		agents:       byID(h.agentsByID),
+		projects:     byID(h.projectsByID),
+		subtasks:     listsByID(h.subtasks),
+		agentTasks:   listsByID(h.agentTasks),
+		projectTasks: listsByID(h.projectTasks),
+		taskEvents:   listsByID(h.taskEvents),
+	}
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// listArgs filter the lists of related objects, such as an agent's tasks;
+// a zero Limit is no limit.
+type listArgs struct {
+	Status string
+	Limit  int
+}
+
+// listKey asks for the list of the object ID, filtered by args.
+type listKey struct {
+	ID   string
+	Args listArgs
+}
+
+// byID makes a loader of objects by ID from a function loading the objects
+// of many IDs at once. An ID it doesn't find loads nil.
+func byID[V any](load func(ctx context.Context, ids []string) (map[string]V, error)) *dataloader.Loader[string, *V] {
+	return dataloader.NewBatchedLoader(func(ctx context.Context, ids []string) []*dataloader.Result[*V] {
+		results := make([]*dataloader.Result[*V], len(ids))
+		found, err := load(ctx, ids)
+		for i, id := range ids {
+			results[i] = &dataloader.Result[*V]{Error: err}
+			if v, ok := found[id]; ok {
+				results[i].Data = &v
+			}
+		}
+		return results
+	})
+}
+
+// listsByID makes a loader of the lists of related objects of IDs, such as
+// the subtasks of tasks, from a function loading the lists of many IDs at
+// once: one call for all the keys with the same arguments.
+func listsByID[V any](load func(ctx context.Context, ids []string, args listArgs) (map[string][]V, error)) *dataloader.Loader[listKey, []V] {
+	return dataloader.NewBatchedLoader(func(ctx context.Context, keys []listKey) []*dataloader.Result[[]V] {
+		results := make([]*dataloader.Result[[]V], len(keys))
+		byArgs := map[listArgs][]int{}
+		for i, k := range keys {
+			byArgs[k.Args] = append(byArgs[k.Args], i)
+		}
+		for args, indexes := range byArgs {
+			ids := make([]string, len(indexes))
+			for j, i := range indexes {
+				ids[j] = keys[i].ID
+			}
+			found, err := load(ctx, ids, args)
+			for _, i := range indexes {
+				list := found[keys[i].ID]
+				if list == nil {
+					list = []V{}
+				}
+				results[i] = &dataloader.Result[[]V]{Data: list, Error: err}
+			}
+		}
+		return results
+	})
+}
+
+// limitArg checks a limit argument.
+func limitArg(limit int32) (int, error) {
+	if limit < 1 || limit > graphqlMaxLimit {
+		return 0, fmt.Errorf("limit must be between 1 and %d", graphqlMaxLimit)
+	}
+	return int(limit), nil
+}
+
+// stringArg reads an optional string or ID argument.
+func stringArg[S ~string](s *S) string {
+	if s == nil {
+		return ""
+	}
+	return string(*s)
+}
+
+// queryResolver resolves the root fields.
+type queryResolver struct {
+	h *GraphQLHandler
+}
+
+func (r *queryResolver) Tasks(ctx context.Context, args struct {
+	Status    *string
+	AgentID   *graphql.ID
+	ProjectID *graphql.ID
+	Limit     int32
+}) ([]*taskResolver, error) {
+	limit, err := limitArg(args.Limit)
+	if err != nil {
+		return nil, err
+	}
+	status, agentID, projectID := stringArg(args.Status), stringArg(args.AgentID), stringArg(args.ProjectID)
+	var tasks []db.Task
+	if status != "" {
+		tasks, err = r.h.store.ListTasksByStatus(ctx, status)
+	} else {
+		tasks, err = r.h.store.ListTasks(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := []TaskResponse{}
+	for _, t := range tasks {
+		if agentID != "" && t.AgentID.String != agentID {
+			continue
+		}
+		if projectID != "" && t.ProjectID.String != projectID {
+			continue
+		}
+		result = append(result, ToTaskResponse(t))
+		if len(result) == limit {
+			break
+		}
+	}
+	return taskResolvers(loadersFrom(ctx), result), nil
+}
+
+func (r *queryResolver) Task(ctx context.Context, args struct{ ID graphql.ID }) (*taskResolver, error) {
+	id := string(args.ID)
+	var task db.Task
+	var err error
+	if taskrefs.IsShortID(id) {
+		task, err = r.h.store.GetTaskByShortID(ctx, id)
+	} else {
+		task, err = r.h.store.GetTask(ctx, id)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &taskResolver{t: ToTaskResponse(task), l: loadersFrom(ctx)}, nil
+}
+
+func (r *queryResolver) Agents(ctx context.Context) ([]*agentResolver, error) {
+	agents, err := r.h.store.ListAgents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return agentResolvers(loadersFrom(ctx), ToAgentResponses(agents)), nil
+}
+
+func (r *queryResolver) Agent(ctx context.Context, args struct{ ID graphql.ID }) (*agentResolver, error) {
+	agent, err := r.h.store.GetAgent(ctx, string(args.ID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &agentResolver{a: ToAgentResponse(agent), l: loadersFrom(ctx)}, nil
+}
+
+func (r *queryResolver) Projects(ctx context.Context, args struct{ Status *string }) ([]*projectResolver, error) {
+	var projects []db.Project
+	var err error
+	if status := stringArg(args.Status); status != "" {
+		projects, err = r.h.store.ListProjectsByStatus(ctx, sql.NullString{String: status, Valid: true})
+	} else {
+		projects, err = r.h.store.ListProjects(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*projectResolver, len(projects))
+	for i, p := range projects {
+		result[i] = &projectResolver{p: toProjectResponse(p), l: loadersFrom(ctx)}
+	}
+	return result, nil
+}
+
+func (r *queryResolver) Project(ctx context.Context, args struct{ ID graphql.ID }) (*projectResolver, error) {
+	project, err := r.h.store.GetProject(ctx, string(args.ID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &projectResolver{p: toProjectResponse(project), l: loadersFrom(ctx)}, nil
+}
+
+func (r *queryResolver) Events(ctx context.Context, args struct {
+	TaskID  *graphql.ID
+	AgentID *graphql.ID
+	Limit   int32
+}) ([]*eventResolver, error) {
+	limit, err := limitArg(args.Limit)
+	if err != nil {
+		return nil, err
+	}
+	var events []db.Event
+	if taskID := stringArg(args.TaskID); taskID != "" {
+		if taskrefs.IsShortID(taskID) {
+			task, err := r.h.store.GetTaskByShortID(ctx, taskID)
+			if err != nil {
+				return []*eventResolver{}, nil
+			}
+			taskID = task.ID
+		}
+		events, err = r.h.store.ListEventsByTask(ctx, taskID, int64(limit))
+	} else if agentID := stringArg(args.AgentID); agentID != "" {
+		events, err = r.h.store.ListEventsByAgent(ctx, agentID, int64(limit))
+	} else {
+		events, err = r.h.store.ListEvents(ctx, int64(limit))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return eventResolvers(loadersFrom(ctx), ToEventResponses(events)), nil
+}
+
+func (h *GraphQLHandler) tasksByID(ctx context.Context, ids []string) (map[string]TaskResponse, error) {
+	tasks, err := h.store.ListTasksByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]TaskResponse, len(tasks))
+	for _, t := range tasks {
+		found[t.ID] = ToTaskResponse(t)
+	}
+	return found, nil
+}
+
+func (h *GraphQLHandler) agentsByID(ctx context.Context, ids []string) (map[string]AgentResponse, error) {
+	agents, err := h.store.ListAgentsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]AgentResponse, len(agents))
+	for _, a := range agents {
+		found[a.ID] = ToAgentResponse(a)
+	}
+	return found, nil
+}
+
+func (h *GraphQLHandler) projectsByID(ctx context.Context, ids []string) (map[string]ProjectResponse, error) {
+	projects, err := h.store.ListProjectsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]ProjectResponse, len(projects))
+	for _, p := range projects {
+		found[p.ID] = toProjectResponse(p)
+	}
+	return found, nil
+}
+
+func (h *GraphQLHandler) subtasks(ctx context.Context, ids []string, args listArgs) (map[string][]TaskResponse, error) {
+	tasks, err := h.store.ListTasksByParentIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return groupTasks(tasks, func(t db.Task) string { return t.ParentTaskID.String }, args)
+}
+
+func (h *GraphQLHandler) agentTasks(ctx context.Context, ids []string, args listArgs) (map[string][]TaskResponse, error) {
+	tasks, err := h.store.ListTasksByAgentIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return groupTasks(tasks, func(t db.Task) string { return t.AgentID.String }, args)
+}
+
+func (h *GraphQLHandler) projectTasks(ctx context.Context, ids []string, args listArgs) (map[string][]TaskResponse, error) {
+	tasks, err := h.store.ListTasksByProjectIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return groupTasks(tasks, func(t db.Task) string { return t.ProjectID.String }, args)
+}
+
+// groupTasks groups tasks by the parent key picks, keeping those of
+// args.Status, if given, up to args.Limit, if any, per parent.
+func groupTasks(tasks []db.Task, key func(db.Task) string, args listArgs) (map[string][]TaskResponse, error) {
+	grouped := map[string][]TaskResponse{}
+	for _, t := range tasks {
+		k := key(t)
+		if args.Status != "" && t.Status.String != args.Status || args.Limit > 0 && len(grouped[k]) == args.Limit {
+			continue
+		}
+		grouped[k] = append(grouped[k], ToTaskResponse(t))
+	}
+	return grouped, nil
+}
+
+func (h *GraphQLHandler) taskEvents(ctx context.Context, ids []string, args listArgs) (map[string][]EventResponse, error) {
+	events, err := h.store.ListEventsByTaskIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	grouped := map[string][]EventResponse{}
+	for _, e := range ToEventResponses(events) {
+		if len(grouped[*e.TaskID]) < args.Limit {
+			grouped[*e.TaskID] = append(grouped[*e.TaskID], e)
+		}
+	}
+	return grouped, nil
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/graph-gophers/dataloader/v7"
+	"github.com/graph-gophers/graphql-go"
+)
+
+// taskResolver resolves a Task, loading its relations through l.
+type taskResolver struct {
+	t TaskResponse
+	l *loaders
+}
+
+func taskResolvers(l *loaders, tasks []TaskResponse) []*taskResolver {
+	result := make([]*taskResolver, len(tasks))
+	for i, t := range tasks {
+		result[i] = &taskResolver{t: t, l: l}
+	}
+	return result
+}
+
+func (r *taskResolver) ID() graphql.ID         { return graphql.ID(r.t.ID) }
+func (r *taskResolver) ShortID() *string       { return r.t.ShortID }
+func (r *taskResolver) Title() string          { return r.t.Title }
+func (r *taskResolver) Description() *string   { return r.t.Description }
+func (r *taskResolver) Status() string         { return r.t.Status }
+func (r *taskResolver) Priority() int32        { return int32(r.t.Priority) }
+func (r *taskResolver) FailureReason() *string { return r.t.FailureReason }
+func (r *taskResolver) GitBranch() *string     { return r.t.GitBranch }
+func (r *taskResolver) Model() *string         { return r.t.Model }
+func (r *taskResolver) CreatedAt() string      { return r.t.CreatedAt }
+func (r *taskResolver) UpdatedAt() string      { return r.t.UpdatedAt }
+func (r *taskResolver) StartedAt() *string     { return r.t.StartedAt }
+func (r *taskResolver) CompletedAt() *string   { return r.t.CompletedAt }
+func (r *taskResolver) ScheduledAt() *string   { return r.t.ScheduledAt }
+
+func (r *taskResolver) Progress() *int32 {
+	if r.t.Progress == nil {
+		return nil
+	}
+	p := int32(*r.t.Progress)
+	return &p
+}
+
+func (r *taskResolver) Agent(ctx context.Context) (*agentResolver, error) {
+	a, err := loadOne(ctx, r.l.agents, r.t.AgentID)
+	if a == nil || err != nil {
+		return nil, err
+	}
+	return &agentResolver{a: *a, l: r.l}, nil
+}
+
+func (r *taskResolver) Project(ctx context.Context) (*projectResolver, error) {
+	p, err := loadOne(ctx, r.l.projects, r.t.ProjectID)
+	if p == nil || err != nil {
+		return nil, err
+	}
+	return &projectResolver{p: *p, l: r.l}, nil
+}
+
+func (r *taskResolver) Parent(ctx context.Context) (*taskResolver, error) {
+	t, err := loadOne(ctx, r.l.tasks, r.t.ParentTaskID)
+	if t == nil || err != nil {
+		return nil, err
+	}
+	return &taskResolver{t: *t, l: r.l}, nil
+}
+
+func (r *taskResolver) Subtasks(ctx context.Context) ([]*taskResolver, error) {
+	tasks, err := r.l.subtasks.Load(ctx, listKey{ID: r.t.ID})()
+	if err != nil {
+		return nil, err
+	}
+	return taskResolvers(r.l, tasks), nil
+}
+
+func (r *taskResolver) Events(ctx context.Context, args struct{ Limit int32 }) ([]*eventResolver, error) {
+	limit, err := limitArg(args.Limit)
+	if err != nil {
+		return nil, err
+	}
+	events, err := r.l.taskEvents.Load(ctx, listKey{ID: r.t.ID, Args: listArgs{Limit: limit}})()
+	if err != nil {
+		return nil, err
+	}
+	return eventResolvers(r.l, events), nil
+}
+
+// agentResolver resolves an Agent, loading its relations through l.
+type agentResolver struct {
+	a AgentResponse
+	l *loaders
+}
+
+func agentResolvers(l *loaders, agents []AgentResponse) []*agentResolver {
+	result := make([]*agentResolver, len(agents))
+	for i, a := range agents {
+		result[i] = &agentResolver{a: a, l: l}
+	}
+	return result
+}
+
+func (r *agentResolver) ID() graphql.ID       { return graphql.ID(r.a.ID) }
+func (r *agentResolver) Name() string         { return r.a.Name }
+func (r *agentResolver) Description() *string { return r.a.Description }
+func (r *agentResolver) Status() string       { return r.a.Status }
+func (r *agentResolver) Model() *string       { return r.a.Model }
+func (r *agentResolver) CreatedAt() string    { return r.a.CreatedAt }
+func (r *agentResolver) UpdatedAt() string    { return r.a.UpdatedAt }
+
+func (r *agentResolver) CurrentTask(ctx context.Context) (*taskResolver, error) {
+	t, err := loadOne(ctx, r.l.tasks, r.a.CurrentTaskID)
+	if t == nil || err != nil {
+		return nil, err
+	}
+	return &taskResolver{t: *t, l: r.l}, nil
+}
+
+func (r *agentResolver) Tasks(ctx context.Context, args struct {
+	Status *string
+	Limit  int32
+}) ([]*taskResolver, error) {
+	return loadTasks(ctx, r.l, r.l.agentTasks, r.a.ID, args.Status, args.Limit)
+}
+
+// projectResolver resolves a Project, loading its tasks through l.
+type projectResolver struct {
+	p ProjectResponse
+	l *loaders
+}
+
+func (r *projectResolver) ID() graphql.ID      { return graphql.ID(r.p.ID) }
+func (r *projectResolver) Name() string        { return r.p.Name }
+func (r *projectResolver) Description() string { return r.p.Description }
+func (r *projectResolver) Status() string      { return r.p.Status }
+func (r *projectResolver) Color() string       { return r.p.Color }
+func (r *projectResolver) Key() string         { return r.p.Key }
+func (r *projectResolver) CreatedAt() string   { return r.p.CreatedAt }
+func (r *projectResolver) UpdatedAt() string   { return r.p.UpdatedAt }
+
+func (r *projectResolver) Tasks(ctx context.Context, args struct {
+	Status *string
+	Limit  int32
+}) ([]*taskResolver, error) {
+	return loadTasks(ctx, r.l, r.l.projectTasks, r.p.ID, args.Status, args.Limit)
+}
+
+// eventResolver resolves an Event, loading its task and agent through l.
+type eventResolver struct {
+	e EventResponse
+	l *loaders
+}
+
+func eventResolvers(l *loaders, events []EventResponse) []*eventResolver {
+	result := make([]*eventResolver, len(events))
+	for i, e := range events {
+		result[i] = &eventResolver{e: e, l: l}
+	}
+	return result
+}
+
+func (r *eventResolver) ID() graphql.ID    { return graphql.ID(r.e.ID) }
+func (r *eventResolver) Type() string      { return r.e.Type }
+func (r *eventResolver) Message() string   { return r.e.Message }
+func (r *eventResolver) Details() *string  { return r.e.Details }
+func (r *eventResolver) CreatedAt() string { return r.e.CreatedAt }
+
+func (r *eventResolver) Task(ctx context.Context) (*taskResolver, error) {
+	t, err := loadOne(ctx, r.l.tasks, r.e.TaskID)
+	if t == nil || err != nil {
+		return nil, err
+	}
+	return &taskResolver{t: *t, l: r.l}, nil
+}
+
+func (r *eventResolver) Agent(ctx context.Context) (*agentResolver, error) {
+	a, err := loadOne(ctx, r.l.agents, r.e.AgentID)
+	if a == nil || err != nil {
+		return nil, err
+	}
+	return &agentResolver{a: *a, l: r.l}, nil
+}
+
+// loadOne loads the object a nullable reference points to, nil if it's
+// null or dangling.
+func loadOne[V any](ctx context.Context, loader *dataloader.Loader[string, *V], id *string) (*V, error) {
+	if id == nil || *id == "" {
+		return nil, nil
+	}
+	return loader.Load(ctx, *id)()
+}
+
+// loadTasks loads the tasks of id, an agent's or a project's.
+func loadTasks(ctx context.Context, l *loaders, loader *dataloader.Loader[listKey, []TaskResponse], id string, status *string, limit int32) ([]*taskResolver, error) {
+	n, err := limitArg(limit)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := loader.Load(ctx, listKey{ID: id, Args: listArgs{Status: stringArg(status), Limit: n}})()
+	if err != nil {
+		return nil, err
+	}
+	return taskResolvers(l, tasks), nil
+}
@@ -0,0 +1,362 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/dispatch"
+	ws "github.com/abelkuruvilla/claw-agent-mission-control/internal/websocket"
+)
+
+// GroupDispatcher offers a group's shared queue to its free members.
+// TaskHandler implements it.
+type GroupDispatcher interface {
+	DispatchGroupQueue(ctx context.Context, groupID string)
+}
+
+// GroupHandler manages agent groups. Tasks assigned to a group wait in a
+// shared queue until a free member claims them.
+type GroupHandler struct {
+	store      GroupHandlerStore
+	hub        *ws.Hub
+	dispatcher GroupDispatcher
+}
+
+func NewGroupHandler(s GroupHandlerStore, hub *ws.Hub, dispatcher GroupDispatcher) *GroupHandler {
+	return &GroupHandler{
+		store:      s,
+		hub:        hub,
+		dispatcher: dispatcher,
+	}
+}
+
+// Request types
+type CreateGroupRequest struct {
+	Name             string              `json:"name" validate:"required"`
+	Description      string              `json:"description"`
+	DispatchStrategy string              `json:"dispatch_strategy"` // see dispatch.Names(); default first_free
+	Members          []string            `json:"members"`           // agent IDs
+	Skills           map[string][]string `json:"skills"`            // agent ID -> skills, for skill_weighted
+}
+
+type UpdateGroupRequest struct {
+	Name             string  `json:"name"`
+	Description      *string `json:"description"`
+	DispatchStrategy string  `json:"dispatch_strategy"`
+}
+
+type AddGroupMemberRequest struct {
+	AgentID string   `json:"agent_id" validate:"required"`
+	Skills  []string `json:"skills"`
+}
+
+// Response types
+type GroupResponse struct {
+	ID                    string              `json:"id"`
+	Name                  string              `json:"name"`
+	Description           string              `json:"description"`
+	DispatchStrategy      string              `json:"dispatch_strategy"`
+	LastDispatchedAgentID *string             `json:"last_dispatched_agent_id,omitempty"`
+	Members               []string            `json:"members"`
+	Skills                map[string][]string `json:"skills,omitempty"`
+	QueueDepth            int                 `json:"queue_depth"`
+	CreatedAt             string              `json:"created_at"`
+	UpdatedAt             string              `json:"updated_at"`
+}
+
+func (h *GroupHandler) toGroupResponse(ctx context.Context, g db.AgentGroup) (GroupResponse, error) {
+	members, err := h.store.ListAgentGroupMemberships(ctx, g.ID)
+	if err != nil {
+		return GroupResponse{}, err
+	}
+	depth, err := h.store.CountQueuedTasksByGroup(ctx, g.ID)
+	if err != nil {
+		return GroupResponse{}, err
+	}
+
+	resp := GroupResponse{
+		ID:                    g.ID,
+		Name:                  g.Name,
+		Description:           g.Description.String,
+		DispatchStrategy:      g.DispatchStrategy,
+		LastDispatchedAgentID: strPtr(g.LastDispatchedAgentID.String, g.LastDispatchedAgentID.Valid),
+		Members:               make([]string, len(members)),
+		QueueDepth:            int(depth),
+		CreatedAt:             nullTimeToString(g.CreatedAt),
+		UpdatedAt:             nullTimeToString(g.UpdatedAt),
+	}
+	for i, m := range members {
+		resp.Members[i] = m.AgentID
+		var skills []string
+		if m.Skills.Valid && json.Unmarshal([]byte(m.Skills.String), &skills) == nil && len(skills) > 0 {
+			if resp.Skills == nil {
+				resp.Skills = make(map[string][]string)
+			}
+			resp.Skills[m.AgentID] = skills
+		}
+	}
+	return resp, nil
+}
+
+// List all groups
+func (h *GroupHandler) List(c echo.Context) error {
+	ctx := c.Request().Context()
+	groups, err := h.store.ListAgentGroups(ctx)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	responses := make([]GroupResponse, len(groups))
+	for i, g := range groups {
+		if responses[i], err = h.toGroupResponse(ctx, g); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+	return c.JSON(http.StatusOK, responses)
+}
+
+// Get a single group with its members
+func (h *GroupHandler) Get(c echo.Context) error {
+	ctx := c.Request().Context()
+	group, err := h.store.GetAgentGroup(ctx, c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Group not found")
+	}
+	resp, err := h.toGroupResponse(ctx, group)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Create a new group, optionally with initial members
+func (h *GroupHandler) Create(c echo.Context) error {
+	var req CreateGroupRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.DispatchStrategy == "" {
+		req.DispatchStrategy = dispatch.Default
+	}
+	if !dispatch.Valid(req.DispatchStrategy) {
+		return echo.NewHTTPError(http.StatusBadRequest, "unsupported dispatch_strategy")
+	}
+
+	ctx := c.Request().Context()
+	for _, agentID := range req.Members {
+		if _, err := h.store.GetAgent(ctx, agentID); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Agent not found")
+		}
+	}
+
+	group, err := h.store.CreateAgentGroup(ctx, db.CreateAgentGroupParams{
+		Name:             req.Name,
+		Description:      sql.NullString{String: req.Description, Valid: req.Description != ""},
+		DispatchStrategy: req.DispatchStrategy,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return echo.NewHTTPError(http.StatusConflict, "A group with this name already exists")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	for _, agentID := range req.Members {
+		if err := h.store.AddAgentGroupMember(ctx, group.ID, agentID, req.Skills[agentID]); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	h.logEvent(ctx, "group_created", fmt.Sprintf("Group created: %s", group.Name),
+		fmt.Sprintf(`{"group_id":%q}`, group.ID))
+
+	resp, err := h.toGroupResponse(ctx, group)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// Update a group's name or description
+func (h *GroupHandler) Update(c echo.Context) error {
+	var req UpdateGroupRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	existing, err := h.store.GetAgentGroup(ctx, c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Group not found")
+	}
+
+	params := db.UpdateAgentGroupParams{
+		ID:               existing.ID,
+		Name:             existing.Name,
+		Description:      existing.Description,
+		DispatchStrategy: existing.DispatchStrategy,
+	}
+	if name := strings.TrimSpace(req.Name); name != "" {
+		params.Name = name
+	}
+	if req.Description != nil {
+		params.Description = sql.NullString{String: *req.Description, Valid: *req.Description != ""}
+	}
+	if req.DispatchStrategy != "" {
+		if !dispatch.Valid(req.DispatchStrategy) {
+			return echo.NewHTTPError(http.StatusBadRequest, "unsupported dispatch_strategy")
+		}
+		params.DispatchStrategy = req.DispatchStrategy
+	}
+
+	group, err := h.store.UpdateAgentGroup(ctx, params)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return echo.NewHTTPError(http.StatusConflict, "A group with this name already exists")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	resp, err := h.toGroupResponse(ctx, group)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Delete a group. Tasks still waiting in its shared queue are moved back to
+// the unassigned backlog so they are not stranded.
+func (h *GroupHandler) Delete(c echo.Context) error {
+	ctx := c.Request().Context()
+	group, err := h.store.GetAgentGroup(ctx, c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Group not found")
+	}
+
+	queued, err := h.store.ListQueuedTasksByGroup(ctx, group.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	for _, task := range queued {
+		if err := h.store.UpdateTaskStatus(ctx, task.ID, "backlog"); err != nil {
+			log.Printf("[GroupHandler] Error returning task %s to backlog: %v", task.ID, err)
+			continue
+		}
+		if h.hub != nil {
+			h.hub.BroadcastTaskStatus(task.ID, "backlog", 0)
+		}
+	}
+
+	if err := h.store.DeleteAgentGroup(ctx, group.ID); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	h.logEvent(ctx, "group_deleted",
+		fmt.Sprintf("Group deleted: %s (%d queued tasks returned to backlog)", group.Name, len(queued)),
+		fmt.Sprintf(`{"group_id":%q,"returned_tasks":%d}`, group.ID, len(queued)))
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// AddMember - POST /api/v1/groups/:id/members
+// Adding an existing member updates its skills. A free member may be handed
+// work from the shared queue right away.
+func (h *GroupHandler) AddMember(c echo.Context) error {
+	var req AddGroupMemberRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	group, err := h.store.GetAgentGroup(ctx, c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Group not found")
+	}
+	if _, err := h.store.GetAgent(ctx, req.AgentID); err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Agent not found")
+	}
+
+	if err := h.store.AddAgentGroupMember(ctx, group.ID, req.AgentID, req.Skills); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	h.logEvent(ctx, "group_member_added",
+		fmt.Sprintf("Agent %s joined group %s", req.AgentID, group.Name),
+		fmt.Sprintf(`{"group_id":%q,"agent_id":%q}`, group.ID, req.AgentID))
+
+	if h.dispatcher != nil {
+		h.dispatcher.DispatchGroupQueue(ctx, group.ID)
+	}
+
+	resp, err := h.toGroupResponse(ctx, group)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// RemoveMember - DELETE /api/v1/groups/:id/members/:agentId
+// Tasks the agent already claimed stay with the agent.
+func (h *GroupHandler) RemoveMember(c echo.Context) error {
+	ctx := c.Request().Context()
+	group, err := h.store.GetAgentGroup(ctx, c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Group not found")
+	}
+
+	agentID := c.Param("agentId")
+	if err := h.store.RemoveAgentGroupMember(ctx, group.ID, agentID); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	h.logEvent(ctx, "group_member_removed",
+		fmt.Sprintf("Agent %s left group %s", agentID, group.Name),
+		fmt.Sprintf(`{"group_id":%q,"agent_id":%q}`, group.ID, agentID))
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetQueue - GET /api/v1/groups/:id/queue
+// Returns the unclaimed tasks in the group's shared queue in dispatch order.
+func (h *GroupHandler) GetQueue(c echo.Context) error {
+	ctx := c.Request().Context()
+	group, err := h.store.GetAgentGroup(ctx, c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Group not found")
+	}
+
+	queued, err := h.store.ListQueuedTasksByGroup(ctx, group.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"group_id":    group.ID,
+		"queue_depth": len(queued),
+		"tasks":       ToTaskResponses(queued),
+	})
+}
+
+func (h *GroupHandler) logEvent(ctx context.Context, eventType, message, details string) {
+	event, err := h.store.CreateEvent(ctx, db.CreateEventParams{
+		Type:    eventType,
+		Message: message,
+		Details: sql.NullString{String: details, Valid: details != ""},
+	})
+	if err != nil {
+		log.Printf("[GroupHandler] Failed to create event (%s): %v", eventType, err)
+		return
+	}
+	if h.hub != nil {
+		h.hub.BroadcastEvent(event)
+	}
+}
@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store/storemock"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/validation"
+)
+
+// storemock.Store stands in for *store.Store in every handler.
+var (
+	_ AgentHandlerStore           = (*storemock.Store)(nil)
+	_ TaskHandlerStore            = (*storemock.Store)(nil)
+	_ ProjectHandlerStore         = (*storemock.Store)(nil)
+	_ CommentHandlerStore         = (*storemock.Store)(nil)
+	_ TrashHandlerStore           = (*storemock.Store)(nil)
+	_ ReportingHandlerStore       = (*storemock.Store)(nil)
+	_ SummaryHandlerStore         = (*storemock.Store)(nil)
+	_ GatewayHandlerStore         = (*storemock.Store)(nil)
+	_ ExperimentHandlerStore      = (*storemock.Store)(nil)
+	_ SecretHandlerStore          = (*storemock.Store)(nil)
+	_ GroupHandlerStore           = (*storemock.Store)(nil)
+	_ MaintenanceHandlerStore     = (*storemock.Store)(nil)
+	_ BackupHandlerStore          = (*storemock.Store)(nil)
+	_ ScorecardHandlerStore       = (*storemock.Store)(nil)
+	_ JiraHandlerStore            = (*storemock.Store)(nil)
+	_ GitHubHandlerStore          = (*storemock.Store)(nil)
+	_ EmailHandlerStore           = (*storemock.Store)(nil)
+	_ AvailabilityHandlerStore    = (*storemock.Store)(nil)
+	_ ChatHandlerStore            = (*storemock.Store)(nil)
+	_ GraphQLHandlerStore         = (*storemock.Store)(nil)
+	_ AnalyticsExportHandlerStore = (*storemock.Store)(nil)
+	_ RoutingHandlerStore         = (*storemock.Store)(nil)
+	_ QuietHoursHandlerStore      = (*storemock.Store)(nil)
+	_ NotifyTimeoutHandlerStore   = (*storemock.Store)(nil)
+	_ TaskScriptHandlerStore      = (*storemock.Store)(nil)
+	_ StorageHandlerStore         = (*storemock.Store)(nil)
+	_ CalendarHandlerStore        = (*storemock.Store)(nil)
+)
+
+// serve runs handler on a request for target with path parameters named
+// and valued by params ("id", "task-1", ...) and returns the status it
+// answers with, an error's included, and the response recorder.
+func serve(t *testing.T, handler echo.HandlerFunc, method, target, body string, params ...string) (int, *httptest.ResponseRecorder) {
+	t.Helper()
+	e := echo.New()
+	e.Validator = validation.New()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	var names, values []string
+	for i := 0; i+1 < len(params); i += 2 {
+		names, values = append(names, params[i]), append(values, params[i+1])
+	}
+	c.SetParamNames(names...)
+	c.SetParamValues(values...)
+	if err := handler(c); err != nil {
+		var he *echo.HTTPError
+		if !errors.As(err, &he) {
+			t.Fatalf("%s %s: %v", method, target, err)
+		}
+		return he.Code, rec
+	}
+	return rec.Code, rec
+}
+
+func TestCommentCreateLinksReferencedTasks(t *testing.T) {
+	m := storemock.New()
+	m.TaskStore.GetTaskFunc = func(ctx context.Context, id string) (db.Task, error) {
+		return db.Task{ID: id, Title: "Ship the importer"}, nil
+	}
+	m.TaskStore.GetTaskByShortIDFunc = func(ctx context.Context, shortID string) (db.Task, error) {
+		if shortID != "MC-7" {
+			return db.Task{}, sql.ErrNoRows
+		}
+		return db.Task{ID: "task-7"}, nil
+	}
+	var created db.CreateCommentParams
+	m.CommentStore.CreateCommentFunc = func(ctx context.Context, params db.CreateCommentParams) (db.Comment, error) {
+		created = params
+		return db.Comment{ID: params.ID, TaskID: params.TaskID, Author: params.Author, Content: params.Content}, nil
+	}
+	var linked []string
+	m.TaskLinkStore.SetTaskLinksFunc = func(ctx context.Context, sourceTaskID, sourceKind, sourceID string, targetIDs []string) error {
+		if sourceTaskID != "task-1" || sourceID != created.ID {
+			t.Errorf("links recorded from %s %s, want task-1's comment %s", sourceTaskID, sourceID, created.ID)
+		}
+		linked = targetIDs
+		return nil
+	}
+
+	h := NewCommentHandler(m, nil)
+	code, rec := serve(t, h.Create, http.MethodPost, "/api/v1/tasks/task-1/comments",
+		`{"author": "user", "content": "Blocked by MC-7."}`, "id", "task-1")
+	if code != http.StatusCreated {
+		t.Fatalf("status %d: %s", code, rec.Body)
+	}
+	if created.TaskID != "task-1" || created.Author != "user" || created.Content != "Blocked by MC-7." {
+		t.Errorf("comment created as %+v", created)
+	}
+	if len(linked) != 1 || linked[0] != "task-7" {
+		t.Errorf("links to %v, want [task-7]", linked)
+	}
+}
+
+func TestCommentCreateOnMissingTask(t *testing.T) {
+	m := storemock.New()
+	m.TaskStore.GetTaskFunc = func(ctx context.Context, id string) (db.Task, error) {
+		return db.Task{}, sql.ErrNoRows
+	}
+
+	h := NewCommentHandler(m, nil)
+	code, _ := serve(t, h.Create, http.MethodPost, "/api/v1/tasks/gone/comments",
+		`{"author": "user", "content": "Still there?"}`, "id", "gone")
+	if code != http.StatusNotFound {
+		t.Fatalf("status %d, want 404", code)
+	}
+	if n := m.CommentStore.Calls("CreateComment"); n != 0 {
+		t.Errorf("CreateComment called %d times", n)
+	}
+}
+
+func TestCommentCreateValidates(t *testing.T) {
+	m := storemock.New()
+	m.TaskStore.GetTaskFunc = func(ctx context.Context, id string) (db.Task, error) {
+		return db.Task{ID: id}, nil
+	}
+
+	h := NewCommentHandler(m, nil)
+	code, _ := serve(t, h.Create, http.MethodPost, "/api/v1/tasks/task-1/comments",
+		`{"author": "user"}`, "id", "task-1")
+	if code != http.StatusBadRequest {
+		t.Fatalf("status %d, want 400", code)
+	}
+	if n := m.CommentStore.Calls("CreateComment"); n != 0 {
+		t.Errorf("CreateComment called %d times", n)
+	}
+}
+
+func TestProjectGetNotFound(t *testing.T) {
+	m := storemock.New()
+	m.ProjectStore.GetProjectWithStatsFunc = func(ctx context.Context, id string) (db.GetProjectWithStatsRow, error) {
+		return db.GetProjectWithStatsRow{}, sql.ErrNoRows
+	}
+
+	h := NewProjectHandler(m)
+	if code, _ := serve(t, h.Get, http.MethodGet, "/api/v1/projects/nope", "", "id", "nope"); code != http.StatusNotFound {
+		t.Fatalf("status %d, want 404", code)
+	}
+}
+
+func TestAgentRunRefusesGatewayAgents(t *testing.T) {
+	m := storemock.New()
+	m.AgentStore.GetAgentFunc = func(ctx context.Context, id string) (db.Agent, error) {
+		return db.Agent{ID: id, DeliveryMethod: sql.NullString{String: openclaw.DeliveryGateway, Valid: true}}, nil
+	}
+	sender := openclaw.NewFakeSender()
+
+	h := NewAgentHandler(m, nil, sender, true, time.Minute)
+	code, rec := serve(t, h.RunCommand, http.MethodPost, "/api/v1/agents/remote/run",
+		`{"prompt": "Summarize the open PRs"}`, "id", "remote")
+	if code != http.StatusConflict {
+		t.Fatalf("status %d, want 409: %s", code, rec.Body)
+	}
+	if n := m.EventStore.Calls("CreateEvent"); n != 0 {
+		t.Errorf("%d events logged for a refused run", n)
+	}
+	if sent := sender.Sent(); len(sent) != 0 {
+		t.Errorf("sent %d messages for a refused run", len(sent))
+	}
+}
+
+func TestGraphQLBatchesEachLevel(t *testing.T) {
+	m := storemock.New()
+	task := func(id, agentID, parentID string) db.Task {
+		return db.Task{ID: id, Title: "Task " + id, Status: sql.NullString{String: "todo", Valid: true},
+			AgentID:      sql.NullString{String: agentID, Valid: true},
+			ParentTaskID: sql.NullString{String: parentID, Valid: parentID != ""}}
+	}
+	m.TaskStore.ListTasksFunc = func(ctx context.Context) ([]db.Task, error) {
+		return []db.Task{task("t1", "dev", ""), task("t2", "ops", ""), task("t3", "dev", "")}, nil
+	}
+	m.TaskStore.ListTasksByParentIDsFunc = func(ctx context.Context, parentIDs []string) ([]db.Task, error) {
+		if len(parentIDs) != 3 {
+			t.Errorf("subtasks of %v, want of all three tasks at once", parentIDs)
+		}
+		return []db.Task{task("s1", "qa", "t1"), task("s2", "docs", "t1"), task("s3", "qa", "t3")}, nil
+	}
+	m.AgentStore.ListAgentsByIDsFunc = func(ctx context.Context, ids []string) ([]db.Agent, error) {
+		var agents []db.Agent
+		for _, id := range ids {
+			agents = append(agents, db.Agent{ID: id, Name: strings.ToUpper(id)})
+		}
+		return agents, nil
+	}
+
+	h := NewGraphQLHandler(m)
+	code, rec := serve(t, h.Query, http.MethodPost, "/api/v1/graphql",
+		`{"query": "{ tasks { id agent { name } subtasks { id agent { name } } } }"}`)
+	if code != http.StatusOK {
+		t.Fatalf("status %d: %s", code, rec.Body)
+	}
+	want := `{"data":{"tasks":[` +
+		`{"id":"t1","agent":{"name":"DEV"},"subtasks":[{"id":"s1","agent":{"name":"QA"}},{"id":"s2","agent":{"name":"DOCS"}}]},` +
+		`{"id":"t2","agent":{"name":"OPS"},"subtasks":[]},` +
+		`{"id":"t3","agent":{"name":"DEV"},"subtasks":[{"id":"s3","agent":{"name":"QA"}}]}]}}`
+	if got := strings.TrimSpace(rec.Body.String()); got != want {
+		t.Errorf("response\n%s\nwant\n%s", got, want)
+	}
+	// One query per level: the tasks' agents, then the subtasks' agents
+	if n := m.TaskStore.Calls("ListTasksByParentIDs"); n != 1 {
+		t.Errorf("ListTasksByParentIDs called %d times, want 1", n)
+	}
+	if n := m.AgentStore.Calls("ListAgentsByIDs"); n != 2 {
+		t.Errorf("ListAgentsByIDs called %d times, want 2", n)
+	}
+}
+
+func TestGraphQLErrors(t *testing.T) {
+	m := storemock.New()
+	m.TaskStore.GetTaskByShortIDFunc = func(ctx context.Context, shortID string) (db.Task, error) {
+		return db.Task{}, sql.ErrNoRows
+	}
+	h := NewGraphQLHandler(m)
+
+	tests := []struct {
+		name, query, want string
+	}{
+		{"missing task", `{ task(id: \"MC-404\") { id } }`, `"data":{"task":null}`},
+		{"bad limit", `{ tasks(limit: 501) { id } }`, `limit must be between 1 and 500`},
+		{"unknown field", `{ tasks { owner } }`, `Cannot query field \"owner\" on type \"Task\"`},
+		{"too deep", `{ tasks { parent { parent { parent { parent { parent { parent { parent { parent { parent { parent { id } } } } } } } } } } } }`, `exceeds max depth 10`},
+	}
+	for _, tt := range tests {
+		code, rec := serve(t, h.Query, http.MethodPost, "/api/v1/graphql", `{"query": "`+tt.query+`"}`)
+		if code != http.StatusOK || !strings.Contains(rec.Body.String(), tt.want) {
+			t.Errorf("%s: %d %s, want %s", tt.name, code, rec.Body, tt.want)
+		}
+	}
+	if code, _ := serve(t, h.Query, http.MethodPost, "/api/v1/graphql", `{"query": " "}`); code != http.StatusBadRequest {
+		t.Errorf("empty query: status %d, want 400", code)
+	}
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/api/apierror"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/hooks"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+)
+
+// SetHooks sets the hooks run before tasks are dispatched and as they
+// complete or fail.
+func (h *TaskHandler) SetHooks(r *hooks.Runner) {
+	h.hooks = r
+}
+
+// runHooks runs the hooks of event on task and saves the changes they make
+// and the comments they add. It returns the task as changed and what the
+// hooks decided; a veto is recorded as a hook_vetoed event.
+func (h *TaskHandler) runHooks(ctx context.Context, event string, task db.Task, errMsg string) (db.Task, hooks.Result) {
+	if !h.hooks.Has(event) {
+		return task, hooks.Result{}
+	}
+	res := h.hooks.Run(ctx, hooks.Payload{
+		Event:  event,
+		Task:   ToTaskResponse(task),
+		Error:  errMsg,
+		DryRun: openclaw.IsDryRun(ctx),
+	})
+
+	for _, c := range res.Comments {
+		if _, err := h.addComment(ctx, db.CreateCommentParams{
+			TaskID:  task.ID,
+			Author:  "hook:" + c.Hook,
+			Content: c.Content,
+		}); err != nil {
+			log.Printf("[TaskHandler] Failed to add comment of hook %s to task %s: %v", c.Hook, task.ID, err)
+		}
+	}
+	if !res.Changes.Empty() {
+		updated, err := h.applyHookChanges(ctx, task, res.Changes)
+		if err != nil {
+			log.Printf("[TaskHandler] Failed to save hook changes to task %s: %v", task.ID, err)
+		} else {
+			task = updated
+			details, _ := json.Marshal(map[string]interface{}{"event": event, "hooks": res.Ran, "changes": res.Changes})
+			h.logEvent(ctx, task.ID, task.AgentID.String, "hook_changed_task",
+				fmt.Sprintf("Hooks changed the task on %s", event), string(details))
+		}
+	}
+	if res.Vetoed {
+		details, _ := json.Marshal(map[string]string{"event": event, "hook": res.VetoedBy, "reason": res.Reason})
+		h.logEvent(ctx, task.ID, task.AgentID.String, "hook_vetoed",
+			fmt.Sprintf("Hook %s vetoed %s: %s", res.VetoedBy, event, res.Reason), string(details))
+	}
+	return task, res
+}
+
+// applyHookChanges saves the fields hooks set on task.
+func (h *TaskHandler) applyHookChanges(ctx context.Context, task db.Task, changes hooks.Changes) (db.Task, error) {
+	params := db.UpdateTaskParams{
+		ID:             task.ID,
+		Title:          task.Title,
+		Description:    task.Description,
+		AgentID:        task.AgentID,
+		ProjectID:      task.ProjectID,
+		Status:         task.Status,
+		Priority:       task.Priority,
+		ProjectMd:      task.ProjectMd,
+		RequirementsMd: task.RequirementsMd,
+		RoadmapMd:      task.RoadmapMd,
+		StateMd:        task.StateMd,
+		PrdJson:        task.PrdJson,
+		ProgressTxt:    task.ProgressTxt,
+		GitBranch:      task.GitBranch,
+		QualityChecks:  task.QualityChecks,
+		DelegationMode: task.DelegationMode,
+		ScheduledAt:    task.ScheduledAt,
+		RetryAt:        task.RetryAt,
+	}
+	if changes.Title != nil && *changes.Title != "" {
+		params.Title = *changes.Title
+	}
+	if changes.Description != nil {
+		params.Description = sql.NullString{String: *changes.Description, Valid: true}
+	}
+	if changes.Priority != nil && *changes.Priority >= 1 && *changes.Priority <= 5 {
+		params.Priority = sql.NullInt64{Int64: int64(*changes.Priority), Valid: true}
+	}
+	return h.store.UpdateTask(ctx, params)
+}
+
+// vetoDispatch runs the pre_dispatch hooks before task is handed to
+// agentID. If one vetoes, the task is left in the backlog with the reason as
+// a comment, and vetoDispatch returns true.
+func (h *TaskHandler) vetoDispatch(ctx context.Context, agentID string, task db.Task) (db.Task, bool) {
+	task, res := h.runHooks(ctx, hooks.PreDispatch, task, "")
+	if !res.Vetoed {
+		return task, false
+	}
+	h.recordAttempt(ctx, task.ID, store.AttemptSend, agentID, "vetoed by hook "+res.VetoedBy, store.AttemptVetoed, res.Reason)
+	if task.Status.String != "backlog" {
+		if err := h.store.UpdateTaskStatus(ctx, task.ID, "backlog"); err != nil {
+			log.Printf("[TaskHandler] Failed to return vetoed task %s to backlog: %v", task.ID, err)
+		} else {
+			task.Status = sql.NullString{String: "backlog", Valid: true}
+			if h.hub != nil {
+				h.hub.BroadcastTaskStatus(task.ID, "backlog", 0)
+			}
+		}
+	}
+	h.addComment(ctx, db.CreateCommentParams{
+		TaskID:  task.ID,
+		Author:  "system",
+		Content: fmt.Sprintf("[Dispatch Vetoed] Hook %s kept this task from being sent to %s: %s", res.VetoedBy, agentID, res.Reason),
+	})
+	return task, true
+}
+
+// vetoStatus runs the hooks of event (post_complete or on_fail) before task
+// is saved as done or failed, errMsg being why it failed. It returns the
+// task as they changed it, or a 409 hook_vetoed error if one vetoed.
+func (h *TaskHandler) vetoStatus(ctx context.Context, event string, task db.Task, errMsg string) (db.Task, error) {
+	task, res := h.runHooks(ctx, event, task, errMsg)
+	if !res.Vetoed {
+		return task, nil
+	}
+	return task, apierror.WithDetails(http.StatusConflict, apierror.HookVetoed,
+		fmt.Sprintf("Hook %s vetoed %s: %s", res.VetoedBy, event, res.Reason),
+		map[string]string{"event": event, "hook": res.VetoedBy, "reason": res.Reason})
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/jira"
+)
+
+// JiraHandler imports JIRA issues as tasks and pushes task status changes
+// back to them.
+type JiraHandler struct {
+	store  JiraHandlerStore
+	syncer *jira.Syncer // nil when JIRA_URL is not set
+}
+
+func NewJiraHandler(s JiraHandlerStore, syncer *jira.Syncer) *JiraHandler {
+	return &JiraHandler{store: s, syncer: syncer}
+}
+
+// JiraImportRequest is the body of a JIRA import.
+type JiraImportRequest struct {
+	JiraProject string `json:"jira_project"` // JIRA project key; imports all its issues
+	JQL         string `json:"jql"`          // a search of its own instead
+	ProjectID   string `json:"project_id"`   // Mission Control project for the tasks
+}
+
+type JiraLinkResponse struct {
+	TaskID       string `json:"task_id"`
+	IssueKey     string `json:"issue_key"`
+	JiraProject  string `json:"jira_project"`
+	JiraStatus   string `json:"jira_status"`
+	SyncedStatus string `json:"synced_status"`
+	CreatedAt    string `json:"created_at"`
+	SyncedAt     string `json:"synced_at"`
+}
+
+func toJiraLinkResponse(l db.JiraLink) JiraLinkResponse {
+	return JiraLinkResponse{
+		TaskID:       l.TaskID,
+		IssueKey:     l.IssueKey,
+		JiraProject:  l.JiraProject,
+		JiraStatus:   l.JiraStatus,
+		SyncedStatus: l.SyncedStatus,
+		CreatedAt:    nullTimeToString(l.CreatedAt),
+		SyncedAt:     nullTimeToString(l.SyncedAt),
+	}
+}
+
+func (h *JiraHandler) checkEnabled() error {
+	if h.syncer == nil {
+		return echo.NewHTTPError(http.StatusForbidden, "JIRA integration is disabled")
+	}
+	return nil
+}
+
+// Import - POST /api/v1/integrations/jira/import
+// Imports the issues of a JIRA project (or matching a JQL search) as tasks.
+// Issues imported before update their task instead of creating another.
+func (h *JiraHandler) Import(c echo.Context) error {
+	if err := h.checkEnabled(); err != nil {
+		return err
+	}
+	var req JiraImportRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+	if req.JiraProject == "" && req.JQL == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "jira_project or jql is required")
+	}
+	ctx := c.Request().Context()
+	if req.ProjectID != "" {
+		if _, err := h.store.GetProject(ctx, req.ProjectID); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Project not found")
+		}
+	}
+
+	result, err := h.syncer.Import(ctx, jira.ImportRequest{
+		JiraProject: req.JiraProject,
+		JQL:         req.JQL,
+		ProjectID:   req.ProjectID,
+	})
+	var refused *echo.HTTPError
+	if errors.As(err, &refused) {
+		// Maintenance mode, or a task script rejected an issue's task
+		return refused
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadGateway, err.Error())
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// Push - POST /api/v1/integrations/jira/push
+// Pushes task status changes to the linked issues now, rather than at the
+// next periodic push.
+func (h *JiraHandler) Push(c echo.Context) error {
+	if err := h.checkEnabled(); err != nil {
+		return err
+	}
+	pushed, err := h.syncer.PushStatuses(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, map[string]int{"pushed": pushed})
+}
+
+// ListLinks - GET /api/v1/integrations/jira/links
+// Lists which tasks were imported from which issues.
+func (h *JiraHandler) ListLinks(c echo.Context) error {
+	links, err := h.store.ListJiraLinks(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	responses := make([]JiraLinkResponse, 0, len(links))
+	for _, l := range links {
+		responses = append(responses, toJiraLinkResponse(l))
+	}
+	return c.JSON(http.StatusOK, responses)
+}
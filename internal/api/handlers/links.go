@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"log"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/taskrefs"
+)
+
+// taskLinkStore is what recording task references needs; the task and
+// comment handler stores both satisfy it.
+type taskLinkStore interface {
+	GetTask(ctx context.Context, id string) (db.Task, error)
+	GetTaskByShortID(ctx context.Context, shortID string) (db.Task, error)
+	store.TaskLinkStore
+}
+
+// TaskLinkResponse is a related task, as seen from the task it was read on.
+type TaskLinkResponse struct {
+	TaskID    string  `json:"task_id"`
+	Title     string  `json:"title"`
+	Status    *string `json:"status,omitempty"`
+	Source    string  `json:"source"`    // description | comment
+	SourceID  string  `json:"source_id"` // task ID or comment ID holding the reference
+	CreatedAt string  `json:"created_at"`
+}
+
+// syncTaskLinks records the tasks referenced in text, replacing the links
+// previously recorded for the same description or comment. References that
+// match no task (or, for short codes, more than one) are ignored.
+func syncTaskLinks(ctx context.Context, st taskLinkStore, sourceTaskID, sourceKind, sourceID, text string) {
+	var targets []string
+	for _, ref := range taskrefs.Find(text) {
+		switch ref.Kind {
+		case taskrefs.RefID:
+			if _, err := st.GetTask(ctx, ref.Value); err == nil {
+				targets = append(targets, ref.Value)
+			}
+			continue
+		case taskrefs.RefShortID:
+			if task, err := st.GetTaskByShortID(ctx, ref.Value); err == nil {
+				targets = append(targets, task.ID)
+			}
+			continue
+		}
+		id, err := st.ResolveTaskPrefix(ctx, ref.Value)
+		if err != nil {
+			log.Printf("[TaskLinks] Error resolving #%s: %v", ref.Value, err)
+			continue
+		}
+		if id != "" {
+			targets = append(targets, id)
+		}
+	}
+	if err := st.SetTaskLinks(ctx, sourceTaskID, sourceKind, sourceID, targets); err != nil {
+		log.Printf("[TaskLinks] Error recording links from %s %s: %v", sourceKind, sourceID, err)
+	}
+}
+
+// taskLinks returns the tasks taskID refers to and the tasks referring to it.
+func taskLinks(ctx context.Context, st taskLinkStore, taskID string) (links, backlinks []TaskLinkResponse) {
+	links = []TaskLinkResponse{}
+	backlinks = []TaskLinkResponse{}
+	out, err := st.ListTaskLinks(ctx, taskID)
+	if err != nil {
+		log.Printf("[TaskLinks] Error listing links of task %s: %v", taskID, err)
+	}
+	for _, l := range out {
+		links = append(links, TaskLinkResponse{
+			TaskID:    l.TargetTaskID,
+			Title:     l.Title,
+			Status:    strPtr(l.Status.String, l.Status.Valid),
+			Source:    l.SourceKind,
+			SourceID:  l.SourceID,
+			CreatedAt: nullTimeToString(l.CreatedAt),
+		})
+	}
+	in, err := st.ListTaskBacklinks(ctx, taskID)
+	if err != nil {
+		log.Printf("[TaskLinks] Error listing backlinks of task %s: %v", taskID, err)
+	}
+	for _, l := range in {
+		backlinks = append(backlinks, TaskLinkResponse{
+			TaskID:    l.SourceTaskID,
+			Title:     l.Title,
+			Status:    strPtr(l.Status.String, l.Status.Valid),
+			Source:    l.SourceKind,
+			SourceID:  l.SourceID,
+			CreatedAt: nullTimeToString(l.CreatedAt),
+		})
+	}
+	return links, backlinks
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/localtime"
+)
+
+// localize adds the pending times of t (scheduled_at, retry_at,
+// deferred_until) in loc next to their UTC values.
+func (r *TaskResponse) localize(t db.Task, loc *time.Location) {
+	if loc == nil {
+		return
+	}
+	name := loc.String()
+	r.Timezone = &name
+	local := func(nt sql.NullTime) *string {
+		if !nt.Valid {
+			return nil
+		}
+		s := localtime.Format(nt.Time, loc)
+		return &s
+	}
+	r.ScheduledAtLocal = local(t.ScheduledAt)
+	r.RetryAtLocal = local(t.RetryAt)
+	r.DeferredUntilLocal = local(t.DeferredUntil)
+}
+
+// taskResponses returns the responses to tasks with their latest results
+// and their pending times also in the reader's time zone (?tz= or
+// X-Timezone), else in the timezone of each task's agent. Tasks with neither
+// are in UTC only.
+func (h *TaskHandler) taskResponses(ctx context.Context, tasks ...db.Task) []TaskResponse {
+	resp := ToTaskResponses(tasks)
+	h.addLatestResults(ctx, resp)
+	h.addLabels(ctx, resp)
+	if loc := localtime.FromContext(ctx); loc != nil {
+		for i := range resp {
+			resp[i].localize(tasks[i], loc)
+		}
+		return resp
+	}
+
+	var ids []string
+	seen := map[string]bool{}
+	for _, t := range tasks {
+		if t.AgentID.Valid && t.AgentID.String != "" && !seen[t.AgentID.String] {
+			seen[t.AgentID.String] = true
+			ids = append(ids, t.AgentID.String)
+		}
+	}
+	if len(ids) == 0 {
+		return resp
+	}
+	agents, err := h.store.ListAgentsByIDs(ctx, ids)
+	if err != nil {
+		log.Printf("[TaskHandler] Error loading agent time zones: %v", err)
+		return resp
+	}
+	zones := make(map[string]*time.Location, len(agents))
+	for _, a := range agents {
+		if !a.Timezone.Valid {
+			continue
+		}
+		if loc, err := localtime.Load(a.Timezone.String); err == nil {
+			zones[a.ID] = loc
+		}
+	}
+	for i, t := range tasks {
+		resp[i].localize(t, zones[t.AgentID.String])
+	}
+	return resp
+}
+
+// taskResponse is taskResponses for one task.
+func (h *TaskHandler) taskResponse(ctx context.Context, t db.Task) TaskResponse {
+	return h.taskResponses(ctx, t)[0]
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/maintenance"
+	ws "github.com/abelkuruvilla/claw-agent-mission-control/internal/websocket"
+)
+
+// MaintenanceHandler turns maintenance mode on and off.
+type MaintenanceHandler struct {
+	store MaintenanceHandlerStore
+	mode  *maintenance.Mode
+	hub   *ws.Hub
+}
+
+func NewMaintenanceHandler(s MaintenanceHandlerStore, mode *maintenance.Mode, hub *ws.Hub) *MaintenanceHandler {
+	return &MaintenanceHandler{store: s, mode: mode, hub: hub}
+}
+
+// LoadMaintenance returns the maintenance mode kept in settings, off if it
+// cannot be read.
+func LoadMaintenance(ctx context.Context, s MaintenanceHandlerStore) *maintenance.Mode {
+	settings, err := s.GetSettings(ctx)
+	if err != nil || !settings.MaintenanceSince.Valid {
+		return maintenance.New(maintenance.State{})
+	}
+	since := settings.MaintenanceSince.Time.UTC()
+	log.Printf("[MaintenanceHandler] Maintenance mode is on since %s", since.Format(time.RFC3339))
+	return maintenance.New(maintenance.State{Enabled: true, Reason: settings.MaintenanceReason.String, Since: &since})
+}
+
+type MaintenanceRequest struct {
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason"`
+}
+
+// Get - GET /api/v1/admin/maintenance
+func (h *MaintenanceHandler) Get(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.mode.State())
+}
+
+// Set - POST /api/v1/admin/maintenance
+// Turns maintenance mode on ({"enabled": true, "reason": "..."}) or off.
+func (h *MaintenanceHandler) Set(c echo.Context) error {
+	var req MaintenanceRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+	ctx := c.Request().Context()
+	was := h.mode.Enabled()
+
+	var since time.Time
+	if req.Enabled {
+		since = time.Now().UTC()
+		if prev := h.mode.State(); prev.Since != nil {
+			since = *prev.Since
+		}
+	}
+	if err := h.store.SetMaintenance(ctx, since, req.Reason); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	state := h.mode.Set(req.Enabled, req.Reason)
+
+	switch {
+	case req.Enabled && !was:
+		message := "Maintenance mode on: background dispatching paused, new tasks refused"
+		if req.Reason != "" {
+			message += " (" + req.Reason + ")"
+		}
+		log.Printf("[MaintenanceHandler] %s", message)
+		h.logEvent(ctx, "maintenance_started", message, "")
+	case !req.Enabled && was:
+		log.Printf("[MaintenanceHandler] Maintenance mode off")
+		h.logEvent(ctx, "maintenance_ended", "Maintenance mode off: dispatching resumed", "")
+	}
+	return c.JSON(http.StatusOK, state)
+}
+
+func (h *MaintenanceHandler) logEvent(ctx context.Context, eventType, message, details string) {
+	event, err := h.store.CreateEvent(ctx, db.CreateEventParams{
+		Type:    eventType,
+		Message: message,
+		Details: sql.NullString{String: details, Valid: details != ""},
+	})
+	if err != nil {
+		log.Printf("[MaintenanceHandler] Failed to create event (%s): %v", eventType, err)
+		return
+	}
+	if h.hub != nil {
+		h.hub.BroadcastEvent(event)
+	}
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/mentions"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/notifyprefs"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+)
+
+// MentionNotifier tells agents @-mentioned in a task's description or a
+// comment on it. TaskHandler implements it.
+type MentionNotifier interface {
+	// NotifyMentions tells the agents text mentions; source is description
+	// or comment, sourceID the task or comment ID. Agents previous (the
+	// text before an edit) already mentioned are not told again.
+	NotifyMentions(ctx context.Context, task db.Task, source, sourceID, author, text, previous string)
+}
+
+var _ MentionNotifier = (*TaskHandler)(nil)
+
+// NotifyMentions records a mention event for each registered agent text
+// mentions and sends it a mention notification; its reply is kept as a
+// comment. An author mentioning itself is not told, nor a task's agent
+// mentioned in its description.
+func (h *TaskHandler) NotifyMentions(ctx context.Context, task db.Task, source, sourceID, author, text, previous string) {
+	handles := mentions.Find(text)
+	if len(handles) == 0 {
+		return
+	}
+	agents, err := h.store.ListAgents(ctx)
+	if err != nil {
+		log.Printf("[TaskHandler] Error listing agents for mentions on task %s: %v", task.ID, err)
+		return
+	}
+	byHandle := make(map[string]string)
+	for _, a := range agents {
+		for _, hd := range mentions.Handles(a.ID, a.MentionPatterns.String) {
+			if _, taken := byHandle[hd]; !taken {
+				byHandle[hd] = a.ID
+			}
+		}
+	}
+	already := make(map[string]bool)
+	for _, hd := range mentions.Find(previous) {
+		already[byHandle[hd]] = true
+	}
+	already[author] = true
+	if source == store.LinkFromDescription {
+		// The task's own agent gets the description with its assignment
+		already[task.AgentID.String] = true
+	}
+
+	for _, hd := range handles {
+		agentID, ok := byHandle[hd]
+		if !ok || already[agentID] {
+			continue
+		}
+		already[agentID] = true
+
+		message := fmt.Sprintf("Agent %s mentioned in the task %s", agentID, source)
+		if source == store.LinkFromComment {
+			message = fmt.Sprintf("Agent %s mentioned in a comment", agentID)
+		}
+		if author != "" {
+			message += " by " + author
+		}
+		details, _ := json.Marshal(map[string]string{"source": source, "source_id": sourceID, "author": author})
+		h.logEvent(ctx, task.ID, agentID, "mention", message, string(details))
+
+		if h.agentSender == nil || !h.pushes(ctx, agentID, notifyprefs.Mention) {
+			continue
+		}
+		sender, callback := h.scoped(ctx, task.ID, agentID, func(ctx context.Context, tID, aID, reply string, sendErr error) {
+			if sendErr != nil {
+				log.Printf("[TaskHandler] Failed to tell agent %s of its mention on task %s: %v", aID, tID, sendErr)
+				return
+			}
+			if reply != "" {
+				h.saveResult(ctx, tID, aID, store.ResultMention, reply,
+					map[string]string{"source": source, "source_id": sourceID})
+			}
+		})
+		sender.NotifyMentionAsync(agentID, task.ID, task.Title, author, source, text, callback)
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/notifytimeout"
+)
+
+// NotifyTimeoutHandler manages the system-wide notification timeouts: how
+// long each attempt to deliver a notification to an agent may take, by
+// dispatch type. Agents can override them (notification_timeouts).
+type NotifyTimeoutHandler struct {
+	store NotifyTimeoutHandlerStore
+}
+
+func NewNotifyTimeoutHandler(s NotifyTimeoutHandlerStore) *NotifyTimeoutHandler {
+	return &NotifyTimeoutHandler{store: s}
+}
+
+// NotifyTimeoutsResponse is the notification timeouts and the timeout each
+// dispatch type ends up with for an agent without its own.
+type NotifyTimeoutsResponse struct {
+	notifytimeout.Timeouts
+	EffectiveSeconds map[string]int `json:"effective_seconds"`
+}
+
+func toNotifyTimeoutsResponse(t notifytimeout.Timeouts) NotifyTimeoutsResponse {
+	resp := NotifyTimeoutsResponse{Timeouts: t, EffectiveSeconds: map[string]int{}}
+	for _, typ := range notifytimeout.Types {
+		resp.EffectiveSeconds[typ] = int(notifytimeout.Resolve(notifytimeout.Timeouts{}, t, typ).Seconds())
+	}
+	return resp
+}
+
+// Get - GET /api/v1/settings/notification-timeouts
+func (h *NotifyTimeoutHandler) Get(c echo.Context) error {
+	settings, err := h.store.GetSettings(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusOK, toNotifyTimeoutsResponse(notifytimeout.Timeouts{}))
+	}
+	timeouts, err := notifytimeout.Parse(settings.NotificationTimeouts.String)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, toNotifyTimeoutsResponse(timeouts))
+}
+
+// Update - PUT /api/v1/settings/notification-timeouts
+// Replaces the timeouts; {} goes back to 5 minutes for every type.
+func (h *NotifyTimeoutHandler) Update(c echo.Context) error {
+	var timeouts notifytimeout.Timeouts
+	if err := bind(c, &timeouts); err != nil {
+		return err
+	}
+	if err := timeouts.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	stored := ""
+	if !timeouts.Empty() {
+		b, err := json.Marshal(timeouts)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		stored = string(b)
+	}
+	if err := h.store.SetNotificationTimeouts(c.Request().Context(), stored); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	log.Printf("[NotifyTimeoutHandler] Notification timeouts updated")
+	return c.JSON(http.StatusOK, toNotifyTimeoutsResponse(timeouts))
+}
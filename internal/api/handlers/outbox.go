@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
+)
+
+// OutboxHandler exposes the notifications captured in dry-run mode, and
+// counts of the messages sent to agents.
+type OutboxHandler struct {
+	agentSender openclaw.Sender
+}
+
+func NewOutboxHandler(agentSender openclaw.Sender) *OutboxHandler {
+	return &OutboxHandler{agentSender: agentSender}
+}
+
+type SetDryRunRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// List - GET /api/v1/outbox
+func (h *OutboxHandler) List(c echo.Context) error {
+	entries := h.agentSender.Outbox().List()
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"dry_run": h.agentSender.DryRun(),
+		"data":    entries,
+		"meta": map[string]interface{}{
+			"total": len(entries),
+		},
+	})
+}
+
+// Clear - DELETE /api/v1/outbox
+func (h *OutboxHandler) Clear(c echo.Context) error {
+	h.agentSender.Outbox().Clear()
+	return c.NoContent(http.StatusNoContent)
+}
+
+// SetDryRun - PUT /api/v1/outbox/dry-run
+// Toggles global dry-run mode at runtime (initial value comes from NOTIFY_DRY_RUN).
+func (h *OutboxHandler) SetDryRun(c echo.Context) error {
+	var req SetDryRunRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+	h.agentSender.SetDryRun(req.Enabled)
+	return c.JSON(http.StatusOK, map[string]bool{"dry_run": req.Enabled})
+}
+
+// MessageStats - GET /api/v1/notifications/stats
+// Counts the messages sent to agents since the server started, and how many
+// were truncated to fit NOTIFY_MAX_MESSAGE_SIZE.
+func (h *OutboxHandler) MessageStats(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.agentSender.MessageStats())
+}
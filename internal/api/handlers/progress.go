@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"math"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	ws "github.com/abelkuruvilla/claw-agent-mission-control/internal/websocket"
+)
+
+// progressStore is what maintaining task progress needs; the task and
+// reporting handler stores both satisfy it.
+type progressStore interface {
+	GetTask(ctx context.Context, id string) (db.Task, error)
+	GetStoryProgress(ctx context.Context, taskID string) (passed, total int64, err error)
+	ListPhasesByTask(ctx context.Context, taskID string) ([]db.Phase, error)
+	SetTaskProgress(ctx context.Context, id string, percent int, explicit bool) error
+}
+
+// progressFraction returns t's progress as a fraction (0-1), the unit of
+// task.status WebSocket messages.
+func progressFraction(t db.Task) float64 {
+	return float64(t.Progress.Int64) / 100
+}
+
+// ProgressRequest reports a task's progress explicitly. A null progress
+// goes back to deriving it.
+type ProgressRequest struct {
+	Progress *int `json:"progress"` // percentage, 0-100
+}
+
+// derivedProgress returns the progress of taskID derived from its work
+// breakdown: the share of passed stories, else the share of done phases,
+// counting inFlight (0-1) of one more phase that is under way. ok is false
+// for tasks with neither stories nor phases.
+func derivedProgress(ctx context.Context, st progressStore, taskID string, inFlight float64) (percent int, ok bool) {
+	passed, total, err := st.GetStoryProgress(ctx, taskID)
+	if err != nil {
+		log.Printf("[Progress] Error counting stories of task %s: %v", taskID, err)
+		return 0, false
+	}
+	if total > 0 {
+		return int(math.Round(float64(passed) * 100 / float64(total))), true
+	}
+
+	phases, err := st.ListPhasesByTask(ctx, taskID)
+	if err != nil || len(phases) == 0 {
+		return 0, false
+	}
+	done := 0.0
+	for _, p := range phases {
+		if p.Status.String == "done" {
+			done++
+		}
+	}
+	if done < float64(len(phases)) {
+		done += math.Max(0, math.Min(inFlight, 1))
+	}
+	return int(math.Round(done * 100 / float64(len(phases)))), true
+}
+
+// refreshTaskProgress re-derives the progress of taskID after its stories or
+// phases changed, unless progress was reported explicitly, and broadcasts a
+// change on hub (if not nil). inFlight is the reported progress (0-1) of a
+// phase under way.
+func refreshTaskProgress(ctx context.Context, st progressStore, hub *ws.Hub, taskID string, inFlight float64) {
+	task, err := st.GetTask(ctx, taskID)
+	if err != nil || task.ProgressExplicit {
+		return
+	}
+	percent, ok := derivedProgress(ctx, st, taskID, inFlight)
+	if !ok || (task.Progress.Valid && task.Progress.Int64 == int64(percent)) {
+		return
+	}
+	if err := st.SetTaskProgress(ctx, taskID, percent, false); err != nil {
+		log.Printf("[Progress] Error updating progress of task %s: %v", taskID, err)
+		return
+	}
+	if hub != nil {
+		hub.BroadcastTaskStatus(taskID, task.Status.String, float64(percent)/100)
+	}
+}
@@ -1,47 +1,85 @@
 package handlers
 
 import (
+	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/google/uuid"
 
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/pathpolicy"
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/taskrefs"
 )
 
 type ProjectHandler struct {
-	store *store.Store
+	store ProjectHandlerStore
+	tasks ProjectTaskRemover
 }
 
-func NewProjectHandler(s *store.Store) *ProjectHandler {
+// ProjectTaskRemover winds down the tasks cancelled or deleted with their
+// project; see TaskHandler.ProjectTasksRemoved.
+type ProjectTaskRemover interface {
+	ProjectTasksRemoved(ctx context.Context, tasks []db.Task, deleted bool)
+}
+
+func NewProjectHandler(s ProjectHandlerStore) *ProjectHandler {
 	return &ProjectHandler{
 		store: s,
 	}
 }
 
+// SetTaskRemover sets what winds down the tasks of deleted projects.
+func (h *ProjectHandler) SetTaskRemover(r ProjectTaskRemover) {
+	h.tasks = r
+}
+
 // Request types
 type CreateProjectRequest struct {
 	Name        string `json:"name" validate:"required"`
 	Description string `json:"description"`
-	Status      string `json:"status"`   // "active" | "completed" | "on-hold"
+	Status      string `json:"status" validate:"omitempty,oneof=active completed on-hold"`
 	Color       string `json:"color"`    // hex color string
 	Location    string `json:"location"` // project directory path
 	DefaultBranch     string `json:"default_branch"`
 	LocalExecBranch  string `json:"local_exec_branch"`
 	RemoteMergeBranch string `json:"remote_merge_branch"`
+	Key               string `json:"key"` // short ID prefix of the project's tasks, e.g. MC
+	AllowedPaths      []string `json:"allowed_paths"` // directories or globs agents may touch; relative ones are under location
+	PolicyAction      string   `json:"policy_action" validate:"omitempty,oneof=warn pause"`
+	MaxSubtaskDepth       *int     `json:"max_subtask_depth"`       // delegation limits of the project's tasks,
+	MaxConcurrentSubtasks *int     `json:"max_concurrent_subtasks"` // omitted = the server defaults
+	GitHubRepo            string   `json:"github_repo"`  // owner/name whose issues labelled github_label become tasks
+	GitHubLabel           string   `json:"github_label"` // default mission-control
 }
 
 type UpdateProjectRequest struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
-	Status      string `json:"status"`
+	Status      string `json:"status" validate:"omitempty,oneof=active completed on-hold"`
 	Color       string `json:"color"`
 	Location    string `json:"location"`
 	DefaultBranch     string `json:"default_branch"`
 	LocalExecBranch  string `json:"local_exec_branch"`
 	RemoteMergeBranch string `json:"remote_merge_branch"`
+	Key               string `json:"key"` // short ID prefix of the project's tasks, e.g. MC
+	AllowedPaths      *[]string `json:"allowed_paths"` // nil leaves them unchanged, [] lifts the restriction
+	PolicyAction      string    `json:"policy_action" validate:"omitempty,oneof=warn pause"`
+	MaxSubtaskDepth       *int      `json:"max_subtask_depth"`       // nil leaves a limit unchanged,
+	MaxConcurrentSubtasks *int      `json:"max_concurrent_subtasks"` // -1 removes it
+	GitHubRepo            *string   `json:"github_repo"`  // nil leaves GitHub sync unchanged, "" turns it off
+	GitHubLabel           *string   `json:"github_label"`
 }
 
 // Response types
@@ -55,32 +93,33 @@ type ProjectResponse struct {
 	DefaultBranch     string `json:"default_branch"`
 	LocalExecBranch  string `json:"local_exec_branch"`
 	RemoteMergeBranch string `json:"remote_merge_branch"`
+	Key               string `json:"key,omitempty"`
+	AllowedPaths      []string `json:"allowed_paths,omitempty"`
+	PolicyAction      string   `json:"policy_action,omitempty"`
+	MaxSubtaskDepth       *int `json:"max_subtask_depth,omitempty"`
+	MaxConcurrentSubtasks *int `json:"max_concurrent_subtasks,omitempty"`
+	GitHubRepo            string `json:"github_repo,omitempty"`
+	GitHubLabel           string `json:"github_label,omitempty"`
 	CreatedAt   string `json:"created_at"`
 	UpdatedAt   string `json:"updated_at"`
 	TaskCount   int64  `json:"task_count,omitempty"`
 	DoneCount   int64  `json:"done_count,omitempty"`
+	LastActivityAt *string `json:"last_activity_at,omitempty"` // last change to one of its tasks
 }
 
-// List all projects
+// List all projects with stats
 func (h *ProjectHandler) List(c echo.Context) error {
 	status := c.QueryParam("status")
 
-	var projects []db.Project
-	var err error
-
-	if status != "" {
-		projects, err = h.store.ListProjectsByStatus(c.Request().Context(), sql.NullString{String: status, Valid: true})
-	} else {
-		projects, err = h.store.ListProjects(c.Request().Context())
-	}
-
+	// Counts come with the projects, in one query
+	projects, err := h.store.ListProjectsWithStats(c.Request().Context(), status)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
 	responses := make([]ProjectResponse, len(projects))
 	for i, p := range projects {
-		responses[i] = toProjectResponse(p)
+		responses[i] = toProjectStatsResponse(p.Project, p.TaskCount, p.DoneCount, p.LastActivity)
 	}
 
 	return c.JSON(http.StatusOK, responses)
@@ -89,32 +128,43 @@ func (h *ProjectHandler) List(c echo.Context) error {
 // Get a single project with stats
 func (h *ProjectHandler) Get(c echo.Context) error {
 	id := c.Param("id")
-	project, err := h.store.GetProject(c.Request().Context(), id)
+	p, err := h.store.GetProjectWithStats(c.Request().Context(), id)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusNotFound, "Project not found")
 	}
 
-	// Get task counts
-	taskCount, _ := h.store.GetProjectTaskCount(c.Request().Context(), sql.NullString{String: id, Valid: true})
-	doneCount, _ := h.store.GetProjectDoneTaskCount(c.Request().Context(), sql.NullString{String: id, Valid: true})
-
-	response := toProjectResponse(project)
-	response.TaskCount = taskCount
-	response.DoneCount = doneCount
-
-	return c.JSON(http.StatusOK, response)
+	return c.JSON(http.StatusOK, toProjectStatsResponse(p.Project, p.TaskCount, p.DoneCount, p.LastActivity))
 }
 
 // Create a new project
 func (h *ProjectHandler) Create(c echo.Context) error {
 	var req CreateProjectRequest
-	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	if err := bind(c, &req); err != nil {
+		return err
 	}
 
 	// Generate UUID for new project
 	id := uuid.New().String()
 
+	// Tasks are numbered under the project key; derive one from the name if none is given
+	key := strings.ToUpper(strings.TrimSpace(req.Key))
+	if key == "" {
+		key = taskrefs.DeriveKey(req.Name)
+	} else if !taskrefs.ValidKey(key) {
+		return echo.NewHTTPError(http.StatusBadRequest, "key must be 2-10 letters or digits, starting with a letter")
+	}
+
+	if err := checkPathPolicy(req.AllowedPaths, req.PolicyAction, req.Location); err != nil {
+		return err
+	}
+	if err := checkDelegationRequest(false, req.MaxSubtaskDepth, req.MaxConcurrentSubtasks); err != nil {
+		return err
+	}
+	githubLabel, err := checkGitHubSync(req.GitHubRepo, req.GitHubLabel)
+	if err != nil {
+		return err
+	}
+
 	// Set defaults
 	status := req.Status
 	if status == "" {
@@ -136,11 +186,35 @@ func (h *ProjectHandler) Create(c echo.Context) error {
 		DefaultBranch:     sql.NullString{String: req.DefaultBranch, Valid: req.DefaultBranch != ""},
 		LocalExecBranch:  sql.NullString{String: req.LocalExecBranch, Valid: req.LocalExecBranch != ""},
 		RemoteMergeBranch: sql.NullString{String: req.RemoteMergeBranch, Valid: req.RemoteMergeBranch != ""},
+		Key:               sql.NullString{String: key, Valid: key != ""},
 	})
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
+	if len(req.AllowedPaths) > 0 || req.PolicyAction != "" {
+		if err := h.store.SetProjectPathPolicy(c.Request().Context(), id, req.AllowedPaths, req.PolicyAction); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+	if req.MaxSubtaskDepth != nil || req.MaxConcurrentSubtasks != nil {
+		err := h.store.SetProjectDelegationLimits(c.Request().Context(), id,
+			limitParam(sql.NullInt64{}, req.MaxSubtaskDepth), limitParam(sql.NullInt64{}, req.MaxConcurrentSubtasks))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+	if req.GitHubRepo != "" {
+		if err := h.store.SetProjectGitHub(c.Request().Context(), id, req.GitHubRepo, githubLabel); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+	if len(req.AllowedPaths) > 0 || req.PolicyAction != "" || req.MaxSubtaskDepth != nil || req.MaxConcurrentSubtasks != nil || req.GitHubRepo != "" {
+		if project, err = h.store.GetProject(c.Request().Context(), id); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
 	return c.JSON(http.StatusCreated, toProjectResponse(project))
 }
 
@@ -148,8 +222,8 @@ func (h *ProjectHandler) Create(c echo.Context) error {
 func (h *ProjectHandler) Update(c echo.Context) error {
 	id := c.Param("id")
 	var req UpdateProjectRequest
-	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	if err := bind(c, &req); err != nil {
+		return err
 	}
 
 	// Get existing project first
@@ -184,6 +258,39 @@ func (h *ProjectHandler) Update(c echo.Context) error {
 		location = existing.Location.String
 	}
 
+	// A new key applies to tasks created from now on; existing short IDs stay
+	key := strings.ToUpper(strings.TrimSpace(req.Key))
+	if key == "" && existing.Key.Valid {
+		key = existing.Key.String
+	} else if key != "" && !taskrefs.ValidKey(key) {
+		return echo.NewHTTPError(http.StatusBadRequest, "key must be 2-10 letters or digits, starting with a letter")
+	}
+
+	allowedPaths, _ := pathpolicy.Parse(existing.AllowedPaths.String)
+	if req.AllowedPaths != nil {
+		allowedPaths = *req.AllowedPaths
+	}
+	policyAction := req.PolicyAction
+	if policyAction == "" {
+		policyAction = existing.PolicyAction.String
+	}
+	if err := checkPathPolicy(allowedPaths, policyAction, location); err != nil {
+		return err
+	}
+	if err := checkDelegationRequest(true, req.MaxSubtaskDepth, req.MaxConcurrentSubtasks); err != nil {
+		return err
+	}
+	githubRepo, githubLabel := existing.GithubRepo.String, existing.GithubLabel.String
+	if req.GitHubRepo != nil {
+		githubRepo = *req.GitHubRepo
+	}
+	if req.GitHubLabel != nil {
+		githubLabel = *req.GitHubLabel
+	}
+	if githubLabel, err = checkGitHubSync(githubRepo, githubLabel); err != nil {
+		return err
+	}
+
 	updated, err := h.store.UpdateProject(c.Request().Context(), db.UpdateProjectParams{
 		ID:          id,
 		Name:        name,
@@ -194,23 +301,139 @@ func (h *ProjectHandler) Update(c echo.Context) error {
 		DefaultBranch:     sql.NullString{String: req.DefaultBranch, Valid: req.DefaultBranch != ""},
 		LocalExecBranch:  sql.NullString{String: req.LocalExecBranch, Valid: req.LocalExecBranch != ""},
 		RemoteMergeBranch: sql.NullString{String: req.RemoteMergeBranch, Valid: req.RemoteMergeBranch != ""},
+		Key:               sql.NullString{String: key, Valid: key != ""},
 	})
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
+	if req.AllowedPaths != nil || req.PolicyAction != "" {
+		if err := h.store.SetProjectPathPolicy(c.Request().Context(), id, allowedPaths, policyAction); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+	if req.MaxSubtaskDepth != nil || req.MaxConcurrentSubtasks != nil {
+		err := h.store.SetProjectDelegationLimits(c.Request().Context(), id,
+			limitParam(existing.MaxSubtaskDepth, req.MaxSubtaskDepth),
+			limitParam(existing.MaxConcurrentSubtasks, req.MaxConcurrentSubtasks))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+	if req.GitHubRepo != nil || req.GitHubLabel != nil {
+		if err := h.store.SetProjectGitHub(c.Request().Context(), id, githubRepo, githubLabel); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+	if req.AllowedPaths != nil || req.PolicyAction != "" || req.MaxSubtaskDepth != nil || req.MaxConcurrentSubtasks != nil || req.GitHubRepo != nil || req.GitHubLabel != nil {
+		if updated, err = h.store.GetProject(c.Request().Context(), id); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
 	return c.JSON(http.StatusOK, toProjectResponse(updated))
 }
 
 // Delete a project
+// ProjectDeletionResponse is what deleting a project affects, as a dry run
+// reports it.
+type ProjectDeletionResponse struct {
+	ProjectID    string `json:"project_id"`
+	Tasks        string `json:"tasks"` // the strategy: orphan, archive or delete
+	TaskCount    int64  `json:"task_count"`
+	OpenTasks    int64  `json:"open_tasks"`
+	Secrets      int64  `json:"secrets"`
+	Experiments  int64  `json:"experiments"`
+	GitHubIssues int64  `json:"github_issues"` // issue links; the issues stay on GitHub
+	// ConfirmToken is to be passed as confirm when the deletion destroys
+	// tasks, secrets or experiments; empty if it destroys none
+	ConfirmToken string `json:"confirm_token,omitempty"`
+}
+
+// Delete - DELETE /api/v1/projects/:id?tasks=orphan|archive|delete
+// Deletes a project. Its tasks are kept without a project (orphan, the
+// default), kept with the open ones cancelled (archive) or deleted. Its
+// secrets, experiments and GitHub issue links always go with it.
+// dry_run=true only reports what would be affected, with the confirm token a
+// deletion destroying tasks, secrets or experiments needs; the token changes
+// when the counts do.
 func (h *ProjectHandler) Delete(c echo.Context) error {
 	id := c.Param("id")
-	if err := h.store.DeleteProject(c.Request().Context(), id); err != nil {
+	ctx := c.Request().Context()
+
+	mode := c.QueryParam("tasks")
+	switch mode {
+	case "":
+		mode = store.ProjectTasksOrphan
+	case store.ProjectTasksOrphan, store.ProjectTasksArchive, store.ProjectTasksDelete:
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, "tasks must be orphan, archive or delete")
+	}
+	dryRun := false
+	if v := c.QueryParam("dry_run"); v != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "dry_run must be true or false")
+		}
+	}
+
+	impact, err := h.store.GetProjectDeletionImpact(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+	}
+	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
+	resp := ProjectDeletionResponse{
+		ProjectID:    id,
+		Tasks:        mode,
+		TaskCount:    impact.Tasks,
+		OpenTasks:    impact.OpenTasks,
+		Secrets:      impact.Secrets,
+		Experiments:  impact.Experiments,
+		GitHubIssues: impact.GithubIssues,
+	}
+	if (mode == store.ProjectTasksDelete && impact.Tasks > 0) || impact.Secrets > 0 || impact.Experiments > 0 {
+		resp.ConfirmToken = projectDeletionToken(id, mode, impact)
+	}
+	if dryRun {
+		return c.JSON(http.StatusOK, resp)
+	}
+
+	if resp.ConfirmToken != "" {
+		switch c.QueryParam("confirm") {
+		case resp.ConfirmToken:
+		case "":
+			return echo.NewHTTPError(http.StatusBadRequest,
+				"Deleting this project destroys its tasks, secrets or experiments; pass the confirm token of a dry run (dry_run=true)")
+		default:
+			return echo.NewHTTPError(http.StatusConflict, "The project changed since the dry run; do it again to confirm")
+		}
+	}
+
+	tasks, err := h.store.DeleteProjectWithTasks(ctx, id, mode)
+	if errors.Is(err, sql.ErrNoRows) {
+		return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if h.tasks != nil && mode != store.ProjectTasksOrphan {
+		h.tasks.ProjectTasksRemoved(context.WithoutCancel(ctx), tasks, mode == store.ProjectTasksDelete)
+	}
+	log.Printf("[ProjectHandler] Deleted project %s (tasks=%s, %d tasks, %d open)", id, mode, impact.Tasks, impact.OpenTasks)
 	return c.NoContent(http.StatusNoContent)
 }
 
+// projectDeletionToken confirms deleting the project with mode as a dry run
+// reported it: it is derived from what the deletion affects, so it goes
+// stale when that changes.
+func projectDeletionToken(id, mode string, impact db.GetProjectDeletionImpactRow) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%d|%d|%d|%d|%d", id, mode,
+		impact.Tasks, impact.OpenTasks, impact.Secrets, impact.Experiments, impact.GithubIssues)))
+	return hex.EncodeToString(sum[:8])
+}
+
 // Get all tasks for a project
 func (h *ProjectHandler) ListTasks(c echo.Context) error {
 	id := c.Param("id")
@@ -229,8 +452,51 @@ func (h *ProjectHandler) ListTasks(c echo.Context) error {
 	return c.JSON(http.StatusOK, ToTaskResponses(tasks))
 }
 
+// githubRepoPattern matches GitHub repository names, owner/name.
+var githubRepoPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+
+// defaultGitHubLabel marks the issues of a synced repository that become
+// tasks when the project names no label.
+const defaultGitHubLabel = "mission-control"
+
+// checkGitHubSync validates a project's GitHub sync settings and returns
+// the label to use; without a repo there is no label.
+func checkGitHubSync(repo, label string) (string, error) {
+	if repo == "" {
+		return "", nil
+	}
+	if !githubRepoPattern.MatchString(repo) {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "github_repo must be owner/name")
+	}
+	if label = strings.TrimSpace(label); label == "" {
+		label = defaultGitHubLabel
+	}
+	return label, nil
+}
+
+// checkPathPolicy validates a project's path policy against its location.
+func checkPathPolicy(allowed []string, action, location string) error {
+	if action != "" && !pathpolicy.ValidAction(action) {
+		return echo.NewHTTPError(http.StatusBadRequest, "policy_action must be warn or pause")
+	}
+	if err := pathpolicy.Validate(allowed, location); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "allowed_paths: "+err.Error())
+	}
+	return nil
+}
+
+// projectPathPolicy returns the path policy of p.
+func projectPathPolicy(p db.Project) pathpolicy.Policy {
+	allowed, err := pathpolicy.Parse(p.AllowedPaths.String)
+	if err != nil {
+		log.Printf("[ProjectHandler] Ignoring path policy of project %s: %v", p.ID, err)
+	}
+	return pathpolicy.Policy{Allowed: allowed, Base: p.Location.String}
+}
+
 // Helper functions
 func toProjectResponse(p db.Project) ProjectResponse {
+	allowed, _ := pathpolicy.Parse(p.AllowedPaths.String)
 	return ProjectResponse{
 		ID:                p.ID,
 		Name:              p.Name,
@@ -241,11 +507,31 @@ func toProjectResponse(p db.Project) ProjectResponse {
 		DefaultBranch:     nullStringToString(p.DefaultBranch),
 		LocalExecBranch:   nullStringToString(p.LocalExecBranch),
 		RemoteMergeBranch: nullStringToString(p.RemoteMergeBranch),
+		Key:               nullStringToString(p.Key),
+		AllowedPaths:      allowed,
+		PolicyAction:      nullStringToString(p.PolicyAction),
+		MaxSubtaskDepth:       nullLimit(p.MaxSubtaskDepth),
+		MaxConcurrentSubtasks: nullLimit(p.MaxConcurrentSubtasks),
+		GitHubRepo:            nullStringToString(p.GithubRepo),
+		GitHubLabel:           nullStringToString(p.GithubLabel),
 		CreatedAt:         nullTimeToString(p.CreatedAt),
 		UpdatedAt:         nullTimeToString(p.UpdatedAt),
 	}
 }
 
+// toProjectStatsResponse is toProjectResponse with the task counts and the
+// last task change (Unix seconds, 0 for none).
+func toProjectStatsResponse(p db.Project, taskCount, doneCount, lastActivity int64) ProjectResponse {
+	resp := toProjectResponse(p)
+	resp.TaskCount = taskCount
+	resp.DoneCount = doneCount
+	if lastActivity > 0 {
+		at := time.Unix(lastActivity, 0).UTC().Format(time.RFC3339)
+		resp.LastActivityAt = &at
+	}
+	return resp
+}
+
 func nullStringToString(ns sql.NullString) string {
 	if ns.Valid {
 		return ns.String
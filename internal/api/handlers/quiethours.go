@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/quiethours"
+)
+
+// QuietHoursHandler manages the system-wide quiet hours, during which
+// dispatch of all but priority 1 tasks is deferred.
+type QuietHoursHandler struct {
+	store QuietHoursHandlerStore
+}
+
+func NewQuietHoursHandler(s QuietHoursHandlerStore) *QuietHoursHandler {
+	return &QuietHoursHandler{store: s}
+}
+
+// QuietHoursResponse is the quiet hours and whether it is quiet now.
+type QuietHoursResponse struct {
+	quiethours.Policy
+	Quiet      bool       `json:"quiet"`
+	QuietUntil *time.Time `json:"quiet_until,omitempty"`
+}
+
+func toQuietHoursResponse(p quiethours.Policy) QuietHoursResponse {
+	resp := QuietHoursResponse{Policy: p}
+	if until, quiet := p.Quiet(time.Now()); quiet {
+		until = until.UTC()
+		resp.Quiet, resp.QuietUntil = true, &until
+	}
+	return resp
+}
+
+// Get - GET /api/v1/settings/quiet-hours
+func (h *QuietHoursHandler) Get(c echo.Context) error {
+	settings, err := h.store.GetSettings(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusOK, toQuietHoursResponse(quiethours.Policy{}))
+	}
+	policy, err := quiethours.Parse(settings.QuietHours.String)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, toQuietHoursResponse(policy))
+}
+
+// Update - PUT /api/v1/settings/quiet-hours
+// Replaces the quiet hours; {"windows": []} without do_not_disturb_until
+// turns them off.
+func (h *QuietHoursHandler) Update(c echo.Context) error {
+	var policy quiethours.Policy
+	if err := bind(c, &policy); err != nil {
+		return err
+	}
+	if err := policy.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	stored := ""
+	if !policy.Empty() {
+		b, err := json.Marshal(policy)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		stored = string(b)
+	}
+	if err := h.store.SetQuietHours(c.Request().Context(), stored); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	log.Printf("[QuietHoursHandler] Quiet hours updated (%s)", policy)
+	return c.JSON(http.StatusOK, toQuietHoursResponse(policy))
+}
+
+// quietHours returns the quiet hours in settings. Unreadable ones are
+// logged and treated as never quiet.
+func (h *TaskHandler) quietHours(ctx context.Context) quiethours.Policy {
+	settings, err := h.store.GetSettings(ctx)
+	if err != nil {
+		return quiethours.Policy{}
+	}
+	policy, err := quiethours.Parse(settings.QuietHours.String)
+	if err != nil {
+		log.Printf("[TaskHandler] Ignoring quiet hours: %v", err)
+		return quiethours.Policy{}
+	}
+	return policy
+}
+
+// deferIfQuiet postpones dispatch of a task during quiet hours unless it is
+// priority 1: deferred_until is set to the end of the quiet period and a
+// task_deferred event says why. A task already deferred that long is left
+// alone. Reports whether dispatch was deferred.
+func (h *TaskHandler) deferIfQuiet(ctx context.Context, agentID string, task db.Task) bool {
+	policy := h.quietHours(ctx)
+	until, quiet := policy.Defer(time.Now(), int(task.Priority.Int64))
+	if !quiet {
+		return false
+	}
+	if task.DeferredUntil.Valid && !task.DeferredUntil.Time.Before(until) {
+		return true
+	}
+	if err := h.store.SetTaskDeferredUntil(ctx, task.ID, until); err != nil {
+		log.Printf("[TaskHandler] Error deferring task %s: %v", task.ID, err)
+		return true
+	}
+	log.Printf("[TaskHandler] Quiet hours, task %s for agent %s deferred until %s", task.ID, agentID, until.Format(time.RFC3339))
+	h.logEvent(ctx, task.ID, agentID, "task_deferred",
+		fmt.Sprintf("Dispatch to agent %s deferred until %s: quiet hours (%s)", agentID, until.Format(time.RFC3339), policy),
+		fmt.Sprintf(`{"reason":"quiet_hours","deferred_until":%q,"quiet_hours":%q}`, until.UTC().Format(time.RFC3339), policy.String()))
+	return true
+}
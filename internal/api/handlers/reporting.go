@@ -1,23 +1,28 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
-	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/pathpolicy"
 	ws "github.com/abelkuruvilla/claw-agent-mission-control/internal/websocket"
 )
 
 type ReportingHandler struct {
-	store *store.Store
+	store ReportingHandlerStore
 	hub   *ws.Hub
 }
 
-func NewReportingHandler(s *store.Store, hub *ws.Hub) *ReportingHandler {
+func NewReportingHandler(s ReportingHandlerStore, hub *ws.Hub) *ReportingHandler {
 	return &ReportingHandler{store: s, hub: hub}
 }
 
@@ -41,8 +46,8 @@ type PhaseFailRequest struct {
 func (h *ReportingHandler) UpdatePhaseProgress(c echo.Context) error {
 	phaseID := c.Param("id")
 	var req PhaseProgressRequest
-	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	if err := bind(c, &req); err != nil {
+		return err
 	}
 
 	// Get phase to find task
@@ -58,9 +63,13 @@ func (h *ReportingHandler) UpdatePhaseProgress(c echo.Context) error {
 		Message: req.Message,
 	})
 
+	// The phase under way counts toward the task's derived progress
+	refreshTaskProgress(c.Request().Context(), h.store, nil, phase.TaskID, req.Progress)
+
 	// Broadcast via WebSocket
 	if h.hub != nil {
-		h.hub.BroadcastTaskStatus(phase.TaskID, "executing", req.Progress)
+		task, _ := h.store.GetTask(c.Request().Context(), phase.TaskID)
+		h.hub.BroadcastTaskStatus(phase.TaskID, "executing", progressFraction(task))
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{"status": "progress_updated"})
@@ -69,8 +78,8 @@ func (h *ReportingHandler) UpdatePhaseProgress(c echo.Context) error {
 func (h *ReportingHandler) CompletePhase(c echo.Context) error {
 	phaseID := c.Param("id")
 	var req PhaseCompleteRequest
-	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	if err := bind(c, &req); err != nil {
+		return err
 	}
 
 	// Update phase status
@@ -86,6 +95,7 @@ func (h *ReportingHandler) CompletePhase(c echo.Context) error {
 		Type:    "phase_completed",
 		Message: req.Summary,
 	})
+	refreshTaskProgress(c.Request().Context(), h.store, h.hub, phase.TaskID, 0)
 
 	// Broadcast
 	if h.hub != nil {
@@ -101,8 +111,8 @@ func (h *ReportingHandler) CompletePhase(c echo.Context) error {
 func (h *ReportingHandler) FailPhase(c echo.Context) error {
 	phaseID := c.Param("id")
 	var req PhaseFailRequest
-	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	if err := bind(c, &req); err != nil {
+		return err
 	}
 
 	status := "failed"
@@ -137,14 +147,47 @@ type StoryFailRequest struct {
 }
 
 type ProgressTxtRequest struct {
-	Content string `json:"content"`
+	Content string `json:"content" validate:"required"`
+	Author  string `json:"author"` // defaults to the task's agent
+}
+
+// Progress entry listing pages.
+const (
+	defaultProgressPage = 50
+	maxProgressPage     = 500
+)
+
+// ProgressEntryResponse is one progress note of a task.
+type ProgressEntryResponse struct {
+	ID        int64  `json:"id"`
+	TaskID    string `json:"task_id"`
+	Author    string `json:"author,omitempty"`
+	Content   string `json:"content"`
+	CreatedAt string `json:"created_at"`
+}
+
+func toProgressEntryResponse(e db.ProgressEntry) ProgressEntryResponse {
+	return ProgressEntryResponse{
+		ID:        e.ID,
+		TaskID:    e.TaskID,
+		Author:    e.Author.String,
+		Content:   e.Content,
+		CreatedAt: nullTimeToString(e.CreatedAt),
+	}
+}
+
+// ReportFilesRequest declares the files an agent touched while working on a
+// task, for checking against the project's path policy.
+type ReportFilesRequest struct {
+	AgentID string   `json:"agent_id"` // defaults to the task's agent
+	Files   []string `json:"files" validate:"required"`
 }
 
 func (h *ReportingHandler) PassStory(c echo.Context) error {
 	storyID := c.Param("id")
 	var req StoryPassRequest
-	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	if err := bind(c, &req); err != nil {
+		return err
 	}
 
 	if err := h.store.MarkStoryPassed(c.Request().Context(), storyID); err != nil {
@@ -153,11 +196,18 @@ func (h *ReportingHandler) PassStory(c echo.Context) error {
 
 	story, _ := h.store.GetStory(c.Request().Context(), storyID)
 
+	// The commit is kept for linking the work, e.g. when closing a GitHub issue
+	details := sql.NullString{}
+	if req.CommitSHA != "" {
+		details = sql.NullString{String: fmt.Sprintf(`{"commit_sha":%q}`, req.CommitSHA), Valid: true}
+	}
 	h.store.CreateEvent(c.Request().Context(), db.CreateEventParams{
 		TaskID:  sql.NullString{String: story.TaskID, Valid: true},
 		Type:    "story_passed",
 		Message: "Story passed: " + story.Title,
+		Details: details,
 	})
+	refreshTaskProgress(c.Request().Context(), h.store, h.hub, story.TaskID, 0)
 
 	if h.hub != nil {
 		h.hub.Broadcast(&ws.Message{
@@ -172,8 +222,8 @@ func (h *ReportingHandler) PassStory(c echo.Context) error {
 func (h *ReportingHandler) FailStory(c echo.Context) error {
 	storyID := c.Param("id")
 	var req StoryFailRequest
-	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	if err := bind(c, &req); err != nil {
+		return err
 	}
 
 	if err := h.store.MarkStoryFailed(c.Request().Context(), storyID, req.Error); err != nil {
@@ -187,6 +237,7 @@ func (h *ReportingHandler) FailStory(c echo.Context) error {
 		Type:    "story_failed",
 		Message: req.Error,
 	})
+	refreshTaskProgress(c.Request().Context(), h.store, h.hub, story.TaskID, 0)
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"status":    "failed",
@@ -195,15 +246,204 @@ func (h *ReportingHandler) FailStory(c echo.Context) error {
 }
 
 func (h *ReportingHandler) AppendProgressTxt(c echo.Context) error {
-	taskID := c.Param("id")
 	var req ProgressTxtRequest
-	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.AppendProgress(c.Request().Context(), c.Param("id"), req.Author, req.Content); err != nil {
+		return err
 	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "appended"})
+}
 
-	if err := h.store.AppendProgressTxt(c.Request().Context(), taskID, req.Content); err != nil {
+// AppendProgress adds an entry to a task's progress log as
+// POST /tasks/:id/progress-txt does, for callers other than the API; author
+// defaults to the task's agent. Errors are *echo.HTTPError.
+func (h *ReportingHandler) AppendProgress(ctx context.Context, taskID, author, content string) error {
+	task, err := h.store.GetTask(ctx, taskID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+	}
+	if author == "" {
+		author = task.AgentID.String
+	}
+	if _, err := h.store.AddProgressEntry(ctx, task.ID, author, content); err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
+	return nil
+}
 
-	return c.JSON(http.StatusOK, map[string]string{"status": "appended"})
+// ListProgress - GET /api/v1/tasks/:id/progress?limit=&before=
+// Returns the task's progress entries newest first. When the page is full,
+// next_before is the cursor for the next (older) page.
+func (h *ReportingHandler) ListProgress(c echo.Context) error {
+	limit := int64(defaultProgressPage)
+	if raw := c.QueryParam("limit"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n < 1 || n > maxProgressPage {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxProgressPage))
+		}
+		limit = n
+	}
+	var before int64
+	if raw := c.QueryParam("before"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n < 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, "before must be a progress entry ID")
+		}
+		before = n
+	}
+
+	ctx := c.Request().Context()
+	task, err := h.store.GetTask(ctx, c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+	}
+	entries, err := h.store.ListProgressEntries(ctx, task.ID, before, limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	responses := make([]ProgressEntryResponse, len(entries))
+	for i, e := range entries {
+		responses[i] = toProgressEntryResponse(e)
+	}
+	resp := map[string]interface{}{"entries": responses}
+	if int64(len(entries)) == limit {
+		resp["next_before"] = entries[len(entries)-1].ID
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// ReportFiles checks the files an agent declares it touched against the
+// path policy of the task's project. Violations are recorded as a
+// policy_violation event, and pause the task if the project says so.
+func (h *ReportingHandler) ReportFiles(c echo.Context) error {
+	var req ReportFilesRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	task, err := h.store.GetTask(ctx, c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+	}
+	agentID := req.AgentID
+	if agentID == "" {
+		agentID = task.AgentID.String
+	}
+
+	if task.ProjectID.Valid {
+		project, err := h.store.GetProject(ctx, task.ProjectID.String)
+		if err == nil {
+			if v := projectPathPolicy(project).Violations(req.Files); v != nil {
+				return c.JSON(http.StatusOK, h.recordViolation(ctx, task, project, agentID, v))
+			}
+		}
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":     "ok",
+		"violations": []string{},
+		"paused":     false,
+	})
+}
+
+// recordViolation logs a policy_violation for files touched outside the
+// allowed paths of project and pauses the task when the project's
+// policy action is pause. It returns the ReportFiles response.
+func (h *ReportingHandler) recordViolation(ctx context.Context, task db.Task, project db.Project, agentID string, files []string) map[string]interface{} {
+	status := task.Status.String
+	paused := false
+	if project.PolicyAction.String == pathpolicy.ActionPause && status != "paused" &&
+		status != "done" && status != "failed" && status != "cancelled" {
+		if err := h.store.UpdateTaskStatus(ctx, task.ID, "paused"); err != nil {
+			log.Printf("[ReportingHandler] Error pausing task %s: %v", task.ID, err)
+		} else {
+			paused = true
+			if h.hub != nil {
+				h.hub.BroadcastTaskStatus(task.ID, "paused", 0)
+			}
+		}
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"files":         files,
+		"allowed_paths": projectPathPolicy(project).Resolved(),
+		"paused":        paused,
+	})
+	message := fmt.Sprintf("Agent %s touched files outside the allowed paths of project %s: %s",
+		agentID, project.Name, strings.Join(files, ", "))
+	if paused {
+		message += " (task paused)"
+	}
+	event, err := h.store.CreateEvent(ctx, db.CreateEventParams{
+		TaskID:  sql.NullString{String: task.ID, Valid: true},
+		AgentID: sql.NullString{String: agentID, Valid: agentID != ""},
+		Type:    "policy_violation",
+		Message: message,
+		Details: sql.NullString{String: string(details), Valid: true},
+	})
+	if err != nil {
+		log.Printf("[ReportingHandler] Failed to create event (policy_violation): %v", err)
+	} else if h.hub != nil {
+		h.hub.BroadcastEvent(event)
+	}
+
+	return map[string]interface{}{
+		"status":     "violation",
+		"violations": files,
+		"paused":     paused,
+	}
+}
+
+// UpdateProgress - POST /api/v1/tasks/:id/progress
+// Sets the task's progress explicitly; a null progress goes back to deriving
+// it from the task's stories and phases.
+func (h *ReportingHandler) UpdateProgress(c echo.Context) error {
+	var req ProgressRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+	task, err := h.SetProgress(c.Request().Context(), c.Param("id"), req.Progress)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, ToTaskResponse(task))
+}
+
+// SetProgress sets a task's progress as POST /tasks/:id/progress does, for
+// callers other than the API; nil goes back to deriving it. Errors are
+// *echo.HTTPError.
+func (h *ReportingHandler) SetProgress(ctx context.Context, taskID string, progress *int) (db.Task, error) {
+	if progress != nil && (*progress < 0 || *progress > 100) {
+		return db.Task{}, echo.NewHTTPError(http.StatusBadRequest, "progress must be between 0 and 100")
+	}
+
+	task, err := h.store.GetTask(ctx, taskID)
+	if err != nil {
+		return db.Task{}, echo.NewHTTPError(http.StatusNotFound, "Task not found")
+	}
+
+	if progress != nil {
+		err = h.store.SetTaskProgress(ctx, task.ID, *progress, true)
+	} else {
+		err = h.store.ClearTaskProgress(ctx, task.ID)
+		if err == nil {
+			refreshTaskProgress(ctx, h.store, nil, task.ID, 0)
+		}
+	}
+	if err != nil {
+		return db.Task{}, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	task, err = h.store.GetTask(ctx, task.ID)
+	if err != nil {
+		return db.Task{}, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if h.hub != nil {
+		h.hub.BroadcastTaskStatus(task.ID, task.Status.String, progressFraction(task))
+	}
+	return task, nil
 }
@@ -2,61 +2,108 @@ package handlers
 
 import (
 	"database/sql"
+	"encoding/json"
 	
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/notifyprefs"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
 )
 
 // Clean response types that serialize properly to JSON
 // These avoid the sql.NullString {String: "", Valid: bool} issue
 
 type AgentResponse struct {
-	ID               string  `json:"id"`
-	Name             string  `json:"name"`
-	Description      *string `json:"description,omitempty"`
-	Status           string  `json:"status"`
-	WorkspacePath    *string `json:"workspace_path,omitempty"`
-	AgentDirPath     *string `json:"agent_dir_path,omitempty"`
-	Model            *string `json:"model,omitempty"`
-	MentionPatterns  *string `json:"mention_patterns,omitempty"`
-	SoulMD           *string `json:"soul_md,omitempty"`
-	AgentsMD         *string `json:"agents_md,omitempty"`
-	IdentityMD       *string `json:"identity_md,omitempty"`
-	UserMD           *string `json:"user_md,omitempty"`
-	ToolsMD          *string `json:"tools_md,omitempty"`
-	HeartbeatMD      *string `json:"heartbeat_md,omitempty"`
-	MemoryMD         *string `json:"memory_md,omitempty"`
-	ActiveSessionKey *string `json:"active_session_key,omitempty"`
-	CurrentTaskID    *string `json:"current_task_id,omitempty"`
-	CreatedAt        string  `json:"created_at"`
-	UpdatedAt        string  `json:"updated_at"`
+	ID                   string            `json:"id"`
+	Name                 string            `json:"name"`
+	Description          *string           `json:"description,omitempty"`
+	Status               string            `json:"status"`
+	WorkspacePath        *string           `json:"workspace_path,omitempty"`
+	AgentDirPath         *string           `json:"agent_dir_path,omitempty"`
+	Model                *string           `json:"model,omitempty"`
+	MentionPatterns      *string           `json:"mention_patterns,omitempty"`
+	SoulMD               *string           `json:"soul_md,omitempty"`
+	AgentsMD             *string           `json:"agents_md,omitempty"`
+	IdentityMD           *string           `json:"identity_md,omitempty"`
+	UserMD               *string           `json:"user_md,omitempty"`
+	ToolsMD              *string           `json:"tools_md,omitempty"`
+	HeartbeatMD          *string           `json:"heartbeat_md,omitempty"`
+	MemoryMD             *string           `json:"memory_md,omitempty"`
+	ActiveSessionKey     *string           `json:"active_session_key,omitempty"`
+	CurrentTaskID        *string           `json:"current_task_id,omitempty"`
+	Locale               *string           `json:"locale,omitempty"`
+	Timezone             *string           `json:"timezone,omitempty"`
+	WorkingHours         json.RawMessage   `json:"working_hours,omitempty"`
+	ManagedExternally    bool              `json:"managed_externally"`
+	CallbackURL          *string           `json:"callback_url,omitempty"`
+	DeliveryMethod       string            `json:"delivery_method"`
+	CallbackSecret       *string           `json:"callback_secret,omitempty"` // only on responses that (re)issue it
+	GatewayID            *string           `json:"gateway_id,omitempty"`      // unset = the default gateway
+	NotificationPrefs    notifyprefs.Prefs `json:"notification_prefs"`
+	NotificationTimeouts json.RawMessage   `json:"notification_timeouts,omitempty"` // unset = the system's
+	PublicAPIURL         *string           `json:"public_api_url,omitempty"`        // unset = the server's
+	CreatedAt            string            `json:"created_at"`
+	UpdatedAt            string            `json:"updated_at"`
+	// IdentityGeneration reports how the identity files of an agent just
+	// created were generated
+	IdentityGeneration *openclaw.IdentityGeneration `json:"identity_generation,omitempty"`
 }
 
 type TaskResponse struct {
-	ID             string  `json:"id"`
-	Title          string  `json:"title"`
-	Description    *string `json:"description,omitempty"`
-	AgentID        *string `json:"agent_id,omitempty"`
-	ProjectID      *string `json:"project_id,omitempty"`
-	ParentTaskID   *string `json:"parent_task_id,omitempty"`
-	Status         string  `json:"status"`
-	Priority       int     `json:"priority"`
-	GitBranch      *string `json:"git_branch,omitempty"`
-	ProjectMD      *string `json:"project_md,omitempty"`
-	RequirementsMD *string `json:"requirements_md,omitempty"`
-	RoadmapMD      *string `json:"roadmap_md,omitempty"`
-	StateMD        *string `json:"state_md,omitempty"`
-	PrdJSON        *string `json:"prd_json,omitempty"`
-	ProgressTxt    *string `json:"progress_txt,omitempty"`
-	QualityChecks  *string `json:"quality_checks,omitempty"`
-	DelegationMode string  `json:"delegation_mode"`
-	CreatedAt      string  `json:"created_at"`
-	UpdatedAt      string  `json:"updated_at"`
-	StartedAt      *string `json:"started_at,omitempty"`
-	CompletedAt    *string `json:"completed_at,omitempty"`
-	ScheduledAt    *string `json:"scheduled_at,omitempty"`
-	RetryAt        *string `json:"retry_at,omitempty"`
-	StoriesTotal   int     `json:"stories_total,omitempty"`
-	StoriesPassed  int     `json:"stories_passed,omitempty"`
+	ID                  string   `json:"id"`
+	ShortID             *string  `json:"short_id,omitempty"`
+	Title               string   `json:"title"`
+	Description         *string  `json:"description,omitempty"`
+	AgentID             *string  `json:"agent_id,omitempty"`
+	GroupID             *string  `json:"group_id,omitempty"`
+	ProjectID           *string  `json:"project_id,omitempty"`
+	ParentTaskID        *string  `json:"parent_task_id,omitempty"`
+	Status              string   `json:"status"`
+	Priority            int      `json:"priority"`
+	GitBranch           *string  `json:"git_branch,omitempty"`
+	ProjectMD           *string  `json:"project_md,omitempty"`
+	RequirementsMD      *string  `json:"requirements_md,omitempty"`
+	RoadmapMD           *string  `json:"roadmap_md,omitempty"`
+	StateMD             *string  `json:"state_md,omitempty"`
+	PrdJSON             *string  `json:"prd_json,omitempty"`
+	ProgressTxt         *string  `json:"progress_txt,omitempty"`
+	QualityChecks       *string  `json:"quality_checks,omitempty"`
+	DelegationMode      string   `json:"delegation_mode"`
+	CreatedAt           string   `json:"created_at"`
+	UpdatedAt           string   `json:"updated_at"`
+	StartedAt           *string  `json:"started_at,omitempty"`
+	CompletedAt         *string  `json:"completed_at,omitempty"`
+	ScheduledAt         *string  `json:"scheduled_at,omitempty"`
+	// Set on create and update when the requested scheduled_at had passed, so
+	// the task was dispatched now instead of scheduled
+	DispatchedImmediately bool `json:"dispatched_immediately,omitempty"`
+	RetryAt             *string  `json:"retry_at,omitempty"`
+	// The pending times above in Timezone, the reader's or the agent's
+	Timezone           *string `json:"timezone,omitempty"`
+	ScheduledAtLocal   *string `json:"scheduled_at_local,omitempty"`
+	RetryAtLocal       *string `json:"retry_at_local,omitempty"`
+	DeferredUntilLocal *string `json:"deferred_until_local,omitempty"`
+	QueuePosition       *int     `json:"queue_position,omitempty"`
+	DeferredUntil       *string  `json:"deferred_until,omitempty"`
+	StoriesTotal        int      `json:"stories_total,omitempty"`
+	StoriesPassed       int      `json:"stories_passed,omitempty"`
+	Progress            *int     `json:"progress,omitempty"`        // percentage, 0-100
+	ProgressSource      string   `json:"progress_source,omitempty"` // reported | derived
+	Secrets             []string `json:"secrets,omitempty"`         // names of the project secrets injected into its notification
+	ContextSummary      *string  `json:"context_summary,omitempty"`
+	ContextSummarizedAt *string  `json:"context_summarized_at,omitempty"`
+	FailureReason       *string  `json:"failure_reason,omitempty"` // why it last failed or was reset
+	Model               *string  `json:"model,omitempty"`          // set on the task, overriding the routing policy
+	RoutedModel         *string  `json:"routed_model,omitempty"`   // chosen when it was last dispatched
+	// Review before done, by another agent or a human user
+	RequiresReview  bool    `json:"requires_review,omitempty"`
+	ReviewerAgentID *string `json:"reviewer_agent_id,omitempty"`
+	ReviewerUser    *string `json:"reviewer_user,omitempty"`
+	// Delegation limits on subtasks of the task, if it sets its own
+	MaxSubtaskDepth       *int `json:"max_subtask_depth,omitempty"`
+	MaxConcurrentSubtasks *int `json:"max_concurrent_subtasks,omitempty"`
+	// The agent's latest reply to a notification about the task (see /results)
+	LatestResult *TaskResultSummary `json:"latest_result,omitempty"`
+	Labels       []string           `json:"labels,omitempty"` // set by triage
 }
 // Note: No "approach" field - all tasks use GSD for planning and Ralph Loop for execution
 
@@ -73,32 +120,68 @@ func strPtr(s string, valid bool) *string {
 	return &s
 }
 
+// rawJSON passes a stored JSON column through unchanged (nil when unset).
+func rawJSON(s sql.NullString) json.RawMessage {
+	if !s.Valid || s.String == "" {
+		return nil
+	}
+	return json.RawMessage(s.String)
+}
+
+// agentNotificationPrefs returns which notifications are pushed to a,
+// defaulting to everything.
+func agentNotificationPrefs(a db.Agent) notifyprefs.Prefs {
+	prefs, err := notifyprefs.Parse(a.NotificationPrefs.String)
+	if err != nil {
+		return notifyprefs.Default()
+	}
+	return prefs
+}
+
+// agentDeliveryMethod returns how notifications reach a, defaulting to the CLI.
+func agentDeliveryMethod(a db.Agent) string {
+	if a.DeliveryMethod.Valid && a.DeliveryMethod.String != "" {
+		return a.DeliveryMethod.String
+	}
+	return openclaw.DeliveryCLI
+}
+
 func ToAgentResponse(a db.Agent) AgentResponse {
 	status := "idle"
 	if a.Status.Valid {
 		status = a.Status.String
 	}
-	
+
 	return AgentResponse{
-		ID:               a.ID,
-		Name:             a.Name,
-		Description:      strPtr(a.Description.String, a.Description.Valid),
-		Status:           status,
-		WorkspacePath:    strPtr(a.WorkspacePath.String, a.WorkspacePath.Valid),
-		AgentDirPath:     strPtr(a.AgentDirPath.String, a.AgentDirPath.Valid),
-		Model:            strPtr(a.Model.String, a.Model.Valid),
-		MentionPatterns:  strPtr(a.MentionPatterns.String, a.MentionPatterns.Valid),
-		SoulMD:           strPtr(a.SoulMd.String, a.SoulMd.Valid),
-		AgentsMD:         strPtr(a.AgentsMd.String, a.AgentsMd.Valid),
-		IdentityMD:       strPtr(a.IdentityMd.String, a.IdentityMd.Valid),
-		UserMD:           strPtr(a.UserMd.String, a.UserMd.Valid),
-		ToolsMD:          strPtr(a.ToolsMd.String, a.ToolsMd.Valid),
-		HeartbeatMD:      strPtr(a.HeartbeatMd.String, a.HeartbeatMd.Valid),
-		MemoryMD:         strPtr(a.MemoryMd.String, a.MemoryMd.Valid),
-		ActiveSessionKey: strPtr(a.ActiveSessionKey.String, a.ActiveSessionKey.Valid),
-		CurrentTaskID:    strPtr(a.CurrentTaskID.String, a.CurrentTaskID.Valid),
-		CreatedAt:        a.CreatedAt.Time.Format("2006-01-02T15:04:05Z"),
-		UpdatedAt:        a.UpdatedAt.Time.Format("2006-01-02T15:04:05Z"),
+		ID:                   a.ID,
+		Name:                 a.Name,
+		Description:          strPtr(a.Description.String, a.Description.Valid),
+		Status:               status,
+		WorkspacePath:        strPtr(a.WorkspacePath.String, a.WorkspacePath.Valid),
+		AgentDirPath:         strPtr(a.AgentDirPath.String, a.AgentDirPath.Valid),
+		Model:                strPtr(a.Model.String, a.Model.Valid),
+		MentionPatterns:      strPtr(a.MentionPatterns.String, a.MentionPatterns.Valid),
+		SoulMD:               strPtr(a.SoulMd.String, a.SoulMd.Valid),
+		AgentsMD:             strPtr(a.AgentsMd.String, a.AgentsMd.Valid),
+		IdentityMD:           strPtr(a.IdentityMd.String, a.IdentityMd.Valid),
+		UserMD:               strPtr(a.UserMd.String, a.UserMd.Valid),
+		ToolsMD:              strPtr(a.ToolsMd.String, a.ToolsMd.Valid),
+		HeartbeatMD:          strPtr(a.HeartbeatMd.String, a.HeartbeatMd.Valid),
+		MemoryMD:             strPtr(a.MemoryMd.String, a.MemoryMd.Valid),
+		ActiveSessionKey:     strPtr(a.ActiveSessionKey.String, a.ActiveSessionKey.Valid),
+		CurrentTaskID:        strPtr(a.CurrentTaskID.String, a.CurrentTaskID.Valid),
+		Locale:               strPtr(a.Locale.String, a.Locale.Valid),
+		Timezone:             strPtr(a.Timezone.String, a.Timezone.Valid),
+		WorkingHours:         rawJSON(a.WorkingHours),
+		ManagedExternally:    a.ManagedExternally,
+		CallbackURL:          strPtr(a.CallbackURL.String, a.CallbackURL.Valid),
+		DeliveryMethod:       agentDeliveryMethod(a),
+		GatewayID:            strPtr(a.GatewayID.String, a.GatewayID.Valid),
+		NotificationPrefs:    agentNotificationPrefs(a),
+		NotificationTimeouts: rawJSON(a.NotificationTimeouts),
+		PublicAPIURL:         strPtr(a.PublicAPIURL.String, a.PublicAPIURL.Valid),
+		CreatedAt:            a.CreatedAt.Time.UTC().Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:            a.UpdatedAt.Time.UTC().Format("2006-01-02T15:04:05Z"),
 	}
 }
 
@@ -128,9 +211,11 @@ func ToTaskResponse(t db.Task) TaskResponse {
 
 	resp := TaskResponse{
 		ID:             t.ID,
+		ShortID:        strPtr(t.ShortID.String, t.ShortID.Valid),
 		Title:          t.Title,
 		Description:    strPtr(t.Description.String, t.Description.Valid),
 		AgentID:        strPtr(t.AgentID.String, t.AgentID.Valid),
+		GroupID:        strPtr(t.GroupID.String, t.GroupID.Valid),
 		ProjectID:      strPtr(t.ProjectID.String, t.ProjectID.Valid),
 		ParentTaskID:   strPtr(t.ParentTaskID.String, t.ParentTaskID.Valid),
 		Status:         status,
@@ -144,26 +229,56 @@ func ToTaskResponse(t db.Task) TaskResponse {
 		ProgressTxt:    strPtr(t.ProgressTxt.String, t.ProgressTxt.Valid),
 		QualityChecks:  strPtr(t.QualityChecks.String, t.QualityChecks.Valid),
 		DelegationMode: delegationMode,
-		CreatedAt:      t.CreatedAt.Time.Format("2006-01-02T15:04:05Z"),
-		UpdatedAt:      t.UpdatedAt.Time.Format("2006-01-02T15:04:05Z"),
+		CreatedAt:      t.CreatedAt.Time.UTC().Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:      t.UpdatedAt.Time.UTC().Format("2006-01-02T15:04:05Z"),
+		Secrets:        taskSecretNames(t),
+		ContextSummary: strPtr(t.ContextSummary.String, t.ContextSummary.Valid),
+		FailureReason:  strPtr(t.FailureReason.String, t.FailureReason.Valid),
+		Model:          strPtr(t.Model.String, t.Model.Valid),
+		RoutedModel:    strPtr(t.RoutedModel.String, t.RoutedModel.Valid),
+		RequiresReview: t.RequiresReview,
+		ReviewerUser:   strPtr(t.ReviewerUser.String, t.ReviewerUser.Valid),
 	}
 	
 	if t.StartedAt.Valid {
-		s := t.StartedAt.Time.Format("2006-01-02T15:04:05Z")
+		s := t.StartedAt.Time.UTC().Format("2006-01-02T15:04:05Z")
 		resp.StartedAt = &s
 	}
 	if t.CompletedAt.Valid {
-		s := t.CompletedAt.Time.Format("2006-01-02T15:04:05Z")
+		s := t.CompletedAt.Time.UTC().Format("2006-01-02T15:04:05Z")
 		resp.CompletedAt = &s
 	}
 	if t.ScheduledAt.Valid {
-		s := t.ScheduledAt.Time.Format("2006-01-02T15:04:05Z")
+		s := t.ScheduledAt.Time.UTC().Format("2006-01-02T15:04:05Z")
 		resp.ScheduledAt = &s
 	}
 	if t.RetryAt.Valid {
-		s := t.RetryAt.Time.Format("2006-01-02T15:04:05Z")
+		s := t.RetryAt.Time.UTC().Format("2006-01-02T15:04:05Z")
 		resp.RetryAt = &s
 	}
+	if t.DeferredUntil.Valid {
+		s := t.DeferredUntil.Time.UTC().Format("2006-01-02T15:04:05Z")
+		resp.DeferredUntil = &s
+	}
+	if t.ContextSummarizedAt.Valid {
+		s := t.ContextSummarizedAt.Time.UTC().Format("2006-01-02T15:04:05Z")
+		resp.ContextSummarizedAt = &s
+	}
+	if t.QueuePosition.Valid {
+		p := int(t.QueuePosition.Int64)
+		resp.QueuePosition = &p
+	}
+	if t.Progress.Valid {
+		p := int(t.Progress.Int64)
+		resp.Progress = &p
+		resp.ProgressSource = "derived"
+		if t.ProgressExplicit {
+			resp.ProgressSource = "reported"
+		}
+	}
+	resp.MaxSubtaskDepth = nullLimit(t.MaxSubtaskDepth)
+	resp.MaxConcurrentSubtasks = nullLimit(t.MaxConcurrentSubtasks)
+	resp.ReviewerAgentID = strPtr(t.ReviewerAgentID.String, t.ReviewerAgentID.Valid)
 	
 	return resp
 }
@@ -179,7 +294,101 @@ func ToTaskResponses(tasks []db.Task) []TaskResponse {
 // Helper function to convert sql.NullTime to string
 func nullTimeToString(nt sql.NullTime) string {
 	if nt.Valid {
-		return nt.Time.Format("2006-01-02T15:04:05Z")
+		return nt.Time.UTC().Format("2006-01-02T15:04:05Z")
 	}
 	return ""
 }
+
+// NotificationDeliveryResponse is one tracked notification about a task's
+// status transition.
+type NotificationDeliveryResponse struct {
+	ID          string  `json:"id"`
+	Kind        string  `json:"kind"`
+	TaskID      string  `json:"task_id"`
+	Transition  string  `json:"transition"`
+	AgentID     string  `json:"agent_id"`
+	Status      string  `json:"status"` // pending | delivered | failed | abandoned
+	Attempts    int64   `json:"attempts"`
+	LastError   *string `json:"last_error,omitempty"`
+	CreatedAt   string  `json:"created_at"`
+	UpdatedAt   string  `json:"updated_at"`
+	DeliveredAt *string `json:"delivered_at,omitempty"`
+}
+
+func ToNotificationDeliveryResponses(deliveries []db.NotificationDelivery) []NotificationDeliveryResponse {
+	result := make([]NotificationDeliveryResponse, len(deliveries))
+	for i, d := range deliveries {
+		result[i] = NotificationDeliveryResponse{
+			ID:          d.ID,
+			Kind:        d.Kind,
+			TaskID:      d.TaskID,
+			Transition:  d.Transition,
+			AgentID:     d.AgentID,
+			Status:      d.Status,
+			Attempts:    d.Attempts,
+			LastError:   strPtr(d.LastError.String, d.LastError.Valid),
+			CreatedAt:   nullTimeToString(d.CreatedAt),
+			UpdatedAt:   nullTimeToString(d.UpdatedAt),
+			DeliveredAt: strPtr(nullTimeToString(d.DeliveredAt), d.DeliveredAt.Valid),
+		}
+	}
+	return result
+}
+
+// EventResponse is an activity event, as listed by GET /events.
+type EventResponse struct {
+	ID        string  `json:"id"`
+	Type      string  `json:"type"`
+	Message   string  `json:"message"`
+	TaskID    *string `json:"task_id,omitempty"`
+	AgentID   *string `json:"agent_id,omitempty"`
+	Details   *string `json:"details,omitempty"`
+	CreatedAt string  `json:"created_at"`
+}
+
+func ToEventResponses(events []db.Event) []EventResponse {
+	result := make([]EventResponse, len(events))
+	for i, e := range events {
+		result[i] = EventResponse{
+			ID:        e.ID,
+			Type:      e.Type,
+			Message:   e.Message,
+			TaskID:    strPtr(e.TaskID.String, e.TaskID.Valid),
+			AgentID:   strPtr(e.AgentID.String, e.AgentID.Valid),
+			Details:   strPtr(e.Details.String, e.Details.Valid),
+			CreatedAt: nullTimeToString(e.CreatedAt),
+		}
+	}
+	return result
+}
+
+// TaskAttemptResponse is one entry in a task's retry history.
+type TaskAttemptResponse struct {
+	ID         string  `json:"id"`
+	Kind       string  `json:"kind"` // send | watchdog_retry | watchdog_reset | manual_retry | scheduled_retry
+	AgentID    *string `json:"agent_id,omitempty"`
+	Reason     *string `json:"reason,omitempty"`
+	Outcome    string  `json:"outcome"`
+	Error      *string `json:"error,omitempty"`
+	RetryCount int64   `json:"retry_count"`
+	CreatedAt  string  `json:"created_at"`
+	FinishedAt *string `json:"finished_at,omitempty"`
+}
+
+func ToTaskAttemptResponses(attempts []db.TaskAttempt) []TaskAttemptResponse {
+	result := make([]TaskAttemptResponse, len(attempts))
+	for i, a := range attempts {
+		result[i] = TaskAttemptResponse{
+			ID:         a.ID,
+			Kind:       a.Kind,
+			AgentID:    strPtr(a.AgentID.String, a.AgentID.Valid),
+			Reason:     strPtr(a.Reason.String, a.Reason.Valid),
+			Outcome:    a.Outcome,
+			Error:      strPtr(a.Error.String, a.Error.Valid),
+			RetryCount: a.RetryCount,
+			CreatedAt:  nullTimeToString(a.CreatedAt),
+			FinishedAt: strPtr(nullTimeToString(a.FinishedAt), a.FinishedAt.Valid),
+		}
+	}
+	return result
+}
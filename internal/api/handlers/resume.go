@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/notifyprefs"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
+)
+
+// ResumeReport is what Resume found left in flight by the previous process.
+type ResumeReport struct {
+	Interrupted int `json:"interrupted_sends"` // sends recorded as failed
+	Reattached  int `json:"reattached"`        // arrived before the restart
+	Resent      int `json:"resent"`
+	Held        int `json:"held"` // not resent: quiet hours, or no longer applies
+}
+
+// Outcomes of resend.
+type resendOutcome int
+
+const (
+	resendHeld resendOutcome = iota
+	resendSent
+	resendReattached
+)
+
+// sessionHistoryLookback is how many of a session's latest messages are
+// searched for a notification that may have arrived before a restart.
+const sessionHistoryLookback = 20
+
+// Resume picks up the notifications a previous process left in flight when
+// it stopped, so a restart loses no work. It is run when an instance becomes
+// leader, at startup before the queue processor and watchdog. Sends whose
+// callback never ran are recorded as failed in their task's retry history;
+// deliveries still pending are checked against the gateway session they went
+// to, marked delivered if they arrived and resent otherwise. A work_resumed event sums it up. In
+// maintenance mode nothing is resent; the watchdog resends once it is off.
+func (h *TaskHandler) Resume(ctx context.Context) ResumeReport {
+	var report ResumeReport
+	n, err := h.store.InterruptPendingTaskAttempts(ctx, "interrupted by restart")
+	if err != nil {
+		log.Printf("[TaskHandler] Resume: failed to close interrupted sends: %v", err)
+	}
+	report.Interrupted = int(n)
+
+	if !h.maintenance.Enabled() {
+		pending, err := h.store.ListUndeliveredNotifications(ctx, time.Now())
+		if err != nil {
+			log.Printf("[TaskHandler] Resume: failed to list undelivered notifications: %v", err)
+		}
+		for _, d := range pending {
+			if d.Status != "pending" {
+				continue // failed before the restart; retried by the watchdog
+			}
+			switch h.resend(ctx, d) {
+			case resendSent:
+				report.Resent++
+			case resendReattached:
+				report.Reattached++
+			default:
+				report.Held++
+			}
+		}
+	}
+
+	if report == (ResumeReport{}) {
+		return report
+	}
+	log.Printf("[TaskHandler] Resume: %d interrupted send(s), %d notification(s) reattached, %d resent, %d held",
+		report.Interrupted, report.Reattached, report.Resent, report.Held)
+	h.logEvent(ctx, "", "", "work_resumed",
+		fmt.Sprintf("Resumed after restart: %d notification(s) resent, %d had arrived, %d held; %d interrupted send(s)",
+			report.Resent, report.Reattached, report.Held, report.Interrupted),
+		fmt.Sprintf(`{"interrupted_sends":%d,"reattached":%d,"resent":%d,"held":%d}`,
+			report.Interrupted, report.Reattached, report.Resent, report.Held))
+	return report
+}
+
+// resend is ResendNotification, telling a notification that arrived apart
+// from one that was held.
+func (h *TaskHandler) resend(ctx context.Context, d db.NotificationDelivery) resendOutcome {
+	if h.agentSender == nil {
+		return resendHeld
+	}
+	if _, busy := h.inflight.Load(d.ID); busy {
+		return resendHeld
+	}
+	if h.arrived(ctx, d) {
+		if err := h.store.MarkNotificationDelivered(ctx, d.ID); err != nil {
+			log.Printf("[TaskHandler] Failed to mark notification %s delivered: %v", d.ID, err)
+			return resendHeld
+		}
+		log.Printf("[TaskHandler] Notification %s reached session %s before it was confirmed, not resending", d.ID, d.SessionKey.String)
+		h.logEvent(ctx, d.TaskID, d.AgentID, "notification_reattached",
+			fmt.Sprintf("Notification to %s (%s) had reached its session; not resending", d.AgentID, d.Kind),
+			fmt.Sprintf(`{"delivery_id":"%s","session_key":%q}`, d.ID, d.SessionKey.String))
+		return resendReattached
+	}
+
+	resendKind := h.resendSubtaskResult
+	if d.Kind == notificationKindTaskAssignment {
+		resendKind = h.resendAssignment
+	}
+	if !resendKind(ctx, d) {
+		return resendHeld
+	}
+	return resendSent
+}
+
+// arrived reports whether the gateway session a delivery went to holds its
+// message, sent since the delivery was created: the send got through but its
+// confirmation was lost, e.g. to a restart.
+func (h *TaskHandler) arrived(ctx context.Context, d db.NotificationDelivery) bool {
+	if h.gateway == nil || !d.SessionKey.Valid || d.SessionKey.String == "" {
+		return false
+	}
+	history, err := h.gateway.GetSessionHistory(ctx, d.SessionKey.String, sessionHistoryLookback)
+	if err != nil {
+		log.Printf("[TaskHandler] Could not read session %s to check notification %s: %v", d.SessionKey.String, d.ID, err)
+		return false
+	}
+	for _, m := range history.Messages {
+		if m.Role != "user" || !strings.Contains(m.Content, d.TaskID) {
+			continue
+		}
+		if !d.CreatedAt.Valid || !m.Time().Before(d.CreatedAt.Time.Truncate(time.Second)) {
+			return true
+		}
+	}
+	return false
+}
+
+// deliverySessionKey is the gateway session notifications to agentID land
+// in, or "" for agents reached by HTTP callback.
+func (h *TaskHandler) deliverySessionKey(ctx context.Context, agentID string) string {
+	if agent, err := h.store.GetAgent(ctx, agentID); err == nil && agent.DeliveryMethod.String == openclaw.DeliveryHTTPCallback {
+		return ""
+	}
+	return openclaw.MainSessionKey(agentID)
+}
+
+// assignmentResumable reports whether a task in status still waits on its
+// assignment reaching the agent.
+func assignmentResumable(status string) bool {
+	switch status {
+	case "backlog", "planning", "discussing", "executing", "verifying":
+		return true
+	}
+	return false
+}
+
+// resendAssignment is ResendNotification for a task assignment: the task is
+// sent again, with its history, if it is still assigned to the same agent and
+// not yet finished or in review.
+func (h *TaskHandler) resendAssignment(ctx context.Context, d db.NotificationDelivery) bool {
+	task, err := h.store.GetTask(ctx, d.TaskID)
+	if err != nil || task.AgentID.String != d.AgentID || !assignmentResumable(task.Status.String) {
+		_ = h.store.AbandonNotificationDelivery(ctx, d.ID)
+		return false
+	}
+	if !h.pushes(ctx, d.AgentID, notifyprefs.TaskAssignment) {
+		_ = h.store.AbandonNotificationDelivery(ctx, d.ID)
+		return false
+	}
+	now := time.Now()
+	if _, quiet := h.quietHours(ctx).Defer(now, int(task.Priority.Int64)); quiet {
+		return false // resent once the quiet hours end
+	}
+	if _, deferred := h.agentWorkingHours(ctx, d.AgentID).Defer(now); deferred {
+		return false // resent once the agent's working hours start
+	}
+	if err := h.store.RetryNotificationDelivery(ctx, d.ID, d.AgentID); err != nil {
+		log.Printf("[TaskHandler] Failed to mark notification delivery %s for retry: %v", d.ID, err)
+		return false
+	}
+	h.inflight.Store(d.ID, struct{}{})
+
+	log.Printf("[TaskHandler] Resending unconfirmed assignment %s of task %s to agent %s", d.ID, task.ID, d.AgentID)
+	h.logEvent(ctx, task.ID, d.AgentID, "notification_resent",
+		fmt.Sprintf("Resending task \"%s\" to agent %s (attempt %d)", task.Title, d.AgentID, d.Attempts+1),
+		fmt.Sprintf(`{"delivery_id":"%s","kind":"%s","attempt":%d}`, d.ID, d.Kind, d.Attempts+1))
+	h.pushAssignment(ctx, d.AgentID, task.ID, task.Title, task.Description.String, h.taskHistory(ctx, task.ID), d.ID)
+	return true
+}
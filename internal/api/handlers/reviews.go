@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/api/apierror"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/hooks"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/notifyprefs"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+)
+
+// ReviewRequest is the body of a review decision.
+type ReviewRequest struct {
+	Reviewer string `json:"reviewer"` // who decided; defaults to the task's reviewer
+	Comment  string `json:"comment"`  // required to reject
+}
+
+// Verdicts a reviewer agent can submit.
+const (
+	verdictApprove        = "approve"
+	verdictRequestChanges = "request_changes"
+)
+
+// VerdictRequest is a reviewer agent's structured verdict on a task.
+type VerdictRequest struct {
+	AgentID string   `json:"agent_id"` // the reviewer; defaults to the task's reviewer agent
+	Verdict string   `json:"verdict"`  // approve | request_changes
+	Summary string   `json:"summary"`
+	Issues  []string `json:"issues"` // what must change, for request_changes
+}
+
+// checkReviewer checks the reviewer of a task worked on by agentID: an
+// existing agent other than agentID, or a user, not both.
+func checkReviewer(ctx context.Context, st TaskHandlerStore, agentID, reviewerAgentID, reviewerUser string) error {
+	if reviewerAgentID != "" && reviewerUser != "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Specify reviewer_agent_id or reviewer_user, not both")
+	}
+	if reviewerAgentID == "" {
+		return nil
+	}
+	if _, err := st.GetAgent(ctx, reviewerAgentID); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Reviewer agent not found")
+	}
+	if reviewerAgentID == agentID {
+		return echo.NewHTTPError(http.StatusBadRequest, "An agent cannot review its own task")
+	}
+	return nil
+}
+
+// taskReviewer returns who reviews task: its reviewer agent or user, "" if
+// none is assigned.
+func taskReviewer(task db.Task) string {
+	if task.ReviewerAgentID.Valid && task.ReviewerAgentID.String != "" {
+		return task.ReviewerAgentID.String
+	}
+	return task.ReviewerUser.String
+}
+
+// reviewRequested records that task's work is waiting on its reviewer.
+func (h *TaskHandler) reviewRequested(ctx context.Context, task db.Task) {
+	message := "Task awaiting review"
+	if reviewer := taskReviewer(task); reviewer != "" {
+		message = fmt.Sprintf("Task awaiting review by %s", reviewer)
+	}
+	details, _ := json.Marshal(map[string]string{
+		"reviewer_agent_id": task.ReviewerAgentID.String,
+		"reviewer_user":     task.ReviewerUser.String,
+	})
+	h.logEvent(ctx, task.ID, task.AgentID.String, "review_requested", message, string(details))
+	if h.hub != nil {
+		h.hub.BroadcastApprovalPending("review", task.ID, task.ParentTaskID.String, taskReviewer(task))
+	}
+
+	if task.ReviewerAgentID.Valid && task.ReviewerAgentID.String != "" && h.agentSender != nil &&
+		h.pushes(ctx, task.ReviewerAgentID.String, notifyprefs.ReviewRequest) {
+		h.notifyReviewer(ctx, task)
+	}
+}
+
+// notifyReviewer asks task's reviewer agent to review the work with what
+// the executing agent produced; its reply is kept as a result of the task.
+func (h *TaskHandler) notifyReviewer(ctx context.Context, task db.Task) {
+	reviewerID := task.ReviewerAgentID.String
+	h.logEvent(ctx, task.ID, reviewerID, "reviewer_notified",
+		fmt.Sprintf("Asking agent %s to review the task", reviewerID), "")
+	sender, callback := h.scoped(ctx, task.ID, reviewerID, func(ctx context.Context, tID, aID, reply string, sendErr error) {
+		if sendErr != nil {
+			log.Printf("[TaskHandler] Failed to ask agent %s to review task %s: %v", aID, tID, sendErr)
+			return
+		}
+		if reply != "" {
+			h.saveResult(ctx, tID, aID, store.ResultReviewRequest, reply, nil)
+		}
+	})
+	sender.NotifyReviewRequestAsync(reviewerID, task.ID, task.Title, task.Description.String,
+		task.AgentID.String, task.GitBranch.String, callback)
+}
+
+// reviewDecision reads a review decision on the task in the request, which
+// must be in review. The reviewer defaults to the task's, then "human".
+func (h *TaskHandler) reviewDecision(c echo.Context) (db.Task, ReviewRequest, error) {
+	var req ReviewRequest
+	if err := bind(c, &req); err != nil {
+		return db.Task{}, req, err
+	}
+	task, err := h.store.GetTask(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return task, req, echo.NewHTTPError(http.StatusNotFound, "Task not found")
+	}
+	if task.Status.String != "review" {
+		return task, req, apierror.New(http.StatusConflict, apierror.InvalidTransition,
+			fmt.Sprintf("Task is %s, not in review", task.Status.String))
+	}
+	if req.Reviewer == "" {
+		req.Reviewer = taskReviewer(task)
+	}
+	if req.Reviewer == "" {
+		req.Reviewer = "human"
+	}
+	return task, req, nil
+}
+
+// ApproveReview - POST /api/v1/tasks/:id/review/approve
+// Approves the work of a task in review, completing it.
+func (h *TaskHandler) ApproveReview(c echo.Context) error {
+	task, req, err := h.reviewDecision(c)
+	if err != nil {
+		return err
+	}
+	if err := h.checkChangeRequestsResolved(c.Request().Context(), task.ID); err != nil {
+		return err
+	}
+	if task, err = h.vetoStatus(c.Request().Context(), hooks.PostComplete, task, ""); err != nil {
+		return err
+	}
+	if task, err = h.approveReview(c.Request().Context(), task, req.Reviewer, req.Comment); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, ToTaskResponse(task))
+}
+
+// RejectReview - POST /api/v1/tasks/:id/review/reject
+// Rejects the work of a task in review: the comment goes to the executing
+// agent as a change request and the task goes back to executing.
+func (h *TaskHandler) RejectReview(c echo.Context) error {
+	task, req, err := h.reviewDecision(c)
+	if err != nil {
+		return err
+	}
+	if req.Comment == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Comment is required")
+	}
+	if !task.AgentID.Valid || task.AgentID.String == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Task has no assigned agent")
+	}
+	if task, err = h.rejectReview(c.Request().Context(), task, req.Reviewer, req.Comment); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, ToTaskResponse(task))
+}
+
+// SubmitVerdict - POST /api/v1/tasks/:id/review/verdict
+// Takes the verdict of the task's reviewer agent: approve completes the
+// task, request_changes sends the summary and issues to the executing agent.
+func (h *TaskHandler) SubmitVerdict(c echo.Context) error {
+	var req VerdictRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+	if req.Verdict != verdictApprove && req.Verdict != verdictRequestChanges {
+		return echo.NewHTTPError(http.StatusBadRequest, "verdict must be approve or request_changes")
+	}
+	if req.Verdict == verdictRequestChanges && strings.TrimSpace(req.Summary) == "" && len(req.Issues) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "summary or issues is required to request changes")
+	}
+
+	ctx := c.Request().Context()
+	task, err := h.store.GetTask(ctx, c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+	}
+	if !task.ReviewerAgentID.Valid || task.ReviewerAgentID.String == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Task has no reviewer agent")
+	}
+	if req.AgentID != "" && req.AgentID != task.ReviewerAgentID.String {
+		return echo.NewHTTPError(http.StatusForbidden,
+			fmt.Sprintf("Only the task's reviewer (%s) can submit a verdict", task.ReviewerAgentID.String))
+	}
+	if task.Status.String != "review" {
+		return apierror.New(http.StatusConflict, apierror.InvalidTransition,
+			fmt.Sprintf("Task is %s, not in review", task.Status.String))
+	}
+	if req.Verdict == verdictRequestChanges && (!task.AgentID.Valid || task.AgentID.String == "") {
+		return echo.NewHTTPError(http.StatusBadRequest, "Task has no assigned agent")
+	}
+	if req.Verdict == verdictApprove {
+		if err := h.checkChangeRequestsResolved(ctx, task.ID); err != nil {
+			return err
+		}
+		if task, err = h.vetoStatus(ctx, hooks.PostComplete, task, ""); err != nil {
+			return err
+		}
+	}
+
+	reviewerID := task.ReviewerAgentID.String
+	details, _ := json.Marshal(req)
+	h.logEvent(ctx, task.ID, reviewerID, "review_verdict",
+		fmt.Sprintf("Agent %s submitted verdict %s", reviewerID, req.Verdict), string(details))
+
+	comment := verdictComment(req)
+	if req.Verdict == verdictApprove {
+		task, err = h.approveReview(ctx, task, reviewerID, comment)
+	} else {
+		task, err = h.rejectReview(ctx, task, reviewerID, comment)
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, ToTaskResponse(task))
+}
+
+// verdictComment renders a verdict's summary and issues as a comment.
+func verdictComment(req VerdictRequest) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(req.Summary))
+	if len(req.Issues) > 0 {
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString("Issues:")
+		for _, issue := range req.Issues {
+			b.WriteString("\n- " + issue)
+		}
+	}
+	return b.String()
+}
+
+// approveReview completes task on reviewer's approval, with comment if not
+// empty, and returns it updated.
+func (h *TaskHandler) approveReview(ctx context.Context, task db.Task, reviewer, comment string) (db.Task, error) {
+	if comment != "" {
+		h.addComment(ctx, db.CreateCommentParams{
+			TaskID:  task.ID,
+			Author:  reviewer,
+			Content: comment,
+		})
+	}
+	if err := h.store.UpdateTaskStatus(ctx, task.ID, "done"); err != nil {
+		return task, err
+	}
+	if err := h.store.ResetTaskRetryCount(ctx, task.ID); err != nil {
+		log.Printf("[TaskHandler] Failed to reset retry count for task %s: %v", task.ID, err)
+	}
+	h.logEvent(ctx, task.ID, task.AgentID.String, "review_approved",
+		fmt.Sprintf("Review approved by %s", reviewer), "")
+	if h.hub != nil {
+		h.hub.BroadcastTaskStatus(task.ID, "done", 0)
+	}
+
+	updated, err := h.store.GetTask(ctx, task.ID)
+	if err != nil {
+		return task, err
+	}
+	h.taskFinished(ctx, updated, "done")
+	return updated, nil
+}
+
+// rejectReview sends reviewer's comment on task to its agent as a change
+// request and returns the task updated.
+func (h *TaskHandler) rejectReview(ctx context.Context, task db.Task, reviewer, comment string) (db.Task, error) {
+	h.logEvent(ctx, task.ID, task.AgentID.String, "review_rejected",
+		fmt.Sprintf("Review rejected by %s", reviewer), "")
+	if err := h.requestChanges(ctx, task, reviewer, comment); err != nil {
+		return task, err
+	}
+	return h.store.GetTask(ctx, task.ID)
+}
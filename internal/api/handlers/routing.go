@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/routing"
+)
+
+// RoutingHandler manages the model routing policy: which model tasks run on
+// by priority and size.
+type RoutingHandler struct {
+	store RoutingHandlerStore
+}
+
+func NewRoutingHandler(s RoutingHandlerStore) *RoutingHandler {
+	return &RoutingHandler{store: s}
+}
+
+// Get - GET /api/v1/settings/model-routing
+func (h *RoutingHandler) Get(c echo.Context) error {
+	settings, err := h.store.GetSettings(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusOK, routing.Policy{Rules: []routing.Rule{}})
+	}
+	policy, err := routing.Parse(settings.ModelRouting.String)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, policy)
+}
+
+// Update - PUT /api/v1/settings/model-routing
+// Replaces the policy; an empty one ({"rules": []}) turns routing off.
+func (h *RoutingHandler) Update(c echo.Context) error {
+	var policy routing.Policy
+	if err := bind(c, &policy); err != nil {
+		return err
+	}
+	if policy.Rules == nil {
+		policy.Rules = []routing.Rule{}
+	}
+	if err := policy.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	stored := ""
+	if !policy.Empty() {
+		b, err := json.Marshal(policy)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		stored = string(b)
+	}
+	if err := h.store.SetModelRouting(c.Request().Context(), stored); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	log.Printf("[RoutingHandler] Model routing policy updated (%d rules)", len(policy.Rules))
+	return c.JSON(http.StatusOK, policy)
+}
+
+// modelRoute decides the model task runs on: its own if set, else the
+// routing policy's choice.
+func (h *TaskHandler) modelRoute(ctx context.Context, task db.Task) routing.Decision {
+	priority, size := int(task.Priority.Int64), routing.SizeOf(task.Description.String)
+	if task.Model.Valid {
+		return routing.Decision{Model: task.Model.String, Source: "task", Priority: priority, Size: size}
+	}
+	settings, err := h.store.GetSettings(ctx)
+	if err != nil {
+		return routing.Policy{}.Route(priority, size)
+	}
+	policy, err := routing.Parse(settings.ModelRouting.String)
+	if err != nil {
+		log.Printf("[TaskHandler] Ignoring model routing policy: %v", err)
+		return routing.Policy{}.Route(priority, size)
+	}
+	return policy.Route(priority, size)
+}
+
+// RouteModel returns the model taskID is to run on as it is dispatched, ""
+// leaving the agent on its own. A change of model is recorded on the task
+// (routed_model) with a model_routed event.
+func (h *TaskHandler) RouteModel(taskID string) string {
+	ctx := context.Background()
+	task, err := h.store.GetTask(ctx, taskID)
+	if err != nil {
+		return ""
+	}
+	d := h.modelRoute(ctx, task)
+	if d.Model == task.RoutedModel.String {
+		return d.Model
+	}
+	if err := h.store.SetTaskRoutedModel(ctx, task.ID, d.Model); err != nil {
+		log.Printf("[TaskHandler] Failed to record routed model of task %s: %v", task.ID, err)
+	}
+	if d.Model != "" {
+		details, _ := json.Marshal(d)
+		h.logEvent(ctx, task.ID, task.AgentID.String, "model_routed",
+			fmt.Sprintf("Task routed to model %s (%s)", d.Model, d.Source), string(details))
+	}
+	return d.Model
+}
+
+// GetModelRoute - GET /api/v1/tasks/:id/model-route
+// Returns the model the task would run on if dispatched now, and why,
+// without recording it.
+func (h *TaskHandler) GetModelRoute(c echo.Context) error {
+	ctx := c.Request().Context()
+	task, err := h.store.GetTask(ctx, c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+	}
+	return c.JSON(http.StatusOK, h.modelRoute(ctx, task))
+}
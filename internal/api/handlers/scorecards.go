@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/scorecard"
+)
+
+// ScorecardHandler reports how agents have been performing: a scorecard per
+// agent and a leaderboard ranking them.
+type ScorecardHandler struct {
+	store  ScorecardHandlerStore
+	window time.Duration // default window; 0 = all time
+}
+
+func NewScorecardHandler(s ScorecardHandlerStore, window time.Duration) *ScorecardHandler {
+	return &ScorecardHandler{store: s, window: window}
+}
+
+// buildScorecards collects every agent's work over the window ending now
+// (all time if window is 0): finished tasks, watchdog resets and change
+// requests.
+func buildScorecards(ctx context.Context, st ScorecardHandlerStore, window time.Duration) (*scorecard.Builder, error) {
+	since := scorecard.Since(window, time.Now())
+	b := scorecard.NewBuilder(window)
+
+	outcomes, err := st.ListAgentTaskOutcomes(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range outcomes {
+		outcome := scorecard.Outcome{Done: o.Status.String == "done"}
+		if o.CompletedAt.Valid {
+			start := o.CreatedAt
+			if o.StartedAt.Valid {
+				start = o.StartedAt
+			}
+			if start.Valid {
+				outcome.CycleTime = o.CompletedAt.Time.Sub(start.Time)
+			}
+		}
+		b.Add(o.AgentID.String, outcome)
+	}
+
+	resets, err := st.CountWatchdogResetsByAgent(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range resets {
+		b.AddResets(r.AgentID.String, int(r.Count))
+	}
+
+	changes, err := st.CountEventsByAgent(ctx, "changes_requested", since)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range changes {
+		b.AddChangeRequests(r.AgentID.String, int(r.Count))
+	}
+	return b, nil
+}
+
+// windowParam reads the window query parameter, defaulting to the
+// configured window.
+func (h *ScorecardHandler) windowParam(c echo.Context) (time.Duration, error) {
+	if c.QueryParam("window") == "" {
+		return h.window, nil
+	}
+	window, err := scorecard.ParseWindow(c.QueryParam("window"))
+	if err != nil {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return window, nil
+}
+
+// Get - GET /api/v1/agents/:id/stats?window=7d
+// Returns the agent's scorecard over the window.
+func (h *ScorecardHandler) Get(c echo.Context) error {
+	ctx := c.Request().Context()
+	agent, err := h.store.GetAgent(ctx, c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Agent not found")
+	}
+	window, err := h.windowParam(c)
+	if err != nil {
+		return err
+	}
+	b, err := buildScorecards(ctx, h.store, window)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, b.Card(agent.ID))
+}
+
+// Leaderboard - GET /api/v1/agents/leaderboard?window=7d
+// Ranks every agent by its scorecard over the window, best first.
+// ?format=csv returns a row per agent.
+func (h *ScorecardHandler) Leaderboard(c echo.Context) error {
+	asCSV, err := wantsCSV(c)
+	if err != nil {
+		return err
+	}
+	ctx := c.Request().Context()
+	window, err := h.windowParam(c)
+	if err != nil {
+		return err
+	}
+	agents, err := h.store.ListAgents(ctx)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	b, err := buildScorecards(ctx, h.store, window)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	ids := make([]string, len(agents))
+	for i, a := range agents {
+		ids[i] = a.ID
+	}
+	cards := b.Leaderboard(ids)
+	if asCSV {
+		return writeCSV(c, "leaderboard", func(w *csv.Writer) error {
+			if err := w.Write([]string{
+				"rank", "agent_id", "window", "score", "rated", "tasks_completed", "tasks_failed", "success_rate",
+				"avg_cycle_time_seconds", "watchdog_resets", "change_requests", "change_request_rate",
+			}); err != nil {
+				return err
+			}
+			for _, card := range cards {
+				if err := w.Write([]string{
+					strconv.Itoa(card.Rank), card.AgentID, card.Window, csvFloat(card.Score), strconv.FormatBool(card.Rated),
+					strconv.Itoa(card.TasksCompleted), strconv.Itoa(card.TasksFailed), csvFloat(card.SuccessRate),
+					csvFloat(card.AvgCycleTimeSeconds), strconv.Itoa(card.WatchdogResets),
+					strconv.Itoa(card.ChangeRequests), csvFloat(card.ChangeRequestRate),
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"window": scorecard.FormatWindow(window),
+		"agents": cards,
+	})
+}
+
+// SetScorecardWindow sets the window of the scorecards best_performer group
+// dispatch ranks members by (0 = all time).
+func (h *TaskHandler) SetScorecardWindow(window time.Duration) {
+	h.scorecardWindow = window
+}
+
+// memberRatings returns the scorecard scores of a group's members, for
+// best_performer dispatch. Members can't be told apart if the scorecards
+// fail to build, so all get the neutral score.
+func (h *TaskHandler) memberRatings(ctx context.Context, members []string) map[string]float64 {
+	b, err := buildScorecards(ctx, h.store, h.scorecardWindow)
+	if err != nil {
+		log.Printf("[QueueProcessor] Error building scorecards, rating members equally: %v", err)
+		b = scorecard.NewBuilder(h.scorecardWindow)
+	}
+	return b.Scores(members)
+}
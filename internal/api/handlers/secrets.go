@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/secrets"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+	ws "github.com/abelkuruvilla/claw-agent-mission-control/internal/websocket"
+)
+
+// SecretHandler manages project secrets. Values are write-only: the API
+// lists names, and values only ever leave the vault inside the assignment
+// notification of a task that asks for them (see InjectSecrets).
+type SecretHandler struct {
+	store SecretHandlerStore
+	hub   *ws.Hub
+	vault *secrets.Vault
+}
+
+// NewSecretHandler creates a SecretHandler sealing values with vault. A nil
+// vault (no SECRETS_KEY) disables secrets.
+func NewSecretHandler(s SecretHandlerStore, hub *ws.Hub, vault *secrets.Vault) *SecretHandler {
+	return &SecretHandler{
+		store: s,
+		hub:   hub,
+		vault: vault,
+	}
+}
+
+// Request types
+type SetSecretRequest struct {
+	Value string `json:"value" validate:"required"`
+}
+
+// SecretResponse describes a secret. The value is never returned.
+type SecretResponse struct {
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+func toSecretResponse(s db.ProjectSecret) SecretResponse {
+	return SecretResponse{
+		Name:      s.Name,
+		CreatedAt: nullTimeToString(s.CreatedAt),
+		UpdatedAt: nullTimeToString(s.UpdatedAt),
+	}
+}
+
+// taskSecretNames returns the names of the secrets injected into t's
+// assignment notification.
+func taskSecretNames(t db.Task) []string {
+	if !t.SecretNames.Valid || t.SecretNames.String == "" {
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal([]byte(t.SecretNames.String), &names); err != nil {
+		log.Printf("[SecretHandler] Invalid secret_names on task %s: %v", t.ID, err)
+		return nil
+	}
+	return names
+}
+
+// List - GET /api/v1/projects/:id/secrets
+func (h *SecretHandler) List(c echo.Context) error {
+	ctx := c.Request().Context()
+	project, err := h.store.GetProject(ctx, c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+	}
+	list, err := h.store.ListProjectSecrets(ctx, project.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	responses := make([]SecretResponse, len(list))
+	for i, s := range list {
+		responses[i] = toSecretResponse(s)
+	}
+	return c.JSON(http.StatusOK, responses)
+}
+
+// Set - PUT /api/v1/projects/:id/secrets/:name
+// Creates the secret (201) or replaces its value (200).
+func (h *SecretHandler) Set(c echo.Context) error {
+	if h.vault == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "Secrets are disabled (SECRETS_KEY is not set)")
+	}
+	var req SetSecretRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+	name := c.Param("name")
+	if !secrets.ValidName(name) {
+		return echo.NewHTTPError(http.StatusBadRequest, "Secret names must be valid environment variable names")
+	}
+
+	ctx := c.Request().Context()
+	project, err := h.store.GetProject(ctx, c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+	}
+	_, err = h.store.GetProjectSecret(ctx, project.ID, name)
+	created := err != nil
+
+	secret, err := h.store.SetProjectSecret(ctx, project.ID, name, h.vault.Seal(project.ID, name, req.Value))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	verb := "updated"
+	status := http.StatusOK
+	if created {
+		verb, status = "added", http.StatusCreated
+	}
+	h.logEvent(ctx, "", "", "secret_set", fmt.Sprintf("Secret %s %s in project %s", name, verb, project.Name),
+		fmt.Sprintf(`{"project_id":%q,"name":%q}`, project.ID, name))
+	return c.JSON(status, toSecretResponse(secret))
+}
+
+// Delete - DELETE /api/v1/projects/:id/secrets/:name
+// Tasks still naming the secret go without it.
+func (h *SecretHandler) Delete(c echo.Context) error {
+	ctx := c.Request().Context()
+	project, err := h.store.GetProject(ctx, c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+	}
+	name := c.Param("name")
+	deleted, err := h.store.DeleteProjectSecret(ctx, project.ID, name)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if !deleted {
+		return echo.NewHTTPError(http.StatusNotFound, "Secret not found")
+	}
+
+	h.logEvent(ctx, "", "", "secret_deleted", fmt.Sprintf("Secret %s removed from project %s", name, project.Name),
+		fmt.Sprintf(`{"project_id":%q,"name":%q}`, project.ID, name))
+	return c.NoContent(http.StatusNoContent)
+}
+
+// checkTaskSecrets validates the secret names requested for a task in
+// projectID: each must name a secret of the project.
+func checkTaskSecrets(ctx context.Context, st store.SecretStore, projectID string, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	if projectID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Only tasks in a project can use secrets")
+	}
+	for _, name := range names {
+		if _, err := st.GetProjectSecret(ctx, projectID, name); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unknown secret: %s", name))
+		}
+	}
+	return nil
+}
+
+// InjectSecrets is the openclaw.SecretsResolver: it opens the secrets taskID
+// asks for and records their release to agentID in the audit trail, by name.
+// Secrets that were deleted or no longer open are skipped.
+func (h *SecretHandler) InjectSecrets(agentID, taskID string) []openclaw.Secret {
+	if h.vault == nil {
+		return nil
+	}
+	ctx := context.Background()
+	task, err := h.store.GetTask(ctx, taskID)
+	if err != nil || !task.ProjectID.Valid {
+		return nil
+	}
+	names := taskSecretNames(task)
+	if len(names) == 0 {
+		return nil
+	}
+
+	var out []openclaw.Secret
+	var released []string
+	for _, name := range names {
+		secret, err := h.store.GetProjectSecret(ctx, task.ProjectID.String, name)
+		if err != nil {
+			log.Printf("[SecretHandler] Secret %s of task %s is gone: %v", name, taskID, err)
+			continue
+		}
+		value, err := h.vault.Open(task.ProjectID.String, name, secret.SealedValue)
+		if err != nil {
+			log.Printf("[SecretHandler] Cannot open secret %s of task %s: %v", name, taskID, err)
+			continue
+		}
+		out = append(out, openclaw.Secret{Name: name, Value: value})
+		released = append(released, name)
+	}
+	if len(released) > 0 {
+		details, _ := json.Marshal(map[string]interface{}{"names": released})
+		h.logEvent(ctx, taskID, agentID, "secrets_injected",
+			fmt.Sprintf("Secrets released to agent %s: %s", agentID, strings.Join(released, ", ")), string(details))
+	}
+	return out
+}
+
+// logEvent creates a persistent event record and broadcasts it via WebSocket.
+func (h *SecretHandler) logEvent(ctx context.Context, taskID, agentID, eventType, message, details string) {
+	event, err := h.store.CreateEvent(ctx, db.CreateEventParams{
+		TaskID:  sql.NullString{String: taskID, Valid: taskID != ""},
+		AgentID: sql.NullString{String: agentID, Valid: agentID != ""},
+		Type:    eventType,
+		Message: message,
+		Details: sql.NullString{String: details, Valid: details != ""},
+	})
+	if err != nil {
+		log.Printf("[SecretHandler] Failed to create event (%s): %v", eventType, err)
+		return
+	}
+	if h.hub != nil {
+		h.hub.BroadcastEvent(event)
+	}
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/standup"
+)
+
+// StandupHandler shows and posts the daily standup.
+type StandupHandler struct {
+	standup *standup.Generator
+}
+
+func NewStandupHandler(generator *standup.Generator) *StandupHandler {
+	return &StandupHandler{standup: generator}
+}
+
+// StandupResponse is a standup with how standups are set up.
+type StandupResponse struct {
+	standup.Report
+	Schedule  string `json:"schedule,omitempty"` // time of day, UTC; empty when not scheduled
+	AskAgents bool   `json:"ask_agents"`
+}
+
+// Preview - GET /api/v1/standup
+// Compiles the standup of the last 24 hours without asking the agents or
+// posting it.
+func (h *StandupHandler) Preview(c echo.Context) error {
+	report, err := h.standup.Compile(c.Request().Context(), time.Now())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, h.response(report))
+}
+
+// Post - POST /api/v1/standup
+// Posts the standup now, without waiting for the daily one. When agents are
+// asked for their status, this waits for their answers.
+func (h *StandupHandler) Post(c echo.Context) error {
+	// Posted in full even if the caller goes away
+	report, err := h.standup.Post(context.WithoutCancel(c.Request().Context()))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Standup failed: "+err.Error())
+	}
+	return c.JSON(http.StatusOK, h.response(report))
+}
+
+func (h *StandupHandler) response(report standup.Report) StandupResponse {
+	return StandupResponse{Report: report, Schedule: h.standup.Schedule(), AskAgents: h.standup.AsksAgents()}
+}
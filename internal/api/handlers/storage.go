@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/objectstore"
+)
+
+// StorageHandler manages the object storage backups and export bundles are
+// kept in, and hands out download links to its objects.
+type StorageHandler struct {
+	store      StorageHandlerStore
+	live       *objectstore.Live
+	creds      objectstore.Credentials
+	defaultDir string
+	signKey    []byte
+}
+
+func NewStorageHandler(s StorageHandlerStore, live *objectstore.Live, creds objectstore.Credentials, defaultDir string, signKey []byte) *StorageHandler {
+	return &StorageHandler{store: s, live: live, creds: creds, defaultDir: defaultDir, signKey: signKey}
+}
+
+// LoadStorage opens the object storage chosen in settings, falling back to
+// local storage in defaultDir if it cannot be opened.
+func LoadStorage(ctx context.Context, s StorageHandlerStore, creds objectstore.Credentials, defaultDir string, signKey []byte) *objectstore.Live {
+	local := objectstore.NewLocal(defaultDir, signKey)
+	settings, err := s.GetSettings(ctx)
+	if err != nil {
+		return objectstore.NewLive(local)
+	}
+	cfg, err := objectstore.Parse(settings.Storage.String)
+	if err != nil {
+		log.Printf("[StorageHandler] Ignoring storage settings, using %s: %v", defaultDir, err)
+		return objectstore.NewLive(local)
+	}
+	st, err := objectstore.Open(cfg, creds, defaultDir, signKey)
+	if err != nil {
+		log.Printf("[StorageHandler] Cannot open %s storage, using %s: %v", cfg.Backend, defaultDir, err)
+		return objectstore.NewLive(local)
+	}
+	return objectstore.NewLive(st)
+}
+
+// StorageResponse is the storage config and where objects are kept.
+type StorageResponse struct {
+	objectstore.Config
+	Location string `json:"location"`
+}
+
+// ObjectResponse is a stored object and a link to download it.
+type ObjectResponse struct {
+	Key        string  `json:"key"`
+	Size       int64   `json:"size,omitempty"`
+	ModifiedAt *string `json:"modified_at,omitempty"`
+	URL        string  `json:"url"`
+	ExpiresAt  string  `json:"expires_at"`
+}
+
+// toObjectResponse links to o, valid for ttl.
+func toObjectResponse(objects objectstore.Store, o objectstore.Object, ttl time.Duration) (ObjectResponse, error) {
+	u, err := objects.URL(o.Key, ttl)
+	if err != nil {
+		return ObjectResponse{}, err
+	}
+	resp := ObjectResponse{Key: o.Key, Size: o.Size, URL: u, ExpiresAt: time.Now().Add(ttl).UTC().Format(time.RFC3339)}
+	if !o.ModifiedAt.IsZero() {
+		modified := o.ModifiedAt.Format(time.RFC3339)
+		resp.ModifiedAt = &modified
+	}
+	return resp, nil
+}
+
+// urlTTL reads how long download links should work from ?expires_in=
+// (seconds).
+func urlTTL(c echo.Context) (time.Duration, error) {
+	raw := c.QueryParam("expires_in")
+	if raw == "" {
+		return objectstore.DefaultURLTTL, nil
+	}
+	secs, err := strconv.Atoi(raw)
+	ttl := time.Duration(secs) * time.Second
+	if err != nil || secs <= 0 || ttl > objectstore.MaxURLTTL {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, "expires_in must be between 1 and 604800 seconds")
+	}
+	return ttl, nil
+}
+
+// Get - GET /api/v1/settings/storage
+func (h *StorageHandler) Get(c echo.Context) error {
+	cfg := objectstore.Config{Backend: objectstore.BackendLocal}
+	if settings, err := h.store.GetSettings(c.Request().Context()); err == nil {
+		if parsed, err := objectstore.Parse(settings.Storage.String); err == nil {
+			cfg = parsed
+		}
+	}
+	return c.JSON(http.StatusOK, StorageResponse{Config: cfg, Location: h.live.String()})
+}
+
+// Update - PUT /api/v1/settings/storage
+// Switches object storage, after checking the new one can be reached.
+// Objects already stored are not moved.
+func (h *StorageHandler) Update(c echo.Context) error {
+	var cfg objectstore.Config
+	if err := bind(c, &cfg); err != nil {
+		return err
+	}
+	ctx := c.Request().Context()
+	st, err := objectstore.Open(cfg, h.creds, h.defaultDir, h.signKey)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if _, err := st.List(ctx, "backups/"); err != nil {
+		return echo.NewHTTPError(http.StatusBadGateway, "Storage cannot be reached: "+err.Error())
+	}
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if err := h.store.SetStorage(ctx, string(b)); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	h.live.Swap(st)
+	log.Printf("[StorageHandler] Object storage is now %s", st)
+	return c.JSON(http.StatusOK, StorageResponse{Config: cfg, Location: st.String()})
+}
+
+// List - GET /api/v1/storage/objects
+// Lists stored objects, optionally under ?prefix= (e.g. backups/), with
+// download links.
+func (h *StorageHandler) List(c echo.Context) error {
+	ttl, err := urlTTL(c)
+	if err != nil {
+		return err
+	}
+	objects, err := h.live.List(c.Request().Context(), c.QueryParam("prefix"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadGateway, err.Error())
+	}
+	result := make([]ObjectResponse, 0, len(objects))
+	for _, o := range objects {
+		resp, err := toObjectResponse(h.live, o, ttl)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		result = append(result, resp)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// Download - GET /api/v1/storage/objects/*
+// Serves an object of local storage to whoever holds its download link.
+// Links to S3 objects point at the bucket instead.
+func (h *StorageHandler) Download(c echo.Context) error {
+	local, ok := h.live.Current().(*objectstore.Local)
+	if !ok {
+		return echo.NewHTTPError(http.StatusNotFound, "Object not found")
+	}
+	key, err := url.PathUnescape(c.Param("*"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := local.Verify(key, c.QueryParam("expires"), c.QueryParam("signature")); err != nil {
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	}
+	r, err := local.Get(c.Request().Context(), key)
+	if errors.Is(err, objectstore.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "Object not found")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	defer r.Close()
+	c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename="+strconv.Quote(path.Base(key)))
+	return c.Stream(http.StatusOK, echo.MIMEOctetStream, r)
+}
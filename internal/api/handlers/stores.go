@@ -0,0 +1,168 @@
+package handlers
+
+import "github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+
+// Store dependencies of each handler. *store.Store satisfies all of them;
+// storemock.Store does too, for unit tests that should not touch SQLite.
+
+type AgentHandlerStore interface {
+	store.AgentStore
+	store.EventStore
+	store.GatewayStore
+}
+
+type TaskHandlerStore interface {
+	store.AgentStore
+	store.AgentGroupStore
+	store.TaskStore
+	store.PhaseStore
+	store.StoryStore
+	store.CommentStore
+	store.EventStore
+	store.NotificationStore
+	store.TaskAttemptStore
+	store.TaskResultStore
+	store.TaskLinkStore
+	store.ProjectStore
+	store.SecretStore
+	store.ProgressEntryStore
+	store.SettingsStore
+	store.ExperimentStore
+	store.ChangeRequestStore
+	store.TaskTriageStore
+}
+
+type ProjectHandlerStore interface {
+	store.ProjectStore
+}
+
+type CommentHandlerStore interface {
+	store.CommentStore
+	store.TaskStore
+	store.TaskLinkStore
+}
+
+type TrashHandlerStore interface {
+	store.TrashStore
+	store.TaskStore
+	store.CommentStore
+	store.TaskLinkStore
+}
+
+type ReportingHandlerStore interface {
+	store.TaskStore
+	store.PhaseStore
+	store.StoryStore
+	store.EventStore
+	store.ProjectStore
+	store.ProgressEntryStore
+}
+
+type SummaryHandlerStore interface {
+	store.TaskStore
+	store.ProgressEntryStore
+	store.CommentStore
+	store.EventStore
+}
+
+type GatewayHandlerStore interface {
+	store.GatewayStore
+	store.EventStore
+}
+
+type ExperimentHandlerStore interface {
+	store.ExperimentStore
+	store.AgentStore
+	store.ProjectStore
+	store.EventStore
+}
+
+type SecretHandlerStore interface {
+	store.ProjectStore
+	store.SecretStore
+	store.TaskStore
+	store.EventStore
+}
+
+type GroupHandlerStore interface {
+	store.AgentGroupStore
+	store.AgentStore
+	store.TaskStore
+	store.EventStore
+}
+
+type RoutingHandlerStore interface {
+	store.SettingsStore
+}
+
+type QuietHoursHandlerStore interface {
+	store.SettingsStore
+}
+
+type NotifyTimeoutHandlerStore interface {
+	store.SettingsStore
+}
+
+type TaskScriptHandlerStore interface {
+	store.SettingsStore
+}
+
+type MaintenanceHandlerStore interface {
+	store.SettingsStore
+	store.EventStore
+}
+
+type AnalyticsExportHandlerStore interface {
+	store.SettingsStore
+}
+
+type StorageHandlerStore interface {
+	store.SettingsStore
+}
+
+type BackupHandlerStore interface {
+	store.BackupStore
+	store.EventStore
+}
+
+type ScorecardHandlerStore interface {
+	store.AgentStore
+	store.TaskStore
+	store.TaskAttemptStore
+	store.EventStore
+}
+
+type CalendarHandlerStore interface {
+	store.TaskStore
+}
+
+type JiraHandlerStore interface {
+	store.JiraLinkStore
+	store.ProjectStore
+}
+
+type GitHubHandlerStore interface {
+	store.GitHubIssueLinkStore
+	store.ProjectStore
+}
+
+type EmailHandlerStore interface {
+	store.InboundEmailStore
+}
+
+type AvailabilityHandlerStore interface {
+	store.AgentStore
+	store.TaskStore
+}
+
+type ChatHandlerStore interface {
+	store.AgentStore
+	store.ChatStore
+}
+
+type GraphQLHandlerStore interface {
+	store.TaskStore
+	store.AgentStore
+	store.ProjectStore
+	store.EventStore
+}
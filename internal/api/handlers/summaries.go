@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
+	ws "github.com/abelkuruvilla/claw-agent-mission-control/internal/websocket"
+)
+
+// Bounds of a context summarization: how long its session may take, and how
+// much of the task's history goes into it.
+const (
+	summaryTimeout     = 5 * time.Minute
+	summaryMaxProgress = 200
+	summaryMaxComments = 100
+)
+
+// SummaryHandler maintains the rolling context summaries of tasks, written by
+// a summarization session on the OpenClaw Gateway and included in the
+// notifications of agents picking a task up again.
+type SummaryHandler struct {
+	store      SummaryHandlerStore
+	hub        *ws.Hub
+	summarizer *openclaw.Summarizer
+	running    sync.Map // task ID -> struct{}, for summaries under way
+}
+
+func NewSummaryHandler(s SummaryHandlerStore, hub *ws.Hub, summarizer *openclaw.Summarizer) *SummaryHandler {
+	return &SummaryHandler{
+		store:      s,
+		hub:        hub,
+		summarizer: summarizer,
+	}
+}
+
+// Summarize - POST /api/v1/tasks/:id/summarize
+// Starts summarizing what happened on the task since its last summary
+// (202), or answers 200 when nothing did. The summary is stored when the
+// session replies, and a context_summarized event is logged.
+func (h *SummaryHandler) Summarize(c echo.Context) error {
+	ctx := c.Request().Context()
+	task, err := h.store.GetTask(ctx, c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+	}
+
+	req, err := h.summaryRequest(ctx, task)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if len(req.Progress) == 0 && len(req.Comments) == 0 {
+		if req.PreviousSummary == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "Task has no progress or comments to summarize")
+		}
+		return c.JSON(http.StatusOK, map[string]string{"status": "up_to_date"})
+	}
+	if _, busy := h.running.LoadOrStore(task.ID, struct{}{}); busy {
+		return echo.NewHTTPError(http.StatusConflict, "A summary is already being generated for this task")
+	}
+
+	go h.summarize(task, req)
+	return c.JSON(http.StatusAccepted, map[string]string{"status": "summarizing"})
+}
+
+// summaryRequest gathers the history of task to summarize: its previous
+// summary and the progress entries and comments added since.
+func (h *SummaryHandler) summaryRequest(ctx context.Context, task db.Task) (openclaw.SummaryRequest, error) {
+	req := openclaw.SummaryRequest{
+		TaskID:          task.ID,
+		Title:           task.Title,
+		Description:     task.Description.String,
+		PreviousSummary: task.ContextSummary.String,
+	}
+	// Entries and comments from the second of the last summary are included
+	// again rather than risk missing them
+	since := func(t sql.NullTime) bool {
+		return !task.ContextSummarizedAt.Valid || !t.Time.Before(task.ContextSummarizedAt.Time)
+	}
+
+	entries, err := h.store.ListProgressEntries(ctx, task.ID, 0, summaryMaxProgress)
+	if err != nil {
+		return req, err
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if e := entries[i]; since(e.CreatedAt) {
+			req.Progress = append(req.Progress, authored(e.Author.String, e.Content))
+		}
+	}
+
+	comments, err := h.store.ListCommentsByTask(ctx, task.ID)
+	if err != nil {
+		return req, err
+	}
+	if len(comments) > summaryMaxComments {
+		comments = comments[len(comments)-summaryMaxComments:]
+	}
+	for _, cm := range comments {
+		if since(cm.CreatedAt) {
+			req.Comments = append(req.Comments, authored(cm.Author, cm.Content))
+		}
+	}
+	return req, nil
+}
+
+// authored prefixes content with its author, if known.
+func authored(author, content string) string {
+	if author == "" {
+		return content
+	}
+	return author + ": " + content
+}
+
+// summarize runs a summarization session for task and stores its summary.
+func (h *SummaryHandler) summarize(task db.Task, req openclaw.SummaryRequest) {
+	defer h.running.Delete(task.ID)
+	ctx, cancel := context.WithTimeout(context.Background(), summaryTimeout)
+	defer cancel()
+
+	summary, err := h.summarizer.Summarize(ctx, req)
+	if err != nil {
+		log.Printf("[SummaryHandler] Failed to summarize task %s: %v", task.ID, err)
+		h.logEvent(task.ID, "context_summary_failed",
+			fmt.Sprintf("Context summary of task \"%s\" failed: %v", task.Title, err))
+		return
+	}
+	if err := h.store.SetTaskContextSummary(context.Background(), task.ID, summary); err != nil {
+		log.Printf("[SummaryHandler] Failed to store summary of task %s: %v", task.ID, err)
+		return
+	}
+	h.logEvent(task.ID, "context_summarized", fmt.Sprintf("Context summary of task \"%s\" updated", task.Title))
+}
+
+// logEvent creates a persistent event record and broadcasts it via WebSocket.
+func (h *SummaryHandler) logEvent(taskID, eventType, message string) {
+	event, err := h.store.CreateEvent(context.Background(), db.CreateEventParams{
+		TaskID:  sql.NullString{String: taskID, Valid: taskID != ""},
+		Type:    eventType,
+		Message: message,
+	})
+	if err != nil {
+		log.Printf("[SummaryHandler] Failed to create event (%s): %v", eventType, err)
+		return
+	}
+	if h.hub != nil {
+		h.hub.BroadcastEvent(event)
+	}
+}
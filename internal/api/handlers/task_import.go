@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/api/apierror"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/planimport"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+)
+
+// ImportTaskRequest is a task planned outside Mission Control: a markdown
+// roadmap or PRD, or a prd.json, in content. The fields of a task create
+// are taken as given; an empty title, description or git_branch is taken
+// from the plan.
+type ImportTaskRequest struct {
+	CreateTaskRequest
+	Format  string `json:"format" validate:"omitempty,oneof=markdown prd_json"` // detected if omitted
+	Content string `json:"content" validate:"required"`
+}
+
+// Import creates a task with the phases and stories of a plan, before it is
+// dispatched, and keeps the plan as its roadmap_md or prd_json.
+// POST /api/v1/tasks/import
+func (h *TaskHandler) Import(c echo.Context) error {
+	var req ImportTaskRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+	format := req.Format
+	if format == "" {
+		format = planimport.Detect(req.Content)
+	}
+	plan, err := planimport.Parse(format, req.Content)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Could not import plan: "+err.Error())
+	}
+
+	if req.Title == "" {
+		req.Title = plan.Title
+	}
+	if req.Description == "" {
+		req.Description = plan.Description
+	}
+	if req.GitBranch == "" {
+		req.GitBranch = plan.GitBranch
+	}
+	if err := c.Validate(&req.CreateTaskRequest); err != nil {
+		return err
+	}
+
+	taskPlan := store.TaskPlan{}
+	if format == planimport.FormatMarkdown {
+		taskPlan.RoadmapMD = req.Content
+	} else {
+		taskPlan.PrdJSON = req.Content
+	}
+	for _, p := range plan.Phases {
+		status := "pending"
+		if p.Done {
+			status = "done"
+		}
+		taskPlan.Phases = append(taskPlan.Phases, db.AppendPhaseParams{
+			Title:       p.Title,
+			Description: sql.NullString{String: p.Description, Valid: p.Description != ""},
+			Status:      sql.NullString{String: status, Valid: true},
+		})
+	}
+	for _, st := range plan.Stories {
+		acJSON := "[]"
+		if len(st.AcceptanceCriteria) > 0 {
+			acBytes, err := json.Marshal(st.AcceptanceCriteria)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "Invalid acceptance criteria format")
+			}
+			acJSON = string(acBytes)
+		}
+		taskPlan.Stories = append(taskPlan.Stories, store.PlannedStory{
+			AppendStoryParams: db.AppendStoryParams{
+				Title:              st.Title,
+				Description:        sql.NullString{String: st.Description, Valid: st.Description != ""},
+				Priority:           sql.NullInt64{Int64: int64(st.Priority), Valid: true},
+				AcceptanceCriteria: sql.NullString{String: acJSON, Valid: true},
+			},
+			Passes: st.Passes,
+		})
+	}
+
+	ctx := c.Request().Context()
+	task, err := h.createTask(ctx, req.CreateTaskRequest, newTask{setup: func(task db.Task) (db.Task, error) {
+		task, err := h.store.AddTaskPlan(ctx, task.ID, taskPlan)
+		if err != nil {
+			return db.Task{}, err
+		}
+		refreshTaskProgress(ctx, h.store, h.hub, task.ID, 0)
+		return h.store.GetTask(ctx, task.ID)
+	}})
+	var refused *subtaskRefusedError
+	if errors.As(err, &refused) {
+		return apierror.WithDetails(http.StatusUnprocessableEntity, apierror.DelegationLimit, refused.violation.Error, refused.violation)
+	}
+	if err != nil {
+		return err
+	}
+
+	phases, _ := h.store.ListPhasesByTask(ctx, task.ID)
+	stories, _ := h.store.ListStoriesByTask(ctx, task.ID)
+	resp := h.taskResponse(ctx, task)
+	resp.DispatchedImmediately = req.ScheduledAt != "" && !task.ScheduledAt.Valid
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"task":    resp,
+		"phases":  phases,
+		"stories": stories,
+	})
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+)
+
+// maxResultPage caps limit on the result list.
+const maxResultPage = 200
+
+// TaskResultResponse is an agent's reply to a notification about a task.
+type TaskResultResponse struct {
+	ID        string          `json:"id"`
+	TaskID    string          `json:"task_id"`
+	AgentID   *string         `json:"agent_id,omitempty"`
+	Kind      string          `json:"kind"` // the notification answered, e.g. assignment
+	Summary   string          `json:"summary"`
+	Body      string          `json:"body"`
+	Metadata  json.RawMessage `json:"metadata,omitempty"`
+	CreatedAt string          `json:"created_at"`
+}
+
+func toTaskResultResponse(r db.TaskResult) TaskResultResponse {
+	return TaskResultResponse{
+		ID:        r.ID,
+		TaskID:    r.TaskID,
+		AgentID:   strPtr(r.AgentID.String, r.AgentID.Valid),
+		Kind:      r.Kind,
+		Summary:   r.Summary,
+		Body:      r.Body,
+		Metadata:  rawJSON(r.Metadata),
+		CreatedAt: nullTimeToString(r.CreatedAt),
+	}
+}
+
+// TaskResultSummary is the latest result of a task, without its body.
+type TaskResultSummary struct {
+	ID        string  `json:"id"`
+	AgentID   *string `json:"agent_id,omitempty"`
+	Kind      string  `json:"kind"`
+	Summary   string  `json:"summary"`
+	CreatedAt string  `json:"created_at"`
+}
+
+// saveResult keeps an agent's reply to a notification of kind about taskID
+// as a result of the task.
+func (h *TaskHandler) saveResult(ctx context.Context, taskID, agentID, kind, reply string, metadata map[string]string) {
+	log.Printf("[TaskHandler] Saving agent %s reply as %s result of task %s (len=%d)", agentID, kind, taskID, len(reply))
+	if _, err := h.store.AddTaskResult(ctx, taskID, agentID, kind, reply, metadata); err != nil {
+		log.Printf("[TaskHandler] ERROR saving agent reply as result: %v", err)
+	}
+}
+
+// addLatestResults sets the latest result of each task in resp, in one query.
+func (h *TaskHandler) addLatestResults(ctx context.Context, resp []TaskResponse) {
+	ids := make([]string, len(resp))
+	for i := range resp {
+		ids[i] = resp[i].ID
+	}
+	if len(ids) == 0 {
+		return
+	}
+	latest, err := h.store.ListLatestTaskResults(ctx, ids)
+	if err != nil {
+		log.Printf("[TaskHandler] Error loading latest task results: %v", err)
+		return
+	}
+	byTask := make(map[string]*TaskResultSummary, len(latest))
+	for _, r := range latest {
+		byTask[r.TaskID] = &TaskResultSummary{
+			ID:        r.ID,
+			AgentID:   strPtr(r.AgentID.String, r.AgentID.Valid),
+			Kind:      r.Kind,
+			Summary:   r.Summary,
+			CreatedAt: nullTimeToString(r.CreatedAt),
+		}
+	}
+	for i := range resp {
+		resp[i].LatestResult = byTask[resp[i].ID]
+	}
+}
+
+// ListResults - GET /api/v1/tasks/:id/results?limit=50
+// Returns the task's results, newest first.
+func (h *TaskHandler) ListResults(c echo.Context) error {
+	ctx := c.Request().Context()
+	task, err := h.store.GetTask(ctx, c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+	}
+	limit := int64(50)
+	if raw := c.QueryParam("limit"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n < 1 || n > maxResultPage {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxResultPage))
+		}
+		limit = n
+	}
+
+	results, err := h.store.ListTaskResults(ctx, task.ID, limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	responses := make([]TaskResultResponse, len(results))
+	for i, r := range results {
+		responses[i] = toTaskResultResponse(r)
+	}
+	return c.JSON(http.StatusOK, responses)
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/api/apierror"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/taskscript"
+)
+
+// TaskScriptHandler manages the task scripts: small scripts run on every new
+// task that route it (agent, group, priority, ...) or reject it.
+type TaskScriptHandler struct {
+	store TaskScriptHandlerStore
+	// Saved scripts are compiled into it, for TaskHandler to run
+	compiled *taskscript.Cache
+}
+
+func NewTaskScriptHandler(s TaskScriptHandlerStore, compiled *taskscript.Cache) *TaskScriptHandler {
+	return &TaskScriptHandler{store: s, compiled: compiled}
+}
+
+// SetTaskScripts sets the cache the task scripts are compiled into as they
+// are saved.
+func (h *TaskHandler) SetTaskScripts(c *taskscript.Cache) {
+	h.taskScripts = c
+}
+
+// Get - GET /api/v1/settings/task-scripts
+func (h *TaskScriptHandler) Get(c echo.Context) error {
+	settings, err := h.store.GetSettings(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusOK, taskscript.Set{Scripts: []taskscript.Script{}})
+	}
+	set, err := taskscript.Parse(settings.TaskScripts.String)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, set)
+}
+
+// Update - PUT /api/v1/settings/task-scripts
+// Replaces the scripts; {"scripts": []} removes them. A script that doesn't
+// compile is a 400 naming the line.
+func (h *TaskScriptHandler) Update(c echo.Context) error {
+	var set taskscript.Set
+	if err := bind(c, &set); err != nil {
+		return err
+	}
+	if set.Scripts == nil {
+		set.Scripts = []taskscript.Script{}
+	}
+	compiled, err := set.Compile()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	stored := ""
+	if !set.Empty() {
+		b, err := json.Marshal(set)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		stored = string(b)
+	}
+	if err := h.store.SetTaskScripts(c.Request().Context(), stored); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	h.compiled.Store(stored, compiled)
+	log.Printf("[TaskScriptHandler] Task scripts updated (%d scripts)", len(set.Scripts))
+	return c.JSON(http.StatusOK, set)
+}
+
+// TestTaskScriptsRequest is a task to run scripts on, and the scripts; nil
+// scripts are the saved ones.
+type TestTaskScriptsRequest struct {
+	Scripts []taskscript.Script `json:"scripts"`
+	Task    taskscript.Task     `json:"task"`
+}
+
+// Test - POST /api/v1/settings/task-scripts/test
+// Runs scripts on a task without creating it and returns what they made of
+// it.
+func (h *TaskScriptHandler) Test(c echo.Context) error {
+	var req TestTaskScriptsRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+	ctx := c.Request().Context()
+	if req.Scripts != nil {
+		return c.JSON(http.StatusOK, taskscript.Set{Scripts: req.Scripts}.Run(ctx, req.Task))
+	}
+	source := ""
+	if settings, err := h.store.GetSettings(ctx); err == nil {
+		source = settings.TaskScripts.String
+	}
+	compiled, err := h.compiled.Load(source)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, compiled.Run(ctx, req.Task))
+}
+
+// runTaskScripts runs the task scripts on a task about to be created and
+// returns the request as they changed it, or a 422 script_rejected error if
+// one rejected it.
+func (h *TaskHandler) runTaskScripts(ctx context.Context, req CreateTaskRequest) (CreateTaskRequest, taskscript.Outcome, error) {
+	settings, err := h.store.GetSettings(ctx)
+	if err != nil || !settings.TaskScripts.Valid {
+		return req, taskscript.Outcome{}, nil
+	}
+	compiled, err := h.taskScripts.Load(settings.TaskScripts.String)
+	if err != nil {
+		log.Printf("[TaskHandler] Ignoring task scripts: %v", err)
+		return req, taskscript.Outcome{}, nil
+	}
+	if compiled.Empty() {
+		return req, taskscript.Outcome{}, nil
+	}
+
+	agentID := req.AgentID
+	if agentID == "unassigned" {
+		agentID = ""
+	}
+	out := compiled.Run(ctx, taskscript.Task{
+		Title:          req.Title,
+		Description:    req.Description,
+		Priority:       req.Priority,
+		AgentID:        agentID,
+		GroupID:        req.GroupID,
+		ProjectID:      req.ProjectID,
+		ParentTaskID:   req.ParentTaskID,
+		GitBranch:      req.GitBranch,
+		Model:          req.Model,
+		RequiresReview: req.RequiresReview,
+		Reviewer:       req.ReviewerAgentID,
+	})
+	for _, f := range out.Failures {
+		log.Printf("[TaskHandler] Task script %s failed on %q: %s", f.Script, req.Title, f.Error)
+	}
+	if out.Rejected != nil {
+		return req, out, apierror.WithDetails(http.StatusUnprocessableEntity, apierror.ScriptRejected,
+			out.Rejected.Message, out.Rejected)
+	}
+
+	t := out.Task
+	if t.AgentID != agentID {
+		req.AgentID = t.AgentID
+	}
+	req.Title, req.Description, req.Priority = t.Title, t.Description, t.Priority
+	req.GroupID, req.ProjectID = t.GroupID, t.ProjectID
+	req.GitBranch, req.Model = t.GitBranch, t.Model
+	req.RequiresReview, req.ReviewerAgentID = t.RequiresReview, t.Reviewer
+	return req, out, nil
+}
@@ -3,27 +3,81 @@ package handlers
 import (
 	"context"
 	"database/sql"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/labstack/echo/v4"
 
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/api/apierror"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/availability"
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/dispatch"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/github"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/hooks"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/intake"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/localtime"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/maintenance"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/modelhealth"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/notifyprefs"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/notifytimeout"
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/ratelimit"
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/taskctx"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/taskrefs"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/taskscript"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/validation"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/warehouse"
 	ws "github.com/abelkuruvilla/claw-agent-mission-control/internal/websocket"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/workhours"
 )
 
 type TaskHandler struct {
-	store        *store.Store
+	store        TaskHandlerStore
 	hub          *ws.Hub
 	orchestrator Orchestrator
-	agentSender  *openclaw.AgentSender
+	agentSender  openclaw.Sender
+	availability *availability.Tracker
+	rateLimits   *ratelimit.Limiter
+	// Agents whose models are all down get no work; nil if not probing
+	modelHealth *modelhealth.Prober
+	// inflight holds notification delivery IDs whose send has not returned
+	// yet, so the watchdog does not resend them.
+	inflight sync.Map
+	// Contexts background sends run under, cancelled when their task is
+	// stopped or deleted
+	scopes *taskctx.Registry
+	// How long each attempt of a send may take; nil for the fallback
+	sendTimeoutFor func(agentID, dispatch string) time.Duration
+	// Delegation limits of tasks whose project sets none (0 = unlimited)
+	maxSubtaskDepth int
+	maxSubtasks     int
+	// Window of the scorecards best_performer dispatch ranks members by
+	scorecardWindow time.Duration
+	// Closes the GitHub issues of tasks that are done; nil if not syncing
+	githubSync *github.Syncer
+	// Tells people following tasks outside the UI of outcomes; nil if none
+	notifier TaskNotifier
+	// While on, new tasks are refused and queues are not processed
+	maintenance *maintenance.Mode
+	// Checked for notifications that arrived before a restart; nil if none
+	gateway openclaw.Gateway
+	// Run before dispatch and as tasks complete or fail; nil if none
+	hooks *hooks.Runner
+	// The task scripts, compiled as saved; nil compiles them per task
+	taskScripts *taskscript.Cache
+	// Agent new unassigned tasks are sent to for triage; "" if none
+	triageAgent   string
+	triageTimeout time.Duration
+	triageAuto    bool // apply its suggestions as they arrive, else stage them
 }
 
 type Orchestrator interface {
@@ -34,19 +88,135 @@ type Orchestrator interface {
 	IsRunning(taskID string) bool
 }
 
-func NewTaskHandler(s *store.Store, hub *ws.Hub, agentSender *openclaw.AgentSender) *TaskHandler {
+// TaskNotifier is told of task outcomes people follow outside the UI, such
+// as in a chat. Implementations must not block.
+type TaskNotifier interface {
+	TaskFinished(ctx context.Context, task db.Task, status string)
+	ApprovalRequested(ctx context.Context, subtask, parent db.Task)
+}
+
+func NewTaskHandler(s TaskHandlerStore, hub *ws.Hub, agentSender openclaw.Sender) *TaskHandler {
 	return &TaskHandler{
 		store:        s,
 		hub:          hub,
 		orchestrator: nil,
 		agentSender:  agentSender,
+		scopes:       taskctx.New(),
+	}
+}
+
+// SetSendTimeoutResolver sets how long each attempt to notify an agent may
+// take by dispatch type, as the AgentSender was told (see
+// openclaw.AgentSender.SetTimeoutResolver), so that background sends are
+// given long enough. Without one, attempts take notifytimeout.Fallback.
+func (h *TaskHandler) SetSendTimeoutResolver(fn func(agentID, dispatch string) time.Duration) {
+	h.sendTimeoutFor = fn
+}
+
+// sendScopeTimeout bounds a background send to agentID. It outlasts the
+// AgentSender's retries of both deliveries of an assignment routed to a
+// model, the model switch and the message, at the longest attempt timeout
+// of any dispatch type for the agent.
+func (h *TaskHandler) sendScopeTimeout(agentID string) time.Duration {
+	attempt := notifytimeout.Fallback
+	if h.sendTimeoutFor != nil {
+		attempt = 0
+		for _, dispatch := range notifytimeout.Types {
+			attempt = max(attempt, h.sendTimeoutFor(agentID, dispatch))
+		}
 	}
+	return 2 * openclaw.SendBudget(attempt)
+}
+
+// sendCallback is an openclaw.AgentSendCallback that is given a context.
+type sendCallback func(ctx context.Context, taskID, agentID, reply string, err error)
+
+// scoped prepares a background send to agentID about taskID: it returns the sender to
+// send with, running under a task-scoped context begun from ctx, and
+// callback wrapped to run under a context detached from it (see taskctx),
+// closing the scope when done.
+func (h *TaskHandler) scoped(ctx context.Context, taskID, agentID string, callback sendCallback) (openclaw.Sender, openclaw.AgentSendCallback) {
+	sendCtx, release := h.scopes.Begin(ctx, taskID, agentID, h.sendScopeTimeout(agentID))
+	return h.agentSender.For(sendCtx), func(tID, aID, reply string, err error) {
+		defer release()
+		cbCtx, cancel := taskctx.Detach(sendCtx)
+		defer cancel()
+		callback(cbCtx, tID, aID, reply, err)
+	}
+}
+
+// TaskScope opens a task-scoped context for a background send to agentID
+// about taskID; release closes it once the send has returned.
+func (h *TaskHandler) TaskScope(ctx context.Context, taskID, agentID string) (scope context.Context, release func()) {
+	return h.scopes.Begin(ctx, taskID, agentID, h.sendScopeTimeout(agentID))
 }
 
 func (h *TaskHandler) SetOrchestrator(orch Orchestrator) {
 	h.orchestrator = orch
 }
 
+// SetAvailability sets the tracker consulted before the task count when
+// deciding whether an agent is busy.
+func (h *TaskHandler) SetAvailability(t *availability.Tracker) {
+	h.availability = t
+}
+
+// SetRateLimiter sets the limiter holding dispatch back from rate-limited
+// agents and models.
+func (h *TaskHandler) SetRateLimiter(l *ratelimit.Limiter) {
+	h.rateLimits = l
+}
+
+// SetModelHealth sets the prober whose findings hold dispatch back from
+// agents with no available model.
+func (h *TaskHandler) SetModelHealth(p *modelhealth.Prober) {
+	h.modelHealth = p
+}
+
+// SetGitHubSync sets the syncer closing the GitHub issues tasks were created
+// from when they are done.
+func (h *TaskHandler) SetGitHubSync(g *github.Syncer) {
+	h.githubSync = g
+}
+
+// SetNotifier sets who is told when tasks finish and subtasks await
+// approval.
+func (h *TaskHandler) SetNotifier(n TaskNotifier) {
+	h.notifier = n
+}
+
+// SetGateway sets the gateway whose session history tells whether a
+// notification interrupted by a restart arrived (see Resume).
+func (h *TaskHandler) SetGateway(g openclaw.Gateway) {
+	h.gateway = g
+}
+
+// SetMaintenance sets the maintenance switch; while it is on new tasks are
+// refused and queues are not processed.
+func (h *TaskHandler) SetMaintenance(m *maintenance.Mode) {
+	h.maintenance = m
+}
+
+// RateLimitedUntil reports whether dispatch to the agent is held back by a
+// rate limit, and until when.
+func (h *TaskHandler) RateLimitedUntil(agentID string) (time.Time, bool) {
+	return h.rateLimits.BlockedUntil(agentID)
+}
+
+// ModelUnavailable reports whether every model the agent could run on (its
+// own or the default, then the fallbacks) was found down, and which.
+func (h *TaskHandler) ModelUnavailable(ctx context.Context, agentID string) ([]string, bool) {
+	if h.modelHealth == nil {
+		return nil, false
+	}
+	agent, err := h.store.GetAgent(ctx, agentID)
+	if err != nil {
+		return nil, false
+	}
+	usable, down := h.modelHealth.Usable(agent.Model.String)
+	return down, !usable
+}
+
 // logEvent creates a persistent event record and broadcasts it via WebSocket.
 func (h *TaskHandler) logEvent(ctx context.Context, taskID, agentID, eventType, message, details string) {
 	event, err := h.store.CreateEvent(ctx, db.CreateEventParams{
@@ -65,9 +235,145 @@ func (h *TaskHandler) logEvent(ctx context.Context, taskID, agentID, eventType,
 	}
 }
 
+// addComment adds a comment to a task and broadcasts it via WebSocket.
+func (h *TaskHandler) addComment(ctx context.Context, params db.CreateCommentParams) (db.Comment, error) {
+	comment, err := h.store.CreateComment(ctx, params)
+	if err == nil {
+		broadcastComment(h.hub, comment)
+	}
+	return comment, err
+}
+
+// broadcastQueue broadcasts the depth of agentID's queue after it changed.
+func (h *TaskHandler) broadcastQueue(ctx context.Context, agentID string) {
+	if h.hub == nil || agentID == "" || agentID == "unassigned" {
+		return
+	}
+	depth, err := h.store.CountQueuedTasksByAgent(ctx, agentID)
+	if err != nil {
+		log.Printf("[TaskHandler] Error counting queue of agent %s: %v", agentID, err)
+		return
+	}
+	h.hub.BroadcastQueueUpdated(agentID, depth)
+}
+
+// resolveTaskID returns the task ID a request field refers to: id itself,
+// or the ID of the task with that short ID (e.g. MC-142). Short IDs naming
+// no task are returned unchanged, to fail the caller's lookup.
+func (h *TaskHandler) resolveTaskID(ctx context.Context, id string) string {
+	if !taskrefs.IsShortID(id) {
+		return id
+	}
+	task, err := h.store.GetTaskByShortID(ctx, id)
+	if err != nil {
+		return id
+	}
+	return task.ID
+}
+
+// How much of a task's recent activity a re-notification embeds.
+const (
+	renotifyProgressEntries = 10
+	renotifyComments        = 5
+)
+
 // NotifyAssignedAgent is the exported hook for the stuck-task watchdog to re-notify an agent.
-func (h *TaskHandler) NotifyAssignedAgent(agentID, taskID, title, description string) {
-	h.notifyAssignedAgent(agentID, taskID, title, description)
+// With withHistory, the message embeds the task's latest progress entries,
+// comments and story states so the agent does not repeat finished work.
+func (h *TaskHandler) NotifyAssignedAgent(agentID, taskID, title, description string, withHistory bool) {
+	ctx := context.Background()
+	var history *openclaw.TaskHistory
+	if withHistory {
+		history = h.taskHistory(ctx, taskID)
+	}
+	h.sendAssignment(ctx, agentID, taskID, title, description, history)
+}
+
+// taskHistory collects what has happened on taskID so far for a
+// re-notification. Parts that cannot be read are left out.
+func (h *TaskHandler) taskHistory(ctx context.Context, taskID string) *openclaw.TaskHistory {
+	history := &openclaw.TaskHistory{}
+	if entries, err := h.store.ListProgressEntries(ctx, taskID, 0, renotifyProgressEntries); err == nil {
+		for i := len(entries) - 1; i >= 0; i-- {
+			history.Progress = append(history.Progress, entries[i].Content)
+		}
+	}
+	if comments, err := h.store.ListCommentsByTask(ctx, taskID); err == nil {
+		if len(comments) > renotifyComments {
+			comments = comments[len(comments)-renotifyComments:]
+		}
+		for _, c := range comments {
+			history.Comments = append(history.Comments, authored(c.Author, c.Content))
+		}
+	}
+	if stories, err := h.store.ListStoriesByTask(ctx, taskID); err == nil {
+		for _, s := range stories {
+			history.Stories = append(history.Stories, openclaw.StoryState{
+				Title:      s.Title,
+				Passed:     s.Passes.Bool,
+				Iterations: int(s.Iterations.Int64),
+				LastError:  s.LastError.String,
+			})
+		}
+	}
+	return history
+}
+
+// subtaskResultProgressEntries is how many of a subtask's latest progress
+// entries its completion notification quotes.
+const subtaskResultProgressEntries = 5
+
+// subtaskResultScan is how many of a subtask's latest results are searched
+// for the specialist's.
+const subtaskResultScan = 20
+
+// SubtaskResult is the openclaw subtask result resolver: what the specialist
+// produced on subtaskID, for the orchestrator's completion notification. The
+// final reply is the specialist's last result or comment, whichever is newer
+// (else the last comment not by the system).
+func (h *TaskHandler) SubtaskResult(subtaskID string) *openclaw.SubtaskResult {
+	ctx := context.Background()
+	subtask, err := h.store.GetTask(ctx, subtaskID)
+	if err != nil {
+		return nil
+	}
+	result := &openclaw.SubtaskResult{}
+	var repliedAt time.Time
+	if comments, err := h.store.ListCommentsByTask(ctx, subtaskID); err == nil {
+		for i := len(comments) - 1; i >= 0; i-- {
+			c := comments[i]
+			if c.Author == subtask.AgentID.String {
+				result.FinalReply, repliedAt = c.Content, c.CreatedAt.Time
+				break
+			}
+			if result.FinalReply == "" && c.Author != "system" {
+				result.FinalReply = c.Content
+			}
+		}
+	}
+	if results, err := h.store.ListTaskResults(ctx, subtaskID, subtaskResultScan); err == nil {
+		for _, r := range results {
+			if r.AgentID.String != subtask.AgentID.String {
+				continue
+			}
+			if !r.CreatedAt.Time.Before(repliedAt) {
+				result.FinalReply = r.Body
+			}
+			break
+		}
+	}
+	if passed, total, err := h.store.GetStoryProgress(ctx, subtaskID); err == nil {
+		result.StoriesPassed, result.StoriesTotal = int(passed), int(total)
+	}
+	if entries, err := h.store.ListProgressEntries(ctx, subtaskID, 0, subtaskResultProgressEntries); err == nil {
+		for i := len(entries) - 1; i >= 0; i-- {
+			result.Progress = append(result.Progress, entries[i].Content)
+		}
+	}
+	if result.FinalReply == "" && result.StoriesTotal == 0 && len(result.Progress) == 0 {
+		return nil
+	}
+	return result
 }
 
 // NotifyParentTaskAgent is the exported hook for the watchdog to notify the parent's orchestrator (e.g. after reset).
@@ -76,8 +382,14 @@ func (h *TaskHandler) NotifyParentTaskAgent(ctx context.Context, subtask db.Task
 }
 
 // notifyAssignedAgent fires an async notification to the agent about a task assignment.
-// It saves the agent's reply (or error) as a comment on the task.
-func (h *TaskHandler) notifyAssignedAgent(agentID, taskID, title, description string) {
+// It saves the agent's reply as a result of the task, an error as a comment. A dry-run ctx
+// records the notification to the outbox instead.
+func (h *TaskHandler) notifyAssignedAgent(ctx context.Context, agentID, taskID, title, description string) {
+	h.sendAssignment(ctx, agentID, taskID, title, description, nil)
+}
+
+// sendAssignment is notifyAssignedAgent, embedding history if not nil.
+func (h *TaskHandler) sendAssignment(ctx context.Context, agentID, taskID, title, description string, history *openclaw.TaskHistory) {
 	if h.agentSender == nil {
 		log.Printf("[TaskHandler] Agent sender not configured, skipping notification for task %s", taskID)
 		return
@@ -85,15 +397,51 @@ func (h *TaskHandler) notifyAssignedAgent(agentID, taskID, title, description st
 	if agentID == "" || agentID == "unassigned" {
 		return
 	}
+	if h.deferIfOffHours(ctx, agentID, taskID) {
+		h.recordAttempt(ctx, taskID, store.AttemptSend, agentID, "", store.AttemptDeferred, "")
+		return
+	}
+	if task, err := h.store.GetTask(ctx, taskID); err == nil && h.deferIfQuiet(ctx, agentID, task) {
+		h.recordAttempt(ctx, taskID, store.AttemptSend, agentID, "quiet hours", store.AttemptDeferred, "")
+		return
+	}
+	if h.holdForPickup(ctx, agentID, taskID) {
+		h.recordAttempt(ctx, taskID, store.AttemptSend, agentID, "queue only", store.AttemptQueued, "")
+		return
+	}
+	if h.hooks.Has(hooks.PreDispatch) {
+		task, err := h.store.GetTask(ctx, taskID)
+		if err != nil {
+			log.Printf("[TaskHandler] Failed to load task %s for pre_dispatch hooks: %v", taskID, err)
+			return
+		}
+		task, vetoed := h.vetoDispatch(ctx, agentID, task)
+		if vetoed {
+			return
+		}
+		title, description = task.Title, task.Description.String
+	}
 
 	log.Printf("[TaskHandler] Dispatching async notification to agent %s for task %s", agentID, taskID)
 
-	h.agentSender.NotifyAgentAsync(agentID, taskID, title, description, func(tID, aID, reply string, err error) {
-		ctx := context.Background()
+	deliveryID := h.beginDelivery(ctx, notificationKindTaskAssignment, taskID, transitionAssigned, agentID)
+	h.pushAssignment(ctx, agentID, taskID, title, description, history, deliveryID)
+}
+
+// pushAssignment sends a task assignment, recording the attempt and, when
+// the agent replies, the outcome of deliveryID and the reply.
+func (h *TaskHandler) pushAssignment(ctx context.Context, agentID, taskID, title, description string, history *openclaw.TaskHistory, deliveryID string) {
+	attemptID := h.recordAttempt(ctx, taskID, store.AttemptSend, agentID, "", store.AttemptPending, "")
+	sender, callback := h.scoped(ctx, taskID, agentID, func(ctx context.Context, tID, aID, reply string, err error) {
+		h.finishAttempt(ctx, attemptID, err)
+		h.finishDelivery(ctx, deliveryID, err)
 
+		if superseded(err) {
+			return
+		}
 		if err != nil {
 			log.Printf("[TaskHandler] Agent %s failed to process task %s: %v", aID, tID, err)
-			h.store.CreateComment(ctx, db.CreateCommentParams{
+			h.addComment(ctx, db.CreateCommentParams{
 				TaskID:  tID,
 				Author:  "system",
 				Content: "[Agent Notification Error] Failed to notify agent " + aID + ": " + err.Error(),
@@ -106,24 +454,65 @@ func (h *TaskHandler) notifyAssignedAgent(agentID, taskID, title, description st
 			return
 		}
 
-		log.Printf("[TaskHandler] Saving agent %s reply as comment on task %s (len=%d)", aID, tID, len(reply))
-		_, commentErr := h.store.CreateComment(ctx, db.CreateCommentParams{
-			TaskID:  tID,
-			Author:  aID,
-			Content: reply,
-		})
-		if commentErr != nil {
-			log.Printf("[TaskHandler] ERROR saving agent reply as comment: %v", commentErr)
+		kind := store.ResultAssignment
+		if history != nil {
+			kind = store.ResultRenotify
 		}
+		var metadata map[string]string
+		if deliveryID != "" {
+			metadata = map[string]string{"delivery_id": deliveryID}
+		}
+		h.saveResult(ctx, tID, aID, kind, reply, metadata)
 	})
+	if history != nil {
+		sender.RenotifyAgentAsync(agentID, taskID, title, description, history, callback)
+		return
+	}
+	sender.NotifyAgentAsync(agentID, taskID, title, description, callback)
+}
+
+// recordAttempt adds an entry to the task's retry history and returns its ID,
+// or "" if it could not be recorded.
+func (h *TaskHandler) recordAttempt(ctx context.Context, taskID, kind, agentID, reason, outcome, errMsg string) string {
+	attempt, err := h.store.RecordTaskAttempt(ctx, taskID, kind, agentID, reason, outcome, errMsg)
+	if err != nil {
+		log.Printf("[TaskHandler] Failed to record %s attempt for task %s: %v", kind, taskID, err)
+		return ""
+	}
+	return attempt.ID
+}
+
+// finishAttempt records the outcome of a send started with recordAttempt.
+func (h *TaskHandler) finishAttempt(ctx context.Context, attemptID string, sendErr error) {
+	if attemptID == "" {
+		return
+	}
+	outcome, errMsg := store.AttemptSucceeded, ""
+	if sendErr != nil {
+		outcome, errMsg = store.AttemptFailed, sendErr.Error()
+	}
+	if err := h.store.FinishTaskAttempt(ctx, attemptID, outcome, errMsg); err != nil {
+		log.Printf("[TaskHandler] Failed to finish attempt %s: %v", attemptID, err)
+	}
+}
+
+// IsAgentBusy is the exported hook for the queue processor's busy checks.
+func (h *TaskHandler) IsAgentBusy(ctx context.Context, agentID string) bool {
+	return h.isAgentBusy(ctx, agentID)
 }
 
-// isAgentBusy returns true if the agent currently has active tasks
-// (executing, planning, discussing, or verifying).
+// isAgentBusy returns true if the agent is working. Fresh availability
+// signals (an open gateway session or a recent heartbeat) decide; without
+// them the agent is busy if it has active tasks (executing, planning,
+// discussing, or verifying).
 func (h *TaskHandler) isAgentBusy(ctx context.Context, agentID string) bool {
 	if agentID == "" || agentID == "unassigned" {
 		return false
 	}
+	if st := h.availability.Status(agentID); st.State != availability.StateUnknown {
+		log.Printf("[TaskHandler] Agent %s is %s (%s)", agentID, st.State, st.Source)
+		return st.State == availability.StateBusy
+	}
 	count, err := h.store.CountActiveTasksByAgent(ctx, agentID)
 	if err != nil {
 		log.Printf("[TaskHandler] Error checking agent %s busy status: %v", agentID, err)
@@ -133,24 +522,153 @@ func (h *TaskHandler) isAgentBusy(ctx context.Context, agentID string) bool {
 	return count > 0
 }
 
+// pushes reports whether notifications of kind (see notifyprefs) are pushed
+// to the agent. Agents that cannot be read get everything.
+func (h *TaskHandler) pushes(ctx context.Context, agentID, kind string) bool {
+	agent, err := h.store.GetAgent(ctx, agentID)
+	if err != nil {
+		return true
+	}
+	return agentNotificationPrefs(agent).Push(kind)
+}
+
+// PushesAssignments is the exported hook for the queue processor's dispatch.
+func (h *TaskHandler) PushesAssignments(ctx context.Context, agentID string) bool {
+	return h.pushes(ctx, agentID, notifyprefs.TaskAssignment)
+}
+
+// queueReason returns why a task assigned to the agent goes to its queue
+// instead of being pushed now, or "" to push it.
+func (h *TaskHandler) queueReason(ctx context.Context, agentID string) string {
+	if h.isAgentBusy(ctx, agentID) {
+		return "agent is busy"
+	}
+	if !h.pushes(ctx, agentID, notifyprefs.TaskAssignment) {
+		return "agent picks up work on heartbeat"
+	}
+	if _, down := h.ModelUnavailable(ctx, agentID); down {
+		return "agent's model is unavailable"
+	}
+	return ""
+}
+
+// holdForPickup queues a task instead of pushing it when the agent does not
+// take pushed assignments, so the agent picks it up on heartbeat. Reports
+// whether the task was queued.
+func (h *TaskHandler) holdForPickup(ctx context.Context, agentID, taskID string) bool {
+	if h.pushes(ctx, agentID, notifyprefs.TaskAssignment) {
+		return false
+	}
+	if err := h.store.UpdateTaskStatus(ctx, taskID, "queued"); err != nil {
+		log.Printf("[TaskHandler] Error setting task %s to queued: %v", taskID, err)
+		return true
+	}
+	log.Printf("[TaskHandler] Agent %s takes no pushed assignments, task %s queued for heartbeat pickup", agentID, taskID)
+	h.logEvent(ctx, taskID, agentID, "task_queued",
+		fmt.Sprintf("Task queued for agent %s (agent picks up work on heartbeat)", agentID), `{"reason":"queue_only"}`)
+	if h.hub != nil {
+		h.hub.BroadcastTaskStatus(taskID, "queued", 0)
+	}
+	h.broadcastQueue(ctx, agentID)
+	return true
+}
+
+// agentWorkingHours returns the agent's working hours, or nil if it has none.
+// Unreadable hours are logged and treated as always available.
+func (h *TaskHandler) agentWorkingHours(ctx context.Context, agentID string) *workhours.Schedule {
+	agent, err := h.store.GetAgent(ctx, agentID)
+	if err != nil || !agent.WorkingHours.Valid {
+		return nil
+	}
+	sched, err := workhours.Parse(agent.WorkingHours.String)
+	if err != nil {
+		log.Printf("[TaskHandler] Ignoring working hours of agent %s: %v", agentID, err)
+		return nil
+	}
+	return sched
+}
+
+// deferIfOffHours postpones dispatch of a task while the agent is outside its
+// working hours: deferred_until is set to the next window and a task_deferred
+// event says why. The queue processor dispatches the task once it is due.
+// Reports whether dispatch was deferred.
+func (h *TaskHandler) deferIfOffHours(ctx context.Context, agentID, taskID string) bool {
+	sched := h.agentWorkingHours(ctx, agentID)
+	until, deferred := sched.Defer(time.Now())
+	if !deferred {
+		return false
+	}
+	if err := h.store.SetTaskDeferredUntil(ctx, taskID, until); err != nil {
+		log.Printf("[TaskHandler] Error deferring task %s: %v", taskID, err)
+		return true
+	}
+	log.Printf("[TaskHandler] Agent %s outside working hours, task %s deferred until %s", agentID, taskID, until.Format(time.RFC3339))
+	h.logEvent(ctx, taskID, agentID, "task_deferred",
+		fmt.Sprintf("Dispatch to agent %s deferred until %s: outside working hours (%s)", agentID, until.Format(time.RFC3339), sched),
+		fmt.Sprintf(`{"reason":"outside_working_hours","deferred_until":%q,"working_hours":%q}`, until.UTC().Format(time.RFC3339), sched.String()))
+	return true
+}
+
 // ProcessAgentQueue dequeues the next queued task for the given agent
 // and notifies them. Called when an agent finishes a task or periodically.
 func (h *TaskHandler) ProcessAgentQueue(ctx context.Context, agentID string) {
 	if agentID == "" || agentID == "unassigned" {
 		return
 	}
+	if h.maintenance.Enabled() {
+		log.Printf("[QueueProcessor] Maintenance mode, skipping queue processing for agent %s", agentID)
+		return
+	}
+	if !h.pushes(ctx, agentID, notifyprefs.TaskAssignment) {
+		log.Printf("[QueueProcessor] Agent %s picks up work on heartbeat, skipping queue processing", agentID)
+		return
+	}
 	if h.isAgentBusy(ctx, agentID) {
 		log.Printf("[QueueProcessor] Agent %s still busy, skipping queue processing", agentID)
 		return
 	}
+	if until, limited := h.rateLimits.BlockedUntil(agentID); limited {
+		log.Printf("[QueueProcessor] Agent %s rate limited until %s, skipping queue processing", agentID, until.Format(time.RFC3339))
+		return
+	}
+	if models, down := h.ModelUnavailable(ctx, agentID); down {
+		log.Printf("[QueueProcessor] Agent %s has no available model (%s), skipping queue processing", agentID, strings.Join(models, ", "))
+		return
+	}
 
 	queued, err := h.store.ListQueuedTasksByAgent(ctx, agentID)
 	if err != nil {
 		log.Printf("[QueueProcessor] Error fetching queue for agent %s: %v", agentID, err)
 		return
 	}
+	if !h.agentWorkingHours(ctx, agentID).Open(time.Now()) {
+		// Leave the queue alone; mark the head so the deferral is explained
+		// once and the task is picked up when the next window opens.
+		if len(queued) > 0 && !queued[0].DeferredUntil.Valid {
+			h.deferIfOffHours(ctx, agentID, queued[0].ID)
+		}
+		log.Printf("[QueueProcessor] Agent %s outside working hours, skipping queue processing", agentID)
+		return
+	}
+	if len(queued) > 0 && h.deferIfQuiet(ctx, agentID, queued[0]) {
+		// Hold the queue until the quiet hours end; the processor resumes it
+		log.Printf("[QueueProcessor] Quiet hours, skipping queue processing for agent %s", agentID)
+		return
+	}
 	if len(queued) == 0 {
-		log.Printf("[QueueProcessor] No queued tasks for agent %s", agentID)
+		// Nothing of its own: let the agent's groups dispatch to their free
+		// members (this agent included) using each group's strategy.
+		groups, err := h.store.ListAgentGroupsByAgent(ctx, agentID)
+		if err != nil {
+			log.Printf("[QueueProcessor] Error fetching groups for agent %s: %v", agentID, err)
+			return
+		}
+		for _, g := range groups {
+			h.DispatchGroupQueue(ctx, g.ID)
+		}
+		if len(groups) == 0 {
+			log.Printf("[QueueProcessor] No queued tasks for agent %s", agentID)
+		}
 		return
 	}
 
@@ -169,47 +687,263 @@ func (h *TaskHandler) ProcessAgentQueue(ctx context.Context, agentID string) {
 	if h.hub != nil {
 		h.hub.BroadcastTaskStatus(next.ID, "backlog", 0)
 	}
+	h.broadcastQueue(ctx, agentID)
 
 	desc := ""
 	if next.Description.Valid {
 		desc = next.Description.String
 	}
-	h.notifyAssignedAgent(agentID, next.ID, next.Title, desc)
+	h.notifyAssignedAgent(ctx, agentID, next.ID, next.Title, desc)
+}
+
+// claimNextGroupTask claims the next unclaimed task from the shared queues of
+// the agent's groups, moving it to backlog. Used for heartbeat pickup, where
+// the agent asks for work itself, so the groups' dispatch strategies do not
+// apply. A task claimed by someone else in the meantime is skipped.
+func (h *TaskHandler) claimNextGroupTask(ctx context.Context, agentID, trigger string) (db.Task, bool) {
+	candidates, err := h.store.ListQueuedGroupTasksForAgent(ctx, agentID)
+	if err != nil {
+		log.Printf("[QueueProcessor] Error fetching group queues for agent %s: %v", agentID, err)
+		return db.Task{}, false
+	}
+	quiet := h.quietHours(ctx)
+	for _, candidate := range candidates {
+		if _, deferred := quiet.Defer(time.Now(), int(candidate.Priority.Int64)); deferred {
+			continue
+		}
+		task, err := h.store.ClaimGroupTask(ctx, candidate.ID, agentID)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			log.Printf("[QueueProcessor] Error claiming group task %s for agent %s: %v", candidate.ID, agentID, err)
+			return db.Task{}, false
+		}
+
+		groupID := task.GroupID.String
+		log.Printf("[QueueProcessor] Agent %s claimed task %s (%s) from group %s", agentID, task.ID, task.Title, groupID)
+		h.logEvent(ctx, task.ID, agentID, "task_claimed",
+			fmt.Sprintf("Task claimed by agent %s from group %s queue", agentID, groupID),
+			fmt.Sprintf(`{"group_id":%q,"priority":%d,"trigger":%q}`, groupID, task.Priority.Int64, trigger))
+		if h.hub != nil {
+			h.hub.BroadcastTaskStatus(task.ID, "backlog", 0)
+		}
+		return task, true
+	}
+	return db.Task{}, false
+}
+
+// queueForGroup puts a task in a group's shared queue and offers it to the
+// group's free members. It returns the task as it stands afterwards.
+func (h *TaskHandler) queueForGroup(ctx context.Context, task db.Task, groupID string) db.Task {
+	queued, err := h.store.AssignTaskToGroup(ctx, task.ID, groupID)
+	if err != nil {
+		log.Printf("[TaskHandler] Error queuing task %s for group %s: %v", task.ID, groupID, err)
+		return task
+	}
+	h.logEvent(ctx, task.ID, "", "task_queued",
+		fmt.Sprintf("Task queued for group %s", groupID),
+		fmt.Sprintf(`{"group_id":%q}`, groupID))
+	if h.hub != nil {
+		h.hub.BroadcastTaskStatus(task.ID, "queued", 0)
+	}
+
+	h.DispatchGroupQueue(ctx, groupID)
+
+	if current, err := h.store.GetTask(ctx, task.ID); err == nil {
+		return current
+	}
+	return queued
+}
+
+// DispatchGroupQueue hands a group's queued tasks to its free members, at
+// most one per member per call. The group's dispatch strategy picks the
+// member for each task and every decision is logged as a group_dispatch
+// event. Called when a task is assigned to the group, a member joins, or a
+// member becomes free.
+func (h *TaskHandler) DispatchGroupQueue(ctx context.Context, groupID string) {
+	if h.maintenance.Enabled() {
+		return
+	}
+	group, err := h.store.GetAgentGroup(ctx, groupID)
+	if err != nil {
+		log.Printf("[QueueProcessor] Error fetching group %s: %v", groupID, err)
+		return
+	}
+	queued, err := h.store.ListQueuedTasksByGroup(ctx, groupID)
+	if err != nil || len(queued) == 0 {
+		return
+	}
+	memberships, err := h.store.ListAgentGroupMemberships(ctx, groupID)
+	if err != nil {
+		log.Printf("[QueueProcessor] Error listing members of group %s: %v", groupID, err)
+		return
+	}
+
+	members := make([]string, len(memberships))
+	for i, m := range memberships {
+		members[i] = m.AgentID
+	}
+	state := dispatch.State{LastAgentID: group.LastDispatchedAgentID.String, Members: members}
+	taken := make(map[string]bool)
+	var ratings map[string]float64
+	if group.DispatchStrategy == dispatch.BestPerformer {
+		ratings = h.memberRatings(ctx, members)
+	}
+
+	quiet := h.quietHours(ctx)
+	for _, task := range queued {
+		if _, deferred := quiet.Defer(time.Now(), int(task.Priority.Int64)); deferred {
+			continue // stays queued until the quiet hours end
+		}
+		candidates := h.groupCandidates(ctx, memberships, taken)
+		if len(candidates) == 0 {
+			log.Printf("[QueueProcessor] No free members in group %s; %s stays queued", group.Name, task.ID)
+			return
+		}
+		for i := range candidates {
+			candidates[i].Rating = ratings[candidates[i].AgentID]
+		}
+
+		decision := dispatch.Choose(group.DispatchStrategy, dispatch.Task{
+			ID:          task.ID,
+			Title:       task.Title,
+			Description: task.Description.String,
+		}, candidates, state)
+
+		claimed, err := h.store.ClaimGroupTask(ctx, task.ID, decision.AgentID)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue // claimed via heartbeat in the meantime
+		}
+		if err != nil {
+			log.Printf("[QueueProcessor] Error claiming group task %s for agent %s: %v", task.ID, decision.AgentID, err)
+			return
+		}
+
+		taken[decision.AgentID] = true
+		state.LastAgentID = decision.AgentID
+		if err := h.store.SetAgentGroupLastDispatched(ctx, groupID, decision.AgentID); err != nil {
+			log.Printf("[QueueProcessor] Error recording last dispatch for group %s: %v", groupID, err)
+		}
+
+		log.Printf("[QueueProcessor] Group %s dispatched task %s to agent %s (%s: %s)", group.Name, task.ID, decision.AgentID, decision.Strategy, decision.Reason)
+		details, _ := json.Marshal(struct {
+			GroupID string `json:"group_id"`
+			dispatch.Decision
+		}{groupID, decision})
+		h.logEvent(ctx, task.ID, decision.AgentID, "group_dispatch",
+			fmt.Sprintf("Group %s dispatched task to agent %s (%s: %s)", group.Name, decision.AgentID, decision.Strategy, decision.Reason),
+			string(details))
+		if h.hub != nil {
+			h.hub.BroadcastTaskStatus(task.ID, "backlog", 0)
+		}
+
+		desc := ""
+		if claimed.Description.Valid {
+			desc = claimed.Description.String
+		}
+		h.notifyAssignedAgent(ctx, decision.AgentID, claimed.ID, claimed.Title, desc)
+	}
+}
+
+// groupCandidates returns the members that can take a group task now: within
+// working hours, not rate limited, with an available model, not busy, nothing queued of their own, and not already given
+// a task in this dispatch round.
+func (h *TaskHandler) groupCandidates(ctx context.Context, memberships []db.AgentGroupMember, taken map[string]bool) []dispatch.Candidate {
+	var candidates []dispatch.Candidate
+	now := time.Now()
+	for _, m := range memberships {
+		if taken[m.AgentID] || h.isAgentBusy(ctx, m.AgentID) {
+			continue
+		}
+		if !h.pushes(ctx, m.AgentID, notifyprefs.TaskAssignment) {
+			// It claims group work itself on heartbeat
+			continue
+		}
+		if !h.agentWorkingHours(ctx, m.AgentID).Open(now) {
+			log.Printf("[QueueProcessor] Agent %s outside working hours, not a dispatch candidate", m.AgentID)
+			continue
+		}
+		if _, limited := h.rateLimits.BlockedUntil(m.AgentID); limited {
+			log.Printf("[QueueProcessor] Agent %s rate limited, not a dispatch candidate", m.AgentID)
+			continue
+		}
+		if _, down := h.ModelUnavailable(ctx, m.AgentID); down {
+			log.Printf("[QueueProcessor] Agent %s has no available model, not a dispatch candidate", m.AgentID)
+			continue
+		}
+		if own, err := h.store.CountQueuedTasksByAgent(ctx, m.AgentID); err != nil || own > 0 {
+			continue
+		}
+		load, err := h.store.CountOpenTasksByAgent(ctx, m.AgentID)
+		if err != nil {
+			log.Printf("[QueueProcessor] Error counting open tasks for agent %s: %v", m.AgentID, err)
+			continue
+		}
+		var skills []string
+		if m.Skills.Valid {
+			_ = json.Unmarshal([]byte(m.Skills.String), &skills)
+		}
+		candidates = append(candidates, dispatch.Candidate{AgentID: m.AgentID, Load: load, Skills: skills})
+	}
+	return candidates
 }
 
 // Request types
 type CreateTaskRequest struct {
-	Title          string `json:"title" validate:"required"`
-	Description    string `json:"description"`
-	AgentID        string `json:"agent_id"`
-	ProjectID      string `json:"project_id"`
-	ParentTaskID   string `json:"parent_task_id"`
-	Status         string `json:"status"`
-	Priority       int    `json:"priority"`
-	QualityChecks  string `json:"quality_checks"`
-	DelegationMode string `json:"delegation_mode"`
-	ScheduledAt    string `json:"scheduled_at"`
-	GitBranch      string `json:"git_branch"`
+	Title          string   `json:"title" validate:"required"`
+	Description    string   `json:"description"`
+	AgentID        string   `json:"agent_id"`
+	ProjectID      string   `json:"project_id"`
+	ParentTaskID   string   `json:"parent_task_id"`
+	Status         string   `json:"status" validate:"omitempty,oneof=backlog queued planning discussing executing verifying review done failed paused cancelled"`
+	Priority       int      `json:"priority" validate:"omitempty,min=1,max=5"` // 1 is the most urgent
+	QualityChecks  string   `json:"quality_checks"`
+	DelegationMode string   `json:"delegation_mode" validate:"omitempty,oneof=auto manual"`
+	ScheduledAt    string   `json:"scheduled_at"` // RFC3339 with a zone; a past time dispatches now
+	Timezone       string   `json:"timezone"`     // IANA zone of a scheduled_at without an offset
+	GitBranch      string   `json:"git_branch"`
+	GroupID        string   `json:"group_id"` // assign to a group's shared queue instead of an agent
+	Secrets        []string `json:"secrets"`  // project secrets injected into the assignment notification
+	Model          string   `json:"model"`    // run on this model regardless of the routing policy
+	// Review before done; naming a reviewer (an agent or a user) requires it
+	RequiresReview  bool   `json:"requires_review"`
+	ReviewerAgentID string `json:"reviewer_agent_id"`
+	ReviewerUser    string `json:"reviewer_user"`
+	// Delegation limits for subtasks of this task; omitted = the project's
+	MaxSubtaskDepth       *int `json:"max_subtask_depth"`
+	MaxConcurrentSubtasks *int `json:"max_concurrent_subtasks"`
 }
 
 type UpdateTaskRequest struct {
-	Title          string  `json:"title"`
-	Description    string  `json:"description"`
-	AgentID        *string `json:"agent_id"`
-	ProjectID      *string `json:"project_id"`
-	Status         string  `json:"status"`
-	Priority       int     `json:"priority"`
-	ProjectMD      string  `json:"project_md"`
-	RequirementsMD string  `json:"requirements_md"`
-	RoadmapMD      string  `json:"roadmap_md"`
-	StateMD        string  `json:"state_md"`
-	PrdJSON        string  `json:"prd_json"`
-	ProgressTxt    string  `json:"progress_txt"`
-	GitBranch      string  `json:"git_branch"`
-	QualityChecks  string  `json:"quality_checks"`
-	DelegationMode string  `json:"delegation_mode"`
-	ScheduledAt   string  `json:"scheduled_at"`
-	ClearSchedule bool    `json:"clear_schedule"`
+	Title          string    `json:"title"`
+	Description    string    `json:"description"`
+	AgentID        *string   `json:"agent_id"`
+	ProjectID      *string   `json:"project_id"`
+	Status         string    `json:"status" validate:"omitempty,oneof=backlog queued planning discussing executing verifying review done failed paused cancelled"`
+	Priority       int       `json:"priority" validate:"omitempty,min=1,max=5"`
+	ProjectMD      string    `json:"project_md"`
+	RequirementsMD string    `json:"requirements_md"`
+	RoadmapMD      string    `json:"roadmap_md"`
+	StateMD        string    `json:"state_md"`
+	PrdJSON        string    `json:"prd_json"`
+	ProgressTxt    string    `json:"progress_txt"`
+	GitBranch      string    `json:"git_branch"`
+	QualityChecks  string    `json:"quality_checks"`
+	DelegationMode string    `json:"delegation_mode" validate:"omitempty,oneof=auto manual"`
+	ScheduledAt    string    `json:"scheduled_at"` // as on create
+	Timezone       string    `json:"timezone"`
+	ClearSchedule  bool      `json:"clear_schedule"`
+	GroupID        string    `json:"group_id"` // move to a group's shared queue
+	Secrets        *[]string `json:"secrets"`  // nil leaves them unchanged, [] removes them
+	Model          *string   `json:"model"`    // nil leaves it unchanged, "" defers to the routing policy
+	// Review before done; nil leaves a setting unchanged, "" removes a reviewer
+	RequiresReview  *bool   `json:"requires_review"`
+	ReviewerAgentID *string `json:"reviewer_agent_id"`
+	ReviewerUser    *string `json:"reviewer_user"`
+	// Delegation limits; nil leaves a limit unchanged, -1 removes it
+	MaxSubtaskDepth       *int `json:"max_subtask_depth"`
+	MaxConcurrentSubtasks *int `json:"max_concurrent_subtasks"`
 }
 
 type CreatePhaseRequest struct {
@@ -248,6 +982,50 @@ func fuzzyMatch(query, text string) bool {
 
 // Task CRUD
 func (h *TaskHandler) List(c echo.Context) error {
+	tasks, err := h.listTasks(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, h.taskResponses(c.Request().Context(), tasks...))
+}
+
+// Export - GET /api/v1/tasks/export?format=csv
+// Returns the tasks GET /tasks would, with the same filters and order, as a
+// CSV file for spreadsheets.
+func (h *TaskHandler) Export(c echo.Context) error {
+	if format := c.QueryParam("format"); format != "" && format != "csv" {
+		return echo.NewHTTPError(http.StatusBadRequest, "format must be csv")
+	}
+	tasks, err := h.listTasks(c)
+	if err != nil {
+		return err
+	}
+	ctx := c.Request().Context()
+	agents, err := h.store.ListAgents(ctx)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	projects, err := h.store.ListProjects(ctx)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	agentNames := make(map[string]string, len(agents))
+	for _, a := range agents {
+		agentNames[a.ID] = a.Name
+	}
+	projectNames := make(map[string]string, len(projects))
+	for _, p := range projects {
+		projectNames[p.ID] = p.Name
+	}
+	return writeCSV(c, "tasks", func(w *csv.Writer) error {
+		_, err := warehouse.WriteTasks(w, tasks, agentNames, projectNames)
+		return err
+	})
+}
+
+// listTasks returns the tasks GET /tasks lists: filtered by status, agent_id
+// and search, and sorted by sort_by and sort_order.
+func (h *TaskHandler) listTasks(c echo.Context) ([]db.Task, error) {
 	status := c.QueryParam("status")
 	agentID := c.QueryParam("agent_id")
 
@@ -263,7 +1041,7 @@ func (h *TaskHandler) List(c echo.Context) error {
 	}
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
 	// Search filter
@@ -322,32 +1100,213 @@ func (h *TaskHandler) List(c echo.Context) error {
 		}
 		return !less
 	})
-
-	return c.JSON(http.StatusOK, ToTaskResponses(tasks))
+	return tasks, nil
 }
 
 func (h *TaskHandler) Get(c echo.Context) error {
 	id := c.Param("id")
 	task, err := h.store.GetTask(c.Request().Context(), id)
 	if err != nil {
+		// A task merged into another redirects to the survivor
+		if r, rerr := h.store.GetTaskRedirect(c.Request().Context(), id); rerr == nil {
+			target := *c.Request().URL
+			target.Path = strings.Replace(target.Path, id, r.ToTaskID, 1)
+			return c.Redirect(http.StatusMovedPermanently, target.RequestURI())
+		}
 		return echo.NewHTTPError(http.StatusNotFound, "Task not found")
 	}
 
-	// Get phases and stories
-	phases, _ := h.store.ListPhasesByTask(c.Request().Context(), id)
-	stories, _ := h.store.ListStoriesByTask(c.Request().Context(), id)
+	includes, ok := parseTaskIncludes(c.QueryParam("include"))
+	if !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, "include must be a comma-separated list of phases, stories, subtasks, comments, events, agent, project")
+	}
+	ctx := c.Request().Context()
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"task":    ToTaskResponse(task),
-		"phases":  phases,
-		"stories": stories,
-	})
+	// Get phases and stories
+	phases, _ := h.store.ListPhasesByTask(ctx, id)
+	stories, _ := h.store.ListStoriesByTask(ctx, id)
+	links, backlinks := taskLinks(ctx, h.store, id)
+
+	resp := map[string]interface{}{
+		"task":      h.taskResponse(ctx, task),
+		"phases":    phases,
+		"stories":   stories,
+		"links":     links,
+		"backlinks": backlinks,
+	}
+	if includes["subtasks"] {
+		subtasks, err := h.store.ListSubtasks(ctx, sql.NullString{String: id, Valid: true})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		resp["subtasks"] = h.taskResponses(ctx, subtasks...)
+	}
+	if includes["comments"] {
+		comments, err := h.store.ListCommentsByTask(ctx, id)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		out := make([]CommentResponse, len(comments))
+		for i, cm := range comments {
+			out[i] = toCommentListResponse(cm)
+		}
+		resp["comments"] = out
+	}
+	if includes["events"] {
+		events, err := h.store.ListEventsByTask(ctx, id, taskIncludeEventLimit)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		resp["events"] = ToEventResponses(events)
+	}
+	if includes["agent"] {
+		// null when the task is unassigned or its agent was removed
+		var agent *AgentResponse
+		if task.AgentID.Valid {
+			if a, err := h.store.GetAgent(ctx, task.AgentID.String); err == nil {
+				r := ToAgentResponse(a)
+				agent = &r
+			}
+		}
+		resp["agent"] = agent
+	}
+	if includes["project"] {
+		var project *ProjectResponse
+		if task.ProjectID.Valid {
+			if p, err := h.store.GetProject(ctx, task.ProjectID.String); err == nil {
+				r := toProjectResponse(p)
+				project = &r
+			}
+		}
+		resp["project"] = project
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// taskIncludeEventLimit caps the events returned by ?include=events (most
+// recent first); GET /events pages further back.
+const taskIncludeEventLimit = 50
+
+// taskIncludes are the relations GET /tasks/:id can add to its response.
+// Phases and stories are always returned; naming them is allowed.
+var taskIncludes = map[string]bool{
+	"phases": true, "stories": true, "subtasks": true, "comments": true,
+	"events": true, "agent": true, "project": true,
+}
+
+// parseTaskIncludes parses the include query parameter. ok is false if it
+// names a relation that does not exist.
+func parseTaskIncludes(raw string) (map[string]bool, bool) {
+	includes := make(map[string]bool)
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !taskIncludes[name] {
+			return nil, false
+		}
+		includes[name] = true
+	}
+	return includes, true
 }
 
 func (h *TaskHandler) Create(c echo.Context) error {
 	var req CreateTaskRequest
-	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+	task, err := h.CreateTask(c.Request().Context(), req)
+	var refused *subtaskRefusedError
+	if errors.As(err, &refused) {
+		return apierror.WithDetails(http.StatusUnprocessableEntity, apierror.DelegationLimit, refused.violation.Error, refused.violation)
+	}
+	if err != nil {
+		return err
+	}
+	resp := h.taskResponse(c.Request().Context(), task)
+	// A scheduled_at that parsed but left the task unscheduled had passed
+	resp.DispatchedImmediately = req.ScheduledAt != "" && !task.ScheduledAt.Valid
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// parseScheduledAt parses the scheduled_at of a request, an RFC3339
+// timestamp with a zone offset, and returns it in UTC. Without an offset it
+// is a wall clock time in timezone, the request's IANA zone, else the
+// reader's (?tz= or X-Timezone). past reports whether it is not after now:
+// such a task is dispatched at once rather than scheduled. A malformed
+// timestamp or unknown zone is a 422.
+func parseScheduledAt(ctx context.Context, s, timezone string, now time.Time) (t time.Time, past bool, err error) {
+	loc := localtime.FromContext(ctx)
+	if timezone != "" {
+		if loc, err = localtime.Load(timezone); err != nil {
+			return time.Time{}, false, echo.NewHTTPError(http.StatusUnprocessableEntity, validation.Response{Errors: validation.Errors{{
+				Field:   "timezone",
+				Message: "must be an IANA time zone, e.g. Europe/Berlin",
+			}}})
+		}
+	}
+	t, err = time.Parse(time.RFC3339, strings.TrimSpace(s))
+	if err != nil && loc != nil {
+		t, err = localtime.ParseWall(s, loc)
+	}
+	if err != nil {
+		return time.Time{}, false, echo.NewHTTPError(http.StatusUnprocessableEntity, validation.Response{Errors: validation.Errors{{
+			Field:   "scheduled_at",
+			Message: "must be an RFC3339 timestamp with a time zone, e.g. 2026-02-08T10:00:00Z or 2026-02-08T12:00:00+02:00",
+		}}})
+	}
+	t = t.UTC()
+	return t, !t.After(now), nil
+}
+
+// CreateTask creates and dispatches a task as POST /tasks does, for callers
+// other than the API. Invalid requests fail with an *echo.HTTPError (503 in
+// maintenance mode), and subtasks over a delegation limit with a
+// *subtaskRefusedError.
+func (h *TaskHandler) CreateTask(ctx context.Context, req CreateTaskRequest) (db.Task, error) {
+	return h.createTask(ctx, req, newTask{})
+}
+
+// CreateLinkedTask creates a task from a JIRA or GitHub issue or an email
+// as CreateTask does, linking it to where it came from in the transaction
+// that inserts it. It implements intake.Creator.
+func (h *TaskHandler) CreateLinkedTask(ctx context.Context, t intake.Task, link intake.Link) (db.Task, error) {
+	return h.createTask(ctx, CreateTaskRequest{
+		Title:       t.Title,
+		Description: t.Description,
+		ProjectID:   t.ProjectID,
+		Status:      t.Status,
+		Priority:    t.Priority,
+	}, newTask{link: link, logCreated: func(task db.Task) {
+		h.logEvent(ctx, task.ID, task.AgentID.String, "task_created",
+			fmt.Sprintf("Task created from %s: %s", t.Source, task.Title), "")
+	}})
+}
+
+// newTask is what createTask does with a task beyond its request.
+type newTask struct {
+	// link runs in the transaction that inserts the task
+	link func(tx *store.Store, task db.Task) error
+	// setup runs on the created task before it is dispatched, e.g. to add
+	// the phases and stories it is worked through
+	setup func(db.Task) (db.Task, error)
+	// logCreated records the creation of a task that is not a subtask, in
+	// place of the task_created event
+	logCreated func(task db.Task)
+}
+
+// createTask is CreateTask, doing with the task what opts asks for.
+func (h *TaskHandler) createTask(ctx context.Context, req CreateTaskRequest, opts newTask) (db.Task, error) {
+	if h.maintenance.Enabled() {
+		return db.Task{}, apierror.New(http.StatusServiceUnavailable, apierror.Maintenance, h.maintenance.Message())
+	}
+
+	// Task scripts may route the task or reject it before it is checked
+	req, scripted, err := h.runTaskScripts(ctx, req)
+	if err != nil {
+		return db.Task{}, err
 	}
 
 	status := req.Status
@@ -363,22 +1322,75 @@ func (h *TaskHandler) Create(c echo.Context) error {
 	var scheduledAt sql.NullTime
 	isScheduled := false
 	if req.ScheduledAt != "" {
-		if t, err := time.Parse(time.RFC3339, req.ScheduledAt); err == nil && t.After(time.Now()) {
+		t, past, err := parseScheduledAt(ctx, req.ScheduledAt, req.Timezone, time.Now())
+		if err != nil {
+			return db.Task{}, err
+		}
+		if past {
+			log.Printf("[TaskHandler] scheduled_at %s has passed, dispatching immediately", req.ScheduledAt)
+		} else {
 			scheduledAt = sql.NullTime{Time: t, Valid: true}
 			isScheduled = true
 		}
 	}
 
-	// If this is a subtask (has parent_task_id), inherit the parent's git_branch
+	if req.GroupID != "" {
+		if req.AgentID != "" && req.AgentID != "unassigned" {
+			return db.Task{}, echo.NewHTTPError(http.StatusBadRequest, "Specify agent_id or group_id, not both")
+		}
+		if isScheduled {
+			return db.Task{}, echo.NewHTTPError(http.StatusBadRequest, "Group tasks cannot be scheduled")
+		}
+		if _, err := h.store.GetAgentGroup(ctx, req.GroupID); err != nil {
+			return db.Task{}, echo.NewHTTPError(http.StatusBadRequest, "Group not found")
+		}
+	}
+
+	if err := checkTaskSecrets(ctx, h.store, req.ProjectID, req.Secrets); err != nil {
+		return db.Task{}, err
+	}
+
+	if err := checkDelegationRequest(false, req.MaxSubtaskDepth, req.MaxConcurrentSubtasks); err != nil {
+		return db.Task{}, err
+	}
+
+	if err := checkReviewer(ctx, h.store, req.AgentID, req.ReviewerAgentID, req.ReviewerUser); err != nil {
+		return db.Task{}, err
+	}
+	requiresReview := req.RequiresReview || req.ReviewerAgentID != "" || req.ReviewerUser != ""
+
+	req.ParentTaskID = h.resolveTaskID(ctx, req.ParentTaskID)
+
+	// If this is a subtask (has parent_task_id), it must stay within the
+	// delegation limits, and inherits the parent's git_branch
 	gitBranch := req.GitBranch
-	if req.ParentTaskID != "" && gitBranch == "" {
-		parentTask, err := h.store.GetTask(c.Request().Context(), req.ParentTaskID)
-		if err == nil && parentTask.GitBranch.Valid {
+	if req.ParentTaskID != "" {
+		parentTask, err := h.store.GetTask(ctx, req.ParentTaskID)
+		if err != nil {
+			return db.Task{}, echo.NewHTTPError(http.StatusBadRequest, "Parent task not found")
+		}
+		violation, err := h.checkDelegationLimits(ctx, parentTask, req.ProjectID)
+		if err != nil {
+			return db.Task{}, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		if violation != nil {
+			return db.Task{}, h.refuseSubtask(ctx, parentTask, req.Title, violation)
+		}
+		if gitBranch == "" && parentTask.GitBranch.Valid {
 			gitBranch = parentTask.GitBranch.String
 		}
 	}
 
-	task, err := h.store.CreateTask(c.Request().Context(), db.CreateTaskParams{
+	// An eligible task takes part in an experiment, on one of its arms at
+	// random; the arm of an agent experiment decides who gets the task
+	experiment, arm, inExperiment := h.pickExperimentArm(ctx, req)
+	if inExperiment && experiment.Kind == experimentKindAgents {
+		req.AgentID = experimentArm(experiment, arm)
+	}
+
+	// The fields the insert leaves out are set in its transaction, so a
+	// failure cannot leave a task without its secrets, limits or reviewer
+	task, err := h.store.CreateTaskWith(ctx, db.CreateTaskParams{
 		Title:          req.Title,
 		Description:    sql.NullString{String: req.Description, Valid: req.Description != ""},
 		AgentID:        sql.NullString{String: req.AgentID, Valid: req.AgentID != "" && req.AgentID != "unassigned"},
@@ -390,42 +1402,199 @@ func (h *TaskHandler) Create(c echo.Context) error {
 		DelegationMode: sql.NullString{String: delegationMode, Valid: true},
 		ScheduledAt:    scheduledAt,
 		GitBranch:      sql.NullString{String: gitBranch, Valid: gitBranch != ""},
+	}, func(tx *store.Store, task db.Task) error {
+		if len(req.Secrets) > 0 {
+			if err := tx.SetTaskSecretNames(ctx, task.ID, req.Secrets); err != nil {
+				return err
+			}
+		}
+		if req.MaxSubtaskDepth != nil || req.MaxConcurrentSubtasks != nil {
+			if err := tx.SetTaskDelegationLimits(ctx, task.ID, limitParam(sql.NullInt64{}, req.MaxSubtaskDepth), limitParam(sql.NullInt64{}, req.MaxConcurrentSubtasks)); err != nil {
+				return err
+			}
+		}
+		if req.Model != "" {
+			if err := tx.SetTaskModel(ctx, task.ID, req.Model); err != nil {
+				return err
+			}
+		}
+		if requiresReview {
+			if err := tx.SetTaskReview(ctx, task.ID, true, req.ReviewerAgentID, req.ReviewerUser); err != nil {
+				return err
+			}
+		}
+		if opts.link != nil {
+			return opts.link(tx, task)
+		}
+		return nil
 	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return db.Task{}, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	ctx := c.Request().Context()
+	if opts.setup != nil {
+		if task, err = opts.setup(task); err != nil {
+			return db.Task{}, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	if req.Description != "" {
+		syncTaskLinks(ctx, h.store, task.ID, store.LinkFromDescription, task.ID, req.Description)
+	}
 
 	if req.ParentTaskID != "" {
 		h.logEvent(ctx, req.ParentTaskID, req.AgentID, "subtask_created",
 			fmt.Sprintf("Subtask created: %s", req.Title),
 			fmt.Sprintf(`{"subtask_id":"%s","assigned_to":"%s"}`, task.ID, req.AgentID))
+	} else if opts.logCreated != nil {
+		opts.logCreated(task)
 	} else {
 		h.logEvent(ctx, task.ID, req.AgentID, "task_created",
 			fmt.Sprintf("Task created: %s", req.Title), "")
 	}
 
-	if req.AgentID != "" && req.AgentID != "unassigned" && !isScheduled {
-		if h.isAgentBusy(ctx, req.AgentID) {
-			log.Printf("[TaskHandler] Agent %s is busy, queuing task %s", req.AgentID, task.ID)
+	if len(scripted.Changes) > 0 {
+		names := make([]string, len(scripted.Changes))
+		for i, ch := range scripted.Changes {
+			names[i] = ch.Script
+		}
+		details, _ := json.Marshal(map[string]interface{}{"changes": scripted.Changes})
+		h.logEvent(ctx, task.ID, req.AgentID, "task_scripted",
+			fmt.Sprintf("Task scripts changed the new task: %s", strings.Join(names, ", ")), string(details))
+	}
+	for _, f := range scripted.Failures {
+		details, _ := json.Marshal(f)
+		h.logEvent(ctx, task.ID, req.AgentID, "task_script_failed",
+			fmt.Sprintf("Task script %s failed: %s", f.Script, f.Error), string(details))
+	}
+	if inExperiment {
+		h.recordExperimentArm(ctx, task, experiment, arm)
+	}
+	if req.Description != "" {
+		h.NotifyMentions(ctx, task, store.LinkFromDescription, task.ID, "", req.Description, "")
+	}
+
+	task = h.dispatchNewTask(ctx, task, req.GroupID)
+	if h.needsTriage(task) {
+		if _, err := h.requestTriage(ctx, task); err != nil {
+			log.Printf("[TaskHandler] Failed to request triage of task %s: %v", task.ID, err)
+		}
+	}
+	return task, nil
+}
+
+// dispatchNewTask hands a freshly created task to its agent — queued if the
+// agent is busy, notified otherwise — or to groupID's shared queue. Scheduled
+// tasks are left for the queue processor. It returns the task with its
+// updated status.
+func (h *TaskHandler) dispatchNewTask(ctx context.Context, task db.Task, groupID string) db.Task {
+	agentID := task.AgentID.String
+	if task.ScheduledAt.Valid {
+		log.Printf("[TaskHandler] Task %s scheduled for %s — skipping immediate dispatch", task.ID, task.ScheduledAt.Time.Format(time.RFC3339))
+		return task
+	}
+	if agentID != "" && agentID != "unassigned" {
+		if reason := h.queueReason(ctx, agentID); reason != "" {
+			log.Printf("[TaskHandler] Queuing task %s for agent %s (%s)", task.ID, agentID, reason)
 			if err := h.store.UpdateTaskStatus(ctx, task.ID, "queued"); err != nil {
 				log.Printf("[TaskHandler] Error setting task %s to queued: %v", task.ID, err)
 			} else {
 				task.Status = sql.NullString{String: "queued", Valid: true}
 			}
-			h.logEvent(ctx, task.ID, req.AgentID, "task_queued",
-				fmt.Sprintf("Task queued for agent %s (agent is busy)", req.AgentID), "")
+			h.logEvent(ctx, task.ID, agentID, "task_queued",
+				fmt.Sprintf("Task queued for agent %s (%s)", agentID, reason), "")
 			if h.hub != nil {
 				h.hub.BroadcastTaskStatus(task.ID, "queued", 0)
 			}
+			h.broadcastQueue(ctx, agentID)
 		} else {
-			h.logEvent(ctx, task.ID, req.AgentID, "agent_notified",
-				fmt.Sprintf("Notifying agent %s of task assignment", req.AgentID), "")
-			h.notifyAssignedAgent(req.AgentID, task.ID, req.Title, req.Description)
+			h.logEvent(ctx, task.ID, agentID, "agent_notified",
+				fmt.Sprintf("Notifying agent %s of task assignment", agentID), "")
+			h.notifyAssignedAgent(ctx, agentID, task.ID, task.Title, task.Description.String)
+		}
+	} else if groupID != "" {
+		task = h.queueForGroup(ctx, task, groupID)
+	}
+	return task
+}
+
+type CloneTaskRequest struct {
+	Title          string  `json:"title"`      // default: the source task's title
+	AgentID        *string `json:"agent_id"`   // omitted = same agent; "" = unassigned
+	ProjectID      *string `json:"project_id"` // omitted = same project; "" = none
+	GroupID        string  `json:"group_id"`
+	IncludePhases  bool    `json:"include_phases"`
+	IncludeStories bool    `json:"include_stories"`
+}
+
+// Clone creates a new task from an existing task's definition (title,
+// description, assignment, priority, quality checks, delegation mode and git
+// branch), optionally with its phases and stories. Progress, planning
+// documents, comments and history are not copied. The clone is created like
+// a new task, through the task scripts, dispatch and triage.
+func (h *TaskHandler) Clone(c echo.Context) error {
+	id := c.Param("id")
+	ctx := c.Request().Context()
+
+	var req CloneTaskRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+
+	src, err := h.store.GetTask(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+	}
+
+	create := CreateTaskRequest{
+		Title:          src.Title,
+		Description:    src.Description.String,
+		AgentID:        src.AgentID.String,
+		ProjectID:      src.ProjectID.String,
+		Priority:       int(src.Priority.Int64),
+		QualityChecks:  src.QualityChecks.String,
+		DelegationMode: src.DelegationMode.String,
+		GitBranch:      src.GitBranch.String,
+		GroupID:        req.GroupID,
+	}
+	if req.Title != "" {
+		create.Title = req.Title
+	}
+	if req.ProjectID != nil {
+		create.ProjectID = *req.ProjectID
+	}
+	if req.AgentID != nil {
+		create.AgentID = *req.AgentID
+	} else {
+		if create.GroupID == "" && !src.AgentID.Valid && src.GroupID.Valid {
+			// An unclaimed group task goes back to the same group
+			create.GroupID = src.GroupID.String
+		}
+		if create.GroupID != "" {
+			create.AgentID = ""
+		}
+	}
+	if create.AgentID != "" && create.AgentID != "unassigned" {
+		if _, err := h.store.GetAgent(ctx, create.AgentID); err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, "Agent not found")
 		}
-	} else if isScheduled {
-		log.Printf("[TaskHandler] Task %s scheduled for %s — skipping immediate dispatch", task.ID, req.ScheduledAt)
+	}
+
+	// The clone is created like any new task: scripted, dispatched and
+	// triaged, with the source's phases and stories copied in its insert's
+	// transaction
+	task, err := h.createTask(ctx, create, newTask{
+		link: func(tx *store.Store, task db.Task) error {
+			return tx.CopyTaskPlan(ctx, src.ID, task.ID, req.IncludePhases, req.IncludeStories)
+		},
+		logCreated: func(task db.Task) {
+			h.logEvent(ctx, task.ID, task.AgentID.String, "task_cloned",
+				fmt.Sprintf("Task created as a clone of \"%s\"", src.Title),
+				fmt.Sprintf(`{"source_task_id":"%s","include_phases":%t,"include_stories":%t}`, src.ID, req.IncludePhases, req.IncludeStories))
+		},
+	})
+	if err != nil {
+		return err
 	}
 
 	return c.JSON(http.StatusCreated, ToTaskResponse(task))
@@ -434,8 +1603,8 @@ func (h *TaskHandler) Create(c echo.Context) error {
 func (h *TaskHandler) Update(c echo.Context) error {
 	id := c.Param("id")
 	var req UpdateTaskRequest
-	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	if err := bind(c, &req); err != nil {
+		return err
 	}
 
 	// Get existing task first
@@ -444,6 +1613,15 @@ func (h *TaskHandler) Update(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusNotFound, "Task not found")
 	}
 
+	if req.GroupID != "" {
+		if req.AgentID != nil && *req.AgentID != "" && *req.AgentID != "unassigned" {
+			return echo.NewHTTPError(http.StatusBadRequest, "Specify agent_id or group_id, not both")
+		}
+		if _, err := h.store.GetAgentGroup(c.Request().Context(), req.GroupID); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Group not found")
+		}
+	}
+
 	// Build update params, using existing values as defaults when new value is empty
 	params := db.UpdateTaskParams{
 		ID: id,
@@ -547,15 +1725,23 @@ func (h *TaskHandler) Update(c echo.Context) error {
 	}
 
 	// Handle scheduled_at update/clear
+	unscheduled := req.ClearSchedule
+	schedulePassed := false
 	if req.ClearSchedule {
 		// Clear schedule — execute immediately
 		params.ScheduledAt = sql.NullTime{Valid: false}
 	} else if req.ScheduledAt != "" {
-		// Set/update schedule
-		if t, err := time.Parse(time.RFC3339, req.ScheduledAt); err == nil {
-			params.ScheduledAt = sql.NullTime{Time: t, Valid: true}
+		// Set/update schedule; a time already past clears it instead
+		t, past, err := parseScheduledAt(c.Request().Context(), req.ScheduledAt, req.Timezone, time.Now())
+		if err != nil {
+			return err
+		}
+		if past {
+			params.ScheduledAt = sql.NullTime{Valid: false}
+			unscheduled = existing.ScheduledAt.Valid
+			schedulePassed = true
 		} else {
-			params.ScheduledAt = existing.ScheduledAt
+			params.ScheduledAt = sql.NullTime{Time: t, Valid: true}
 		}
 	} else {
 		// No change
@@ -563,17 +1749,116 @@ func (h *TaskHandler) Update(c echo.Context) error {
 	}
 	params.RetryAt = existing.RetryAt
 
+	if err := checkDelegationRequest(true, req.MaxSubtaskDepth, req.MaxConcurrentSubtasks); err != nil {
+		return err
+	}
+
+	// Naming one kind of reviewer replaces the other, and requires review
+	// unless requires_review says otherwise
+	requiresReview := existing.RequiresReview
+	reviewerAgentID, reviewerUser := existing.ReviewerAgentID.String, existing.ReviewerUser.String
+	reviewChanged := req.RequiresReview != nil || req.ReviewerAgentID != nil || req.ReviewerUser != nil
+	if req.ReviewerAgentID != nil {
+		reviewerAgentID = *req.ReviewerAgentID
+		if reviewerAgentID != "" && req.ReviewerUser == nil {
+			reviewerUser = ""
+		}
+	}
+	if req.ReviewerUser != nil {
+		reviewerUser = *req.ReviewerUser
+		if reviewerUser != "" && req.ReviewerAgentID == nil {
+			reviewerAgentID = ""
+		}
+	}
+	if req.RequiresReview != nil {
+		requiresReview = *req.RequiresReview
+	} else if (req.ReviewerAgentID != nil && *req.ReviewerAgentID != "") || (req.ReviewerUser != nil && *req.ReviewerUser != "") {
+		requiresReview = true
+	}
+	if reviewChanged {
+		if err := checkReviewer(c.Request().Context(), h.store, params.AgentID.String, reviewerAgentID, reviewerUser); err != nil {
+			return err
+		}
+	}
+	if req.Status == "done" && existing.Status.String != "done" {
+		if err := h.checkChangeRequestsResolved(c.Request().Context(), id); err != nil {
+			return err
+		}
+	}
+	// Finished work on a task requiring review goes to its reviewer first
+	if req.Status == "done" && requiresReview && existing.Status.String != "done" {
+		params.Status = sql.NullString{String: "review", Valid: true}
+	}
+
+	// Secrets are checked against the task's project, including when the
+	// task moves to another one
+	if req.Secrets != nil || params.ProjectID != existing.ProjectID {
+		names := taskSecretNames(existing)
+		if req.Secrets != nil {
+			names = *req.Secrets
+		}
+		if err := checkTaskSecrets(c.Request().Context(), h.store, params.ProjectID.String, names); err != nil {
+			return err
+		}
+	}
+
 	updated, err := h.store.UpdateTask(c.Request().Context(), params)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
+	if req.Secrets != nil {
+		if err := h.store.SetTaskSecretNames(c.Request().Context(), id, *req.Secrets); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		if refreshed, err := h.store.GetTask(c.Request().Context(), id); err == nil {
+			updated = refreshed
+		}
+	}
+	if req.MaxSubtaskDepth != nil || req.MaxConcurrentSubtasks != nil {
+		err := h.store.SetTaskDelegationLimits(c.Request().Context(), id,
+			limitParam(existing.MaxSubtaskDepth, req.MaxSubtaskDepth),
+			limitParam(existing.MaxConcurrentSubtasks, req.MaxConcurrentSubtasks))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		if refreshed, err := h.store.GetTask(c.Request().Context(), id); err == nil {
+			updated = refreshed
+		}
+	}
+	if req.Model != nil && *req.Model != existing.Model.String {
+		if err := h.store.SetTaskModel(c.Request().Context(), id, *req.Model); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		if refreshed, err := h.store.GetTask(c.Request().Context(), id); err == nil {
+			updated = refreshed
+		}
+	}
+	if reviewChanged {
+		if err := h.store.SetTaskReview(c.Request().Context(), id, requiresReview, reviewerAgentID, reviewerUser); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		if refreshed, err := h.store.GetTask(c.Request().Context(), id); err == nil {
+			updated = refreshed
+		}
+	}
+	if req.Description != "" {
+		syncTaskLinks(c.Request().Context(), h.store, id, store.LinkFromDescription, id, req.Description)
+		h.NotifyMentions(c.Request().Context(), updated, store.LinkFromDescription, id, "", req.Description, existing.Description.String)
+	}
+
+	if req.GroupID != "" {
+		return c.JSON(http.StatusOK, ToTaskResponse(h.queueForGroup(c.Request().Context(), updated, req.GroupID)))
+	}
 
 	if h.hub != nil && updated.Status.Valid {
 		h.hub.BroadcastTaskStatus(updated.ID, updated.Status.String, 0)
 	}
+	if updated.Status.String == "review" && existing.Status.String != "review" {
+		h.reviewRequested(c.Request().Context(), updated)
+	}
 
 	// If schedule was cleared and task is in backlog with an agent, notify immediately
-	if req.ClearSchedule && updated.AgentID.Valid && updated.AgentID.String != "" {
+	if unscheduled && updated.AgentID.Valid && updated.AgentID.String != "" {
 		if updated.Status.Valid && updated.Status.String == "backlog" {
 			desc := ""
 			if updated.Description.Valid {
@@ -581,7 +1866,7 @@ func (h *TaskHandler) Update(c echo.Context) error {
 			}
 			h.logEvent(c.Request().Context(), updated.ID, updated.AgentID.String,
 				"agent_notified", "Task unscheduled — notifying agent immediately", "")
-			h.notifyAssignedAgent(updated.AgentID.String, updated.ID, updated.Title, desc)
+			h.notifyAssignedAgent(c.Request().Context(), updated.AgentID.String, updated.ID, updated.Title, desc)
 		}
 	}
 
@@ -594,6 +1879,10 @@ func (h *TaskHandler) Update(c echo.Context) error {
 	if req.AgentID != nil && *req.AgentID != "" && *req.AgentID != "unassigned" {
 		newAgentID = *req.AgentID
 	}
+	if req.AgentID != nil && oldAgentID != "" && newAgentID != oldAgentID {
+		// The previous agent is no longer told about the task
+		h.cancelSends(c.Request().Context(), updated.ID, oldAgentID, taskctx.ErrTaskReassigned)
+	}
 	if newAgentID != "" && newAgentID != oldAgentID {
 		h.logEvent(c.Request().Context(), updated.ID, newAgentID, "task_assigned",
 			fmt.Sprintf("Task reassigned to agent %s", newAgentID), "")
@@ -602,80 +1891,218 @@ func (h *TaskHandler) Update(c echo.Context) error {
 			desc = updated.Description.String
 		}
 
-		if h.isAgentBusy(c.Request().Context(), newAgentID) {
-			log.Printf("[TaskHandler] Agent %s is busy, queuing reassigned task %s", newAgentID, updated.ID)
+		if reason := h.queueReason(c.Request().Context(), newAgentID); reason != "" {
+			log.Printf("[TaskHandler] Queuing reassigned task %s for agent %s (%s)", updated.ID, newAgentID, reason)
 			if err := h.store.UpdateTaskStatus(c.Request().Context(), updated.ID, "queued"); err != nil {
 				log.Printf("[TaskHandler] Error setting task %s to queued: %v", updated.ID, err)
 			} else {
 				updated.Status = sql.NullString{String: "queued", Valid: true}
 			}
 			h.logEvent(c.Request().Context(), updated.ID, newAgentID, "task_queued",
-				fmt.Sprintf("Task queued for agent %s (agent is busy)", newAgentID), "")
+				fmt.Sprintf("Task queued for agent %s (%s)", newAgentID, reason), "")
 			if h.hub != nil {
 				h.hub.BroadcastTaskStatus(updated.ID, "queued", 0)
 			}
+			h.broadcastQueue(c.Request().Context(), newAgentID)
 		} else {
 			h.logEvent(c.Request().Context(), updated.ID, newAgentID, "agent_notified",
 				fmt.Sprintf("Notifying agent %s of task assignment", newAgentID), "")
-			h.notifyAssignedAgent(newAgentID, updated.ID, updated.Title, desc)
+			h.notifyAssignedAgent(c.Request().Context(), newAgentID, updated.ID, updated.Title, desc)
 		}
 	}
 
-	return c.JSON(http.StatusOK, ToTaskResponse(updated))
+	resp := h.taskResponse(c.Request().Context(), updated)
+	resp.DispatchedImmediately = schedulePassed
+	return c.JSON(http.StatusOK, resp)
 }
 
 func (h *TaskHandler) Delete(c echo.Context) error {
 	id := c.Param("id")
+	task, _ := h.store.GetTask(c.Request().Context(), id)
 	if err := h.store.DeleteTask(c.Request().Context(), id); err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
+	h.cancelSends(c.Request().Context(), id, "", taskctx.ErrTaskDeleted)
+	if task.Status.String == "queued" {
+		h.broadcastQueue(c.Request().Context(), task.AgentID.String)
+	}
 	return c.NoContent(http.StatusNoContent)
 }
 
+// cancelSends abandons the background sends still in flight about the task,
+// to agentID or to any agent if agentID is "", recording a
+// notification_cancelled event per agent.
+func (h *TaskHandler) cancelSends(ctx context.Context, taskID, agentID string, cause error) {
+	for aID, n := range h.scopes.Cancel(taskID, agentID, cause) {
+		log.Printf("[TaskHandler] Cancelled %d notification(s) to agent %s in flight for task %s: %v", n, aID, taskID, cause)
+		// A deleted task can no longer be referred to; its ID is in the details
+		eventTaskID := taskID
+		if errors.Is(cause, taskctx.ErrTaskDeleted) {
+			eventTaskID = ""
+		}
+		h.logEvent(ctx, eventTaskID, aID, "notification_cancelled",
+			fmt.Sprintf("Cancelled %d notification(s) to agent %s in flight: %v", n, aID, cause),
+			fmt.Sprintf(`{"task_id":"%s","reason":"%v","count":%d}`, taskID, cause, n))
+	}
+}
+
+// ProjectTasksRemoved winds down the tasks of a deleted project that were
+// cancelled (archived) or deleted with it, as they were before: the sends in
+// flight about them are abandoned and their agents move on. Cancelled tasks
+// are finished the way SetTaskStatus finishes them.
+func (h *TaskHandler) ProjectTasksRemoved(ctx context.Context, tasks []db.Task, deleted bool) {
+	agents := map[string]bool{}
+	queues := map[string]bool{}
+	for _, t := range tasks {
+		switch t.Status.String {
+		case "done", "failed", "cancelled":
+			continue
+		case "queued":
+			queues[t.AgentID.String] = true
+		}
+		if deleted {
+			h.cancelSends(ctx, t.ID, "", taskctx.ErrTaskDeleted)
+			if t.AgentID.Valid && t.AgentID.String != "" {
+				agents[t.AgentID.String] = true
+			}
+			continue
+		}
+		h.cancelSends(ctx, t.ID, "", taskctx.ErrTaskStopped)
+		agentID := ""
+		if t.AgentID.Valid {
+			agentID = t.AgentID.String
+		}
+		h.logEvent(ctx, t.ID, agentID, "status_changed", "Status changed to cancelled", "")
+		if h.hub != nil {
+			h.hub.BroadcastTaskStatus(t.ID, "cancelled", progressFraction(t))
+		}
+		t.Status = sql.NullString{String: "cancelled", Valid: true}
+		h.taskFinished(ctx, t, "cancelled")
+	}
+	for agentID := range queues {
+		h.broadcastQueue(ctx, agentID)
+	}
+	for agentID := range agents {
+		go h.ProcessAgentQueue(context.Background(), agentID)
+	}
+}
+
 func (h *TaskHandler) UpdateStatus(c echo.Context) error {
-	id := c.Param("id")
 	var req struct {
-		Status string `json:"status"`
+		Status string `json:"status" validate:"required,oneof=backlog queued planning discussing executing verifying review done failed paused cancelled"`
+		Error  string `json:"error"` // why the task failed, with status failed
 	}
-	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	if err := bind(c, &req); err != nil {
+		return err
 	}
+	task, err := h.SetTaskStatus(c.Request().Context(), c.Param("id"), req.Status, req.Error)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, ToTaskResponse(task))
+}
 
-	if err := h.store.UpdateTaskStatus(c.Request().Context(), id, req.Status); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+// SetTaskStatus changes the status of a task as PUT /tasks/:id/status does,
+// for callers other than the API; errMsg is why it failed, with status
+// failed. Errors are *echo.HTTPError.
+func (h *TaskHandler) SetTaskStatus(ctx context.Context, id, status, errMsg string) (db.Task, error) {
+	existing, _ := h.store.GetTask(ctx, id)
+	// Finished work on a task requiring review goes to its reviewer first
+	if status == "done" {
+		if err := h.checkChangeRequestsResolved(ctx, id); err != nil {
+			return db.Task{}, err
+		}
+		if existing.RequiresReview {
+			status = "review"
+		}
+	}
+	// Hooks may veto completion or failure, and change the task first
+	if existing.ID != "" && existing.Status.String != status {
+		var err error
+		switch status {
+		case "done":
+			_, err = h.vetoStatus(ctx, hooks.PostComplete, existing, "")
+		case "failed":
+			_, err = h.vetoStatus(ctx, hooks.OnFail, existing, errMsg)
+		}
+		if err != nil {
+			return db.Task{}, err
+		}
+	}
+
+	if err := h.store.UpdateTaskStatus(ctx, id, status); err != nil {
+		return db.Task{}, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 	// Clear watchdog retry count on any status transition so normal progress is not treated as stuck
-	if err := h.store.ResetTaskRetryCount(c.Request().Context(), id); err != nil {
+	if err := h.store.ResetTaskRetryCount(ctx, id); err != nil {
 		log.Printf("[TaskHandler] Failed to reset retry count for task %s: %v", id, err)
 	}
 
-	task, err := h.store.GetTask(c.Request().Context(), id)
+	task, err := h.store.GetTask(ctx, id)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return db.Task{}, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	ctx := c.Request().Context()
 	agentID := ""
 	if task.AgentID.Valid {
 		agentID = task.AgentID.String
 	}
 
+	details := ""
+	if status == "failed" {
+		reason := h.recordFailure(ctx, id, errMsg)
+		task.FailureReason = sql.NullString{String: reason, Valid: true}
+		encoded, _ := json.Marshal(map[string]string{"failure_reason": reason, "error": errMsg})
+		details = string(encoded)
+	}
 	h.logEvent(ctx, id, agentID, "status_changed",
-		fmt.Sprintf("Status changed to %s", req.Status), "")
+		fmt.Sprintf("Status changed to %s", status), details)
+	if status == "review" {
+		h.reviewRequested(ctx, task)
+	}
+
+	if activeStatuses[status] && agentID != "" {
+		h.availability.TaskActivity(agentID)
+	}
 
 	if h.hub != nil {
-		h.hub.BroadcastTaskStatus(id, req.Status, 0)
+		h.hub.BroadcastTaskStatus(id, status, 0)
+	}
+	if status == "queued" || existing.Status.String == "queued" {
+		h.broadcastQueue(ctx, agentID)
 	}
 
-	if req.Status == "done" || req.Status == "failed" || req.Status == "cancelled" {
-		h.notifyParentTaskAgent(ctx, task, req.Status)
+	if status == "done" || status == "failed" || status == "cancelled" {
+		h.taskFinished(ctx, task, status)
+	}
 
-		if agentID != "" {
-			go h.ProcessAgentQueue(context.Background(), agentID)
-		}
+	return task, nil
+}
+
+// taskFinished tells the parent task's agent that task ended with status
+// and moves its agent on to the next queued task.
+func (h *TaskHandler) taskFinished(ctx context.Context, task db.Task, status string) {
+	h.notifyParentTaskAgent(ctx, task, status)
+	if h.notifier != nil {
+		h.notifier.TaskFinished(ctx, task, status)
 	}
 
-	return c.JSON(http.StatusOK, ToTaskResponse(task))
+	if status == "done" && h.githubSync != nil {
+		go func() {
+			if err := h.githubSync.CloseTaskIssue(context.Background(), task); err != nil {
+				log.Printf("[TaskHandler] Failed to close GitHub issue of task %s: %v", task.ID, err)
+			}
+		}()
+	}
+
+	if task.AgentID.Valid && task.AgentID.String != "" {
+		// Detach from the request but keep its dry-run marker for the dequeued task
+		queueCtx := context.Background()
+		if openclaw.IsDryRun(ctx) {
+			queueCtx = openclaw.WithDryRun(queueCtx)
+		}
+		go h.ProcessAgentQueue(queueCtx, task.AgentID.String)
+	}
 }
 
 // RetryTask resets retry_count, sets status to backlog, and re-notifies the assigned agent.
@@ -691,14 +2118,19 @@ func (h *TaskHandler) RetryTask(c echo.Context) error {
 
 	// Check for scheduled retry
 	var retryReq struct {
-		RetryAt string `json:"retry_at"`
+		RetryAt string `json:"retry_at" validate:"omitempty,rfc3339"`
 	}
 	if err := json.NewDecoder(c.Request().Body).Decode(&retryReq); err == nil && retryReq.RetryAt != "" {
+		if err := c.Validate(&retryReq); err != nil {
+			return err
+		}
 		if t, err := time.Parse(time.RFC3339, retryReq.RetryAt); err == nil && t.After(time.Now()) {
+			h.recordAttempt(ctx, id, store.AttemptManualRetry, task.AgentID.String,
+				fmt.Sprintf("retry of %s task scheduled for %s", task.Status.String, retryReq.RetryAt), store.AttemptScheduled, "")
 			if err := h.store.ResetTaskRetryCount(ctx, id); err != nil {
 				log.Printf("[TaskHandler] Failed to reset retry count for task %s: %v", id, err)
 			}
-			if err := h.store.SetTaskRetryAt(ctx, id, t); err != nil {
+			if err := h.store.SetTaskRetryAt(ctx, id, t.UTC()); err != nil {
 				return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 			}
 			task, _ = h.store.GetTask(ctx, id)
@@ -715,122 +2147,606 @@ func (h *TaskHandler) RetryTask(c echo.Context) error {
 		}
 	}
 
-	// Immediate retry (existing behavior)
-	if err := h.store.ResetTaskRetryCount(ctx, id); err != nil {
-		log.Printf("[TaskHandler] Failed to reset retry count for task %s: %v", id, err)
+	// Immediate retry (existing behavior)
+	h.recordAttempt(ctx, id, store.AttemptManualRetry, task.AgentID.String,
+		fmt.Sprintf("retry of %s task", task.Status.String), store.AttemptSucceeded, "")
+	if err := h.store.ResetTaskRetryCount(ctx, id); err != nil {
+		log.Printf("[TaskHandler] Failed to reset retry count for task %s: %v", id, err)
+	}
+	if err := h.store.UpdateTaskStatus(ctx, id, "backlog"); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	task, _ = h.store.GetTask(ctx, id)
+	agentID := ""
+	if task.AgentID.Valid {
+		agentID = task.AgentID.String
+	}
+
+	h.logEvent(ctx, id, agentID, "task_retry",
+		fmt.Sprintf("Task \"%s\" manually retried (status set to backlog)", task.Title), "")
+
+	if h.hub != nil {
+		h.hub.BroadcastTaskStatus(id, "backlog", 0)
+	}
+
+	if agentID != "" && agentID != "unassigned" {
+		desc := ""
+		if task.Description.Valid {
+			desc = task.Description.String
+		}
+		h.notifyAssignedAgent(ctx, agentID, id, task.Title, desc)
+	}
+
+	return c.JSON(http.StatusOK, ToTaskResponse(task))
+}
+
+type TransferTaskRequest struct {
+	AgentID string `json:"agent_id" validate:"required"`
+	Reason  string `json:"reason"`
+}
+
+// activeStatuses are the statuses in which an agent is working on a task.
+var activeStatuses = map[string]bool{"executing": true, "planning": true, "discussing": true, "verifying": true}
+
+// TransferTask moves a queued, backlog or stuck task to another agent in one
+// step. Retry state is cleared and the task is queued for the target if it is
+// busy, otherwise the target is notified immediately. A task counts as stuck
+// once the watchdog has re-notified it (retry_count > 0).
+func (h *TaskHandler) TransferTask(c echo.Context) error {
+	id := c.Param("id")
+	ctx := c.Request().Context()
+
+	var req TransferTaskRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+
+	task, err := h.store.GetTask(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+	}
+	if _, err := h.store.GetAgent(ctx, req.AgentID); err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Agent not found")
+	}
+
+	fromAgentID := ""
+	if task.AgentID.Valid {
+		fromAgentID = task.AgentID.String
+	}
+	if fromAgentID == req.AgentID {
+		return echo.NewHTTPError(http.StatusBadRequest, "Task is already assigned to this agent")
+	}
+
+	fromStatus := task.Status.String
+	stuck := activeStatuses[fromStatus] && task.RetryCount > 0
+	if fromStatus != "queued" && fromStatus != "backlog" && !stuck {
+		return apierror.New(http.StatusConflict, apierror.InvalidTransition, "Only queued, backlog or stuck tasks can be transferred")
+	}
+
+	queueReason := h.queueReason(ctx, req.AgentID)
+	newStatus := "backlog"
+	if queueReason != "" {
+		newStatus = "queued"
+	}
+
+	updated, err := h.store.TransferTask(ctx, id, fromStatus, req.AgentID, newStatus)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return apierror.New(http.StatusConflict, apierror.InvalidTransition, "Task changed state during transfer; try again")
+		}
+		log.Printf("[TaskHandler] Error transferring task %s: %v", id, err)
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	log.Printf("[TaskHandler] Transferred task %s from %q to %s (%s -> %s)", id, fromAgentID, req.AgentID, fromStatus, newStatus)
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"from_agent_id":   fromAgentID,
+		"to_agent_id":     req.AgentID,
+		"previous_status": fromStatus,
+		"status":          newStatus,
+		"retry_count":     task.RetryCount,
+		"reason":          req.Reason,
+	})
+	message := fmt.Sprintf("Task transferred to agent %s", req.AgentID)
+	if fromAgentID != "" {
+		message = fmt.Sprintf("Task transferred from agent %s to agent %s", fromAgentID, req.AgentID)
+	}
+	if req.Reason != "" {
+		message += ": " + req.Reason
+	}
+	h.logEvent(ctx, id, req.AgentID, "task_transferred", message, string(details))
+	if _, err := h.addComment(ctx, db.CreateCommentParams{
+		TaskID:  id,
+		Author:  "system",
+		Content: message,
+	}); err != nil {
+		log.Printf("[TaskHandler] Failed to add transfer comment to task %s: %v", id, err)
+	}
+
+	if h.hub != nil {
+		h.hub.BroadcastTaskStatus(id, newStatus, 0)
+	}
+	if fromStatus == "queued" {
+		h.broadcastQueue(ctx, fromAgentID)
+	}
+
+	if queueReason != "" {
+		h.logEvent(ctx, id, req.AgentID, "task_queued",
+			fmt.Sprintf("Task queued for agent %s (%s)", req.AgentID, queueReason), "")
+		h.broadcastQueue(ctx, req.AgentID)
+	} else {
+		desc := ""
+		if updated.Description.Valid {
+			desc = updated.Description.String
+		}
+		h.logEvent(ctx, id, req.AgentID, "agent_notified",
+			fmt.Sprintf("Notifying agent %s of transferred task", req.AgentID), "")
+		h.notifyAssignedAgent(ctx, req.AgentID, id, updated.Title, desc)
+	}
+
+	// A stuck task was occupying its previous agent; let their queue move on.
+	if stuck && fromAgentID != "" {
+		queueCtx := context.Background()
+		if openclaw.IsDryRun(ctx) {
+			queueCtx = openclaw.WithDryRun(queueCtx)
+		}
+		go h.ProcessAgentQueue(queueCtx, fromAgentID)
+	}
+
+	return c.JSON(http.StatusOK, ToTaskResponse(updated))
+}
+
+// notifyParentTaskAgent checks if a completed/failed task is a subtask,
+// and if so, sends a push notification to the parent task's assigned agent
+// (the orchestrator) so it can continue the delegation chain.
+// If the parent task's delegation_mode is "manual", the notification is held
+// for human approval instead of being sent immediately.
+func (h *TaskHandler) notifyParentTaskAgent(ctx context.Context, subtask db.Task, newStatus string) {
+	if h.agentSender == nil {
+		return
+	}
+	if !subtask.ParentTaskID.Valid || subtask.ParentTaskID.String == "" {
+		return
+	}
+
+	parentTaskID := subtask.ParentTaskID.String
+	parentTask, err := h.store.GetTask(ctx, parentTaskID)
+	if err != nil {
+		log.Printf("[TaskHandler] Could not fetch parent task %s for subtask completion notification: %v", parentTaskID, err)
+		return
+	}
+
+	if !parentTask.AgentID.Valid || parentTask.AgentID.String == "" {
+		log.Printf("[TaskHandler] Parent task %s has no assigned agent, skipping subtask completion notification", parentTaskID)
+		return
+	}
+
+	orchestratorID := parentTask.AgentID.String
+	subtaskAgentID := ""
+	if subtask.AgentID.Valid {
+		subtaskAgentID = subtask.AgentID.String
+	}
+
+	delegationMode := "auto"
+	if parentTask.DelegationMode.Valid && parentTask.DelegationMode.String != "" {
+		delegationMode = parentTask.DelegationMode.String
+	}
+
+	h.logEvent(ctx, parentTaskID, orchestratorID, "subtask_result_received",
+		fmt.Sprintf("Subtask \"%s\" completed with status: %s", subtask.Title, newStatus),
+		fmt.Sprintf(`{"subtask_id":"%s","status":"%s","specialist":"%s"}`, subtask.ID, newStatus, subtaskAgentID))
+
+	if delegationMode == "manual" {
+		log.Printf("[TaskHandler] Parent task %s has manual delegation — holding subtask %s for human approval", parentTaskID, subtask.ID)
+		h.logEvent(ctx, parentTaskID, orchestratorID, "pending_approval",
+			fmt.Sprintf("Subtask \"%s\" awaiting human approval before notifying orchestrator", subtask.Title),
+			fmt.Sprintf(`{"subtask_id":"%s","status":"%s"}`, subtask.ID, newStatus))
+		if h.hub != nil {
+			h.hub.BroadcastApprovalPending("delegation", subtask.ID, parentTaskID, "")
+		}
+		if h.notifier != nil {
+			h.notifier.ApprovalRequested(ctx, subtask, parentTask)
+		}
+		return
+	}
+
+	if !h.pushes(ctx, orchestratorID, notifyprefs.SubtaskCompletion) {
+		log.Printf("[TaskHandler] Orchestrator %s takes no pushed subtask completions, not notifying it of subtask %s", orchestratorID, subtask.ID)
+		return
+	}
+
+	log.Printf("[TaskHandler] Subtask %s (%s) reached status %s — notifying orchestrator %s on parent task %s",
+		subtask.ID, subtask.Title, newStatus, orchestratorID, parentTaskID)
+
+	h.logEvent(ctx, parentTaskID, orchestratorID, "orchestrator_notified",
+		fmt.Sprintf("Notifying orchestrator %s: subtask \"%s\" is %s", orchestratorID, subtask.Title, newStatus), "")
+
+	deliveryID := h.beginDelivery(ctx, notificationKindSubtaskResult, subtask.ID, newStatus, orchestratorID)
+	sender, callback := h.scoped(ctx, parentTaskID, orchestratorID, func(ctx context.Context, tID, aID, reply string, err error) {
+		h.finishDelivery(ctx, deliveryID, err)
+		if superseded(err) {
+			return
+		}
+		if err != nil {
+			log.Printf("[TaskHandler] Failed to notify orchestrator %s about subtask %s: %v", aID, subtask.ID, err)
+			h.logEvent(ctx, tID, aID, "notification_error",
+				fmt.Sprintf("Failed to notify orchestrator %s about subtask completion: %s", aID, err.Error()), "")
+			h.addComment(ctx, db.CreateCommentParams{
+				TaskID:  tID,
+				Author:  "system",
+				Content: "[Subtask Notification Error] Failed to notify orchestrator " + aID + " about subtask " + subtask.ID + " completion: " + err.Error(),
+			})
+			return
+		}
+		h.logEvent(ctx, tID, aID, "orchestrator_acknowledged",
+			fmt.Sprintf("Orchestrator %s acknowledged subtask \"%s\" completion", aID, subtask.Title), "")
+		if reply != "" {
+			h.saveResult(ctx, tID, aID, store.ResultSubtaskCompletion, reply,
+				map[string]string{"subtask_id": subtask.ID, "status": newStatus})
+		}
+	})
+	sender.NotifySubtaskCompletionAsync(
+		orchestratorID,
+		subtask.ID, subtask.Title, newStatus,
+		parentTaskID, parentTask.Title,
+		subtaskAgentID,
+		callback,
+	)
+}
+
+type SplitTaskRequest struct {
+	Subtasks []SplitSubtaskRequest `json:"subtasks"`
+}
+
+type SplitSubtaskRequest struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	AgentID     string   `json:"agent_id"` // default: the parent's agent
+	Priority    *int     `json:"priority"` // default: the parent's priority
+	StoryIDs    []string `json:"story_ids"`
+}
+
+// Split breaks a task into subtasks, moving the selected stories from the
+// task onto each. The subtasks inherit the parent's project, git branch and
+// quality checks and are dispatched like newly created subtasks.
+func (h *TaskHandler) Split(c echo.Context) error {
+	id := c.Param("id")
+	ctx := c.Request().Context()
+
+	var req SplitTaskRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+	if len(req.Subtasks) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "At least one subtask is required")
+	}
+
+	parent, err := h.store.GetTask(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+	}
+	subtasks, err := h.splitTask(ctx, parent, req.Subtasks)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"task":     ToTaskResponse(parent),
+		"subtasks": ToTaskResponses(subtasks),
+	})
+}
+
+// splitTask splits parent into subs as Split does and returns the subtasks,
+// dispatched. Invalid subtasks fail with an *echo.HTTPError.
+func (h *TaskHandler) splitTask(ctx context.Context, parent db.Task, subs []SplitSubtaskRequest) ([]db.Task, error) {
+	seen := make(map[string]bool)
+	splits := make([]store.TaskSplit, len(subs))
+	for i, sub := range subs {
+		if strings.TrimSpace(sub.Title) == "" {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Every subtask needs a title")
+		}
+		for _, storyID := range sub.StoryIDs {
+			if seen[storyID] {
+				return nil, echo.NewHTTPError(http.StatusBadRequest, "A story can only move to one subtask")
+			}
+			seen[storyID] = true
+		}
+		agentID := parent.AgentID
+		if sub.AgentID != "" {
+			if _, err := h.store.GetAgent(ctx, sub.AgentID); err != nil {
+				return nil, echo.NewHTTPError(http.StatusNotFound, "Agent not found")
+			}
+			agentID = sql.NullString{String: sub.AgentID, Valid: true}
+		}
+		priority := parent.Priority
+		if sub.Priority != nil {
+			priority = sql.NullInt64{Int64: int64(*sub.Priority), Valid: true}
+		}
+		splits[i] = store.TaskSplit{
+			Params: db.CreateTaskParams{
+				Title:          sub.Title,
+				Description:    sql.NullString{String: sub.Description, Valid: sub.Description != ""},
+				AgentID:        agentID,
+				ProjectID:      parent.ProjectID,
+				Status:         sql.NullString{String: "backlog", Valid: true},
+				Priority:       priority,
+				QualityChecks:  parent.QualityChecks,
+				DelegationMode: sql.NullString{String: "auto", Valid: true},
+				GitBranch:      parent.GitBranch,
+			},
+			StoryIDs: sub.StoryIDs,
+		}
+	}
+
+	subtasks, err := h.store.SplitTask(ctx, parent.ID, splits)
+	if errors.Is(err, store.ErrStoryNotOnTask) {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Story not found on this task")
+	}
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	ids := make([]string, len(subtasks))
+	for i, sub := range subtasks {
+		ids[i] = sub.ID
+	}
+	idsJSON, _ := json.Marshal(ids)
+	h.logEvent(ctx, parent.ID, "", "task_split",
+		fmt.Sprintf("Task \"%s\" split into %d subtask(s)", parent.Title, len(subtasks)),
+		fmt.Sprintf(`{"subtask_ids":%s,"stories_moved":%d}`, idsJSON, len(seen)))
+
+	for i, sub := range subtasks {
+		if sub.Description.Valid {
+			syncTaskLinks(ctx, h.store, sub.ID, store.LinkFromDescription, sub.ID, sub.Description.String)
+		}
+		h.logEvent(ctx, parent.ID, sub.AgentID.String, "subtask_created",
+			fmt.Sprintf("Subtask created: %s", sub.Title),
+			fmt.Sprintf(`{"subtask_id":"%s","assigned_to":"%s"}`, sub.ID, sub.AgentID.String))
+		subtasks[i] = h.dispatchNewTask(ctx, sub, "")
+	}
+
+	return subtasks, nil
+}
+
+type MergeTasksRequest struct {
+	TaskIDs []string `json:"task_ids"` // duplicates to fold into the task in the path
+}
+
+// Merge folds duplicate tasks into the task in the path. Their comments,
+// events, stories, subtasks and history move to the survivor and the
+// duplicates are deleted, leaving redirects so their IDs still resolve.
+// Duplicates an agent is actively working on cannot be merged.
+func (h *TaskHandler) Merge(c echo.Context) error {
+	id := c.Param("id")
+	ctx := c.Request().Context()
+
+	var req MergeTasksRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+	if len(req.TaskIDs) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "task_ids is required")
+	}
+
+	survivor, err := h.store.GetTask(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+	}
+
+	seen := make(map[string]bool)
+	var duplicates []db.Task
+	for _, dupID := range req.TaskIDs {
+		dupID = h.resolveTaskID(ctx, dupID)
+		if dupID == id {
+			return echo.NewHTTPError(http.StatusBadRequest, "A task cannot be merged into itself")
+		}
+		if seen[dupID] {
+			continue
+		}
+		seen[dupID] = true
+		dup, err := h.store.GetTask(ctx, dupID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+		}
+		if activeStatuses[dup.Status.String] {
+			return apierror.New(http.StatusConflict, apierror.InvalidTransition, "Cannot merge a task that is in progress")
+		}
+		duplicates = append(duplicates, dup)
+	}
+
+	mergedIDs := make([]string, len(duplicates))
+	for i, dup := range duplicates {
+		mergedIDs[i] = dup.ID
 	}
-	if err := h.store.UpdateTaskStatus(ctx, id, "backlog"); err != nil {
+	if err := h.store.MergeTasks(ctx, id, mergedIDs); err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	task, _ = h.store.GetTask(ctx, id)
-	agentID := ""
-	if task.AgentID.Valid {
-		agentID = task.AgentID.String
+	idsJSON, _ := json.Marshal(mergedIDs)
+	for _, dup := range duplicates {
+		_, _ = h.addComment(ctx, db.CreateCommentParams{
+			TaskID:  id,
+			Author:  "system",
+			Content: fmt.Sprintf("[Merge] Task \"%s\" (%s) was merged into this task.", dup.Title, dup.ID),
+		})
 	}
+	h.logEvent(ctx, id, survivor.AgentID.String, "task_merged",
+		fmt.Sprintf("Merged %d duplicate task(s) into \"%s\"", len(duplicates), survivor.Title),
+		fmt.Sprintf(`{"merged_task_ids":%s}`, idsJSON))
 
-	h.logEvent(ctx, id, agentID, "task_retry",
-		fmt.Sprintf("Task \"%s\" manually retried (status set to backlog)", task.Title), "")
+	survivor, err = h.store.GetTask(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, ToTaskResponse(survivor))
+}
 
-	if h.hub != nil {
-		h.hub.BroadcastTaskStatus(id, "backlog", 0)
+// ListRetries returns the task's retry history: watchdog retries and resets,
+// manual and scheduled retries, and every send to an agent with its outcome.
+func (h *TaskHandler) ListRetries(c echo.Context) error {
+	ctx := c.Request().Context()
+	taskID := c.Param("id")
+	if _, err := h.store.GetTask(ctx, taskID); err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+	}
+	attempts, err := h.store.ListTaskAttemptsByTask(ctx, taskID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
+	return c.JSON(http.StatusOK, ToTaskAttemptResponses(attempts))
+}
 
-	if agentID != "" && agentID != "unassigned" {
-		desc := ""
-		if task.Description.Valid {
-			desc = task.Description.String
+// Kinds of tracked notification deliveries.
+const (
+	// A subtask's outcome, sent to the orchestrator on its parent task
+	notificationKindSubtaskResult = "subtask_result"
+	// A task, sent to the agent it is assigned to
+	notificationKindTaskAssignment = "task_assignment"
+)
+
+// transitionAssigned is the transition recorded for task assignments.
+const transitionAssigned = "assigned"
+
+// beginDelivery records that agentID is being told taskID reached
+// transition, so a notification lost to a restart or a dropped callback can
+// be found and resent (see Resume and the watchdog). A new assignment
+// supersedes earlier ones of the task that were never confirmed. It returns
+// "" if the row could not be written; the notification is still sent, just
+// untracked.
+func (h *TaskHandler) beginDelivery(ctx context.Context, kind, taskID, transition, agentID string) string {
+	if kind == notificationKindTaskAssignment {
+		if err := h.store.AbandonUndeliveredNotifications(ctx, taskID, kind); err != nil {
+			log.Printf("[TaskHandler] Failed to supersede earlier assignments of task %s: %v", taskID, err)
 		}
-		h.notifyAssignedAgent(agentID, id, task.Title, desc)
 	}
+	d, err := h.store.CreateNotificationDelivery(ctx, kind, taskID, transition, agentID, h.deliverySessionKey(ctx, agentID))
+	if err != nil {
+		log.Printf("[TaskHandler] Failed to record %s delivery for task %s: %v", kind, taskID, err)
+		return ""
+	}
+	h.inflight.Store(d.ID, struct{}{})
+	return d.ID
+}
 
-	return c.JSON(http.StatusOK, ToTaskResponse(task))
+// superseded reports whether a send failed only because it was cancelled
+// with its task's scopes (see taskctx) or suppressed as a duplicate of one
+// in flight, which is not for its callback to report as a failure.
+func superseded(err error) bool {
+	return taskctx.Cancelled(err) || errors.Is(err, openclaw.ErrDuplicate)
 }
 
-// notifyParentTaskAgent checks if a completed/failed task is a subtask,
-// and if so, sends a push notification to the parent task's assigned agent
-// (the orchestrator) so it can continue the delegation chain.
-// If the parent task's delegation_mode is "manual", the notification is held
-// for human approval instead of being sent immediately.
-func (h *TaskHandler) notifyParentTaskAgent(ctx context.Context, subtask db.Task, newStatus string) {
-	if h.agentSender == nil {
+// finishDelivery records the outcome of a send started by beginDelivery.
+func (h *TaskHandler) finishDelivery(ctx context.Context, deliveryID string, sendErr error) {
+	if deliveryID == "" {
 		return
 	}
-	if !subtask.ParentTaskID.Valid || subtask.ParentTaskID.String == "" {
-		return
+	defer h.inflight.Delete(deliveryID)
+	var err error
+	if superseded(sendErr) {
+		// Not to be resent: the task was stopped, deleted or reassigned, or
+		// the same notification is already on its way
+		err = h.store.AbandonNotificationDelivery(ctx, deliveryID)
+	} else if sendErr != nil {
+		err = h.store.MarkNotificationFailed(ctx, deliveryID, sendErr.Error())
+	} else {
+		err = h.store.MarkNotificationDelivered(ctx, deliveryID)
 	}
-
-	parentTaskID := subtask.ParentTaskID.String
-	parentTask, err := h.store.GetTask(ctx, parentTaskID)
 	if err != nil {
-		log.Printf("[TaskHandler] Could not fetch parent task %s for subtask completion notification: %v", parentTaskID, err)
-		return
+		log.Printf("[TaskHandler] Failed to update notification delivery %s: %v", deliveryID, err)
 	}
+}
 
-	if !parentTask.AgentID.Valid || parentTask.AgentID.String == "" {
-		log.Printf("[TaskHandler] Parent task %s has no assigned agent, skipping subtask completion notification", parentTaskID)
-		return
+// ResendNotification re-sends a notification whose delivery was never
+// confirmed: an orchestrator notification to whoever now owns the parent
+// task, or a task assignment to the agent still assigned the task. It
+// reports false when nothing was sent: the original send is still in
+// progress, it turns out to have arrived (and is marked delivered), it is
+// quiet hours, or the notification no longer applies (in which case it is
+// abandoned).
+func (h *TaskHandler) ResendNotification(ctx context.Context, d db.NotificationDelivery) bool {
+	return h.resend(ctx, d) == resendSent
+}
+
+// resendSubtaskResult is ResendNotification for a subtask's outcome.
+func (h *TaskHandler) resendSubtaskResult(ctx context.Context, d db.NotificationDelivery) bool {
+	subtask, err := h.store.GetTask(ctx, d.TaskID)
+	if err != nil || !subtask.ParentTaskID.Valid || subtask.ParentTaskID.String == "" {
+		_ = h.store.AbandonNotificationDelivery(ctx, d.ID)
+		return false
+	}
+	parentTask, err := h.store.GetTask(ctx, subtask.ParentTaskID.String)
+	if err != nil || !parentTask.AgentID.Valid || parentTask.AgentID.String == "" {
+		_ = h.store.AbandonNotificationDelivery(ctx, d.ID)
+		return false
 	}
 
 	orchestratorID := parentTask.AgentID.String
+	if !h.pushes(ctx, orchestratorID, notifyprefs.SubtaskCompletion) {
+		_ = h.store.AbandonNotificationDelivery(ctx, d.ID)
+		return false
+	}
+	if _, quiet := h.quietHours(ctx).Defer(time.Now(), int(parentTask.Priority.Int64)); quiet {
+		return false // resent once the quiet hours end
+	}
 	subtaskAgentID := ""
 	if subtask.AgentID.Valid {
 		subtaskAgentID = subtask.AgentID.String
 	}
-
-	delegationMode := "auto"
-	if parentTask.DelegationMode.Valid && parentTask.DelegationMode.String != "" {
-		delegationMode = parentTask.DelegationMode.String
-	}
-
-	h.logEvent(ctx, parentTaskID, orchestratorID, "subtask_result_received",
-		fmt.Sprintf("Subtask \"%s\" completed with status: %s", subtask.Title, newStatus),
-		fmt.Sprintf(`{"subtask_id":"%s","status":"%s","specialist":"%s"}`, subtask.ID, newStatus, subtaskAgentID))
-
-	if delegationMode == "manual" {
-		log.Printf("[TaskHandler] Parent task %s has manual delegation — holding subtask %s for human approval", parentTaskID, subtask.ID)
-		h.logEvent(ctx, parentTaskID, orchestratorID, "pending_approval",
-			fmt.Sprintf("Subtask \"%s\" awaiting human approval before notifying orchestrator", subtask.Title),
-			fmt.Sprintf(`{"subtask_id":"%s","status":"%s"}`, subtask.ID, newStatus))
-		return
+	if err := h.store.RetryNotificationDelivery(ctx, d.ID, orchestratorID); err != nil {
+		log.Printf("[TaskHandler] Failed to mark notification delivery %s for retry: %v", d.ID, err)
+		return false
 	}
+	h.inflight.Store(d.ID, struct{}{})
 
-	log.Printf("[TaskHandler] Subtask %s (%s) reached status %s — notifying orchestrator %s on parent task %s",
-		subtask.ID, subtask.Title, newStatus, orchestratorID, parentTaskID)
-
-	h.logEvent(ctx, parentTaskID, orchestratorID, "orchestrator_notified",
-		fmt.Sprintf("Notifying orchestrator %s: subtask \"%s\" is %s", orchestratorID, subtask.Title, newStatus), "")
+	log.Printf("[TaskHandler] Resending unconfirmed notification %s to orchestrator %s (subtask %s is %s)", d.ID, orchestratorID, subtask.ID, d.Transition)
+	h.logEvent(ctx, parentTask.ID, orchestratorID, "notification_resent",
+		fmt.Sprintf("Resending notification to orchestrator %s: subtask \"%s\" is %s (attempt %d)", orchestratorID, subtask.Title, d.Transition, d.Attempts+1),
+		fmt.Sprintf(`{"delivery_id":"%s","subtask_id":"%s","status":"%s","attempt":%d}`, d.ID, subtask.ID, d.Transition, d.Attempts+1))
 
-	h.agentSender.NotifySubtaskCompletionAsync(
+	sender, callback := h.scoped(ctx, parentTask.ID, orchestratorID, func(ctx context.Context, tID, aID, reply string, err error) {
+		h.finishDelivery(ctx, d.ID, err)
+		if superseded(err) {
+			return
+		}
+		if err != nil {
+			log.Printf("[TaskHandler] Resend of notification %s to orchestrator %s failed: %v", d.ID, aID, err)
+			h.logEvent(ctx, tID, aID, "notification_error",
+				fmt.Sprintf("Failed to resend subtask notification to orchestrator %s: %s", aID, err.Error()), "")
+			return
+		}
+		h.logEvent(ctx, tID, aID, "orchestrator_acknowledged",
+			fmt.Sprintf("Orchestrator %s acknowledged subtask \"%s\" completion", aID, subtask.Title), "")
+		if reply != "" {
+			h.saveResult(ctx, tID, aID, store.ResultSubtaskCompletion, reply,
+				map[string]string{"subtask_id": subtask.ID, "status": d.Transition, "delivery_id": d.ID})
+		}
+	})
+	sender.NotifySubtaskCompletionAsync(
 		orchestratorID,
-		subtask.ID, subtask.Title, newStatus,
-		parentTaskID, parentTask.Title,
+		subtask.ID, subtask.Title, d.Transition,
+		parentTask.ID, parentTask.Title,
 		subtaskAgentID,
-		func(tID, aID, reply string, err error) {
-			bgCtx := context.Background()
-			if err != nil {
-				log.Printf("[TaskHandler] Failed to notify orchestrator %s about subtask %s: %v", aID, subtask.ID, err)
-				h.logEvent(bgCtx, tID, aID, "notification_error",
-					fmt.Sprintf("Failed to notify orchestrator %s about subtask completion: %s", aID, err.Error()), "")
-				h.store.CreateComment(bgCtx, db.CreateCommentParams{
-					TaskID:  tID,
-					Author:  "system",
-					Content: "[Subtask Notification Error] Failed to notify orchestrator " + aID + " about subtask " + subtask.ID + " completion: " + err.Error(),
-				})
-				return
-			}
-			h.logEvent(bgCtx, tID, aID, "orchestrator_acknowledged",
-				fmt.Sprintf("Orchestrator %s acknowledged subtask \"%s\" completion", aID, subtask.Title), "")
-			if reply != "" {
-				log.Printf("[TaskHandler] Orchestrator %s replied to subtask %s completion (len=%d)", aID, subtask.ID, len(reply))
-				h.store.CreateComment(bgCtx, db.CreateCommentParams{
-					TaskID:  tID,
-					Author:  aID,
-					Content: reply,
-				})
-			}
-		},
+		callback,
 	)
+	return true
+}
+
+// ListNotifications returns the notification deliveries recorded for a
+// task: its assignments and, for a subtask, its outcomes sent to the
+// orchestrator.
+func (h *TaskHandler) ListNotifications(c echo.Context) error {
+	ctx := c.Request().Context()
+	taskID := c.Param("id")
+	if _, err := h.store.GetTask(ctx, taskID); err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+	}
+	deliveries, err := h.store.ListNotificationDeliveriesByTask(ctx, taskID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, ToNotificationDeliveryResponses(deliveries))
 }
 
 // Phase handlers
@@ -846,17 +2762,12 @@ func (h *TaskHandler) ListPhases(c echo.Context) error {
 func (h *TaskHandler) CreatePhase(c echo.Context) error {
 	taskID := c.Param("id")
 	var req CreatePhaseRequest
-	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	if err := bind(c, &req); err != nil {
+		return err
 	}
 
-	// Get next sequence number
-	phases, _ := h.store.ListPhasesByTask(c.Request().Context(), taskID)
-	seq := int64(len(phases) + 1)
-
-	phase, err := h.store.CreatePhase(c.Request().Context(), db.CreatePhaseParams{
+	phase, err := h.store.AppendPhase(c.Request().Context(), db.AppendPhaseParams{
 		TaskID:      taskID,
-		Sequence:    seq,
 		Title:       req.Title,
 		Description: sql.NullString{String: req.Description, Valid: req.Description != ""},
 		Status:      sql.NullString{String: "pending", Valid: true},
@@ -880,13 +2791,10 @@ func (h *TaskHandler) ListStories(c echo.Context) error {
 func (h *TaskHandler) CreateStory(c echo.Context) error {
 	taskID := c.Param("id")
 	var req CreateStoryRequest
-	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	if err := bind(c, &req); err != nil {
+		return err
 	}
 
-	stories, _ := h.store.ListStoriesByTask(c.Request().Context(), taskID)
-	seq := int64(len(stories) + 1)
-
 	// Convert acceptance criteria to JSON
 	acJSON := "[]"
 	if len(req.AcceptanceCriteria) > 0 {
@@ -897,9 +2805,8 @@ func (h *TaskHandler) CreateStory(c echo.Context) error {
 		acJSON = string(acBytes)
 	}
 
-	story, err := h.store.CreateStory(c.Request().Context(), db.CreateStoryParams{
+	story, err := h.store.AppendStory(c.Request().Context(), db.AppendStoryParams{
 		TaskID:             taskID,
-		Sequence:           seq,
 		Title:              req.Title,
 		Description:        sql.NullString{String: req.Description, Valid: req.Description != ""},
 		Priority:           sql.NullInt64{Int64: int64(req.Priority), Valid: true},
@@ -908,6 +2815,7 @@ func (h *TaskHandler) CreateStory(c echo.Context) error {
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
+	refreshTaskProgress(c.Request().Context(), h.store, h.hub, taskID, 0)
 	return c.JSON(http.StatusCreated, story)
 }
 
@@ -940,6 +2848,7 @@ func (h *TaskHandler) StartTask(c echo.Context) error {
 
 func (h *TaskHandler) StopTask(c echo.Context) error {
 	id := c.Param("id")
+	h.cancelSends(c.Request().Context(), id, "", taskctx.ErrTaskStopped)
 	if h.orchestrator == nil {
 		return echo.NewHTTPError(http.StatusServiceUnavailable, "Orchestrator not available")
 	}
@@ -953,8 +2862,15 @@ func (h *TaskHandler) StopTask(c echo.Context) error {
 // Used when the parent task has delegation_mode = "manual".
 func (h *TaskHandler) ApproveDelegation(c echo.Context) error {
 	subtaskID := c.Param("id")
-	ctx := c.Request().Context()
+	if err := h.ApproveSubtask(c.Request().Context(), subtaskID); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "approved", "subtask_id": subtaskID})
+}
 
+// ApproveSubtask approves a completed subtask as POST /tasks/:id/approve
+// does, for callers other than the API. Errors are *echo.HTTPError.
+func (h *TaskHandler) ApproveSubtask(ctx context.Context, subtaskID string) error {
 	subtask, err := h.store.GetTask(ctx, subtaskID)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusNotFound, "Subtask not found")
@@ -994,35 +2910,44 @@ func (h *TaskHandler) ApproveDelegation(c echo.Context) error {
 		fmt.Sprintf("Human approved subtask \"%s\" — notifying orchestrator", subtask.Title),
 		fmt.Sprintf(`{"subtask_id":"%s","status":"%s"}`, subtaskID, status))
 
-	h.agentSender.NotifySubtaskCompletionAsync(
+	if !h.pushes(ctx, orchestratorID, notifyprefs.SubtaskCompletion) {
+		log.Printf("[TaskHandler] Orchestrator %s takes no pushed subtask completions, not notifying it of approved subtask %s", orchestratorID, subtaskID)
+		return nil
+	}
+
+	deliveryID := h.beginDelivery(ctx, notificationKindSubtaskResult, subtask.ID, status, orchestratorID)
+	sender, callback := h.scoped(ctx, parentTaskID, orchestratorID, func(ctx context.Context, tID, aID, reply string, sendErr error) {
+		h.finishDelivery(ctx, deliveryID, sendErr)
+		if superseded(sendErr) {
+			return
+		}
+		if sendErr != nil {
+			log.Printf("[TaskHandler] Failed to notify orchestrator %s after approval: %v", aID, sendErr)
+			h.logEvent(ctx, tID, aID, "notification_error",
+				fmt.Sprintf("Failed to notify orchestrator after approval: %s", sendErr.Error()), "")
+			return
+		}
+		h.logEvent(ctx, tID, aID, "orchestrator_acknowledged",
+			fmt.Sprintf("Orchestrator %s acknowledged approved subtask \"%s\"", aID, subtask.Title), "")
+		if reply != "" {
+			h.saveResult(ctx, tID, aID, store.ResultSubtaskCompletion, reply,
+				map[string]string{"subtask_id": subtask.ID, "status": status})
+		}
+	})
+	sender.NotifySubtaskCompletionAsync(
 		orchestratorID,
 		subtask.ID, subtask.Title, status,
 		parentTaskID, parentTask.Title,
 		subtaskAgentID,
-		func(tID, aID, reply string, sendErr error) {
-			bgCtx := context.Background()
-			if sendErr != nil {
-				log.Printf("[TaskHandler] Failed to notify orchestrator %s after approval: %v", aID, sendErr)
-				h.logEvent(bgCtx, tID, aID, "notification_error",
-					fmt.Sprintf("Failed to notify orchestrator after approval: %s", sendErr.Error()), "")
-				return
-			}
-			h.logEvent(bgCtx, tID, aID, "orchestrator_acknowledged",
-				fmt.Sprintf("Orchestrator %s acknowledged approved subtask \"%s\"", aID, subtask.Title), "")
-			if reply != "" {
-				h.store.CreateComment(bgCtx, db.CreateCommentParams{
-					TaskID:  tID,
-					Author:  aID,
-					Content: reply,
-				})
-			}
-		},
+		callback,
 	)
 
-	return c.JSON(http.StatusOK, map[string]string{"status": "approved", "subtask_id": subtaskID})
+	return nil
 }
 
-// GetAgentQueue returns all queued tasks for a specific agent, ordered by priority then FIFO.
+// GetAgentQueue returns all queued tasks for a specific agent: manually positioned
+// tasks first, then by priority then FIFO. Unclaimed tasks in the shared queues
+// of the agent's groups are listed separately under group_tasks.
 // Agents call this on heartbeat to check for pending work.
 func (h *TaskHandler) GetAgentQueue(c echo.Context) error {
 	agentID := c.Param("id")
@@ -1036,16 +2961,132 @@ func (h *TaskHandler) GetAgentQueue(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	log.Printf("[TaskHandler] Agent %s has %d queued tasks", agentID, len(queued))
+	groupQueued, err := h.store.ListQueuedGroupTasksForAgent(ctx, agentID)
+	if err != nil {
+		log.Printf("[TaskHandler] Error fetching group queues for agent %s: %v", agentID, err)
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	log.Printf("[TaskHandler] Agent %s has %d queued tasks (%d in group queues)", agentID, len(queued), len(groupQueued))
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"agent_id":    agentID,
-		"queue_depth": len(queued),
-		"tasks":       ToTaskResponses(queued),
+		"agent_id":          agentID,
+		"queue_depth":       len(queued),
+		"tasks":             ToTaskResponses(queued),
+		"group_queue_depth": len(groupQueued),
+		"group_tasks":       ToTaskResponses(groupQueued),
 	})
 }
 
+type ReorderQueueRequest struct {
+	TaskIDs []string `json:"task_ids"`
+}
+
+// ReorderAgentQueue sets an explicit order for an agent's queue. The listed
+// tasks move to the front in the given order; queued tasks not listed keep
+// their relative order behind them.
+func (h *TaskHandler) ReorderAgentQueue(c echo.Context) error {
+	agentID := c.Param("id")
+	ctx := c.Request().Context()
+
+	var req ReorderQueueRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+	if len(req.TaskIDs) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "task_ids is required")
+	}
+
+	queued, err := h.store.ListQueuedTasksByAgent(ctx, agentID)
+	if err != nil {
+		log.Printf("[TaskHandler] Error fetching queue for agent %s: %v", agentID, err)
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	inQueue := make(map[string]bool, len(queued))
+	for _, t := range queued {
+		inQueue[t.ID] = true
+	}
+	listed := make(map[string]bool, len(req.TaskIDs))
+	for i, id := range req.TaskIDs {
+		id = h.resolveTaskID(ctx, id)
+		req.TaskIDs[i] = id
+		if !inQueue[id] || listed[id] {
+			return echo.NewHTTPError(http.StatusBadRequest, "task_ids must be distinct queued tasks of this agent")
+		}
+		listed[id] = true
+	}
+
+	order := append([]string{}, req.TaskIDs...)
+	for _, t := range queued {
+		if !listed[t.ID] {
+			order = append(order, t.ID)
+		}
+	}
+
+	if err := h.store.SetTaskQueuePositions(ctx, order); err != nil {
+		log.Printf("[TaskHandler] Error reordering queue for agent %s: %v", agentID, err)
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	orderJSON, _ := json.Marshal(order)
+	h.logEvent(ctx, "", agentID, "queue_reordered",
+		fmt.Sprintf("Queue for agent %s reordered (%d tasks)", agentID, len(order)),
+		fmt.Sprintf(`{"order":%s}`, orderJSON))
+
+	h.broadcastQueue(ctx, agentID)
+
+	log.Printf("[TaskHandler] Reordered queue for agent %s: %v", agentID, order)
+	return h.GetAgentQueue(c)
+}
+
+// BumpTask moves a queued task to the front of its agent's queue.
+func (h *TaskHandler) BumpTask(c echo.Context) error {
+	id := c.Param("id")
+	ctx := c.Request().Context()
+
+	task, err := h.store.GetTask(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+	}
+	if task.Status.String != "queued" || !task.AgentID.Valid || task.AgentID.String == "" {
+		return apierror.New(http.StatusBadRequest, apierror.InvalidTransition, "Only queued tasks with an assigned agent can be bumped")
+	}
+	agentID := task.AgentID.String
+
+	queued, err := h.store.ListQueuedTasksByAgent(ctx, agentID)
+	if err != nil {
+		log.Printf("[TaskHandler] Error fetching queue for agent %s: %v", agentID, err)
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	order := []string{id}
+	previous := 0
+	for i, t := range queued {
+		if t.ID == id {
+			previous = i + 1
+			continue
+		}
+		order = append(order, t.ID)
+	}
+
+	if err := h.store.SetTaskQueuePositions(ctx, order); err != nil {
+		log.Printf("[TaskHandler] Error bumping task %s: %v", id, err)
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	h.logEvent(ctx, id, agentID, "task_bumped",
+		fmt.Sprintf("Task moved to the front of agent %s's queue (was position %d of %d)", agentID, previous, len(queued)),
+		fmt.Sprintf(`{"previous_position":%d,"queue_depth":%d}`, previous, len(queued)))
+	h.broadcastQueue(ctx, agentID)
+
+	c.SetParamNames("id")
+	c.SetParamValues(agentID)
+	return h.GetAgentQueue(c)
+}
+
 // DequeueNextTask picks the next task from an agent's queue, transitions it
-// from "queued" to "backlog", notifies the agent, and returns the task.
+// from "queued" to "backlog", notifies the agent, and returns the task. When
+// the agent's own queue is empty it claims the next task from its groups.
 // Agents call this to self-serve pickup during heartbeat.
 func (h *TaskHandler) DequeueNextTask(c echo.Context) error {
 	agentID := c.Param("id")
@@ -1055,8 +3096,34 @@ func (h *TaskHandler) DequeueNextTask(c echo.Context) error {
 
 	if h.isAgentBusy(ctx, agentID) {
 		log.Printf("[TaskHandler] Agent %s is still busy, cannot dequeue", agentID)
-		return c.JSON(http.StatusConflict, map[string]string{
-			"error": "Agent is currently busy with active tasks",
+		return apierror.New(http.StatusConflict, apierror.AgentBusy, "Agent is currently busy with active tasks")
+	}
+
+	if until, deferred := h.agentWorkingHours(ctx, agentID).Defer(time.Now()); deferred {
+		log.Printf("[TaskHandler] Agent %s is outside working hours until %s, not dequeuing", agentID, until.Format(time.RFC3339))
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"agent_id":       agentID,
+			"task":           nil,
+			"message":        "Outside working hours",
+			"deferred_until": until.UTC().Format(time.RFC3339),
+		})
+	}
+	if until, limited := h.rateLimits.BlockedUntil(agentID); limited {
+		log.Printf("[TaskHandler] Agent %s is rate limited until %s, not dequeuing", agentID, until.Format(time.RFC3339))
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"agent_id":           agentID,
+			"task":               nil,
+			"message":            "Rate limited",
+			"rate_limited_until": until.UTC().Format(time.RFC3339),
+		})
+	}
+	if models, down := h.ModelUnavailable(ctx, agentID); down {
+		log.Printf("[TaskHandler] Agent %s has no available model, not dequeuing", agentID)
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"agent_id":           agentID,
+			"task":               nil,
+			"message":            "Model unavailable",
+			"unavailable_models": models,
 		})
 	}
 
@@ -1067,6 +3134,30 @@ func (h *TaskHandler) DequeueNextTask(c echo.Context) error {
 	}
 
 	if len(queued) == 0 {
+		if task, ok := h.claimNextGroupTask(ctx, agentID, "heartbeat"); ok {
+			desc := ""
+			if task.Description.Valid {
+				desc = task.Description.String
+			}
+			if h.pushes(ctx, agentID, notifyprefs.TaskAssignment) {
+				h.notifyAssignedAgent(ctx, agentID, task.ID, task.Title, desc)
+			} else {
+				var vetoed bool
+				if task, vetoed = h.vetoDispatch(ctx, agentID, task); vetoed {
+					return c.JSON(http.StatusOK, map[string]interface{}{
+						"agent_id": agentID,
+						"task":     nil,
+						"message":  "Vetoed by hook",
+					})
+				}
+			}
+			return c.JSON(http.StatusOK, map[string]interface{}{
+				"agent_id":        agentID,
+				"task":            ToTaskResponse(task),
+				"remaining_queue": 0,
+			})
+		}
+
 		log.Printf("[TaskHandler] No queued tasks for agent %s", agentID)
 		return c.JSON(http.StatusOK, map[string]interface{}{
 			"agent_id": agentID,
@@ -1076,6 +3167,16 @@ func (h *TaskHandler) DequeueNextTask(c echo.Context) error {
 	}
 
 	next := queued[0]
+	if h.deferIfQuiet(ctx, agentID, next) {
+		until, _ := h.quietHours(ctx).Quiet(time.Now())
+		log.Printf("[TaskHandler] Quiet hours until %s, not dequeuing for agent %s", until.Format(time.RFC3339), agentID)
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"agent_id":       agentID,
+			"task":           nil,
+			"message":        "Quiet hours",
+			"deferred_until": until.UTC().Format(time.RFC3339),
+		})
+	}
 	log.Printf("[TaskHandler] Dequeuing task %s (%s) for agent %s", next.ID, next.Title, agentID)
 
 	if err := h.store.UpdateTaskStatus(ctx, next.ID, "backlog"); err != nil {
@@ -1090,12 +3191,23 @@ func (h *TaskHandler) DequeueNextTask(c echo.Context) error {
 	if h.hub != nil {
 		h.hub.BroadcastTaskStatus(next.ID, "backlog", 0)
 	}
+	h.broadcastQueue(ctx, agentID)
 
 	desc := ""
 	if next.Description.Valid {
 		desc = next.Description.String
 	}
-	h.notifyAssignedAgent(agentID, next.ID, next.Title, desc)
+	// Agents that take no pushed assignments get the task in the response
+	if h.pushes(ctx, agentID, notifyprefs.TaskAssignment) {
+		h.notifyAssignedAgent(ctx, agentID, next.ID, next.Title, desc)
+	} else if _, vetoed := h.vetoDispatch(ctx, agentID, next); vetoed {
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"agent_id":        agentID,
+			"task":            nil,
+			"message":         "Vetoed by hook",
+			"remaining_queue": len(queued) - 1,
+		})
+	}
 
 	updatedTask, err := h.store.GetTask(ctx, next.ID)
 	if err != nil {
@@ -1122,11 +3234,8 @@ func (h *TaskHandler) RequestChanges(c echo.Context) error {
 	var req struct {
 		Comment string `json:"comment" validate:"required"`
 	}
-	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
-	}
-	if req.Comment == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "Comment is required")
+	if err := bind(c, &req); err != nil {
+		return err
 	}
 
 	subtask, err := h.store.GetTask(ctx, subtaskID)
@@ -1138,59 +3247,78 @@ func (h *TaskHandler) RequestChanges(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Subtask has no assigned agent")
 	}
 
-	agentID := subtask.AgentID.String
-	parentTaskID := ""
-	if subtask.ParentTaskID.Valid {
-		parentTaskID = subtask.ParentTaskID.String
+	if err := h.requestChanges(ctx, subtask, "human", req.Comment); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	h.store.CreateComment(ctx, db.CreateCommentParams{
-		TaskID:  subtaskID,
-		Author:  "human",
-		Content: req.Comment,
+	return c.JSON(http.StatusOK, map[string]string{"status": "changes_requested", "subtask_id": subtaskID})
+}
+
+// requestChanges opens author's change request on task, also kept as a
+// comment, puts the task back to "executing" and notifies its agent with the
+// feedback.
+func (h *TaskHandler) requestChanges(ctx context.Context, task db.Task, author, comment string) error {
+	agentID := task.AgentID.String
+
+	cr, err := h.store.CreateChangeRequest(ctx, db.CreateChangeRequestParams{
+		TaskID:    task.ID,
+		Requester: author,
+		Content:   comment,
+	})
+	if err != nil {
+		return err
+	}
+	h.addComment(ctx, db.CreateCommentParams{
+		TaskID:  task.ID,
+		Author:  author,
+		Content: comment,
 	})
 
-	if err := h.store.UpdateTaskStatus(ctx, subtaskID, "executing"); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	if err := h.store.UpdateTaskStatus(ctx, task.ID, "executing"); err != nil {
+		return err
+	}
+	if h.hub != nil {
+		h.hub.BroadcastTaskStatus(task.ID, "executing", 0)
 	}
 
-	h.logEvent(ctx, subtaskID, agentID, "changes_requested",
-		fmt.Sprintf("Human requested changes: %s", req.Comment), "")
+	message := fmt.Sprintf("Human requested changes: %s", comment)
+	if author != "human" {
+		message = fmt.Sprintf("%s requested changes: %s", author, comment)
+	}
+	h.logEvent(ctx, task.ID, agentID, "changes_requested", message,
+		fmt.Sprintf(`{"change_request_id":"%s"}`, cr.ID))
 
-	if parentTaskID != "" {
-		h.logEvent(ctx, parentTaskID, "", "changes_requested",
-			fmt.Sprintf("Changes requested on subtask \"%s\"", subtask.Title),
-			fmt.Sprintf(`{"subtask_id":"%s"}`, subtaskID))
+	if task.ParentTaskID.Valid && task.ParentTaskID.String != "" {
+		h.logEvent(ctx, task.ParentTaskID.String, "", "changes_requested",
+			fmt.Sprintf("Changes requested on subtask \"%s\"", task.Title),
+			fmt.Sprintf(`{"subtask_id":"%s"}`, task.ID))
 	}
 
-	if h.agentSender != nil {
+	if h.agentSender != nil && h.pushes(ctx, agentID, notifyprefs.TaskAssignment) {
 		changeMsg := fmt.Sprintf(
 			"Changes have been requested on your task.\n\n"+
 				"## Change Request\n"+
 				"- **Task ID:** %s\n"+
 				"- **Title:** %s\n"+
+				"- **Change Request ID:** %s\n"+
 				"- **Feedback:** %s\n\n"+
-				"Please review the feedback, make the requested changes, and update the task status to `done` when complete.",
-			subtaskID, subtask.Title, req.Comment,
+				"Please review the feedback and make the requested changes. Then resolve the change request "+
+				"(`POST /tasks/%s/change-requests/%s/resolve` with `{\"commit\": \"<sha>\"}`) "+
+				"and update the task status to `done`; it can't be done while change requests are open.",
+			task.ID, task.Title, cr.ID, comment, task.ID, cr.ID,
 		)
 
-		h.agentSender.NotifyAgentAsync(agentID, subtaskID, subtask.Title, changeMsg,
-			func(tID, aID, reply string, sendErr error) {
-				bgCtx := context.Background()
-				if sendErr != nil {
-					log.Printf("[TaskHandler] Failed to notify agent %s about change request: %v", aID, sendErr)
-					return
-				}
-				if reply != "" {
-					h.store.CreateComment(bgCtx, db.CreateCommentParams{
-						TaskID:  tID,
-						Author:  aID,
-						Content: reply,
-					})
-				}
-			},
-		)
+		sender, callback := h.scoped(ctx, task.ID, agentID, func(ctx context.Context, tID, aID, reply string, sendErr error) {
+			if sendErr != nil {
+				log.Printf("[TaskHandler] Failed to notify agent %s about change request: %v", aID, sendErr)
+				return
+			}
+			if reply != "" {
+				h.saveResult(ctx, tID, aID, store.ResultChangeRequest, reply,
+					map[string]string{"change_request_id": cr.ID})
+			}
+		})
+		sender.NotifyAgentAsync(agentID, task.ID, task.Title, changeMsg, callback)
 	}
-
-	return c.JSON(http.StatusOK, map[string]string{"status": "changes_requested", "subtask_id": subtaskID})
+	return nil
 }
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
+)
+
+// TemplateHandler lists and previews the agent notification templates.
+type TemplateHandler struct {
+	templates *openclaw.Templates
+}
+
+func NewTemplateHandler(templates *openclaw.Templates) *TemplateHandler {
+	return &TemplateHandler{templates: templates}
+}
+
+// PreviewTemplateRequest renders a template without sending anything.
+// Content, if set, is rendered instead of the active template so edits can
+// be checked before saving them to the templates directory. Data overrides
+// individual variables of the built-in sample data.
+type PreviewTemplateRequest struct {
+	Locale  string                 `json:"locale,omitempty"`
+	Content string                 `json:"content,omitempty"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+}
+
+// List - GET /api/v1/templates?locale=es
+func (h *TemplateHandler) List(c echo.Context) error {
+	locale := c.QueryParam("locale")
+	names := h.templates.Names()
+	result := make([]openclaw.TemplateInfo, 0, len(names))
+	for _, name := range names {
+		info, err := h.templates.Info(name, locale)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		result = append(result, info)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": result,
+		"meta": map[string]interface{}{
+			"total": len(result),
+		},
+	})
+}
+
+// Get - GET /api/v1/templates/:name?locale=es
+func (h *TemplateHandler) Get(c echo.Context) error {
+	info, err := h.templates.Info(c.Param("name"), c.QueryParam("locale"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": info,
+	})
+}
+
+// Preview - POST /api/v1/templates/:name/preview
+func (h *TemplateHandler) Preview(c echo.Context) error {
+	name := c.Param("name")
+
+	var req PreviewTemplateRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+
+	info, err := h.templates.Info(name, req.Locale)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+
+	data, err := previewData(h.templates.SampleData(name), req.Data)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	content := info.Content
+	source := info.Source
+	if req.Content != "" {
+		content = req.Content
+		source = "request"
+	}
+
+	rendered, err := openclaw.RenderTemplate(name, content, data)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": map[string]interface{}{
+			"name":     name,
+			"locale":   info.Locale,
+			"source":   source,
+			"rendered": rendered,
+		},
+	})
+}
+
+// previewData merges caller-supplied variables over the sample data.
+func previewData(sample interface{}, overrides map[string]interface{}) (map[string]interface{}, error) {
+	data := map[string]interface{}{}
+	raw, err := json.Marshal(sample)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+	for k, v := range overrides {
+		data[k] = v
+	}
+	return data, nil
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+	ws "github.com/abelkuruvilla/claw-agent-mission-control/internal/websocket"
+)
+
+// TrashHandler lists and restores deleted tasks, comments and projects while
+// they are kept in the trash (TRASH_RETENTION).
+type TrashHandler struct {
+	store TrashHandlerStore
+	hub   *ws.Hub
+}
+
+func NewTrashHandler(s TrashHandlerStore, hub *ws.Hub) *TrashHandler {
+	return &TrashHandler{
+		store: s,
+		hub:   hub,
+	}
+}
+
+// TrashItemResponse is a deleted task, comment or project.
+type TrashItemResponse struct {
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`    // task, comment or project
+	ItemID    string `json:"item_id"` // ID of the task, comment or project, also once restored
+	Title     string `json:"title"`   // its title, name or the start of the comment
+	DeletedAt string `json:"deleted_at"`
+	ExpiresAt string `json:"expires_at"` // when it is gone for good
+}
+
+// TrashListResponse is the trash.
+type TrashListResponse struct {
+	Items          []TrashItemResponse `json:"items"`
+	RetentionHours float64             `json:"retention_hours"` // 0 = there is no trash, deletions are final
+}
+
+func toTrashItemResponse(id, kind, itemID, title string, deletedAt sql.NullTime, expiresAt time.Time) TrashItemResponse {
+	return TrashItemResponse{
+		ID:        id,
+		Kind:      kind,
+		ItemID:    itemID,
+		Title:     title,
+		DeletedAt: nullTimeToString(deletedAt),
+		ExpiresAt: expiresAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// List - GET /api/v1/trash?kind=task|comment|project
+func (h *TrashHandler) List(c echo.Context) error {
+	kind := c.QueryParam("kind")
+	switch kind {
+	case "", store.TrashTask, store.TrashComment, store.TrashProject:
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, "kind must be task, comment or project")
+	}
+	rows, err := h.store.ListTrashItems(c.Request().Context(), kind)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	resp := TrashListResponse{
+		Items:          make([]TrashItemResponse, len(rows)),
+		RetentionHours: h.store.TrashRetention().Hours(),
+	}
+	for i, r := range rows {
+		resp.Items[i] = toTrashItemResponse(r.ID, r.Kind, r.ItemID, r.Title, r.DeletedAt, r.ExpiresAt)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Restore - POST /api/v1/trash/:id/restore
+// Puts a deleted item back with what was deleted along with it: a task with
+// its stories, comments and other records, a project with its secrets,
+// experiments and the tasks deleted with it. References to it are restored
+// too, e.g. the tasks kept when their project was deleted get it back.
+func (h *TrashHandler) Restore(c echo.Context) error {
+	ctx := c.Request().Context()
+	item, err := h.store.RestoreTrashItem(ctx, c.Param("id"))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return echo.NewHTTPError(http.StatusNotFound, "Trash item not found")
+	case errors.Is(err, db.ErrSnapshotConflict):
+		return echo.NewHTTPError(http.StatusConflict, "An item with the same ID exists again")
+	case errors.Is(err, db.ErrSnapshotParentGone):
+		return echo.NewHTTPError(http.StatusConflict, "What the item belongs to has been deleted since")
+	case err != nil:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	switch item.Kind {
+	case store.TrashTask:
+		if task, err := h.store.GetTask(ctx, item.ItemID); err == nil && h.hub != nil {
+			h.hub.BroadcastTaskStatus(task.ID, task.Status.String, progressFraction(task))
+		}
+	case store.TrashComment:
+		// Its links went with it, outside the trash
+		if comment, err := h.store.GetComment(ctx, item.ItemID); err == nil {
+			syncTaskLinks(ctx, h.store, comment.TaskID, store.LinkFromComment, comment.ID, comment.Content)
+		}
+	}
+	log.Printf("[TrashHandler] Restored %s %s from the trash", item.Kind, item.ItemID)
+	return c.JSON(http.StatusOK, toTrashItemResponse(item.ID, item.Kind, item.ItemID, item.Title, item.DeletedAt, item.ExpiresAt))
+}
+
+// Delete - DELETE /api/v1/trash/:id
+// Deletes an item in the trash for good.
+func (h *TrashHandler) Delete(c echo.Context) error {
+	ctx := c.Request().Context()
+	if _, err := h.store.GetTrashItem(ctx, c.Param("id")); err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Trash item not found")
+	}
+	if err := h.store.DeleteTrashItem(ctx, c.Param("id")); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,407 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/api/apierror"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/taskctx"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/triage"
+)
+
+// SetTriage sets the agent new unassigned tasks are sent to for triage ("" to
+// triage none), how long it may take to answer, and whether its suggestions
+// are applied as they arrive or staged until someone accepts them.
+func (h *TaskHandler) SetTriage(agentID string, timeout time.Duration, autoApply bool) {
+	h.triageAgent, h.triageTimeout, h.triageAuto = agentID, timeout, autoApply
+}
+
+// TriageResponse is what the triage agent suggested for a task and what
+// became of it.
+type TriageResponse struct {
+	TaskID     string             `json:"task_id"`
+	AgentID    string             `json:"agent_id"` // the triage agent
+	Status     string             `json:"status"`   // pending | staged | applied | dismissed | failed
+	Suggestion *triage.Suggestion `json:"suggestion,omitempty"`
+	Error      *string            `json:"error,omitempty"`
+	DecidedBy  *string            `json:"decided_by,omitempty"`
+	CreatedAt  string             `json:"created_at"`
+	UpdatedAt  string             `json:"updated_at"`
+	DecidedAt  *string            `json:"decided_at,omitempty"`
+	Task       *TaskResponse      `json:"task,omitempty"` // in lists
+}
+
+func toTriageResponse(t db.TaskTriage) TriageResponse {
+	resp := TriageResponse{
+		TaskID:    t.TaskID,
+		AgentID:   t.AgentID,
+		Status:    t.Status,
+		Error:     strPtr(t.Error.String, t.Error.Valid),
+		DecidedBy: strPtr(t.DecidedBy.String, t.DecidedBy.Valid),
+		CreatedAt: nullTimeToString(t.CreatedAt),
+		UpdatedAt: nullTimeToString(t.UpdatedAt),
+		DecidedAt: strPtr(nullTimeToString(t.DecidedAt), t.DecidedAt.Valid),
+	}
+	if t.Suggestion.Valid {
+		var s triage.Suggestion
+		if err := json.Unmarshal([]byte(t.Suggestion.String), &s); err == nil {
+			resp.Suggestion = &s
+		}
+	}
+	return resp
+}
+
+// needsTriage reports whether a new task goes to the triage agent: one in
+// the backlog that isn't assigned, queued for a group, scheduled or a
+// subtask.
+func (h *TaskHandler) needsTriage(task db.Task) bool {
+	return h.triageAgent != "" && h.agentSender != nil &&
+		task.Status.String == "backlog" && !taskAssigned(task) &&
+		!task.ParentTaskID.Valid && !task.ScheduledAt.Valid
+}
+
+// taskAssigned reports whether task has an agent or is on a group's queue.
+func taskAssigned(task db.Task) bool {
+	return (task.AgentID.Valid && task.AgentID.String != "" && task.AgentID.String != "unassigned") ||
+		(task.GroupID.Valid && task.GroupID.String != "")
+}
+
+// requestTriage asks the triage agent about task in the background; its
+// answer is applied or staged as it arrives.
+func (h *TaskHandler) requestTriage(ctx context.Context, task db.Task) (db.TaskTriage, error) {
+	record, err := h.store.StartTaskTriage(ctx, task.ID, h.triageAgent)
+	if err != nil {
+		return record, err
+	}
+	var agents []triage.Agent
+	if list, err := h.store.ListAgents(ctx); err == nil {
+		for _, a := range list {
+			if a.ID != h.triageAgent {
+				agents = append(agents, triage.Agent{ID: a.ID, Name: a.Name, Description: a.Description.String})
+			}
+		}
+	}
+	h.logEvent(ctx, task.ID, h.triageAgent, "triage_requested",
+		fmt.Sprintf("Asking agent %s to triage the task", h.triageAgent), "")
+
+	prompt := triage.Prompt(triage.Task{
+		ID:          task.ID,
+		Title:       task.Title,
+		Description: task.Description.String,
+		Priority:    int(task.Priority.Int64),
+		ProjectID:   task.ProjectID.String,
+	}, agents)
+	// The run outlives the request, as a send does (see scoped)
+	agentID, timeout := h.triageAgent, h.triageTimeout
+	runCtx, release := h.scopes.Begin(ctx, task.ID, agentID, timeout)
+	sender := h.agentSender.For(runCtx)
+	go func() {
+		defer release()
+		reply, err := sender.RunCommand(runCtx, agentID, prompt, timeout, nil)
+		cbCtx, cancel := taskctx.Detach(runCtx)
+		defer cancel()
+		h.triageAnswered(cbCtx, task.ID, agentID, agents, reply, err)
+	}()
+	return record, nil
+}
+
+// triageAnswered records the triage agent's reply on taskID, applying it if
+// suggestions are applied on arrival.
+func (h *TaskHandler) triageAnswered(ctx context.Context, taskID, agentID string, agents []triage.Agent, reply string, sendErr error) {
+	suggestion, err := triage.Parse(reply, agents)
+	if sendErr != nil {
+		err = sendErr
+	}
+	if err != nil {
+		log.Printf("[TaskHandler] Triage of task %s failed: %v", taskID, err)
+		if err := h.store.SetTaskTriageResult(ctx, taskID, store.TriageFailed, "", err.Error()); err != nil {
+			log.Printf("[TaskHandler] Failed to record triage of task %s: %v", taskID, err)
+		}
+		h.logEvent(ctx, taskID, agentID, "triage_failed",
+			fmt.Sprintf("Triage by agent %s failed: %s", agentID, err.Error()), "")
+		return
+	}
+	encoded, _ := json.Marshal(suggestion)
+	if err := h.store.SetTaskTriageResult(ctx, taskID, store.TriageStaged, string(encoded), ""); err != nil {
+		log.Printf("[TaskHandler] Failed to record triage of task %s: %v", taskID, err)
+		return
+	}
+
+	if h.triageAuto {
+		if _, _, err := h.applyTriage(ctx, taskID, suggestion, "auto"); err != nil {
+			log.Printf("[TaskHandler] Failed to apply triage of task %s: %v", taskID, err)
+		}
+		return
+	}
+	h.logEvent(ctx, taskID, agentID, "triage_staged",
+		fmt.Sprintf("Agent %s suggested %s", agentID, describeSuggestion(suggestion)), string(encoded))
+	if h.hub != nil {
+		h.hub.BroadcastApprovalPending("triage", taskID, "", "")
+	}
+}
+
+// applyTriage applies a suggestion to taskID on behalf of by: its priority,
+// its labels, its agent if the task is still unassigned in the backlog, and
+// its split. It returns the task and the subtasks it was split into.
+func (h *TaskHandler) applyTriage(ctx context.Context, taskID string, s triage.Suggestion, by string) (db.Task, []db.Task, error) {
+	task, err := h.store.GetTask(ctx, taskID)
+	if err != nil {
+		return task, nil, echo.NewHTTPError(http.StatusNotFound, "Task not found")
+	}
+	assign := s.AgentID != "" && !taskAssigned(task) && task.Status.String == "backlog"
+	if assign {
+		if _, err := h.store.GetAgent(ctx, s.AgentID); err != nil {
+			assign = false
+		}
+	}
+
+	if s.Priority > 0 || assign {
+		params := db.UpdateTaskParams{
+			ID:             task.ID,
+			Title:          task.Title,
+			Description:    task.Description,
+			AgentID:        task.AgentID,
+			ProjectID:      task.ProjectID,
+			Status:         task.Status,
+			Priority:       task.Priority,
+			ProjectMd:      task.ProjectMd,
+			RequirementsMd: task.RequirementsMd,
+			RoadmapMd:      task.RoadmapMd,
+			StateMd:        task.StateMd,
+			PrdJson:        task.PrdJson,
+			ProgressTxt:    task.ProgressTxt,
+			GitBranch:      task.GitBranch,
+			QualityChecks:  task.QualityChecks,
+			DelegationMode: task.DelegationMode,
+			ScheduledAt:    task.ScheduledAt,
+			RetryAt:        task.RetryAt,
+		}
+		if s.Priority > 0 {
+			params.Priority = sql.NullInt64{Int64: int64(s.Priority), Valid: true}
+		}
+		if assign {
+			params.AgentID = sql.NullString{String: s.AgentID, Valid: true}
+		}
+		if task, err = h.store.UpdateTask(ctx, params); err != nil {
+			return task, nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+	if len(s.Labels) > 0 {
+		if err := h.store.SetTaskLabels(ctx, task.ID, s.Labels); err != nil {
+			return task, nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+	if err := h.store.DecideTaskTriage(ctx, task.ID, store.TriageApplied, by); err != nil {
+		return task, nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{"suggestion": s, "assigned": assign, "decided_by": by})
+	h.logEvent(ctx, task.ID, task.AgentID.String, "triage_applied",
+		fmt.Sprintf("Triage applied (%s): %s", by, describeSuggestion(s)), string(details))
+
+	var subtasks []db.Task
+	if len(s.Split) > 0 {
+		subs := make([]SplitSubtaskRequest, len(s.Split))
+		for i, sub := range s.Split {
+			subs[i] = SplitSubtaskRequest{Title: sub.Title, Description: sub.Description, AgentID: sub.AgentID}
+			if sub.Priority > 0 {
+				p := sub.Priority
+				subs[i].Priority = &p
+			}
+		}
+		if subtasks, err = h.splitTask(ctx, task, subs); err != nil {
+			log.Printf("[TaskHandler] Failed to split task %s as triaged: %v", task.ID, err)
+		}
+	}
+	if assign {
+		task = h.dispatchNewTask(ctx, task, "")
+	}
+	return task, subtasks, nil
+}
+
+// describeSuggestion sums a suggestion up for an event message.
+func describeSuggestion(s triage.Suggestion) string {
+	if s.Empty() {
+		return "no changes"
+	}
+	var parts []string
+	if s.Priority > 0 {
+		parts = append(parts, fmt.Sprintf("priority %d", s.Priority))
+	}
+	if len(s.Labels) > 0 {
+		parts = append(parts, "labels "+strings.Join(s.Labels, ", "))
+	}
+	if s.AgentID != "" {
+		parts = append(parts, "agent "+s.AgentID)
+	}
+	if len(s.Split) > 0 {
+		parts = append(parts, fmt.Sprintf("a split into %d subtask(s)", len(s.Split)))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// GetTriage - GET /api/v1/tasks/:id/triage
+func (h *TaskHandler) GetTriage(c echo.Context) error {
+	ctx := c.Request().Context()
+	task, err := h.store.GetTask(ctx, c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+	}
+	record, err := h.store.GetTaskTriage(ctx, task.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return echo.NewHTTPError(http.StatusNotFound, "Task has not been triaged")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, toTriageResponse(record))
+}
+
+// RequestTriage - POST /api/v1/tasks/:id/triage
+// Asks the triage agent about the task now, whoever it is assigned to,
+// replacing any earlier suggestion.
+func (h *TaskHandler) RequestTriage(c echo.Context) error {
+	if h.triageAgent == "" || h.agentSender == nil {
+		return apierror.New(http.StatusServiceUnavailable, apierror.Unavailable, "Triage is disabled (set TRIAGE_AGENT_ID)")
+	}
+	ctx := c.Request().Context()
+	task, err := h.store.GetTask(ctx, c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+	}
+	record, err := h.requestTriage(ctx, task)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusAccepted, toTriageResponse(record))
+}
+
+// TriageDecisionRequest is the body of accepting or dismissing a suggestion.
+type TriageDecisionRequest struct {
+	DecidedBy string `json:"decided_by"` // default "human"
+}
+
+// stagedTriage reads a decision on the staged suggestion of the task in the
+// request.
+func (h *TaskHandler) stagedTriage(c echo.Context) (db.TaskTriage, TriageDecisionRequest, error) {
+	var req TriageDecisionRequest
+	if err := bind(c, &req); err != nil {
+		return db.TaskTriage{}, req, err
+	}
+	if req.DecidedBy == "" {
+		req.DecidedBy = "human"
+	}
+	record, err := h.store.GetTaskTriage(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return record, req, echo.NewHTTPError(http.StatusNotFound, "Task has not been triaged")
+	}
+	if record.Status != store.TriageStaged {
+		return record, req, apierror.New(http.StatusConflict, apierror.InvalidTransition,
+			fmt.Sprintf("Triage is %s, not staged", record.Status))
+	}
+	return record, req, nil
+}
+
+// AcceptTriage - POST /api/v1/tasks/:id/triage/accept
+// Applies the staged suggestion.
+func (h *TaskHandler) AcceptTriage(c echo.Context) error {
+	record, req, err := h.stagedTriage(c)
+	if err != nil {
+		return err
+	}
+	var s triage.Suggestion
+	if err := json.Unmarshal([]byte(record.Suggestion.String), &s); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	ctx := c.Request().Context()
+	task, subtasks, err := h.applyTriage(ctx, record.TaskID, s, req.DecidedBy)
+	if err != nil {
+		return err
+	}
+	if record, err = h.store.GetTaskTriage(ctx, record.TaskID); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"triage":   toTriageResponse(record),
+		"task":     h.taskResponse(ctx, task),
+		"subtasks": h.taskResponses(ctx, subtasks...),
+	})
+}
+
+// DismissTriage - POST /api/v1/tasks/:id/triage/dismiss
+// Turns the staged suggestion down, leaving the task as it is.
+func (h *TaskHandler) DismissTriage(c echo.Context) error {
+	record, req, err := h.stagedTriage(c)
+	if err != nil {
+		return err
+	}
+	ctx := c.Request().Context()
+	if err := h.store.DecideTaskTriage(ctx, record.TaskID, store.TriageDismissed, req.DecidedBy); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	h.logEvent(ctx, record.TaskID, "", "triage_dismissed",
+		fmt.Sprintf("Triage dismissed by %s", req.DecidedBy), "")
+	if record, err = h.store.GetTaskTriage(ctx, record.TaskID); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, toTriageResponse(record))
+}
+
+// ListTriage - GET /api/v1/triage?status=staged
+// Returns the suggestions with status (default staged), with their tasks,
+// most recent first.
+func (h *TaskHandler) ListTriage(c echo.Context) error {
+	status := c.QueryParam("status")
+	switch status {
+	case "":
+		status = store.TriageStaged
+	case store.TriagePending, store.TriageStaged, store.TriageApplied, store.TriageDismissed, store.TriageFailed:
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, "status must be pending, staged, applied, dismissed or failed")
+	}
+	ctx := c.Request().Context()
+	records, err := h.store.ListTaskTriage(ctx, status)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	resp := make([]TriageResponse, 0, len(records))
+	for _, r := range records {
+		item := toTriageResponse(r)
+		if task, err := h.store.GetTask(ctx, r.TaskID); err == nil {
+			t := h.taskResponse(ctx, task)
+			item.Task = &t
+		}
+		resp = append(resp, item)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// addLabels sets the labels of each task in resp, in one query.
+func (h *TaskHandler) addLabels(ctx context.Context, resp []TaskResponse) {
+	ids := make([]string, len(resp))
+	for i := range resp {
+		ids[i] = resp[i].ID
+	}
+	if len(ids) == 0 {
+		return
+	}
+	labels, err := h.store.ListTaskLabels(ctx, ids)
+	if err != nil {
+		log.Printf("[TaskHandler] Error loading task labels: %v", err)
+		return
+	}
+	for i := range resp {
+		resp[i].Labels = labels[resp[i].ID]
+	}
+}
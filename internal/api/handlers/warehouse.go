@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/objectstore"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/warehouse"
+)
+
+// AnalyticsExportHandler shows and triggers the nightly analytics export.
+type AnalyticsExportHandler struct {
+	store    AnalyticsExportHandlerStore
+	exporter *warehouse.Exporter // nil when ANALYTICS_EXPORT_ENABLED is not set
+}
+
+func NewAnalyticsExportHandler(s AnalyticsExportHandlerStore, exporter *warehouse.Exporter) *AnalyticsExportHandler {
+	return &AnalyticsExportHandler{store: s, exporter: exporter}
+}
+
+type AnalyticsExportStatusResponse struct {
+	Enabled        bool    `json:"enabled"`
+	Destination    string  `json:"destination,omitempty"`
+	Schedule       string  `json:"schedule,omitempty"` // time of day, UTC
+	LastExportedAt *string `json:"last_exported_at,omitempty"`
+	LastEventSeq   int64   `json:"last_event_seq"`
+}
+
+// Status - GET /api/v1/admin/analytics-export
+func (h *AnalyticsExportHandler) Status(c echo.Context) error {
+	settings, err := h.store.GetSettings(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	resp := AnalyticsExportStatusResponse{
+		Enabled:        h.exporter != nil,
+		LastExportedAt: strPtr(nullTimeToString(settings.AnalyticsExportedAt), settings.AnalyticsExportedAt.Valid),
+		LastEventSeq:   settings.AnalyticsExportSeq,
+	}
+	if h.exporter != nil {
+		resp.Destination = h.exporter.Destination().String()
+		resp.Schedule = h.exporter.Schedule()
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// AnalyticsExportResponse is what an export wrote, with download links.
+type AnalyticsExportResponse struct {
+	warehouse.Report
+	Files []ObjectResponse `json:"files"`
+}
+
+// Run - POST /api/v1/admin/analytics-export
+// Runs an export now, without waiting for the nightly one.
+func (h *AnalyticsExportHandler) Run(c echo.Context) error {
+	if h.exporter == nil {
+		return echo.NewHTTPError(http.StatusForbidden, "Analytics export is disabled")
+	}
+	ttl, err := urlTTL(c)
+	if err != nil {
+		return err
+	}
+	report, err := h.exporter.Export(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadGateway, "Analytics export failed: "+err.Error())
+	}
+	resp := AnalyticsExportResponse{Report: report, Files: make([]ObjectResponse, 0, len(report.Files))}
+	for _, key := range report.Files {
+		file, err := toObjectResponse(h.exporter.Destination(), objectstore.Object{Key: key}, ttl)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		resp.Files = append(resp.Files, file)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// BasePath serves the app under prefix (BASE_PATH), e.g. /mission-control,
+// for reverse proxies that pass the path on as is: the prefix is cut from
+// request paths before routing, and the prefix itself is redirected to
+// prefix/. Requests without it are routed as they are, for proxies that
+// cut it themselves and for local clients. Use with Echo#Pre.
+func BasePath(prefix string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			switch p := req.URL.Path; {
+			case p == prefix:
+				target := prefix + "/"
+				if req.URL.RawQuery != "" {
+					target += "?" + req.URL.RawQuery
+				}
+				return c.Redirect(http.StatusMovedPermanently, target)
+			case strings.HasPrefix(p, prefix+"/"):
+				req.URL.Path = strings.TrimPrefix(p, prefix)
+				if req.URL.RawPath != "" {
+					req.URL.RawPath = strings.TrimPrefix(req.URL.RawPath, prefix)
+				}
+			}
+			return next(c)
+		}
+	}
+}
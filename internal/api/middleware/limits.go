@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// rawBodyKey holds the request body as it came, before BodyLimit.
+const rawBodyKey = "mc.rawBody"
+
+// BodyLimit refuses request bodies over limit bytes (MAX_BODY_SIZE) with 413
+// Request Entity Too Large, so a runaway client cannot have a handler read
+// an unbounded body into memory. Routes that take more, such as uploads,
+// raise it with RouteBodyLimit. 0 means no limit.
+func BodyLimit(limit int64) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if limit > 0 && req.Body != nil && req.Body != http.NoBody {
+				c.Set(rawBodyKey, req.Body)
+				req.Body = http.MaxBytesReader(c.Response(), req.Body, limit)
+			}
+			return tooLarge(next(c))
+		}
+	}
+}
+
+// RouteBodyLimit replaces the limit BodyLimit set with limit on the route
+// it is given to. Bodies declared larger are refused before being read.
+// 0 means no limit.
+func RouteBodyLimit(limit int64) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if limit > 0 && req.ContentLength > limit {
+				return echo.ErrStatusRequestEntityTooLarge
+			}
+			if body, ok := c.Get(rawBodyKey).(io.ReadCloser); ok {
+				req.Body = body
+				if limit > 0 {
+					req.Body = http.MaxBytesReader(c.Response(), body, limit)
+				}
+			}
+			return tooLarge(next(c))
+		}
+	}
+}
+
+// tooLarge turns err into 413 if a body limit was hit.
+func tooLarge(err error) error {
+	var maxBytes *http.MaxBytesError
+	if errors.As(err, &maxBytes) {
+		return echo.ErrStatusRequestEntityTooLarge
+	}
+	return err
+}
+
+// Deadline gives the route read and write from when it starts to read the
+// request and write the response, instead of the server's timeouts
+// (HTTP_READ_TIMEOUT, HTTP_WRITE_TIMEOUT), e.g. for uploads and agent runs.
+// 0 leaves that timeout as it is.
+func Deadline(read, write time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			rc := http.NewResponseController(c.Response())
+			if read > 0 {
+				rc.SetReadDeadline(time.Now().Add(read))
+			}
+			if write > 0 {
+				rc.SetWriteDeadline(time.Now().Add(write))
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/api/apierror"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/i18n"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/validation"
+)
+
+// Locale selects the request locale from the lang query parameter or the
+// Accept-Language header (falling back to defaultLocale), stores it on the
+// request context and echoes it in Content-Language.
+func Locale(defaultLocale string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			locale := i18n.Resolve(c.QueryParam("lang"), "")
+			if locale == "" {
+				locale = i18n.Match(c.Request().Header.Get("Accept-Language"), defaultLocale)
+			}
+			req := c.Request()
+			c.SetRequest(req.WithContext(i18n.WithLocale(req.Context(), locale)))
+			c.Response().Header().Set("Content-Language", locale)
+			return next(c)
+		}
+	}
+}
+
+// LocalizedErrorHandler writes every error in the apierror envelope, with
+// its message, and the field messages of validation errors, translated into
+// the request locale, by handing it to next (usually echo's default handler)
+// as an HTTP error. Messages without a translation are passed through
+// unchanged.
+func LocalizedErrorHandler(next echo.HTTPErrorHandler) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		locale := i18n.FromContext(c.Request().Context())
+
+		status, body := apierror.From(err)
+		body.Error.Message = i18n.Translate(locale, body.Error.Message)
+		if len(body.Error.Fields) > 0 {
+			fields := make(validation.Errors, len(body.Error.Fields))
+			for i, fe := range body.Error.Fields {
+				fields[i] = validation.FieldError{Field: fe.Field, Message: i18n.Translate(locale, fe.Message)}
+			}
+			body.Error.Fields = fields
+		}
+		next(echo.NewHTTPError(status, body), c)
+	}
+}
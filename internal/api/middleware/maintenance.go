@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/api/apierror"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/maintenance"
+)
+
+// RefuseDuringMaintenance fails requests with 503 while maintenance mode is
+// on. It guards the routes that create tasks; reads stay available.
+func RefuseDuringMaintenance(mode *maintenance.Mode) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if mode.Enabled() {
+				return apierror.New(http.StatusServiceUnavailable, apierror.Maintenance, mode.Message())
+			}
+			return next(c)
+		}
+	}
+}
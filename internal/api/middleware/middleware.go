@@ -1,3 +1,31 @@
 package middleware
 
-// Middleware placeholder
+import (
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
+)
+
+// HeaderDryRun marks a single request as dry-run (same effect as ?dry_run=true).
+const HeaderDryRun = "X-Dry-Run"
+
+// DryRun marks the request context as dry-run when the X-Dry-Run header or
+// the dry_run query parameter is truthy, so any agent notification triggered
+// by the request is recorded to the outbox instead of sent.
+func DryRun() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			value := c.Request().Header.Get(HeaderDryRun)
+			if value == "" {
+				value = c.QueryParam("dry_run")
+			}
+			if enabled, _ := strconv.ParseBool(value); enabled {
+				req := c.Request()
+				c.SetRequest(req.WithContext(openclaw.WithDryRun(req.Context())))
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/taskrefs"
+)
+
+// TaskShortIDs lets routes that take a task ID in their :id parameter accept
+// the task's short ID (e.g. MC-142) too: resolve maps the short ID to the task
+// ID, which replaces the parameter before the handler runs. Short IDs naming
+// no task are left as they are, so the handler reports the task not found.
+func TaskShortIDs(resolve func(ctx context.Context, shortID string) (string, bool)) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			names := c.ParamNames()
+			values := c.ParamValues()
+			for i, name := range names {
+				if name != "id" || i >= len(values) || !taskrefs.IsShortID(values[i]) {
+					continue
+				}
+				if id, ok := resolve(c.Request().Context(), values[i]); ok {
+					resolved := append([]string(nil), values...)
+					resolved[i] = id
+					c.SetParamValues(resolved...)
+				}
+			}
+			return next(c)
+		}
+	}
+}
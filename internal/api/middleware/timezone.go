@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/localtime"
+)
+
+// HeaderTimezone names the reader's time zone.
+const HeaderTimezone = "X-Timezone"
+
+// Timezone selects the reader's time zone from the tz query parameter or the
+// X-Timezone header, an IANA name such as Europe/Berlin, and stores it on the
+// request context for responses to add local times. Unknown names are
+// ignored, leaving responses in UTC only.
+func Timezone() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			name := c.QueryParam("tz")
+			if name == "" {
+				name = c.Request().Header.Get(HeaderTimezone)
+			}
+			if name != "" {
+				if loc, err := localtime.Load(name); err == nil {
+					req := c.Request()
+					c.SetRequest(req.WithContext(localtime.WithLocation(req.Context(), loc)))
+				}
+			}
+			return next(c)
+		}
+	}
+}
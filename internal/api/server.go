@@ -1,43 +1,188 @@
 package api
 
 import (
+	"context"
+	"crypto/rand"
 	"fmt"
-	"io/fs"
+	"log"
 	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/api/handlers"
+	mcmiddleware "github.com/abelkuruvilla/claw-agent-mission-control/internal/api/middleware"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/availability"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/broker"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/buildinfo"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/chatbot"
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/config"
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/dbmaint"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/email"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/github"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/grpcapi"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/hooks"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/i18n"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/jira"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/leader"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/maintenance"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/mcp"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/modelhealth"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/notifytimeout"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/objectstore"
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/pathpolicy"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/ratelimit"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/secrets"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/standup"
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/validation"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/taskrefs"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/taskscript"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/tlscert"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/updatecheck"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/warehouse"
 	ws "github.com/abelkuruvilla/claw-agent-mission-control/internal/websocket"
 )
 
 type Server struct {
-	echo             *echo.Echo
-	config           *config.Config
-	store            *store.Store
-	hub              *ws.Hub
-	agentSender      *openclaw.AgentSender
-	agentHandler     *handlers.AgentHandler
-	taskHandler      *handlers.TaskHandler
-	projectHandler   *handlers.ProjectHandler
-	commentHandler   *handlers.CommentHandler
-	reportingHandler *handlers.ReportingHandler
-	wsHandler        *handlers.WebSocketHandler
-	chatHandler      *handlers.ChatHandler
+	echo                *echo.Echo
+	config              *config.Config
+	store               *store.Store
+	hub                 *ws.Hub
+	agentSender         openclaw.Sender
+	gateway             openclaw.Gateway
+	configReader        *openclaw.ConfigReader
+	modelProber         *modelhealth.Prober
+	agentHandler        *handlers.AgentHandler
+	taskHandler         *handlers.TaskHandler
+	projectHandler      *handlers.ProjectHandler
+	groupHandler        *handlers.GroupHandler
+	gatewayHandler      *handlers.GatewayHandler
+	routingHandler      *handlers.RoutingHandler
+	quietHoursHandler   *handlers.QuietHoursHandler
+	timeoutsHandler     *handlers.NotifyTimeoutHandler
+	taskScriptHandler   *handlers.TaskScriptHandler
+	maintenanceHandler  *handlers.MaintenanceHandler
+	maintenance         *maintenance.Mode
+	leader              *leader.Elector
+	exportHandler       *handlers.AnalyticsExportHandler
+	storageHandler      *handlers.StorageHandler
+	backupHandler       *handlers.BackupHandler
+	objects             *objectstore.Live
+	exporter            *warehouse.Exporter
+	dbMaintHandler      *handlers.DBMaintHandler
+	optimizer           *dbmaint.Optimizer
+	standupHandler      *handlers.StandupHandler
+	standup             *standup.Generator
+	experimentHandler   *handlers.ExperimentHandler
+	secretHandler       *handlers.SecretHandler
+	summaryHandler      *handlers.SummaryHandler
+	commentHandler      *handlers.CommentHandler
+	trashHandler        *handlers.TrashHandler
+	reportingHandler    *handlers.ReportingHandler
+	wsHandler           *handlers.WebSocketHandler
+	chatHandler         *handlers.ChatHandler
+	outboxHandler       *handlers.OutboxHandler
+	templateHandler     *handlers.TemplateHandler
+	packHandler         *handlers.AgentTemplateHandler
+	availabilityHandler *handlers.AvailabilityHandler
+	scorecardHandler    *handlers.ScorecardHandler
+	calendarHandler     *handlers.CalendarHandler
+	jiraHandler         *handlers.JiraHandler
+	jiraSyncer          *jira.Syncer
+	githubHandler       *handlers.GitHubHandler
+	emailHandler        *handlers.EmailHandler
+	graphqlHandler      *handlers.GraphQLHandler
+	chatBot             *chatbot.Bot
+	mcpServer           *mcp.Server
+	grpcServer          *grpcapi.Server
+	updates             *updatecheck.Checker
+	startedAt           time.Time
+	workers             map[string]Worker // background services GET /status reports on
 }
 
+// NewServer creates a Server backed by the real OpenClaw CLI and Gateway.
 func NewServer(cfg *config.Config, store *store.Store) *Server {
+	// Create OpenClaw client
+	var gateway openclaw.Gateway
+	openclawClient, err := openclaw.NewClientFromEnv()
+	if err != nil {
+		// Log warning but don't fail - gateway will be nil and chat features won't work
+		log.Printf("Warning: Failed to create OpenClaw client: %v", err)
+	} else {
+		gateway = openclawClient
+	}
+	// Agents assigned to a registered gateway are reached through it instead
+	gateway = openclaw.NewGatewayRouter(gateway, agentGatewayLookup(store))
+
+	// Build the Mission Control API URL for agent notifications: the public
+	// URL if set, since the bind address is only reachable from this host
+	mcAPIURL := cfg.PublicAPIURL + "/api/v1"
+	if cfg.PublicAPIURL == "" {
+		scheme := "http"
+		if cfg.TLSCertFile != "" && cfg.TLSKeyFile != "" {
+			scheme = "https"
+		}
+		mcAPIURL = fmt.Sprintf("%s://%s:%d/api/v1", scheme, cfg.Host, cfg.Port)
+		if cfg.Host == "0.0.0.0" {
+			mcAPIURL = fmt.Sprintf("%s://127.0.0.1:%d/api/v1", scheme, cfg.Port)
+		}
+	}
+
+	agentSender := openclaw.NewAgentSender(mcAPIURL)
+	agentSender.SetTemplates(openclaw.NewTemplates(cfg.NotifyTemplatesDir))
+	agentSender.SetTransport(openclaw.DeliveryGateway, openclaw.NewGatewayTransport(gateway))
+
+	return NewServerWithBackends(cfg, store, agentSender, gateway)
+}
+
+// agentGatewayLookup finds the registered gateway an agent is assigned to.
+func agentGatewayLookup(st *store.Store) openclaw.GatewayLookup {
+	return func(agentID string) (string, openclaw.Config, bool) {
+		ctx := context.Background()
+		agent, err := st.GetAgent(ctx, agentID)
+		if err != nil || !agent.GatewayID.Valid {
+			return "", openclaw.Config{}, false
+		}
+		gw, err := st.GetGateway(ctx, agent.GatewayID.String)
+		if err != nil {
+			log.Printf("Warning: gateway %s of agent %s not found, using the default gateway", agent.GatewayID.String, agentID)
+			return "", openclaw.Config{}, false
+		}
+		return gw.ID, openclaw.Config{GatewayURL: gw.URL, GatewayToken: gw.Token.String}, true
+	}
+}
+
+// NewServerWithBackends creates a Server using the given agent sender and
+// gateway, e.g. the in-memory fakes from the openclaw package in tests.
+func NewServerWithBackends(cfg *config.Config, store *store.Store, agentSender openclaw.Sender, gateway openclaw.Gateway) *Server {
 	e := echo.New()
 	e.HideBanner = true
+	e.HTTPErrorHandler = mcmiddleware.LocalizedErrorHandler(e.DefaultHTTPErrorHandler)
+	e.Validator = validation.New()
+	if cfg.BasePath != "" {
+		e.Pre(mcmiddleware.BasePath(cfg.BasePath))
+	}
+	defaultLocale := i18n.Resolve(cfg.DefaultLocale, i18n.DefaultLocale)
+
+	// A runaway client can neither send unbounded bodies nor hold a
+	// connection open forever; routes that need more raise the limits
+	for _, srv := range []*http.Server{e.Server, e.TLSServer} {
+		srv.ReadTimeout = cfg.HTTPReadTimeout
+		srv.WriteTimeout = cfg.HTTPWriteTimeout
+		srv.IdleTimeout = cfg.HTTPIdleTimeout
+	}
 
 	// Middleware
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
+	e.Use(mcmiddleware.BodyLimit(int64(cfg.MaxBodySize)))
 	
 	// CORS configuration - allow all origins for network access
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
@@ -57,48 +202,360 @@ func NewServer(cfg *config.Config, store *store.Store) *Server {
 			echo.HeaderAccept,
 			echo.HeaderAuthorization,
 			"X-Requested-With",
+			mcmiddleware.HeaderDryRun,
+			mcmiddleware.HeaderTimezone,
 		},
 		ExposeHeaders: []string{
 			echo.HeaderContentLength,
 			echo.HeaderContentType,
+			"X-Total-Count",
 		},
 		AllowCredentials: false,
 		MaxAge:           86400, // 24 hours
 	}))
 	
 	e.Use(middleware.Gzip())
+	e.Use(mcmiddleware.DryRun())
+	e.Use(mcmiddleware.Locale(defaultLocale))
+	e.Use(mcmiddleware.Timezone())
 
 	// Create WebSocket hub
 	hub := ws.NewHub()
 	go hub.Run()
 
-	// Create OpenClaw client
-	openclawClient, err := openclaw.NewClientFromEnv()
+	// Broadcasts also reach clients of the other instances through the
+	// broker, if one is configured
+	bus, err := broker.Open(cfg.BrokerURL)
 	if err != nil {
-		// Log warning but don't fail - client will be nil and chat features won't work
-		e.Logger.Warn("Failed to create OpenClaw client: ", err)
+		log.Printf("Warning: %v; WebSocket broadcasts stay on this instance", err)
+		bus = broker.Local{}
+	}
+	if _, local := bus.(broker.Local); !local {
+		log.Printf("Sharing WebSocket broadcasts with other instances through %s", bus)
 	}
+	hub.SetBroker(context.Background(), bus, cfg.InstanceID)
 
-	// Build the Mission Control API URL for agent notifications
-	mcAPIURL := fmt.Sprintf("http://%s:%d/api/v1", cfg.Host, cfg.Port)
-	if cfg.Host == "0.0.0.0" {
-		mcAPIURL = fmt.Sprintf("http://127.0.0.1:%d/api/v1", cfg.Port)
+	if cfg.NotifyDryRun {
+		e.Logger.Warn("NOTIFY_DRY_RUN enabled: agent notifications are recorded to the outbox, not sent")
+		agentSender.SetDryRun(true)
 	}
-	agentSender := openclaw.NewAgentSender(mcAPIURL)
+	agentSender.SetDeduper(openclaw.NewDeduper(cfg.NotifyDedupeWindow))
+	agentSender.SetSizeLimit(openclaw.NewSizeLimit(cfg.NotifyMaxMessageSize))
+
+	// Agent notifications use the agent's own locale, else the server default
+	agentSender.SetLocaleResolver(func(agentID string) string {
+		agent, err := store.GetAgent(context.Background(), agentID)
+		if err != nil || !agent.Locale.Valid {
+			return defaultLocale
+		}
+		return agent.Locale.String
+	})
+
+	// Agents that reach Mission Control by another URL (e.g. over a VPN) are
+	// told theirs
+	agentSender.SetAPIURLResolver(func(agentID string) string {
+		agent, err := store.GetAgent(context.Background(), agentID)
+		if err != nil || !agent.PublicAPIURL.Valid {
+			return ""
+		}
+		return agent.PublicAPIURL.String + "/api/v1"
+	})
+
+	// Notifications mention tasks by short ID as well, for agents to quote back
+	agentSender.SetShortIDResolver(func(taskID string) string {
+		task, err := store.GetTask(context.Background(), taskID)
+		if err != nil {
+			return ""
+		}
+		return task.ShortID.String
+	})
+
+	// Agents are told which paths their task's project lets them touch
+	agentSender.SetPathPolicyResolver(func(taskID string) []string {
+		task, err := store.GetTask(context.Background(), taskID)
+		if err != nil || !task.ProjectID.Valid {
+			return nil
+		}
+		project, err := store.GetProject(context.Background(), task.ProjectID.String)
+		if err != nil {
+			return nil
+		}
+		allowed, err := pathpolicy.Parse(project.AllowedPaths.String)
+		if err != nil {
+			return nil
+		}
+		return pathpolicy.Policy{Allowed: allowed, Base: project.Location.String}.Resolved()
+	})
+
+	// Agents picking a task up again are told what happened on it so far
+	agentSender.SetContextSummaryResolver(func(taskID string) string {
+		task, err := store.GetTask(context.Background(), taskID)
+		if err != nil {
+			return ""
+		}
+		return task.ContextSummary.String
+	})
+
+	// Agents outside the local OpenClaw config are reached the way they are set up to be
+	agentSender.SetRouteResolver(func(agentID string) openclaw.Route {
+		agent, err := store.GetAgent(context.Background(), agentID)
+		if err != nil {
+			return openclaw.Route{}
+		}
+		return openclaw.Route{
+			Method:      agent.DeliveryMethod.String,
+			CallbackURL: agent.CallbackURL.String,
+			Secret:      agent.CallbackSecret.String,
+		}
+	})
+
+	// Each attempt to notify an agent may take as long as the agent's, else
+	// the system's, timeout for the dispatch type
+	sendTimeout := func(agentID, dispatch string) time.Duration {
+		var agentTimeouts, systemTimeouts notifytimeout.Timeouts
+		if agent, err := store.GetAgent(context.Background(), agentID); err == nil {
+			agentTimeouts, _ = notifytimeout.Parse(agent.NotificationTimeouts.String)
+		}
+		if settings, err := store.GetSettings(context.Background()); err == nil {
+			systemTimeouts, _ = notifytimeout.Parse(settings.NotificationTimeouts.String)
+		}
+		return notifytimeout.Resolve(agentTimeouts, systemTimeouts, dispatch)
+	}
+	agentSender.SetTimeoutResolver(sendTimeout)
 
 	s := &Server{
 		echo:             e,
 		config:           cfg,
+		startedAt:        time.Now(),
+		workers:          make(map[string]Worker),
 		store:            store,
 		hub:              hub,
 		agentSender:      agentSender,
-		agentHandler:     handlers.NewAgentHandler(store),
+		gateway:          gateway,
+		configReader:     openclaw.NewConfigReader(cfg.OpenClawConfigPath),
+		agentHandler:     handlers.NewAgentHandler(store, hub, agentSender, cfg.AgentRunEnabled, cfg.AgentRunMaxTimeout),
 		taskHandler:      handlers.NewTaskHandler(store, hub, agentSender),
 		projectHandler:   handlers.NewProjectHandler(store),
-		commentHandler:   handlers.NewCommentHandler(store),
+		commentHandler:   handlers.NewCommentHandler(store, hub),
+		trashHandler:     handlers.NewTrashHandler(store, hub),
 		reportingHandler: handlers.NewReportingHandler(store, hub),
 		wsHandler:        handlers.NewWebSocketHandler(hub),
-		chatHandler:      handlers.NewChatHandler(store, openclawClient),
+		chatHandler:      handlers.NewChatHandler(store, gateway),
+		outboxHandler:    handlers.NewOutboxHandler(agentSender),
+		templateHandler:  handlers.NewTemplateHandler(agentSender.Templates()),
+	}
+
+	// Agents @-mentioned in comments are told, as in descriptions
+	s.commentHandler.SetMentionNotifier(s.taskHandler)
+	s.projectHandler.SetTaskRemover(s.taskHandler)
+	s.groupHandler = handlers.NewGroupHandler(store, hub, s.taskHandler)
+	s.gatewayHandler = handlers.NewGatewayHandler(store, hub)
+	s.routingHandler = handlers.NewRoutingHandler(store)
+	s.quietHoursHandler = handlers.NewQuietHoursHandler(store)
+	s.timeoutsHandler = handlers.NewNotifyTimeoutHandler(store)
+	// Task scripts are compiled as they are saved, not for every task
+	taskScripts := taskscript.NewCache()
+	s.taskScriptHandler = handlers.NewTaskScriptHandler(store, taskScripts)
+	s.taskHandler.SetTaskScripts(taskScripts)
+
+	// Maintenance mode pauses background dispatching and refuses new tasks;
+	// it is kept in settings so it survives the restart of an upgrade
+	s.maintenance = handlers.LoadMaintenance(context.Background(), store)
+	s.maintenanceHandler = handlers.NewMaintenanceHandler(store, s.maintenance, hub)
+
+	// Instances sharing the database elect a leader, the only one to run
+	// background work; all of them serve the API
+	s.leader = leader.New(store, hub, cfg.InstanceID, cfg.LeaderLeaseTTL)
+
+	// Object storage keeps backups and export bundles, on local disk next to
+	// the database or in an S3-compatible bucket, as chosen in settings
+	signKey := make([]byte, 32)
+	if _, err := rand.Read(signKey); err != nil {
+		log.Fatal("Failed to create storage signing key:", err)
+	}
+	storageCreds := objectstore.Credentials{AccessKey: cfg.S3AccessKey, SecretKey: cfg.S3SecretKey}
+	storageDir := filepath.Join(filepath.Dir(cfg.DatabasePath), "objects")
+	s.objects = handlers.LoadStorage(context.Background(), store, storageCreds, storageDir, signKey)
+	s.objects.SetBasePath(cfg.BasePath)
+	s.storageHandler = handlers.NewStorageHandler(store, s.objects, storageCreds, storageDir, signKey)
+	s.backupHandler = handlers.NewBackupHandler(store, s.objects, hub)
+
+	// Analytics export: nightly CSV snapshots to object storage
+	if cfg.AnalyticsExportEnabled {
+		exportAt, err := warehouse.ParseTimeOfDay(cfg.AnalyticsExportTime)
+		if err != nil {
+			log.Printf("Warning: %v; analytics export runs at 02:00", err)
+			exportAt = 2 * time.Hour
+		}
+		s.exporter = warehouse.NewExporter(store, hub, s.objects, exportAt)
+		s.exporter.SetMaintenance(s.maintenance)
+		s.exporter.SetLeader(s.leader)
+	}
+	s.exportHandler = handlers.NewAnalyticsExportHandler(store, s.exporter)
+
+	// Database maintenance: on demand, and every DB_OPTIMIZE_INTERVAL if set
+	s.optimizer = dbmaint.NewOptimizer(store, hub, cfg.DBOptimizeInterval)
+	s.optimizer.SetMaintenance(s.maintenance)
+	s.optimizer.SetLeader(s.leader)
+	s.dbMaintHandler = handlers.NewDBMaintHandler(s.optimizer)
+
+	// Standup: on demand, and daily at STANDUP_TIME if STANDUP_ENABLED is set
+	standupAt := time.Duration(-1)
+	if cfg.StandupEnabled {
+		standupAt, err = warehouse.ParseTimeOfDay(cfg.StandupTime)
+		if err != nil {
+			log.Printf("Warning: %v; the standup is posted at 09:00", err)
+			standupAt = 9 * time.Hour
+		}
+	}
+	s.standup = standup.NewGenerator(store, hub, standupAt)
+	s.standup.SetMaintenance(s.maintenance)
+	s.standup.SetLeader(s.leader)
+	if cfg.StandupAskAgents {
+		s.standup.SetAskAgents(agentSender, cfg.StandupAskTimeout)
+	}
+	s.standupHandler = handlers.NewStandupHandler(s.standup)
+	s.taskHandler.SetMaintenance(s.maintenance)
+	// Background sends are given as long as all their attempts may take
+	s.taskHandler.SetSendTimeoutResolver(sendTimeout)
+	// Notifications interrupted by a restart are checked against the
+	// gateway before they are resent
+	s.taskHandler.SetGateway(gateway)
+
+	s.experimentHandler = handlers.NewExperimentHandler(store, hub)
+	s.secretHandler = handlers.NewSecretHandler(store, hub, secrets.NewVault(cfg.SecretsKey))
+	agentSender.SetSecretsResolver(s.secretHandler.InjectSecrets)
+	agentSender.SetSubtaskResultResolver(s.taskHandler.SubtaskResult)
+	// Tasks run on the model the routing policy picks when they are dispatched
+	agentSender.SetModelResolver(s.taskHandler.RouteModel)
+	// Tasks in a template experiment are sent with their arm's template
+	agentSender.SetAssignmentTemplateResolver(s.taskHandler.ExperimentTemplate)
+	s.summaryHandler = handlers.NewSummaryHandler(store, hub, openclaw.NewSummarizer(gateway))
+	s.agentHandler.SetRegistrationToken(cfg.AgentRegistrationToken)
+	s.agentHandler.SetConfigReader(s.configReader)
+	agentTemplatesDir := cfg.AgentTemplatesDir
+	if agentTemplatesDir == "" {
+		agentTemplatesDir = filepath.Join(filepath.Dir(cfg.DatabasePath), "agent-templates")
+	}
+	templatePacks := openclaw.NewTemplatePacks(agentTemplatesDir)
+	s.agentHandler.SetTemplatePacks(templatePacks)
+	if cfg.IdentityGenTimeout > 0 {
+		s.agentHandler.SetIdentityGenerator(openclaw.NewIdentityGenerator(gateway, cfg.IdentityGenTimeout))
+	}
+	s.packHandler = handlers.NewAgentTemplateHandler(templatePacks)
+
+	// Busy checks trust live signals (agent heartbeats, open gateway
+	// sessions) over task counts while they are fresh
+	tracker := availability.NewTracker(cfg.AgentHeartbeatTTL)
+	agentSender.SetSessionObserver(tracker.SessionObserver)
+	s.taskHandler.SetAvailability(tracker)
+	s.taskHandler.SetDelegationDefaults(cfg.DelegationMaxDepth, cfg.DelegationMaxSubtasks)
+	s.availabilityHandler = handlers.NewAvailabilityHandler(store, hub, tracker, s.taskHandler)
+	s.agentHandler.SetQueueRunner(s.taskHandler)
+
+	// Scorecards rate agents on recent work; best_performer groups dispatch by them
+	s.scorecardHandler = handlers.NewScorecardHandler(store, cfg.ScorecardWindow)
+	s.taskHandler.SetScorecardWindow(cfg.ScorecardWindow)
+
+	s.calendarHandler = handlers.NewCalendarHandler(store, cfg.CalendarToken)
+
+	// JIRA bridge: imports issues as tasks and pushes status changes back
+	if cfg.JiraURL != "" {
+		s.jiraSyncer = jira.NewSyncer(jira.NewClient(cfg.JiraURL, cfg.JiraEmail, cfg.JiraAPIToken), s.taskHandler, store, hub)
+		s.jiraSyncer.SetLeader(s.leader)
+	}
+	s.jiraHandler = handlers.NewJiraHandler(store, s.jiraSyncer)
+
+	// GitHub Issues sync: labelled issues become tasks, done tasks close them
+	var githubSyncer *github.Syncer
+	if cfg.GitHubToken != "" {
+		githubSyncer = github.NewSyncer(github.NewClient(cfg.GitHubAPIURL, cfg.GitHubToken), s.taskHandler, store, hub)
+		s.taskHandler.SetGitHubSync(githubSyncer)
+	}
+	s.githubHandler = handlers.NewGitHubHandler(store, githubSyncer, cfg.GitHubWebhookSecret)
+
+	// Email-to-task: emails posted by a mail provider or MTA become tasks
+	var emailGateway *email.Gateway
+	if cfg.EmailInboundToken != "" {
+		emailGateway = email.NewGateway(s.taskHandler, store, hub, cfg.EmailInboundAddress, cfg.EmailAllowedSenders, cfg.EmailProjectID)
+	}
+	s.emailHandler = handlers.NewEmailHandler(store, emailGateway, cfg.EmailInboundToken)
+
+	s.graphqlHandler = handlers.NewGraphQLHandler(store)
+
+	// Chat bot: tasks created, checked and approved from Telegram, which
+	// hears when they finish
+	if cfg.TelegramBotToken != "" {
+		s.chatBot = chatbot.NewBot(chatbot.NewTelegram(cfg.TelegramAPIURL, cfg.TelegramBotToken), s.taskHandler, store, cfg.TelegramChatIDs)
+		s.taskHandler.SetNotifier(s.chatBot)
+		s.chatBot.SetLeader(s.leader)
+	}
+
+	// MCP server: agents and IDEs work on tasks through MCP tools
+	if cfg.MCPPort != 0 {
+		s.mcpServer = mcp.NewServer(s.taskHandler, s.reportingHandler, store)
+	}
+
+	// gRPC API: tasks, agents and events for integrations and agents
+	// reporting often
+	if cfg.GRPCPort != 0 {
+		s.grpcServer = grpcapi.NewServer(s.taskHandler, s.reportingHandler, store)
+	}
+
+	// Rate-limited sends hold back dispatch to the agent and every agent on
+	// its model, shared by the sender and the queue
+	rateLimits := ratelimit.NewLimiter(cfg.RateLimitCooldown, func(agentID string) string {
+		agent, err := store.GetAgent(context.Background(), agentID)
+		if err != nil {
+			return ""
+		}
+		return agent.Model.String
+	})
+	agentSender.SetRateLimiter(rateLimits)
+	s.taskHandler.SetRateLimiter(rateLimits)
+	s.availabilityHandler.SetRateLimiter(rateLimits)
+
+	// Model health: configured models are probed with a test call, on
+	// demand and every MODEL_PROBE_INTERVAL if set; agents left with no
+	// available model get no work
+	s.modelProber = modelhealth.NewProber(gateway, s.configReader.ReadModels, store, hub,
+		cfg.ModelProbeInterval, cfg.ModelProbeTimeout, cfg.ModelProbeSlow)
+	s.modelProber.SetLeader(s.leader)
+	s.taskHandler.SetModelHealth(s.modelProber)
+
+	// Lifecycle hooks: site-specific executables and endpoints run before
+	// dispatch and as tasks complete or fail, able to veto or change them
+	if cfg.HooksFile != "" {
+		runner, err := hooks.Load(cfg.HooksFile)
+		if err != nil {
+			log.Fatalf("Failed to load hooks from %s: %v", cfg.HooksFile, err)
+		}
+		log.Printf("Loaded %d hook(s) from %s", len(runner.Hooks()), cfg.HooksFile)
+		s.taskHandler.SetHooks(runner)
+	}
+
+	// Triage: new unassigned tasks go to the triage agent, whose suggestions
+	// are applied or staged for acceptance
+	if cfg.TriageAgentID != "" {
+		s.taskHandler.SetTriage(cfg.TriageAgentID, cfg.TriageTimeout, cfg.TriageAutoApply)
+		log.Printf("New unassigned tasks are triaged by agent %s", cfg.TriageAgentID)
+	}
+
+	// Background services GET /status reports on; main adds those it runs
+	s.AddWorker("db_optimizer", s.optimizer)
+	s.AddWorker("model_prober", s.modelProber)
+	s.AddWorker("standup", s.standup)
+	if s.exporter != nil {
+		s.AddWorker("analytics_export", s.exporter)
+	}
+	if s.jiraSyncer != nil {
+		s.AddWorker("jira_sync", s.jiraSyncer)
+	}
+
+	// Update check: the latest GitHub release, compared with this build
+	if cfg.UpdateCheckEnabled {
+		s.updates = updatecheck.New(cfg.UpdateCheckRepo, buildinfo.Version, cfg.UpdateCheckInterval)
+		s.AddWorker("update_check", s.updates)
 	}
 
 	s.setupRoutes()
@@ -106,6 +563,28 @@ func NewServer(cfg *config.Config, store *store.Store) *Server {
 	return s
 }
 
+// resolveTaskShortID returns the ID of the task with the given short ID.
+func (s *Server) resolveTaskShortID(ctx context.Context, shortID string) (string, bool) {
+	task, err := s.store.GetTaskByShortID(ctx, shortID)
+	if err != nil {
+		return "", false
+	}
+	return task.ID, true
+}
+
+// uploadTimeout is how long upload routes may take to read the request and
+// write the response.
+const uploadTimeout = 10 * time.Minute
+
+// routeTimeout returns the timeout of a route that may take d, or 0 if the
+// server's timeout (0 = none) allows that already.
+func routeTimeout(server, d time.Duration) time.Duration {
+	if server == 0 || server >= d {
+		return 0
+	}
+	return d
+}
+
 func (s *Server) setupRoutes() {
 	// API v1 routes - all API endpoints under /api/v1
 	api := s.echo.Group("/api/v1")
@@ -113,17 +592,56 @@ func (s *Server) setupRoutes() {
 	// Health check
 	api.GET("/health", s.healthCheck)
 
+	// Routes that create tasks other than POST /tasks are refused while in
+	// maintenance mode
+	refuseDuringMaintenance := mcmiddleware.RefuseDuringMaintenance(s.maintenance)
+
+	// Uploads take larger bodies, and longer to arrive, than other requests
+	uploadBody := mcmiddleware.RouteBodyLimit(int64(s.config.MaxUploadSize))
+	uploadDeadline := mcmiddleware.Deadline(routeTimeout(s.config.HTTPReadTimeout, uploadTimeout), routeTimeout(s.config.HTTPWriteTimeout, uploadTimeout))
+
 	// Agents
 	agents := api.Group("/agents")
 	agents.GET("", s.agentHandler.List)
-	agents.POST("", s.agentHandler.Create)
+	agents.GET("/availability", s.availabilityHandler.List)
+	agents.GET("/rate-limits", s.availabilityHandler.RateLimits)
+	agents.DELETE("/rate-limits", s.availabilityHandler.ResetRateLimits)
+	agents.GET("/leaderboard", s.scorecardHandler.Leaderboard)
+	agents.POST("/register", s.agentHandler.Register)
+	agents.POST("", s.agentHandler.Create, mcmiddleware.Deadline(0, routeTimeout(s.config.HTTPWriteTimeout, s.config.IdentityGenTimeout+time.Minute)))
 	agents.GET("/:id", s.agentHandler.Get)
 	agents.PUT("/:id", s.agentHandler.Update)
 	agents.DELETE("/:id", s.agentHandler.Delete)
+	agents.POST("/:id/run", s.agentHandler.RunCommand, mcmiddleware.Deadline(0, routeTimeout(s.config.HTTPWriteTimeout, s.config.AgentRunMaxTimeout+time.Minute)))
+	agents.GET("/:id/workspace", s.agentHandler.Workspace)
+	agents.GET("/:id/workspace/file", s.agentHandler.WorkspaceFile)
+	agents.GET("/:id/memory", s.agentHandler.ListMemory)
+	agents.GET("/:id/memory/:name", s.agentHandler.GetMemory)
+	agents.PUT("/:id/memory/:name", s.agentHandler.PutMemory)
+	agents.POST("/:id/regenerate-identity", s.agentHandler.RegenerateIdentity)
+	agents.POST("/:id/regenerate-identity/apply", s.agentHandler.ApplyIdentity)
+	agents.POST("/:id/clone", s.agentHandler.Clone)
 
 	// Agent Queue
 	agents.GET("/:id/queue", s.taskHandler.GetAgentQueue)
 	agents.POST("/:id/queue/next", s.taskHandler.DequeueNextTask)
+	agents.POST("/:id/queue/reorder", s.taskHandler.ReorderAgentQueue)
+
+	// Agent Availability
+	agents.POST("/:id/heartbeat", s.availabilityHandler.Heartbeat)
+	agents.GET("/:id/availability", s.availabilityHandler.Get)
+	agents.GET("/:id/stats", s.scorecardHandler.Get)
+
+	// Agent Groups (shared queues)
+	groups := api.Group("/groups")
+	groups.GET("", s.groupHandler.List)
+	groups.POST("", s.groupHandler.Create)
+	groups.GET("/:id", s.groupHandler.Get)
+	groups.PUT("/:id", s.groupHandler.Update)
+	groups.DELETE("/:id", s.groupHandler.Delete)
+	groups.POST("/:id/members", s.groupHandler.AddMember)
+	groups.DELETE("/:id/members/:agentId", s.groupHandler.RemoveMember)
+	groups.GET("/:id/queue", s.groupHandler.GetQueue)
 
 	// Agent Chat
 	agentChat := agents.Group("/:id/sessions")
@@ -135,15 +653,27 @@ func (s *Server) setupRoutes() {
 	agentChat.GET("/:sessionId/poll", s.chatHandler.PollMessages)
 
 	// Tasks
-	tasks := api.Group("/tasks")
+	// Task routes accept short IDs (e.g. MC-142) wherever they take a task ID
+	tasks := api.Group("/tasks", mcmiddleware.TaskShortIDs(s.resolveTaskShortID))
 	tasks.GET("", s.taskHandler.List)
 	tasks.POST("", s.taskHandler.Create)
+	tasks.POST("/import", s.taskHandler.Import)
+	tasks.GET("/export", s.taskHandler.Export)
 	tasks.GET("/:id", s.taskHandler.Get)
 	tasks.PUT("/:id", s.taskHandler.Update)
 	tasks.DELETE("/:id", s.taskHandler.Delete)
 	tasks.PUT("/:id/status", s.taskHandler.UpdateStatus)
 	tasks.POST("/:id/retry", s.taskHandler.RetryTask)
+	tasks.POST("/:id/bump", s.taskHandler.BumpTask)
+	tasks.POST("/:id/transfer", s.taskHandler.TransferTask)
+	tasks.POST("/:id/clone", s.taskHandler.Clone, refuseDuringMaintenance)
+	tasks.POST("/:id/split", s.taskHandler.Split, refuseDuringMaintenance)
+	tasks.POST("/:id/merge", s.taskHandler.Merge)
+	tasks.POST("/:id/progress", s.reportingHandler.UpdateProgress)
+	tasks.GET("/:id/progress", s.reportingHandler.ListProgress)
+	tasks.POST("/:id/summarize", s.summaryHandler.Summarize)
 	tasks.POST("/:id/progress-txt", s.reportingHandler.AppendProgressTxt)
+	tasks.POST("/:id/files", s.reportingHandler.ReportFiles)
 	
 	// Task sub-resources
 	tasks.GET("/:id/subtasks", s.taskHandler.ListSubtasks)
@@ -151,6 +681,11 @@ func (s *Server) setupRoutes() {
 	tasks.POST("/:id/phases", s.taskHandler.CreatePhase)
 	tasks.GET("/:id/stories", s.taskHandler.ListStories)
 	tasks.POST("/:id/stories", s.taskHandler.CreateStory)
+	tasks.GET("/:id/notifications", s.taskHandler.ListNotifications)
+	tasks.GET("/:id/retries", s.taskHandler.ListRetries)
+	tasks.GET("/:id/results", s.taskHandler.ListResults)
+	tasks.GET("/:id/failure", s.taskHandler.GetFailure)
+	tasks.GET("/:id/model-route", s.taskHandler.GetModelRoute)
 	
 	// Task execution
 	tasks.POST("/:id/start", s.taskHandler.StartTask)
@@ -159,6 +694,20 @@ func (s *Server) setupRoutes() {
 	// Delegation approval
 	tasks.POST("/:id/approve", s.taskHandler.ApproveDelegation)
 	tasks.POST("/:id/request-changes", s.taskHandler.RequestChanges)
+
+	// Review
+	tasks.POST("/:id/review/approve", s.taskHandler.ApproveReview)
+	tasks.POST("/:id/review/reject", s.taskHandler.RejectReview)
+	tasks.POST("/:id/review/verdict", s.taskHandler.SubmitVerdict)
+	tasks.GET("/:id/change-requests", s.taskHandler.ListChangeRequests)
+	tasks.POST("/:id/change-requests/:crId/resolve", s.taskHandler.ResolveChangeRequest)
+
+	// Triage
+	tasks.GET("/:id/triage", s.taskHandler.GetTriage)
+	tasks.POST("/:id/triage", s.taskHandler.RequestTriage)
+	tasks.POST("/:id/triage/accept", s.taskHandler.AcceptTriage)
+	tasks.POST("/:id/triage/dismiss", s.taskHandler.DismissTriage)
+	api.GET("/triage", s.taskHandler.ListTriage)
 	
 	// Task comments
 	tasks.GET("/:id/comments", s.commentHandler.ListByTask)
@@ -172,11 +721,22 @@ func (s *Server) setupRoutes() {
 	projects.PUT("/:id", s.projectHandler.Update)
 	projects.DELETE("/:id", s.projectHandler.Delete)
 	projects.GET("/:id/tasks", s.projectHandler.ListTasks)
+	projects.GET("/:id/secrets", s.secretHandler.List)
+	projects.PUT("/:id/secrets/:name", s.secretHandler.Set)
+	projects.DELETE("/:id/secrets/:name", s.secretHandler.Delete)
+	projects.POST("/:id/github/sync", s.githubHandler.Sync, refuseDuringMaintenance)
+	projects.GET("/:id/github/issues", s.githubHandler.ListIssues)
 
 	// Comments (direct access)
 	comments := api.Group("/comments")
+	comments.GET("/:id", s.commentHandler.Get)
 	comments.DELETE("/:id", s.commentHandler.Delete)
 
+	// Trash: deleted tasks, comments and projects, restorable for a while
+	api.GET("/trash", s.trashHandler.List)
+	api.POST("/trash/:id/restore", s.trashHandler.Restore)
+	api.DELETE("/trash/:id", s.trashHandler.Delete)
+
 	// Phases
 	phases := api.Group("/phases")
 	phases.GET("/:id", s.getPhase)
@@ -192,161 +752,263 @@ func (s *Server) setupRoutes() {
 	stories.POST("/:id/pass", s.reportingHandler.PassStory)
 	stories.POST("/:id/fail", s.reportingHandler.FailStory)
 
+	// Analytics
+	api.GET("/analytics/failures", s.taskHandler.FailureAnalytics)
+	api.GET("/analytics/experiments/:id", s.experimentHandler.Analytics)
+
+	// Experiments (A/B splits of new tasks between two agents or templates)
+	experiments := api.Group("/experiments")
+	experiments.GET("", s.experimentHandler.List)
+	experiments.POST("", s.experimentHandler.Create)
+	experiments.GET("/:id", s.experimentHandler.Get)
+	experiments.PUT("/:id", s.experimentHandler.Update)
+	experiments.DELETE("/:id", s.experimentHandler.Delete)
+
+	// Calendar feed (token in the query, for calendar app subscriptions)
+	api.GET("/calendar.ics", s.calendarHandler.Feed)
+
+	// Integrations
+	jiraRoutes := api.Group("/integrations/jira")
+	jiraRoutes.POST("/import", s.jiraHandler.Import, refuseDuringMaintenance)
+	jiraRoutes.POST("/push", s.jiraHandler.Push)
+	jiraRoutes.GET("/links", s.jiraHandler.ListLinks)
+	api.POST("/integrations/github/webhook", s.githubHandler.Webhook, refuseDuringMaintenance, uploadDeadline, uploadBody)
+	api.POST("/integrations/email/inbound", s.emailHandler.Inbound, refuseDuringMaintenance, uploadDeadline, uploadBody)
+	api.GET("/integrations/email/messages", s.emailHandler.ListInbound)
+
 	// Events
 	api.GET("/events", s.listEvents)
 	api.POST("/events", s.createEvent)
 
+	// GraphQL (tasks, agents, projects and events, in the shape a view needs)
+	api.GET("/graphql", s.graphqlHandler.Query)
+	api.POST("/graphql", s.graphqlHandler.Query)
+	api.GET("/graphql/schema", s.graphqlHandler.Schema)
+
 	// Settings
 	api.GET("/settings", s.getSettings)
 	api.PUT("/settings", s.updateSettings)
 	api.POST("/settings/test-connection", s.testConnection)
 
+	// Gateways (besides the default one from OPENCLAW_GATEWAY_URL)
+	gateways := api.Group("/settings/gateways")
+	gateways.GET("", s.gatewayHandler.List)
+	gateways.POST("", s.gatewayHandler.Create)
+	gateways.GET("/:id", s.gatewayHandler.Get)
+	gateways.PUT("/:id", s.gatewayHandler.Update)
+	gateways.DELETE("/:id", s.gatewayHandler.Delete)
+	gateways.POST("/:id/test", s.gatewayHandler.Test)
+
+	// Model routing policy
+	api.GET("/settings/model-routing", s.routingHandler.Get)
+	api.PUT("/settings/model-routing", s.routingHandler.Update)
+
+	// Quiet hours
+	api.GET("/settings/quiet-hours", s.quietHoursHandler.Get)
+	api.PUT("/settings/quiet-hours", s.quietHoursHandler.Update)
+
+	// Notification timeouts
+	api.GET("/settings/notification-timeouts", s.timeoutsHandler.Get)
+	api.PUT("/settings/notification-timeouts", s.timeoutsHandler.Update)
+
+	// Task scripts, run on new tasks
+	api.GET("/settings/task-scripts", s.taskScriptHandler.Get)
+	api.PUT("/settings/task-scripts", s.taskScriptHandler.Update)
+	api.POST("/settings/task-scripts/test", s.taskScriptHandler.Test)
+
+	// Maintenance mode
+	api.GET("/admin/maintenance", s.maintenanceHandler.Get)
+	api.POST("/admin/maintenance", s.maintenanceHandler.Set)
+	api.GET("/admin/analytics-export", s.exportHandler.Status)
+	api.POST("/admin/analytics-export", s.exportHandler.Run)
+	api.GET("/admin/backups", s.backupHandler.List)
+	api.POST("/admin/backups", s.backupHandler.Create)
+	api.GET("/admin/db", s.dbMaintHandler.Status)
+	api.POST("/admin/db/optimize", s.dbMaintHandler.Optimize)
+
+	// Daily standup
+	api.GET("/standup", s.standupHandler.Preview)
+	api.POST("/standup", s.standupHandler.Post, mcmiddleware.Deadline(0, routeTimeout(s.config.HTTPWriteTimeout, s.config.StandupAskTimeout+time.Minute)))
+
+	// Object storage
+	api.GET("/settings/storage", s.storageHandler.Get)
+	api.PUT("/settings/storage", s.storageHandler.Update)
+	api.GET("/storage/objects", s.storageHandler.List)
+	api.GET("/storage/objects/*", s.storageHandler.Download)
+
+	// Dry-run outbox
+	api.GET("/outbox", s.outboxHandler.List)
+	api.DELETE("/outbox", s.outboxHandler.Clear)
+	api.PUT("/outbox/dry-run", s.outboxHandler.SetDryRun)
+
+	// Message size counts
+	api.GET("/notifications/stats", s.outboxHandler.MessageStats)
+
+	// Notification templates
+	api.GET("/templates", s.templateHandler.List)
+	api.GET("/templates/:name", s.templateHandler.Get)
+	api.POST("/templates/:name/preview", s.templateHandler.Preview)
+
+	// Agent template packs
+	agentTemplates := api.Group("/agent-templates")
+	agentTemplates.GET("", s.packHandler.List)
+	agentTemplates.GET("/:name", s.packHandler.Get)
+	agentTemplates.PUT("/:name", s.packHandler.Save)
+	agentTemplates.DELETE("/:name", s.packHandler.Delete)
+
 	// Status
 	api.GET("/status", s.getStatus)
+	api.GET("/version", s.getVersion)
 
 	// Models (from OpenClaw config)
 	api.GET("/models", s.listModels)
+	api.POST("/models/probe", s.probeModels)
 
 	// WebSocket
 	s.echo.GET("/ws", s.wsHandler.HandleWebSocket)
 }
 
-func (s *Server) ServeUI(assets fs.FS) {
-	// Serve static files from embedded UI with SPA fallback
-	fileServer := http.FileServer(http.FS(assets))
-	
-	// Handle for SPA - serve index.html for client-side routes
-	uiHandler := func(c echo.Context) error {
-		path := c.Request().URL.Path
-		
-		// Don't handle API routes
-		if len(path) >= 4 && path[:4] == "/api" {
-			return echo.NewHTTPError(http.StatusNotFound)
-		}
-		
-		// Don't handle WebSocket
-		if path == "/ws" {
-			return echo.NewHTTPError(http.StatusNotFound)
-		}
-		
-		// Clean path - remove leading slash for fs.Open
-		cleanPath := path
-		if len(cleanPath) > 0 && cleanPath[0] == '/' {
-			cleanPath = cleanPath[1:]
-		}
-		// Remove trailing slash for consistent lookup
-		if len(cleanPath) > 0 && cleanPath[len(cleanPath)-1] == '/' {
-			cleanPath = cleanPath[:len(cleanPath)-1]
-		}
-		if cleanPath == "" {
-			cleanPath = "index.html"
-		}
-		
-		// Try to open the file to check if it exists
-		f, err := assets.Open(cleanPath)
-		if err == nil {
-			stat, statErr := f.Stat()
-			f.Close()
-			
-			// If it's a directory, serve its index.html directly
-			if statErr == nil && stat.IsDir() {
-				indexPath := cleanPath + "/index.html"
-				indexFile, idxErr := assets.Open(indexPath)
-				if idxErr == nil {
-					defer indexFile.Close()
-					idxStat, _ := indexFile.Stat()
-					content := make([]byte, idxStat.Size())
-					indexFile.Read(content)
-					return c.HTMLBlob(http.StatusOK, content)
-				}
-			} else if statErr == nil {
-				// It's a file, serve it directly
-				fileServer.ServeHTTP(c.Response(), c.Request())
-				return nil
-			}
-		}
-		
-		// Check if cleanPath + "/index.html" exists (for paths without trailing slash)
-		indexPath := cleanPath + "/index.html"
-		if indexFile, err := assets.Open(indexPath); err == nil {
-			defer indexFile.Close()
-			idxStat, _ := indexFile.Stat()
-			content := make([]byte, idxStat.Size())
-			indexFile.Read(content)
-			return c.HTMLBlob(http.StatusOK, content)
-		}
-		
-		// File doesn't exist - for SPA routing, serve root index.html
-		// But only for non-asset paths (don't serve HTML for .js, .css, etc)
-		if isAssetPath(path) {
-			return echo.NewHTTPError(http.StatusNotFound, "asset not found")
-		}
-		
-		// Serve root index.html for SPA client-side routing
-		indexFile, err := assets.Open("index.html")
+// Start serves the API, WebSocket and UI, over HTTPS if a certificate is
+// configured (TLS_CERT_FILE or TLS_SELF_SIGNED). With HTTPS, a plain HTTP
+// listener on HTTP_REDIRECT_PORT sends clients on to it.
+func (s *Server) Start() error {
+	addr := fmt.Sprintf("%s:%d", s.config.Host, s.config.Port)
+	if s.config.TLSCertFile == "" || s.config.TLSKeyFile == "" {
+		return s.echo.Start(addr)
+	}
+	if s.config.TLSSelfSigned {
+		made, err := tlscert.EnsureSelfSigned(s.config.TLSCertFile, s.config.TLSKeyFile, tlscert.LocalHosts(s.config.Host))
 		if err != nil {
-			return echo.NewHTTPError(http.StatusNotFound, "index.html not found")
+			return fmt.Errorf("self-signed certificate: %w", err)
 		}
-		defer indexFile.Close()
-		
-		stat, _ := indexFile.Stat()
-		content := make([]byte, stat.Size())
-		indexFile.Read(content)
-		
-		return c.HTMLBlob(http.StatusOK, content)
-	}
-	
-	// Register for both GET and HEAD methods
-	s.echo.GET("/*", uiHandler)
-	s.echo.HEAD("/*", uiHandler)
-}
-
-// isAssetPath checks if the path is for a static asset that shouldn't fallback to index.html
-func isAssetPath(path string) bool {
-	assetExtensions := []string{".js", ".css", ".map", ".woff", ".woff2", ".ttf", ".eot", ".svg", ".png", ".jpg", ".jpeg", ".gif", ".ico", ".json"}
-	for _, ext := range assetExtensions {
-		if len(path) > len(ext) && path[len(path)-len(ext):] == ext {
-			return true
+		if made {
+			log.Printf("Made a self-signed certificate at %s; browsers and agents must be told to trust it", s.config.TLSCertFile)
 		}
 	}
-	return false
-}
-
-func (s *Server) Start() error {
-	addr := fmt.Sprintf("%s:%d", s.config.Host, s.config.Port)
-	return s.echo.Start(addr)
+	if s.config.HTTPRedirectPort > 0 {
+		go func() {
+			redirectAddr := fmt.Sprintf("%s:%d", s.config.Host, s.config.HTTPRedirectPort)
+			log.Printf("Redirecting HTTP on %s to HTTPS", redirectAddr)
+			if err := http.ListenAndServe(redirectAddr, tlscert.Redirect(s.config.Port)); err != nil {
+				log.Printf("Warning: HTTP redirect listener stopped: %v", err)
+			}
+		}()
+	}
+	return s.echo.StartTLS(addr, s.config.TLSCertFile, s.config.TLSKeyFile)
 }
 
 func (s *Server) TaskHandler() *handlers.TaskHandler {
 	return s.taskHandler
 }
 
+// AgentHandler returns the agent handler, which finishes interrupted agent
+// removals.
+func (s *Server) AgentHandler() *handlers.AgentHandler {
+	return s.agentHandler
+}
+
 func (s *Server) Hub() *ws.Hub {
 	return s.hub
 }
 
-func (s *Server) AgentSender() *openclaw.AgentSender {
+func (s *Server) AgentSender() openclaw.Sender {
 	return s.agentSender
 }
 
+// Gateway returns the OpenClaw Gateway the server talks to.
+func (s *Server) Gateway() openclaw.Gateway {
+	return s.gateway
+}
+
+// ModelProber returns the model health prober, which runs on a schedule only
+// when MODEL_PROBE_INTERVAL is set.
+func (s *Server) ModelProber() *modelhealth.Prober {
+	return s.modelProber
+}
+
+// ConfigReader returns the reader of the OpenClaw config file
+// (OPENCLAW_CONFIG_PATH), shared with agent sync.
+func (s *Server) ConfigReader() *openclaw.ConfigReader {
+	return s.configReader
+}
+
+// AnalyticsExporter returns the nightly analytics export, or nil when
+// ANALYTICS_EXPORT_ENABLED is not set.
+func (s *Server) AnalyticsExporter() *warehouse.Exporter {
+	return s.exporter
+}
+
+// Standup returns the daily standup, which is posted on a schedule only
+// when STANDUP_ENABLED is set.
+func (s *Server) Standup() *standup.Generator {
+	return s.standup
+}
+
+// DBOptimizer returns the database maintenance, which runs on a schedule
+// only when DB_OPTIMIZE_INTERVAL is set.
+func (s *Server) DBOptimizer() *dbmaint.Optimizer {
+	return s.optimizer
+}
+
+// JiraSyncer returns the JIRA bridge, or nil when JIRA_URL is not set.
+func (s *Server) JiraSyncer() *jira.Syncer {
+	return s.jiraSyncer
+}
+
+// ChatBot returns the chat bot, or nil when TELEGRAM_BOT_TOKEN is not set.
+func (s *Server) ChatBot() *chatbot.Bot {
+	return s.chatBot
+}
+
+// MCPServer returns the MCP server, or nil when MCP_PORT is not set.
+func (s *Server) MCPServer() *mcp.Server {
+	return s.mcpServer
+}
+
+// Maintenance returns the maintenance mode switch, shared with the queue
+// processor and watchdog.
+func (s *Server) Maintenance() *maintenance.Mode {
+	return s.maintenance
+}
+
+// Leader returns the leader elector, shared with the background workers.
+func (s *Server) Leader() *leader.Elector {
+	return s.leader
+}
+
+// UpdateChecker returns the check for newer releases, or nil when
+// UPDATE_CHECK_ENABLED is not set.
+func (s *Server) UpdateChecker() *updatecheck.Checker {
+	return s.updates
+}
+
+// GRPCServer returns the gRPC API server, or nil when GRPC_PORT is not set.
+func (s *Server) GRPCServer() *grpcapi.Server {
+	return s.grpcServer
+}
+
+// Handler returns the server's HTTP handler, for use with httptest.
+func (s *Server) Handler() http.Handler {
+	return s.echo
+}
+
 // Handler stubs (to be implemented in handlers/)
 func (s *Server) healthCheck(c echo.Context) error {
+	if s.maintenance.Enabled() {
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"status":      "maintenance",
+			"maintenance": s.maintenance.State(),
+		})
+	}
 	return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
 }
 
-func (s *Server) getStatus(c echo.Context) error {
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"version": "1.0.0",
-		"status":  "running",
-	})
-}
-
 // Placeholder handlers - return not implemented for now
-func (s *Server) getPhase(c echo.Context) error         { return c.JSON(http.StatusNotImplemented, nil) }
-func (s *Server) updatePhase(c echo.Context) error      { return c.JSON(http.StatusNotImplemented, nil) }
+func (s *Server) getPhase(c echo.Context) error         { return echo.NewHTTPError(http.StatusNotImplemented) }
+func (s *Server) updatePhase(c echo.Context) error      { return echo.NewHTTPError(http.StatusNotImplemented) }
 
-func (s *Server) getStory(c echo.Context) error         { return c.JSON(http.StatusNotImplemented, nil) }
-func (s *Server) updateStory(c echo.Context) error      { return c.JSON(http.StatusNotImplemented, nil) }
+func (s *Server) getStory(c echo.Context) error         { return echo.NewHTTPError(http.StatusNotImplemented) }
+func (s *Server) updateStory(c echo.Context) error      { return echo.NewHTTPError(http.StatusNotImplemented) }
 
 // Events handlers
 func (s *Server) listEvents(c echo.Context) error {
@@ -360,51 +1022,89 @@ func (s *Server) listEvents(c echo.Context) error {
 		}
 	}
 	
-	// Check for task_id or agent_id filters
-	taskID := c.QueryParam("task_id")
-	agentID := c.QueryParam("agent_id")
-	
-	var apiEvents []map[string]interface{}
-	var err error
-	
-	if taskID != "" {
-		dbEvents, e := s.store.ListEventsByTask(ctx, taskID, limit)
-		err = e
-		apiEvents = make([]map[string]interface{}, len(dbEvents))
-		for i, ev := range dbEvents {
-			apiEvents[i] = eventToAPI(ev)
+	// Events are numbered (seq) in the order they are recorded; before and
+	// after page by that number
+	filter := store.EventFilter{
+		TaskID:  c.QueryParam("task_id"),
+		AgentID: c.QueryParam("agent_id"),
+		Limit:   limit + 1, // one more, to tell whether there are more
+	}
+	if taskrefs.IsShortID(filter.TaskID) {
+		if id, ok := s.resolveTaskShortID(ctx, filter.TaskID); ok {
+			filter.TaskID = id
 		}
-	} else if agentID != "" {
-		dbEvents, e := s.store.ListEventsByAgent(ctx, agentID, limit)
-		err = e
-		apiEvents = make([]map[string]interface{}, len(dbEvents))
-		for i, ev := range dbEvents {
-			apiEvents[i] = eventToAPI(ev)
+	}
+	for _, t := range strings.Split(c.QueryParam("type"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			filter.Types = append(filter.Types, t)
 		}
-	} else {
-		dbEvents, e := s.store.ListEvents(ctx, limit)
-		err = e
-		apiEvents = make([]map[string]interface{}, len(dbEvents))
-		for i, ev := range dbEvents {
-			apiEvents[i] = eventToAPI(ev)
+	}
+	if raw := c.QueryParam("before"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n < 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, "before must be an event seq")
+		}
+		filter.Before = n
+	}
+	if raw := c.QueryParam("after"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "after must be an event seq")
+		}
+		filter.After = n
+		filter.OldestFirst = true
+	}
+	if raw := c.QueryParam("since"); raw != "" {
+		since, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "since must be an RFC3339 timestamp, e.g. 2026-02-08T10:00:00Z")
 		}
+		filter.Since = since
+		filter.OldestFirst = true
 	}
 	
+	events, err := s.store.ListEventsFiltered(ctx, filter)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	hasMore := int64(len(events)) > limit
+	if hasMore {
+		events = events[:limit]
+	}
+	
+	apiEvents := make([]map[string]interface{}, len(events))
+	for i, ev := range events {
+		apiEvents[i] = eventRowToAPI(ev)
+	}
+	meta := map[string]interface{}{
+		"total":    len(apiEvents),
+		"limit":    limit,
+		"has_more": hasMore,
+	}
+	if filter.OldestFirst {
+		// Polling: the next request continues after the last event seen
+		next := filter.After
+		if len(events) > 0 {
+			next = events[len(events)-1].Seq
+		}
+		meta["next_after"] = next
+	} else if len(events) > 0 {
+		// Scrolling back: older events are before the last one; newer ones
+		// are polled for after the first
+		meta["next_after"] = events[0].Seq
+		if hasMore {
+			meta["next_before"] = events[len(events)-1].Seq
+		}
 	}
 	
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"data": apiEvents,
-		"meta": map[string]interface{}{
-			"total": len(apiEvents),
-			"limit": limit,
-		},
+		"meta": meta,
 	})
 }
 
 func (s *Server) createEvent(c echo.Context) error {
-	return c.JSON(http.StatusNotImplemented, map[string]string{"error": "Create event not implemented"})
+	return echo.NewHTTPError(http.StatusNotImplemented, "Create event not implemented")
 }
 
 // Settings handlers
@@ -441,7 +1141,7 @@ func (s *Server) getSettings(c echo.Context) error {
 }
 
 func (s *Server) updateSettings(c echo.Context) error {
-	return c.JSON(http.StatusNotImplemented, map[string]string{"error": "Update settings not implemented yet"})
+	return echo.NewHTTPError(http.StatusNotImplemented, "Update settings not implemented yet")
 }
 
 func (s *Server) testConnection(c echo.Context) error {
@@ -490,6 +1190,21 @@ func eventToAPI(e db.Event) map[string]interface{} {
 	return result
 }
 
+// eventRowToAPI is eventToAPI with the event's number (seq).
+func eventRowToAPI(e db.ListEventsAfterRow) map[string]interface{} {
+	result := eventToAPI(db.Event{
+		ID:        e.ID,
+		TaskID:    e.TaskID,
+		AgentID:   e.AgentID,
+		Type:      e.Type,
+		Message:   e.Message,
+		Details:   e.Details,
+		CreatedAt: e.CreatedAt,
+	})
+	result["seq"] = e.Seq
+	return result
+}
+
 func settingsToAPI(s db.Setting) map[string]interface{} {
 	result := map[string]interface{}{
 		"id": s.ID,
@@ -579,10 +1294,15 @@ func settingsToAPI(s db.Setting) map[string]interface{} {
 	return result
 }
 
+// modelResponse is a configured model with what its probes found.
+type modelResponse struct {
+	openclaw.ModelConfig
+	Status modelhealth.Status `json:"status"`
+}
+
 // Models handler - returns configured models from OpenClaw
 func (s *Server) listModels(c echo.Context) error {
-	configReader := openclaw.NewConfigReader("")
-	models, err := configReader.ReadModels()
+	models, err := s.configReader.ReadModels()
 	if err != nil {
 		// Return empty list on error
 		return c.JSON(http.StatusOK, map[string]interface{}{
@@ -591,7 +1311,24 @@ func (s *Server) listModels(c echo.Context) error {
 		})
 	}
 	
+	data := make([]modelResponse, len(models))
+	for i, m := range models {
+		data[i] = modelResponse{ModelConfig: m, Status: s.modelProber.Status(m.ID)}
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data":                   data,
+		"probe_interval_seconds": int(s.modelProber.Interval().Seconds()),
+	})
+}
+
+// probeModels probes every configured model now and returns their statuses.
+// It waits for the slowest, up to MODEL_PROBE_TIMEOUT.
+func (s *Server) probeModels(c echo.Context) error {
+	statuses, err := s.modelProber.Probe(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, err.Error())
+	}
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": models,
+		"data": statuses,
 	})
 }
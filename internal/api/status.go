@@ -0,0 +1,192 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/buildinfo"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/maintenance"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/updatecheck"
+)
+
+// statusGatewayTimeout is how long GET /status waits for the gateway's
+// health endpoint.
+const statusGatewayTimeout = 3 * time.Second
+
+// Worker is a background service GET /status reports on. One that also has
+// a LastRun() time.Time method reports when it last ran (zero if never).
+type Worker interface {
+	// Running reports whether it runs on its schedule.
+	Running() bool
+}
+
+// AddWorker adds a background service GET /status reports on, under name.
+func (s *Server) AddWorker(name string, w Worker) {
+	s.workers[name] = w
+}
+
+// StatusResponse is the state of this instance: what it runs, how busy it
+// is and whether it can reach its gateway.
+type StatusResponse struct {
+	Status        string                   `json:"status"` // running or maintenance
+	Maintenance   *maintenance.State       `json:"maintenance,omitempty"`
+	Build         buildinfo.Info           `json:"build"`
+	Version       string                   `json:"version"`
+	Instance      string                   `json:"instance"`
+	IsLeader      bool                     `json:"is_leader"`
+	Leader        string                   `json:"leader"`
+	StartedAt     string                   `json:"started_at"`
+	UptimeSeconds int64                    `json:"uptime_seconds"`
+	Database      *DatabaseStatus          `json:"database,omitempty"` // omitted if its size can't be read
+	Services      map[string]ServiceStatus `json:"services"`
+	Queues        QueueStatus              `json:"queues"`
+	WebSocket     WebSocketStatus          `json:"websocket"`
+	Gateway       GatewayStatus            `json:"gateway"`
+	Update        *updatecheck.Status      `json:"update,omitempty"` // omitted unless UPDATE_CHECK_ENABLED
+}
+
+// DatabaseStatus is the size of the database file and its WAL.
+type DatabaseStatus struct {
+	SizeBytes int64 `json:"size_bytes"`
+	FreeBytes int64 `json:"free_bytes"`
+	WALBytes  int64 `json:"wal_bytes"`
+}
+
+// ServiceStatus is the state of a background service. Services only do
+// their work on the leader, but run on every instance.
+type ServiceStatus struct {
+	Running   bool    `json:"running"`
+	LastRunAt *string `json:"last_run_at,omitempty"`
+}
+
+// QueueStatus is how much work is waiting: tasks per status, and queued
+// tasks per agent and per group (group tasks no member has claimed).
+type QueueStatus struct {
+	Queued   int64            `json:"queued"`
+	ByAgent  map[string]int64 `json:"by_agent"`
+	ByGroup  map[string]int64 `json:"by_group"`
+	ByStatus map[string]int64 `json:"by_status"`
+}
+
+type WebSocketStatus struct {
+	Clients int `json:"clients"` // connected to this instance
+}
+
+type GatewayStatus struct {
+	Connected bool   `json:"connected"`
+	Error     string `json:"error,omitempty"`
+}
+
+// getStatus - GET /api/v1/status
+// Reports on this instance for a status page. Parts that can't be read are
+// logged and left empty rather than failing the request.
+func (s *Server) getStatus(c echo.Context) error {
+	ctx := c.Request().Context()
+	resp := StatusResponse{
+		Status:        "running",
+		Build:         buildinfo.Get(),
+		Version:       buildinfo.Version,
+		Instance:      s.leader.ID(),
+		IsLeader:      s.leader.IsLeader(),
+		StartedAt:     s.startedAt.UTC().Format(time.RFC3339),
+		UptimeSeconds: int64(time.Since(s.startedAt).Seconds()),
+		Services:      make(map[string]ServiceStatus, len(s.workers)),
+		Queues: QueueStatus{
+			ByAgent:  map[string]int64{},
+			ByGroup:  map[string]int64{},
+			ByStatus: map[string]int64{},
+		},
+		WebSocket: WebSocketStatus{Clients: s.hub.ClientCount()},
+		Gateway:   s.gatewayStatus(ctx),
+	}
+	if s.maintenance.Enabled() {
+		state := s.maintenance.State()
+		resp.Status = "maintenance"
+		resp.Maintenance = &state
+	}
+	if s.updates != nil {
+		update := s.updates.Status()
+		resp.Update = &update
+	}
+
+	leaderID, err := s.leader.Leader(ctx)
+	if err != nil {
+		log.Printf("Warning: failed to look up the leader: %v", err)
+	}
+	resp.Leader = leaderID
+
+	if stats, err := s.store.DBStats(ctx); err != nil {
+		log.Printf("Warning: failed to read the database size: %v", err)
+	} else {
+		resp.Database = &DatabaseStatus{SizeBytes: stats.SizeBytes, FreeBytes: stats.FreeBytes, WALBytes: stats.WALBytes}
+	}
+
+	for name, w := range s.workers {
+		status := ServiceStatus{Running: w.Running()}
+		if lr, ok := w.(interface{ LastRun() time.Time }); ok {
+			if t := lr.LastRun(); !t.IsZero() {
+				at := t.UTC().Format(time.RFC3339)
+				status.LastRunAt = &at
+			}
+		}
+		resp.Services[name] = status
+	}
+
+	if byStatus, err := s.store.CountTasksByStatus(ctx); err != nil {
+		log.Printf("Warning: failed to count tasks by status: %v", err)
+	} else {
+		for _, row := range byStatus {
+			resp.Queues.ByStatus[row.Status.String] += row.Count
+		}
+	}
+	if queued, err := s.store.CountQueuedTasks(ctx); err != nil {
+		log.Printf("Warning: failed to count queued tasks: %v", err)
+	} else {
+		for _, row := range queued {
+			resp.Queues.Queued += row.Count
+			if row.AgentID.Valid {
+				resp.Queues.ByAgent[row.AgentID.String] += row.Count
+			} else if row.GroupID.Valid {
+				resp.Queues.ByGroup[row.GroupID.String] += row.Count
+			}
+		}
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// gatewayStatus checks that the gateway answers its health endpoint.
+func (s *Server) gatewayStatus(ctx context.Context) GatewayStatus {
+	if s.gateway == nil {
+		return GatewayStatus{Error: "no gateway configured"}
+	}
+	ctx, cancel := context.WithTimeout(ctx, statusGatewayTimeout)
+	defer cancel()
+	connected, err := s.gateway.GetStatus(ctx)
+	status := GatewayStatus{Connected: connected}
+	if err != nil {
+		status.Error = err.Error()
+	}
+	return status
+}
+
+// VersionResponse is what the binary was built from and, with
+// UPDATE_CHECK_ENABLED, whether a newer release is out.
+type VersionResponse struct {
+	buildinfo.Info
+	Update *updatecheck.Status `json:"update,omitempty"`
+}
+
+// getVersion - GET /api/v1/version
+func (s *Server) getVersion(c echo.Context) error {
+	resp := VersionResponse{Info: buildinfo.Get()}
+	if s.updates != nil {
+		update := s.updates.Status()
+		resp.Update = &update
+	}
+	return c.JSON(http.StatusOK, resp)
+}
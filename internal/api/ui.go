@@ -0,0 +1,124 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// basePathPlaceholder is the base path the UI is built with (see
+// ui/next.config.ts); it is replaced with BASE_PATH as files are served, so
+// one build works at the root and under any sub-path.
+var basePathPlaceholder = []byte("/__MC_BASE_PATH__")
+
+// immutableAssetPrefix holds the Next.js build output whose file names
+// carry a content hash (or the build ID), so a URL never changes content.
+const immutableAssetPrefix = "_next/static/"
+
+// ServeUI serves the UI from assets, with index.html for client-side routes,
+// under BASE_PATH.
+// Hashed build assets are cached for good; everything else, index.html
+// included, is revalidated by ETag so a new build shows up on reload.
+func (s *Server) ServeUI(assets fs.FS) {
+	uiHandler := func(c echo.Context) error {
+		urlPath := c.Request().URL.Path
+
+		// Don't handle API routes or the WebSocket
+		if strings.HasPrefix(urlPath, "/api") || urlPath == "/ws" {
+			return echo.NewHTTPError(http.StatusNotFound)
+		}
+
+		// fs.FS names have no leading or trailing slash and no dot segments
+		name := strings.Trim(path.Clean("/"+urlPath), "/")
+		if name == "" {
+			name = "index.html"
+		}
+
+		// A file, or a directory (with or without trailing slash) holding an index.html
+		if fileExists(assets, name) {
+			return serveAsset(c, assets, s.config.BasePath, name)
+		}
+		if index := path.Join(name, "index.html"); fileExists(assets, index) {
+			return serveAsset(c, assets, s.config.BasePath, index)
+		}
+
+		// Missing assets are not answered with HTML
+		if isAssetPath(urlPath) {
+			return echo.NewHTTPError(http.StatusNotFound, "asset not found")
+		}
+
+		// Serve root index.html for SPA client-side routing
+		if !fileExists(assets, "index.html") {
+			return echo.NewHTTPError(http.StatusNotFound, "index.html not found")
+		}
+		return serveAsset(c, assets, s.config.BasePath, "index.html")
+	}
+
+	// Register for both GET and HEAD methods
+	s.echo.GET("/*", uiHandler)
+	s.echo.HEAD("/*", uiHandler)
+}
+
+// serveAsset writes the file name of assets, its links set to basePath, with
+// its content type and cache headers. Conditional and range requests are
+// answered by http.ServeContent.
+func serveAsset(c echo.Context, assets fs.FS, basePath, name string) error {
+	f, err := assets.Open(name)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "asset not found")
+	}
+	defer f.Close()
+	content, err := io.ReadAll(f)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if bytes.Contains(content, basePathPlaceholder) {
+		content = bytes.ReplaceAll(content, basePathPlaceholder, []byte(basePath))
+	}
+	var modTime time.Time // zero for embedded files, which ServeContent skips
+	if info, err := f.Stat(); err == nil {
+		modTime = info.ModTime()
+	}
+
+	header := c.Response().Header()
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = http.DetectContentType(content)
+	}
+	header.Set(echo.HeaderContentType, contentType)
+	if strings.HasPrefix(name, immutableAssetPrefix) {
+		header.Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		sum := sha256.Sum256(content)
+		header.Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+		header.Set("Cache-Control", "no-cache")
+	}
+
+	http.ServeContent(c.Response(), c.Request(), name, modTime, bytes.NewReader(content))
+	return nil
+}
+
+// fileExists reports whether name is a regular file of assets.
+func fileExists(assets fs.FS, name string) bool {
+	info, err := fs.Stat(assets, name)
+	return err == nil && !info.IsDir()
+}
+
+func isAssetPath(path string) bool {
+	assetExtensions := []string{".js", ".css", ".map", ".woff", ".woff2", ".ttf", ".eot", ".svg", ".png", ".jpg", ".jpeg", ".gif", ".ico", ".json"}
+	for _, ext := range assetExtensions {
+		if len(path) > len(ext) && path[len(path)-len(ext):] == ext {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+// Package availability tracks whether agents are free from live signals:
+// heartbeats the agents send and the gateway sessions Mission Control has
+// open with them. Counting active task rows drifts when an agent dies
+// mid-task; these signals win while they are fresh, and callers fall back to
+// the task count when there is nothing fresh to go on.
+package availability
+
+import (
+	"sync"
+	"time"
+)
+
+// Agent states reported by heartbeats and the tracker.
+const (
+	StateIdle    = "idle"
+	StateBusy    = "busy"
+	StateUnknown = "unknown" // no fresh signal; busyness comes from the task count
+)
+
+// Signal sources, reported alongside the state.
+const (
+	SourceHeartbeat = "heartbeat"
+	SourceSession   = "gateway_session"
+)
+
+// DefaultTTL is how long a heartbeat is trusted when no TTL is configured.
+const DefaultTTL = 10 * time.Minute
+
+// Status is the tracker's view of one agent.
+type Status struct {
+	AgentID        string     `json:"agent_id"`
+	State          string     `json:"state"`
+	Source         string     `json:"source,omitempty"`
+	TaskID         string     `json:"task_id,omitempty"` // as reported by the last heartbeat
+	ActiveSessions int        `json:"active_sessions"`
+	LastHeartbeat  *time.Time `json:"last_heartbeat,omitempty"`
+}
+
+type agentState struct {
+	heartbeatAt   time.Time
+	heartbeatBusy bool
+	taskID        string
+	sessions      int
+	// activityAt is the last time work reached the agent by another route
+	// (a session opened, a task went active); older heartbeats are stale.
+	activityAt time.Time
+}
+
+// Tracker holds the latest availability signals per agent. A nil Tracker
+// knows nothing, so every caller falls back to the task count.
+type Tracker struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	now    func() time.Time
+	agents map[string]*agentState
+}
+
+// NewTracker creates a Tracker that trusts heartbeats for ttl (DefaultTTL if
+// ttl <= 0).
+func NewTracker(ttl time.Duration) *Tracker {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Tracker{ttl: ttl, now: time.Now, agents: make(map[string]*agentState)}
+}
+
+func (t *Tracker) agent(agentID string) *agentState {
+	a, ok := t.agents[agentID]
+	if !ok {
+		a = &agentState{}
+		t.agents[agentID] = a
+	}
+	return a
+}
+
+// Heartbeat records an agent's own report of whether it is working, and on
+// which task.
+func (t *Tracker) Heartbeat(agentID string, busy bool, taskID string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	a := t.agent(agentID)
+	a.heartbeatAt = t.now()
+	a.heartbeatBusy = busy
+	a.taskID = taskID
+}
+
+// SessionStarted records that a gateway session with the agent is in flight
+// (e.g. a notification the agent is still processing).
+func (t *Tracker) SessionStarted(agentID string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	a := t.agent(agentID)
+	a.sessions++
+	a.activityAt = t.now()
+}
+
+// SessionEnded records that a gateway session with the agent has finished.
+func (t *Tracker) SessionEnded(agentID string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if a := t.agents[agentID]; a != nil && a.sessions > 0 {
+		a.sessions--
+	}
+}
+
+// SessionObserver adapts the tracker to openclaw.Sender.SetSessionObserver.
+func (t *Tracker) SessionObserver(agentID string, active bool) {
+	if active {
+		t.SessionStarted(agentID)
+	} else {
+		t.SessionEnded(agentID)
+	}
+}
+
+// TaskActivity records that the agent picked up work outside a session (a
+// task of theirs went active), so an earlier idle heartbeat no longer holds.
+func (t *Tracker) TaskActivity(agentID string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.agent(agentID).activityAt = t.now()
+}
+
+// Status returns the tracker's view of one agent. A state other than
+// StateUnknown means live signals decide whether the agent is busy.
+func (t *Tracker) Status(agentID string) Status {
+	s := Status{AgentID: agentID, State: StateUnknown}
+	if t == nil {
+		return s
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	a := t.agents[agentID]
+	if a == nil {
+		return s
+	}
+	s.ActiveSessions = a.sessions
+	if !a.heartbeatAt.IsZero() {
+		at := a.heartbeatAt
+		s.LastHeartbeat = &at
+		s.TaskID = a.taskID
+	}
+
+	switch {
+	case a.sessions > 0:
+		s.State, s.Source = StateBusy, SourceSession
+	case !a.heartbeatAt.IsZero() && t.now().Sub(a.heartbeatAt) < t.ttl && !a.heartbeatAt.Before(a.activityAt):
+		s.State, s.Source = StateIdle, SourceHeartbeat
+		if a.heartbeatBusy {
+			s.State = StateBusy
+		}
+	}
+	return s
+}
@@ -0,0 +1,109 @@
+// Package broker carries WebSocket broadcasts between Mission Control
+// instances, so a client connected to one hears what happens on all of them.
+// Messages go over a pub/sub channel of Redis or NATS, chosen by BROKER_URL;
+// without one, a single instance needs no broker and Local does nothing.
+//
+// The clients are go-redis and nats.go, behind Broker.
+package broker
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Channel is the Redis channel, and NATS subject, broadcasts are sent on.
+const Channel = "mission-control.ws"
+
+// dialTimeout bounds connecting to the broker.
+const dialTimeout = 10 * time.Second
+
+// Reconnects back off from minBackoff, doubling up to maxBackoff.
+const (
+	minBackoff = time.Second
+	maxBackoff = 30 * time.Second
+)
+
+// Broker publishes messages to, and receives them from, every instance
+// subscribed to Channel.
+type Broker interface {
+	// Publish sends data to all subscribers, this instance's included.
+	Publish(ctx context.Context, data []byte) error
+	// Subscribe calls fn with every message published until ctx is done,
+	// reconnecting as needed. It blocks.
+	Subscribe(ctx context.Context, fn func(data []byte))
+	Close() error
+	String() string
+}
+
+// Open returns the broker rawURL names: redis://[user:password@]host[:port],
+// rediss:// for Redis over TLS, or nats://[user:password@ | token@]host[:port].
+// An empty URL is Local.
+func Open(rawURL string) (Broker, error) {
+	if rawURL == "" {
+		return Local{}, nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BROKER_URL: %w", err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid BROKER_URL %q: no host", rawURL)
+	}
+	switch u.Scheme {
+	case "redis", "rediss":
+		return NewRedis(u)
+	case "nats":
+		return NewNATS(u)
+	default:
+		return nil, fmt.Errorf("unsupported BROKER_URL scheme %q (want redis, rediss or nats)", u.Scheme)
+	}
+}
+
+// Local is the broker of a single instance: nothing is sent anywhere.
+type Local struct{}
+
+func (Local) Publish(ctx context.Context, data []byte) error { return nil }
+
+func (Local) Subscribe(ctx context.Context, fn func(data []byte)) { <-ctx.Done() }
+
+func (Local) Close() error { return nil }
+
+func (Local) String() string { return "local" }
+
+// redact returns u for logs, without its password or token.
+func redact(u *url.URL) string {
+	if u.User == nil {
+		return u.String()
+	}
+	if _, ok := u.User.Password(); ok {
+		return u.Redacted()
+	}
+	c := *u
+	c.User = url.User("xxxxx")
+	return c.String()
+}
+
+// backoff returns the delay after d.
+func backoff(d time.Duration) time.Duration {
+	if d == 0 {
+		return minBackoff
+	}
+	if d *= 2; d > maxBackoff {
+		return maxBackoff
+	}
+	return d
+}
+
+// sleep waits d or until ctx is done, reporting whether ctx still isn't.
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,119 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	natsserver "github.com/nats-io/nats-server/v2/test"
+)
+
+// The round trips run against servers in the test process: miniredis, and
+// nats-server itself.
+
+func TestRedisRoundTrip(t *testing.T) {
+	roundTrip(t, "redis://"+miniredis.RunT(t).Addr())
+}
+
+func TestRedisRoundTripWithPassword(t *testing.T) {
+	srv := miniredis.RunT(t)
+	srv.RequireUserAuth("ops", "s3cret")
+	roundTrip(t, "redis://ops:s3cret@"+srv.Addr())
+}
+
+func TestNATSRoundTrip(t *testing.T) {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	opts.Authorization = "tok3n"
+	srv := natsserver.RunServer(&opts)
+	defer srv.Shutdown()
+	roundTrip(t, fmt.Sprintf("nats://tok3n@127.0.0.1:%d", opts.Port))
+}
+
+// The integration tests run the clients against real brokers, named by
+// BROKER_TEST_REDIS_URL and BROKER_TEST_NATS_URL; each is skipped when its
+// variable is unset. For example:
+//
+//	docker run -d -p 6379:6379 redis
+//	docker run -d -p 4222:4222 nats
+//	BROKER_TEST_REDIS_URL=redis://localhost:6379 \
+//	BROKER_TEST_NATS_URL=nats://localhost:4222 go test ./internal/broker
+
+func TestRedisIntegration(t *testing.T) {
+	roundTrip(t, brokerURL(t, "BROKER_TEST_REDIS_URL"))
+}
+
+func TestNATSIntegration(t *testing.T) {
+	roundTrip(t, brokerURL(t, "BROKER_TEST_NATS_URL"))
+}
+
+func brokerURL(t *testing.T, env string) string {
+	rawURL := os.Getenv(env)
+	if rawURL == "" {
+		t.Skipf("%s is not set", env)
+	}
+	return rawURL
+}
+
+// roundTrip connects two instances to the broker at rawURL and checks a
+// broadcast from one reaches both, byte for byte.
+func roundTrip(t *testing.T, rawURL string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var received [2]<-chan string
+	var brokers [2]Broker
+	for i := range brokers {
+		b, err := Open(rawURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer b.Close()
+		// Publishing repeatedly may deliver more than is read: drop the rest
+		ch := make(chan string, 16)
+		fn := func(data []byte) {
+			select {
+			case ch <- string(data):
+			default:
+			}
+		}
+		done := make(chan struct{})
+		go func() {
+			b.Subscribe(ctx, fn)
+			close(done)
+		}()
+		defer func() { <-done }()
+		brokers[i], received[i] = b, ch
+	}
+	defer cancel()
+
+	// A payload unique to this run, with bytes the protocols frame
+	payload := fmt.Sprintf("{\"run\":%d,\"text\":\"line\\r\\nbreak é\"}\r\n%s", time.Now().UnixNano(), strings.Repeat("x", 64<<10))
+
+	// Subscriptions come up in the background: publish until the broadcast
+	// reaches both instances, the publisher included
+	tick := time.NewTicker(200 * time.Millisecond)
+	defer tick.Stop()
+	var heard [2]bool
+	for !heard[0] || !heard[1] {
+		if err := brokers[1].Publish(ctx, []byte(payload)); err != nil {
+			t.Logf("publish: %v", err)
+		}
+		for waiting := true; waiting; {
+			select {
+			case got := <-received[0]:
+				heard[0] = heard[0] || got == payload
+			case got := <-received[1]:
+				heard[1] = heard[1] || got == payload
+			case <-tick.C:
+				waiting = false
+			case <-ctx.Done():
+				t.Fatalf("the broadcast did not reach both instances: heard %v", heard)
+			}
+		}
+	}
+}
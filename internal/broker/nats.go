@@ -0,0 +1,69 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATS publishes and subscribes through one nats.go connection, which
+// reconnects on its own for as long as the broker is open. Messages
+// published while it is down are buffered until it is back, up to the
+// client's reconnect buffer; past that they are lost, as with NATS core.
+type NATS struct {
+	url  *url.URL
+	conn *nats.Conn
+}
+
+// NewNATS connects to the server u names, with the user and password or
+// token it carries, in the background: Open does not wait for the server.
+func NewNATS(u *url.URL) (*NATS, error) {
+	b := &NATS{url: u}
+	conn, err := nats.Connect(u.String(),
+		nats.Name("mission-control"),
+		nats.Timeout(dialTimeout),
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+		nats.ReconnectWait(minBackoff),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				log.Printf("[Broker] NATS connection lost: %v; reconnecting", err)
+			}
+		}),
+		nats.ReconnectHandler(func(*nats.Conn) {
+			log.Printf("[Broker] Reconnected to %s", b)
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	b.conn = conn
+	return b, nil
+}
+
+func (b *NATS) Publish(ctx context.Context, data []byte) error {
+	return b.conn.Publish(Channel, data)
+}
+
+func (b *NATS) Subscribe(ctx context.Context, fn func(data []byte)) {
+	sub, err := b.conn.Subscribe(Channel, func(msg *nats.Msg) { fn(msg.Data) })
+	if err != nil {
+		log.Printf("[Broker] Failed to subscribe to %s on %s: %v", Channel, b, err)
+		return
+	}
+	log.Printf("[Broker] Subscribed to %s on %s", Channel, b)
+	<-ctx.Done()
+	sub.Unsubscribe()
+}
+
+func (b *NATS) Close() error {
+	b.conn.Close()
+	return nil
+}
+
+func (b *NATS) String() string {
+	return redact(b.url)
+}
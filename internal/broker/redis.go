@@ -0,0 +1,78 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis sends messages with PUBLISH and receives them with SUBSCRIBE,
+// through go-redis, which keeps a connection for the subscription apart
+// from the pool commands use and resubscribes when it drops.
+type Redis struct {
+	url    *url.URL
+	client *redis.Client
+}
+
+func NewRedis(u *url.URL) (*Redis, error) {
+	opts, err := redis.ParseURL(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid BROKER_URL: %w", err)
+	}
+	opts.DialTimeout = dialTimeout
+	opts.MinRetryBackoff, opts.MaxRetryBackoff = minBackoff, maxBackoff
+	return &Redis{url: u, client: redis.NewClient(opts)}, nil
+}
+
+func (b *Redis) Publish(ctx context.Context, data []byte) error {
+	return b.client.Publish(ctx, Channel, data).Err()
+}
+
+func (b *Redis) Subscribe(ctx context.Context, fn func(data []byte)) {
+	sub := b.client.Subscribe(ctx, Channel)
+	defer sub.Close()
+
+	// Wait for Redis to confirm the subscription, retrying while it is
+	// unreachable; past that, go-redis resubscribes on its own
+	var delay time.Duration
+	for {
+		_, err := sub.Receive(ctx)
+		if err == nil {
+			break
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		delay = backoff(delay)
+		log.Printf("[Broker] Redis subscription failed: %v; retrying in %v", err, delay)
+		if !sleep(ctx, delay) {
+			return
+		}
+	}
+	log.Printf("[Broker] Subscribed to %s on %s", Channel, b)
+
+	messages := sub.Channel()
+	for {
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			fn([]byte(msg.Payload))
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (b *Redis) Close() error {
+	return b.client.Close()
+}
+
+func (b *Redis) String() string {
+	return redact(b.url)
+}
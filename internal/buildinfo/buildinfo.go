@@ -0,0 +1,56 @@
+// Package buildinfo holds what Mission Control was built from. Release
+// builds set it at link time (see the Makefile):
+//
+//	go build -ldflags "-X github.com/abelkuruvilla/claw-agent-mission-control/internal/buildinfo.Version=1.2.0 \
+//	  -X github.com/abelkuruvilla/claw-agent-mission-control/internal/buildinfo.Commit=$(git rev-parse HEAD) \
+//	  -X github.com/abelkuruvilla/claw-agent-mission-control/internal/buildinfo.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/server
+//
+// Without them, the commit and its time come from the VCS stamp the Go
+// toolchain adds to builds inside a git checkout.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Set with -ldflags "-X ...". Version is the release, without a leading v.
+var (
+	Version = "1.0.0"
+	Commit  = ""
+	Date    = "" // when the binary was built, RFC3339
+)
+
+// Info is what the binary was built from.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"build_date,omitempty"`
+	Modified  bool   `json:"modified,omitempty"` // built with uncommitted changes
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns what the binary was built from. A commit not set at link time
+// is read from the VCS stamp, and so is the build date, as the commit's time.
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, BuildDate: Date, GoVersion: runtime.Version()}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.BuildDate == "" {
+				info.BuildDate = setting.Value
+			}
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
@@ -0,0 +1,94 @@
+// Package calendar renders iCalendar (RFC 5545) feeds, so calendar apps can
+// subscribe to when agent work is due.
+package calendar
+
+import (
+	"strings"
+	"time"
+)
+
+// EventLength is how long each event runs, so point-in-time work such as a
+// scheduled start shows up as a block in calendar apps.
+const EventLength = 15 * time.Minute
+
+// Event is one entry in a feed.
+type Event struct {
+	UID         string // stable across renders, so clients update instead of duplicating
+	Summary     string
+	Description string
+	Start       time.Time
+}
+
+// Render returns a feed named name holding events, stamped at now.
+func Render(name string, events []Event, now time.Time) []byte {
+	var b strings.Builder
+	line := func(s string) {
+		b.WriteString(fold(s))
+		b.WriteString("\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//Claw Agent Mission Control//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:" + escape(name))
+	for _, e := range events {
+		line("BEGIN:VEVENT")
+		line("UID:" + escape(e.UID))
+		line("DTSTAMP:" + timestamp(now))
+		line("DTSTART:" + timestamp(e.Start))
+		line("DTEND:" + timestamp(e.Start.Add(EventLength)))
+		line("SUMMARY:" + escape(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION:" + escape(e.Description))
+		}
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return []byte(b.String())
+}
+
+// timestamp formats t as a UTC date-time.
+func timestamp(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escape escapes text values: backslashes, separators and newlines.
+func escape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", "",
+	).Replace(s)
+}
+
+// fold splits content lines longer than 75 octets, continuing each with a
+// leading space, without breaking UTF-8 sequences.
+func fold(s string) string {
+	const limit = 75
+	if len(s) <= limit {
+		return s
+	}
+	var b strings.Builder
+	width := limit
+	for len(s) > width {
+		cut := width
+		for cut > 0 && !isRuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		width = limit - 1 // the leading space counts
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
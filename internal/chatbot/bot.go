@@ -0,0 +1,334 @@
+package chatbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/api/handlers"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/leader"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/taskrefs"
+)
+
+// pollRetryDelay is how long the bot waits to poll again after a failed poll.
+const pollRetryDelay = 5 * time.Second
+
+// followerPollDelay is how often a bot on an instance that is not the leader
+// checks whether it has become leader, and so should poll.
+const followerPollDelay = 10 * time.Second
+
+// statusListLimit bounds the tasks /status lists.
+const statusListLimit = 10
+
+// listedStatuses are the statuses of the tasks /status lists, in order.
+var listedStatuses = []string{"executing", "planning", "discussing", "verifying", "review", "queued"}
+
+const helpText = `Mission Control commands:
+/new [@agent] <title> - create a task; further lines are its description
+/status - list active tasks
+/status <task> - show a task, by ID or short ID (MC-12)
+/approve <subtask> - approve a finished subtask of a manually delegated task
+You are told here when tasks finish and when subtasks await approval.`
+
+// TaskService is the task logic of the API that the task handler implements,
+// so commands have the same effect as the matching API requests.
+type TaskService interface {
+	CreateTask(ctx context.Context, req handlers.CreateTaskRequest) (db.Task, error)
+	ApproveSubtask(ctx context.Context, subtaskID string) error
+}
+
+// Bot answers task commands sent over a transport and tells its chats when
+// tasks finish. It implements handlers.TaskNotifier.
+type Bot struct {
+	transport Transport
+	tasks     TaskService
+	store     *store.Store
+	chats     []string // chats allowed to use the bot, which get notifications
+	leader    *leader.Elector
+	stopChan  chan struct{}
+	running   bool
+	mu        sync.Mutex
+}
+
+// NewBot creates a bot for the chats chatIDs lists, comma-separated.
+// Messages from other chats get their chat ID back, to add it.
+func NewBot(t Transport, tasks TaskService, st *store.Store, chatIDs string) *Bot {
+	b := &Bot{transport: t, tasks: tasks, store: st, stopChan: make(chan struct{})}
+	for _, id := range strings.Split(chatIDs, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			b.chats = append(b.chats, id)
+		}
+	}
+	return b
+}
+
+// SetLeader sets the leader elector; only the leader polls for commands, as
+// transports such as Telegram allow one poller at a time.
+func (b *Bot) SetLeader(l *leader.Elector) {
+	b.leader = l
+}
+
+// Start polls the transport for commands until ctx is done or Stop is
+// called.
+func (b *Bot) Start(ctx context.Context) {
+	b.mu.Lock()
+	if b.running {
+		b.mu.Unlock()
+		return
+	}
+	b.running = true
+	b.mu.Unlock()
+
+	pollCtx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-b.stopChan:
+			cancel()
+		case <-pollCtx.Done():
+		}
+	}()
+
+	log.Printf("[ChatBot] Started, polling %s for %d chat(s)", b.transport.Name(), len(b.chats))
+	go func() {
+		defer cancel()
+		for pollCtx.Err() == nil {
+			if !b.leader.IsLeader() {
+				select {
+				case <-time.After(followerPollDelay):
+				case <-pollCtx.Done():
+				}
+				continue
+			}
+			updates, err := b.transport.Poll(pollCtx)
+			if err != nil {
+				if pollCtx.Err() != nil {
+					break
+				}
+				log.Printf("[ChatBot] Poll failed: %v", err)
+				select {
+				case <-time.After(pollRetryDelay):
+				case <-pollCtx.Done():
+				}
+				continue
+			}
+			for _, u := range updates {
+				b.Handle(pollCtx, u)
+			}
+		}
+		log.Println("[ChatBot] Stopped")
+	}()
+}
+
+// Stop stops polling.
+func (b *Bot) Stop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.running {
+		close(b.stopChan)
+		b.running = false
+	}
+}
+
+// Handle answers one message; transports that receive messages by webhook
+// call it directly.
+func (b *Bot) Handle(ctx context.Context, u Update) {
+	reply := b.answer(ctx, u)
+	if reply == "" {
+		return
+	}
+	if err := b.transport.Send(ctx, u.ChatID, reply); err != nil {
+		log.Printf("[ChatBot] Failed to answer chat %s: %v", u.ChatID, err)
+	}
+}
+
+func (b *Bot) answer(ctx context.Context, u Update) string {
+	if !b.allowed(u.ChatID) {
+		return fmt.Sprintf("This chat may not use Mission Control. Add its ID, %s, to the allowed chats to let it.", u.ChatID)
+	}
+	command, args, _ := strings.Cut(strings.TrimSpace(u.Text), " ")
+	if nl := strings.Index(command, "\n"); nl >= 0 {
+		command, args = command[:nl], command[nl+1:]+" "+args
+	}
+	// Commands sent in groups may name the bot: /status@mc_bot
+	command, _, _ = strings.Cut(strings.ToLower(command), "@")
+	args = strings.TrimSpace(args)
+
+	switch command {
+	case "/new":
+		return b.newTask(ctx, u, args)
+	case "/status":
+		if args == "" {
+			return b.listActive(ctx)
+		}
+		return b.taskStatus(ctx, args)
+	case "/approve":
+		return b.approve(ctx, args)
+	case "/start", "/help":
+		return helpText
+	}
+	if strings.HasPrefix(command, "/") {
+		return "Unknown command.\n\n" + helpText
+	}
+	return ""
+}
+
+// newTask creates a task from "/new [@agent] title\ndescription".
+func (b *Bot) newTask(ctx context.Context, u Update, args string) string {
+	title, description, _ := strings.Cut(args, "\n")
+	title = strings.TrimSpace(title)
+	var agentID string
+	if strings.HasPrefix(title, "@") {
+		agentID, title, _ = strings.Cut(title[1:], " ")
+		title = strings.TrimSpace(title)
+		if _, err := b.store.GetAgent(ctx, agentID); err != nil {
+			return fmt.Sprintf("There is no agent %s.", agentID)
+		}
+	}
+	if title == "" {
+		return "Usage: /new [@agent] <title>, with the description on further lines."
+	}
+
+	description = strings.TrimSpace(description)
+	source := fmt.Sprintf("Created on %s by %s", b.transport.Name(), u.User)
+	if description != "" {
+		description += "\n\n" + source
+	} else {
+		description = source
+	}
+	task, err := b.tasks.CreateTask(ctx, handlers.CreateTaskRequest{
+		Title:       title,
+		Description: description,
+		AgentID:     agentID,
+	})
+	if err != nil {
+		return "Could not create the task: " + errorText(err)
+	}
+	return fmt.Sprintf("Created %s: %s (%s)", taskLabel(task), task.Title, task.Status.String)
+}
+
+func (b *Bot) taskStatus(ctx context.Context, ref string) string {
+	task, err := b.findTask(ctx, ref)
+	if err != nil {
+		return fmt.Sprintf("There is no task %s.", ref)
+	}
+	var s strings.Builder
+	fmt.Fprintf(&s, "%s: %s\nStatus: %s", taskLabel(task), task.Title, task.Status.String)
+	if task.Progress.Valid {
+		fmt.Fprintf(&s, " (%d%%)", task.Progress.Int64)
+	}
+	if task.AgentID.Valid && task.AgentID.String != "" {
+		fmt.Fprintf(&s, "\nAgent: %s", task.AgentID.String)
+	}
+	if task.Status.String == "failed" && task.FailureReason.Valid {
+		fmt.Fprintf(&s, "\nFailure: %s", task.FailureReason.String)
+	}
+	return s.String()
+}
+
+func (b *Bot) listActive(ctx context.Context) string {
+	var lines []string
+	for _, status := range listedStatuses {
+		tasks, err := b.store.ListTasksByStatus(ctx, status)
+		if err != nil {
+			return "Could not list tasks: " + err.Error()
+		}
+		for _, t := range tasks {
+			if len(lines) == statusListLimit {
+				lines = append(lines, "...")
+				return strings.Join(lines, "\n")
+			}
+			lines = append(lines, fmt.Sprintf("%s %s: %s", taskLabel(t), status, t.Title))
+		}
+	}
+	if len(lines) == 0 {
+		return "No tasks are active."
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (b *Bot) approve(ctx context.Context, ref string) string {
+	if ref == "" {
+		return "Usage: /approve <subtask>"
+	}
+	task, err := b.findTask(ctx, ref)
+	if err != nil {
+		return fmt.Sprintf("There is no task %s.", ref)
+	}
+	if err := b.tasks.ApproveSubtask(ctx, task.ID); err != nil {
+		return "Could not approve: " + errorText(err)
+	}
+	return fmt.Sprintf("Approved %s; its orchestrator is being notified.", taskLabel(task))
+}
+
+// TaskFinished tells the chats a top-level task is done, failed or was
+// cancelled. Subtasks are left to their orchestrators.
+func (b *Bot) TaskFinished(ctx context.Context, task db.Task, status string) {
+	if task.ParentTaskID.Valid && task.ParentTaskID.String != "" {
+		return
+	}
+	text := fmt.Sprintf("%s %s: %s", taskLabel(task), status, task.Title)
+	if status == "failed" && task.FailureReason.Valid && task.FailureReason.String != "" {
+		text += "\nFailure: " + task.FailureReason.String
+	}
+	b.notify(text)
+}
+
+// ApprovalRequested tells the chats a subtask of a manually delegated task
+// awaits approval.
+func (b *Bot) ApprovalRequested(ctx context.Context, subtask, parent db.Task) {
+	b.notify(fmt.Sprintf("Subtask %s of %s is %s and awaits approval: %s\nReply /approve %s to pass it on.",
+		taskLabel(subtask), taskLabel(parent), subtask.Status.String, subtask.Title, taskLabel(subtask)))
+}
+
+// notify sends text to every chat, in the background.
+func (b *Bot) notify(text string) {
+	for _, chatID := range b.chats {
+		go func(chatID string) {
+			if err := b.transport.Send(context.Background(), chatID, text); err != nil {
+				log.Printf("[ChatBot] Failed to notify chat %s: %v", chatID, err)
+			}
+		}(chatID)
+	}
+}
+
+func (b *Bot) allowed(chatID string) bool {
+	for _, id := range b.chats {
+		if id == chatID {
+			return true
+		}
+	}
+	return false
+}
+
+// findTask returns the task ref names, by ID or short ID.
+func (b *Bot) findTask(ctx context.Context, ref string) (db.Task, error) {
+	if taskrefs.IsShortID(ref) {
+		return b.store.GetTaskByShortID(ctx, ref)
+	}
+	return b.store.GetTask(ctx, ref)
+}
+
+// taskLabel names a task by its short ID, or its ID if it has none.
+func taskLabel(task db.Task) string {
+	if task.ShortID.Valid && task.ShortID.String != "" {
+		return task.ShortID.String
+	}
+	return task.ID
+}
+
+// errorText is the message of a task service error.
+func errorText(err error) string {
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		return fmt.Sprint(he.Message)
+	}
+	return err.Error()
+}
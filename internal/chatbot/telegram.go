@@ -0,0 +1,145 @@
+package chatbot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTelegramAPIURL is Telegram's Bot API; a self-hosted Bot API server
+// has its own.
+const DefaultTelegramAPIURL = "https://api.telegram.org"
+
+// telegramPollTimeout is how long a getUpdates request waits for messages.
+const telegramPollTimeout = 25 * time.Second
+
+// Telegram is the Transport of a Telegram bot, receiving messages by long
+// polling so Mission Control needs no public URL.
+type Telegram struct {
+	apiURL string
+	token  string
+	http   *http.Client
+	offset int64 // the update after the last one received
+}
+
+// NewTelegram creates the transport of the bot token belongs to, talking to
+// the Bot API at apiURL (DefaultTelegramAPIURL if empty).
+func NewTelegram(apiURL, token string) *Telegram {
+	if apiURL == "" {
+		apiURL = DefaultTelegramAPIURL
+	}
+	return &Telegram{
+		apiURL: strings.TrimRight(apiURL, "/"),
+		token:  token,
+		http:   &http.Client{Timeout: telegramPollTimeout + 15*time.Second},
+	}
+}
+
+func (t *Telegram) Name() string {
+	return "telegram"
+}
+
+type telegramUpdate struct {
+	UpdateID int64 `json:"update_id"`
+	Message  *struct {
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+		From struct {
+			Username  string `json:"username"`
+			FirstName string `json:"first_name"`
+		} `json:"from"`
+		Text string `json:"text"`
+	} `json:"message"`
+}
+
+// Poll returns the text messages sent to the bot since the last poll.
+func (t *Telegram) Poll(ctx context.Context) ([]Update, error) {
+	query := url.Values{
+		"timeout":         {strconv.Itoa(int(telegramPollTimeout.Seconds()))},
+		"offset":          {strconv.FormatInt(t.offset, 10)},
+		"allowed_updates": {`["message"]`},
+	}
+	var raw []telegramUpdate
+	if err := t.call(ctx, http.MethodGet, "getUpdates?"+query.Encode(), nil, &raw); err != nil {
+		return nil, err
+	}
+
+	var updates []Update
+	for _, u := range raw {
+		t.offset = u.UpdateID + 1
+		if u.Message == nil || u.Message.Text == "" {
+			continue
+		}
+		user := u.Message.From.FirstName
+		if u.Message.From.Username != "" {
+			user = "@" + u.Message.From.Username
+		}
+		updates = append(updates, Update{
+			ChatID: strconv.FormatInt(u.Message.Chat.ID, 10),
+			User:   user,
+			Text:   u.Message.Text,
+		})
+	}
+	return updates, nil
+}
+
+// Send sends text to a chat as plain text.
+func (t *Telegram) Send(ctx context.Context, chatID, text string) error {
+	return t.call(ctx, http.MethodPost, "sendMessage", map[string]string{"chat_id": chatID, "text": text}, nil)
+}
+
+// call calls a Bot API method with body as JSON, if any, and decodes its
+// result into out, if any.
+func (t *Telegram) call(ctx context.Context, httpMethod, method string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, httpMethod, fmt.Sprintf("%s/bot%s/%s", t.apiURL, t.token, method), reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := t.http.Do(req)
+	if err != nil {
+		// The error names the URL, which holds the token
+		return fmt.Errorf("telegram %s: %w", method, redact(err, t.token))
+	}
+	defer resp.Body.Close()
+	var result struct {
+		OK          bool            `json:"ok"`
+		Description string          `json:"description"`
+		Result      json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("telegram %s: %s", method, resp.Status)
+	}
+	if !result.OK {
+		return fmt.Errorf("telegram %s: %s", method, result.Description)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(result.Result, out)
+}
+
+// redact hides token in err.
+func redact(err error, token string) error {
+	return errors.New(strings.ReplaceAll(err.Error(), token, "<token>"))
+}
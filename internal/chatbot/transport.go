@@ -0,0 +1,25 @@
+// Package chatbot lets people control tasks from a chat app: create tasks,
+// check their status and approve delegations, and hear when tasks finish.
+// Commands go through the same task logic as the API. Chat services plug in
+// as Transports; Telegram is built in.
+package chatbot
+
+import "context"
+
+// Update is a message someone sent the bot.
+type Update struct {
+	ChatID string // the chat to answer in
+	User   string // who sent it, as the chat service names them
+	Text   string
+}
+
+// Transport connects the bot to a chat service.
+type Transport interface {
+	// Name names the chat service, e.g. telegram.
+	Name() string
+	// Poll waits for new messages, returning when some arrive, the
+	// service's long-poll timeout passes or ctx is done.
+	Poll(ctx context.Context) ([]Update, error)
+	// Send sends text to a chat.
+	Send(ctx context.Context, chatID, text string) error
+}
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -19,6 +22,79 @@ type Config struct {
 	WatchdogInterval       time.Duration // How often the stuck-task watchdog runs (default 5m)
 	WatchdogStaleThreshold time.Duration // Time without update before a task is considered stuck (default 30m)
 	WatchdogMaxRetries     int           // Max re-notify attempts before resetting task (default 3)
+	AgentRunEnabled        bool          // Allow one-shot agent instructions via POST /agents/:id/run (default false)
+	AgentRunMaxTimeout     time.Duration // Upper bound for a single agent run (default 10m)
+	NotifyDryRun           bool          // Record agent notifications to the outbox instead of sending them (default false)
+	NotifyDedupeWindow     time.Duration // How long the same notification to an agent is suppressed after it was sent; 0 = never (default 2m)
+	NotifyMaxMessageSize   int           // Largest message sent to an agent in bytes; longer ones are cut, 0 = unlimited (default 65536)
+	NotifyTemplatesDir     string        // Directory of <name>.tmpl files overriding the built-in notification templates (default none)
+	IdentityGenTimeout     time.Duration // How long agent creation waits for the model to write the identity files before using templates; 0 = templates only (default 2m)
+	AgentTemplatesDir      string        // Directory of agent template packs, one subdirectory each (default agent-templates/ next to the database)
+	DefaultLocale          string        // Locale for API errors and agent notifications when none is requested/configured (default en)
+	AgentHeartbeatTTL      time.Duration // How long an agent heartbeat decides availability before falling back to task counts (default 10m)
+	AgentRegistrationToken string        // Token agents present to POST /agents/register; empty disables self-registration (default none)
+	SecretsKey             string        // Key project secrets are encrypted with; empty disables the secrets vault (default none)
+	DelegationMaxDepth     int           // How deep subtasks may nest when neither task nor project sets a limit; 0 = unlimited (default 5)
+	DelegationMaxSubtasks  int           // Unfinished subtasks a task may have when neither task nor project sets a limit; 0 = unlimited (default 20)
+	RateLimitCooldown      time.Duration // How long dispatch to a rate-limited agent and its model backs off, doubling while limits recur (default 5m)
+	ModelProbeInterval     time.Duration // How often configured models are probed with a test call; 0 = on demand only (default 0)
+	ModelProbeTimeout      time.Duration // How long a model probe waits for its reply before it fails (default 60s)
+	ModelProbeSlow         time.Duration // A probe reply slower than this marks the model degraded (default 20s)
+	ScorecardWindow        time.Duration // Window agent scorecards cover by default, also for best_performer dispatch; 0 = all time (default 720h)
+	CalendarToken          string        // Token calendar apps present to GET /calendar.ics; empty disables the feed (default none)
+	JiraURL                string        // Base URL of the JIRA site issues are imported from; empty disables the JIRA bridge (default none)
+	JiraEmail              string        // JIRA Cloud account the API token belongs to; empty sends the token as a bearer token (default none)
+	JiraAPIToken           string        // JIRA API token or personal access token (default none)
+	JiraSyncInterval       time.Duration // How often task status changes are pushed to linked JIRA issues (default 5m)
+	GitHubToken            string        // Token for the GitHub API, for syncing project issues; empty disables GitHub sync (default none)
+	GitHubAPIURL           string        // GitHub API base URL, for GitHub Enterprise Server (default https://api.github.com)
+	GitHubWebhookSecret    string        // Secret GitHub signs webhook deliveries with; empty disables the webhook (default none)
+	EmailInboundToken      string        // Token the email webhook is posted with; empty disables email-to-task (default none)
+	EmailInboundAddress    string        // Address tasks are emailed to; mail for other recipients is rejected; empty accepts any (default none)
+	EmailAllowedSenders    string        // Comma-separated senders (alice@example.com) and domains (@example.com) whose mail becomes tasks (default none)
+	EmailProjectID         string        // Project tasks from email are created in (default none)
+	TelegramBotToken       string        // Token of the Telegram bot tasks are controlled from; empty disables the bot (default none)
+	TelegramChatIDs        string        // Comma-separated Telegram chats allowed to use the bot, which get notifications (default none)
+	TelegramAPIURL         string        // Telegram Bot API base URL, for a self-hosted Bot API server (default https://api.telegram.org)
+	MCPPort                int           // Port the MCP server listens on, alongside the HTTP API; 0 disables it (default 0)
+	GRPCPort               int           // Port the gRPC API listens on, alongside the HTTP API; 0 disables it (default 0)
+	EventArchiveDir        string        // Directory events are also appended to as daily JSONL files; empty disables it (default none)
+	EventArchiveMaxSizeMB  int           // Size in MB an archive file grows to before the next one is started; 0 = daily only (default 100)
+	EventArchiveURL        string        // Endpoint events are also POSTed to in batches, as JSON lines; empty disables it (default none)
+	EventArchiveToken      string        // Bearer token for EVENT_ARCHIVE_URL (default none)
+	AnalyticsExportEnabled bool          // Export analytics CSV snapshots to object storage nightly (default false)
+	AnalyticsExportTime    string        // Time of day (UTC, HH:MM) the analytics export runs (default 02:00)
+	DBOptimizeInterval     time.Duration // How often the database is checkpointed, analyzed and vacuumed, e.g. 168h for weekly; 0 = on demand only (default 0)
+	TrashRetention         time.Duration // How long deleted tasks, comments and projects can be restored from the trash; 0 = deleted for good at once (default 168h)
+	S3AccessKey            string        // Access key ID for the s3 object storage backend (default none)
+	S3SecretKey            string        // Secret access key for the s3 object storage backend (default none)
+	InstanceID             string        // Name this instance goes by in leader election; must differ between instances (default <hostname>:<port>)
+	LeaderLeaseTTL         time.Duration // How long the leader lease lasts unless renewed; another instance takes over after this (default 30s)
+	BrokerURL              string        // Redis (redis://, rediss://) or NATS (nats://) URL WebSocket broadcasts are shared between instances through; empty keeps them local (default none)
+	BasePath               string        // URL path the app is served under behind a reverse proxy, e.g. /mission-control, without trailing slash (default none: the root)
+	TLSCertFile            string        // Certificate (PEM) to serve HTTPS with; with TLSKeyFile, the API and UI are served over HTTPS only (default none: HTTP)
+	TLSKeyFile             string        // Private key (PEM) of TLSCertFile
+	TLSSelfSigned          bool          // Make a self-signed certificate for LAN use at TLSCertFile/TLSKeyFile if none is there, by default in the tls directory next to the database (default false)
+	HTTPRedirectPort       int           // Port a plain HTTP listener redirects to HTTPS from, with TLS; 0 disables it (default 0)
+	MDNSEnabled            bool          // Advertise the API on the LAN over mDNS as a _clawmc._tcp service (default false)
+	MDNSName               string        // Service instance name advertised over mDNS (default "Mission Control (<hostname>)")
+	MaxBodySize            int           // Largest request body accepted in bytes, except on upload routes; larger ones get 413 (default 4194304)
+	MaxUploadSize          int           // Largest request body accepted on upload routes (inbound email, GitHub webhooks) in bytes (default 26214400)
+	HTTPReadTimeout        time.Duration // How long reading a request, body included, may take; upload routes get 10m; 0 = no limit (default 60s)
+	HTTPWriteTimeout       time.Duration // How long handling a request and writing the response may take; agent runs get their own timeout; 0 = no limit (default 5m)
+	HTTPIdleTimeout        time.Duration // How long an idle keep-alive connection is kept open (default 2m)
+	PublicAPIURL           string        // URL agents reach Mission Control at, e.g. https://mc.example.com, used in the API instructions they are sent; agents can override it (default http(s)://HOST:PORT, with 127.0.0.1 for 0.0.0.0)
+	UpdateCheckEnabled     bool          // Check GitHub for a newer release and report it on /api/v1/status and /api/v1/version (default false)
+	UpdateCheckRepo        string        // GitHub repository (owner/name) releases are checked in (default abelkuruvilla/claw-agent-mission-control)
+	UpdateCheckInterval    time.Duration // How often to check for a newer release (default 24h)
+	HooksFile              string        // JSON file of hooks run before dispatch and as tasks complete or fail (default none)
+	TriageAgentID          string        // Agent new unassigned tasks are sent to for triage (default none)
+	TriageTimeout          time.Duration // How long the triage agent may take to answer (default 5m)
+	TriageAutoApply        bool          // Apply triage suggestions as they arrive instead of staging them for acceptance (default false)
+	StandupEnabled         bool          // Post a standup of each agent's last 24 hours daily (default false)
+	StandupTime            string        // Time of day (UTC, HH:MM) the standup is posted (default 09:00)
+	StandupAskAgents       bool          // Ask each agent in the standup for a short status of its own (default false)
+	StandupAskTimeout      time.Duration // How long an agent may take to give its status (default 2m)
 }
 
 func Load() *Config {
@@ -50,10 +126,196 @@ func Load() *Config {
 		watchdogMaxRetries = 3
 	}
 
+	// Agent run console: disabled unless explicitly enabled, runs capped at 10m by default
+	agentRunEnabled := getEnv("AGENT_RUN_ENABLED", "false") == "true"
+	agentRunMaxTimeout, err := time.ParseDuration(getEnv("AGENT_RUN_MAX_TIMEOUT", "10m"))
+	if err != nil || agentRunMaxTimeout <= 0 {
+		agentRunMaxTimeout = 10 * time.Minute
+	}
+
+	// Duplicate notifications: suppress repeats within 2m by default
+	notifyDedupeWindow, err := time.ParseDuration(getEnv("NOTIFY_DEDUPE_WINDOW", "2m"))
+	if err != nil || notifyDedupeWindow < 0 {
+		notifyDedupeWindow = 2 * time.Minute
+	}
+
+	// Message size: cut messages to agents at 64 KiB by default
+	notifyMaxMessageSize, err := strconv.Atoi(getEnv("NOTIFY_MAX_MESSAGE_SIZE", "65536"))
+	if err != nil || notifyMaxMessageSize < 0 {
+		notifyMaxMessageSize = 65536
+	}
+
+	// Availability: trust an agent's heartbeat for 10m by default
+	agentHeartbeatTTL, err := time.ParseDuration(getEnv("AGENT_HEARTBEAT_TTL", "10m"))
+	if err != nil || agentHeartbeatTTL <= 0 {
+		agentHeartbeatTTL = 10 * time.Minute
+	}
+
+	// Delegation: subtasks nest at most 5 deep, 20 unfinished per task, by default
+	delegationMaxDepth, err := strconv.Atoi(getEnv("DELEGATION_MAX_DEPTH", "5"))
+	if err != nil || delegationMaxDepth < 0 {
+		delegationMaxDepth = 5
+	}
+	delegationMaxSubtasks, err := strconv.Atoi(getEnv("DELEGATION_MAX_CONCURRENT_SUBTASKS", "20"))
+	if err != nil || delegationMaxSubtasks < 0 {
+		delegationMaxSubtasks = 20
+	}
+
+	// Rate limits: back off dispatch for 5m after the first by default
+	rateLimitCooldown, err := time.ParseDuration(getEnv("RATE_LIMIT_COOLDOWN", "5m"))
+	if err != nil || rateLimitCooldown <= 0 {
+		rateLimitCooldown = 5 * time.Minute
+	}
+
+	// Model probes: on demand only unless an interval is set; a reply takes
+	// at most 60s, over 20s is slow
+	modelProbeInterval, err := time.ParseDuration(getEnv("MODEL_PROBE_INTERVAL", "0"))
+	if err != nil || modelProbeInterval < 0 {
+		modelProbeInterval = 0
+	}
+	modelProbeTimeout, err := time.ParseDuration(getEnv("MODEL_PROBE_TIMEOUT", "60s"))
+	if err != nil || modelProbeTimeout <= 0 {
+		modelProbeTimeout = 60 * time.Second
+	}
+	identityGenTimeout, err := time.ParseDuration(getEnv("IDENTITY_GENERATION_TIMEOUT", "2m"))
+	if err != nil || identityGenTimeout < 0 {
+		identityGenTimeout = 2 * time.Minute
+	}
+	modelProbeSlow, err := time.ParseDuration(getEnv("MODEL_PROBE_SLOW", "20s"))
+	if err != nil || modelProbeSlow < 0 {
+		modelProbeSlow = 20 * time.Second
+	}
+
+	// Scorecards: rate agents on the last 30 days by default
+	scorecardWindow, err := time.ParseDuration(getEnv("SCORECARD_WINDOW", "720h"))
+	if err != nil || scorecardWindow < 0 {
+		scorecardWindow = 720 * time.Hour
+	}
+
+	// MCP server: off unless given a port
+	mcpPort, err := strconv.Atoi(getEnv("MCP_PORT", "0"))
+	if err != nil || mcpPort < 0 {
+		mcpPort = 0
+	}
+
+	// gRPC API: off unless given a port
+	grpcPort, err := strconv.Atoi(getEnv("GRPC_PORT", "0"))
+	if err != nil || grpcPort < 0 {
+		grpcPort = 0
+	}
+
+	// Event archive: start a new file every 100 MB, besides daily
+	eventArchiveMaxSize, err := strconv.Atoi(getEnv("EVENT_ARCHIVE_MAX_SIZE_MB", "100"))
+	if err != nil || eventArchiveMaxSize < 0 {
+		eventArchiveMaxSize = 100
+	}
+
+	// JIRA bridge: push task status changes every 5 minutes by default
+	jiraSyncInterval, err := time.ParseDuration(getEnv("JIRA_SYNC_INTERVAL", "5m"))
+	if err != nil || jiraSyncInterval <= 0 {
+		jiraSyncInterval = 5 * time.Minute
+	}
+
+	// Database maintenance: on demand only unless an interval is set
+	dbOptimizeInterval, err := time.ParseDuration(getEnv("DB_OPTIMIZE_INTERVAL", "0"))
+	if err != nil || dbOptimizeInterval < 0 {
+		dbOptimizeInterval = 0
+	}
+
+	// Trash: deleted tasks, comments and projects can be restored for a week
+	trashRetention, err := time.ParseDuration(getEnv("TRASH_RETENTION", "168h"))
+	if err != nil || trashRetention < 0 {
+		trashRetention = 168 * time.Hour
+	}
+
+	// Leader election: instances sharing the database are told apart by host
+	// and port, and the leader lease lasts 30s by default
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "mission-control"
+	}
+	instanceID := getEnv("INSTANCE_ID", fmt.Sprintf("%s:%d", hostname, port))
+	leaderLeaseTTL, err := time.ParseDuration(getEnv("LEADER_LEASE_TTL", "30s"))
+	if err != nil || leaderLeaseTTL <= 0 {
+		leaderLeaseTTL = 30 * time.Second
+	}
+
+	databasePath := getEnv("DATABASE_PATH", "./data/mission-control.db")
+
+	// Base path: served at the root unless hosted under a sub-path
+	basePath := strings.Trim(getEnv("BASE_PATH", ""), "/")
+	if basePath != "" {
+		basePath = "/" + basePath
+	}
+
+	// TLS: plain HTTP unless a certificate is given or made
+	tlsSelfSigned := getEnv("TLS_SELF_SIGNED", "false") == "true"
+	tlsCertFile := getEnv("TLS_CERT_FILE", "")
+	tlsKeyFile := getEnv("TLS_KEY_FILE", "")
+	if tlsSelfSigned {
+		tlsDir := filepath.Join(filepath.Dir(databasePath), "tls")
+		if tlsCertFile == "" {
+			tlsCertFile = filepath.Join(tlsDir, "cert.pem")
+		}
+		if tlsKeyFile == "" {
+			tlsKeyFile = filepath.Join(tlsDir, "key.pem")
+		}
+	}
+	httpRedirectPort, err := strconv.Atoi(getEnv("HTTP_REDIRECT_PORT", "0"))
+	if err != nil || httpRedirectPort < 0 {
+		httpRedirectPort = 0
+	}
+
+	// Request limits: bodies and how long requests may take
+	maxBodySize, err := strconv.Atoi(getEnv("MAX_BODY_SIZE", "4194304"))
+	if err != nil || maxBodySize <= 0 {
+		maxBodySize = 4 << 20
+	}
+	maxUploadSize, err := strconv.Atoi(getEnv("MAX_UPLOAD_SIZE", "26214400"))
+	if err != nil || maxUploadSize <= 0 {
+		maxUploadSize = 25 << 20
+	}
+	httpReadTimeout, err := time.ParseDuration(getEnv("HTTP_READ_TIMEOUT", "60s"))
+	if err != nil || httpReadTimeout < 0 {
+		httpReadTimeout = 60 * time.Second
+	}
+	httpWriteTimeout, err := time.ParseDuration(getEnv("HTTP_WRITE_TIMEOUT", "5m"))
+	if err != nil || httpWriteTimeout < 0 {
+		httpWriteTimeout = 5 * time.Minute
+	}
+	httpIdleTimeout, err := time.ParseDuration(getEnv("HTTP_IDLE_TIMEOUT", "2m"))
+	if err != nil || httpIdleTimeout <= 0 {
+		httpIdleTimeout = 2 * time.Minute
+	}
+
+	// Public URL: the API under it, so a trailing /api/v1 is dropped
+	publicAPIURL := strings.TrimSuffix(strings.TrimRight(getEnv("PUBLIC_API_URL", ""), "/"), "/api/v1")
+
+	// Update check: off unless enabled, daily
+	updateCheckInterval, err := time.ParseDuration(getEnv("UPDATE_CHECK_INTERVAL", "24h"))
+	if err != nil || updateCheckInterval <= 0 {
+		updateCheckInterval = 24 * time.Hour
+	}
+
+	// Triage: off unless an agent is named; answers within 5 minutes
+	triageTimeout, err := time.ParseDuration(getEnv("TRIAGE_TIMEOUT", "5m"))
+	if err != nil || triageTimeout <= 0 {
+		triageTimeout = 5 * time.Minute
+	}
+
+	// Standup: agents asked for their status have 2 minutes to answer
+	standupAskTimeout, err := time.ParseDuration(getEnv("STANDUP_ASK_TIMEOUT", "2m"))
+	if err != nil || standupAskTimeout <= 0 {
+		standupAskTimeout = 2 * time.Minute
+	}
+
+	// mDNS: not advertised unless enabled, as Mission Control on this host
+	mdnsName := getEnv("MDNS_NAME", fmt.Sprintf("Mission Control (%s)", hostname))
+
 	return &Config{
 		Port:                   port,
 		Host:                   getEnv("HOST", "0.0.0.0"),
-		DatabasePath:           getEnv("DATABASE_PATH", "./data/mission-control.db"),
+		DatabasePath:           databasePath,
 		OpenClawGatewayURL:     getEnv("OPENCLAW_GATEWAY_URL", "ws://127.0.0.1:18789"),
 		OpenClawGatewayToken:   getEnv("OPENCLAW_GATEWAY_TOKEN", ""),
 		OpenClawConfigPath:     getEnv("OPENCLAW_CONFIG_PATH", ""), // Empty = use default ~/.openclaw/openclaw.json
@@ -63,6 +325,79 @@ func Load() *Config {
 		WatchdogInterval:       watchdogInterval,
 		WatchdogStaleThreshold: watchdogStale,
 		WatchdogMaxRetries:     watchdogMaxRetries,
+		AgentRunEnabled:        agentRunEnabled,
+		AgentRunMaxTimeout:     agentRunMaxTimeout,
+		NotifyDryRun:           getEnv("NOTIFY_DRY_RUN", "false") == "true",
+		NotifyDedupeWindow:     notifyDedupeWindow,
+		NotifyMaxMessageSize:   notifyMaxMessageSize,
+		NotifyTemplatesDir:     getEnv("NOTIFY_TEMPLATES_DIR", ""),
+		IdentityGenTimeout:     identityGenTimeout,
+		AgentTemplatesDir:      getEnv("AGENT_TEMPLATES_DIR", ""),
+		DefaultLocale:          getEnv("DEFAULT_LOCALE", "en"),
+		AgentHeartbeatTTL:      agentHeartbeatTTL,
+		AgentRegistrationToken: getEnv("AGENT_REGISTRATION_TOKEN", ""),
+		SecretsKey:             getEnv("SECRETS_KEY", ""),
+		DelegationMaxDepth:     delegationMaxDepth,
+		DelegationMaxSubtasks:  delegationMaxSubtasks,
+		RateLimitCooldown:      rateLimitCooldown,
+		ModelProbeInterval:     modelProbeInterval,
+		ModelProbeTimeout:      modelProbeTimeout,
+		ModelProbeSlow:         modelProbeSlow,
+		ScorecardWindow:        scorecardWindow,
+		CalendarToken:          getEnv("CALENDAR_TOKEN", ""),
+		JiraURL:                getEnv("JIRA_URL", ""),
+		JiraEmail:              getEnv("JIRA_EMAIL", ""),
+		JiraAPIToken:           getEnv("JIRA_API_TOKEN", ""),
+		JiraSyncInterval:       jiraSyncInterval,
+		GitHubToken:            getEnv("GITHUB_TOKEN", ""),
+		GitHubAPIURL:           getEnv("GITHUB_API_URL", "https://api.github.com"),
+		GitHubWebhookSecret:    getEnv("GITHUB_WEBHOOK_SECRET", ""),
+		EmailInboundToken:      getEnv("EMAIL_INBOUND_TOKEN", ""),
+		EmailInboundAddress:    getEnv("EMAIL_INBOUND_ADDRESS", ""),
+		EmailAllowedSenders:    getEnv("EMAIL_ALLOWED_SENDERS", ""),
+		EmailProjectID:         getEnv("EMAIL_PROJECT_ID", ""),
+		TelegramBotToken:       getEnv("TELEGRAM_BOT_TOKEN", ""),
+		TelegramChatIDs:        getEnv("TELEGRAM_CHAT_IDS", ""),
+		TelegramAPIURL:         getEnv("TELEGRAM_API_URL", ""),
+		MCPPort:                mcpPort,
+		GRPCPort:               grpcPort,
+		EventArchiveDir:        getEnv("EVENT_ARCHIVE_DIR", ""),
+		EventArchiveMaxSizeMB:  eventArchiveMaxSize,
+		EventArchiveURL:        getEnv("EVENT_ARCHIVE_URL", ""),
+		EventArchiveToken:      getEnv("EVENT_ARCHIVE_TOKEN", ""),
+		AnalyticsExportEnabled: getEnv("ANALYTICS_EXPORT_ENABLED", "false") == "true",
+		AnalyticsExportTime:    getEnv("ANALYTICS_EXPORT_TIME", "02:00"),
+		DBOptimizeInterval:     dbOptimizeInterval,
+		TrashRetention:         trashRetention,
+		S3AccessKey:            getEnv("S3_ACCESS_KEY_ID", ""),
+		S3SecretKey:            getEnv("S3_SECRET_ACCESS_KEY", ""),
+		InstanceID:             instanceID,
+		LeaderLeaseTTL:         leaderLeaseTTL,
+		BrokerURL:              getEnv("BROKER_URL", ""),
+		BasePath:               basePath,
+		TLSCertFile:            tlsCertFile,
+		TLSKeyFile:             tlsKeyFile,
+		TLSSelfSigned:          tlsSelfSigned,
+		HTTPRedirectPort:       httpRedirectPort,
+		MDNSEnabled:            getEnv("MDNS_ENABLED", "false") == "true",
+		MDNSName:               mdnsName,
+		MaxBodySize:            maxBodySize,
+		MaxUploadSize:          maxUploadSize,
+		HTTPReadTimeout:        httpReadTimeout,
+		HTTPWriteTimeout:       httpWriteTimeout,
+		HTTPIdleTimeout:        httpIdleTimeout,
+		PublicAPIURL:           publicAPIURL,
+		UpdateCheckEnabled:     getEnv("UPDATE_CHECK_ENABLED", "false") == "true",
+		UpdateCheckRepo:        getEnv("UPDATE_CHECK_REPO", "abelkuruvilla/claw-agent-mission-control"),
+		UpdateCheckInterval:    updateCheckInterval,
+		HooksFile:              getEnv("HOOKS_FILE", ""),
+		TriageAgentID:          getEnv("TRIAGE_AGENT_ID", ""),
+		TriageTimeout:          triageTimeout,
+		TriageAutoApply:        getEnv("TRIAGE_AUTO_APPLY", "false") == "true",
+		StandupEnabled:         getEnv("STANDUP_ENABLED", "false") == "true",
+		StandupTime:            getEnv("STANDUP_TIME", "09:00"),
+		StandupAskAgents:       getEnv("STANDUP_ASK_AGENTS", "false") == "true",
+		StandupAskTimeout:      standupAskTimeout,
 	}
 }
 
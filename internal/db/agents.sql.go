@@ -8,12 +8,13 @@ package db
 import (
 	"context"
 	"database/sql"
+	"strings"
 )
 
 const createAgent = `-- name: CreateAgent :one
 INSERT INTO agents (id, name, description, status, workspace_path, agent_dir_path, model, mention_patterns, soul_md, agents_md, identity_md, user_md, tools_md, heartbeat_md, memory_md)
 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-RETURNING id, name, description, status, workspace_path, agent_dir_path, model, mention_patterns, soul_md, agents_md, identity_md, user_md, tools_md, heartbeat_md, memory_md, active_session_key, current_task_id, created_at, updated_at
+RETURNING id, name, description, status, workspace_path, agent_dir_path, model, mention_patterns, soul_md, agents_md, identity_md, user_md, tools_md, heartbeat_md, memory_md, active_session_key, current_task_id, created_at, updated_at, locale, working_hours, managed_externally, callback_url, delivery_method, callback_secret, gateway_id, notification_prefs, timezone, notification_timeouts, public_api_url
 `
 
 type CreateAgentParams struct {
@@ -73,6 +74,17 @@ func (q *Queries) CreateAgent(ctx context.Context, arg CreateAgentParams) (Agent
 		&i.CurrentTaskID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Locale,
+		&i.WorkingHours,
+		&i.ManagedExternally,
+		&i.CallbackURL,
+		&i.DeliveryMethod,
+		&i.CallbackSecret,
+		&i.GatewayID,
+		&i.NotificationPrefs,
+		&i.Timezone,
+		&i.NotificationTimeouts,
+		&i.PublicAPIURL,
 	)
 	return i, err
 }
@@ -87,7 +99,7 @@ func (q *Queries) DeleteAgent(ctx context.Context, id string) error {
 }
 
 const getAgent = `-- name: GetAgent :one
-SELECT id, name, description, status, workspace_path, agent_dir_path, model, mention_patterns, soul_md, agents_md, identity_md, user_md, tools_md, heartbeat_md, memory_md, active_session_key, current_task_id, created_at, updated_at FROM agents WHERE id = ? LIMIT 1
+SELECT id, name, description, status, workspace_path, agent_dir_path, model, mention_patterns, soul_md, agents_md, identity_md, user_md, tools_md, heartbeat_md, memory_md, active_session_key, current_task_id, created_at, updated_at, locale, working_hours, managed_externally, callback_url, delivery_method, callback_secret, gateway_id, notification_prefs, timezone, notification_timeouts, public_api_url FROM agents WHERE id = ? LIMIT 1
 `
 
 func (q *Queries) GetAgent(ctx context.Context, id string) (Agent, error) {
@@ -113,12 +125,23 @@ func (q *Queries) GetAgent(ctx context.Context, id string) (Agent, error) {
 		&i.CurrentTaskID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Locale,
+		&i.WorkingHours,
+		&i.ManagedExternally,
+		&i.CallbackURL,
+		&i.DeliveryMethod,
+		&i.CallbackSecret,
+		&i.GatewayID,
+		&i.NotificationPrefs,
+		&i.Timezone,
+		&i.NotificationTimeouts,
+		&i.PublicAPIURL,
 	)
 	return i, err
 }
 
 const listAgents = `-- name: ListAgents :many
-SELECT id, name, description, status, workspace_path, agent_dir_path, model, mention_patterns, soul_md, agents_md, identity_md, user_md, tools_md, heartbeat_md, memory_md, active_session_key, current_task_id, created_at, updated_at FROM agents ORDER BY created_at DESC
+SELECT id, name, description, status, workspace_path, agent_dir_path, model, mention_patterns, soul_md, agents_md, identity_md, user_md, tools_md, heartbeat_md, memory_md, active_session_key, current_task_id, created_at, updated_at, locale, working_hours, managed_externally, callback_url, delivery_method, callback_secret, gateway_id, notification_prefs, timezone, notification_timeouts, public_api_url FROM agents ORDER BY created_at DESC
 `
 
 func (q *Queries) ListAgents(ctx context.Context) ([]Agent, error) {
@@ -150,6 +173,17 @@ func (q *Queries) ListAgents(ctx context.Context) ([]Agent, error) {
 			&i.CurrentTaskID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Locale,
+			&i.WorkingHours,
+			&i.ManagedExternally,
+			&i.CallbackURL,
+			&i.DeliveryMethod,
+			&i.CallbackSecret,
+			&i.GatewayID,
+			&i.NotificationPrefs,
+			&i.Timezone,
+			&i.NotificationTimeouts,
+			&i.PublicAPIURL,
 		); err != nil {
 			return nil, err
 		}
@@ -164,12 +198,108 @@ func (q *Queries) ListAgents(ctx context.Context) ([]Agent, error) {
 	return items, nil
 }
 
+const listAgentsByIDs = `-- name: ListAgentsByIDs :many
+SELECT id, name, description, status, workspace_path, agent_dir_path, model, mention_patterns, soul_md, agents_md, identity_md, user_md, tools_md, heartbeat_md, memory_md, active_session_key, current_task_id, created_at, updated_at, locale, working_hours, managed_externally, callback_url, delivery_method, callback_secret, gateway_id, notification_prefs, timezone, notification_timeouts, public_api_url FROM agents WHERE id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) ListAgentsByIDs(ctx context.Context, ids []string) ([]Agent, error) {
+	query := listAgentsByIDs
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Agent{}
+	for rows.Next() {
+		var i Agent
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.Status,
+			&i.WorkspacePath,
+			&i.AgentDirPath,
+			&i.Model,
+			&i.MentionPatterns,
+			&i.SoulMd,
+			&i.AgentsMd,
+			&i.IdentityMd,
+			&i.UserMd,
+			&i.ToolsMd,
+			&i.HeartbeatMd,
+			&i.MemoryMd,
+			&i.ActiveSessionKey,
+			&i.CurrentTaskID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Locale,
+			&i.WorkingHours,
+			&i.ManagedExternally,
+			&i.CallbackURL,
+			&i.DeliveryMethod,
+			&i.CallbackSecret,
+			&i.GatewayID,
+			&i.NotificationPrefs,
+			&i.Timezone,
+			&i.NotificationTimeouts,
+			&i.PublicAPIURL,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setAgentExternal = `-- name: SetAgentExternal :exec
+UPDATE agents SET managed_externally = TRUE, callback_url = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+`
+
+type SetAgentExternalParams struct {
+	CallbackURL sql.NullString `json:"callback_url"`
+	ID          string         `json:"id"`
+}
+
+func (q *Queries) SetAgentExternal(ctx context.Context, arg SetAgentExternalParams) error {
+	_, err := q.db.ExecContext(ctx, setAgentExternal, arg.CallbackURL, arg.ID)
+	return err
+}
+
+const setAgentGateway = `-- name: SetAgentGateway :exec
+UPDATE agents SET gateway_id = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+`
+
+type SetAgentGatewayParams struct {
+	GatewayID sql.NullString `json:"gateway_id"`
+	ID        string         `json:"id"`
+}
+
+func (q *Queries) SetAgentGateway(ctx context.Context, arg SetAgentGatewayParams) error {
+	_, err := q.db.ExecContext(ctx, setAgentGateway, arg.GatewayID, arg.ID)
+	return err
+}
+
 const updateAgent = `-- name: UpdateAgent :one
 UPDATE agents SET 
     name = ?, description = ?, status = ?, model = ?, mention_patterns = ?,
     soul_md = ?, agents_md = ?, identity_md = ?, user_md = ?, tools_md = ?, heartbeat_md = ?,
     active_session_key = ?, current_task_id = ?, updated_at = CURRENT_TIMESTAMP
-WHERE id = ? RETURNING id, name, description, status, workspace_path, agent_dir_path, model, mention_patterns, soul_md, agents_md, identity_md, user_md, tools_md, heartbeat_md, memory_md, active_session_key, current_task_id, created_at, updated_at
+WHERE id = ? RETURNING id, name, description, status, workspace_path, agent_dir_path, model, mention_patterns, soul_md, agents_md, identity_md, user_md, tools_md, heartbeat_md, memory_md, active_session_key, current_task_id, created_at, updated_at, locale, working_hours, managed_externally, callback_url, delivery_method, callback_secret, gateway_id, notification_prefs, timezone, notification_timeouts, public_api_url
 `
 
 type UpdateAgentParams struct {
@@ -227,10 +357,112 @@ func (q *Queries) UpdateAgent(ctx context.Context, arg UpdateAgentParams) (Agent
 		&i.CurrentTaskID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Locale,
+		&i.WorkingHours,
+		&i.ManagedExternally,
+		&i.CallbackURL,
+		&i.DeliveryMethod,
+		&i.CallbackSecret,
+		&i.GatewayID,
+		&i.NotificationPrefs,
+		&i.Timezone,
+		&i.NotificationTimeouts,
+		&i.PublicAPIURL,
 	)
 	return i, err
 }
 
+const updateAgentDelivery = `-- name: UpdateAgentDelivery :exec
+UPDATE agents SET delivery_method = ?, callback_url = ?, callback_secret = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+`
+
+type UpdateAgentDeliveryParams struct {
+	DeliveryMethod sql.NullString `json:"delivery_method"`
+	CallbackURL    sql.NullString `json:"callback_url"`
+	CallbackSecret sql.NullString `json:"callback_secret"`
+	ID             string         `json:"id"`
+}
+
+func (q *Queries) UpdateAgentDelivery(ctx context.Context, arg UpdateAgentDeliveryParams) error {
+	_, err := q.db.ExecContext(ctx, updateAgentDelivery,
+		arg.DeliveryMethod,
+		arg.CallbackURL,
+		arg.CallbackSecret,
+		arg.ID,
+	)
+	return err
+}
+
+const updateAgentLocale = `-- name: UpdateAgentLocale :exec
+UPDATE agents SET locale = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+`
+
+type UpdateAgentLocaleParams struct {
+	Locale sql.NullString `json:"locale"`
+	ID     string         `json:"id"`
+}
+
+func (q *Queries) UpdateAgentLocale(ctx context.Context, arg UpdateAgentLocaleParams) error {
+	_, err := q.db.ExecContext(ctx, updateAgentLocale, arg.Locale, arg.ID)
+	return err
+}
+
+const updateAgentMemory = `-- name: UpdateAgentMemory :exec
+UPDATE agents SET memory_md = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+`
+
+type UpdateAgentMemoryParams struct {
+	MemoryMd sql.NullString `json:"memory_md"`
+	ID       string         `json:"id"`
+}
+
+func (q *Queries) UpdateAgentMemory(ctx context.Context, arg UpdateAgentMemoryParams) error {
+	_, err := q.db.ExecContext(ctx, updateAgentMemory, arg.MemoryMd, arg.ID)
+	return err
+}
+
+const updateAgentNotificationPrefs = `-- name: UpdateAgentNotificationPrefs :exec
+UPDATE agents SET notification_prefs = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+`
+
+type UpdateAgentNotificationPrefsParams struct {
+	NotificationPrefs sql.NullString `json:"notification_prefs"`
+	ID                string         `json:"id"`
+}
+
+func (q *Queries) UpdateAgentNotificationPrefs(ctx context.Context, arg UpdateAgentNotificationPrefsParams) error {
+	_, err := q.db.ExecContext(ctx, updateAgentNotificationPrefs, arg.NotificationPrefs, arg.ID)
+	return err
+}
+
+const updateAgentNotificationTimeouts = `-- name: UpdateAgentNotificationTimeouts :exec
+UPDATE agents SET notification_timeouts = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+`
+
+type UpdateAgentNotificationTimeoutsParams struct {
+	NotificationTimeouts sql.NullString `json:"notification_timeouts"`
+	ID                   string         `json:"id"`
+}
+
+func (q *Queries) UpdateAgentNotificationTimeouts(ctx context.Context, arg UpdateAgentNotificationTimeoutsParams) error {
+	_, err := q.db.ExecContext(ctx, updateAgentNotificationTimeouts, arg.NotificationTimeouts, arg.ID)
+	return err
+}
+
+const updateAgentPublicAPIURL = `-- name: UpdateAgentPublicAPIURL :exec
+UPDATE agents SET public_api_url = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+`
+
+type UpdateAgentPublicAPIURLParams struct {
+	PublicAPIURL sql.NullString `json:"public_api_url"`
+	ID           string         `json:"id"`
+}
+
+func (q *Queries) UpdateAgentPublicAPIURL(ctx context.Context, arg UpdateAgentPublicAPIURLParams) error {
+	_, err := q.db.ExecContext(ctx, updateAgentPublicAPIURL, arg.PublicAPIURL, arg.ID)
+	return err
+}
+
 const updateAgentStatus = `-- name: UpdateAgentStatus :exec
 UPDATE agents SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
 `
@@ -244,3 +476,31 @@ func (q *Queries) UpdateAgentStatus(ctx context.Context, arg UpdateAgentStatusPa
 	_, err := q.db.ExecContext(ctx, updateAgentStatus, arg.Status, arg.ID)
 	return err
 }
+
+const updateAgentTimezone = `-- name: UpdateAgentTimezone :exec
+UPDATE agents SET timezone = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+`
+
+type UpdateAgentTimezoneParams struct {
+	Timezone sql.NullString `json:"timezone"`
+	ID       string         `json:"id"`
+}
+
+func (q *Queries) UpdateAgentTimezone(ctx context.Context, arg UpdateAgentTimezoneParams) error {
+	_, err := q.db.ExecContext(ctx, updateAgentTimezone, arg.Timezone, arg.ID)
+	return err
+}
+
+const updateAgentWorkingHours = `-- name: UpdateAgentWorkingHours :exec
+UPDATE agents SET working_hours = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+`
+
+type UpdateAgentWorkingHoursParams struct {
+	WorkingHours sql.NullString `json:"working_hours"`
+	ID           string         `json:"id"`
+}
+
+func (q *Queries) UpdateAgentWorkingHours(ctx context.Context, arg UpdateAgentWorkingHoursParams) error {
+	_, err := q.db.ExecContext(ctx, updateAgentWorkingHours, arg.WorkingHours, arg.ID)
+	return err
+}
@@ -7,19 +7,38 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
+const countCommentsByTask = `-- name: CountCommentsByTask :one
+SELECT COUNT(*) AS count FROM comments
+WHERE task_id = ?1 AND (CAST(?2 AS TEXT) = '' OR author = ?2)
+`
+
+type CountCommentsByTaskParams struct {
+	TaskID string `json:"task_id"`
+	Author string `json:"author"`
+}
+
+func (q *Queries) CountCommentsByTask(ctx context.Context, arg CountCommentsByTaskParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countCommentsByTask, arg.TaskID, arg.Author)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createComment = `-- name: CreateComment :one
-INSERT INTO comments (id, task_id, author, content)
-VALUES (?, ?, ?, ?)
-RETURNING id, task_id, author, content, created_at
+INSERT INTO comments (id, task_id, author, content, preview)
+VALUES (?, ?, ?, ?, ?)
+RETURNING id, task_id, author, content, created_at, preview
 `
 
 type CreateCommentParams struct {
-	ID      string `json:"id"`
-	TaskID  string `json:"task_id"`
-	Author  string `json:"author"`
-	Content string `json:"content"`
+	ID      string         `json:"id"`
+	TaskID  string         `json:"task_id"`
+	Author  string         `json:"author"`
+	Content string         `json:"content"`
+	Preview sql.NullString `json:"preview"`
 }
 
 func (q *Queries) CreateComment(ctx context.Context, arg CreateCommentParams) (Comment, error) {
@@ -28,6 +47,7 @@ func (q *Queries) CreateComment(ctx context.Context, arg CreateCommentParams) (C
 		arg.TaskID,
 		arg.Author,
 		arg.Content,
+		arg.Preview,
 	)
 	var i Comment
 	err := row.Scan(
@@ -36,6 +56,7 @@ func (q *Queries) CreateComment(ctx context.Context, arg CreateCommentParams) (C
 		&i.Author,
 		&i.Content,
 		&i.CreatedAt,
+		&i.Preview,
 	)
 	return i, err
 }
@@ -50,7 +71,7 @@ func (q *Queries) DeleteComment(ctx context.Context, id string) error {
 }
 
 const getComment = `-- name: GetComment :one
-SELECT id, task_id, author, content, created_at FROM comments WHERE id = ? LIMIT 1
+SELECT id, task_id, author, content, created_at, preview FROM comments WHERE id = ? LIMIT 1
 `
 
 func (q *Queries) GetComment(ctx context.Context, id string) (Comment, error) {
@@ -62,12 +83,13 @@ func (q *Queries) GetComment(ctx context.Context, id string) (Comment, error) {
 		&i.Author,
 		&i.Content,
 		&i.CreatedAt,
+		&i.Preview,
 	)
 	return i, err
 }
 
 const listCommentsByTask = `-- name: ListCommentsByTask :many
-SELECT id, task_id, author, content, created_at FROM comments WHERE task_id = ? ORDER BY created_at ASC
+SELECT id, task_id, author, content, created_at, preview FROM comments WHERE task_id = ? ORDER BY created_at ASC
 `
 
 func (q *Queries) ListCommentsByTask(ctx context.Context, taskID string) ([]Comment, error) {
@@ -85,6 +107,105 @@ func (q *Queries) ListCommentsByTask(ctx context.Context, taskID string) ([]Comm
 			&i.Author,
 			&i.Content,
 			&i.CreatedAt,
+			&i.Preview,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCommentsByTaskAsc = `-- name: ListCommentsByTaskAsc :many
+SELECT id, task_id, author, content, created_at, preview FROM comments
+WHERE task_id = ?1 AND (CAST(?2 AS TEXT) = '' OR author = ?2)
+ORDER BY created_at ASC, rowid ASC
+LIMIT ?3 OFFSET ?4
+`
+
+type ListCommentsByTaskAscParams struct {
+	TaskID string `json:"task_id"`
+	Author string `json:"author"`
+	Limit  int64  `json:"limit"`
+	Offset int64  `json:"offset"`
+}
+
+func (q *Queries) ListCommentsByTaskAsc(ctx context.Context, arg ListCommentsByTaskAscParams) ([]Comment, error) {
+	rows, err := q.db.QueryContext(ctx, listCommentsByTaskAsc,
+		arg.TaskID,
+		arg.Author,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Comment{}
+	for rows.Next() {
+		var i Comment
+		if err := rows.Scan(
+			&i.ID,
+			&i.TaskID,
+			&i.Author,
+			&i.Content,
+			&i.CreatedAt,
+			&i.Preview,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCommentsByTaskDesc = `-- name: ListCommentsByTaskDesc :many
+SELECT id, task_id, author, content, created_at, preview FROM comments
+WHERE task_id = ?1 AND (CAST(?2 AS TEXT) = '' OR author = ?2)
+ORDER BY created_at DESC, rowid DESC
+LIMIT ?3 OFFSET ?4
+`
+
+type ListCommentsByTaskDescParams struct {
+	TaskID string `json:"task_id"`
+	Author string `json:"author"`
+	Limit  int64  `json:"limit"`
+	Offset int64  `json:"offset"`
+}
+
+func (q *Queries) ListCommentsByTaskDesc(ctx context.Context, arg ListCommentsByTaskDescParams) ([]Comment, error) {
+	rows, err := q.db.QueryContext(ctx, listCommentsByTaskDesc,
+		arg.TaskID,
+		arg.Author,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Comment{}
+	for rows.Next() {
+		var i Comment
+		if err := rows.Scan(
+			&i.ID,
+			&i.TaskID,
+			&i.Author,
+			&i.Content,
+			&i.CreatedAt,
+			&i.Preview,
 		); err != nil {
 			return nil, err
 		}
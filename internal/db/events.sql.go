@@ -8,12 +8,56 @@ package db
 import (
 	"context"
 	"database/sql"
+	"strings"
 )
 
+const countEventsByAgent = `-- name: CountEventsByAgent :many
+SELECT agent_id, COUNT(*) AS count
+FROM events
+WHERE type = ? AND agent_id IS NOT NULL AND agent_id != '' AND created_at >= ?
+GROUP BY agent_id
+`
+
+type CountEventsByAgentParams struct {
+	Type  string       `json:"type"`
+	Since sql.NullTime `json:"since"`
+}
+
+type CountEventsByAgentRow struct {
+	AgentID sql.NullString `json:"agent_id"`
+	Count   int64          `json:"count"`
+}
+
+func (q *Queries) CountEventsByAgent(ctx context.Context, arg CountEventsByAgentParams) ([]CountEventsByAgentRow, error) {
+	rows, err := q.db.QueryContext(ctx, countEventsByAgent, arg.Type, arg.Since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CountEventsByAgentRow{}
+	for rows.Next() {
+		var i CountEventsByAgentRow
+		if err := rows.Scan(
+			&i.AgentID,
+			&i.Count,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createEvent = `-- name: CreateEvent :one
-INSERT INTO events (id, task_id, agent_id, type, message, details)
-VALUES (?, ?, ?, ?, ?, ?)
-RETURNING id, task_id, agent_id, type, message, details, created_at
+INSERT INTO events (id, task_id, agent_id, type, message, details, seq)
+VALUES (?, ?, ?, ?, ?, ?, (SELECT COALESCE(MAX(seq), 0) + 1 FROM events))
+RETURNING id, task_id, agent_id, type, message, details, created_at, seq
 `
 
 type CreateEventParams struct {
@@ -43,12 +87,42 @@ func (q *Queries) CreateEvent(ctx context.Context, arg CreateEventParams) (Event
 		&i.Message,
 		&i.Details,
 		&i.CreatedAt,
+		&i.Seq,
 	)
 	return i, err
 }
 
+const detachAgentEvents = `-- name: DetachAgentEvents :exec
+UPDATE events SET agent_id = NULL WHERE agent_id = ?
+`
+
+func (q *Queries) DetachAgentEvents(ctx context.Context, agentID sql.NullString) error {
+	_, err := q.db.ExecContext(ctx, detachAgentEvents, agentID)
+	return err
+}
+
+const detachProjectTaskEvents = `-- name: DetachProjectTaskEvents :exec
+UPDATE events SET task_id = NULL WHERE task_id IN (SELECT id FROM tasks WHERE project_id = ?)
+`
+
+func (q *Queries) DetachProjectTaskEvents(ctx context.Context, projectID sql.NullString) error {
+	_, err := q.db.ExecContext(ctx, detachProjectTaskEvents, projectID)
+	return err
+}
+
+const getLatestEventSeq = `-- name: GetLatestEventSeq :one
+SELECT CAST(COALESCE(MAX(seq), 0) AS INTEGER) AS seq FROM events
+`
+
+func (q *Queries) GetLatestEventSeq(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getLatestEventSeq)
+	var seq int64
+	err := row.Scan(&seq)
+	return seq, err
+}
+
 const listEvents = `-- name: ListEvents :many
-SELECT id, task_id, agent_id, type, message, details, created_at FROM events ORDER BY created_at DESC LIMIT ?
+SELECT id, task_id, agent_id, type, message, details, created_at, seq FROM events ORDER BY created_at DESC LIMIT ?
 `
 
 func (q *Queries) ListEvents(ctx context.Context, limit int64) ([]Event, error) {
@@ -68,6 +142,59 @@ func (q *Queries) ListEvents(ctx context.Context, limit int64) ([]Event, error)
 			&i.Message,
 			&i.Details,
 			&i.CreatedAt,
+			&i.Seq,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEventsAfter = `-- name: ListEventsAfter :many
+SELECT seq, id, task_id, agent_id, type, message, details, created_at FROM events WHERE seq > ? ORDER BY seq LIMIT ?
+`
+
+type ListEventsAfterParams struct {
+	Seq   int64 `json:"seq"`
+	Limit int64 `json:"limit"`
+}
+
+type ListEventsAfterRow struct {
+	Seq       int64          `json:"seq"`
+	ID        string         `json:"id"`
+	TaskID    sql.NullString `json:"task_id"`
+	AgentID   sql.NullString `json:"agent_id"`
+	Type      string         `json:"type"`
+	Message   string         `json:"message"`
+	Details   sql.NullString `json:"details"`
+	CreatedAt sql.NullTime   `json:"created_at"`
+}
+
+func (q *Queries) ListEventsAfter(ctx context.Context, arg ListEventsAfterParams) ([]ListEventsAfterRow, error) {
+	rows, err := q.db.QueryContext(ctx, listEventsAfter, arg.Seq, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListEventsAfterRow{}
+	for rows.Next() {
+		var i ListEventsAfterRow
+		if err := rows.Scan(
+			&i.Seq,
+			&i.ID,
+			&i.TaskID,
+			&i.AgentID,
+			&i.Type,
+			&i.Message,
+			&i.Details,
+			&i.CreatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -83,7 +210,7 @@ func (q *Queries) ListEvents(ctx context.Context, limit int64) ([]Event, error)
 }
 
 const listEventsByAgent = `-- name: ListEventsByAgent :many
-SELECT id, task_id, agent_id, type, message, details, created_at FROM events WHERE agent_id = ? ORDER BY created_at DESC LIMIT ?
+SELECT id, task_id, agent_id, type, message, details, created_at, seq FROM events WHERE agent_id = ? ORDER BY created_at DESC LIMIT ?
 `
 
 type ListEventsByAgentParams struct {
@@ -108,6 +235,7 @@ func (q *Queries) ListEventsByAgent(ctx context.Context, arg ListEventsByAgentPa
 			&i.Message,
 			&i.Details,
 			&i.CreatedAt,
+			&i.Seq,
 		); err != nil {
 			return nil, err
 		}
@@ -123,7 +251,7 @@ func (q *Queries) ListEventsByAgent(ctx context.Context, arg ListEventsByAgentPa
 }
 
 const listEventsByTask = `-- name: ListEventsByTask :many
-SELECT id, task_id, agent_id, type, message, details, created_at FROM events WHERE task_id = ? ORDER BY created_at DESC LIMIT ?
+SELECT id, task_id, agent_id, type, message, details, created_at, seq FROM events WHERE task_id = ? ORDER BY created_at DESC LIMIT ?
 `
 
 type ListEventsByTaskParams struct {
@@ -148,6 +276,199 @@ func (q *Queries) ListEventsByTask(ctx context.Context, arg ListEventsByTaskPara
 			&i.Message,
 			&i.Details,
 			&i.CreatedAt,
+			&i.Seq,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEventsByTaskIDs = `-- name: ListEventsByTaskIDs :many
+SELECT id, task_id, agent_id, type, message, details, created_at, seq FROM events WHERE task_id IN (/*SLICE:task_ids*/?) ORDER BY created_at DESC
+`
+
+func (q *Queries) ListEventsByTaskIDs(ctx context.Context, taskIds []sql.NullString) ([]Event, error) {
+	query := listEventsByTaskIDs
+	var queryParams []interface{}
+	if len(taskIds) > 0 {
+		for _, v := range taskIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:task_ids*/?", strings.Repeat(",?", len(taskIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:task_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Event{}
+	for rows.Next() {
+		var i Event
+		if err := rows.Scan(
+			&i.ID,
+			&i.TaskID,
+			&i.AgentID,
+			&i.Type,
+			&i.Message,
+			&i.Details,
+			&i.CreatedAt,
+			&i.Seq,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEventsFilteredAsc = `-- name: ListEventsFilteredAsc :many
+SELECT seq, id, task_id, agent_id, type, message, details, created_at
+FROM events
+WHERE seq > ?1 AND seq < ?2
+  AND (CAST(?3 AS TEXT) = '' OR task_id = ?3)
+  AND (CAST(?4 AS TEXT) = '' OR agent_id = ?4)
+  AND (CAST(?5 AS TEXT) = '' OR instr(',' || ?5 || ',', ',' || type || ',') > 0)
+  AND created_at >= CAST(?6 AS TEXT)
+ORDER BY seq ASC
+LIMIT ?7
+`
+
+type ListEventsFilteredAscParams struct {
+	After   int64  `json:"after"`
+	Before  int64  `json:"before"`
+	TaskID  string `json:"task_id"`
+	AgentID string `json:"agent_id"`
+	Types   string `json:"types"`
+	Since   string `json:"since"`
+	Limit   int64  `json:"limit"`
+}
+
+type ListEventsFilteredAscRow struct {
+	Seq       int64          `json:"seq"`
+	ID        string         `json:"id"`
+	TaskID    sql.NullString `json:"task_id"`
+	AgentID   sql.NullString `json:"agent_id"`
+	Type      string         `json:"type"`
+	Message   string         `json:"message"`
+	Details   sql.NullString `json:"details"`
+	CreatedAt sql.NullTime   `json:"created_at"`
+}
+
+func (q *Queries) ListEventsFilteredAsc(ctx context.Context, arg ListEventsFilteredAscParams) ([]ListEventsFilteredAscRow, error) {
+	rows, err := q.db.QueryContext(ctx, listEventsFilteredAsc,
+		arg.After,
+		arg.Before,
+		arg.TaskID,
+		arg.AgentID,
+		arg.Types,
+		arg.Since,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListEventsFilteredAscRow{}
+	for rows.Next() {
+		var i ListEventsFilteredAscRow
+		if err := rows.Scan(
+			&i.Seq,
+			&i.ID,
+			&i.TaskID,
+			&i.AgentID,
+			&i.Type,
+			&i.Message,
+			&i.Details,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEventsFilteredDesc = `-- name: ListEventsFilteredDesc :many
+SELECT seq, id, task_id, agent_id, type, message, details, created_at
+FROM events
+WHERE seq > ?1 AND seq < ?2
+  AND (CAST(?3 AS TEXT) = '' OR task_id = ?3)
+  AND (CAST(?4 AS TEXT) = '' OR agent_id = ?4)
+  AND (CAST(?5 AS TEXT) = '' OR instr(',' || ?5 || ',', ',' || type || ',') > 0)
+  AND created_at >= CAST(?6 AS TEXT)
+ORDER BY seq DESC
+LIMIT ?7
+`
+
+type ListEventsFilteredDescParams struct {
+	After   int64  `json:"after"`
+	Before  int64  `json:"before"`
+	TaskID  string `json:"task_id"`
+	AgentID string `json:"agent_id"`
+	Types   string `json:"types"`
+	Since   string `json:"since"`
+	Limit   int64  `json:"limit"`
+}
+
+type ListEventsFilteredDescRow struct {
+	Seq       int64          `json:"seq"`
+	ID        string         `json:"id"`
+	TaskID    sql.NullString `json:"task_id"`
+	AgentID   sql.NullString `json:"agent_id"`
+	Type      string         `json:"type"`
+	Message   string         `json:"message"`
+	Details   sql.NullString `json:"details"`
+	CreatedAt sql.NullTime   `json:"created_at"`
+}
+
+func (q *Queries) ListEventsFilteredDesc(ctx context.Context, arg ListEventsFilteredDescParams) ([]ListEventsFilteredDescRow, error) {
+	rows, err := q.db.QueryContext(ctx, listEventsFilteredDesc,
+		arg.After,
+		arg.Before,
+		arg.TaskID,
+		arg.AgentID,
+		arg.Types,
+		arg.Since,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListEventsFilteredDescRow{}
+	for rows.Next() {
+		var i ListEventsFilteredDescRow
+		if err := rows.Scan(
+			&i.Seq,
+			&i.ID,
+			&i.TaskID,
+			&i.AgentID,
+			&i.Type,
+			&i.Message,
+			&i.Details,
+			&i.CreatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,2 @@
+-- Preferred language for agent notifications (e.g. "es", "de"); NULL = server default
+ALTER TABLE agents ADD COLUMN locale TEXT;
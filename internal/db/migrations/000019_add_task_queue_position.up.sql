@@ -0,0 +1,2 @@
+-- Manual position within the agent's queue (1 = front); NULL = ordered by priority then FIFO
+ALTER TABLE tasks ADD COLUMN queue_position INTEGER;
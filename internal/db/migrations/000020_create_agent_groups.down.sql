@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_tasks_group_id;
+DROP INDEX IF EXISTS idx_agent_group_members_agent;
+DROP TABLE IF EXISTS agent_group_members;
+DROP TABLE IF EXISTS agent_groups;
+-- SQLite doesn't support DROP COLUMN in older versions
+-- tasks.group_id will remain but be unused
@@ -0,0 +1,22 @@
+-- Agent groups: tasks assigned to a group wait in a shared queue until a free member claims them
+CREATE TABLE IF NOT EXISTS agent_groups (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS agent_group_members (
+    group_id TEXT NOT NULL REFERENCES agent_groups(id) ON DELETE CASCADE,
+    agent_id TEXT NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (group_id, agent_id)
+);
+
+-- Group the task was assigned to; agent_id stays NULL until a member claims it
+ALTER TABLE tasks ADD COLUMN group_id TEXT REFERENCES agent_groups(id) ON DELETE SET NULL;
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_agent_group_members_agent ON agent_group_members(agent_id);
+CREATE INDEX IF NOT EXISTS idx_tasks_group_id ON tasks(group_id);
@@ -0,0 +1,6 @@
+-- How queued group tasks are pushed to free members: first_free | round_robin | least_loaded | skill_weighted
+ALTER TABLE agent_groups ADD COLUMN dispatch_strategy TEXT NOT NULL DEFAULT 'first_free';
+-- Last member chosen by dispatch; round_robin continues after it
+ALTER TABLE agent_groups ADD COLUMN last_dispatched_agent_id TEXT;
+-- Member skills used by skill_weighted dispatch (JSON array of keywords)
+ALTER TABLE agent_group_members ADD COLUMN skills TEXT;
@@ -0,0 +1,3 @@
+-- SQLite doesn't support DROP COLUMN in older versions
+-- These columns will remain but be unused
+DROP INDEX IF EXISTS idx_tasks_deferred_until;
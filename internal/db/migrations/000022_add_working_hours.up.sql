@@ -0,0 +1,5 @@
+-- Per-agent dispatch windows (JSON: timezone + weekly windows); NULL = always available
+ALTER TABLE agents ADD COLUMN working_hours TEXT;
+-- Set when dispatch was postponed until the assigned agent's next working window
+ALTER TABLE tasks ADD COLUMN deferred_until DATETIME;
+CREATE INDEX IF NOT EXISTS idx_tasks_deferred_until ON tasks(deferred_until);
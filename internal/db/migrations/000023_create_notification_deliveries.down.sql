@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_notification_deliveries_task;
+DROP INDEX IF EXISTS idx_notification_deliveries_status;
+DROP TABLE IF EXISTS notification_deliveries;
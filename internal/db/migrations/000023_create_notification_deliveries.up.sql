@@ -0,0 +1,20 @@
+-- Delivery state of agent notifications, one row per task transition reported.
+-- The watchdog re-sends rows that were never confirmed (e.g. the callback was
+-- lost on restart).
+CREATE TABLE IF NOT EXISTS notification_deliveries (
+    id TEXT PRIMARY KEY,
+    kind TEXT NOT NULL,                      -- subtask_result
+    task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE, -- task whose transition is reported
+    transition TEXT NOT NULL,                -- status reported, e.g. done | failed
+    agent_id TEXT NOT NULL,                  -- recipient
+    status TEXT NOT NULL DEFAULT 'pending',  -- pending | delivered | failed | abandoned
+    attempts INTEGER NOT NULL DEFAULT 1,
+    last_error TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    delivered_at DATETIME
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_status ON notification_deliveries(status, updated_at);
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_task ON notification_deliveries(task_id);
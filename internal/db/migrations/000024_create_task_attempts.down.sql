@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_task_attempts_task;
+DROP TABLE IF EXISTS task_attempts;
@@ -0,0 +1,17 @@
+-- Every attempt to get a task worked on: watchdog retries and resets, manual
+-- and scheduled retries, and each send to an agent with its outcome.
+CREATE TABLE IF NOT EXISTS task_attempts (
+    id TEXT PRIMARY KEY,
+    task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,                      -- send | watchdog_retry | watchdog_reset | manual_retry | scheduled_retry
+    agent_id TEXT,
+    reason TEXT,                             -- why the attempt was made
+    outcome TEXT NOT NULL DEFAULT 'pending', -- pending | succeeded | failed | deferred | queued | scheduled | reset
+    error TEXT,
+    retry_count INTEGER NOT NULL DEFAULT 0,  -- task retry_count when the attempt was made
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    finished_at DATETIME
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_task_attempts_task ON task_attempts(task_id, created_at);
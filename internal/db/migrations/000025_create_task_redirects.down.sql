@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_task_redirects_to;
+DROP TABLE IF EXISTS task_redirects;
@@ -0,0 +1,10 @@
+-- Tasks merged into another task. Looking up a merged task's ID leads to the
+-- task that absorbed it.
+CREATE TABLE IF NOT EXISTS task_redirects (
+    from_task_id TEXT PRIMARY KEY,
+    to_task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_task_redirects_to ON task_redirects(to_task_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_task_links_target;
+DROP INDEX IF EXISTS idx_task_links_source;
+DROP TABLE IF EXISTS task_links;
@@ -0,0 +1,14 @@
+-- References from one task to another, parsed from descriptions and comments.
+-- Read forward they are the task's links; read backward, its backlinks.
+CREATE TABLE IF NOT EXISTS task_links (
+    source_task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    target_task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    source_kind TEXT NOT NULL,  -- description | comment
+    source_id TEXT NOT NULL,    -- task ID for descriptions, comment ID for comments
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (source_kind, source_id, target_task_id)
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_task_links_source ON task_links(source_task_id);
+CREATE INDEX IF NOT EXISTS idx_task_links_target ON task_links(target_task_id);
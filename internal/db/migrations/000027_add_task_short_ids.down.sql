@@ -0,0 +1,4 @@
+-- SQLite doesn't support DROP COLUMN in older versions
+-- These columns will remain but be unused
+DROP INDEX IF EXISTS idx_tasks_short_id;
+DROP TABLE IF EXISTS task_sequences;
@@ -0,0 +1,20 @@
+-- Short project key used as the prefix of its tasks' short IDs (e.g. MC); NULL = default prefix
+ALTER TABLE projects ADD COLUMN key TEXT;
+-- Human-friendly task identifier such as MC-142, unique across all tasks
+ALTER TABLE tasks ADD COLUMN short_id TEXT;
+
+-- Last number handed out per short ID prefix. Projects sharing a key share its sequence.
+CREATE TABLE IF NOT EXISTS task_sequences (
+    prefix TEXT PRIMARY KEY,
+    last_number INTEGER NOT NULL DEFAULT 0
+);
+
+-- Number existing tasks in creation order under the default prefix
+UPDATE tasks SET short_id = 'MC-' || (
+    SELECT COUNT(*) FROM tasks t
+    WHERE t.created_at < tasks.created_at
+       OR (t.created_at = tasks.created_at AND t.rowid <= tasks.rowid)
+);
+INSERT INTO task_sequences (prefix, last_number) SELECT 'MC', COUNT(*) FROM tasks;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_short_id ON tasks(short_id);
@@ -0,0 +1,5 @@
+-- Agents that registered themselves over the API instead of coming from openclaw.json.
+-- Config sync leaves them alone.
+ALTER TABLE agents ADD COLUMN managed_externally BOOLEAN NOT NULL DEFAULT FALSE;
+-- Where a self-registered agent can be reached (e.g. https://host:9000/mission-control)
+ALTER TABLE agents ADD COLUMN callback_url TEXT;
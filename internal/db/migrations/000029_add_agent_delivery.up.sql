@@ -0,0 +1,4 @@
+-- How task notifications reach the agent: cli (default when NULL), gateway or http_callback
+ALTER TABLE agents ADD COLUMN delivery_method TEXT;
+-- Key http_callback deliveries are signed with (HMAC-SHA256)
+ALTER TABLE agents ADD COLUMN callback_secret TEXT;
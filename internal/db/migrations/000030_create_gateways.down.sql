@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_agents_gateway_id;
+DROP TABLE IF EXISTS gateways;
+-- SQLite doesn't support DROP COLUMN in older versions
+-- agents.gateway_id will remain but be unused
@@ -0,0 +1,14 @@
+-- OpenClaw gateways besides the default one from OPENCLAW_GATEWAY_URL (e.g. one per host)
+CREATE TABLE IF NOT EXISTS gateways (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    url TEXT NOT NULL,
+    token TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Gateway the agent lives on; NULL = the default gateway
+ALTER TABLE agents ADD COLUMN gateway_id TEXT REFERENCES gateways(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_agents_gateway_id ON agents(gateway_id);
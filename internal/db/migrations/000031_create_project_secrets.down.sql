@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS project_secrets;
+-- SQLite doesn't support DROP COLUMN in older versions
+-- tasks.secret_names will remain but be unused
@@ -0,0 +1,12 @@
+-- Project secrets vault: values are sealed with SECRETS_KEY and never returned by the API
+CREATE TABLE IF NOT EXISTS project_secrets (
+    project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    sealed_value TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (project_id, name)
+);
+
+-- JSON array of the project secret names injected into the task's assignment notification
+ALTER TABLE tasks ADD COLUMN secret_names TEXT;
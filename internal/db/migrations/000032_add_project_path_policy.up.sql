@@ -0,0 +1,4 @@
+-- Paths agents may touch in the project's tasks (JSON array of directories or globs); NULL = unrestricted
+ALTER TABLE projects ADD COLUMN allowed_paths TEXT;
+-- What a reported violation does: warn (default when NULL) or pause
+ALTER TABLE projects ADD COLUMN policy_action TEXT;
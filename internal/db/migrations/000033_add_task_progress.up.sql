@@ -0,0 +1,9 @@
+-- Task progress as a percentage (0-100); NULL until reported or derived
+ALTER TABLE tasks ADD COLUMN progress INTEGER;
+-- Set when progress was reported explicitly; otherwise it is derived from passed stories (or done phases)
+ALTER TABLE tasks ADD COLUMN progress_explicit BOOLEAN NOT NULL DEFAULT FALSE;
+-- Derive progress for tasks that already have stories
+UPDATE tasks SET progress = (
+    SELECT CAST(ROUND(100.0 * SUM(CASE WHEN passes THEN 1 ELSE 0 END) / COUNT(*)) AS INTEGER)
+    FROM stories WHERE stories.task_id = tasks.id
+) WHERE EXISTS (SELECT 1 FROM stories WHERE stories.task_id = tasks.id);
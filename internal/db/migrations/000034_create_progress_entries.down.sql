@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_progress_entries_task_id;
+DROP TABLE IF EXISTS progress_entries;
@@ -0,0 +1,14 @@
+-- Progress notes on a task, one row per report (replaces appending to tasks.progress_txt)
+CREATE TABLE IF NOT EXISTS progress_entries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT, -- increasing, so it orders entries and pages through them
+    task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    author TEXT, -- agent that reported it, if known
+    content TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_progress_entries_task_id ON progress_entries(task_id, id);
+
+-- Existing progress logs become a single entry each
+INSERT INTO progress_entries (task_id, content, created_at)
+SELECT id, progress_txt, updated_at FROM tasks WHERE progress_txt IS NOT NULL AND progress_txt != '';
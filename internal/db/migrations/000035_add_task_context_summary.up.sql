@@ -0,0 +1,4 @@
+-- Rolling summary of a task's progress and discussion, written by a
+-- summarization session and included in re-notifications
+ALTER TABLE tasks ADD COLUMN context_summary TEXT;
+ALTER TABLE tasks ADD COLUMN context_summarized_at DATETIME;
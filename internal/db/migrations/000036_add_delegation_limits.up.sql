@@ -0,0 +1,6 @@
+-- Delegation limits: how deep subtasks may nest below a task or project, and
+-- how many unfinished subtasks a task may have at once (NULL = no limit set)
+ALTER TABLE tasks ADD COLUMN max_subtask_depth INTEGER;
+ALTER TABLE tasks ADD COLUMN max_concurrent_subtasks INTEGER;
+ALTER TABLE projects ADD COLUMN max_subtask_depth INTEGER;
+ALTER TABLE projects ADD COLUMN max_concurrent_subtasks INTEGER;
@@ -0,0 +1,3 @@
+-- Why the task last failed or was reset (rate_limit, session_lock,
+-- test_failure, timeout, unknown), classified from its error text
+ALTER TABLE tasks ADD COLUMN failure_reason TEXT;
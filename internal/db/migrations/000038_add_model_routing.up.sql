@@ -0,0 +1,9 @@
+-- Routing policy mapping task priority and size to models (JSON, see
+-- internal/routing)
+ALTER TABLE settings ADD COLUMN model_routing TEXT;
+
+-- Model a task runs on regardless of the routing policy
+ALTER TABLE tasks ADD COLUMN model TEXT;
+
+-- Model chosen for the task when it was last dispatched
+ALTER TABLE tasks ADD COLUMN routed_model TEXT;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_experiment_tasks_experiment_id;
+DROP TABLE IF EXISTS experiment_tasks;
+DROP TABLE IF EXISTS experiments;
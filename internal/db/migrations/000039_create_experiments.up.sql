@@ -0,0 +1,26 @@
+-- A/B experiments: new tasks are randomly split between two arms, either two
+-- agents (kind 'agents', arms are agent IDs) or two task_assignment templates
+-- (kind 'templates', arms are template text)
+CREATE TABLE IF NOT EXISTS experiments (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT,
+    kind TEXT NOT NULL, -- agents | templates
+    arm_a TEXT NOT NULL,
+    arm_b TEXT NOT NULL,
+    project_id TEXT REFERENCES projects(id) ON DELETE CASCADE, -- only tasks of this project take part; NULL = any
+    status TEXT NOT NULL DEFAULT 'active', -- active | stopped
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    stopped_at DATETIME
+);
+
+-- The arm each task taking part in an experiment was assigned to
+CREATE TABLE IF NOT EXISTS experiment_tasks (
+    task_id TEXT PRIMARY KEY REFERENCES tasks(id) ON DELETE CASCADE,
+    experiment_id TEXT NOT NULL REFERENCES experiments(id) ON DELETE CASCADE,
+    arm TEXT NOT NULL, -- a | b
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_experiment_tasks_experiment_id ON experiment_tasks(experiment_id);
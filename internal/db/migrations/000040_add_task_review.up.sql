@@ -0,0 +1,7 @@
+-- Tasks requiring review go to 'review' rather than 'done' when their work is
+-- finished, until the reviewer approves them
+ALTER TABLE tasks ADD COLUMN requires_review BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Who reviews the task: another agent or a human user, by name
+ALTER TABLE tasks ADD COLUMN reviewer_agent_id TEXT REFERENCES agents(id) ON DELETE SET NULL;
+ALTER TABLE tasks ADD COLUMN reviewer_user TEXT;
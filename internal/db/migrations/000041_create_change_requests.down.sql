@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_change_requests_task_id;
+DROP TABLE IF EXISTS change_requests;
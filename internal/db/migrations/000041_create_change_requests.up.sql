@@ -0,0 +1,16 @@
+-- Changes requested on a task's work, by a person or a reviewer agent. The
+-- task can't be done while any is still open.
+CREATE TABLE IF NOT EXISTS change_requests (
+    id TEXT PRIMARY KEY,
+    task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    requester TEXT NOT NULL, -- human, a user name or an agent ID
+    content TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'open', -- open | addressed
+    commit_sha TEXT, -- commit that addressed it
+    resolution TEXT, -- how it was addressed
+    addressed_by TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    addressed_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_change_requests_task_id ON change_requests(task_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_jira_links_jira_project;
+DROP TABLE IF EXISTS jira_links;
@@ -0,0 +1,13 @@
+-- Tasks imported from JIRA issues, so re-imports update the same task and
+-- status changes are pushed back to the issue.
+CREATE TABLE IF NOT EXISTS jira_links (
+    task_id TEXT PRIMARY KEY REFERENCES tasks(id) ON DELETE CASCADE,
+    issue_key TEXT NOT NULL UNIQUE, -- e.g. OPS-12
+    jira_project TEXT NOT NULL,
+    jira_status TEXT NOT NULL, -- the issue's status as of the last sync
+    synced_status TEXT NOT NULL, -- the task's status as of the last sync
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    synced_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_jira_links_jira_project ON jira_links(jira_project);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS github_issue_links;
+
+-- SQLite doesn't support DROP COLUMN in older versions
+-- These columns will remain but be unused
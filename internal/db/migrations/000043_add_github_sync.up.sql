@@ -0,0 +1,17 @@
+-- GitHub repository (owner/name) whose issues carrying github_label become
+-- the project's tasks
+ALTER TABLE projects ADD COLUMN github_repo TEXT;
+ALTER TABLE projects ADD COLUMN github_label TEXT;
+
+-- Tasks created from GitHub issues, so webhooks find the task of an issue
+-- and finishing the task closes the issue
+CREATE TABLE IF NOT EXISTS github_issue_links (
+    task_id TEXT PRIMARY KEY REFERENCES tasks(id) ON DELETE CASCADE,
+    project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    repo TEXT NOT NULL, -- owner/name
+    issue_number INTEGER NOT NULL,
+    state TEXT NOT NULL DEFAULT 'open', -- open | closed, as last seen
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    synced_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (repo, issue_number)
+);
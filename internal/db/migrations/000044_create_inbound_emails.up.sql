@@ -0,0 +1,9 @@
+-- Tasks created from inbound emails: who sent them, and the Message-ID so a
+-- redelivered message does not create a second task.
+CREATE TABLE IF NOT EXISTS inbound_emails (
+    task_id TEXT PRIMARY KEY REFERENCES tasks(id) ON DELETE CASCADE,
+    message_id TEXT UNIQUE, -- the Message-ID header, if the message had one
+    sender TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
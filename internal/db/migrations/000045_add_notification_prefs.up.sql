@@ -0,0 +1,2 @@
+-- Per-agent notification preferences (JSON: which kinds are pushed, queue_only); NULL = push everything
+ALTER TABLE agents ADD COLUMN notification_prefs TEXT;
@@ -0,0 +1,2 @@
+-- System-wide quiet hours (JSON, see internal/quiethours); NULL = never quiet
+ALTER TABLE settings ADD COLUMN quiet_hours TEXT;
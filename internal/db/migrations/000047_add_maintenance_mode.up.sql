@@ -0,0 +1,3 @@
+-- Maintenance mode: set while on (see internal/maintenance); NULL = off
+ALTER TABLE settings ADD COLUMN maintenance_since DATETIME;
+ALTER TABLE settings ADD COLUMN maintenance_reason TEXT;
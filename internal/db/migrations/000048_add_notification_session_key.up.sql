@@ -0,0 +1,3 @@
+-- Gateway session a notification went to, so a restarted process can check
+-- whether it arrived before resending it
+ALTER TABLE notification_deliveries ADD COLUMN session_key TEXT;
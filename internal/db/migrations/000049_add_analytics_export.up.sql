@@ -0,0 +1,4 @@
+-- Analytics export: the last event exported (events.rowid), so each nightly
+-- run exports only newer events, and when the last run finished
+ALTER TABLE settings ADD COLUMN analytics_export_seq INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE settings ADD COLUMN analytics_exported_at DATETIME;
@@ -0,0 +1,3 @@
+-- Object storage for backups and export bundles (see internal/objectstore),
+-- as JSON; NULL = local disk next to the database
+ALTER TABLE settings ADD COLUMN storage TEXT;
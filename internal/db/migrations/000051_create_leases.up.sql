@@ -0,0 +1,8 @@
+-- Leases coordinate Mission Control instances sharing the database: the
+-- instance holding a lease (e.g. "leader") until it expires does the work it
+-- guards, and keeps it by renewing the lease before then.
+CREATE TABLE IF NOT EXISTS leases (
+    name TEXT PRIMARY KEY,
+    holder TEXT NOT NULL, -- instance ID
+    expires_at DATETIME NOT NULL
+);
@@ -0,0 +1,3 @@
+-- Time zone the agent works in (IANA name, e.g. "Europe/Berlin"), used to render
+-- its tasks' times; NULL = UTC only
+ALTER TABLE agents ADD COLUMN timezone TEXT;
@@ -0,0 +1,4 @@
+-- How long each attempt to deliver a notification may take, system-wide and
+-- per agent (JSON, see package notifytimeout); NULL = not set
+ALTER TABLE settings ADD COLUMN notification_timeouts TEXT;
+ALTER TABLE agents ADD COLUMN notification_timeouts TEXT;
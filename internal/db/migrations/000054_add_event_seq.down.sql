@@ -0,0 +1,3 @@
+-- SQLite doesn't support DROP COLUMN in older versions
+-- This column will remain but be unused
+DROP INDEX IF EXISTS idx_events_seq;
@@ -0,0 +1,9 @@
+-- Events are numbered in the order they are recorded, for paging and for
+-- following the event stream. The number used to be the table's implicit
+-- rowid, which VACUUM may renumber since events have a TEXT primary key;
+-- seq is stored, so it never changes. Existing events keep their rowid,
+-- so cursors handed out before (e.g. settings.analytics_export_seq) still
+-- point to the same event.
+ALTER TABLE events ADD COLUMN seq INTEGER NOT NULL DEFAULT 0;
+UPDATE events SET seq = rowid;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_events_seq ON events(seq);
@@ -0,0 +1,5 @@
+-- The start of a long comment, for lists; NULL when the comment is short
+-- enough to be listed whole (see store.CommentPreviewSize)
+ALTER TABLE comments ADD COLUMN preview TEXT;
+
+UPDATE comments SET preview = substr(content, 1, 1000) WHERE length(content) > 1000;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_task_results_task;
+DROP TABLE IF EXISTS task_results;
@@ -0,0 +1,16 @@
+-- What agents send back when notified about a task: the reply to an
+-- assignment, a subtask result, a review, ... Kept whole, with a one-line
+-- summary for task lists.
+CREATE TABLE IF NOT EXISTS task_results (
+    id TEXT PRIMARY KEY,
+    task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    agent_id TEXT,
+    kind TEXT NOT NULL,   -- the notification replied to: assignment | renotify | subtask_completion | review_request | mention | change_request
+    summary TEXT NOT NULL,
+    body TEXT NOT NULL,
+    metadata TEXT,        -- JSON
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_task_results_task ON task_results(task_id, created_at);
@@ -0,0 +1,12 @@
+-- Agents being removed: deactivated (status 'deleting'), waiting for their
+-- OpenClaw config entry and workspace to go before the agent row is purged.
+-- A removal that failed halfway stays here to be retried.
+CREATE TABLE IF NOT EXISTS agent_deletions (
+    agent_id TEXT PRIMARY KEY,
+    purge_workspace BOOLEAN NOT NULL DEFAULT 1,
+    previous_status TEXT,  -- the agent's status before it was deactivated
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_trash_items_expires_at;
+DROP TABLE IF EXISTS trash_items;
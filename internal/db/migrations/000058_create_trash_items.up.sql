@@ -0,0 +1,14 @@
+-- Deleted tasks, comments and projects, restorable until they expire. The
+-- snapshot holds the deleted rows with everything deleted along with them
+-- and the references that were cleared (see db.Snapshot), as JSON.
+CREATE TABLE IF NOT EXISTS trash_items (
+    id TEXT PRIMARY KEY,
+    kind TEXT NOT NULL,     -- task | comment | project
+    item_id TEXT NOT NULL,  -- ID of the deleted task, comment or project
+    title TEXT NOT NULL DEFAULT '',
+    snapshot TEXT NOT NULL,
+    deleted_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    expires_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_trash_items_expires_at ON trash_items(expires_at);
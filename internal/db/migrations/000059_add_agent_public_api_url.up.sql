@@ -0,0 +1,4 @@
+-- Base URL of the Mission Control API as the agent reaches it (e.g.
+-- "https://mc.example.com"), used in the instructions it is sent; NULL = the
+-- server's PUBLIC_API_URL
+ALTER TABLE agents ADD COLUMN public_api_url TEXT;
@@ -0,0 +1,2 @@
+-- SQLite doesn't support DROP COLUMN in older versions
+-- This column will remain but be unused
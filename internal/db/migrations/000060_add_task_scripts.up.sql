@@ -0,0 +1,3 @@
+-- Scripts routing and checking tasks as they are created (JSON, see
+-- package taskscript); NULL = none
+ALTER TABLE settings ADD COLUMN task_scripts TEXT;
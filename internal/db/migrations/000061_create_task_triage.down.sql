@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS task_labels;
+DROP INDEX IF EXISTS idx_task_triage_status;
+DROP TABLE IF EXISTS task_triage;
@@ -0,0 +1,22 @@
+-- What the triage agent suggested for a task (see package triage) and what
+-- became of it. The suggestion is kept as JSON.
+CREATE TABLE IF NOT EXISTS task_triage (
+    task_id TEXT PRIMARY KEY REFERENCES tasks(id) ON DELETE CASCADE,
+    agent_id TEXT NOT NULL,                  -- the triage agent asked
+    status TEXT NOT NULL DEFAULT 'pending',  -- pending | staged | applied | dismissed | failed
+    suggestion TEXT,
+    error TEXT,                              -- why it failed
+    decided_by TEXT,                         -- who applied or dismissed it; "auto" if applied on arrival
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    decided_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_triage_status ON task_triage(status);
+
+-- Labels on tasks
+CREATE TABLE IF NOT EXISTS task_labels (
+    task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    label TEXT NOT NULL,
+    PRIMARY KEY (task_id, label)
+);
@@ -6,28 +6,80 @@ package db
 
 import (
 	"database/sql"
+	"time"
 )
 
 type Agent struct {
-	ID               string         `json:"id"`
-	Name             string         `json:"name"`
-	Description      sql.NullString `json:"description"`
-	Status           sql.NullString `json:"status"`
-	WorkspacePath    sql.NullString `json:"workspace_path"`
-	AgentDirPath     sql.NullString `json:"agent_dir_path"`
-	Model            sql.NullString `json:"model"`
-	MentionPatterns  sql.NullString `json:"mention_patterns"`
-	SoulMd           sql.NullString `json:"soul_md"`
-	AgentsMd         sql.NullString `json:"agents_md"`
-	IdentityMd       sql.NullString `json:"identity_md"`
-	UserMd           sql.NullString `json:"user_md"`
-	ToolsMd          sql.NullString `json:"tools_md"`
-	HeartbeatMd      sql.NullString `json:"heartbeat_md"`
-	MemoryMd         sql.NullString `json:"memory_md"`
-	ActiveSessionKey sql.NullString `json:"active_session_key"`
-	CurrentTaskID    sql.NullString `json:"current_task_id"`
-	CreatedAt        sql.NullTime   `json:"created_at"`
-	UpdatedAt        sql.NullTime   `json:"updated_at"`
+	ID                   string         `json:"id"`
+	Name                 string         `json:"name"`
+	Description          sql.NullString `json:"description"`
+	Status               sql.NullString `json:"status"`
+	WorkspacePath        sql.NullString `json:"workspace_path"`
+	AgentDirPath         sql.NullString `json:"agent_dir_path"`
+	Model                sql.NullString `json:"model"`
+	MentionPatterns      sql.NullString `json:"mention_patterns"`
+	SoulMd               sql.NullString `json:"soul_md"`
+	AgentsMd             sql.NullString `json:"agents_md"`
+	IdentityMd           sql.NullString `json:"identity_md"`
+	UserMd               sql.NullString `json:"user_md"`
+	ToolsMd              sql.NullString `json:"tools_md"`
+	HeartbeatMd          sql.NullString `json:"heartbeat_md"`
+	MemoryMd             sql.NullString `json:"memory_md"`
+	ActiveSessionKey     sql.NullString `json:"active_session_key"`
+	CurrentTaskID        sql.NullString `json:"current_task_id"`
+	CreatedAt            sql.NullTime   `json:"created_at"`
+	UpdatedAt            sql.NullTime   `json:"updated_at"`
+	Locale               sql.NullString `json:"locale"`
+	WorkingHours         sql.NullString `json:"working_hours"`
+	ManagedExternally    bool           `json:"managed_externally"`
+	CallbackURL          sql.NullString `json:"callback_url"`
+	DeliveryMethod       sql.NullString `json:"delivery_method"`
+	CallbackSecret       sql.NullString `json:"callback_secret"`
+	GatewayID            sql.NullString `json:"gateway_id"`
+	NotificationPrefs    sql.NullString `json:"notification_prefs"`
+	Timezone             sql.NullString `json:"timezone"`
+	NotificationTimeouts sql.NullString `json:"notification_timeouts"`
+	PublicAPIURL         sql.NullString `json:"public_api_url"`
+}
+
+type AgentDeletion struct {
+	AgentID        string         `json:"agent_id"`
+	PurgeWorkspace bool           `json:"purge_workspace"`
+	PreviousStatus sql.NullString `json:"previous_status"`
+	Attempts       int64          `json:"attempts"`
+	LastError      sql.NullString `json:"last_error"`
+	CreatedAt      sql.NullTime   `json:"created_at"`
+	UpdatedAt      sql.NullTime   `json:"updated_at"`
+}
+
+type AgentGroup struct {
+	ID                    string         `json:"id"`
+	Name                  string         `json:"name"`
+	Description           sql.NullString `json:"description"`
+	CreatedAt             sql.NullTime   `json:"created_at"`
+	UpdatedAt             sql.NullTime   `json:"updated_at"`
+	DispatchStrategy      string         `json:"dispatch_strategy"`
+	LastDispatchedAgentID sql.NullString `json:"last_dispatched_agent_id"`
+}
+
+type AgentGroupMember struct {
+	GroupID   string         `json:"group_id"`
+	AgentID   string         `json:"agent_id"`
+	CreatedAt sql.NullTime   `json:"created_at"`
+	Skills    sql.NullString `json:"skills"`
+}
+
+type ChangeRequest struct {
+	ID          string         `json:"id"`
+	TaskID      string         `json:"task_id"`
+	Requester   string         `json:"requester"`
+	Content     string         `json:"content"`
+	Status      string         `json:"status"`
+	CommitSha   sql.NullString `json:"commit_sha"`
+	Resolution  sql.NullString `json:"resolution"`
+	AddressedBy sql.NullString `json:"addressed_by"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
+	AddressedAt sql.NullTime   `json:"addressed_at"`
 }
 
 type ChatMessage struct {
@@ -49,11 +101,12 @@ type ChatSession struct {
 }
 
 type Comment struct {
-	ID        string       `json:"id"`
-	TaskID    string       `json:"task_id"`
-	Author    string       `json:"author"`
-	Content   string       `json:"content"`
-	CreatedAt sql.NullTime `json:"created_at"`
+	ID        string         `json:"id"`
+	TaskID    string         `json:"task_id"`
+	Author    string         `json:"author"`
+	Content   string         `json:"content"`
+	CreatedAt sql.NullTime   `json:"created_at"`
+	Preview   sql.NullString `json:"preview"`
 }
 
 type Event struct {
@@ -64,6 +117,129 @@ type Event struct {
 	Message   string         `json:"message"`
 	Details   sql.NullString `json:"details"`
 	CreatedAt sql.NullTime   `json:"created_at"`
+	Seq       int64          `json:"seq"`
+}
+
+type Experiment struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Description sql.NullString `json:"description"`
+	Kind        string         `json:"kind"`
+	ArmA        string         `json:"arm_a"`
+	ArmB        string         `json:"arm_b"`
+	ProjectID   sql.NullString `json:"project_id"`
+	Status      string         `json:"status"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
+	UpdatedAt   sql.NullTime   `json:"updated_at"`
+	StoppedAt   sql.NullTime   `json:"stopped_at"`
+}
+
+type ExperimentTask struct {
+	TaskID       string       `json:"task_id"`
+	ExperimentID string       `json:"experiment_id"`
+	Arm          string       `json:"arm"`
+	CreatedAt    sql.NullTime `json:"created_at"`
+}
+
+type Gateway struct {
+	ID        string         `json:"id"`
+	Name      string         `json:"name"`
+	URL       string         `json:"url"`
+	Token     sql.NullString `json:"token"`
+	CreatedAt sql.NullTime   `json:"created_at"`
+	UpdatedAt sql.NullTime   `json:"updated_at"`
+}
+
+type GithubIssueLink struct {
+	TaskID      string       `json:"task_id"`
+	ProjectID   string       `json:"project_id"`
+	Repo        string       `json:"repo"`
+	IssueNumber int64        `json:"issue_number"`
+	State       string       `json:"state"`
+	CreatedAt   sql.NullTime `json:"created_at"`
+	SyncedAt    sql.NullTime `json:"synced_at"`
+}
+
+type InboundEmail struct {
+	TaskID    string         `json:"task_id"`
+	MessageID sql.NullString `json:"message_id"`
+	Sender    string         `json:"sender"`
+	Subject   string         `json:"subject"`
+	CreatedAt sql.NullTime   `json:"created_at"`
+}
+
+type JiraLink struct {
+	TaskID       string       `json:"task_id"`
+	IssueKey     string       `json:"issue_key"`
+	JiraProject  string       `json:"jira_project"`
+	JiraStatus   string       `json:"jira_status"`
+	SyncedStatus string       `json:"synced_status"`
+	CreatedAt    sql.NullTime `json:"created_at"`
+	SyncedAt     sql.NullTime `json:"synced_at"`
+}
+
+type Lease struct {
+	Name      string    `json:"name"`
+	Holder    string    `json:"holder"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type NotificationDelivery struct {
+	ID          string         `json:"id"`
+	Kind        string         `json:"kind"`
+	TaskID      string         `json:"task_id"`
+	Transition  string         `json:"transition"`
+	AgentID     string         `json:"agent_id"`
+	Status      string         `json:"status"`
+	Attempts    int64          `json:"attempts"`
+	LastError   sql.NullString `json:"last_error"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
+	UpdatedAt   sql.NullTime   `json:"updated_at"`
+	DeliveredAt sql.NullTime   `json:"delivered_at"`
+	SessionKey  sql.NullString `json:"session_key"`
+}
+
+type TaskLabel struct {
+	TaskID string `json:"task_id"`
+	Label  string `json:"label"`
+}
+
+type TaskLink struct {
+	SourceTaskID string       `json:"source_task_id"`
+	TargetTaskID string       `json:"target_task_id"`
+	SourceKind   string       `json:"source_kind"`
+	SourceID     string       `json:"source_id"`
+	CreatedAt    sql.NullTime `json:"created_at"`
+}
+
+type TaskRedirect struct {
+	FromTaskID string       `json:"from_task_id"`
+	ToTaskID   string       `json:"to_task_id"`
+	CreatedAt  sql.NullTime `json:"created_at"`
+}
+
+type TaskAttempt struct {
+	ID         string         `json:"id"`
+	TaskID     string         `json:"task_id"`
+	Kind       string         `json:"kind"`
+	AgentID    sql.NullString `json:"agent_id"`
+	Reason     sql.NullString `json:"reason"`
+	Outcome    string         `json:"outcome"`
+	Error      sql.NullString `json:"error"`
+	RetryCount int64          `json:"retry_count"`
+	CreatedAt  sql.NullTime   `json:"created_at"`
+	FinishedAt sql.NullTime   `json:"finished_at"`
+}
+
+type TaskResult struct {
+	ID        string         `json:"id"`
+	TaskID    string         `json:"task_id"`
+	AgentID   sql.NullString `json:"agent_id"`
+	Kind      string         `json:"kind"`
+	Summary   string         `json:"summary"`
+	Body      string         `json:"body"`
+	Metadata  sql.NullString `json:"metadata"`
+	CreatedAt sql.NullTime   `json:"created_at"`
 }
 
 type Phase struct {
@@ -84,18 +260,41 @@ type Phase struct {
 	UpdatedAt          sql.NullTime   `json:"updated_at"`
 }
 
+type ProgressEntry struct {
+	ID        int64          `json:"id"`
+	TaskID    string         `json:"task_id"`
+	Author    sql.NullString `json:"author"`
+	Content   string         `json:"content"`
+	CreatedAt sql.NullTime   `json:"created_at"`
+}
+
 type Project struct {
-	ID                string         `json:"id"`
-	Name              string         `json:"name"`
-	Description       sql.NullString `json:"description"`
-	Status            sql.NullString `json:"status"`
-	Color             sql.NullString `json:"color"`
-	CreatedAt         sql.NullTime   `json:"created_at"`
-	UpdatedAt         sql.NullTime   `json:"updated_at"`
-	Location          sql.NullString `json:"location"`
-	DefaultBranch     sql.NullString `json:"default_branch"`
-	LocalExecBranch   sql.NullString `json:"local_exec_branch"`
-	RemoteMergeBranch sql.NullString `json:"remote_merge_branch"`
+	ID                    string         `json:"id"`
+	Name                  string         `json:"name"`
+	Description           sql.NullString `json:"description"`
+	Status                sql.NullString `json:"status"`
+	Color                 sql.NullString `json:"color"`
+	CreatedAt             sql.NullTime   `json:"created_at"`
+	UpdatedAt             sql.NullTime   `json:"updated_at"`
+	Location              sql.NullString `json:"location"`
+	DefaultBranch         sql.NullString `json:"default_branch"`
+	LocalExecBranch       sql.NullString `json:"local_exec_branch"`
+	RemoteMergeBranch     sql.NullString `json:"remote_merge_branch"`
+	Key                   sql.NullString `json:"key"`
+	AllowedPaths          sql.NullString `json:"allowed_paths"`
+	PolicyAction          sql.NullString `json:"policy_action"`
+	MaxSubtaskDepth       sql.NullInt64  `json:"max_subtask_depth"`
+	MaxConcurrentSubtasks sql.NullInt64  `json:"max_concurrent_subtasks"`
+	GithubRepo            sql.NullString `json:"github_repo"`
+	GithubLabel           sql.NullString `json:"github_label"`
+}
+
+type ProjectSecret struct {
+	ProjectID   string       `json:"project_id"`
+	Name        string       `json:"name"`
+	SealedValue string       `json:"sealed_value"`
+	CreatedAt   sql.NullTime `json:"created_at"`
+	UpdatedAt   sql.NullTime `json:"updated_at"`
 }
 
 type Setting struct {
@@ -114,6 +313,15 @@ type Setting struct {
 	RalphAutoCommit         sql.NullInt64  `json:"ralph_auto_commit"`
 	Theme                   sql.NullString `json:"theme"`
 	UpdatedAt               sql.NullTime   `json:"updated_at"`
+	ModelRouting            sql.NullString `json:"model_routing"`
+	QuietHours              sql.NullString `json:"quiet_hours"`
+	MaintenanceSince        sql.NullTime   `json:"maintenance_since"`
+	MaintenanceReason       sql.NullString `json:"maintenance_reason"`
+	AnalyticsExportSeq      int64          `json:"analytics_export_seq"`
+	AnalyticsExportedAt     sql.NullTime   `json:"analytics_exported_at"`
+	Storage                 sql.NullString `json:"storage"`
+	NotificationTimeouts    sql.NullString `json:"notification_timeouts"`
+	TaskScripts             sql.NullString `json:"task_scripts"`
 }
 
 type Story struct {
@@ -149,28 +357,67 @@ type SubAgent struct {
 }
 
 type Task struct {
-	ID             string         `json:"id"`
-	Title          string         `json:"title"`
-	Description    sql.NullString `json:"description"`
-	AgentID        sql.NullString `json:"agent_id"`
-	ProjectID      sql.NullString `json:"project_id"`
-	ParentTaskID   sql.NullString `json:"parent_task_id"`
-	Status         sql.NullString `json:"status"`
-	Priority       sql.NullInt64  `json:"priority"`
-	GitBranch      sql.NullString `json:"git_branch"`
-	ProjectMd      sql.NullString `json:"project_md"`
-	RequirementsMd sql.NullString `json:"requirements_md"`
-	RoadmapMd      sql.NullString `json:"roadmap_md"`
-	StateMd        sql.NullString `json:"state_md"`
-	PrdJson        sql.NullString `json:"prd_json"`
-	ProgressTxt    sql.NullString `json:"progress_txt"`
-	QualityChecks  sql.NullString `json:"quality_checks"`
-	CreatedAt      sql.NullTime   `json:"created_at"`
-	UpdatedAt      sql.NullTime   `json:"updated_at"`
-	StartedAt      sql.NullTime   `json:"started_at"`
-	CompletedAt    sql.NullTime   `json:"completed_at"`
-	DelegationMode sql.NullString `json:"delegation_mode"`
-	RetryCount     int64          `json:"retry_count"`
-	ScheduledAt    sql.NullTime   `json:"scheduled_at"`
-	RetryAt        sql.NullTime   `json:"retry_at"`
+	ID                    string         `json:"id"`
+	Title                 string         `json:"title"`
+	Description           sql.NullString `json:"description"`
+	AgentID               sql.NullString `json:"agent_id"`
+	ProjectID             sql.NullString `json:"project_id"`
+	ParentTaskID          sql.NullString `json:"parent_task_id"`
+	Status                sql.NullString `json:"status"`
+	Priority              sql.NullInt64  `json:"priority"`
+	GitBranch             sql.NullString `json:"git_branch"`
+	ProjectMd             sql.NullString `json:"project_md"`
+	RequirementsMd        sql.NullString `json:"requirements_md"`
+	RoadmapMd             sql.NullString `json:"roadmap_md"`
+	StateMd               sql.NullString `json:"state_md"`
+	PrdJson               sql.NullString `json:"prd_json"`
+	ProgressTxt           sql.NullString `json:"progress_txt"`
+	QualityChecks         sql.NullString `json:"quality_checks"`
+	CreatedAt             sql.NullTime   `json:"created_at"`
+	UpdatedAt             sql.NullTime   `json:"updated_at"`
+	StartedAt             sql.NullTime   `json:"started_at"`
+	CompletedAt           sql.NullTime   `json:"completed_at"`
+	DelegationMode        sql.NullString `json:"delegation_mode"`
+	RetryCount            int64          `json:"retry_count"`
+	ScheduledAt           sql.NullTime   `json:"scheduled_at"`
+	RetryAt               sql.NullTime   `json:"retry_at"`
+	QueuePosition         sql.NullInt64  `json:"queue_position"`
+	GroupID               sql.NullString `json:"group_id"`
+	DeferredUntil         sql.NullTime   `json:"deferred_until"`
+	ShortID               sql.NullString `json:"short_id"`
+	SecretNames           sql.NullString `json:"secret_names"`
+	Progress              sql.NullInt64  `json:"progress"`
+	ProgressExplicit      bool           `json:"progress_explicit"`
+	ContextSummary        sql.NullString `json:"context_summary"`
+	ContextSummarizedAt   sql.NullTime   `json:"context_summarized_at"`
+	MaxSubtaskDepth       sql.NullInt64  `json:"max_subtask_depth"`
+	MaxConcurrentSubtasks sql.NullInt64  `json:"max_concurrent_subtasks"`
+	FailureReason         sql.NullString `json:"failure_reason"`
+	Model                 sql.NullString `json:"model"`
+	RoutedModel           sql.NullString `json:"routed_model"`
+	RequiresReview        bool           `json:"requires_review"`
+	ReviewerAgentID       sql.NullString `json:"reviewer_agent_id"`
+	ReviewerUser          sql.NullString `json:"reviewer_user"`
+}
+
+type TaskTriage struct {
+	TaskID     string         `json:"task_id"`
+	AgentID    string         `json:"agent_id"`
+	Status     string         `json:"status"`
+	Suggestion sql.NullString `json:"suggestion"`
+	Error      sql.NullString `json:"error"`
+	DecidedBy  sql.NullString `json:"decided_by"`
+	CreatedAt  sql.NullTime   `json:"created_at"`
+	UpdatedAt  sql.NullTime   `json:"updated_at"`
+	DecidedAt  sql.NullTime   `json:"decided_at"`
+}
+
+type TrashItem struct {
+	ID        string       `json:"id"`
+	Kind      string       `json:"kind"`
+	ItemID    string       `json:"item_id"`
+	Title     string       `json:"title"`
+	Snapshot  string       `json:"snapshot"`
+	DeletedAt sql.NullTime `json:"deleted_at"`
+	ExpiresAt time.Time    `json:"expires_at"`
 }
@@ -10,6 +10,50 @@ import (
 	"database/sql"
 )
 
+const appendPhase = `-- name: AppendPhase :one
+INSERT INTO phases (id, task_id, sequence, title, description, status)
+SELECT ?1, ?2, COALESCE(MAX(sequence), 0) + 1, ?3, ?4, ?5
+FROM phases WHERE task_id = ?2
+RETURNING id, task_id, sequence, title, description, status, context_md, research_md, plan_md, summary_md, uat_md, verification_result, session_key, created_at, updated_at
+`
+
+type AppendPhaseParams struct {
+	ID          string         `json:"id"`
+	TaskID      string         `json:"task_id"`
+	Title       string         `json:"title"`
+	Description sql.NullString `json:"description"`
+	Status      sql.NullString `json:"status"`
+}
+
+func (q *Queries) AppendPhase(ctx context.Context, arg AppendPhaseParams) (Phase, error) {
+	row := q.db.QueryRowContext(ctx, appendPhase,
+		arg.ID,
+		arg.TaskID,
+		arg.Title,
+		arg.Description,
+		arg.Status,
+	)
+	var i Phase
+	err := row.Scan(
+		&i.ID,
+		&i.TaskID,
+		&i.Sequence,
+		&i.Title,
+		&i.Description,
+		&i.Status,
+		&i.ContextMd,
+		&i.ResearchMd,
+		&i.PlanMd,
+		&i.SummaryMd,
+		&i.UatMd,
+		&i.VerificationResult,
+		&i.SessionKey,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const createPhase = `-- name: CreatePhase :one
 INSERT INTO phases (id, task_id, sequence, title, description, status)
 VALUES (?, ?, ?, ?, ?, ?)
@@ -8,12 +8,13 @@ package db
 import (
 	"context"
 	"database/sql"
+	"strings"
 )
 
 const createProject = `-- name: CreateProject :one
-INSERT INTO projects (id, name, description, status, color, location, default_branch, local_exec_branch, remote_merge_branch)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-RETURNING id, name, description, status, color, created_at, updated_at, location, default_branch, local_exec_branch, remote_merge_branch
+INSERT INTO projects (id, name, description, status, color, location, default_branch, local_exec_branch, remote_merge_branch, key)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id, name, description, status, color, created_at, updated_at, location, default_branch, local_exec_branch, remote_merge_branch, key, allowed_paths, policy_action, max_subtask_depth, max_concurrent_subtasks, github_repo, github_label
 `
 
 type CreateProjectParams struct {
@@ -26,6 +27,7 @@ type CreateProjectParams struct {
 	DefaultBranch     sql.NullString `json:"default_branch"`
 	LocalExecBranch   sql.NullString `json:"local_exec_branch"`
 	RemoteMergeBranch sql.NullString `json:"remote_merge_branch"`
+	Key               sql.NullString `json:"key"`
 }
 
 func (q *Queries) CreateProject(ctx context.Context, arg CreateProjectParams) (Project, error) {
@@ -39,6 +41,7 @@ func (q *Queries) CreateProject(ctx context.Context, arg CreateProjectParams) (P
 		arg.DefaultBranch,
 		arg.LocalExecBranch,
 		arg.RemoteMergeBranch,
+		arg.Key,
 	)
 	var i Project
 	err := row.Scan(
@@ -53,6 +56,13 @@ func (q *Queries) CreateProject(ctx context.Context, arg CreateProjectParams) (P
 		&i.DefaultBranch,
 		&i.LocalExecBranch,
 		&i.RemoteMergeBranch,
+		&i.Key,
+		&i.AllowedPaths,
+		&i.PolicyAction,
+		&i.MaxSubtaskDepth,
+		&i.MaxConcurrentSubtasks,
+		&i.GithubRepo,
+		&i.GithubLabel,
 	)
 	return i, err
 }
@@ -67,7 +77,7 @@ func (q *Queries) DeleteProject(ctx context.Context, id string) error {
 }
 
 const getProject = `-- name: GetProject :one
-SELECT id, name, description, status, color, created_at, updated_at, location, default_branch, local_exec_branch, remote_merge_branch FROM projects WHERE id = ? LIMIT 1
+SELECT id, name, description, status, color, created_at, updated_at, location, default_branch, local_exec_branch, remote_merge_branch, key, allowed_paths, policy_action, max_subtask_depth, max_concurrent_subtasks, github_repo, github_label FROM projects WHERE id = ? LIMIT 1
 `
 
 func (q *Queries) GetProject(ctx context.Context, id string) (Project, error) {
@@ -85,6 +95,44 @@ func (q *Queries) GetProject(ctx context.Context, id string) (Project, error) {
 		&i.DefaultBranch,
 		&i.LocalExecBranch,
 		&i.RemoteMergeBranch,
+		&i.Key,
+		&i.AllowedPaths,
+		&i.PolicyAction,
+		&i.MaxSubtaskDepth,
+		&i.MaxConcurrentSubtasks,
+		&i.GithubRepo,
+		&i.GithubLabel,
+	)
+	return i, err
+}
+
+const getProjectDeletionImpact = `-- name: GetProjectDeletionImpact :one
+SELECT
+    (SELECT COUNT(*) FROM tasks WHERE tasks.project_id = projects.id) AS tasks,
+    (SELECT COUNT(*) FROM tasks WHERE tasks.project_id = projects.id AND tasks.status NOT IN ('done', 'failed', 'cancelled')) AS open_tasks,
+    (SELECT COUNT(*) FROM project_secrets WHERE project_secrets.project_id = projects.id) AS secrets,
+    (SELECT COUNT(*) FROM experiments WHERE experiments.project_id = projects.id) AS experiments,
+    (SELECT COUNT(*) FROM github_issue_links WHERE github_issue_links.project_id = projects.id) AS github_issues
+FROM projects WHERE projects.id = ?
+`
+
+type GetProjectDeletionImpactRow struct {
+	Tasks        int64 `json:"tasks"`
+	OpenTasks    int64 `json:"open_tasks"`
+	Secrets      int64 `json:"secrets"`
+	Experiments  int64 `json:"experiments"`
+	GithubIssues int64 `json:"github_issues"`
+}
+
+func (q *Queries) GetProjectDeletionImpact(ctx context.Context, id string) (GetProjectDeletionImpactRow, error) {
+	row := q.db.QueryRowContext(ctx, getProjectDeletionImpact, id)
+	var i GetProjectDeletionImpactRow
+	err := row.Scan(
+		&i.Tasks,
+		&i.OpenTasks,
+		&i.Secrets,
+		&i.Experiments,
+		&i.GithubIssues,
 	)
 	return i, err
 }
@@ -111,8 +159,55 @@ func (q *Queries) GetProjectTaskCount(ctx context.Context, projectID sql.NullStr
 	return count, err
 }
 
+const getProjectWithStats = `-- name: GetProjectWithStats :one
+SELECT p.id, p.name, p.description, p.status, p.color, p.created_at, p.updated_at, p.location, p.default_branch, p.local_exec_branch, p.remote_merge_branch, p.key, p.allowed_paths, p.policy_action, p.max_subtask_depth, p.max_concurrent_subtasks, p.github_repo, p.github_label,
+    COUNT(t.id) AS task_count,
+    COUNT(CASE WHEN t.status = 'done' THEN 1 END) AS done_count,
+    CAST(COALESCE(MAX(unixepoch(t.updated_at)), 0) AS INTEGER) AS last_activity
+FROM projects p
+LEFT JOIN tasks t ON t.project_id = p.id
+WHERE p.id = ?
+GROUP BY p.id
+`
+
+type GetProjectWithStatsRow struct {
+	Project      Project `json:"project"`
+	TaskCount    int64   `json:"task_count"`
+	DoneCount    int64   `json:"done_count"`
+	LastActivity int64   `json:"last_activity"`
+}
+
+func (q *Queries) GetProjectWithStats(ctx context.Context, id string) (GetProjectWithStatsRow, error) {
+	row := q.db.QueryRowContext(ctx, getProjectWithStats, id)
+	var i GetProjectWithStatsRow
+	err := row.Scan(
+		&i.Project.ID,
+		&i.Project.Name,
+		&i.Project.Description,
+		&i.Project.Status,
+		&i.Project.Color,
+		&i.Project.CreatedAt,
+		&i.Project.UpdatedAt,
+		&i.Project.Location,
+		&i.Project.DefaultBranch,
+		&i.Project.LocalExecBranch,
+		&i.Project.RemoteMergeBranch,
+		&i.Project.Key,
+		&i.Project.AllowedPaths,
+		&i.Project.PolicyAction,
+		&i.Project.MaxSubtaskDepth,
+		&i.Project.MaxConcurrentSubtasks,
+		&i.Project.GithubRepo,
+		&i.Project.GithubLabel,
+		&i.TaskCount,
+		&i.DoneCount,
+		&i.LastActivity,
+	)
+	return i, err
+}
+
 const listProjects = `-- name: ListProjects :many
-SELECT id, name, description, status, color, created_at, updated_at, location, default_branch, local_exec_branch, remote_merge_branch FROM projects ORDER BY created_at DESC
+SELECT id, name, description, status, color, created_at, updated_at, location, default_branch, local_exec_branch, remote_merge_branch, key, allowed_paths, policy_action, max_subtask_depth, max_concurrent_subtasks, github_repo, github_label FROM projects ORDER BY created_at DESC
 `
 
 func (q *Queries) ListProjects(ctx context.Context) ([]Project, error) {
@@ -136,6 +231,115 @@ func (q *Queries) ListProjects(ctx context.Context) ([]Project, error) {
 			&i.DefaultBranch,
 			&i.LocalExecBranch,
 			&i.RemoteMergeBranch,
+			&i.Key,
+			&i.AllowedPaths,
+			&i.PolicyAction,
+			&i.MaxSubtaskDepth,
+			&i.MaxConcurrentSubtasks,
+			&i.GithubRepo,
+			&i.GithubLabel,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectsByGithubRepo = `-- name: ListProjectsByGithubRepo :many
+SELECT id, name, description, status, color, created_at, updated_at, location, default_branch, local_exec_branch, remote_merge_branch, key, allowed_paths, policy_action, max_subtask_depth, max_concurrent_subtasks, github_repo, github_label FROM projects WHERE github_repo = ? ORDER BY created_at ASC
+`
+
+func (q *Queries) ListProjectsByGithubRepo(ctx context.Context, githubRepo sql.NullString) ([]Project, error) {
+	rows, err := q.db.QueryContext(ctx, listProjectsByGithubRepo, githubRepo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Project{}
+	for rows.Next() {
+		var i Project
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.Status,
+			&i.Color,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Location,
+			&i.DefaultBranch,
+			&i.LocalExecBranch,
+			&i.RemoteMergeBranch,
+			&i.Key,
+			&i.AllowedPaths,
+			&i.PolicyAction,
+			&i.MaxSubtaskDepth,
+			&i.MaxConcurrentSubtasks,
+			&i.GithubRepo,
+			&i.GithubLabel,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectsByIDs = `-- name: ListProjectsByIDs :many
+SELECT id, name, description, status, color, created_at, updated_at, location, default_branch, local_exec_branch, remote_merge_branch, key, allowed_paths, policy_action, max_subtask_depth, max_concurrent_subtasks, github_repo, github_label FROM projects WHERE id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) ListProjectsByIDs(ctx context.Context, ids []string) ([]Project, error) {
+	query := listProjectsByIDs
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Project{}
+	for rows.Next() {
+		var i Project
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.Status,
+			&i.Color,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Location,
+			&i.DefaultBranch,
+			&i.LocalExecBranch,
+			&i.RemoteMergeBranch,
+			&i.Key,
+			&i.AllowedPaths,
+			&i.PolicyAction,
+			&i.MaxSubtaskDepth,
+			&i.MaxConcurrentSubtasks,
+			&i.GithubRepo,
+			&i.GithubLabel,
 		); err != nil {
 			return nil, err
 		}
@@ -151,7 +355,7 @@ func (q *Queries) ListProjects(ctx context.Context) ([]Project, error) {
 }
 
 const listProjectsByStatus = `-- name: ListProjectsByStatus :many
-SELECT id, name, description, status, color, created_at, updated_at, location, default_branch, local_exec_branch, remote_merge_branch FROM projects WHERE status = ? ORDER BY created_at DESC
+SELECT id, name, description, status, color, created_at, updated_at, location, default_branch, local_exec_branch, remote_merge_branch, key, allowed_paths, policy_action, max_subtask_depth, max_concurrent_subtasks, github_repo, github_label FROM projects WHERE status = ? ORDER BY created_at DESC
 `
 
 func (q *Queries) ListProjectsByStatus(ctx context.Context, status sql.NullString) ([]Project, error) {
@@ -175,6 +379,76 @@ func (q *Queries) ListProjectsByStatus(ctx context.Context, status sql.NullStrin
 			&i.DefaultBranch,
 			&i.LocalExecBranch,
 			&i.RemoteMergeBranch,
+			&i.Key,
+			&i.AllowedPaths,
+			&i.PolicyAction,
+			&i.MaxSubtaskDepth,
+			&i.MaxConcurrentSubtasks,
+			&i.GithubRepo,
+			&i.GithubLabel,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectsWithStats = `-- name: ListProjectsWithStats :many
+SELECT p.id, p.name, p.description, p.status, p.color, p.created_at, p.updated_at, p.location, p.default_branch, p.local_exec_branch, p.remote_merge_branch, p.key, p.allowed_paths, p.policy_action, p.max_subtask_depth, p.max_concurrent_subtasks, p.github_repo, p.github_label,
+    COUNT(t.id) AS task_count,
+    COUNT(CASE WHEN t.status = 'done' THEN 1 END) AS done_count,
+    CAST(COALESCE(MAX(unixepoch(t.updated_at)), 0) AS INTEGER) AS last_activity
+FROM projects p
+LEFT JOIN tasks t ON t.project_id = p.id
+GROUP BY p.id
+ORDER BY p.created_at DESC
+`
+
+type ListProjectsWithStatsRow struct {
+	Project      Project `json:"project"`
+	TaskCount    int64   `json:"task_count"`
+	DoneCount    int64   `json:"done_count"`
+	LastActivity int64   `json:"last_activity"`
+}
+
+func (q *Queries) ListProjectsWithStats(ctx context.Context) ([]ListProjectsWithStatsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listProjectsWithStats)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListProjectsWithStatsRow{}
+	for rows.Next() {
+		var i ListProjectsWithStatsRow
+		if err := rows.Scan(
+			&i.Project.ID,
+			&i.Project.Name,
+			&i.Project.Description,
+			&i.Project.Status,
+			&i.Project.Color,
+			&i.Project.CreatedAt,
+			&i.Project.UpdatedAt,
+			&i.Project.Location,
+			&i.Project.DefaultBranch,
+			&i.Project.LocalExecBranch,
+			&i.Project.RemoteMergeBranch,
+			&i.Project.Key,
+			&i.Project.AllowedPaths,
+			&i.Project.PolicyAction,
+			&i.Project.MaxSubtaskDepth,
+			&i.Project.MaxConcurrentSubtasks,
+			&i.Project.GithubRepo,
+			&i.Project.GithubLabel,
+			&i.TaskCount,
+			&i.DoneCount,
+			&i.LastActivity,
 		); err != nil {
 			return nil, err
 		}
@@ -189,6 +463,115 @@ func (q *Queries) ListProjectsByStatus(ctx context.Context, status sql.NullStrin
 	return items, nil
 }
 
+const listProjectsWithStatsByStatus = `-- name: ListProjectsWithStatsByStatus :many
+SELECT p.id, p.name, p.description, p.status, p.color, p.created_at, p.updated_at, p.location, p.default_branch, p.local_exec_branch, p.remote_merge_branch, p.key, p.allowed_paths, p.policy_action, p.max_subtask_depth, p.max_concurrent_subtasks, p.github_repo, p.github_label,
+    COUNT(t.id) AS task_count,
+    COUNT(CASE WHEN t.status = 'done' THEN 1 END) AS done_count,
+    CAST(COALESCE(MAX(unixepoch(t.updated_at)), 0) AS INTEGER) AS last_activity
+FROM projects p
+LEFT JOIN tasks t ON t.project_id = p.id
+WHERE p.status = ?
+GROUP BY p.id
+ORDER BY p.created_at DESC
+`
+
+type ListProjectsWithStatsByStatusRow struct {
+	Project      Project `json:"project"`
+	TaskCount    int64   `json:"task_count"`
+	DoneCount    int64   `json:"done_count"`
+	LastActivity int64   `json:"last_activity"`
+}
+
+func (q *Queries) ListProjectsWithStatsByStatus(ctx context.Context, status sql.NullString) ([]ListProjectsWithStatsByStatusRow, error) {
+	rows, err := q.db.QueryContext(ctx, listProjectsWithStatsByStatus, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListProjectsWithStatsByStatusRow{}
+	for rows.Next() {
+		var i ListProjectsWithStatsByStatusRow
+		if err := rows.Scan(
+			&i.Project.ID,
+			&i.Project.Name,
+			&i.Project.Description,
+			&i.Project.Status,
+			&i.Project.Color,
+			&i.Project.CreatedAt,
+			&i.Project.UpdatedAt,
+			&i.Project.Location,
+			&i.Project.DefaultBranch,
+			&i.Project.LocalExecBranch,
+			&i.Project.RemoteMergeBranch,
+			&i.Project.Key,
+			&i.Project.AllowedPaths,
+			&i.Project.PolicyAction,
+			&i.Project.MaxSubtaskDepth,
+			&i.Project.MaxConcurrentSubtasks,
+			&i.Project.GithubRepo,
+			&i.Project.GithubLabel,
+			&i.TaskCount,
+			&i.DoneCount,
+			&i.LastActivity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setProjectDelegationLimits = `-- name: SetProjectDelegationLimits :exec
+UPDATE projects SET max_subtask_depth = ?, max_concurrent_subtasks = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+`
+
+type SetProjectDelegationLimitsParams struct {
+	MaxSubtaskDepth       sql.NullInt64 `json:"max_subtask_depth"`
+	MaxConcurrentSubtasks sql.NullInt64 `json:"max_concurrent_subtasks"`
+	ID                    string        `json:"id"`
+}
+
+func (q *Queries) SetProjectDelegationLimits(ctx context.Context, arg SetProjectDelegationLimitsParams) error {
+	_, err := q.db.ExecContext(ctx, setProjectDelegationLimits, arg.MaxSubtaskDepth, arg.MaxConcurrentSubtasks, arg.ID)
+	return err
+}
+
+const setProjectGithub = `-- name: SetProjectGithub :exec
+UPDATE projects SET github_repo = ?, github_label = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+`
+
+type SetProjectGithubParams struct {
+	GithubRepo  sql.NullString `json:"github_repo"`
+	GithubLabel sql.NullString `json:"github_label"`
+	ID          string         `json:"id"`
+}
+
+func (q *Queries) SetProjectGithub(ctx context.Context, arg SetProjectGithubParams) error {
+	_, err := q.db.ExecContext(ctx, setProjectGithub, arg.GithubRepo, arg.GithubLabel, arg.ID)
+	return err
+}
+
+const setProjectPathPolicy = `-- name: SetProjectPathPolicy :exec
+UPDATE projects SET allowed_paths = ?, policy_action = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+`
+
+type SetProjectPathPolicyParams struct {
+	AllowedPaths sql.NullString `json:"allowed_paths"`
+	PolicyAction sql.NullString `json:"policy_action"`
+	ID           string         `json:"id"`
+}
+
+func (q *Queries) SetProjectPathPolicy(ctx context.Context, arg SetProjectPathPolicyParams) error {
+	_, err := q.db.ExecContext(ctx, setProjectPathPolicy, arg.AllowedPaths, arg.PolicyAction, arg.ID)
+	return err
+}
+
 const updateProject = `-- name: UpdateProject :one
 UPDATE projects SET
     name = ?, 
@@ -199,9 +582,10 @@ UPDATE projects SET
     default_branch = ?,
     local_exec_branch = ?,
     remote_merge_branch = ?,
+    key = ?,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = ? 
-RETURNING id, name, description, status, color, created_at, updated_at, location, default_branch, local_exec_branch, remote_merge_branch
+RETURNING id, name, description, status, color, created_at, updated_at, location, default_branch, local_exec_branch, remote_merge_branch, key, allowed_paths, policy_action, max_subtask_depth, max_concurrent_subtasks, github_repo, github_label
 `
 
 type UpdateProjectParams struct {
@@ -213,6 +597,7 @@ type UpdateProjectParams struct {
 	DefaultBranch     sql.NullString `json:"default_branch"`
 	LocalExecBranch   sql.NullString `json:"local_exec_branch"`
 	RemoteMergeBranch sql.NullString `json:"remote_merge_branch"`
+	Key               sql.NullString `json:"key"`
 	ID                string         `json:"id"`
 }
 
@@ -226,6 +611,7 @@ func (q *Queries) UpdateProject(ctx context.Context, arg UpdateProjectParams) (P
 		arg.DefaultBranch,
 		arg.LocalExecBranch,
 		arg.RemoteMergeBranch,
+		arg.Key,
 		arg.ID,
 	)
 	var i Project
@@ -241,6 +627,13 @@ func (q *Queries) UpdateProject(ctx context.Context, arg UpdateProjectParams) (P
 		&i.DefaultBranch,
 		&i.LocalExecBranch,
 		&i.RemoteMergeBranch,
+		&i.Key,
+		&i.AllowedPaths,
+		&i.PolicyAction,
+		&i.MaxSubtaskDepth,
+		&i.MaxConcurrentSubtasks,
+		&i.GithubRepo,
+		&i.GithubLabel,
 	)
 	return i, err
 }
@@ -0,0 +1,18 @@
+-- name: CreateAgentDeletion :one
+INSERT INTO agent_deletions (agent_id, purge_workspace, previous_status)
+VALUES (?, ?, ?)
+ON CONFLICT(agent_id) DO UPDATE SET purge_workspace = excluded.purge_workspace, updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: GetAgentDeletion :one
+SELECT * FROM agent_deletions WHERE agent_id = ? LIMIT 1;
+
+-- name: ListAgentDeletions :many
+SELECT * FROM agent_deletions ORDER BY created_at ASC;
+
+-- name: RecordAgentDeletionFailure :exec
+UPDATE agent_deletions SET attempts = attempts + 1, last_error = ?, updated_at = CURRENT_TIMESTAMP
+WHERE agent_id = ?;
+
+-- name: DeleteAgentDeletion :exec
+DELETE FROM agent_deletions WHERE agent_id = ?;
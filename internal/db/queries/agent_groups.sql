@@ -0,0 +1,42 @@
+-- name: GetAgentGroup :one
+SELECT * FROM agent_groups WHERE id = ? LIMIT 1;
+
+-- name: ListAgentGroups :many
+SELECT * FROM agent_groups ORDER BY name ASC;
+
+-- name: CreateAgentGroup :one
+INSERT INTO agent_groups (id, name, description, dispatch_strategy)
+VALUES (?, ?, ?, ?)
+RETURNING *;
+
+-- name: UpdateAgentGroup :one
+UPDATE agent_groups SET name = ?, description = ?, dispatch_strategy = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ? RETURNING *;
+
+-- name: SetAgentGroupLastDispatched :exec
+UPDATE agent_groups SET last_dispatched_agent_id = ? WHERE id = ?;
+
+-- name: DeleteAgentGroup :exec
+DELETE FROM agent_groups WHERE id = ?;
+
+-- name: AddAgentGroupMember :exec
+INSERT INTO agent_group_members (group_id, agent_id, skills) VALUES (?, ?, ?)
+ON CONFLICT (group_id, agent_id) DO UPDATE SET skills = excluded.skills;
+
+-- name: RemoveAgentGroupMember :exec
+DELETE FROM agent_group_members WHERE group_id = ? AND agent_id = ?;
+
+-- name: ListAgentGroupMembers :many
+SELECT a.* FROM agents a
+JOIN agent_group_members m ON m.agent_id = a.id
+WHERE m.group_id = ?
+ORDER BY m.created_at ASC, a.id ASC;
+
+-- name: ListAgentGroupMemberships :many
+SELECT * FROM agent_group_members WHERE group_id = ? ORDER BY created_at ASC, agent_id ASC;
+
+-- name: ListAgentGroupsByAgent :many
+SELECT g.* FROM agent_groups g
+JOIN agent_group_members m ON m.group_id = g.id
+WHERE m.agent_id = ?
+ORDER BY g.name ASC;
@@ -19,5 +19,38 @@ WHERE id = ? RETURNING *;
 -- name: DeleteAgent :exec
 DELETE FROM agents WHERE id = ?;
 
+-- name: UpdateAgentLocale :exec
+UPDATE agents SET locale = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: UpdateAgentMemory :exec
+UPDATE agents SET memory_md = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
+
 -- name: UpdateAgentStatus :exec
 UPDATE agents SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: UpdateAgentWorkingHours :exec
+UPDATE agents SET working_hours = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: UpdateAgentNotificationPrefs :exec
+UPDATE agents SET notification_prefs = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: SetAgentExternal :exec
+UPDATE agents SET managed_externally = TRUE, callback_url = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: UpdateAgentDelivery :exec
+UPDATE agents SET delivery_method = ?, callback_url = ?, callback_secret = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: SetAgentGateway :exec
+UPDATE agents SET gateway_id = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: ListAgentsByIDs :many
+SELECT * FROM agents WHERE id IN (sqlc.slice('ids'));
+
+-- name: UpdateAgentTimezone :exec
+UPDATE agents SET timezone = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: UpdateAgentNotificationTimeouts :exec
+UPDATE agents SET notification_timeouts = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: UpdateAgentPublicAPIURL :exec
+UPDATE agents SET public_api_url = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
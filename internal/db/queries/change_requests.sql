@@ -0,0 +1,17 @@
+-- name: CreateChangeRequest :one
+INSERT INTO change_requests (id, task_id, requester, content)
+VALUES (?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetChangeRequest :one
+SELECT * FROM change_requests WHERE id = ? LIMIT 1;
+
+-- name: ListChangeRequestsByTask :many
+SELECT * FROM change_requests WHERE task_id = ? ORDER BY created_at ASC;
+
+-- name: ResolveChangeRequest :one
+UPDATE change_requests SET status = 'addressed', commit_sha = ?, resolution = ?, addressed_by = ?, addressed_at = CURRENT_TIMESTAMP
+WHERE id = ? RETURNING *;
+
+-- name: CountOpenChangeRequests :one
+SELECT COUNT(*) FROM change_requests WHERE task_id = ? AND status = 'open';
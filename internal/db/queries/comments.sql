@@ -5,9 +5,25 @@ SELECT * FROM comments WHERE id = ? LIMIT 1;
 SELECT * FROM comments WHERE task_id = ? ORDER BY created_at ASC;
 
 -- name: CreateComment :one
-INSERT INTO comments (id, task_id, author, content)
-VALUES (?, ?, ?, ?)
+INSERT INTO comments (id, task_id, author, content, preview)
+VALUES (?, ?, ?, ?, ?)
 RETURNING *;
 
 -- name: DeleteComment :exec
 DELETE FROM comments WHERE id = ?;
+
+-- name: CountCommentsByTask :one
+SELECT COUNT(*) AS count FROM comments
+WHERE task_id = sqlc.arg('task_id') AND (CAST(sqlc.arg('author') AS TEXT) = '' OR author = sqlc.arg('author'));
+
+-- name: ListCommentsByTaskAsc :many
+SELECT * FROM comments
+WHERE task_id = sqlc.arg('task_id') AND (CAST(sqlc.arg('author') AS TEXT) = '' OR author = sqlc.arg('author'))
+ORDER BY created_at ASC, rowid ASC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListCommentsByTaskDesc :many
+SELECT * FROM comments
+WHERE task_id = sqlc.arg('task_id') AND (CAST(sqlc.arg('author') AS TEXT) = '' OR author = sqlc.arg('author'))
+ORDER BY created_at DESC, rowid DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
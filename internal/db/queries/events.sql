@@ -1,6 +1,6 @@
 -- name: CreateEvent :one
-INSERT INTO events (id, task_id, agent_id, type, message, details)
-VALUES (?, ?, ?, ?, ?, ?)
+INSERT INTO events (id, task_id, agent_id, type, message, details, seq)
+VALUES (?, ?, ?, ?, ?, ?, (SELECT COALESCE(MAX(seq), 0) + 1 FROM events))
 RETURNING *;
 
 -- name: ListEvents :many
@@ -11,3 +11,46 @@ SELECT * FROM events WHERE task_id = ? ORDER BY created_at DESC LIMIT ?;
 
 -- name: ListEventsByAgent :many  
 SELECT * FROM events WHERE agent_id = ? ORDER BY created_at DESC LIMIT ?;
+
+-- name: CountEventsByAgent :many
+SELECT agent_id, COUNT(*) AS count
+FROM events
+WHERE type = ? AND agent_id IS NOT NULL AND agent_id != '' AND created_at >= ?
+GROUP BY agent_id;
+
+-- name: ListEventsAfter :many
+SELECT seq, id, task_id, agent_id, type, message, details, created_at FROM events WHERE seq > ? ORDER BY seq LIMIT ?;
+
+-- name: GetLatestEventSeq :one
+SELECT CAST(COALESCE(MAX(seq), 0) AS INTEGER) AS seq FROM events;
+
+-- name: ListEventsByTaskIDs :many
+SELECT * FROM events WHERE task_id IN (sqlc.slice('task_ids')) ORDER BY created_at DESC;
+
+-- name: ListEventsFilteredAsc :many
+SELECT seq, id, task_id, agent_id, type, message, details, created_at
+FROM events
+WHERE seq > sqlc.arg('after') AND seq < sqlc.arg('before')
+  AND (CAST(sqlc.arg('task_id') AS TEXT) = '' OR task_id = sqlc.arg('task_id'))
+  AND (CAST(sqlc.arg('agent_id') AS TEXT) = '' OR agent_id = sqlc.arg('agent_id'))
+  AND (CAST(sqlc.arg('types') AS TEXT) = '' OR instr(',' || sqlc.arg('types') || ',', ',' || type || ',') > 0)
+  AND created_at >= CAST(sqlc.arg('since') AS TEXT)
+ORDER BY seq ASC
+LIMIT sqlc.arg('limit');
+
+-- name: ListEventsFilteredDesc :many
+SELECT seq, id, task_id, agent_id, type, message, details, created_at
+FROM events
+WHERE seq > sqlc.arg('after') AND seq < sqlc.arg('before')
+  AND (CAST(sqlc.arg('task_id') AS TEXT) = '' OR task_id = sqlc.arg('task_id'))
+  AND (CAST(sqlc.arg('agent_id') AS TEXT) = '' OR agent_id = sqlc.arg('agent_id'))
+  AND (CAST(sqlc.arg('types') AS TEXT) = '' OR instr(',' || sqlc.arg('types') || ',', ',' || type || ',') > 0)
+  AND created_at >= CAST(sqlc.arg('since') AS TEXT)
+ORDER BY seq DESC
+LIMIT sqlc.arg('limit');
+
+-- name: DetachAgentEvents :exec
+UPDATE events SET agent_id = NULL WHERE agent_id = ?;
+
+-- name: DetachProjectTaskEvents :exec
+UPDATE events SET task_id = NULL WHERE task_id IN (SELECT id FROM tasks WHERE project_id = ?);
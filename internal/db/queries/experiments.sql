@@ -0,0 +1,33 @@
+-- name: GetExperiment :one
+SELECT * FROM experiments WHERE id = ? LIMIT 1;
+
+-- name: ListExperiments :many
+SELECT * FROM experiments ORDER BY created_at DESC;
+
+-- name: ListActiveExperiments :many
+SELECT * FROM experiments WHERE status = 'active' ORDER BY created_at ASC;
+
+-- name: CreateExperiment :one
+INSERT INTO experiments (id, name, description, kind, arm_a, arm_b, project_id)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: UpdateExperiment :one
+UPDATE experiments SET name = ?, description = ?, status = ?, stopped_at = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ? RETURNING *;
+
+-- name: DeleteExperiment :exec
+DELETE FROM experiments WHERE id = ?;
+
+-- name: AddExperimentTask :exec
+INSERT OR IGNORE INTO experiment_tasks (task_id, experiment_id, arm)
+VALUES (?, ?, ?);
+
+-- name: GetExperimentTask :one
+SELECT * FROM experiment_tasks WHERE task_id = ? LIMIT 1;
+
+-- name: ListExperimentOutcomes :many
+SELECT et.arm, t.status, t.created_at, t.started_at, t.completed_at
+FROM experiment_tasks et
+JOIN tasks t ON t.id = et.task_id
+WHERE et.experiment_id = ?;
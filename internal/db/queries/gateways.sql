@@ -0,0 +1,17 @@
+-- name: GetGateway :one
+SELECT * FROM gateways WHERE id = ? LIMIT 1;
+
+-- name: ListGateways :many
+SELECT * FROM gateways ORDER BY name ASC;
+
+-- name: CreateGateway :one
+INSERT INTO gateways (id, name, url, token)
+VALUES (?, ?, ?, ?)
+RETURNING *;
+
+-- name: UpdateGateway :one
+UPDATE gateways SET name = ?, url = ?, token = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ? RETURNING *;
+
+-- name: DeleteGateway :exec
+DELETE FROM gateways WHERE id = ?;
@@ -0,0 +1,16 @@
+-- name: CreateGithubIssueLink :one
+INSERT INTO github_issue_links (task_id, project_id, repo, issue_number, state)
+VALUES (?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetGithubIssueLinkByIssue :one
+SELECT * FROM github_issue_links WHERE repo = ? AND issue_number = ? LIMIT 1;
+
+-- name: GetGithubIssueLinkByTask :one
+SELECT * FROM github_issue_links WHERE task_id = ? LIMIT 1;
+
+-- name: ListGithubIssueLinksByProject :many
+SELECT * FROM github_issue_links WHERE project_id = ? ORDER BY issue_number ASC;
+
+-- name: SetGithubIssueLinkState :exec
+UPDATE github_issue_links SET state = ?, synced_at = CURRENT_TIMESTAMP WHERE task_id = ?;
@@ -0,0 +1,10 @@
+-- name: CreateInboundEmail :one
+INSERT INTO inbound_emails (task_id, message_id, sender, subject)
+VALUES (?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetInboundEmailByMessageID :one
+SELECT * FROM inbound_emails WHERE message_id = ? LIMIT 1;
+
+-- name: ListInboundEmails :many
+SELECT * FROM inbound_emails ORDER BY created_at DESC LIMIT ?;
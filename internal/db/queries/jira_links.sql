@@ -0,0 +1,13 @@
+-- name: CreateJiraLink :one
+INSERT INTO jira_links (task_id, issue_key, jira_project, jira_status, synced_status)
+VALUES (?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetJiraLinkByIssue :one
+SELECT * FROM jira_links WHERE issue_key = ? LIMIT 1;
+
+-- name: ListJiraLinks :many
+SELECT * FROM jira_links ORDER BY created_at ASC;
+
+-- name: SetJiraLinkSynced :exec
+UPDATE jira_links SET jira_status = ?, synced_status = ?, synced_at = CURRENT_TIMESTAMP WHERE task_id = ?;
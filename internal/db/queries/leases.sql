@@ -0,0 +1,11 @@
+-- name: AcquireLease :execrows
+INSERT INTO leases (name, holder, expires_at)
+VALUES (?, ?, ?)
+ON CONFLICT (name) DO UPDATE SET holder = excluded.holder, expires_at = excluded.expires_at
+WHERE leases.holder = excluded.holder OR leases.expires_at < ?;
+
+-- name: GetLease :one
+SELECT * FROM leases WHERE name = ?;
+
+-- name: ReleaseLease :exec
+DELETE FROM leases WHERE name = ? AND holder = ?;
@@ -0,0 +1,38 @@
+-- name: CreateNotificationDelivery :one
+INSERT INTO notification_deliveries (id, kind, task_id, transition, agent_id, session_key)
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetNotificationDelivery :one
+SELECT * FROM notification_deliveries WHERE id = ? LIMIT 1;
+
+-- name: MarkNotificationDelivered :exec
+UPDATE notification_deliveries
+SET status = 'delivered', last_error = NULL, delivered_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: MarkNotificationFailed :exec
+UPDATE notification_deliveries
+SET status = 'failed', last_error = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND status NOT IN ('delivered', 'abandoned');
+
+-- name: RetryNotificationDelivery :exec
+UPDATE notification_deliveries
+SET status = 'pending', agent_id = ?, attempts = attempts + 1, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: AbandonNotificationDelivery :exec
+UPDATE notification_deliveries SET status = 'abandoned', updated_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: ListUndeliveredNotifications :many
+SELECT * FROM notification_deliveries
+WHERE status IN ('pending', 'failed')
+  AND updated_at < ?
+ORDER BY created_at ASC;
+
+-- name: ListNotificationDeliveriesByTask :many
+SELECT * FROM notification_deliveries WHERE task_id = ? ORDER BY created_at ASC;
+
+-- name: AbandonUndeliveredNotifications :exec
+UPDATE notification_deliveries SET status = 'abandoned', updated_at = CURRENT_TIMESTAMP
+WHERE task_id = ? AND kind = ? AND status IN ('pending', 'failed');
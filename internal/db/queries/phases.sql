@@ -21,3 +21,9 @@ UPDATE phases SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
 
 -- name: DeletePhase :exec
 DELETE FROM phases WHERE id = ?;
+
+-- name: AppendPhase :one
+INSERT INTO phases (id, task_id, sequence, title, description, status)
+SELECT ?1, ?2, COALESCE(MAX(sequence), 0) + 1, ?3, ?4, ?5
+FROM phases WHERE task_id = ?2
+RETURNING *;
@@ -0,0 +1,17 @@
+-- name: CreateProgressEntry :one
+INSERT INTO progress_entries (task_id, author, content) VALUES (?, ?, ?) RETURNING *;
+
+-- name: ListProgressEntries :many
+SELECT * FROM progress_entries WHERE task_id = ? AND id < ? ORDER BY id DESC LIMIT ?;
+
+-- name: ListProgressEntriesSince :many
+SELECT * FROM progress_entries WHERE created_at >= CAST(sqlc.arg('since') AS TEXT) ORDER BY id;
+
+-- name: RefreshProgressTxt :exec
+UPDATE tasks SET progress_txt = (
+    SELECT group_concat(content, char(10)) FROM (
+        SELECT content FROM (
+            SELECT id, content FROM progress_entries WHERE task_id = ? ORDER BY id DESC LIMIT ?
+        ) ORDER BY id
+    )
+), updated_at = CURRENT_TIMESTAMP WHERE id = ?;
@@ -0,0 +1,14 @@
+-- name: ListProjectSecrets :many
+SELECT * FROM project_secrets WHERE project_id = ? ORDER BY name ASC;
+
+-- name: GetProjectSecret :one
+SELECT * FROM project_secrets WHERE project_id = ? AND name = ? LIMIT 1;
+
+-- name: UpsertProjectSecret :one
+INSERT INTO project_secrets (project_id, name, sealed_value)
+VALUES (?, ?, ?)
+ON CONFLICT (project_id, name) DO UPDATE SET sealed_value = excluded.sealed_value, updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: DeleteProjectSecret :execrows
+DELETE FROM project_secrets WHERE project_id = ? AND name = ?;
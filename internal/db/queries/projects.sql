@@ -8,8 +8,8 @@ SELECT * FROM projects ORDER BY created_at DESC;
 SELECT * FROM projects WHERE status = ? ORDER BY created_at DESC;
 
 -- name: CreateProject :one
-INSERT INTO projects (id, name, description, status, color, location, default_branch, local_exec_branch, remote_merge_branch)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+INSERT INTO projects (id, name, description, status, color, location, default_branch, local_exec_branch, remote_merge_branch, key)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 RETURNING *;
 
 -- name: UpdateProject :one
@@ -22,6 +22,7 @@ UPDATE projects SET
     default_branch = ?,
     local_exec_branch = ?,
     remote_merge_branch = ?,
+    key = ?,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = ? 
 RETURNING *;
@@ -29,8 +30,63 @@ RETURNING *;
 -- name: DeleteProject :exec
 DELETE FROM projects WHERE id = ?;
 
+-- name: GetProjectDeletionImpact :one
+SELECT
+    (SELECT COUNT(*) FROM tasks WHERE tasks.project_id = projects.id) AS tasks,
+    (SELECT COUNT(*) FROM tasks WHERE tasks.project_id = projects.id AND tasks.status NOT IN ('done', 'failed', 'cancelled')) AS open_tasks,
+    (SELECT COUNT(*) FROM project_secrets WHERE project_secrets.project_id = projects.id) AS secrets,
+    (SELECT COUNT(*) FROM experiments WHERE experiments.project_id = projects.id) AS experiments,
+    (SELECT COUNT(*) FROM github_issue_links WHERE github_issue_links.project_id = projects.id) AS github_issues
+FROM projects WHERE projects.id = ?;
+
 -- name: GetProjectTaskCount :one
 SELECT COUNT(*) as count FROM tasks WHERE project_id = ?;
 
 -- name: GetProjectDoneTaskCount :one
 SELECT COUNT(*) as count FROM tasks WHERE project_id = ? AND status = 'done';
+
+-- name: SetProjectPathPolicy :exec
+UPDATE projects SET allowed_paths = ?, policy_action = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: SetProjectDelegationLimits :exec
+UPDATE projects SET max_subtask_depth = ?, max_concurrent_subtasks = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: SetProjectGithub :exec
+UPDATE projects SET github_repo = ?, github_label = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: ListProjectsByGithubRepo :many
+SELECT * FROM projects WHERE github_repo = ? ORDER BY created_at ASC;
+
+-- name: ListProjectsByIDs :many
+SELECT * FROM projects WHERE id IN (sqlc.slice('ids'));
+
+-- name: ListProjectsWithStats :many
+SELECT sqlc.embed(p),
+    COUNT(t.id) AS task_count,
+    COUNT(CASE WHEN t.status = 'done' THEN 1 END) AS done_count,
+    CAST(COALESCE(MAX(unixepoch(t.updated_at)), 0) AS INTEGER) AS last_activity
+FROM projects p
+LEFT JOIN tasks t ON t.project_id = p.id
+GROUP BY p.id
+ORDER BY p.created_at DESC;
+
+-- name: ListProjectsWithStatsByStatus :many
+SELECT sqlc.embed(p),
+    COUNT(t.id) AS task_count,
+    COUNT(CASE WHEN t.status = 'done' THEN 1 END) AS done_count,
+    CAST(COALESCE(MAX(unixepoch(t.updated_at)), 0) AS INTEGER) AS last_activity
+FROM projects p
+LEFT JOIN tasks t ON t.project_id = p.id
+WHERE p.status = ?
+GROUP BY p.id
+ORDER BY p.created_at DESC;
+
+-- name: GetProjectWithStats :one
+SELECT sqlc.embed(p),
+    COUNT(t.id) AS task_count,
+    COUNT(CASE WHEN t.status = 'done' THEN 1 END) AS done_count,
+    CAST(COALESCE(MAX(unixepoch(t.updated_at)), 0) AS INTEGER) AS last_activity
+FROM projects p
+LEFT JOIN tasks t ON t.project_id = p.id
+WHERE p.id = ?
+GROUP BY p.id;
@@ -10,3 +10,24 @@ UPDATE settings SET
     ralph_max_iterations = ?, ralph_auto_commit = ?, theme = ?,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = 'default' RETURNING *;
+
+-- name: SetModelRouting :exec
+UPDATE settings SET model_routing = ?, updated_at = CURRENT_TIMESTAMP WHERE id = 'default';
+
+-- name: SetQuietHours :exec
+UPDATE settings SET quiet_hours = ?, updated_at = CURRENT_TIMESTAMP WHERE id = 'default';
+
+-- name: SetMaintenance :exec
+UPDATE settings SET maintenance_since = ?, maintenance_reason = ?, updated_at = CURRENT_TIMESTAMP WHERE id = 'default';
+
+-- name: SetAnalyticsExport :exec
+UPDATE settings SET analytics_export_seq = ?, analytics_exported_at = ?, updated_at = CURRENT_TIMESTAMP WHERE id = 'default';
+
+-- name: SetStorage :exec
+UPDATE settings SET storage = ?, updated_at = CURRENT_TIMESTAMP WHERE id = 'default';
+
+-- name: SetNotificationTimeouts :exec
+UPDATE settings SET notification_timeouts = ?, updated_at = CURRENT_TIMESTAMP WHERE id = 'default';
+
+-- name: SetTaskScripts :exec
+UPDATE settings SET task_scripts = ?, updated_at = CURRENT_TIMESTAMP WHERE id = 'default';
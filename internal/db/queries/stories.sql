@@ -1,6 +1,12 @@
 -- name: GetStory :one
 SELECT * FROM stories WHERE id = ? LIMIT 1;
 
+-- name: ListFailedStoriesSince :many
+SELECT * FROM stories
+WHERE passes = FALSE AND last_error IS NOT NULL AND last_error != ''
+  AND updated_at >= CAST(sqlc.arg('since') AS TEXT)
+ORDER BY updated_at;
+
 -- name: ListStoriesByTask :many
 SELECT * FROM stories WHERE task_id = ? ORDER BY priority ASC, sequence ASC;
 
@@ -33,3 +39,9 @@ SELECT COUNT(*) FROM stories WHERE task_id = ? AND passes = TRUE;
 
 -- name: CountTotalStories :one
 SELECT COUNT(*) FROM stories WHERE task_id = ?;
+
+-- name: AppendStory :one
+INSERT INTO stories (id, task_id, sequence, title, description, priority, acceptance_criteria)
+SELECT ?1, ?2, COALESCE(MAX(sequence), 0) + 1, ?3, ?4, ?5, ?6
+FROM stories WHERE task_id = ?2
+RETURNING *;
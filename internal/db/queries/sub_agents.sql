@@ -17,3 +17,9 @@ UPDATE sub_agents SET status = ?, output = ?, error = ?, completed_at = CURRENT_
 
 -- name: DeleteSubAgent :exec
 DELETE FROM sub_agents WHERE id = ?;
+
+-- name: DeleteSubAgentsByOrchestrator :exec
+DELETE FROM sub_agents WHERE orchestrator_id = ?;
+
+-- name: DeleteSubAgentsByProject :exec
+DELETE FROM sub_agents WHERE task_id IN (SELECT id FROM tasks WHERE project_id = ?);
@@ -0,0 +1,23 @@
+-- name: CreateTaskAttempt :one
+INSERT INTO task_attempts (id, task_id, kind, agent_id, reason, outcome, error, retry_count, finished_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: FinishTaskAttempt :exec
+UPDATE task_attempts
+SET outcome = ?, error = ?, finished_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: ListTaskAttemptsByTask :many
+SELECT * FROM task_attempts WHERE task_id = ? ORDER BY created_at ASC, rowid ASC;
+
+-- name: CountWatchdogResetsByAgent :many
+SELECT agent_id, COUNT(*) AS count
+FROM task_attempts
+WHERE kind = 'watchdog_reset' AND agent_id IS NOT NULL AND created_at >= ?
+GROUP BY agent_id;
+
+-- name: InterruptPendingTaskAttempts :execrows
+UPDATE task_attempts
+SET outcome = 'failed', error = ?, finished_at = CURRENT_TIMESTAMP
+WHERE outcome = 'pending';
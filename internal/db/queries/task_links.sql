@@ -0,0 +1,32 @@
+-- name: CreateTaskLink :exec
+INSERT OR IGNORE INTO task_links (source_task_id, target_task_id, source_kind, source_id)
+VALUES (?, ?, ?, ?);
+
+-- name: DeleteTaskLinksBySource :exec
+DELETE FROM task_links WHERE source_kind = ? AND source_id = ?;
+
+-- name: ListTaskIDsByPrefix :many
+SELECT id FROM tasks WHERE id LIKE ? || '%' LIMIT 2;
+
+-- name: MoveTaskLinkSources :exec
+UPDATE task_links SET source_task_id = ? WHERE source_task_id = ?;
+
+-- name: RetargetTaskLinks :exec
+UPDATE OR IGNORE task_links SET target_task_id = ? WHERE target_task_id = ?;
+
+-- name: DeleteSelfTaskLinks :exec
+DELETE FROM task_links WHERE source_task_id = target_task_id;
+
+-- name: ListTaskLinks :many
+SELECT l.source_task_id, l.target_task_id, l.source_kind, l.source_id, l.created_at, t.title, t.status
+FROM task_links l
+JOIN tasks t ON t.id = l.target_task_id
+WHERE l.source_task_id = ?
+ORDER BY l.created_at ASC;
+
+-- name: ListTaskBacklinks :many
+SELECT l.source_task_id, l.target_task_id, l.source_kind, l.source_id, l.created_at, t.title, t.status
+FROM task_links l
+JOIN tasks t ON t.id = l.source_task_id
+WHERE l.target_task_id = ?
+ORDER BY l.created_at ASC;
@@ -0,0 +1,38 @@
+-- name: CreateTaskRedirect :exec
+INSERT INTO task_redirects (from_task_id, to_task_id) VALUES (?, ?);
+
+-- name: RetargetTaskRedirects :exec
+UPDATE task_redirects SET to_task_id = ? WHERE to_task_id = ?;
+
+-- name: GetTaskRedirect :one
+SELECT * FROM task_redirects WHERE from_task_id = ? LIMIT 1;
+
+-- name: MoveCommentsToTask :exec
+UPDATE comments SET task_id = ? WHERE task_id = ?;
+
+-- name: MoveEventsToTask :exec
+UPDATE events SET task_id = ? WHERE task_id = ?;
+
+-- name: MoveSubAgentsToTask :exec
+UPDATE sub_agents SET task_id = ? WHERE task_id = ?;
+
+-- name: MoveTaskAttemptsToTask :exec
+UPDATE task_attempts SET task_id = ? WHERE task_id = ?;
+
+-- name: MoveNotificationDeliveriesToTask :exec
+UPDATE notification_deliveries SET task_id = ? WHERE task_id = ?;
+
+-- name: MoveStoriesToTask :exec
+UPDATE stories SET task_id = ?, sequence = sequence + ?, updated_at = CURRENT_TIMESTAMP WHERE task_id = ?;
+
+-- name: ReparentSubtasks :exec
+UPDATE tasks SET parent_task_id = ?, updated_at = CURRENT_TIMESTAMP WHERE parent_task_id = ? AND id != ?;
+
+-- name: GetMaxStorySequence :one
+SELECT CAST(COALESCE(MAX(sequence), 0) AS INTEGER) FROM stories WHERE task_id = ?;
+
+-- name: MoveStory :execrows
+UPDATE stories SET task_id = ?, sequence = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND task_id = ?;
+
+-- name: SetStorySequence :exec
+UPDATE stories SET sequence = ? WHERE id = ?;
@@ -0,0 +1,13 @@
+-- name: CreateTaskResult :one
+INSERT INTO task_results (id, task_id, agent_id, kind, summary, body, metadata)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: ListTaskResultsByTask :many
+SELECT * FROM task_results WHERE task_id = ? ORDER BY created_at DESC, rowid DESC LIMIT ?;
+
+-- name: ListLatestTaskResults :many
+SELECT id, task_id, agent_id, kind, summary, created_at FROM task_results
+WHERE rowid IN (
+    SELECT MAX(rowid) FROM task_results WHERE task_id IN (sqlc.slice('task_ids')) GROUP BY task_id
+);
@@ -0,0 +1,4 @@
+-- name: NextTaskNumber :one
+INSERT INTO task_sequences (prefix, last_number) VALUES (?, 1)
+ON CONFLICT(prefix) DO UPDATE SET last_number = last_number + 1
+RETURNING last_number;
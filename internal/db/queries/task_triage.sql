@@ -0,0 +1,27 @@
+-- name: StartTaskTriage :one
+INSERT INTO task_triage (task_id, agent_id) VALUES (?, ?)
+ON CONFLICT (task_id) DO UPDATE SET
+    agent_id = excluded.agent_id, status = 'pending', suggestion = NULL, error = NULL,
+    decided_by = NULL, decided_at = NULL, updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: GetTaskTriage :one
+SELECT * FROM task_triage WHERE task_id = ? LIMIT 1;
+
+-- name: ListTaskTriageByStatus :many
+SELECT * FROM task_triage WHERE status = ? ORDER BY updated_at DESC;
+
+-- name: SetTaskTriageResult :exec
+UPDATE task_triage SET status = ?, suggestion = ?, error = ?, updated_at = CURRENT_TIMESTAMP WHERE task_id = ?;
+
+-- name: DecideTaskTriage :exec
+UPDATE task_triage SET status = ?, decided_by = ?, decided_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE task_id = ?;
+
+-- name: AddTaskLabel :exec
+INSERT OR IGNORE INTO task_labels (task_id, label) VALUES (?, ?);
+
+-- name: DeleteTaskLabels :exec
+DELETE FROM task_labels WHERE task_id = ?;
+
+-- name: ListTaskLabels :many
+SELECT task_id, label FROM task_labels WHERE task_id IN (sqlc.slice('task_ids')) ORDER BY task_id, label;
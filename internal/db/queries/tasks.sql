@@ -11,8 +11,8 @@ SELECT * FROM tasks WHERE status = ? ORDER BY priority ASC, created_at DESC;
 SELECT * FROM tasks WHERE agent_id = ? ORDER BY created_at DESC;
 
 -- name: CreateTask :one
-INSERT INTO tasks (id, title, description, agent_id, project_id, parent_task_id, status, priority, quality_checks, delegation_mode, scheduled_at, git_branch)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+INSERT INTO tasks (id, title, description, agent_id, project_id, parent_task_id, status, priority, quality_checks, delegation_mode, scheduled_at, git_branch, short_id)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 RETURNING *;
 
 -- name: GetTaskWithStoryCounts :one
@@ -38,7 +38,7 @@ UPDATE tasks SET
 WHERE id = ? RETURNING *;
 
 -- name: UpdateTaskStatus :exec
-UPDATE tasks SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
+UPDATE tasks SET status = ?, queue_position = NULL, deferred_until = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
 
 -- name: DeleteTask :exec
 DELETE FROM tasks WHERE id = ?;
@@ -46,11 +46,31 @@ DELETE FROM tasks WHERE id = ?;
 -- name: ListTasksByProject :many
 SELECT * FROM tasks WHERE project_id = ? ORDER BY priority ASC, created_at DESC;
 
+-- name: CancelOpenTasksByProject :exec
+UPDATE tasks SET status = 'cancelled', queue_position = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE project_id = ? AND status NOT IN ('done', 'failed', 'cancelled');
+
+-- name: DeleteTasksByProject :exec
+DELETE FROM tasks WHERE project_id = ?;
+
 -- name: ListSubtasks :many
 SELECT * FROM tasks WHERE parent_task_id = ? ORDER BY created_at ASC;
 
 -- name: ListQueuedTasksByAgent :many
-SELECT * FROM tasks WHERE agent_id = ? AND status = 'queued' ORDER BY priority ASC, created_at ASC;
+SELECT * FROM tasks WHERE agent_id = ? AND status = 'queued' ORDER BY queue_position IS NULL, queue_position ASC, priority ASC, created_at ASC;
+
+-- name: ListOpenTasksByAgent :many
+SELECT * FROM tasks WHERE agent_id = ? AND status NOT IN ('done', 'failed', 'cancelled') ORDER BY created_at ASC;
+
+-- name: UnassignOpenTasksByAgent :exec
+UPDATE tasks SET agent_id = NULL, status = 'backlog', queue_position = NULL, retry_count = 0, updated_at = CURRENT_TIMESTAMP
+WHERE agent_id = ? AND status NOT IN ('done', 'failed', 'cancelled');
+
+-- name: ReassignOpenTasksByAgent :exec
+UPDATE tasks SET agent_id = sqlc.arg(new_agent_id),
+    status = CASE WHEN status IN ('planning', 'discussing', 'executing', 'verifying') THEN 'queued' ELSE status END,
+    queue_position = NULL, retry_count = 0, updated_at = CURRENT_TIMESTAMP
+WHERE agent_id = sqlc.arg(agent_id) AND status NOT IN ('done', 'failed', 'cancelled');
 
 -- name: CountActiveTasksByAgent :one
 SELECT COUNT(*) FROM tasks WHERE agent_id = ? AND status IN ('executing', 'planning', 'discussing', 'verifying');
@@ -70,9 +90,6 @@ UPDATE tasks SET status = 'backlog', agent_id = NULL, retry_count = 0, updated_a
 -- name: ResetTaskRetryCount :exec
 UPDATE tasks SET retry_count = 0 WHERE id = ?;
 
--- name: AppendProgressTxt :exec
-UPDATE tasks SET progress_txt = COALESCE(progress_txt || char(10), '') || ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
-
 -- name: SetTaskScheduledAt :exec
 UPDATE tasks SET scheduled_at = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
 
@@ -98,3 +115,139 @@ WHERE retry_at IS NOT NULL
   AND retry_at <= CURRENT_TIMESTAMP
   AND status = 'backlog'
 ORDER BY retry_at ASC;
+
+-- name: SetTaskQueuePosition :exec
+UPDATE tasks SET queue_position = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: TransferTask :one
+UPDATE tasks SET
+    agent_id = ?, status = ?, retry_count = 0, retry_at = NULL, queue_position = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND status = ? RETURNING *;
+
+-- name: ListQueuedTasksByGroup :many
+SELECT * FROM tasks
+WHERE group_id = ? AND agent_id IS NULL AND status = 'queued'
+ORDER BY queue_position IS NULL, queue_position ASC, priority ASC, created_at ASC;
+
+-- name: ListQueuedGroupTasksForAgent :many
+SELECT t.* FROM tasks t
+JOIN agent_group_members m ON m.group_id = t.group_id
+WHERE m.agent_id = ? AND t.agent_id IS NULL AND t.status = 'queued'
+ORDER BY t.queue_position IS NULL, t.queue_position ASC, t.priority ASC, t.created_at ASC;
+
+-- name: AssignTaskToGroup :one
+UPDATE tasks SET
+    group_id = ?, agent_id = NULL, status = 'queued', queue_position = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ? RETURNING *;
+
+-- name: ClaimGroupTask :one
+UPDATE tasks SET
+    agent_id = ?, status = 'backlog', queue_position = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND agent_id IS NULL AND status = 'queued' RETURNING *;
+
+-- name: CountOpenTasksByAgent :one
+SELECT COUNT(*) FROM tasks WHERE agent_id = ? AND status IN ('backlog', 'queued', 'planning', 'discussing', 'executing', 'verifying', 'review');
+
+-- name: SetTaskDeferredUntil :exec
+UPDATE tasks SET deferred_until = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: ClearTaskDeferredUntil :exec
+UPDATE tasks SET deferred_until = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: ListDeferredDueTasks :many
+SELECT * FROM tasks
+WHERE deferred_until IS NOT NULL
+  AND deferred_until <= CURRENT_TIMESTAMP
+  AND status IN ('backlog', 'queued')
+ORDER BY deferred_until ASC;
+
+-- name: GetTaskByShortID :one
+SELECT * FROM tasks WHERE short_id = ? LIMIT 1;
+
+-- name: SetTaskSecretNames :exec
+UPDATE tasks SET secret_names = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: SetTaskProgress :exec
+UPDATE tasks SET progress = ?, progress_explicit = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: SetTaskContextSummary :exec
+UPDATE tasks SET context_summary = ?, context_summarized_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: SetTaskDelegationLimits :exec
+UPDATE tasks SET max_subtask_depth = ?, max_concurrent_subtasks = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: SetTaskFailureReason :exec
+UPDATE tasks SET failure_reason = ? WHERE id = ?;
+
+-- name: CountTaskFailures :many
+SELECT project_id, agent_id, failure_reason, COUNT(*) AS count
+FROM tasks
+WHERE failure_reason IS NOT NULL
+GROUP BY project_id, agent_id, failure_reason;
+
+-- name: CountQueuedTasks :many
+SELECT agent_id, group_id, COUNT(*) AS count
+FROM tasks
+WHERE status = 'queued'
+GROUP BY agent_id, group_id;
+
+-- name: CountTasksByStatus :many
+SELECT status, COUNT(*) AS count
+FROM tasks
+GROUP BY status;
+
+-- name: SetTaskModel :exec
+UPDATE tasks SET model = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: SetTaskRoutedModel :exec
+UPDATE tasks SET routed_model = ? WHERE id = ?;
+
+-- name: StampTaskTimes :exec
+UPDATE tasks SET
+    started_at = CASE WHEN started_at IS NULL AND status NOT IN ('backlog', 'queued') THEN CURRENT_TIMESTAMP ELSE started_at END,
+    completed_at = CASE WHEN status IN ('done', 'failed', 'cancelled') THEN COALESCE(completed_at, CURRENT_TIMESTAMP) ELSE NULL END
+WHERE id = ?;
+
+-- name: ListAgentTaskOutcomes :many
+SELECT agent_id, status, created_at, started_at, completed_at
+FROM tasks
+WHERE agent_id IS NOT NULL
+  AND status IN ('done', 'failed')
+  AND COALESCE(completed_at, updated_at) >= ?;
+
+-- name: SetTaskReview :exec
+UPDATE tasks SET requires_review = ?, reviewer_agent_id = ?, reviewer_user = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: ListCalendarTasks :many
+SELECT * FROM tasks
+WHERE scheduled_at IS NOT NULL
+   OR retry_at IS NOT NULL
+ORDER BY COALESCE(scheduled_at, retry_at) ASC;
+
+-- name: UpdateTaskDetails :exec
+UPDATE tasks SET title = ?, description = ?, priority = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: ListTasksByIDs :many
+SELECT * FROM tasks WHERE id IN (sqlc.slice('ids'));
+
+-- name: ListTasksByParentIDs :many
+SELECT * FROM tasks WHERE parent_task_id IN (sqlc.slice('parent_ids')) ORDER BY created_at ASC;
+
+-- name: ListTasksByAgentIDs :many
+SELECT * FROM tasks WHERE agent_id IN (sqlc.slice('agent_ids')) ORDER BY created_at DESC;
+
+-- name: ListTasksByProjectIDs :many
+SELECT * FROM tasks WHERE project_id IN (sqlc.slice('project_ids')) ORDER BY priority ASC, created_at DESC;
+
+-- name: CountQueuedTasksByAgent :one
+SELECT COUNT(*) FROM tasks WHERE agent_id = ? AND status = 'queued';
+
+-- name: CountQueuedTasksByGroup :one
+SELECT COUNT(*) FROM tasks WHERE group_id = ? AND agent_id IS NULL AND status = 'queued';
+
+-- name: CountQueuedGroupTasksForAgent :one
+SELECT COUNT(*) FROM tasks t
+JOIN agent_group_members m ON m.group_id = t.group_id
+WHERE m.agent_id = ? AND t.agent_id IS NULL AND t.status = 'queued';
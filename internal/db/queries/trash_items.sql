@@ -0,0 +1,19 @@
+-- name: CreateTrashItem :one
+INSERT INTO trash_items (id, kind, item_id, title, snapshot, expires_at)
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetTrashItem :one
+SELECT * FROM trash_items WHERE id = ? AND expires_at > CURRENT_TIMESTAMP LIMIT 1;
+
+-- name: ListTrashItems :many
+SELECT id, kind, item_id, title, deleted_at, expires_at FROM trash_items
+WHERE expires_at > CURRENT_TIMESTAMP
+  AND (CAST(sqlc.arg('kind') AS TEXT) = '' OR kind = sqlc.arg('kind'))
+ORDER BY deleted_at DESC;
+
+-- name: DeleteTrashItem :exec
+DELETE FROM trash_items WHERE id = ?;
+
+-- name: DeleteExpiredTrashItems :execrows
+DELETE FROM trash_items WHERE expires_at <= CURRENT_TIMESTAMP;
@@ -11,7 +11,7 @@ import (
 )
 
 const getSettings = `-- name: GetSettings :one
-SELECT id, openclaw_gateway_url, openclaw_gateway_token, default_model, max_parallel_executions, default_project_directory, gsd_depth, gsd_mode, gsd_research_enabled, gsd_plan_check_enabled, gsd_verifier_enabled, ralph_max_iterations, ralph_auto_commit, theme, updated_at FROM settings WHERE id = 'default' LIMIT 1
+SELECT id, openclaw_gateway_url, openclaw_gateway_token, default_model, max_parallel_executions, default_project_directory, gsd_depth, gsd_mode, gsd_research_enabled, gsd_plan_check_enabled, gsd_verifier_enabled, ralph_max_iterations, ralph_auto_commit, theme, updated_at, model_routing, quiet_hours, maintenance_since, maintenance_reason, analytics_export_seq, analytics_exported_at, storage, notification_timeouts, task_scripts FROM settings WHERE id = 'default' LIMIT 1
 `
 
 func (q *Queries) GetSettings(ctx context.Context) (Setting, error) {
@@ -33,10 +33,92 @@ func (q *Queries) GetSettings(ctx context.Context) (Setting, error) {
 		&i.RalphAutoCommit,
 		&i.Theme,
 		&i.UpdatedAt,
+		&i.ModelRouting,
+		&i.QuietHours,
+		&i.MaintenanceSince,
+		&i.MaintenanceReason,
+		&i.AnalyticsExportSeq,
+		&i.AnalyticsExportedAt,
+		&i.Storage,
+		&i.NotificationTimeouts,
+		&i.TaskScripts,
 	)
 	return i, err
 }
 
+const setAnalyticsExport = `-- name: SetAnalyticsExport :exec
+UPDATE settings SET analytics_export_seq = ?, analytics_exported_at = ?, updated_at = CURRENT_TIMESTAMP WHERE id = 'default'
+`
+
+type SetAnalyticsExportParams struct {
+	AnalyticsExportSeq  int64        `json:"analytics_export_seq"`
+	AnalyticsExportedAt sql.NullTime `json:"analytics_exported_at"`
+}
+
+func (q *Queries) SetAnalyticsExport(ctx context.Context, arg SetAnalyticsExportParams) error {
+	_, err := q.db.ExecContext(ctx, setAnalyticsExport, arg.AnalyticsExportSeq, arg.AnalyticsExportedAt)
+	return err
+}
+
+const setMaintenance = `-- name: SetMaintenance :exec
+UPDATE settings SET maintenance_since = ?, maintenance_reason = ?, updated_at = CURRENT_TIMESTAMP WHERE id = 'default'
+`
+
+type SetMaintenanceParams struct {
+	MaintenanceSince  sql.NullTime   `json:"maintenance_since"`
+	MaintenanceReason sql.NullString `json:"maintenance_reason"`
+}
+
+func (q *Queries) SetMaintenance(ctx context.Context, arg SetMaintenanceParams) error {
+	_, err := q.db.ExecContext(ctx, setMaintenance, arg.MaintenanceSince, arg.MaintenanceReason)
+	return err
+}
+
+const setModelRouting = `-- name: SetModelRouting :exec
+UPDATE settings SET model_routing = ?, updated_at = CURRENT_TIMESTAMP WHERE id = 'default'
+`
+
+func (q *Queries) SetModelRouting(ctx context.Context, modelRouting sql.NullString) error {
+	_, err := q.db.ExecContext(ctx, setModelRouting, modelRouting)
+	return err
+}
+
+const setNotificationTimeouts = `-- name: SetNotificationTimeouts :exec
+UPDATE settings SET notification_timeouts = ?, updated_at = CURRENT_TIMESTAMP WHERE id = 'default'
+`
+
+func (q *Queries) SetNotificationTimeouts(ctx context.Context, notificationTimeouts sql.NullString) error {
+	_, err := q.db.ExecContext(ctx, setNotificationTimeouts, notificationTimeouts)
+	return err
+}
+
+const setQuietHours = `-- name: SetQuietHours :exec
+UPDATE settings SET quiet_hours = ?, updated_at = CURRENT_TIMESTAMP WHERE id = 'default'
+`
+
+func (q *Queries) SetQuietHours(ctx context.Context, quietHours sql.NullString) error {
+	_, err := q.db.ExecContext(ctx, setQuietHours, quietHours)
+	return err
+}
+
+const setStorage = `-- name: SetStorage :exec
+UPDATE settings SET storage = ?, updated_at = CURRENT_TIMESTAMP WHERE id = 'default'
+`
+
+func (q *Queries) SetStorage(ctx context.Context, storage sql.NullString) error {
+	_, err := q.db.ExecContext(ctx, setStorage, storage)
+	return err
+}
+
+const setTaskScripts = `-- name: SetTaskScripts :exec
+UPDATE settings SET task_scripts = ?, updated_at = CURRENT_TIMESTAMP WHERE id = 'default'
+`
+
+func (q *Queries) SetTaskScripts(ctx context.Context, taskScripts sql.NullString) error {
+	_, err := q.db.ExecContext(ctx, setTaskScripts, taskScripts)
+	return err
+}
+
 const updateSettings = `-- name: UpdateSettings :one
 UPDATE settings SET
     openclaw_gateway_url = ?, openclaw_gateway_token = ?,
@@ -45,7 +127,7 @@ UPDATE settings SET
     gsd_depth = ?, gsd_mode = ?, gsd_research_enabled = ?, gsd_plan_check_enabled = ?, gsd_verifier_enabled = ?,
     ralph_max_iterations = ?, ralph_auto_commit = ?, theme = ?,
     updated_at = CURRENT_TIMESTAMP
-WHERE id = 'default' RETURNING id, openclaw_gateway_url, openclaw_gateway_token, default_model, max_parallel_executions, default_project_directory, gsd_depth, gsd_mode, gsd_research_enabled, gsd_plan_check_enabled, gsd_verifier_enabled, ralph_max_iterations, ralph_auto_commit, theme, updated_at
+WHERE id = 'default' RETURNING id, openclaw_gateway_url, openclaw_gateway_token, default_model, max_parallel_executions, default_project_directory, gsd_depth, gsd_mode, gsd_research_enabled, gsd_plan_check_enabled, gsd_verifier_enabled, ralph_max_iterations, ralph_auto_commit, theme, updated_at, model_routing, quiet_hours, maintenance_since, maintenance_reason, analytics_export_seq, analytics_exported_at, storage, notification_timeouts, task_scripts
 `
 
 type UpdateSettingsParams struct {
@@ -97,6 +179,15 @@ func (q *Queries) UpdateSettings(ctx context.Context, arg UpdateSettingsParams)
 		&i.RalphAutoCommit,
 		&i.Theme,
 		&i.UpdatedAt,
+		&i.ModelRouting,
+		&i.QuietHours,
+		&i.MaintenanceSince,
+		&i.MaintenanceReason,
+		&i.AnalyticsExportSeq,
+		&i.AnalyticsExportedAt,
+		&i.Storage,
+		&i.NotificationTimeouts,
+		&i.TaskScripts,
 	)
 	return i, err
 }
@@ -0,0 +1,483 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrSnapshotConflict is returned when restoring rows whose first row,
+	// the one that was deleted, exists again.
+	ErrSnapshotConflict = errors.New("the deleted row exists again")
+	// ErrSnapshotParentGone is returned when restoring rows whose first row
+	// belongs to a row that has been deleted since, e.g. a comment's task.
+	ErrSnapshotParentGone = errors.New("what the deleted row belongs to has been deleted")
+)
+
+// Snapshot is a copy of rows deleted together, to put them back: the rows,
+// the deleted row first, and the references to them that were set to NULL.
+type Snapshot struct {
+	Rows []SnapshotRow `json:"rows"`
+	Refs []SnapshotRef `json:"refs,omitempty"`
+}
+
+// SnapshotRow is a deleted row, by column.
+type SnapshotRow struct {
+	Table  string                 `json:"table"`
+	Values map[string]interface{} `json:"values"`
+}
+
+// SnapshotRef is a reference to a deleted row that was set to NULL: Column
+// of the rows of Table with the primary keys Keys held Value, Parent's
+// ParentColumn.
+type SnapshotRef struct {
+	Table        string                   `json:"table"`
+	Column       string                   `json:"column"`
+	Parent       string                   `json:"parent"`
+	ParentColumn string                   `json:"parent_column"`
+	Value        interface{}              `json:"value"`
+	Keys         []map[string]interface{} `json:"keys"`
+}
+
+// Add appends the rows and references of o, deleted with s.
+func (s *Snapshot) Add(o Snapshot) {
+	s.Rows = append(s.Rows, o.Rows...)
+	s.Refs = append(s.Refs, o.Refs...)
+}
+
+// ParseSnapshot reads a snapshot stored as JSON, with its numbers as int64
+// or float64 again.
+func ParseSnapshot(data string) (Snapshot, error) {
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.UseNumber()
+	var s Snapshot
+	if err := dec.Decode(&s); err != nil {
+		return s, err
+	}
+	for _, row := range s.Rows {
+		for col, v := range row.Values {
+			row.Values[col] = fromJSON(v)
+		}
+	}
+	for i := range s.Refs {
+		s.Refs[i].Value = fromJSON(s.Refs[i].Value)
+		for _, key := range s.Refs[i].Keys {
+			for col, v := range key {
+				key[col] = fromJSON(v)
+			}
+		}
+	}
+	return s, nil
+}
+
+func fromJSON(v interface{}) interface{} {
+	n, ok := v.(json.Number)
+	if !ok {
+		return v
+	}
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	f, _ := n.Float64()
+	return f
+}
+
+// foreignKey is a column of table referencing parentColumn of another table.
+type foreignKey struct {
+	table, column, parent, parentColumn, onDelete string
+}
+
+// DeleteWithSnapshot deletes the rows of table whose column is value and
+// returns a snapshot of them with what goes with them: the rows deleted
+// through ON DELETE CASCADE and the references set to NULL, by ON DELETE SET
+// NULL or, for references without an action, here. Table and column names
+// are put into SQL as they are. Run it in a transaction.
+func (q *Queries) DeleteWithSnapshot(ctx context.Context, table, column string, value interface{}) (Snapshot, error) {
+	var snap Snapshot
+	if err := q.snapshot(ctx, &snap, table, column, value, map[string]bool{}); err != nil {
+		return snap, err
+	}
+	_, err := q.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %q WHERE %q = ?`, table, column), value)
+	return snap, err
+}
+
+// snapshot adds the rows of table whose column is value to snap, then what
+// references them; seen holds the rows added so far.
+func (q *Queries) snapshot(ctx context.Context, snap *Snapshot, table, column string, value interface{}, seen map[string]bool) error {
+	rows, err := q.selectRows(ctx, table, column, value)
+	if err != nil {
+		return err
+	}
+	refs, err := q.referencing(ctx, table)
+	if err != nil {
+		return err
+	}
+	pk, err := q.primaryKey(ctx, table)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		id := table + "|" + rowKey(row, pk)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		snap.Rows = append(snap.Rows, SnapshotRow{Table: table, Values: row})
+
+		for _, fk := range refs {
+			v := row[fk.parentColumn]
+			if v == nil {
+				continue
+			}
+			if fk.onDelete == "CASCADE" {
+				if err := q.snapshot(ctx, snap, fk.table, fk.column, v, seen); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := q.snapshotRef(ctx, snap, fk, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// snapshotRef records the rows whose fk references v, clearing the
+// reference unless ON DELETE SET NULL will.
+func (q *Queries) snapshotRef(ctx context.Context, snap *Snapshot, fk foreignKey, v interface{}) error {
+	pk, err := q.primaryKey(ctx, fk.table)
+	if err != nil {
+		return err
+	}
+	rows, err := q.selectRows(ctx, fk.table, fk.column, v)
+	if err != nil || len(rows) == 0 {
+		return err
+	}
+	ref := SnapshotRef{Table: fk.table, Column: fk.column, Parent: fk.parent, ParentColumn: fk.parentColumn, Value: v}
+	for _, row := range rows {
+		key := map[string]interface{}{}
+		for _, col := range pk {
+			key[col] = row[col]
+		}
+		ref.Keys = append(ref.Keys, key)
+	}
+	snap.Refs = append(snap.Refs, ref)
+	if fk.onDelete == "SET NULL" {
+		return nil
+	}
+	_, err = q.db.ExecContext(ctx, fmt.Sprintf(`UPDATE %q SET %q = NULL WHERE %q = ?`, fk.table, fk.column, fk.column), v)
+	return err
+}
+
+// RestoreSnapshot puts deleted rows back with the references to them. A row
+// belonging (ON DELETE CASCADE) to a row deleted since is left out, with what
+// belongs to it; other references to rows deleted since stay NULL. Run it in
+// a transaction.
+func (q *Queries) RestoreSnapshot(ctx context.Context, snap Snapshot) error {
+	if len(snap.Rows) == 0 {
+		return nil
+	}
+	// The rows go back in any order; they are checked against each other at
+	// commit
+	if _, err := q.db.ExecContext(ctx, `PRAGMA defer_foreign_keys = ON`); err != nil {
+		return err
+	}
+
+	first := snap.Rows[0]
+	pk, err := q.primaryKey(ctx, first.Table)
+	if err != nil {
+		return err
+	}
+	exists, err := q.rowExists(ctx, first.Table, pk, first.Values)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrSnapshotConflict
+	}
+
+	fks := map[string][]foreignKey{}
+	for _, row := range snap.Rows {
+		if _, ok := fks[row.Table]; !ok {
+			if fks[row.Table], err = q.references(ctx, row.Table); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Leave out the rows whose parent is neither there nor restored, until
+	// none is left out anymore
+	kept := make([]bool, len(snap.Rows))
+	for i := range kept {
+		kept[i] = true
+	}
+	restored := func(table, column string, v interface{}) bool {
+		for i, row := range snap.Rows {
+			if kept[i] && row.Table == table && sameValue(row.Values[column], v) {
+				return true
+			}
+		}
+		return false
+	}
+	missing := func(fk foreignKey, v interface{}) (bool, error) {
+		if restored(fk.parent, fk.parentColumn, v) {
+			return false, nil
+		}
+		found, err := q.rowExists(ctx, fk.parent, []string{fk.parentColumn}, map[string]interface{}{fk.parentColumn: v})
+		return !found, err
+	}
+	for changed := true; changed; {
+		changed = false
+		for i, row := range snap.Rows {
+			if !kept[i] {
+				continue
+			}
+			for _, fk := range fks[row.Table] {
+				v := row.Values[fk.column]
+				if v == nil || fk.onDelete == "SET NULL" {
+					continue
+				}
+				gone, err := missing(fk, v)
+				if err != nil {
+					return err
+				}
+				if gone {
+					kept[i] = false
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	if !kept[0] {
+		return ErrSnapshotParentGone
+	}
+
+	for i, row := range snap.Rows {
+		if !kept[i] {
+			continue
+		}
+		values := map[string]interface{}{}
+		for col, v := range row.Values {
+			values[col] = v
+		}
+		for _, fk := range fks[row.Table] {
+			if v := values[fk.column]; v != nil && fk.onDelete == "SET NULL" {
+				gone, err := missing(fk, v)
+				if err != nil {
+					return err
+				}
+				if gone {
+					values[fk.column] = nil
+				}
+			}
+		}
+		if err := q.insertRow(ctx, row.Table, values); err != nil {
+			return fmt.Errorf("restore %s: %w", row.Table, err)
+		}
+	}
+
+	for _, ref := range snap.Refs {
+		if !restored(ref.Parent, ref.ParentColumn, ref.Value) {
+			continue
+		}
+		for _, key := range ref.Keys {
+			where, args := keyClause(key)
+			args = append([]interface{}{ref.Value}, args...)
+			_, err := q.db.ExecContext(ctx,
+				fmt.Sprintf(`UPDATE %q SET %q = ? WHERE %s AND %q IS NULL`, ref.Table, ref.Column, where, ref.Column), args...)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// selectRows returns the rows of table whose column is value, as they would
+// be stored again: times in the format SQLite's CURRENT_TIMESTAMP uses.
+func (q *Queries) selectRows(ctx context.Context, table, column string, value interface{}) ([]map[string]interface{}, error) {
+	rows, err := q.db.QueryContext(ctx, fmt.Sprintf(`SELECT * FROM %q WHERE %q = ?`, table, column), value)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var items []map[string]interface{}
+	for rows.Next() {
+		values := make([]interface{}, len(cols))
+		dest := make([]interface{}, len(cols))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		row := map[string]interface{}{}
+		for i, col := range cols {
+			switch v := values[i].(type) {
+			case time.Time:
+				row[col] = formatTime(v)
+			case []byte:
+				row[col] = string(v)
+			default:
+				row[col] = v
+			}
+		}
+		items = append(items, row)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	return items, rows.Err()
+}
+
+func formatTime(t time.Time) string {
+	if t.Nanosecond() == 0 {
+		return t.UTC().Format("2006-01-02 15:04:05")
+	}
+	return t.UTC().Format("2006-01-02 15:04:05.999999999-07:00")
+}
+
+func (q *Queries) insertRow(ctx context.Context, table string, values map[string]interface{}) error {
+	cols := make([]string, 0, len(values))
+	for col := range values {
+		cols = append(cols, col)
+	}
+	sort.Strings(cols)
+	quoted := make([]string, len(cols))
+	args := make([]interface{}, len(cols))
+	for i, col := range cols {
+		quoted[i] = fmt.Sprintf("%q", col)
+		args[i] = values[col]
+	}
+	_, err := q.db.ExecContext(ctx,
+		fmt.Sprintf(`INSERT INTO %q (%s) VALUES (%s)`, table, strings.Join(quoted, ", "),
+			strings.TrimSuffix(strings.Repeat("?, ", len(cols)), ", ")), args...)
+	return err
+}
+
+func (q *Queries) rowExists(ctx context.Context, table string, key []string, values map[string]interface{}) (bool, error) {
+	k := map[string]interface{}{}
+	for _, col := range key {
+		k[col] = values[col]
+	}
+	where, args := keyClause(k)
+	var one int
+	err := q.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT 1 FROM %q WHERE %s LIMIT 1`, table, where), args...).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// keyClause matches the columns of key to its values.
+func keyClause(key map[string]interface{}) (string, []interface{}) {
+	cols := make([]string, 0, len(key))
+	for col := range key {
+		cols = append(cols, col)
+	}
+	sort.Strings(cols)
+	conds := make([]string, len(cols))
+	args := make([]interface{}, len(cols))
+	for i, col := range cols {
+		conds[i] = fmt.Sprintf("%q = ?", col)
+		args[i] = key[col]
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+func rowKey(row map[string]interface{}, pk []string) string {
+	parts := make([]string, len(pk))
+	for i, col := range pk {
+		parts[i] = fmt.Sprint(row[col])
+	}
+	return strings.Join(parts, "|")
+}
+
+func sameValue(a, b interface{}) bool {
+	return a != nil && b != nil && fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+// primaryKey returns the primary key columns of table, rowid if it has none.
+func (q *Queries) primaryKey(ctx context.Context, table string) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, `SELECT name FROM pragma_table_info(?) WHERE pk > 0 ORDER BY pk`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var cols []string
+	for rows.Next() {
+		var col string
+		if err := rows.Scan(&col); err != nil {
+			return nil, err
+		}
+		cols = append(cols, col)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(cols) == 0 {
+		cols = []string{"rowid"}
+	}
+	return cols, nil
+}
+
+// referencing returns the foreign keys referencing table.
+func (q *Queries) referencing(ctx context.Context, table string) ([]foreignKey, error) {
+	return q.foreignKeys(ctx, `SELECT m.name, p."from", p."table", p."to", p.on_delete
+FROM sqlite_master m JOIN pragma_foreign_key_list(m.name) p
+WHERE m.type = 'table' AND p."table" = ?
+ORDER BY m.name, p.id`, table)
+}
+
+// references returns the foreign keys of table.
+func (q *Queries) references(ctx context.Context, table string) ([]foreignKey, error) {
+	return q.foreignKeys(ctx, `SELECT ?1, p."from", p."table", p."to", p.on_delete
+FROM pragma_foreign_key_list(?1) p ORDER BY p.id`, table)
+}
+
+func (q *Queries) foreignKeys(ctx context.Context, query, table string) ([]foreignKey, error) {
+	rows, err := q.db.QueryContext(ctx, query, table)
+	if err != nil {
+		return nil, err
+	}
+	var fks []foreignKey
+	var to []sql.NullString
+	for rows.Next() {
+		var fk foreignKey
+		var parentColumn sql.NullString
+		if err := rows.Scan(&fk.table, &fk.column, &fk.parent, &parentColumn, &fk.onDelete); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		fks = append(fks, fk)
+		to = append(to, parentColumn)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// A foreign key naming no column references the primary key
+	for i := range fks {
+		fks[i].parentColumn = to[i].String
+		if !to[i].Valid {
+			pk, err := q.primaryKey(ctx, fks[i].parent)
+			if err != nil {
+				return nil, err
+			}
+			fks[i].parentColumn = pk[0]
+		}
+	}
+	return fks, nil
+}
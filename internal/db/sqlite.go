@@ -1,7 +1,9 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -34,3 +36,34 @@ func NewDB() (*sql.DB, error) {
 
 	return db, nil
 }
+
+// EnableIncrementalVacuum switches the database to incremental auto-vacuum,
+// so that later optimizations can give free pages back without rewriting
+// the file, and reports whether it had to. Switching an existing database
+// takes one full VACUUM, which locks the database for as long as it takes
+// to rewrite it: call it at startup, before anything else uses the
+// database.
+func EnableIncrementalVacuum(ctx context.Context, db *sql.DB) (bool, error) {
+	// auto_vacuum is changed per connection and applied by VACUUM on that
+	// same connection
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	var autoVacuum int
+	if err := conn.QueryRowContext(ctx, "PRAGMA auto_vacuum").Scan(&autoVacuum); err != nil {
+		return false, fmt.Errorf("auto_vacuum: %w", err)
+	}
+	if autoVacuum == 2 { // incremental
+		return false, nil
+	}
+	if _, err := conn.ExecContext(ctx, "PRAGMA auto_vacuum = INCREMENTAL"); err != nil {
+		return false, fmt.Errorf("auto_vacuum: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "VACUUM"); err != nil {
+		return false, fmt.Errorf("vacuum: %w", err)
+	}
+	return true, nil
+}
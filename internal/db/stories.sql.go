@@ -10,6 +10,50 @@ import (
 	"database/sql"
 )
 
+const appendStory = `-- name: AppendStory :one
+INSERT INTO stories (id, task_id, sequence, title, description, priority, acceptance_criteria)
+SELECT ?1, ?2, COALESCE(MAX(sequence), 0) + 1, ?3, ?4, ?5, ?6
+FROM stories WHERE task_id = ?2
+RETURNING id, task_id, sequence, title, description, priority, passes, acceptance_criteria, iterations, last_error, session_key, created_at, updated_at
+`
+
+type AppendStoryParams struct {
+	ID                 string         `json:"id"`
+	TaskID             string         `json:"task_id"`
+	Title              string         `json:"title"`
+	Description        sql.NullString `json:"description"`
+	Priority           sql.NullInt64  `json:"priority"`
+	AcceptanceCriteria sql.NullString `json:"acceptance_criteria"`
+}
+
+func (q *Queries) AppendStory(ctx context.Context, arg AppendStoryParams) (Story, error) {
+	row := q.db.QueryRowContext(ctx, appendStory,
+		arg.ID,
+		arg.TaskID,
+		arg.Title,
+		arg.Description,
+		arg.Priority,
+		arg.AcceptanceCriteria,
+	)
+	var i Story
+	err := row.Scan(
+		&i.ID,
+		&i.TaskID,
+		&i.Sequence,
+		&i.Title,
+		&i.Description,
+		&i.Priority,
+		&i.Passes,
+		&i.AcceptanceCriteria,
+		&i.Iterations,
+		&i.LastError,
+		&i.SessionKey,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const countPassedStories = `-- name: CountPassedStories :one
 SELECT COUNT(*) FROM stories WHERE task_id = ? AND passes = TRUE
 `
@@ -136,6 +180,50 @@ func (q *Queries) GetStory(ctx context.Context, id string) (Story, error) {
 	return i, err
 }
 
+const listFailedStoriesSince = `-- name: ListFailedStoriesSince :many
+SELECT id, task_id, sequence, title, description, priority, passes, acceptance_criteria, iterations, last_error, session_key, created_at, updated_at FROM stories
+WHERE passes = FALSE AND last_error IS NOT NULL AND last_error != ''
+  AND updated_at >= CAST(?1 AS TEXT)
+ORDER BY updated_at
+`
+
+func (q *Queries) ListFailedStoriesSince(ctx context.Context, since string) ([]Story, error) {
+	rows, err := q.db.QueryContext(ctx, listFailedStoriesSince, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Story{}
+	for rows.Next() {
+		var i Story
+		if err := rows.Scan(
+			&i.ID,
+			&i.TaskID,
+			&i.Sequence,
+			&i.Title,
+			&i.Description,
+			&i.Priority,
+			&i.Passes,
+			&i.AcceptanceCriteria,
+			&i.Iterations,
+			&i.LastError,
+			&i.SessionKey,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listStoriesByTask = `-- name: ListStoriesByTask :many
 SELECT id, task_id, sequence, title, description, priority, passes, acceptance_criteria, iterations, last_error, session_key, created_at, updated_at FROM stories WHERE task_id = ? ORDER BY priority ASC, sequence ASC
 `
@@ -68,6 +68,24 @@ func (q *Queries) DeleteSubAgent(ctx context.Context, id string) error {
 	return err
 }
 
+const deleteSubAgentsByOrchestrator = `-- name: DeleteSubAgentsByOrchestrator :exec
+DELETE FROM sub_agents WHERE orchestrator_id = ?
+`
+
+func (q *Queries) DeleteSubAgentsByOrchestrator(ctx context.Context, orchestratorID string) error {
+	_, err := q.db.ExecContext(ctx, deleteSubAgentsByOrchestrator, orchestratorID)
+	return err
+}
+
+const deleteSubAgentsByProject = `-- name: DeleteSubAgentsByProject :exec
+DELETE FROM sub_agents WHERE task_id IN (SELECT id FROM tasks WHERE project_id = ?)
+`
+
+func (q *Queries) DeleteSubAgentsByProject(ctx context.Context, projectID sql.NullString) error {
+	_, err := q.db.ExecContext(ctx, deleteSubAgentsByProject, projectID)
+	return err
+}
+
 const getSubAgent = `-- name: GetSubAgent :one
 SELECT id, orchestrator_id, task_id, name, status, session_key, session_label, purpose, iteration, output, error, spawned_at, completed_at FROM sub_agents WHERE id = ? LIMIT 1
 `
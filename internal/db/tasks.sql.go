@@ -8,19 +8,146 @@ package db
 import (
 	"context"
 	"database/sql"
+	"strings"
 )
 
-const appendProgressTxt = `-- name: AppendProgressTxt :exec
-UPDATE tasks SET progress_txt = COALESCE(progress_txt || char(10), '') || ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+const assignTaskToGroup = `-- name: AssignTaskToGroup :one
+UPDATE tasks SET
+    group_id = ?, agent_id = NULL, status = 'queued', queue_position = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ? RETURNING id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at, queue_position, group_id, deferred_until, short_id, secret_names, progress, progress_explicit, context_summary, context_summarized_at, max_subtask_depth, max_concurrent_subtasks, failure_reason, model, routed_model, requires_review, reviewer_agent_id, reviewer_user
 `
 
-type AppendProgressTxtParams struct {
-	ProgressTxt sql.NullString `json:"progress_txt"`
-	ID          string         `json:"id"`
+type AssignTaskToGroupParams struct {
+	GroupID sql.NullString `json:"group_id"`
+	ID      string         `json:"id"`
+}
+
+func (q *Queries) AssignTaskToGroup(ctx context.Context, arg AssignTaskToGroupParams) (Task, error) {
+	row := q.db.QueryRowContext(ctx, assignTaskToGroup, arg.GroupID, arg.ID)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.AgentID,
+		&i.ProjectID,
+		&i.ParentTaskID,
+		&i.Status,
+		&i.Priority,
+		&i.GitBranch,
+		&i.ProjectMd,
+		&i.RequirementsMd,
+		&i.RoadmapMd,
+		&i.StateMd,
+		&i.PrdJson,
+		&i.ProgressTxt,
+		&i.QualityChecks,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.StartedAt,
+		&i.CompletedAt,
+		&i.DelegationMode,
+		&i.RetryCount,
+		&i.ScheduledAt,
+		&i.RetryAt,
+		&i.QueuePosition,
+		&i.GroupID,
+		&i.DeferredUntil,
+		&i.ShortID,
+		&i.SecretNames,
+		&i.Progress,
+		&i.ProgressExplicit,
+		&i.ContextSummary,
+		&i.ContextSummarizedAt,
+		&i.MaxSubtaskDepth,
+		&i.MaxConcurrentSubtasks,
+		&i.FailureReason,
+		&i.Model,
+		&i.RoutedModel,
+		&i.RequiresReview,
+		&i.ReviewerAgentID,
+		&i.ReviewerUser,
+	)
+	return i, err
+}
+
+const cancelOpenTasksByProject = `-- name: CancelOpenTasksByProject :exec
+UPDATE tasks SET status = 'cancelled', queue_position = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE project_id = ? AND status NOT IN ('done', 'failed', 'cancelled')
+`
+
+func (q *Queries) CancelOpenTasksByProject(ctx context.Context, projectID sql.NullString) error {
+	_, err := q.db.ExecContext(ctx, cancelOpenTasksByProject, projectID)
+	return err
+}
+
+const claimGroupTask = `-- name: ClaimGroupTask :one
+UPDATE tasks SET
+    agent_id = ?, status = 'backlog', queue_position = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND agent_id IS NULL AND status = 'queued' RETURNING id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at, queue_position, group_id, deferred_until, short_id, secret_names, progress, progress_explicit, context_summary, context_summarized_at, max_subtask_depth, max_concurrent_subtasks, failure_reason, model, routed_model, requires_review, reviewer_agent_id, reviewer_user
+`
+
+type ClaimGroupTaskParams struct {
+	AgentID sql.NullString `json:"agent_id"`
+	ID      string         `json:"id"`
+}
+
+func (q *Queries) ClaimGroupTask(ctx context.Context, arg ClaimGroupTaskParams) (Task, error) {
+	row := q.db.QueryRowContext(ctx, claimGroupTask, arg.AgentID, arg.ID)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.AgentID,
+		&i.ProjectID,
+		&i.ParentTaskID,
+		&i.Status,
+		&i.Priority,
+		&i.GitBranch,
+		&i.ProjectMd,
+		&i.RequirementsMd,
+		&i.RoadmapMd,
+		&i.StateMd,
+		&i.PrdJson,
+		&i.ProgressTxt,
+		&i.QualityChecks,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.StartedAt,
+		&i.CompletedAt,
+		&i.DelegationMode,
+		&i.RetryCount,
+		&i.ScheduledAt,
+		&i.RetryAt,
+		&i.QueuePosition,
+		&i.GroupID,
+		&i.DeferredUntil,
+		&i.ShortID,
+		&i.SecretNames,
+		&i.Progress,
+		&i.ProgressExplicit,
+		&i.ContextSummary,
+		&i.ContextSummarizedAt,
+		&i.MaxSubtaskDepth,
+		&i.MaxConcurrentSubtasks,
+		&i.FailureReason,
+		&i.Model,
+		&i.RoutedModel,
+		&i.RequiresReview,
+		&i.ReviewerAgentID,
+		&i.ReviewerUser,
+	)
+	return i, err
 }
 
-func (q *Queries) AppendProgressTxt(ctx context.Context, arg AppendProgressTxtParams) error {
-	_, err := q.db.ExecContext(ctx, appendProgressTxt, arg.ProgressTxt, arg.ID)
+const clearTaskDeferredUntil = `-- name: ClearTaskDeferredUntil :exec
+UPDATE tasks SET deferred_until = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+`
+
+func (q *Queries) ClearTaskDeferredUntil(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, clearTaskDeferredUntil, id)
 	return err
 }
 
@@ -53,10 +180,175 @@ func (q *Queries) CountActiveTasksByAgent(ctx context.Context, agentID sql.NullS
 	return count, err
 }
 
+const countOpenTasksByAgent = `-- name: CountOpenTasksByAgent :one
+SELECT COUNT(*) FROM tasks WHERE agent_id = ? AND status IN ('backlog', 'queued', 'planning', 'discussing', 'executing', 'verifying', 'review')
+`
+
+func (q *Queries) CountOpenTasksByAgent(ctx context.Context, agentId sql.NullString) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countOpenTasksByAgent, agentId)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countQueuedGroupTasksForAgent = `-- name: CountQueuedGroupTasksForAgent :one
+SELECT COUNT(*) FROM tasks t
+JOIN agent_group_members m ON m.group_id = t.group_id
+WHERE m.agent_id = ? AND t.agent_id IS NULL AND t.status = 'queued'
+`
+
+func (q *Queries) CountQueuedGroupTasksForAgent(ctx context.Context, agentId string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countQueuedGroupTasksForAgent, agentId)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countQueuedTasks = `-- name: CountQueuedTasks :many
+SELECT agent_id, group_id, COUNT(*) AS count
+FROM tasks
+WHERE status = 'queued'
+GROUP BY agent_id, group_id
+`
+
+type CountQueuedTasksRow struct {
+	AgentID sql.NullString `json:"agent_id"`
+	GroupID sql.NullString `json:"group_id"`
+	Count   int64          `json:"count"`
+}
+
+func (q *Queries) CountQueuedTasks(ctx context.Context) ([]CountQueuedTasksRow, error) {
+	rows, err := q.db.QueryContext(ctx, countQueuedTasks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CountQueuedTasksRow{}
+	for rows.Next() {
+		var i CountQueuedTasksRow
+		if err := rows.Scan(
+			&i.AgentID,
+			&i.GroupID,
+			&i.Count,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countQueuedTasksByAgent = `-- name: CountQueuedTasksByAgent :one
+SELECT COUNT(*) FROM tasks WHERE agent_id = ? AND status = 'queued'
+`
+
+func (q *Queries) CountQueuedTasksByAgent(ctx context.Context, agentId sql.NullString) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countQueuedTasksByAgent, agentId)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countQueuedTasksByGroup = `-- name: CountQueuedTasksByGroup :one
+SELECT COUNT(*) FROM tasks WHERE group_id = ? AND agent_id IS NULL AND status = 'queued'
+`
+
+func (q *Queries) CountQueuedTasksByGroup(ctx context.Context, groupId sql.NullString) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countQueuedTasksByGroup, groupId)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countTaskFailures = `-- name: CountTaskFailures :many
+SELECT project_id, agent_id, failure_reason, COUNT(*) AS count
+FROM tasks
+WHERE failure_reason IS NOT NULL
+GROUP BY project_id, agent_id, failure_reason
+`
+
+type CountTaskFailuresRow struct {
+	ProjectID     sql.NullString `json:"project_id"`
+	AgentID       sql.NullString `json:"agent_id"`
+	FailureReason sql.NullString `json:"failure_reason"`
+	Count         int64          `json:"count"`
+}
+
+func (q *Queries) CountTaskFailures(ctx context.Context) ([]CountTaskFailuresRow, error) {
+	rows, err := q.db.QueryContext(ctx, countTaskFailures)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CountTaskFailuresRow{}
+	for rows.Next() {
+		var i CountTaskFailuresRow
+		if err := rows.Scan(
+			&i.ProjectID,
+			&i.AgentID,
+			&i.FailureReason,
+			&i.Count,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countTasksByStatus = `-- name: CountTasksByStatus :many
+SELECT status, COUNT(*) AS count
+FROM tasks
+GROUP BY status
+`
+
+type CountTasksByStatusRow struct {
+	Status sql.NullString `json:"status"`
+	Count  int64          `json:"count"`
+}
+
+func (q *Queries) CountTasksByStatus(ctx context.Context) ([]CountTasksByStatusRow, error) {
+	rows, err := q.db.QueryContext(ctx, countTasksByStatus)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CountTasksByStatusRow{}
+	for rows.Next() {
+		var i CountTasksByStatusRow
+		if err := rows.Scan(
+			&i.Status,
+			&i.Count,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createTask = `-- name: CreateTask :one
-INSERT INTO tasks (id, title, description, agent_id, project_id, parent_task_id, status, priority, quality_checks, delegation_mode, scheduled_at, git_branch)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-RETURNING id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at
+INSERT INTO tasks (id, title, description, agent_id, project_id, parent_task_id, status, priority, quality_checks, delegation_mode, scheduled_at, git_branch, short_id)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at, queue_position, group_id, deferred_until, short_id, secret_names, progress, progress_explicit, context_summary, context_summarized_at, max_subtask_depth, max_concurrent_subtasks, failure_reason, model, routed_model, requires_review, reviewer_agent_id, reviewer_user
 `
 
 type CreateTaskParams struct {
@@ -72,6 +364,7 @@ type CreateTaskParams struct {
 	DelegationMode sql.NullString `json:"delegation_mode"`
 	ScheduledAt    sql.NullTime   `json:"scheduled_at"`
 	GitBranch      sql.NullString `json:"git_branch"`
+	ShortID        sql.NullString `json:"short_id"`
 }
 
 func (q *Queries) CreateTask(ctx context.Context, arg CreateTaskParams) (Task, error) {
@@ -88,6 +381,7 @@ func (q *Queries) CreateTask(ctx context.Context, arg CreateTaskParams) (Task, e
 		arg.DelegationMode,
 		arg.ScheduledAt,
 		arg.GitBranch,
+		arg.ShortID,
 	)
 	var i Task
 	err := row.Scan(
@@ -115,6 +409,23 @@ func (q *Queries) CreateTask(ctx context.Context, arg CreateTaskParams) (Task, e
 		&i.RetryCount,
 		&i.ScheduledAt,
 		&i.RetryAt,
+		&i.QueuePosition,
+		&i.GroupID,
+		&i.DeferredUntil,
+		&i.ShortID,
+		&i.SecretNames,
+		&i.Progress,
+		&i.ProgressExplicit,
+		&i.ContextSummary,
+		&i.ContextSummarizedAt,
+		&i.MaxSubtaskDepth,
+		&i.MaxConcurrentSubtasks,
+		&i.FailureReason,
+		&i.Model,
+		&i.RoutedModel,
+		&i.RequiresReview,
+		&i.ReviewerAgentID,
+		&i.ReviewerUser,
 	)
 	return i, err
 }
@@ -128,8 +439,17 @@ func (q *Queries) DeleteTask(ctx context.Context, id string) error {
 	return err
 }
 
+const deleteTasksByProject = `-- name: DeleteTasksByProject :exec
+DELETE FROM tasks WHERE project_id = ?
+`
+
+func (q *Queries) DeleteTasksByProject(ctx context.Context, projectID sql.NullString) error {
+	_, err := q.db.ExecContext(ctx, deleteTasksByProject, projectID)
+	return err
+}
+
 const getTask = `-- name: GetTask :one
-SELECT id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at FROM tasks WHERE id = ? LIMIT 1
+SELECT id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at, queue_position, group_id, deferred_until, short_id, secret_names, progress, progress_explicit, context_summary, context_summarized_at, max_subtask_depth, max_concurrent_subtasks, failure_reason, model, routed_model, requires_review, reviewer_agent_id, reviewer_user FROM tasks WHERE id = ? LIMIT 1
 `
 
 func (q *Queries) GetTask(ctx context.Context, id string) (Task, error) {
@@ -160,45 +480,132 @@ func (q *Queries) GetTask(ctx context.Context, id string) (Task, error) {
 		&i.RetryCount,
 		&i.ScheduledAt,
 		&i.RetryAt,
+		&i.QueuePosition,
+		&i.GroupID,
+		&i.DeferredUntil,
+		&i.ShortID,
+		&i.SecretNames,
+		&i.Progress,
+		&i.ProgressExplicit,
+		&i.ContextSummary,
+		&i.ContextSummarizedAt,
+		&i.MaxSubtaskDepth,
+		&i.MaxConcurrentSubtasks,
+		&i.FailureReason,
+		&i.Model,
+		&i.RoutedModel,
+		&i.RequiresReview,
+		&i.ReviewerAgentID,
+		&i.ReviewerUser,
+	)
+	return i, err
+}
+
+const getTaskByShortID = `-- name: GetTaskByShortID :one
+SELECT id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at, queue_position, group_id, deferred_until, short_id, secret_names, progress, progress_explicit, context_summary, context_summarized_at, max_subtask_depth, max_concurrent_subtasks, failure_reason, model, routed_model, requires_review, reviewer_agent_id, reviewer_user FROM tasks WHERE short_id = ? LIMIT 1
+`
+
+func (q *Queries) GetTaskByShortID(ctx context.Context, shortID sql.NullString) (Task, error) {
+	row := q.db.QueryRowContext(ctx, getTaskByShortID, shortID)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.AgentID,
+		&i.ProjectID,
+		&i.ParentTaskID,
+		&i.Status,
+		&i.Priority,
+		&i.GitBranch,
+		&i.ProjectMd,
+		&i.RequirementsMd,
+		&i.RoadmapMd,
+		&i.StateMd,
+		&i.PrdJson,
+		&i.ProgressTxt,
+		&i.QualityChecks,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.StartedAt,
+		&i.CompletedAt,
+		&i.DelegationMode,
+		&i.RetryCount,
+		&i.ScheduledAt,
+		&i.RetryAt,
+		&i.QueuePosition,
+		&i.GroupID,
+		&i.DeferredUntil,
+		&i.ShortID,
+		&i.SecretNames,
+		&i.Progress,
+		&i.ProgressExplicit,
+		&i.ContextSummary,
+		&i.ContextSummarizedAt,
+		&i.MaxSubtaskDepth,
+		&i.MaxConcurrentSubtasks,
+		&i.FailureReason,
+		&i.Model,
+		&i.RoutedModel,
+		&i.RequiresReview,
+		&i.ReviewerAgentID,
+		&i.ReviewerUser,
 	)
 	return i, err
 }
 
 const getTaskWithStoryCounts = `-- name: GetTaskWithStoryCounts :one
 SELECT 
-    t.id, t.title, t.description, t.agent_id, t.project_id, t.parent_task_id, t.status, t.priority, t.git_branch, t.project_md, t.requirements_md, t.roadmap_md, t.state_md, t.prd_json, t.progress_txt, t.quality_checks, t.created_at, t.updated_at, t.started_at, t.completed_at, t.delegation_mode, t.retry_count, t.scheduled_at, t.retry_at,
+    t.id, t.title, t.description, t.agent_id, t.project_id, t.parent_task_id, t.status, t.priority, t.git_branch, t.project_md, t.requirements_md, t.roadmap_md, t.state_md, t.prd_json, t.progress_txt, t.quality_checks, t.created_at, t.updated_at, t.started_at, t.completed_at, t.delegation_mode, t.retry_count, t.scheduled_at, t.retry_at, t.queue_position, t.group_id, t.deferred_until, t.short_id, t.secret_names, t.progress, t.progress_explicit, t.context_summary, t.context_summarized_at, t.max_subtask_depth, t.max_concurrent_subtasks, t.failure_reason, t.model, t.routed_model, t.requires_review, t.reviewer_agent_id, t.reviewer_user,
     (SELECT COUNT(*) FROM stories WHERE task_id = t.id) as stories_total,
     (SELECT COUNT(*) FROM stories WHERE task_id = t.id AND passes = 1) as stories_passed
 FROM tasks t WHERE t.id = ? LIMIT 1
 `
 
 type GetTaskWithStoryCountsRow struct {
-	ID             string         `json:"id"`
-	Title          string         `json:"title"`
-	Description    sql.NullString `json:"description"`
-	AgentID        sql.NullString `json:"agent_id"`
-	ProjectID      sql.NullString `json:"project_id"`
-	ParentTaskID   sql.NullString `json:"parent_task_id"`
-	Status         sql.NullString `json:"status"`
-	Priority       sql.NullInt64  `json:"priority"`
-	GitBranch      sql.NullString `json:"git_branch"`
-	ProjectMd      sql.NullString `json:"project_md"`
-	RequirementsMd sql.NullString `json:"requirements_md"`
-	RoadmapMd      sql.NullString `json:"roadmap_md"`
-	StateMd        sql.NullString `json:"state_md"`
-	PrdJson        sql.NullString `json:"prd_json"`
-	ProgressTxt    sql.NullString `json:"progress_txt"`
-	QualityChecks  sql.NullString `json:"quality_checks"`
-	CreatedAt      sql.NullTime   `json:"created_at"`
-	UpdatedAt      sql.NullTime   `json:"updated_at"`
-	StartedAt      sql.NullTime   `json:"started_at"`
-	CompletedAt    sql.NullTime   `json:"completed_at"`
-	DelegationMode sql.NullString `json:"delegation_mode"`
-	RetryCount     int64          `json:"retry_count"`
-	ScheduledAt    sql.NullTime   `json:"scheduled_at"`
-	RetryAt        sql.NullTime   `json:"retry_at"`
-	StoriesTotal   int64          `json:"stories_total"`
-	StoriesPassed  int64          `json:"stories_passed"`
+	ID                    string         `json:"id"`
+	Title                 string         `json:"title"`
+	Description           sql.NullString `json:"description"`
+	AgentID               sql.NullString `json:"agent_id"`
+	ProjectID             sql.NullString `json:"project_id"`
+	ParentTaskID          sql.NullString `json:"parent_task_id"`
+	Status                sql.NullString `json:"status"`
+	Priority              sql.NullInt64  `json:"priority"`
+	GitBranch             sql.NullString `json:"git_branch"`
+	ProjectMd             sql.NullString `json:"project_md"`
+	RequirementsMd        sql.NullString `json:"requirements_md"`
+	RoadmapMd             sql.NullString `json:"roadmap_md"`
+	StateMd               sql.NullString `json:"state_md"`
+	PrdJson               sql.NullString `json:"prd_json"`
+	ProgressTxt           sql.NullString `json:"progress_txt"`
+	QualityChecks         sql.NullString `json:"quality_checks"`
+	CreatedAt             sql.NullTime   `json:"created_at"`
+	UpdatedAt             sql.NullTime   `json:"updated_at"`
+	StartedAt             sql.NullTime   `json:"started_at"`
+	CompletedAt           sql.NullTime   `json:"completed_at"`
+	DelegationMode        sql.NullString `json:"delegation_mode"`
+	RetryCount            int64          `json:"retry_count"`
+	ScheduledAt           sql.NullTime   `json:"scheduled_at"`
+	RetryAt               sql.NullTime   `json:"retry_at"`
+	QueuePosition         sql.NullInt64  `json:"queue_position"`
+	GroupID               sql.NullString `json:"group_id"`
+	DeferredUntil         sql.NullTime   `json:"deferred_until"`
+	ShortID               sql.NullString `json:"short_id"`
+	SecretNames           sql.NullString `json:"secret_names"`
+	Progress              sql.NullInt64  `json:"progress"`
+	ProgressExplicit      bool           `json:"progress_explicit"`
+	ContextSummary        sql.NullString `json:"context_summary"`
+	ContextSummarizedAt   sql.NullTime   `json:"context_summarized_at"`
+	MaxSubtaskDepth       sql.NullInt64  `json:"max_subtask_depth"`
+	MaxConcurrentSubtasks sql.NullInt64  `json:"max_concurrent_subtasks"`
+	FailureReason         sql.NullString `json:"failure_reason"`
+	Model                 sql.NullString `json:"model"`
+	RoutedModel           sql.NullString `json:"routed_model"`
+	RequiresReview        bool           `json:"requires_review"`
+	ReviewerAgentID       sql.NullString `json:"reviewer_agent_id"`
+	ReviewerUser          sql.NullString `json:"reviewer_user"`
+	StoriesTotal          int64          `json:"stories_total"`
+	StoriesPassed         int64          `json:"stories_passed"`
 }
 
 func (q *Queries) GetTaskWithStoryCounts(ctx context.Context, id string) (GetTaskWithStoryCountsRow, error) {
@@ -229,6 +636,23 @@ func (q *Queries) GetTaskWithStoryCounts(ctx context.Context, id string) (GetTas
 		&i.RetryCount,
 		&i.ScheduledAt,
 		&i.RetryAt,
+		&i.QueuePosition,
+		&i.GroupID,
+		&i.DeferredUntil,
+		&i.ShortID,
+		&i.SecretNames,
+		&i.Progress,
+		&i.ProgressExplicit,
+		&i.ContextSummary,
+		&i.ContextSummarizedAt,
+		&i.MaxSubtaskDepth,
+		&i.MaxConcurrentSubtasks,
+		&i.FailureReason,
+		&i.Model,
+		&i.RoutedModel,
+		&i.RequiresReview,
+		&i.ReviewerAgentID,
+		&i.ReviewerUser,
 		&i.StoriesTotal,
 		&i.StoriesPassed,
 	)
@@ -244,12 +668,701 @@ func (q *Queries) IncrementTaskRetryCount(ctx context.Context, id string) error
 	return err
 }
 
-const listQueuedTasksByAgent = `-- name: ListQueuedTasksByAgent :many
-SELECT id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at FROM tasks WHERE agent_id = ? AND status = 'queued' ORDER BY priority ASC, created_at ASC
+const listAgentTaskOutcomes = `-- name: ListAgentTaskOutcomes :many
+SELECT agent_id, status, created_at, started_at, completed_at
+FROM tasks
+WHERE agent_id IS NOT NULL
+  AND status IN ('done', 'failed')
+  AND COALESCE(completed_at, updated_at) >= ?
+`
+
+type ListAgentTaskOutcomesRow struct {
+	AgentID     sql.NullString `json:"agent_id"`
+	Status      sql.NullString `json:"status"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
+	StartedAt   sql.NullTime   `json:"started_at"`
+	CompletedAt sql.NullTime   `json:"completed_at"`
+}
+
+func (q *Queries) ListAgentTaskOutcomes(ctx context.Context, since sql.NullTime) ([]ListAgentTaskOutcomesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAgentTaskOutcomes, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAgentTaskOutcomesRow{}
+	for rows.Next() {
+		var i ListAgentTaskOutcomesRow
+		if err := rows.Scan(
+			&i.AgentID,
+			&i.Status,
+			&i.CreatedAt,
+			&i.StartedAt,
+			&i.CompletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCalendarTasks = `-- name: ListCalendarTasks :many
+SELECT id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at, queue_position, group_id, deferred_until, short_id, secret_names, progress, progress_explicit, context_summary, context_summarized_at, max_subtask_depth, max_concurrent_subtasks, failure_reason, model, routed_model, requires_review, reviewer_agent_id, reviewer_user FROM tasks
+WHERE scheduled_at IS NOT NULL
+   OR retry_at IS NOT NULL
+ORDER BY COALESCE(scheduled_at, retry_at) ASC
+`
+
+func (q *Queries) ListCalendarTasks(ctx context.Context) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, listCalendarTasks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.AgentID,
+			&i.ProjectID,
+			&i.ParentTaskID,
+			&i.Status,
+			&i.Priority,
+			&i.GitBranch,
+			&i.ProjectMd,
+			&i.RequirementsMd,
+			&i.RoadmapMd,
+			&i.StateMd,
+			&i.PrdJson,
+			&i.ProgressTxt,
+			&i.QualityChecks,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StartedAt,
+			&i.CompletedAt,
+			&i.DelegationMode,
+			&i.RetryCount,
+			&i.ScheduledAt,
+			&i.RetryAt,
+			&i.QueuePosition,
+			&i.GroupID,
+			&i.DeferredUntil,
+			&i.ShortID,
+			&i.SecretNames,
+			&i.Progress,
+			&i.ProgressExplicit,
+			&i.ContextSummary,
+			&i.ContextSummarizedAt,
+			&i.MaxSubtaskDepth,
+			&i.MaxConcurrentSubtasks,
+			&i.FailureReason,
+			&i.Model,
+			&i.RoutedModel,
+			&i.RequiresReview,
+			&i.ReviewerAgentID,
+			&i.ReviewerUser,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDeferredDueTasks = `-- name: ListDeferredDueTasks :many
+SELECT id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at, queue_position, group_id, deferred_until, short_id, secret_names, progress, progress_explicit, context_summary, context_summarized_at, max_subtask_depth, max_concurrent_subtasks, failure_reason, model, routed_model, requires_review, reviewer_agent_id, reviewer_user FROM tasks
+WHERE deferred_until IS NOT NULL
+  AND deferred_until <= CURRENT_TIMESTAMP
+  AND status IN ('backlog', 'queued')
+ORDER BY deferred_until ASC
+`
+
+func (q *Queries) ListDeferredDueTasks(ctx context.Context) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, listDeferredDueTasks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.AgentID,
+			&i.ProjectID,
+			&i.ParentTaskID,
+			&i.Status,
+			&i.Priority,
+			&i.GitBranch,
+			&i.ProjectMd,
+			&i.RequirementsMd,
+			&i.RoadmapMd,
+			&i.StateMd,
+			&i.PrdJson,
+			&i.ProgressTxt,
+			&i.QualityChecks,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StartedAt,
+			&i.CompletedAt,
+			&i.DelegationMode,
+			&i.RetryCount,
+			&i.ScheduledAt,
+			&i.RetryAt,
+			&i.QueuePosition,
+			&i.GroupID,
+			&i.DeferredUntil,
+			&i.ShortID,
+			&i.SecretNames,
+			&i.Progress,
+			&i.ProgressExplicit,
+			&i.ContextSummary,
+			&i.ContextSummarizedAt,
+			&i.MaxSubtaskDepth,
+			&i.MaxConcurrentSubtasks,
+			&i.FailureReason,
+			&i.Model,
+			&i.RoutedModel,
+			&i.RequiresReview,
+			&i.ReviewerAgentID,
+			&i.ReviewerUser,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOpenTasksByAgent = `-- name: ListOpenTasksByAgent :many
+SELECT id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at, queue_position, group_id, deferred_until, short_id, secret_names, progress, progress_explicit, context_summary, context_summarized_at, max_subtask_depth, max_concurrent_subtasks, failure_reason, model, routed_model, requires_review, reviewer_agent_id, reviewer_user FROM tasks WHERE agent_id = ? AND status NOT IN ('done', 'failed', 'cancelled') ORDER BY created_at ASC
+`
+
+func (q *Queries) ListOpenTasksByAgent(ctx context.Context, agentID sql.NullString) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, listOpenTasksByAgent, agentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.AgentID,
+			&i.ProjectID,
+			&i.ParentTaskID,
+			&i.Status,
+			&i.Priority,
+			&i.GitBranch,
+			&i.ProjectMd,
+			&i.RequirementsMd,
+			&i.RoadmapMd,
+			&i.StateMd,
+			&i.PrdJson,
+			&i.ProgressTxt,
+			&i.QualityChecks,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StartedAt,
+			&i.CompletedAt,
+			&i.DelegationMode,
+			&i.RetryCount,
+			&i.ScheduledAt,
+			&i.RetryAt,
+			&i.QueuePosition,
+			&i.GroupID,
+			&i.DeferredUntil,
+			&i.ShortID,
+			&i.SecretNames,
+			&i.Progress,
+			&i.ProgressExplicit,
+			&i.ContextSummary,
+			&i.ContextSummarizedAt,
+			&i.MaxSubtaskDepth,
+			&i.MaxConcurrentSubtasks,
+			&i.FailureReason,
+			&i.Model,
+			&i.RoutedModel,
+			&i.RequiresReview,
+			&i.ReviewerAgentID,
+			&i.ReviewerUser,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listQueuedGroupTasksForAgent = `-- name: ListQueuedGroupTasksForAgent :many
+SELECT t.id, t.title, t.description, t.agent_id, t.project_id, t.parent_task_id, t.status, t.priority, t.git_branch, t.project_md, t.requirements_md, t.roadmap_md, t.state_md, t.prd_json, t.progress_txt, t.quality_checks, t.created_at, t.updated_at, t.started_at, t.completed_at, t.delegation_mode, t.retry_count, t.scheduled_at, t.retry_at, t.queue_position, t.group_id, t.deferred_until, t.short_id, t.secret_names, t.progress, t.progress_explicit, t.context_summary, t.context_summarized_at, t.max_subtask_depth, t.max_concurrent_subtasks, t.failure_reason, t.model, t.routed_model, t.requires_review, t.reviewer_agent_id, t.reviewer_user FROM tasks t
+JOIN agent_group_members m ON m.group_id = t.group_id
+WHERE m.agent_id = ? AND t.agent_id IS NULL AND t.status = 'queued'
+ORDER BY t.queue_position IS NULL, t.queue_position ASC, t.priority ASC, t.created_at ASC
+`
+
+func (q *Queries) ListQueuedGroupTasksForAgent(ctx context.Context, agentId string) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, listQueuedGroupTasksForAgent, agentId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.AgentID,
+			&i.ProjectID,
+			&i.ParentTaskID,
+			&i.Status,
+			&i.Priority,
+			&i.GitBranch,
+			&i.ProjectMd,
+			&i.RequirementsMd,
+			&i.RoadmapMd,
+			&i.StateMd,
+			&i.PrdJson,
+			&i.ProgressTxt,
+			&i.QualityChecks,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StartedAt,
+			&i.CompletedAt,
+			&i.DelegationMode,
+			&i.RetryCount,
+			&i.ScheduledAt,
+			&i.RetryAt,
+			&i.QueuePosition,
+			&i.GroupID,
+			&i.DeferredUntil,
+			&i.ShortID,
+			&i.SecretNames,
+			&i.Progress,
+			&i.ProgressExplicit,
+			&i.ContextSummary,
+			&i.ContextSummarizedAt,
+			&i.MaxSubtaskDepth,
+			&i.MaxConcurrentSubtasks,
+			&i.FailureReason,
+			&i.Model,
+			&i.RoutedModel,
+			&i.RequiresReview,
+			&i.ReviewerAgentID,
+			&i.ReviewerUser,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listQueuedTasksByAgent = `-- name: ListQueuedTasksByAgent :many
+SELECT id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at, queue_position, group_id, deferred_until, short_id, secret_names, progress, progress_explicit, context_summary, context_summarized_at, max_subtask_depth, max_concurrent_subtasks, failure_reason, model, routed_model, requires_review, reviewer_agent_id, reviewer_user FROM tasks WHERE agent_id = ? AND status = 'queued' ORDER BY queue_position IS NULL, queue_position ASC, priority ASC, created_at ASC
+`
+
+func (q *Queries) ListQueuedTasksByAgent(ctx context.Context, agentID sql.NullString) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, listQueuedTasksByAgent, agentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.AgentID,
+			&i.ProjectID,
+			&i.ParentTaskID,
+			&i.Status,
+			&i.Priority,
+			&i.GitBranch,
+			&i.ProjectMd,
+			&i.RequirementsMd,
+			&i.RoadmapMd,
+			&i.StateMd,
+			&i.PrdJson,
+			&i.ProgressTxt,
+			&i.QualityChecks,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StartedAt,
+			&i.CompletedAt,
+			&i.DelegationMode,
+			&i.RetryCount,
+			&i.ScheduledAt,
+			&i.RetryAt,
+			&i.QueuePosition,
+			&i.GroupID,
+			&i.DeferredUntil,
+			&i.ShortID,
+			&i.SecretNames,
+			&i.Progress,
+			&i.ProgressExplicit,
+			&i.ContextSummary,
+			&i.ContextSummarizedAt,
+			&i.MaxSubtaskDepth,
+			&i.MaxConcurrentSubtasks,
+			&i.FailureReason,
+			&i.Model,
+			&i.RoutedModel,
+			&i.RequiresReview,
+			&i.ReviewerAgentID,
+			&i.ReviewerUser,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listQueuedTasksByGroup = `-- name: ListQueuedTasksByGroup :many
+SELECT id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at, queue_position, group_id, deferred_until, short_id, secret_names, progress, progress_explicit, context_summary, context_summarized_at, max_subtask_depth, max_concurrent_subtasks, failure_reason, model, routed_model, requires_review, reviewer_agent_id, reviewer_user FROM tasks
+WHERE group_id = ? AND agent_id IS NULL AND status = 'queued'
+ORDER BY queue_position IS NULL, queue_position ASC, priority ASC, created_at ASC
+`
+
+func (q *Queries) ListQueuedTasksByGroup(ctx context.Context, groupId sql.NullString) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, listQueuedTasksByGroup, groupId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.AgentID,
+			&i.ProjectID,
+			&i.ParentTaskID,
+			&i.Status,
+			&i.Priority,
+			&i.GitBranch,
+			&i.ProjectMd,
+			&i.RequirementsMd,
+			&i.RoadmapMd,
+			&i.StateMd,
+			&i.PrdJson,
+			&i.ProgressTxt,
+			&i.QualityChecks,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StartedAt,
+			&i.CompletedAt,
+			&i.DelegationMode,
+			&i.RetryCount,
+			&i.ScheduledAt,
+			&i.RetryAt,
+			&i.QueuePosition,
+			&i.GroupID,
+			&i.DeferredUntil,
+			&i.ShortID,
+			&i.SecretNames,
+			&i.Progress,
+			&i.ProgressExplicit,
+			&i.ContextSummary,
+			&i.ContextSummarizedAt,
+			&i.MaxSubtaskDepth,
+			&i.MaxConcurrentSubtasks,
+			&i.FailureReason,
+			&i.Model,
+			&i.RoutedModel,
+			&i.RequiresReview,
+			&i.ReviewerAgentID,
+			&i.ReviewerUser,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRetryDueTasks = `-- name: ListRetryDueTasks :many
+SELECT id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at, queue_position, group_id, deferred_until, short_id, secret_names, progress, progress_explicit, context_summary, context_summarized_at, max_subtask_depth, max_concurrent_subtasks, failure_reason, model, routed_model, requires_review, reviewer_agent_id, reviewer_user FROM tasks
+WHERE retry_at IS NOT NULL
+  AND retry_at <= CURRENT_TIMESTAMP
+  AND status = 'backlog'
+ORDER BY retry_at ASC
+`
+
+func (q *Queries) ListRetryDueTasks(ctx context.Context) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, listRetryDueTasks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.AgentID,
+			&i.ProjectID,
+			&i.ParentTaskID,
+			&i.Status,
+			&i.Priority,
+			&i.GitBranch,
+			&i.ProjectMd,
+			&i.RequirementsMd,
+			&i.RoadmapMd,
+			&i.StateMd,
+			&i.PrdJson,
+			&i.ProgressTxt,
+			&i.QualityChecks,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StartedAt,
+			&i.CompletedAt,
+			&i.DelegationMode,
+			&i.RetryCount,
+			&i.ScheduledAt,
+			&i.RetryAt,
+			&i.QueuePosition,
+			&i.GroupID,
+			&i.DeferredUntil,
+			&i.ShortID,
+			&i.SecretNames,
+			&i.Progress,
+			&i.ProgressExplicit,
+			&i.ContextSummary,
+			&i.ContextSummarizedAt,
+			&i.MaxSubtaskDepth,
+			&i.MaxConcurrentSubtasks,
+			&i.FailureReason,
+			&i.Model,
+			&i.RoutedModel,
+			&i.RequiresReview,
+			&i.ReviewerAgentID,
+			&i.ReviewerUser,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listScheduledDueTasks = `-- name: ListScheduledDueTasks :many
+SELECT id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at, queue_position, group_id, deferred_until, short_id, secret_names, progress, progress_explicit, context_summary, context_summarized_at, max_subtask_depth, max_concurrent_subtasks, failure_reason, model, routed_model, requires_review, reviewer_agent_id, reviewer_user FROM tasks
+WHERE scheduled_at IS NOT NULL
+  AND scheduled_at <= CURRENT_TIMESTAMP
+  AND status = 'backlog'
+ORDER BY scheduled_at ASC
+`
+
+func (q *Queries) ListScheduledDueTasks(ctx context.Context) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, listScheduledDueTasks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.AgentID,
+			&i.ProjectID,
+			&i.ParentTaskID,
+			&i.Status,
+			&i.Priority,
+			&i.GitBranch,
+			&i.ProjectMd,
+			&i.RequirementsMd,
+			&i.RoadmapMd,
+			&i.StateMd,
+			&i.PrdJson,
+			&i.ProgressTxt,
+			&i.QualityChecks,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StartedAt,
+			&i.CompletedAt,
+			&i.DelegationMode,
+			&i.RetryCount,
+			&i.ScheduledAt,
+			&i.RetryAt,
+			&i.QueuePosition,
+			&i.GroupID,
+			&i.DeferredUntil,
+			&i.ShortID,
+			&i.SecretNames,
+			&i.Progress,
+			&i.ProgressExplicit,
+			&i.ContextSummary,
+			&i.ContextSummarizedAt,
+			&i.MaxSubtaskDepth,
+			&i.MaxConcurrentSubtasks,
+			&i.FailureReason,
+			&i.Model,
+			&i.RoutedModel,
+			&i.RequiresReview,
+			&i.ReviewerAgentID,
+			&i.ReviewerUser,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStaleTasks = `-- name: ListStaleTasks :many
+SELECT id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at, queue_position, group_id, deferred_until, short_id, secret_names, progress, progress_explicit, context_summary, context_summarized_at, max_subtask_depth, max_concurrent_subtasks, failure_reason, model, routed_model, requires_review, reviewer_agent_id, reviewer_user FROM tasks
+WHERE status IN ('executing', 'planning', 'discussing', 'verifying')
+  AND (updated_at IS NULL OR updated_at < ?)
+ORDER BY updated_at ASC
+`
+
+func (q *Queries) ListStaleTasks(ctx context.Context, updatedAt sql.NullTime) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, listStaleTasks, updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.AgentID,
+			&i.ProjectID,
+			&i.ParentTaskID,
+			&i.Status,
+			&i.Priority,
+			&i.GitBranch,
+			&i.ProjectMd,
+			&i.RequirementsMd,
+			&i.RoadmapMd,
+			&i.StateMd,
+			&i.PrdJson,
+			&i.ProgressTxt,
+			&i.QualityChecks,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StartedAt,
+			&i.CompletedAt,
+			&i.DelegationMode,
+			&i.RetryCount,
+			&i.ScheduledAt,
+			&i.RetryAt,
+			&i.QueuePosition,
+			&i.GroupID,
+			&i.DeferredUntil,
+			&i.ShortID,
+			&i.SecretNames,
+			&i.Progress,
+			&i.ProgressExplicit,
+			&i.ContextSummary,
+			&i.ContextSummarizedAt,
+			&i.MaxSubtaskDepth,
+			&i.MaxConcurrentSubtasks,
+			&i.FailureReason,
+			&i.Model,
+			&i.RoutedModel,
+			&i.RequiresReview,
+			&i.ReviewerAgentID,
+			&i.ReviewerUser,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSubtasks = `-- name: ListSubtasks :many
+SELECT id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at, queue_position, group_id, deferred_until, short_id, secret_names, progress, progress_explicit, context_summary, context_summarized_at, max_subtask_depth, max_concurrent_subtasks, failure_reason, model, routed_model, requires_review, reviewer_agent_id, reviewer_user FROM tasks WHERE parent_task_id = ? ORDER BY created_at ASC
 `
 
-func (q *Queries) ListQueuedTasksByAgent(ctx context.Context, agentID sql.NullString) ([]Task, error) {
-	rows, err := q.db.QueryContext(ctx, listQueuedTasksByAgent, agentID)
+func (q *Queries) ListSubtasks(ctx context.Context, parentTaskID sql.NullString) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, listSubtasks, parentTaskID)
 	if err != nil {
 		return nil, err
 	}
@@ -282,6 +1395,23 @@ func (q *Queries) ListQueuedTasksByAgent(ctx context.Context, agentID sql.NullSt
 			&i.RetryCount,
 			&i.ScheduledAt,
 			&i.RetryAt,
+			&i.QueuePosition,
+			&i.GroupID,
+			&i.DeferredUntil,
+			&i.ShortID,
+			&i.SecretNames,
+			&i.Progress,
+			&i.ProgressExplicit,
+			&i.ContextSummary,
+			&i.ContextSummarizedAt,
+			&i.MaxSubtaskDepth,
+			&i.MaxConcurrentSubtasks,
+			&i.FailureReason,
+			&i.Model,
+			&i.RoutedModel,
+			&i.RequiresReview,
+			&i.ReviewerAgentID,
+			&i.ReviewerUser,
 		); err != nil {
 			return nil, err
 		}
@@ -296,16 +1426,12 @@ func (q *Queries) ListQueuedTasksByAgent(ctx context.Context, agentID sql.NullSt
 	return items, nil
 }
 
-const listRetryDueTasks = `-- name: ListRetryDueTasks :many
-SELECT id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at FROM tasks
-WHERE retry_at IS NOT NULL
-  AND retry_at <= CURRENT_TIMESTAMP
-  AND status = 'backlog'
-ORDER BY retry_at ASC
+const listTasks = `-- name: ListTasks :many
+SELECT id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at, queue_position, group_id, deferred_until, short_id, secret_names, progress, progress_explicit, context_summary, context_summarized_at, max_subtask_depth, max_concurrent_subtasks, failure_reason, model, routed_model, requires_review, reviewer_agent_id, reviewer_user FROM tasks ORDER BY priority ASC, created_at DESC
 `
 
-func (q *Queries) ListRetryDueTasks(ctx context.Context) ([]Task, error) {
-	rows, err := q.db.QueryContext(ctx, listRetryDueTasks)
+func (q *Queries) ListTasks(ctx context.Context) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, listTasks)
 	if err != nil {
 		return nil, err
 	}
@@ -338,6 +1464,23 @@ func (q *Queries) ListRetryDueTasks(ctx context.Context) ([]Task, error) {
 			&i.RetryCount,
 			&i.ScheduledAt,
 			&i.RetryAt,
+			&i.QueuePosition,
+			&i.GroupID,
+			&i.DeferredUntil,
+			&i.ShortID,
+			&i.SecretNames,
+			&i.Progress,
+			&i.ProgressExplicit,
+			&i.ContextSummary,
+			&i.ContextSummarizedAt,
+			&i.MaxSubtaskDepth,
+			&i.MaxConcurrentSubtasks,
+			&i.FailureReason,
+			&i.Model,
+			&i.RoutedModel,
+			&i.RequiresReview,
+			&i.ReviewerAgentID,
+			&i.ReviewerUser,
 		); err != nil {
 			return nil, err
 		}
@@ -352,16 +1495,12 @@ func (q *Queries) ListRetryDueTasks(ctx context.Context) ([]Task, error) {
 	return items, nil
 }
 
-const listScheduledDueTasks = `-- name: ListScheduledDueTasks :many
-SELECT id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at FROM tasks
-WHERE scheduled_at IS NOT NULL
-  AND scheduled_at <= CURRENT_TIMESTAMP
-  AND status = 'backlog'
-ORDER BY scheduled_at ASC
+const listTasksByAgent = `-- name: ListTasksByAgent :many
+SELECT id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at, queue_position, group_id, deferred_until, short_id, secret_names, progress, progress_explicit, context_summary, context_summarized_at, max_subtask_depth, max_concurrent_subtasks, failure_reason, model, routed_model, requires_review, reviewer_agent_id, reviewer_user FROM tasks WHERE agent_id = ? ORDER BY created_at DESC
 `
 
-func (q *Queries) ListScheduledDueTasks(ctx context.Context) ([]Task, error) {
-	rows, err := q.db.QueryContext(ctx, listScheduledDueTasks)
+func (q *Queries) ListTasksByAgent(ctx context.Context, agentID sql.NullString) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, listTasksByAgent, agentID)
 	if err != nil {
 		return nil, err
 	}
@@ -394,6 +1533,23 @@ func (q *Queries) ListScheduledDueTasks(ctx context.Context) ([]Task, error) {
 			&i.RetryCount,
 			&i.ScheduledAt,
 			&i.RetryAt,
+			&i.QueuePosition,
+			&i.GroupID,
+			&i.DeferredUntil,
+			&i.ShortID,
+			&i.SecretNames,
+			&i.Progress,
+			&i.ProgressExplicit,
+			&i.ContextSummary,
+			&i.ContextSummarizedAt,
+			&i.MaxSubtaskDepth,
+			&i.MaxConcurrentSubtasks,
+			&i.FailureReason,
+			&i.Model,
+			&i.RoutedModel,
+			&i.RequiresReview,
+			&i.ReviewerAgentID,
+			&i.ReviewerUser,
 		); err != nil {
 			return nil, err
 		}
@@ -408,15 +1564,22 @@ func (q *Queries) ListScheduledDueTasks(ctx context.Context) ([]Task, error) {
 	return items, nil
 }
 
-const listStaleTasks = `-- name: ListStaleTasks :many
-SELECT id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at FROM tasks
-WHERE status IN ('executing', 'planning', 'discussing', 'verifying')
-  AND (updated_at IS NULL OR updated_at < ?)
-ORDER BY updated_at ASC
+const listTasksByAgentIDs = `-- name: ListTasksByAgentIDs :many
+SELECT id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at, queue_position, group_id, deferred_until, short_id, secret_names, progress, progress_explicit, context_summary, context_summarized_at, max_subtask_depth, max_concurrent_subtasks, failure_reason, model, routed_model, requires_review, reviewer_agent_id, reviewer_user FROM tasks WHERE agent_id IN (/*SLICE:agent_ids*/?) ORDER BY created_at DESC
 `
 
-func (q *Queries) ListStaleTasks(ctx context.Context, updatedAt sql.NullTime) ([]Task, error) {
-	rows, err := q.db.QueryContext(ctx, listStaleTasks, updatedAt)
+func (q *Queries) ListTasksByAgentIDs(ctx context.Context, agentIds []sql.NullString) ([]Task, error) {
+	query := listTasksByAgentIDs
+	var queryParams []interface{}
+	if len(agentIds) > 0 {
+		for _, v := range agentIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:agent_ids*/?", strings.Repeat(",?", len(agentIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:agent_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
 	if err != nil {
 		return nil, err
 	}
@@ -449,6 +1612,23 @@ func (q *Queries) ListStaleTasks(ctx context.Context, updatedAt sql.NullTime) ([
 			&i.RetryCount,
 			&i.ScheduledAt,
 			&i.RetryAt,
+			&i.QueuePosition,
+			&i.GroupID,
+			&i.DeferredUntil,
+			&i.ShortID,
+			&i.SecretNames,
+			&i.Progress,
+			&i.ProgressExplicit,
+			&i.ContextSummary,
+			&i.ContextSummarizedAt,
+			&i.MaxSubtaskDepth,
+			&i.MaxConcurrentSubtasks,
+			&i.FailureReason,
+			&i.Model,
+			&i.RoutedModel,
+			&i.RequiresReview,
+			&i.ReviewerAgentID,
+			&i.ReviewerUser,
 		); err != nil {
 			return nil, err
 		}
@@ -463,12 +1643,22 @@ func (q *Queries) ListStaleTasks(ctx context.Context, updatedAt sql.NullTime) ([
 	return items, nil
 }
 
-const listSubtasks = `-- name: ListSubtasks :many
-SELECT id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at FROM tasks WHERE parent_task_id = ? ORDER BY created_at ASC
+const listTasksByIDs = `-- name: ListTasksByIDs :many
+SELECT id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at, queue_position, group_id, deferred_until, short_id, secret_names, progress, progress_explicit, context_summary, context_summarized_at, max_subtask_depth, max_concurrent_subtasks, failure_reason, model, routed_model, requires_review, reviewer_agent_id, reviewer_user FROM tasks WHERE id IN (/*SLICE:ids*/?)
 `
 
-func (q *Queries) ListSubtasks(ctx context.Context, parentTaskID sql.NullString) ([]Task, error) {
-	rows, err := q.db.QueryContext(ctx, listSubtasks, parentTaskID)
+func (q *Queries) ListTasksByIDs(ctx context.Context, ids []string) ([]Task, error) {
+	query := listTasksByIDs
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
 	if err != nil {
 		return nil, err
 	}
@@ -501,6 +1691,23 @@ func (q *Queries) ListSubtasks(ctx context.Context, parentTaskID sql.NullString)
 			&i.RetryCount,
 			&i.ScheduledAt,
 			&i.RetryAt,
+			&i.QueuePosition,
+			&i.GroupID,
+			&i.DeferredUntil,
+			&i.ShortID,
+			&i.SecretNames,
+			&i.Progress,
+			&i.ProgressExplicit,
+			&i.ContextSummary,
+			&i.ContextSummarizedAt,
+			&i.MaxSubtaskDepth,
+			&i.MaxConcurrentSubtasks,
+			&i.FailureReason,
+			&i.Model,
+			&i.RoutedModel,
+			&i.RequiresReview,
+			&i.ReviewerAgentID,
+			&i.ReviewerUser,
 		); err != nil {
 			return nil, err
 		}
@@ -515,12 +1722,22 @@ func (q *Queries) ListSubtasks(ctx context.Context, parentTaskID sql.NullString)
 	return items, nil
 }
 
-const listTasks = `-- name: ListTasks :many
-SELECT id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at FROM tasks ORDER BY priority ASC, created_at DESC
+const listTasksByParentIDs = `-- name: ListTasksByParentIDs :many
+SELECT id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at, queue_position, group_id, deferred_until, short_id, secret_names, progress, progress_explicit, context_summary, context_summarized_at, max_subtask_depth, max_concurrent_subtasks, failure_reason, model, routed_model, requires_review, reviewer_agent_id, reviewer_user FROM tasks WHERE parent_task_id IN (/*SLICE:parent_ids*/?) ORDER BY created_at ASC
 `
 
-func (q *Queries) ListTasks(ctx context.Context) ([]Task, error) {
-	rows, err := q.db.QueryContext(ctx, listTasks)
+func (q *Queries) ListTasksByParentIDs(ctx context.Context, parentIds []sql.NullString) ([]Task, error) {
+	query := listTasksByParentIDs
+	var queryParams []interface{}
+	if len(parentIds) > 0 {
+		for _, v := range parentIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:parent_ids*/?", strings.Repeat(",?", len(parentIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:parent_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
 	if err != nil {
 		return nil, err
 	}
@@ -553,6 +1770,23 @@ func (q *Queries) ListTasks(ctx context.Context) ([]Task, error) {
 			&i.RetryCount,
 			&i.ScheduledAt,
 			&i.RetryAt,
+			&i.QueuePosition,
+			&i.GroupID,
+			&i.DeferredUntil,
+			&i.ShortID,
+			&i.SecretNames,
+			&i.Progress,
+			&i.ProgressExplicit,
+			&i.ContextSummary,
+			&i.ContextSummarizedAt,
+			&i.MaxSubtaskDepth,
+			&i.MaxConcurrentSubtasks,
+			&i.FailureReason,
+			&i.Model,
+			&i.RoutedModel,
+			&i.RequiresReview,
+			&i.ReviewerAgentID,
+			&i.ReviewerUser,
 		); err != nil {
 			return nil, err
 		}
@@ -567,12 +1801,12 @@ func (q *Queries) ListTasks(ctx context.Context) ([]Task, error) {
 	return items, nil
 }
 
-const listTasksByAgent = `-- name: ListTasksByAgent :many
-SELECT id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at FROM tasks WHERE agent_id = ? ORDER BY created_at DESC
+const listTasksByProject = `-- name: ListTasksByProject :many
+SELECT id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at, queue_position, group_id, deferred_until, short_id, secret_names, progress, progress_explicit, context_summary, context_summarized_at, max_subtask_depth, max_concurrent_subtasks, failure_reason, model, routed_model, requires_review, reviewer_agent_id, reviewer_user FROM tasks WHERE project_id = ? ORDER BY priority ASC, created_at DESC
 `
 
-func (q *Queries) ListTasksByAgent(ctx context.Context, agentID sql.NullString) ([]Task, error) {
-	rows, err := q.db.QueryContext(ctx, listTasksByAgent, agentID)
+func (q *Queries) ListTasksByProject(ctx context.Context, projectID sql.NullString) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, listTasksByProject, projectID)
 	if err != nil {
 		return nil, err
 	}
@@ -605,6 +1839,23 @@ func (q *Queries) ListTasksByAgent(ctx context.Context, agentID sql.NullString)
 			&i.RetryCount,
 			&i.ScheduledAt,
 			&i.RetryAt,
+			&i.QueuePosition,
+			&i.GroupID,
+			&i.DeferredUntil,
+			&i.ShortID,
+			&i.SecretNames,
+			&i.Progress,
+			&i.ProgressExplicit,
+			&i.ContextSummary,
+			&i.ContextSummarizedAt,
+			&i.MaxSubtaskDepth,
+			&i.MaxConcurrentSubtasks,
+			&i.FailureReason,
+			&i.Model,
+			&i.RoutedModel,
+			&i.RequiresReview,
+			&i.ReviewerAgentID,
+			&i.ReviewerUser,
 		); err != nil {
 			return nil, err
 		}
@@ -619,12 +1870,22 @@ func (q *Queries) ListTasksByAgent(ctx context.Context, agentID sql.NullString)
 	return items, nil
 }
 
-const listTasksByProject = `-- name: ListTasksByProject :many
-SELECT id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at FROM tasks WHERE project_id = ? ORDER BY priority ASC, created_at DESC
+const listTasksByProjectIDs = `-- name: ListTasksByProjectIDs :many
+SELECT id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at, queue_position, group_id, deferred_until, short_id, secret_names, progress, progress_explicit, context_summary, context_summarized_at, max_subtask_depth, max_concurrent_subtasks, failure_reason, model, routed_model, requires_review, reviewer_agent_id, reviewer_user FROM tasks WHERE project_id IN (/*SLICE:project_ids*/?) ORDER BY priority ASC, created_at DESC
 `
 
-func (q *Queries) ListTasksByProject(ctx context.Context, projectID sql.NullString) ([]Task, error) {
-	rows, err := q.db.QueryContext(ctx, listTasksByProject, projectID)
+func (q *Queries) ListTasksByProjectIDs(ctx context.Context, projectIds []sql.NullString) ([]Task, error) {
+	query := listTasksByProjectIDs
+	var queryParams []interface{}
+	if len(projectIds) > 0 {
+		for _, v := range projectIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:project_ids*/?", strings.Repeat(",?", len(projectIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:project_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
 	if err != nil {
 		return nil, err
 	}
@@ -657,6 +1918,23 @@ func (q *Queries) ListTasksByProject(ctx context.Context, projectID sql.NullStri
 			&i.RetryCount,
 			&i.ScheduledAt,
 			&i.RetryAt,
+			&i.QueuePosition,
+			&i.GroupID,
+			&i.DeferredUntil,
+			&i.ShortID,
+			&i.SecretNames,
+			&i.Progress,
+			&i.ProgressExplicit,
+			&i.ContextSummary,
+			&i.ContextSummarizedAt,
+			&i.MaxSubtaskDepth,
+			&i.MaxConcurrentSubtasks,
+			&i.FailureReason,
+			&i.Model,
+			&i.RoutedModel,
+			&i.RequiresReview,
+			&i.ReviewerAgentID,
+			&i.ReviewerUser,
 		); err != nil {
 			return nil, err
 		}
@@ -672,7 +1950,7 @@ func (q *Queries) ListTasksByProject(ctx context.Context, projectID sql.NullStri
 }
 
 const listTasksByStatus = `-- name: ListTasksByStatus :many
-SELECT id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at FROM tasks WHERE status = ? ORDER BY priority ASC, created_at DESC
+SELECT id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at, queue_position, group_id, deferred_until, short_id, secret_names, progress, progress_explicit, context_summary, context_summarized_at, max_subtask_depth, max_concurrent_subtasks, failure_reason, model, routed_model, requires_review, reviewer_agent_id, reviewer_user FROM tasks WHERE status = ? ORDER BY priority ASC, created_at DESC
 `
 
 func (q *Queries) ListTasksByStatus(ctx context.Context, status sql.NullString) ([]Task, error) {
@@ -709,6 +1987,23 @@ func (q *Queries) ListTasksByStatus(ctx context.Context, status sql.NullString)
 			&i.RetryCount,
 			&i.ScheduledAt,
 			&i.RetryAt,
+			&i.QueuePosition,
+			&i.GroupID,
+			&i.DeferredUntil,
+			&i.ShortID,
+			&i.SecretNames,
+			&i.Progress,
+			&i.ProgressExplicit,
+			&i.ContextSummary,
+			&i.ContextSummarizedAt,
+			&i.MaxSubtaskDepth,
+			&i.MaxConcurrentSubtasks,
+			&i.FailureReason,
+			&i.Model,
+			&i.RoutedModel,
+			&i.RequiresReview,
+			&i.ReviewerAgentID,
+			&i.ReviewerUser,
 		); err != nil {
 			return nil, err
 		}
@@ -725,39 +2020,56 @@ func (q *Queries) ListTasksByStatus(ctx context.Context, status sql.NullString)
 
 const listTasksWithStoryCounts = `-- name: ListTasksWithStoryCounts :many
 SELECT 
-    t.id, t.title, t.description, t.agent_id, t.project_id, t.parent_task_id, t.status, t.priority, t.git_branch, t.project_md, t.requirements_md, t.roadmap_md, t.state_md, t.prd_json, t.progress_txt, t.quality_checks, t.created_at, t.updated_at, t.started_at, t.completed_at, t.delegation_mode, t.retry_count, t.scheduled_at, t.retry_at,
+    t.id, t.title, t.description, t.agent_id, t.project_id, t.parent_task_id, t.status, t.priority, t.git_branch, t.project_md, t.requirements_md, t.roadmap_md, t.state_md, t.prd_json, t.progress_txt, t.quality_checks, t.created_at, t.updated_at, t.started_at, t.completed_at, t.delegation_mode, t.retry_count, t.scheduled_at, t.retry_at, t.queue_position, t.group_id, t.deferred_until, t.short_id, t.secret_names, t.progress, t.progress_explicit, t.context_summary, t.context_summarized_at, t.max_subtask_depth, t.max_concurrent_subtasks, t.failure_reason, t.model, t.routed_model, t.requires_review, t.reviewer_agent_id, t.reviewer_user,
     (SELECT COUNT(*) FROM stories WHERE task_id = t.id) as stories_total,
     (SELECT COUNT(*) FROM stories WHERE task_id = t.id AND passes = 1) as stories_passed
 FROM tasks t ORDER BY t.priority ASC, t.created_at DESC
 `
 
 type ListTasksWithStoryCountsRow struct {
-	ID             string         `json:"id"`
-	Title          string         `json:"title"`
-	Description    sql.NullString `json:"description"`
-	AgentID        sql.NullString `json:"agent_id"`
-	ProjectID      sql.NullString `json:"project_id"`
-	ParentTaskID   sql.NullString `json:"parent_task_id"`
-	Status         sql.NullString `json:"status"`
-	Priority       sql.NullInt64  `json:"priority"`
-	GitBranch      sql.NullString `json:"git_branch"`
-	ProjectMd      sql.NullString `json:"project_md"`
-	RequirementsMd sql.NullString `json:"requirements_md"`
-	RoadmapMd      sql.NullString `json:"roadmap_md"`
-	StateMd        sql.NullString `json:"state_md"`
-	PrdJson        sql.NullString `json:"prd_json"`
-	ProgressTxt    sql.NullString `json:"progress_txt"`
-	QualityChecks  sql.NullString `json:"quality_checks"`
-	CreatedAt      sql.NullTime   `json:"created_at"`
-	UpdatedAt      sql.NullTime   `json:"updated_at"`
-	StartedAt      sql.NullTime   `json:"started_at"`
-	CompletedAt    sql.NullTime   `json:"completed_at"`
-	DelegationMode sql.NullString `json:"delegation_mode"`
-	RetryCount     int64          `json:"retry_count"`
-	ScheduledAt    sql.NullTime   `json:"scheduled_at"`
-	RetryAt        sql.NullTime   `json:"retry_at"`
-	StoriesTotal   int64          `json:"stories_total"`
-	StoriesPassed  int64          `json:"stories_passed"`
+	ID                    string         `json:"id"`
+	Title                 string         `json:"title"`
+	Description           sql.NullString `json:"description"`
+	AgentID               sql.NullString `json:"agent_id"`
+	ProjectID             sql.NullString `json:"project_id"`
+	ParentTaskID          sql.NullString `json:"parent_task_id"`
+	Status                sql.NullString `json:"status"`
+	Priority              sql.NullInt64  `json:"priority"`
+	GitBranch             sql.NullString `json:"git_branch"`
+	ProjectMd             sql.NullString `json:"project_md"`
+	RequirementsMd        sql.NullString `json:"requirements_md"`
+	RoadmapMd             sql.NullString `json:"roadmap_md"`
+	StateMd               sql.NullString `json:"state_md"`
+	PrdJson               sql.NullString `json:"prd_json"`
+	ProgressTxt           sql.NullString `json:"progress_txt"`
+	QualityChecks         sql.NullString `json:"quality_checks"`
+	CreatedAt             sql.NullTime   `json:"created_at"`
+	UpdatedAt             sql.NullTime   `json:"updated_at"`
+	StartedAt             sql.NullTime   `json:"started_at"`
+	CompletedAt           sql.NullTime   `json:"completed_at"`
+	DelegationMode        sql.NullString `json:"delegation_mode"`
+	RetryCount            int64          `json:"retry_count"`
+	ScheduledAt           sql.NullTime   `json:"scheduled_at"`
+	RetryAt               sql.NullTime   `json:"retry_at"`
+	QueuePosition         sql.NullInt64  `json:"queue_position"`
+	GroupID               sql.NullString `json:"group_id"`
+	DeferredUntil         sql.NullTime   `json:"deferred_until"`
+	ShortID               sql.NullString `json:"short_id"`
+	SecretNames           sql.NullString `json:"secret_names"`
+	Progress              sql.NullInt64  `json:"progress"`
+	ProgressExplicit      bool           `json:"progress_explicit"`
+	ContextSummary        sql.NullString `json:"context_summary"`
+	ContextSummarizedAt   sql.NullTime   `json:"context_summarized_at"`
+	MaxSubtaskDepth       sql.NullInt64  `json:"max_subtask_depth"`
+	MaxConcurrentSubtasks sql.NullInt64  `json:"max_concurrent_subtasks"`
+	FailureReason         sql.NullString `json:"failure_reason"`
+	Model                 sql.NullString `json:"model"`
+	RoutedModel           sql.NullString `json:"routed_model"`
+	RequiresReview        bool           `json:"requires_review"`
+	ReviewerAgentID       sql.NullString `json:"reviewer_agent_id"`
+	ReviewerUser          sql.NullString `json:"reviewer_user"`
+	StoriesTotal          int64          `json:"stories_total"`
+	StoriesPassed         int64          `json:"stories_passed"`
 }
 
 func (q *Queries) ListTasksWithStoryCounts(ctx context.Context) ([]ListTasksWithStoryCountsRow, error) {
@@ -794,6 +2106,23 @@ func (q *Queries) ListTasksWithStoryCounts(ctx context.Context) ([]ListTasksWith
 			&i.RetryCount,
 			&i.ScheduledAt,
 			&i.RetryAt,
+			&i.QueuePosition,
+			&i.GroupID,
+			&i.DeferredUntil,
+			&i.ShortID,
+			&i.SecretNames,
+			&i.Progress,
+			&i.ProgressExplicit,
+			&i.ContextSummary,
+			&i.ContextSummarizedAt,
+			&i.MaxSubtaskDepth,
+			&i.MaxConcurrentSubtasks,
+			&i.FailureReason,
+			&i.Model,
+			&i.RoutedModel,
+			&i.RequiresReview,
+			&i.ReviewerAgentID,
+			&i.ReviewerUser,
 			&i.StoriesTotal,
 			&i.StoriesPassed,
 		); err != nil {
@@ -810,6 +2139,23 @@ func (q *Queries) ListTasksWithStoryCounts(ctx context.Context) ([]ListTasksWith
 	return items, nil
 }
 
+const reassignOpenTasksByAgent = `-- name: ReassignOpenTasksByAgent :exec
+UPDATE tasks SET agent_id = ?,
+    status = CASE WHEN status IN ('planning', 'discussing', 'executing', 'verifying') THEN 'queued' ELSE status END,
+    queue_position = NULL, retry_count = 0, updated_at = CURRENT_TIMESTAMP
+WHERE agent_id = ? AND status NOT IN ('done', 'failed', 'cancelled')
+`
+
+type ReassignOpenTasksByAgentParams struct {
+	NewAgentID sql.NullString `json:"new_agent_id"`
+	AgentID    sql.NullString `json:"agent_id"`
+}
+
+func (q *Queries) ReassignOpenTasksByAgent(ctx context.Context, arg ReassignOpenTasksByAgentParams) error {
+	_, err := q.db.ExecContext(ctx, reassignOpenTasksByAgent, arg.NewAgentID, arg.AgentID)
+	return err
+}
+
 const resetStuckTask = `-- name: ResetStuckTask :exec
 UPDATE tasks SET status = 'backlog', agent_id = NULL, retry_count = 0, updated_at = CURRENT_TIMESTAMP WHERE id = ?
 `
@@ -828,6 +2174,106 @@ func (q *Queries) ResetTaskRetryCount(ctx context.Context, id string) error {
 	return err
 }
 
+const setTaskContextSummary = `-- name: SetTaskContextSummary :exec
+UPDATE tasks SET context_summary = ?, context_summarized_at = CURRENT_TIMESTAMP WHERE id = ?
+`
+
+type SetTaskContextSummaryParams struct {
+	ContextSummary sql.NullString `json:"context_summary"`
+	ID             string         `json:"id"`
+}
+
+func (q *Queries) SetTaskContextSummary(ctx context.Context, arg SetTaskContextSummaryParams) error {
+	_, err := q.db.ExecContext(ctx, setTaskContextSummary, arg.ContextSummary, arg.ID)
+	return err
+}
+
+const setTaskDeferredUntil = `-- name: SetTaskDeferredUntil :exec
+UPDATE tasks SET deferred_until = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+`
+
+type SetTaskDeferredUntilParams struct {
+	DeferredUntil sql.NullTime `json:"deferred_until"`
+	ID            string       `json:"id"`
+}
+
+func (q *Queries) SetTaskDeferredUntil(ctx context.Context, arg SetTaskDeferredUntilParams) error {
+	_, err := q.db.ExecContext(ctx, setTaskDeferredUntil, arg.DeferredUntil, arg.ID)
+	return err
+}
+
+const setTaskDelegationLimits = `-- name: SetTaskDelegationLimits :exec
+UPDATE tasks SET max_subtask_depth = ?, max_concurrent_subtasks = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+`
+
+type SetTaskDelegationLimitsParams struct {
+	MaxSubtaskDepth       sql.NullInt64 `json:"max_subtask_depth"`
+	MaxConcurrentSubtasks sql.NullInt64 `json:"max_concurrent_subtasks"`
+	ID                    string        `json:"id"`
+}
+
+func (q *Queries) SetTaskDelegationLimits(ctx context.Context, arg SetTaskDelegationLimitsParams) error {
+	_, err := q.db.ExecContext(ctx, setTaskDelegationLimits, arg.MaxSubtaskDepth, arg.MaxConcurrentSubtasks, arg.ID)
+	return err
+}
+
+const setTaskFailureReason = `-- name: SetTaskFailureReason :exec
+UPDATE tasks SET failure_reason = ? WHERE id = ?
+`
+
+type SetTaskFailureReasonParams struct {
+	FailureReason sql.NullString `json:"failure_reason"`
+	ID            string         `json:"id"`
+}
+
+func (q *Queries) SetTaskFailureReason(ctx context.Context, arg SetTaskFailureReasonParams) error {
+	_, err := q.db.ExecContext(ctx, setTaskFailureReason, arg.FailureReason, arg.ID)
+	return err
+}
+
+const setTaskModel = `-- name: SetTaskModel :exec
+UPDATE tasks SET model = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+`
+
+type SetTaskModelParams struct {
+	Model sql.NullString `json:"model"`
+	ID    string         `json:"id"`
+}
+
+func (q *Queries) SetTaskModel(ctx context.Context, arg SetTaskModelParams) error {
+	_, err := q.db.ExecContext(ctx, setTaskModel, arg.Model, arg.ID)
+	return err
+}
+
+const setTaskProgress = `-- name: SetTaskProgress :exec
+UPDATE tasks SET progress = ?, progress_explicit = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+`
+
+type SetTaskProgressParams struct {
+	Progress         sql.NullInt64 `json:"progress"`
+	ProgressExplicit bool          `json:"progress_explicit"`
+	ID               string        `json:"id"`
+}
+
+func (q *Queries) SetTaskProgress(ctx context.Context, arg SetTaskProgressParams) error {
+	_, err := q.db.ExecContext(ctx, setTaskProgress, arg.Progress, arg.ProgressExplicit, arg.ID)
+	return err
+}
+
+const setTaskQueuePosition = `-- name: SetTaskQueuePosition :exec
+UPDATE tasks SET queue_position = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+`
+
+type SetTaskQueuePositionParams struct {
+	QueuePosition sql.NullInt64 `json:"queue_position"`
+	ID            string        `json:"id"`
+}
+
+func (q *Queries) SetTaskQueuePosition(ctx context.Context, arg SetTaskQueuePositionParams) error {
+	_, err := q.db.ExecContext(ctx, setTaskQueuePosition, arg.QueuePosition, arg.ID)
+	return err
+}
+
 const setTaskRetryAt = `-- name: SetTaskRetryAt :exec
 UPDATE tasks SET retry_at = ?, status = 'backlog', updated_at = CURRENT_TIMESTAMP WHERE id = ?
 `
@@ -842,6 +2288,41 @@ func (q *Queries) SetTaskRetryAt(ctx context.Context, arg SetTaskRetryAtParams)
 	return err
 }
 
+const setTaskReview = `-- name: SetTaskReview :exec
+UPDATE tasks SET requires_review = ?, reviewer_agent_id = ?, reviewer_user = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+`
+
+type SetTaskReviewParams struct {
+	RequiresReview  bool           `json:"requires_review"`
+	ReviewerAgentID sql.NullString `json:"reviewer_agent_id"`
+	ReviewerUser    sql.NullString `json:"reviewer_user"`
+	ID              string         `json:"id"`
+}
+
+func (q *Queries) SetTaskReview(ctx context.Context, arg SetTaskReviewParams) error {
+	_, err := q.db.ExecContext(ctx, setTaskReview,
+		arg.RequiresReview,
+		arg.ReviewerAgentID,
+		arg.ReviewerUser,
+		arg.ID,
+	)
+	return err
+}
+
+const setTaskRoutedModel = `-- name: SetTaskRoutedModel :exec
+UPDATE tasks SET routed_model = ? WHERE id = ?
+`
+
+type SetTaskRoutedModelParams struct {
+	RoutedModel sql.NullString `json:"routed_model"`
+	ID          string         `json:"id"`
+}
+
+func (q *Queries) SetTaskRoutedModel(ctx context.Context, arg SetTaskRoutedModelParams) error {
+	_, err := q.db.ExecContext(ctx, setTaskRoutedModel, arg.RoutedModel, arg.ID)
+	return err
+}
+
 const setTaskScheduledAt = `-- name: SetTaskScheduledAt :exec
 UPDATE tasks SET scheduled_at = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
 `
@@ -856,13 +2337,117 @@ func (q *Queries) SetTaskScheduledAt(ctx context.Context, arg SetTaskScheduledAt
 	return err
 }
 
+const setTaskSecretNames = `-- name: SetTaskSecretNames :exec
+UPDATE tasks SET secret_names = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+`
+
+type SetTaskSecretNamesParams struct {
+	SecretNames sql.NullString `json:"secret_names"`
+	ID          string         `json:"id"`
+}
+
+func (q *Queries) SetTaskSecretNames(ctx context.Context, arg SetTaskSecretNamesParams) error {
+	_, err := q.db.ExecContext(ctx, setTaskSecretNames, arg.SecretNames, arg.ID)
+	return err
+}
+
+const stampTaskTimes = `-- name: StampTaskTimes :exec
+UPDATE tasks SET
+    started_at = CASE WHEN started_at IS NULL AND status NOT IN ('backlog', 'queued') THEN CURRENT_TIMESTAMP ELSE started_at END,
+    completed_at = CASE WHEN status IN ('done', 'failed', 'cancelled') THEN COALESCE(completed_at, CURRENT_TIMESTAMP) ELSE NULL END
+WHERE id = ?
+`
+
+func (q *Queries) StampTaskTimes(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, stampTaskTimes, id)
+	return err
+}
+
+const transferTask = `-- name: TransferTask :one
+UPDATE tasks SET
+    agent_id = ?, status = ?, retry_count = 0, retry_at = NULL, queue_position = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND status = ? RETURNING id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at, queue_position, group_id, deferred_until, short_id, secret_names, progress, progress_explicit, context_summary, context_summarized_at, max_subtask_depth, max_concurrent_subtasks, failure_reason, model, routed_model, requires_review, reviewer_agent_id, reviewer_user
+`
+
+type TransferTaskParams struct {
+	AgentID  sql.NullString `json:"agent_id"`
+	Status   sql.NullString `json:"status"`
+	ID       string         `json:"id"`
+	Status_2 sql.NullString `json:"status_2"`
+}
+
+func (q *Queries) TransferTask(ctx context.Context, arg TransferTaskParams) (Task, error) {
+	row := q.db.QueryRowContext(ctx, transferTask,
+		arg.AgentID,
+		arg.Status,
+		arg.ID,
+		arg.Status_2,
+	)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.AgentID,
+		&i.ProjectID,
+		&i.ParentTaskID,
+		&i.Status,
+		&i.Priority,
+		&i.GitBranch,
+		&i.ProjectMd,
+		&i.RequirementsMd,
+		&i.RoadmapMd,
+		&i.StateMd,
+		&i.PrdJson,
+		&i.ProgressTxt,
+		&i.QualityChecks,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.StartedAt,
+		&i.CompletedAt,
+		&i.DelegationMode,
+		&i.RetryCount,
+		&i.ScheduledAt,
+		&i.RetryAt,
+		&i.QueuePosition,
+		&i.GroupID,
+		&i.DeferredUntil,
+		&i.ShortID,
+		&i.SecretNames,
+		&i.Progress,
+		&i.ProgressExplicit,
+		&i.ContextSummary,
+		&i.ContextSummarizedAt,
+		&i.MaxSubtaskDepth,
+		&i.MaxConcurrentSubtasks,
+		&i.FailureReason,
+		&i.Model,
+		&i.RoutedModel,
+		&i.RequiresReview,
+		&i.ReviewerAgentID,
+		&i.ReviewerUser,
+	)
+	return i, err
+}
+
+const unassignOpenTasksByAgent = `-- name: UnassignOpenTasksByAgent :exec
+UPDATE tasks SET agent_id = NULL, status = 'backlog', queue_position = NULL, retry_count = 0, updated_at = CURRENT_TIMESTAMP
+WHERE agent_id = ? AND status NOT IN ('done', 'failed', 'cancelled')
+`
+
+func (q *Queries) UnassignOpenTasksByAgent(ctx context.Context, agentID sql.NullString) error {
+	_, err := q.db.ExecContext(ctx, unassignOpenTasksByAgent, agentID)
+	return err
+}
+
 const updateTask = `-- name: UpdateTask :one
 UPDATE tasks SET
     title = ?, description = ?, agent_id = ?, project_id = ?, status = ?, priority = ?,
     project_md = ?, requirements_md = ?, roadmap_md = ?, state_md = ?,
     prd_json = ?, progress_txt = ?, git_branch = ?, quality_checks = ?,
     delegation_mode = ?, scheduled_at = ?, retry_at = ?, updated_at = CURRENT_TIMESTAMP
-WHERE id = ? RETURNING id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at
+WHERE id = ? RETURNING id, title, description, agent_id, project_id, parent_task_id, status, priority, git_branch, project_md, requirements_md, roadmap_md, state_md, prd_json, progress_txt, quality_checks, created_at, updated_at, started_at, completed_at, delegation_mode, retry_count, scheduled_at, retry_at, queue_position, group_id, deferred_until, short_id, secret_names, progress, progress_explicit, context_summary, context_summarized_at, max_subtask_depth, max_concurrent_subtasks, failure_reason, model, routed_model, requires_review, reviewer_agent_id, reviewer_user
 `
 
 type UpdateTaskParams struct {
@@ -933,12 +2518,50 @@ func (q *Queries) UpdateTask(ctx context.Context, arg UpdateTaskParams) (Task, e
 		&i.RetryCount,
 		&i.ScheduledAt,
 		&i.RetryAt,
+		&i.QueuePosition,
+		&i.GroupID,
+		&i.DeferredUntil,
+		&i.ShortID,
+		&i.SecretNames,
+		&i.Progress,
+		&i.ProgressExplicit,
+		&i.ContextSummary,
+		&i.ContextSummarizedAt,
+		&i.MaxSubtaskDepth,
+		&i.MaxConcurrentSubtasks,
+		&i.FailureReason,
+		&i.Model,
+		&i.RoutedModel,
+		&i.RequiresReview,
+		&i.ReviewerAgentID,
+		&i.ReviewerUser,
 	)
 	return i, err
 }
 
+const updateTaskDetails = `-- name: UpdateTaskDetails :exec
+UPDATE tasks SET title = ?, description = ?, priority = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+`
+
+type UpdateTaskDetailsParams struct {
+	Title       string         `json:"title"`
+	Description sql.NullString `json:"description"`
+	Priority    sql.NullInt64  `json:"priority"`
+	ID          string         `json:"id"`
+}
+
+func (q *Queries) UpdateTaskDetails(ctx context.Context, arg UpdateTaskDetailsParams) error {
+	_, err := q.db.ExecContext(ctx, updateTaskDetails,
+		arg.Title,
+		arg.Description,
+		arg.Priority,
+		arg.ID,
+	)
+	return err
+}
+
 const updateTaskStatus = `-- name: UpdateTaskStatus :exec
-UPDATE tasks SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+UPDATE tasks SET status = ?, queue_position = NULL, deferred_until = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = ?
 `
 
 type UpdateTaskStatusParams struct {
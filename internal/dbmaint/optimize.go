@@ -0,0 +1,187 @@
+// Package dbmaint keeps the SQLite database compact on long-running
+// instances: it refreshes the query planner's statistics, gives free pages
+// back to the file system and empties the write-ahead log, on demand and,
+// optionally, on a schedule.
+package dbmaint
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/leader"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/maintenance"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+	ws "github.com/abelkuruvilla/claw-agent-mission-control/internal/websocket"
+)
+
+// Report sums up an optimize run, with the database size before and after.
+type Report struct {
+	store.OptimizeResult
+	Before     store.DBStats `json:"before"`
+	After      store.DBStats `json:"after"`
+	BytesFreed int64         `json:"bytes_freed"` // database and WAL together; negative if they grew
+	StartedAt  time.Time     `json:"started_at"`
+	DurationMs int64         `json:"duration_ms"`
+}
+
+// Optimizer optimizes the database when asked and, while running, every
+// interval.
+type Optimizer struct {
+	store       *store.Store
+	hub         *ws.Hub
+	interval    time.Duration // 0 = on demand only
+	maintenance *maintenance.Mode
+	leader      *leader.Elector
+
+	mu sync.Mutex // one run at a time
+
+	stopChan chan struct{}
+	running  bool
+}
+
+func NewOptimizer(st *store.Store, hub *ws.Hub, interval time.Duration) *Optimizer {
+	return &Optimizer{
+		store:    st,
+		hub:      hub,
+		interval: interval,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// SetMaintenance sets the maintenance switch; scheduled runs are skipped
+// while it is on.
+func (o *Optimizer) SetMaintenance(m *maintenance.Mode) {
+	o.maintenance = m
+}
+
+// SetLeader sets the leader elector; scheduled runs only happen on the
+// leader.
+func (o *Optimizer) SetLeader(l *leader.Elector) {
+	o.leader = l
+}
+
+// Interval returns how often scheduled runs happen, 0 when they don't.
+func (o *Optimizer) Interval() time.Duration {
+	return o.interval
+}
+
+// Stats returns the size of the database now.
+func (o *Optimizer) Stats(ctx context.Context) (store.DBStats, error) {
+	return o.store.DBStats(ctx)
+}
+
+// Run optimizes the database now. Writes wait while it runs; it never
+// rewrites the whole file (see store.Optimize), so it is quick.
+func (o *Optimizer) Run(ctx context.Context) (Report, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	report := Report{StartedAt: time.Now().UTC()}
+	before, err := o.store.DBStats(ctx)
+	if err != nil {
+		return report, err
+	}
+	report.Before = before
+	result, err := o.store.Optimize(ctx)
+	report.OptimizeResult = result
+	report.DurationMs = time.Since(report.StartedAt).Milliseconds()
+	if err != nil {
+		return report, err
+	}
+	after, err := o.store.DBStats(ctx)
+	if err != nil {
+		return report, err
+	}
+	report.After = after
+	report.BytesFreed = before.SizeBytes + before.WALBytes - after.SizeBytes - after.WALBytes
+
+	log.Printf("[DBMaint] Optimized database (%s vacuum) in %dms: %d -> %d bytes, WAL %d -> %d bytes",
+		report.Vacuum, report.DurationMs, before.SizeBytes, after.SizeBytes, before.WALBytes, after.WALBytes)
+	o.logEvent(ctx, "db_optimized",
+		fmt.Sprintf("Database optimized (%s vacuum): %d -> %d bytes, WAL %d -> %d bytes",
+			report.Vacuum, before.SizeBytes, after.SizeBytes, before.WALBytes, after.WALBytes),
+		fmt.Sprintf(`{"vacuum":%q,"checkpoint_busy":%t,"size_before":%d,"size_after":%d,"wal_before":%d,"wal_after":%d,"bytes_freed":%d,"duration_ms":%d}`,
+			report.Vacuum, report.CheckpointBusy, before.SizeBytes, after.SizeBytes, before.WALBytes, after.WALBytes, report.BytesFreed, report.DurationMs))
+	return report, nil
+}
+
+// Start runs an optimize every interval until Stop is called or ctx is
+// done. It does nothing without an interval.
+func (o *Optimizer) Start(ctx context.Context) {
+	if o.interval <= 0 {
+		return
+	}
+	if o.running {
+		log.Println("[DBMaint] Already running")
+		return
+	}
+	o.running = true
+	log.Printf("[DBMaint] Optimizing the database every %s", o.interval)
+
+	go func() {
+		ticker := time.NewTicker(o.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				o.runScheduled(ctx)
+			case <-o.stopChan:
+				log.Println("[DBMaint] Stopping")
+				o.running = false
+				return
+			case <-ctx.Done():
+				o.running = false
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the scheduled runs.
+func (o *Optimizer) Stop() {
+	if !o.running {
+		return
+	}
+	close(o.stopChan)
+	o.running = false
+}
+
+// Running reports whether the scheduled optimize is running.
+func (o *Optimizer) Running() bool {
+	return o.running
+}
+
+func (o *Optimizer) runScheduled(ctx context.Context) {
+	if o.maintenance.Enabled() {
+		log.Println("[DBMaint] Maintenance mode, skipping optimize")
+		return
+	}
+	if !o.leader.IsLeader() {
+		return
+	}
+	if _, err := o.Run(ctx); err != nil {
+		log.Printf("[DBMaint] Optimize failed: %v", err)
+		o.logEvent(ctx, "db_optimize_failed", fmt.Sprintf("Database optimize failed: %v", err), "")
+	}
+}
+
+func (o *Optimizer) logEvent(ctx context.Context, eventType, message, details string) {
+	event, err := o.store.CreateEvent(ctx, db.CreateEventParams{
+		Type:    eventType,
+		Message: message,
+		Details: sql.NullString{String: details, Valid: details != ""},
+	})
+	if err != nil {
+		log.Printf("[DBMaint] Failed to create event (%s): %v", eventType, err)
+		return
+	}
+	if o.hub != nil {
+		o.hub.BroadcastEvent(event)
+	}
+}
@@ -0,0 +1,185 @@
+// Package dispatch decides which free member of an agent group receives the
+// next task from the group's shared queue.
+//
+// Strategies are selected per group by name. To add one, implement Strategy
+// and register it in the strategies map below.
+package dispatch
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Strategy names accepted by the group API.
+const (
+	FirstFree     = "first_free"
+	RoundRobin    = "round_robin"
+	LeastLoaded   = "least_loaded"
+	SkillWeighted = "skill_weighted"
+	BestPerformer = "best_performer"
+)
+
+// Default is used for groups created without an explicit strategy.
+const Default = FirstFree
+
+// Task is the part of a task strategies may look at.
+type Task struct {
+	ID          string
+	Title       string
+	Description string
+}
+
+// Candidate is a free group member that could receive the task.
+type Candidate struct {
+	AgentID string   `json:"agent_id"`
+	Load    int64    `json:"load"` // open tasks currently assigned to the agent
+	Skills  []string `json:"skills,omitempty"`
+	Score   int      `json:"score,omitempty"`  // set by skill_weighted
+	Rating  float64  `json:"rating,omitempty"` // scorecard score (0-100), set by the caller for best_performer
+}
+
+// Decision is the outcome of a strategy, kept for the audit event.
+type Decision struct {
+	Strategy   string      `json:"strategy"`
+	AgentID    string      `json:"agent_id"`
+	Reason     string      `json:"reason"`
+	Candidates []Candidate `json:"candidates"`
+}
+
+// State is what a strategy knows about previous dispatches in the group.
+type State struct {
+	// LastAgentID is the member chosen by the previous dispatch, if any.
+	LastAgentID string
+	// Members lists every member in join order (free or not), so rotation
+	// is stable even when some members are busy.
+	Members []string
+}
+
+// Strategy picks one of the candidates for task and explains why.
+// Candidates are in member join order and never empty; a strategy may set
+// their Score so it shows up in the audit event.
+type Strategy interface {
+	Choose(task Task, candidates []Candidate, state State) (Candidate, string)
+}
+
+type strategyFunc func(task Task, candidates []Candidate, state State) (Candidate, string)
+
+func (f strategyFunc) Choose(task Task, candidates []Candidate, state State) (Candidate, string) {
+	return f(task, candidates, state)
+}
+
+var strategies = map[string]Strategy{
+	FirstFree:     strategyFunc(firstFree),
+	RoundRobin:    strategyFunc(roundRobin),
+	LeastLoaded:   strategyFunc(leastLoaded),
+	SkillWeighted: strategyFunc(skillWeighted),
+	BestPerformer: strategyFunc(bestPerformer),
+}
+
+// Names returns the registered strategy names, sorted.
+func Names() []string {
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Valid reports whether name is a registered strategy.
+func Valid(name string) bool {
+	_, ok := strategies[name]
+	return ok
+}
+
+// Choose runs the named strategy, falling back to Default for unknown names.
+func Choose(name string, task Task, candidates []Candidate, state State) Decision {
+	strategy, ok := strategies[name]
+	if !ok {
+		name = Default
+		strategy = strategies[Default]
+	}
+	chosen, reason := strategy.Choose(task, candidates, state)
+	return Decision{Strategy: name, AgentID: chosen.AgentID, Reason: reason, Candidates: candidates}
+}
+
+func firstFree(task Task, candidates []Candidate, state State) (Candidate, string) {
+	return candidates[0], "first free member in join order"
+}
+
+// roundRobin picks the first free member after the last one dispatched to,
+// wrapping around the full member list.
+func roundRobin(task Task, candidates []Candidate, state State) (Candidate, string) {
+	free := make(map[string]Candidate, len(candidates))
+	for _, c := range candidates {
+		free[c.AgentID] = c
+	}
+
+	start := 0
+	for i, id := range state.Members {
+		if id == state.LastAgentID {
+			start = i + 1
+			break
+		}
+	}
+	for i := 0; i < len(state.Members); i++ {
+		id := state.Members[(start+i)%len(state.Members)]
+		if c, ok := free[id]; ok {
+			if state.LastAgentID == "" {
+				return c, "first turn in rotation"
+			}
+			return c, fmt.Sprintf("next free member after %s", state.LastAgentID)
+		}
+	}
+	return candidates[0], "rotation exhausted; first free member"
+}
+
+// leastLoaded picks the member with the fewest open tasks; ties go to join order.
+func leastLoaded(task Task, candidates []Candidate, state State) (Candidate, string) {
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		if c.Load < best.Load {
+			best = c
+		}
+	}
+	return best, fmt.Sprintf("fewest open tasks (%d)", best.Load)
+}
+
+// skillWeighted scores each member by how many of its skills appear in the
+// task title or description and picks the highest score; ties go to the
+// least loaded, then join order.
+func skillWeighted(task Task, candidates []Candidate, state State) (Candidate, string) {
+	text := strings.ToLower(task.Title + "\n" + task.Description)
+	for i := range candidates {
+		candidates[i].Score = 0
+		for _, skill := range candidates[i].Skills {
+			if skill = strings.ToLower(strings.TrimSpace(skill)); skill != "" && strings.Contains(text, skill) {
+				candidates[i].Score++
+			}
+		}
+	}
+
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		if c.Score > best.Score || (c.Score == best.Score && c.Load < best.Load) {
+			best = c
+		}
+	}
+	if best.Score == 0 {
+		return best, fmt.Sprintf("no skill matched; fewest open tasks (%d)", best.Load)
+	}
+	return best, fmt.Sprintf("matched %d skill(s)", best.Score)
+}
+
+// bestPerformer picks the member with the highest scorecard rating; ties go
+// to the least loaded, then join order.
+func bestPerformer(task Task, candidates []Candidate, state State) (Candidate, string) {
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		if c.Rating > best.Rating || (c.Rating == best.Rating && c.Load < best.Load) {
+			best = c
+		}
+	}
+	return best, fmt.Sprintf("highest scorecard rating (%.1f)", best.Rating)
+}
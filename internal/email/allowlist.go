@@ -0,0 +1,45 @@
+package email
+
+import "strings"
+
+// Allowlist is the senders whose mail may become tasks: single addresses
+// (alice@example.com) and whole domains (@example.com). An empty allowlist
+// allows nobody.
+type Allowlist struct {
+	addresses map[string]bool
+	domains   map[string]bool
+}
+
+// ParseAllowlist parses a comma-separated allowlist, e.g.
+// "alice@example.com, @ops.example.com".
+func ParseAllowlist(s string) Allowlist {
+	a := Allowlist{addresses: map[string]bool{}, domains: map[string]bool{}}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "":
+		case strings.HasPrefix(entry, "@"):
+			a.domains[entry[1:]] = true
+		case strings.Contains(entry, "@"):
+			a.addresses[entry] = true
+		default:
+			a.domains[entry] = true
+		}
+	}
+	return a
+}
+
+// Allows reports whether mail from addr may become a task.
+func (a Allowlist) Allows(addr string) bool {
+	addr = strings.ToLower(addr)
+	if a.addresses[addr] {
+		return true
+	}
+	at := strings.LastIndex(addr, "@")
+	return at >= 0 && a.domains[addr[at+1:]]
+}
+
+// Empty reports whether the allowlist allows nobody.
+func (a Allowlist) Empty() bool {
+	return len(a.addresses) == 0 && len(a.domains) == 0
+}
@@ -0,0 +1,145 @@
+package email
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/intake"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+	ws "github.com/abelkuruvilla/claw-agent-mission-control/internal/websocket"
+)
+
+// What became of an email.
+const (
+	StatusCreated   = "created"   // a task was created
+	StatusDuplicate = "duplicate" // a task was created from the same Message-ID before
+	StatusRejected  = "rejected"  // the recipient or sender is not allowed
+)
+
+// Result is what became of an email.
+type Result struct {
+	Status string `json:"status"`
+	TaskID string `json:"task_id,omitempty"`
+	Reason string `json:"reason,omitempty"` // why it was rejected
+}
+
+// Gateway creates tasks from emails.
+type Gateway struct {
+	tasks     intake.Creator
+	store     *store.Store
+	hub       *ws.Hub
+	address   string // empty accepts mail for any recipient
+	allowlist Allowlist
+	projectID string // empty creates tasks outside any project
+}
+
+// NewGateway creates a gateway taking mail sent to address (any, if empty)
+// from the senders allowedSenders lists (see ParseAllowlist), creating its
+// tasks in projectID (none, if empty) through tasks, as the API does.
+func NewGateway(tasks intake.Creator, st *store.Store, hub *ws.Hub, address, allowedSenders, projectID string) *Gateway {
+	return &Gateway{
+		tasks:     tasks,
+		store:     st,
+		hub:       hub,
+		address:   strings.ToLower(strings.TrimSpace(address)),
+		allowlist: ParseAllowlist(allowedSenders),
+		projectID: projectID,
+	}
+}
+
+// Receive creates the task of an email, unless it is not allowed or was
+// received before. Rejections are recorded as email_rejected events, tasks
+// created as email_received events.
+func (g *Gateway) Receive(ctx context.Context, msg Message) (Result, error) {
+	if msg.MessageID != "" {
+		prior, err := g.store.GetInboundEmailByMessageID(ctx, msg.MessageID)
+		if err == nil {
+			return Result{Status: StatusDuplicate, TaskID: prior.TaskID}, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return Result{}, err
+		}
+	}
+
+	details, _ := json.Marshal(map[string]string{
+		"sender":     msg.Sender(),
+		"subject":    msg.Subject,
+		"message_id": msg.MessageID,
+	})
+	if reason := g.check(msg); reason != "" {
+		g.logEvent(ctx, "", "email_rejected", fmt.Sprintf("Rejected email from %s: %s", msg.From, reason), string(details))
+		return Result{Status: StatusRejected, Reason: reason}, nil
+	}
+
+	title := msg.Subject
+	if title == "" {
+		title = "Email from " + msg.Sender()
+	}
+	desc := "From email by " + msg.Sender()
+	if msg.Text != "" {
+		desc = msg.Text + "\n\n" + desc
+	}
+	// The email is recorded with its task, so a retried delivery is a
+	// duplicate only if the task was created
+	task, err := g.tasks.CreateLinkedTask(ctx, intake.Task{
+		Title:       title,
+		Description: desc,
+		ProjectID:   g.projectID,
+		Source:      "email by " + msg.Sender(),
+	}, func(tx *store.Store, task db.Task) error {
+		_, err := tx.CreateInboundEmail(ctx, db.CreateInboundEmailParams{
+			TaskID:    task.ID,
+			MessageID: sql.NullString{String: msg.MessageID, Valid: msg.MessageID != ""},
+			Sender:    msg.Sender(),
+			Subject:   msg.Subject,
+		})
+		return err
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	g.logEvent(ctx, task.ID, "email_received", "Task created from email by "+msg.Sender(), string(details))
+	return Result{Status: StatusCreated, TaskID: task.ID}, nil
+}
+
+// check returns why an email may not become a task, or "" if it may.
+func (g *Gateway) check(msg Message) string {
+	if g.address != "" && !addressedTo(msg, g.address) {
+		return "not addressed to " + g.address
+	}
+	if !g.allowlist.Allows(msg.From) {
+		return "sender not allowed"
+	}
+	return ""
+}
+
+func addressedTo(msg Message, address string) bool {
+	for _, to := range msg.To {
+		if to == address {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Gateway) logEvent(ctx context.Context, taskID, eventType, message, details string) {
+	event, err := g.store.CreateEvent(ctx, db.CreateEventParams{
+		TaskID:  sql.NullString{String: taskID, Valid: taskID != ""},
+		Type:    eventType,
+		Message: message,
+		Details: sql.NullString{String: details, Valid: details != ""},
+	})
+	if err != nil {
+		log.Printf("[EmailGateway] Failed to record %s event: %v", eventType, err)
+		return
+	}
+	if g.hub != nil {
+		g.hub.BroadcastEvent(event)
+	}
+}
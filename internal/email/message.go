@@ -0,0 +1,203 @@
+// Package email turns inbound emails into tasks: messages a mail provider or
+// MTA posts to the email webhook are parsed, checked against the sender
+// allowlist, and created as tasks with the subject as title and the body as
+// description.
+package email
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// Message is the part of an email a task is made of.
+type Message struct {
+	MessageID string   // Message-ID header, without angle brackets
+	From      string   // sender address, lower case
+	FromName  string   // sender display name, if any
+	To        []string // recipient addresses, lower case
+	Subject   string
+	Text      string // plain-text body
+}
+
+// Sender is the sender as "Name <address>", or just the address.
+func (m Message) Sender() string {
+	if m.FromName == "" {
+		return m.From
+	}
+	return fmt.Sprintf("%s <%s>", m.FromName, m.From)
+}
+
+// recipientHeaders are the headers recipients are read from. Delivered-To
+// and X-Original-To carry the envelope recipient, which is the only trace of
+// the gateway's address on mail it got as Bcc or through an alias.
+var recipientHeaders = []string{"To", "Cc", "Delivered-To", "X-Original-To"}
+
+// Parse parses a raw RFC 5322 message, taking its text/plain body (or its
+// text/html body, stripped of markup, if it has no plain one) and leaving
+// out attachments.
+func Parse(raw []byte) (Message, error) {
+	m, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return Message{}, fmt.Errorf("parse email: %w", err)
+	}
+	from, err := m.Header.AddressList("From")
+	if err != nil || len(from) == 0 {
+		return Message{}, errors.New("parse email: no From address")
+	}
+
+	msg := Message{
+		MessageID: strings.Trim(strings.TrimSpace(m.Header.Get("Message-ID")), "<>"),
+		From:      strings.ToLower(from[0].Address),
+		FromName:  from[0].Name,
+	}
+	subject := m.Header.Get("Subject")
+	if decoded, err := new(mime.WordDecoder).DecodeHeader(subject); err == nil {
+		subject = decoded
+	}
+	msg.Subject = strings.TrimSpace(subject)
+	for _, h := range recipientHeaders {
+		addrs, err := m.Header.AddressList(h)
+		if err != nil {
+			continue
+		}
+		for _, a := range addrs {
+			msg.To = append(msg.To, strings.ToLower(a.Address))
+		}
+	}
+
+	plain, htmlText, err := body(m.Header.Get("Content-Type"), m.Header.Get("Content-Transfer-Encoding"), m.Body)
+	if err != nil {
+		return Message{}, fmt.Errorf("parse email body: %w", err)
+	}
+	if plain == "" && htmlText != "" {
+		plain = stripHTML(htmlText)
+	}
+	msg.Text = strings.TrimSpace(plain)
+	return msg, nil
+}
+
+// NewMessage makes a Message of the fields of an email a provider has
+// parsed already. to may list several addresses, separated by commas.
+func NewMessage(from, to, subject, text, messageID string) (Message, error) {
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return Message{}, fmt.Errorf("invalid from address: %w", err)
+	}
+	msg := Message{
+		MessageID: strings.Trim(strings.TrimSpace(messageID), "<>"),
+		From:      strings.ToLower(sender.Address),
+		FromName:  sender.Name,
+		Subject:   strings.TrimSpace(subject),
+		Text:      strings.TrimSpace(text),
+	}
+	if strings.TrimSpace(to) != "" {
+		addrs, err := mail.ParseAddressList(to)
+		if err != nil {
+			return Message{}, fmt.Errorf("invalid to address: %w", err)
+		}
+		for _, a := range addrs {
+			msg.To = append(msg.To, strings.ToLower(a.Address))
+		}
+	}
+	return msg, nil
+}
+
+// body returns the first text/plain and text/html content of a message
+// (part), descending into multiparts.
+func body(contentType, transferEncoding string, r io.Reader) (plain, htmlText string, err error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		// No (or a broken) Content-Type means plain ASCII text
+		mediaType, params = "text/plain", nil
+	}
+	r = decodeTransfer(transferEncoding, r)
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(r, params["boundary"])
+		for {
+			part, err := mr.NextRawPart()
+			if err == io.EOF {
+				return plain, htmlText, nil
+			}
+			if err != nil {
+				return plain, htmlText, err
+			}
+			if strings.HasPrefix(strings.ToLower(part.Header.Get("Content-Disposition")), "attachment") {
+				continue
+			}
+			p, h, err := body(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part)
+			if err != nil {
+				return plain, htmlText, err
+			}
+			if plain == "" {
+				plain = p
+			}
+			if htmlText == "" {
+				htmlText = h
+			}
+		}
+	}
+
+	if mediaType != "text/plain" && mediaType != "text/html" {
+		return "", "", nil
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", "", err
+	}
+	text := decodeCharset(params["charset"], data)
+	if mediaType == "text/html" {
+		return "", text, nil
+	}
+	return text, "", nil
+}
+
+func decodeTransfer(encoding string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, r)
+	}
+	return r
+}
+
+// decodeCharset returns text in charset as UTF-8. Only Latin-1 needs
+// converting in practice; other charsets are passed through as they are.
+func decodeCharset(charset string, data []byte) string {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1":
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	}
+	return string(data)
+}
+
+var (
+	blockTagPattern = regexp.MustCompile(`(?i)<(br|/p|/div|/li|/tr|/h[1-6])[^>]*>`)
+	skipTagPattern  = regexp.MustCompile(`(?is)<(style|script)[^>]*>.*?</(style|script)>`)
+	tagPattern      = regexp.MustCompile(`<[^>]*>`)
+	blankPattern    = regexp.MustCompile(`\n[ \t]*(\n[ \t]*)+`)
+)
+
+// stripHTML reduces an HTML body to its text, keeping line breaks.
+func stripHTML(s string) string {
+	s = skipTagPattern.ReplaceAllString(s, "")
+	s = blockTagPattern.ReplaceAllString(s, "\n")
+	s = tagPattern.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	return blankPattern.ReplaceAllString(s, "\n\n")
+}
@@ -0,0 +1,350 @@
+// Package eventarchive copies every event to long-term storage as it is
+// recorded, in addition to SQLite: to JSONL files rotated daily and by size,
+// to an HTTP endpoint in batches, or both. The history then survives pruning
+// of the events table and can be analyzed with external tooling.
+package eventarchive
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+)
+
+const (
+	queueSize  = 1024 // events waiting to be archived before new ones are dropped
+	maxPending = 10   // batches kept for the endpoint while it is unreachable
+)
+
+// Record is an event as archived: one line of a JSONL file, or one line of a
+// batch posted to the endpoint.
+type Record struct {
+	ID        string  `json:"id"`
+	Type      string  `json:"type"`
+	Message   string  `json:"message"`
+	TaskID    *string `json:"task_id,omitempty"`
+	AgentID   *string `json:"agent_id,omitempty"`
+	Details   *string `json:"details,omitempty"`
+	CreatedAt string  `json:"created_at"`
+}
+
+func toRecord(e db.Event) Record {
+	r := Record{ID: e.ID, Type: e.Type, Message: e.Message}
+	if e.TaskID.Valid {
+		r.TaskID = &e.TaskID.String
+	}
+	if e.AgentID.Valid {
+		r.AgentID = &e.AgentID.String
+	}
+	if e.Details.Valid {
+		r.Details = &e.Details.String
+	}
+	created := time.Now()
+	if e.CreatedAt.Valid {
+		created = e.CreatedAt.Time
+	}
+	r.CreatedAt = created.UTC().Format(time.RFC3339)
+	return r
+}
+
+// Config is where events are archived. An empty Dir or URL disables that
+// destination.
+type Config struct {
+	Dir           string        // directory of events-YYYY-MM-DD[.N].jsonl files
+	MaxFileSize   int64         // bytes a file grows to before the next is started; 0 = rotate daily only
+	URL           string        // endpoint batches are POSTed to as application/x-ndjson
+	Token         string        // bearer token for the endpoint (optional)
+	BatchSize     int           // events per POST (default 100)
+	FlushInterval time.Duration // longest an event waits for its batch to be posted (default 10s)
+}
+
+// Enabled reports whether any destination is configured.
+func (c Config) Enabled() bool {
+	return c.Dir != "" || c.URL != ""
+}
+
+// Archiver archives events in the background, so recording an event never
+// waits on a disk or the network.
+type Archiver struct {
+	cfg    Config
+	http   *http.Client
+	events chan db.Event
+
+	file     *os.File
+	writer   *bufio.Writer
+	fileDay  string
+	fileSeq  int
+	fileSize int64
+
+	batch   []Record
+	pending [][]Record
+
+	mu       sync.Mutex
+	dropped  int64
+	stopChan chan struct{}
+	done     chan struct{}
+	running  bool
+}
+
+func New(cfg Config) *Archiver {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 10 * time.Second
+	}
+	return &Archiver{
+		cfg:      cfg,
+		http:     &http.Client{Timeout: 30 * time.Second},
+		events:   make(chan db.Event, queueSize),
+		stopChan: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Record queues an event for archiving. It is the store's event sink; if the
+// queue is full the event is dropped (it is still in SQLite) and counted.
+func (a *Archiver) Record(e db.Event) {
+	select {
+	case a.events <- e:
+	default:
+		a.mu.Lock()
+		a.dropped++
+		n := a.dropped
+		a.mu.Unlock()
+		if n == 1 || n%100 == 0 {
+			log.Printf("[EventArchive] Queue full, %d event(s) not archived", n)
+		}
+	}
+}
+
+// Start archives queued events until Stop is called or ctx is done.
+func (a *Archiver) Start(ctx context.Context) {
+	if a.running {
+		log.Println("[EventArchive] Already running")
+		return
+	}
+	a.running = true
+	if a.cfg.Dir != "" {
+		log.Printf("[EventArchive] Archiving events to %s", a.cfg.Dir)
+	}
+	if a.cfg.URL != "" {
+		log.Printf("[EventArchive] Shipping events to %s every %v", a.cfg.URL, a.cfg.FlushInterval)
+	}
+
+	go func() {
+		defer close(a.done)
+		ticker := time.NewTicker(a.cfg.FlushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case e := <-a.events:
+				a.archive(ctx, e)
+			case <-ticker.C:
+				a.flush(ctx)
+			case <-a.stopChan:
+				a.drain(ctx)
+				return
+			case <-ctx.Done():
+				a.drain(context.Background())
+				return
+			}
+		}
+	}()
+}
+
+// Stop archives the events still queued, posts the last batch and closes
+// the current file.
+func (a *Archiver) Stop() {
+	if !a.running {
+		return
+	}
+	close(a.stopChan)
+	<-a.done
+	a.running = false
+}
+
+func (a *Archiver) drain(ctx context.Context) {
+	for {
+		select {
+		case e := <-a.events:
+			a.archive(ctx, e)
+		default:
+			a.flush(ctx)
+			a.closeFile()
+			log.Println("[EventArchive] Stopped")
+			return
+		}
+	}
+}
+
+func (a *Archiver) archive(ctx context.Context, e db.Event) {
+	r := toRecord(e)
+	if a.cfg.Dir != "" {
+		if err := a.writeFile(r); err != nil {
+			log.Printf("[EventArchive] Failed to write event %s: %v", r.ID, err)
+		}
+	}
+	if a.cfg.URL != "" {
+		a.batch = append(a.batch, r)
+		if len(a.batch) >= a.cfg.BatchSize {
+			a.flush(ctx)
+		}
+	}
+}
+
+// flush writes buffered lines to disk and posts the batch, along with any
+// batches the endpoint failed to take earlier.
+func (a *Archiver) flush(ctx context.Context) {
+	if a.writer != nil {
+		if err := a.writer.Flush(); err != nil {
+			log.Printf("[EventArchive] Failed to flush %s: %v", a.file.Name(), err)
+		}
+	}
+	if a.cfg.URL == "" {
+		return
+	}
+	if len(a.batch) > 0 {
+		a.pending = append(a.pending, a.batch)
+		a.batch = nil
+	}
+	if len(a.pending) > maxPending {
+		dropped := 0
+		for _, b := range a.pending[:len(a.pending)-maxPending] {
+			dropped += len(b)
+		}
+		log.Printf("[EventArchive] Endpoint unreachable, dropping %d event(s)", dropped)
+		a.pending = a.pending[len(a.pending)-maxPending:]
+	}
+	for len(a.pending) > 0 {
+		if err := a.post(ctx, a.pending[0]); err != nil {
+			log.Printf("[EventArchive] Failed to ship %d event(s), retrying later: %v", len(a.pending[0]), err)
+			return
+		}
+		a.pending = a.pending[1:]
+	}
+}
+
+func (a *Archiver) post(ctx context.Context, batch []Record) error {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, r := range batch {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.cfg.URL, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if a.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+a.cfg.Token)
+	}
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("endpoint returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// writeFile appends a record to the current file, starting a new one at
+// midnight UTC or once the file reaches MaxFileSize.
+func (a *Archiver) writeFile(r Record) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	day := time.Now().UTC().Format("2006-01-02")
+	switch {
+	case a.file == nil || day != a.fileDay:
+		a.closeFile()
+		if err := a.openFile(day, -1); err != nil {
+			return err
+		}
+	case a.cfg.MaxFileSize > 0 && a.fileSize > 0 && a.fileSize+int64(len(line)) > a.cfg.MaxFileSize:
+		a.closeFile()
+		if err := a.openFile(day, a.fileSeq+1); err != nil {
+			return err
+		}
+	}
+	n, err := a.writer.Write(line)
+	a.fileSize += int64(n)
+	return err
+}
+
+// openFile opens file seq of day for appending; seq -1 picks up the last
+// file of the day, as after a restart.
+func (a *Archiver) openFile(day string, seq int) error {
+	if err := os.MkdirAll(a.cfg.Dir, 0o755); err != nil {
+		return err
+	}
+	if seq < 0 {
+		seq = a.lastSeq(day)
+	}
+	f, err := os.OpenFile(filepath.Join(a.cfg.Dir, fileName(day, seq)), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	a.file, a.writer = f, bufio.NewWriter(f)
+	a.fileDay, a.fileSeq, a.fileSize = day, seq, info.Size()
+	return nil
+}
+
+func (a *Archiver) closeFile() {
+	if a.file == nil {
+		return
+	}
+	if err := a.writer.Flush(); err != nil {
+		log.Printf("[EventArchive] Failed to flush %s: %v", a.file.Name(), err)
+	}
+	a.file.Close()
+	a.file, a.writer = nil, nil
+}
+
+// lastSeq is the highest sequence number among day's files, 0 if none.
+func (a *Archiver) lastSeq(day string) int {
+	matches, _ := filepath.Glob(filepath.Join(a.cfg.Dir, "events-"+day+"*.jsonl"))
+	last := 0
+	for _, m := range matches {
+		rest := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(m), "events-"+day), ".jsonl")
+		if n, err := strconv.Atoi(strings.TrimPrefix(rest, ".")); err == nil && n > last {
+			last = n
+		}
+	}
+	return last
+}
+
+// fileName is events-2024-05-01.jsonl for the first file of a day, then
+// events-2024-05-01.1.jsonl and so on.
+func fileName(day string, seq int) string {
+	if seq == 0 {
+		return "events-" + day + ".jsonl"
+	}
+	return fmt.Sprintf("events-%s.%d.jsonl", day, seq)
+}
@@ -62,7 +62,7 @@ func (e *GSDEngine) ExecutePhase(ctx context.Context, task db.Task, phase db.Pha
 	token := fmt.Sprintf("exec-%s-%d", phase.ID, time.Now().Unix())
 
 	// Build prompt
-	prompt := e.buildExecutePrompt(task, phase, token)
+	prompt := e.buildExecutePrompt(task, phase, token, agentAPIURL(ctx, e.store, e.apiBaseURL, task))
 
 	// Spawn fresh session
 	resp, err := e.openclawClient.Spawn(ctx, &openclaw.SpawnRequest{
@@ -91,7 +91,7 @@ func (e *GSDEngine) ExecutePhase(ctx context.Context, task db.Task, phase db.Pha
 	return nil
 }
 
-func (e *GSDEngine) buildExecutePrompt(task db.Task, phase db.Phase, token string) string {
+func (e *GSDEngine) buildExecutePrompt(task db.Task, phase db.Phase, token, apiURL string) string {
 	return fmt.Sprintf(`# Task Execution Context
 
 ## Mission Control API
@@ -155,10 +155,10 @@ If you encounter a blocking issue, call the fail API with details.
 
 Start working on this phase. Report progress and call complete when done.
 `,
-		e.apiBaseURL, token,
-		e.apiBaseURL, phase.ID,
-		e.apiBaseURL, phase.ID,
-		e.apiBaseURL, phase.ID,
+		apiURL, token,
+		apiURL, phase.ID,
+		apiURL, phase.ID,
+		apiURL, phase.ID,
 		task.ID, task.Title, task.Description.String, task.WorkDir.String,
 		phase.ID, phase.Sequence, phase.Title, phase.Description.String,
 		phase.Sequence,
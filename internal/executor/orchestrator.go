@@ -173,6 +173,19 @@ func (o *Orchestrator) IsRunning(taskID string) bool {
 	return exists
 }
 
+// agentAPIURL returns the URL the task's agent reaches Mission Control at:
+// its own public_api_url, else apiBaseURL (PUBLIC_API_URL).
+func agentAPIURL(ctx context.Context, s *store.Store, apiBaseURL string, task db.Task) string {
+	if !task.AgentID.Valid {
+		return apiBaseURL
+	}
+	agent, err := s.GetAgent(ctx, task.AgentID.String)
+	if err != nil || !agent.PublicAPIURL.Valid {
+		return apiBaseURL
+	}
+	return agent.PublicAPIURL.String
+}
+
 func (o *Orchestrator) logEvent(ctx context.Context, taskID, eventType, message string) {
 	o.store.CreateEvent(ctx, db.CreateEventParams{
 		TaskID:  sql.NullString{String: taskID, Valid: true},
@@ -75,7 +75,7 @@ func (e *RalphEngine) ExecuteStory(ctx context.Context, task db.Task, story db.S
 	token := fmt.Sprintf("ralph-%s-%d", story.ID, time.Now().Unix())
 
 	// Build prompt
-	prompt := e.buildStoryPrompt(task, story, iteration, token)
+	prompt := e.buildStoryPrompt(task, story, iteration, token, agentAPIURL(ctx, e.store, e.apiBaseURL, task))
 
 	// Spawn fresh session
 	resp, err := e.openclawClient.Spawn(ctx, &openclaw.SpawnRequest{
@@ -103,7 +103,7 @@ func (e *RalphEngine) ExecuteStory(ctx context.Context, task db.Task, story db.S
 	return nil
 }
 
-func (e *RalphEngine) buildStoryPrompt(task db.Task, story db.Story, iteration int, token string) string {
+func (e *RalphEngine) buildStoryPrompt(task db.Task, story db.Story, iteration int, token, apiURL string) string {
 	return fmt.Sprintf(`# Ralph Loop Execution Context
 
 ## Mission Control API
@@ -170,10 +170,10 @@ curl -X POST %s/api/v1/tasks/%s/progress-txt \
 Implement this story. Focus on THIS STORY ONLY.
 When done, call the appropriate API endpoint.
 `,
-		e.apiBaseURL, token,
-		e.apiBaseURL, story.ID,
-		e.apiBaseURL, story.ID, iteration,
-		e.apiBaseURL, task.ID,
+		apiURL, token,
+		apiURL, story.ID,
+		apiURL, story.ID, iteration,
+		apiURL, task.ID,
 		task.Title, task.ID, task.WorkDir.String, iteration, e.maxIterations,
 		story.ID, story.Title, story.Priority.Int64,
 		story.Description.String,
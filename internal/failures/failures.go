@@ -0,0 +1,181 @@
+// Package failures tells why a task failed or was reset, from the error text
+// around it (send errors, failure events, system comments), and what to do
+// about it.
+// Classification is by keyword, so it is a hint rather than a diagnosis.
+package failures
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+)
+
+// Failure reasons, from most to least specific.
+const (
+	RateLimit   = "rate_limit"   // the model provider throttled the agent
+	SessionLock = "session_lock" // the agent's session was busy with another run
+	TestFailure = "test_failure" // tests or quality checks did not pass
+	Timeout     = "timeout"      // the agent went silent or a call timed out
+	Unknown     = "unknown"
+)
+
+// Reasons lists the failure reasons in order of precedence.
+var Reasons = []string{RateLimit, SessionLock, TestFailure, Timeout, Unknown}
+
+// patterns are matched, lower-cased, against failure text.
+var patterns = map[string][]string{
+	RateLimit: {
+		"rate limit", "rate_limit", "ratelimit", "too many requests", "status 429",
+		"quota", "overloaded", "all models failed",
+	},
+	SessionLock: {"session file locked", "session locked", "session is locked", "lock held"},
+	TestFailure: {
+		"test failed", "tests failed", "failing test", "--- fail", "assertion",
+		"quality check", "story failed", "verification failed",
+	},
+	Timeout: {"timed out", "timeout", "deadline exceeded", "no update for", "no response"},
+}
+
+// Classify returns the failure reason texts point to. When they point to
+// several, the most specific wins: a rate limit explains a timeout, not the
+// other way round.
+func Classify(texts ...string) string {
+	found := map[string]bool{}
+	for _, t := range texts {
+		t = strings.ToLower(t)
+		for reason, keys := range patterns {
+			for _, k := range keys {
+				if strings.Contains(t, k) {
+					found[reason] = true
+					break
+				}
+			}
+		}
+	}
+	for _, r := range Reasons {
+		if found[r] {
+			return r
+		}
+	}
+	return Unknown
+}
+
+// Valid reports whether reason is a failure reason.
+func Valid(reason string) bool {
+	for _, r := range Reasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// Remediation is a suggested next step for a failed task.
+type Remediation struct {
+	Action string `json:"action"` // retry | retry_later | reassign | split
+	Detail string `json:"detail"`
+}
+
+// Suggest returns the remediations for reason, best first.
+func Suggest(reason string) []Remediation {
+	switch reason {
+	case RateLimit:
+		return []Remediation{
+			{"retry_later", "The provider is throttling requests; schedule a retry (retry_at) once the limit resets"},
+			{"reassign", "Hand the task to an agent on another model or provider"},
+		}
+	case SessionLock:
+		return []Remediation{
+			{"retry", "The agent's session was busy; retry once its current run finishes"},
+			{"reassign", "Hand the task to an idle agent"},
+		}
+	case TestFailure:
+		return []Remediation{
+			{"retry", "Retry with the failing tests or checks pointed out in a comment"},
+			{"split", "Split the task so each part can be verified on its own"},
+			{"reassign", "Hand the task to an agent with the right skills"},
+		}
+	case Timeout:
+		return []Remediation{
+			{"split", "The task may be too large for one run; split it into smaller tasks"},
+			{"retry_later", "Retry later in case the agent or gateway was overloaded"},
+			{"reassign", "Hand the task to another agent"},
+		}
+	}
+	return []Remediation{
+		{"retry", "Retry the task, and check its comments and events for the cause"},
+		{"reassign", "Hand the task to another agent"},
+	}
+}
+
+// Source is where the text of a task's failure is gathered from; the store
+// satisfies it.
+type Source interface {
+	ListTaskAttemptsByTask(ctx context.Context, taskID string) ([]db.TaskAttempt, error)
+	ListEventsByTask(ctx context.Context, taskID string, limit int64) ([]db.Event, error)
+	ListCommentsByTask(ctx context.Context, taskID string) ([]db.Comment, error)
+}
+
+// evidenceSize is how many of a task's latest texts are classified; older
+// ones likely belong to earlier runs.
+const evidenceSize = 30
+
+// Evidence returns the latest text recorded on taskID, newest first: send
+// errors, the messages of failure events and system comments. Other events
+// and agents' own comments are left out, as talk of timeouts or tests in
+// titles and work would be taken for errors.
+func Evidence(ctx context.Context, src Source, taskID string) []string {
+	type item struct {
+		at   time.Time
+		text string
+	}
+	var items []item
+	if attempts, err := src.ListTaskAttemptsByTask(ctx, taskID); err == nil {
+		for _, a := range attempts {
+			if a.Error.Valid && a.Error.String != "" {
+				items = append(items, item{a.CreatedAt.Time, a.Error.String})
+			}
+		}
+	}
+	if events, err := src.ListEventsByTask(ctx, taskID, evidenceSize); err == nil {
+		for _, e := range events {
+			if failureEvent(e.Type) {
+				items = append(items, item{e.CreatedAt.Time, e.Message})
+			}
+		}
+	}
+	if comments, err := src.ListCommentsByTask(ctx, taskID); err == nil {
+		if len(comments) > evidenceSize {
+			comments = comments[len(comments)-evidenceSize:]
+		}
+		for _, c := range comments {
+			if c.Author == "system" {
+				items = append(items, item{c.CreatedAt.Time, c.Content})
+			}
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].at.After(items[j].at) })
+	if len(items) > evidenceSize {
+		items = items[:evidenceSize]
+	}
+	texts := make([]string, len(items))
+	for i, it := range items {
+		texts[i] = it.text
+	}
+	return texts
+}
+
+// failureEvent reports whether events of type eventType record something
+// going wrong, e.g. story_failed or task_stuck_retry.
+func failureEvent(eventType string) bool {
+	for _, k := range []string{"fail", "stuck", "abandon", "error"} {
+		if strings.Contains(eventType, k) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+// Package github syncs GitHub Issues with Mission Control projects: open
+// issues carrying a project's label become its tasks, finishing a task
+// closes its issue with a comment linking the work's commits, and webhooks
+// keep tasks current as their issues are edited, commented on, closed or
+// reopened.
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultAPIURL is the API of github.com; GitHub Enterprise Server has its
+// own, e.g. https://github.example.com/api/v3.
+const DefaultAPIURL = "https://api.github.com"
+
+// issuesPageSize is how many issues each list request asks for.
+const issuesPageSize = 100
+
+// Issue is the part of a GitHub issue Mission Control uses.
+type Issue struct {
+	Number      int64     `json:"number"`
+	Title       string    `json:"title"`
+	Body        string    `json:"body"`
+	State       string    `json:"state"`        // open | closed
+	StateReason string    `json:"state_reason"` // completed | not_planned | reopened, once closed
+	HTMLURL     string    `json:"html_url"`
+	Labels      []Label   `json:"labels"`
+	PullRequest *struct{} `json:"pull_request"` // set on pull requests, which the issues API lists too
+}
+
+// Label is a label on an issue.
+type Label struct {
+	Name string `json:"name"`
+}
+
+// HasLabel reports whether the issue carries label, ignoring case as GitHub
+// does.
+func (i Issue) HasLabel(label string) bool {
+	for _, l := range i.Labels {
+		if strings.EqualFold(l.Name, label) {
+			return true
+		}
+	}
+	return false
+}
+
+// Client talks to the GitHub REST API.
+type Client struct {
+	apiURL string
+	token  string
+	http   *http.Client
+}
+
+// NewClient creates a client for the API at apiURL (DefaultAPIURL if empty)
+// authenticating with token, a personal access or app installation token
+// allowed to read and write issues.
+func NewClient(apiURL, token string) *Client {
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	return &Client{
+		apiURL: strings.TrimRight(apiURL, "/"),
+		token:  token,
+		http:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// ListIssues returns the open issues of repo (owner/name) labelled label,
+// leaving out pull requests.
+func (c *Client) ListIssues(ctx context.Context, repo, label string) ([]Issue, error) {
+	var issues []Issue
+	for page := 1; ; page++ {
+		path := fmt.Sprintf("/repos/%s/issues?state=open&labels=%s&per_page=%d&page=%d",
+			repo, url.QueryEscape(label), issuesPageSize, page)
+		var batch []Issue
+		if err := c.do(ctx, http.MethodGet, path, nil, &batch); err != nil {
+			return nil, err
+		}
+		for _, issue := range batch {
+			if issue.PullRequest == nil {
+				issues = append(issues, issue)
+			}
+		}
+		if len(batch) < issuesPageSize {
+			return issues, nil
+		}
+	}
+}
+
+// Comment adds a comment to issue number of repo.
+func (c *Client) Comment(ctx context.Context, repo string, number int64, body string) error {
+	return c.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/issues/%d/comments", repo, number),
+		map[string]string{"body": body}, nil)
+}
+
+// CloseIssue closes issue number of repo as completed.
+func (c *Client) CloseIssue(ctx context.Context, repo string, number int64) error {
+	return c.do(ctx, http.MethodPatch, fmt.Sprintf("/repos/%s/issues/%d", repo, number),
+		map[string]string{"state": "closed", "state_reason": "completed"}, nil)
+}
+
+// do sends a request to path with body as JSON, if any, and decodes the
+// response into out, if any.
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.apiURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("github %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("github %s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
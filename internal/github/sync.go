@@ -0,0 +1,320 @@
+package github
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/intake"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+	ws "github.com/abelkuruvilla/claw-agent-mission-control/internal/websocket"
+)
+
+// marker tags the comments Mission Control posts, so they are not copied
+// back to the task when their webhook arrives.
+const marker = "<!-- mission-control -->"
+
+// commitEventsLimit bounds the events searched for a task's commits.
+const commitEventsLimit = 500
+
+// SyncedIssue is an issue a sync made a task of.
+type SyncedIssue struct {
+	IssueNumber int64  `json:"issue_number"`
+	TaskID      string `json:"task_id"`
+}
+
+// Syncer keeps GitHub issues and their tasks in step.
+type Syncer struct {
+	client *Client
+	tasks  intake.Creator
+	store  *store.Store
+	hub    *ws.Hub
+}
+
+// NewSyncer creates a syncer that creates the tasks of labelled issues
+// through tasks, as the API does.
+func NewSyncer(client *Client, tasks intake.Creator, st *store.Store, hub *ws.Hub) *Syncer {
+	return &Syncer{client: client, tasks: tasks, store: st, hub: hub}
+}
+
+// SyncProject creates a task for each open issue of the project's
+// repository carrying its label that has none yet, for issues opened before
+// the webhook was set up or while deliveries failed.
+func (s *Syncer) SyncProject(ctx context.Context, project db.Project) ([]SyncedIssue, error) {
+	created := []SyncedIssue{}
+	repo, label := project.GithubRepo.String, project.GithubLabel.String
+	issues, err := s.client.ListIssues(ctx, repo, label)
+	if err != nil {
+		return created, err
+	}
+	for _, issue := range issues {
+		taskID, ok, err := s.createTask(ctx, project, issue)
+		if err != nil {
+			return created, fmt.Errorf("sync %s#%d: %w", repo, issue.Number, err)
+		}
+		if ok {
+			created = append(created, SyncedIssue{IssueNumber: issue.Number, TaskID: taskID})
+		}
+	}
+	return created, nil
+}
+
+// HandleIssues applies an issues webhook: labelled issues opened (or
+// labelled later) in a synced repository become tasks, and edits, closes
+// and reopens of linked issues update their tasks.
+func (s *Syncer) HandleIssues(ctx context.Context, ev IssuesEvent) error {
+	repo := ev.Repository.FullName
+	if ev.Issue.PullRequest != nil {
+		return nil
+	}
+	link, err := s.store.GetGitHubIssueLinkByIssue(ctx, repo, ev.Issue.Number)
+	if errors.Is(err, sql.ErrNoRows) {
+		if ev.Action != "opened" && ev.Action != "labeled" && ev.Action != "reopened" {
+			return nil
+		}
+		return s.createForRepo(ctx, repo, ev.Issue)
+	}
+	if err != nil {
+		return err
+	}
+
+	task, err := s.store.GetTask(ctx, link.TaskID)
+	if err != nil {
+		return err
+	}
+	switch ev.Action {
+	case "edited":
+		return s.store.UpdateTaskDetails(ctx, task.ID, ev.Issue.Title, description(repo, ev.Issue), task.Priority.Int64)
+	case "closed":
+		if err := s.store.SetGitHubIssueLinkState(ctx, task.ID, "closed"); err != nil {
+			return err
+		}
+		if finished(task.Status.String) {
+			return nil
+		}
+		status := "done"
+		if ev.Issue.StateReason == "not_planned" {
+			status = "failed"
+		}
+		return s.setStatus(ctx, task, status, fmt.Sprintf("GitHub issue %s#%d was closed", repo, ev.Issue.Number))
+	case "reopened":
+		if err := s.store.SetGitHubIssueLinkState(ctx, task.ID, "open"); err != nil {
+			return err
+		}
+		if !finished(task.Status.String) {
+			return nil
+		}
+		return s.setStatus(ctx, task, "backlog", fmt.Sprintf("GitHub issue %s#%d was reopened", repo, ev.Issue.Number))
+	}
+	return nil
+}
+
+// HandleIssueComment copies new comments on linked issues to their tasks.
+func (s *Syncer) HandleIssueComment(ctx context.Context, ev IssueCommentEvent) error {
+	if ev.Action != "created" || strings.Contains(ev.Comment.Body, marker) {
+		return nil
+	}
+	link, err := s.store.GetGitHubIssueLinkByIssue(ctx, ev.Repository.FullName, ev.Issue.Number)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	comment, err := s.store.CreateComment(ctx, db.CreateCommentParams{
+		TaskID:  link.TaskID,
+		Author:  "github:" + ev.Comment.User.Login,
+		Content: ev.Comment.Body,
+	})
+	if err != nil {
+		return err
+	}
+	if s.hub != nil {
+		content := comment.Content
+		if comment.Preview.Valid {
+			content = comment.Preview.String
+		}
+		s.hub.BroadcastComment(comment.TaskID, comment.ID, comment.Author, content, comment.Preview.Valid)
+	}
+	return nil
+}
+
+// CloseTaskIssue closes the issue of a task that is done, if it has one
+// still open, with a comment linking the commits of the work.
+func (s *Syncer) CloseTaskIssue(ctx context.Context, task db.Task) error {
+	link, err := s.store.GetGitHubIssueLinkByTask(ctx, task.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if link.State == "closed" {
+		return nil
+	}
+
+	if err := s.client.Comment(ctx, link.Repo, link.IssueNumber, s.closingComment(ctx, task)); err != nil {
+		return err
+	}
+	if err := s.client.CloseIssue(ctx, link.Repo, link.IssueNumber); err != nil {
+		return err
+	}
+	if err := s.store.SetGitHubIssueLinkState(ctx, task.ID, "closed"); err != nil {
+		return err
+	}
+	s.logEvent(ctx, task.ID, "github_issue_closed", fmt.Sprintf("Closed GitHub issue %s#%d", link.Repo, link.IssueNumber))
+	return nil
+}
+
+// createForRepo creates the task of an issue for the first project synced
+// with repo whose label it carries.
+func (s *Syncer) createForRepo(ctx context.Context, repo string, issue Issue) error {
+	projects, err := s.store.ListProjectsByGitHubRepo(ctx, repo)
+	if err != nil {
+		return err
+	}
+	for _, project := range projects {
+		if issue.HasLabel(project.GithubLabel.String) {
+			_, _, err := s.createTask(ctx, project, issue)
+			return err
+		}
+	}
+	return nil
+}
+
+// createTask creates the task of an issue in project and links them,
+// unless the issue has a task already. It reports whether it created one.
+func (s *Syncer) createTask(ctx context.Context, project db.Project, issue Issue) (string, bool, error) {
+	repo := project.GithubRepo.String
+	if link, err := s.store.GetGitHubIssueLinkByIssue(ctx, repo, issue.Number); err == nil {
+		return link.TaskID, false, nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return "", false, err
+	}
+
+	task, err := s.tasks.CreateLinkedTask(ctx, intake.Task{
+		Title:       issue.Title,
+		Description: description(repo, issue),
+		ProjectID:   project.ID,
+		Source:      fmt.Sprintf("GitHub issue %s#%d", repo, issue.Number),
+	}, func(tx *store.Store, task db.Task) error {
+		_, err := tx.CreateGitHubIssueLink(ctx, db.CreateGithubIssueLinkParams{
+			TaskID:      task.ID,
+			ProjectID:   project.ID,
+			Repo:        repo,
+			IssueNumber: issue.Number,
+			State:       "open",
+		})
+		return err
+	})
+	if err != nil {
+		return "", false, err
+	}
+	return task.ID, true, nil
+}
+
+func (s *Syncer) setStatus(ctx context.Context, task db.Task, status, reason string) error {
+	if err := s.store.UpdateTaskStatus(ctx, task.ID, status); err != nil {
+		return err
+	}
+	s.logEvent(ctx, task.ID, "github_status_pulled", fmt.Sprintf("%s; task is now %s", reason, status))
+	if s.hub != nil {
+		s.hub.BroadcastTaskStatus(task.ID, status, 0)
+	}
+	return nil
+}
+
+// closingComment tells an issue its task is done, with the work's branch
+// and the commits reported for it (passed stories, addressed change
+// requests).
+func (s *Syncer) closingComment(ctx context.Context, task db.Task) string {
+	var b strings.Builder
+	name := task.ID
+	if task.ShortID.Valid && task.ShortID.String != "" {
+		name = task.ShortID.String
+	}
+	fmt.Fprintf(&b, "Completed in Mission Control (task %s).\n", name)
+	if task.GitBranch.Valid && task.GitBranch.String != "" {
+		fmt.Fprintf(&b, "\nBranch: `%s`\n", task.GitBranch.String)
+	}
+	if commits := s.commits(ctx, task.ID); len(commits) > 0 {
+		// GitHub links commit SHAs of the issue's repository by itself
+		b.WriteString("\nCommits:\n")
+		for _, sha := range commits {
+			fmt.Fprintf(&b, "- %s\n", sha)
+		}
+	}
+	b.WriteString("\n" + marker)
+	return b.String()
+}
+
+// commits returns the commits reported for a task, oldest first and without
+// duplicates.
+func (s *Syncer) commits(ctx context.Context, taskID string) []string {
+	var commits []string
+	seen := map[string]bool{}
+	add := func(sha string) {
+		if sha != "" && !seen[sha] {
+			seen[sha] = true
+			commits = append(commits, sha)
+		}
+	}
+
+	if events, err := s.store.ListEventsByTask(ctx, taskID, commitEventsLimit); err == nil {
+		// Events come newest first
+		for i := len(events) - 1; i >= 0; i-- {
+			if events[i].Type != "story_passed" || !events[i].Details.Valid {
+				continue
+			}
+			var details struct {
+				CommitSHA string `json:"commit_sha"`
+			}
+			if json.Unmarshal([]byte(events[i].Details.String), &details) == nil {
+				add(details.CommitSHA)
+			}
+		}
+	}
+	if crs, err := s.store.ListChangeRequestsByTask(ctx, taskID); err == nil {
+		for _, cr := range crs {
+			add(cr.CommitSha.String)
+		}
+	}
+	return commits
+}
+
+func (s *Syncer) logEvent(ctx context.Context, taskID, eventType, message string) {
+	event, err := s.store.CreateEvent(ctx, db.CreateEventParams{
+		TaskID:  sql.NullString{String: taskID, Valid: true},
+		Type:    eventType,
+		Message: message,
+	})
+	if err != nil {
+		log.Printf("[GitHubSync] Failed to record %s event for task %s: %v", eventType, taskID, err)
+		return
+	}
+	if s.hub != nil {
+		s.hub.BroadcastEvent(event)
+	}
+}
+
+// description is a task description for an issue: its body, then where it
+// came from.
+func description(repo string, issue Issue) string {
+	source := fmt.Sprintf("From GitHub issue %s#%d", repo, issue.Number)
+	if issue.HTMLURL != "" {
+		source += ": " + issue.HTMLURL
+	}
+	if body := strings.TrimSpace(issue.Body); body != "" {
+		return body + "\n\n" + source
+	}
+	return source
+}
+
+func finished(status string) bool {
+	return status == "done" || status == "failed"
+}
@@ -0,0 +1,61 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Webhook event names, from the X-GitHub-Event header.
+const (
+	EventPing         = "ping"
+	EventIssues       = "issues"
+	EventIssueComment = "issue_comment"
+)
+
+// Repository is the repository a webhook event happened in.
+type Repository struct {
+	FullName string `json:"full_name"` // owner/name
+}
+
+// User is a GitHub account.
+type User struct {
+	Login string `json:"login"`
+}
+
+// IssuesEvent is the payload of an issues webhook: an issue was opened,
+// edited, labeled, closed, reopened, ...
+type IssuesEvent struct {
+	Action     string     `json:"action"`
+	Issue      Issue      `json:"issue"`
+	Label      *Label     `json:"label"` // the label added or removed, for labeled / unlabeled
+	Repository Repository `json:"repository"`
+}
+
+// IssueCommentEvent is the payload of an issue_comment webhook.
+type IssueCommentEvent struct {
+	Action  string `json:"action"`
+	Issue   Issue  `json:"issue"`
+	Comment struct {
+		Body string `json:"body"`
+		User User   `json:"user"`
+	} `json:"comment"`
+	Repository Repository `json:"repository"`
+}
+
+// VerifySignature reports whether signature, the X-Hub-Signature-256 header
+// of a webhook delivery, signs body with secret.
+func VerifySignature(secret string, body []byte, signature string) bool {
+	sig, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
@@ -0,0 +1,156 @@
+// Package grpcapi serves the gRPC API of proto/missioncontrol/v1, for
+// integrations and agents that would rather not pay for JSON over HTTP/1.
+// It shares the store and the task logic of the REST handlers. The messages
+// and service stubs are generated from the .proto by protoc-gen-go and
+// protoc-gen-go-grpc; run make proto after changing it.
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/api/handlers"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+)
+
+// ServiceName is the full name of the gRPC service.
+const ServiceName = "missioncontrol.v1.MissionControl"
+
+// TaskService is the task logic of the API that the task handler implements,
+// so calls have the same effect as the matching REST requests.
+type TaskService interface {
+	CreateTask(ctx context.Context, req handlers.CreateTaskRequest) (db.Task, error)
+	SetTaskStatus(ctx context.Context, id, status, errMsg string) (db.Task, error)
+}
+
+// ProgressService is the progress reporting logic of the API that the
+// reporting handler implements.
+type ProgressService interface {
+	SetProgress(ctx context.Context, taskID string, progress *int) (db.Task, error)
+	AppendProgress(ctx context.Context, taskID, author, content string) error
+}
+
+// Server is the gRPC server.
+type Server struct {
+	UnimplementedMissionControlServer
+
+	tasks    TaskService
+	progress ProgressService
+	store    *store.Store
+	grpc     *grpc.Server
+	done     chan struct{} // closed on shutdown, ending event streams
+}
+
+func NewServer(tasks TaskService, progress ProgressService, st *store.Store) *Server {
+	s := &Server{tasks: tasks, progress: progress, store: st, done: make(chan struct{})}
+	s.grpc = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unaryStatus),
+		grpc.ChainStreamInterceptor(streamStatus),
+	)
+	RegisterMissionControlServer(s.grpc, s)
+	return s
+}
+
+// Start serves gRPC on addr until Shutdown is called.
+func (s *Server) Start(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ln)
+}
+
+// Serve serves gRPC on ln until Shutdown is called.
+func (s *Server) Serve(ln net.Listener) error {
+	err := s.grpc.Serve(ln)
+	if errors.Is(err, grpc.ErrServerStopped) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown ends event streams and stops serving, letting unary calls in
+// progress finish until ctx is done.
+func (s *Server) Shutdown(ctx context.Context) error {
+	select {
+	case <-s.done:
+	default:
+		close(s.done)
+	}
+	stopped := make(chan struct{})
+	go func() {
+		s.grpc.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.grpc.Stop()
+		return ctx.Err()
+	}
+}
+
+// unaryStatus and streamStatus give the errors of calls their gRPC status.
+func unaryStatus(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+	return resp, toStatus(info.FullMethod, err)
+}
+
+func streamStatus(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return toStatus(info.FullMethod, handler(srv, ss))
+}
+
+// toStatus returns err as a gRPC status error, mapping the HTTP status of
+// a task service error to the nearest code.
+func toStatus(method string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		return status.Error(httpCode(he.Code), fmt.Sprint(he.Message))
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	}
+	log.Printf("[gRPC] %s failed: %v", method, err)
+	return status.Error(codes.Internal, err.Error())
+}
+
+// httpCode returns the gRPC code nearest an HTTP status.
+func httpCode(code int) codes.Code {
+	switch code {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict, http.StatusUnprocessableEntity:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	}
+	return codes.Internal
+}
@@ -0,0 +1,203 @@
+package grpcapi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	_ "github.com/mattn/go-sqlite3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/api/handlers"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+)
+
+var dbCounter int
+
+// refusingTasks is a task service that refuses every task as the REST
+// handler refuses one it won't create.
+type refusingTasks struct{}
+
+func (refusingTasks) CreateTask(ctx context.Context, req handlers.CreateTaskRequest) (db.Task, error) {
+	return db.Task{}, echo.NewHTTPError(http.StatusUnprocessableEntity, "rejected by script")
+}
+
+func (refusingTasks) SetTaskStatus(ctx context.Context, id, status, errMsg string) (db.Task, error) {
+	return db.Task{}, echo.NewHTTPError(http.StatusConflict, "task is locked")
+}
+
+// startServer serves gRPC on a loopback port over a fresh in-memory
+// database and returns a client of it, the server and its store.
+func startServer(t *testing.T) (MissionControlClient, *Server, *store.Store) {
+	t.Helper()
+	dbCounter++
+	sqlDB, err := sql.Open("sqlite3", fmt.Sprintf("file:grpcapi%d?mode=memory&cache=shared&_foreign_keys=on", dbCounter))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.Migrate(sqlDB); err != nil {
+		t.Fatal(err)
+	}
+	st := store.New(sqlDB)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(refusingTasks{}, nil, st)
+	go s.Serve(ln)
+	conn, err := grpc.NewClient(ln.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		s.Shutdown(ctx)
+		sqlDB.Close()
+	})
+	return NewMissionControlClient(conn), s, st
+}
+
+func createAgent(t *testing.T, st *store.Store, id string) {
+	t.Helper()
+	if _, err := st.CreateAgent(context.Background(), db.CreateAgentParams{
+		ID: id, Name: "Dev", Status: sql.NullString{String: "idle", Valid: true},
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetAgent(t *testing.T) {
+	client, _, st := startServer(t)
+	createAgent(t, st, "dev")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	agent, err := client.GetAgent(ctx, &GetAgentRequest{Id: "dev"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if agent.Id != "dev" || agent.Name != "Dev" || agent.Status != "idle" || agent.CreatedAt == "" {
+		t.Errorf("agent %v", agent)
+	}
+	list, err := client.ListAgents(ctx, &ListAgentsRequest{})
+	if err != nil || len(list.Agents) != 1 {
+		t.Errorf("ListAgents = %v, %v", list, err)
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	client, _, _ := startServer(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		call func() error
+		code codes.Code
+		msg  string
+	}{
+		{"missing task", func() error {
+			_, err := client.GetTask(ctx, &GetTaskRequest{Task: "missing"})
+			return err
+		}, codes.NotFound, "Task not found"},
+		{"no title", func() error {
+			_, err := client.CreateTask(ctx, &CreateTaskRequest{})
+			return err
+		}, codes.InvalidArgument, "title is required"},
+		{"bad limit", func() error {
+			_, err := client.ListTasks(ctx, &ListTasksRequest{Limit: 501})
+			return err
+		}, codes.InvalidArgument, "limit must be between 1 and 500"},
+		// The REST error of the task service, with its nearest code
+		{"refused task", func() error {
+			_, err := client.CreateTask(ctx, &CreateTaskRequest{Title: "Deploy"})
+			return err
+		}, codes.FailedPrecondition, "rejected by script"},
+	}
+	for _, tt := range tests {
+		st, _ := status.FromError(tt.call())
+		if st.Code() != tt.code || st.Message() != tt.msg {
+			t.Errorf("%s: %v %q, want %v %q", tt.name, st.Code(), st.Message(), tt.code, tt.msg)
+		}
+	}
+}
+
+func TestToStatus(t *testing.T) {
+	for _, tt := range []struct {
+		err  error
+		code codes.Code
+	}{
+		{echo.NewHTTPError(http.StatusConflict, "busy"), codes.FailedPrecondition},
+		{echo.NewHTTPError(http.StatusTooManyRequests, "slow down"), codes.ResourceExhausted},
+		{echo.NewHTTPError(http.StatusTeapot, "?"), codes.Internal},
+		{fmt.Errorf("waiting: %w", context.DeadlineExceeded), codes.DeadlineExceeded},
+		{fmt.Errorf("database is locked"), codes.Internal},
+		{status.Error(codes.NotFound, "gone"), codes.NotFound},
+	} {
+		if got := status.Code(toStatus("/m", tt.err)); got != tt.code {
+			t.Errorf("toStatus(%v) = %v, want %v", tt.err, got, tt.code)
+		}
+	}
+	if toStatus("/m", nil) != nil {
+		t.Error("toStatus(nil) is an error")
+	}
+}
+
+func TestStreamEvents(t *testing.T) {
+	client, s, st := startServer(t)
+	createAgent(t, st, "dev")
+	createAgent(t, st, "ops")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	record := func(agentID, message string) {
+		t.Helper()
+		if _, err := st.CreateEvent(ctx, db.CreateEventParams{
+			AgentID: sql.NullString{String: agentID, Valid: true}, Type: "note", Message: message,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	record("dev", "before")
+	before, err := st.GetLatestEventSeq(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record("dev", "missed while away")
+	record("ops", "someone else's")
+
+	// Resuming after a seq sends what was missed, of the agent asked for
+	stream, err := client.StreamEvents(ctx, &StreamEventsRequest{AgentId: "dev", AfterSeq: before})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Message != "missed while away" || e.Seq <= before {
+		t.Errorf("first event %v", e)
+	}
+	record("dev", "new")
+	if e, err = stream.Recv(); err != nil || e.Message != "new" {
+		t.Fatalf("second event %v, %v", e, err)
+	}
+
+	// Shutting down ends the stream
+	go s.Shutdown(ctx)
+	if _, err := stream.Recv(); status.Code(err) != codes.Unavailable {
+		t.Errorf("stream ended with %v, want UNAVAILABLE", err)
+	}
+}
@@ -0,0 +1,322 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/api/handlers"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/taskrefs"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+
+	// eventPollInterval is how often event streams look for new events.
+	eventPollInterval = time.Second
+	// eventBatchSize bounds the events a stream reads at once.
+	eventBatchSize = 100
+)
+
+func (s *Server) GetTask(ctx context.Context, req *GetTaskRequest) (*Task, error) {
+	task, err := s.findTask(ctx, req.Task)
+	if err != nil {
+		return nil, err
+	}
+	return toTask(task), nil
+}
+
+func (s *Server) ListTasks(ctx context.Context, req *ListTasksRequest) (*ListTasksResponse, error) {
+	limit, err := listLimit(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	var tasks []db.Task
+	if req.Status != "" {
+		tasks, err = s.store.ListTasksByStatus(ctx, req.Status)
+	} else {
+		tasks, err = s.store.ListTasks(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	resp := &ListTasksResponse{}
+	for _, t := range tasks {
+		if req.AgentId != "" && t.AgentID.String != req.AgentId {
+			continue
+		}
+		if req.ProjectId != "" && t.ProjectID.String != req.ProjectId {
+			continue
+		}
+		resp.Tasks = append(resp.Tasks, toTask(t))
+		if len(resp.Tasks) == limit {
+			break
+		}
+	}
+	return resp, nil
+}
+
+func (s *Server) CreateTask(ctx context.Context, req *CreateTaskRequest) (*Task, error) {
+	if strings.TrimSpace(req.Title) == "" {
+		return nil, status.Error(codes.InvalidArgument, "title is required")
+	}
+	task, err := s.tasks.CreateTask(ctx, handlers.CreateTaskRequest{
+		Title:        req.Title,
+		Description:  req.Description,
+		AgentID:      req.AgentId,
+		ProjectID:    req.ProjectId,
+		ParentTaskID: req.ParentTaskId,
+		Priority:     int(req.Priority),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toTask(task), nil
+}
+
+func (s *Server) UpdateTaskStatus(ctx context.Context, req *UpdateTaskStatusRequest) (*Task, error) {
+	if req.Status == "" {
+		return nil, status.Error(codes.InvalidArgument, "status is required")
+	}
+	task, err := s.findTask(ctx, req.Task)
+	if err != nil {
+		return nil, err
+	}
+	task, err = s.tasks.SetTaskStatus(ctx, task.ID, req.Status, req.Error)
+	if err != nil {
+		return nil, err
+	}
+	return toTask(task), nil
+}
+
+func (s *Server) ReportProgress(ctx context.Context, req *ReportProgressRequest) (*Task, error) {
+	task, err := s.findTask(ctx, req.Task)
+	if err != nil {
+		return nil, err
+	}
+	var progress *int
+	if req.Progress != nil {
+		p := int(*req.Progress)
+		progress = &p
+	}
+	task, err = s.progress.SetProgress(ctx, task.ID, progress)
+	if err != nil {
+		return nil, err
+	}
+	return toTask(task), nil
+}
+
+func (s *Server) AppendProgress(ctx context.Context, req *AppendProgressRequest) (*AppendProgressResponse, error) {
+	if strings.TrimSpace(req.Content) == "" {
+		return nil, status.Error(codes.InvalidArgument, "content is required")
+	}
+	task, err := s.findTask(ctx, req.Task)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.progress.AppendProgress(ctx, task.ID, req.Author, req.Content); err != nil {
+		return nil, err
+	}
+	return &AppendProgressResponse{}, nil
+}
+
+func (s *Server) GetAgent(ctx context.Context, req *GetAgentRequest) (*Agent, error) {
+	agent, err := s.store.GetAgent(ctx, req.Id)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "Agent not found")
+	}
+	return toAgent(agent), nil
+}
+
+func (s *Server) ListAgents(ctx context.Context, req *ListAgentsRequest) (*ListAgentsResponse, error) {
+	agents, err := s.store.ListAgents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ListAgentsResponse{}
+	for _, a := range agents {
+		resp.Agents = append(resp.Agents, toAgent(a))
+	}
+	return resp, nil
+}
+
+// ListEvents lists events newest first, of a task or else of an agent, as
+// GET /events does.
+func (s *Server) ListEvents(ctx context.Context, req *ListEventsRequest) (*ListEventsResponse, error) {
+	limit, err := listLimit(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	var events []db.Event
+	switch {
+	case req.TaskId != "":
+		var task db.Task
+		if task, err = s.findTask(ctx, req.TaskId); err != nil {
+			return nil, err
+		}
+		events, err = s.store.ListEventsByTask(ctx, task.ID, int64(limit))
+	case req.AgentId != "":
+		events, err = s.store.ListEventsByAgent(ctx, req.AgentId, int64(limit))
+	default:
+		events, err = s.store.ListEvents(ctx, int64(limit))
+	}
+	if err != nil {
+		return nil, err
+	}
+	resp := &ListEventsResponse{}
+	for _, e := range events {
+		resp.Events = append(resp.Events, toEvent(e, 0))
+	}
+	return resp, nil
+}
+
+// StreamEvents sends events recorded after req.AfterSeq, or from now on, as
+// they are recorded, until the call is cancelled or the server shuts down.
+func (s *Server) StreamEvents(req *StreamEventsRequest, stream MissionControl_StreamEventsServer) error {
+	ctx := stream.Context()
+	taskID := req.TaskId
+	if taskID != "" {
+		task, err := s.findTask(ctx, taskID)
+		if err != nil {
+			return err
+		}
+		taskID = task.ID
+	}
+	seq := req.AfterSeq
+	if seq <= 0 {
+		latest, err := s.store.GetLatestEventSeq(ctx)
+		if err != nil {
+			return err
+		}
+		seq = latest
+	}
+
+	ticker := time.NewTicker(eventPollInterval)
+	defer ticker.Stop()
+	for {
+		rows, err := s.store.ListEventsAfter(ctx, seq, eventBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			seq = row.Seq
+			if taskID != "" && row.TaskID.String != taskID {
+				continue
+			}
+			if req.AgentId != "" && row.AgentID.String != req.AgentId {
+				continue
+			}
+			event := db.Event{ID: row.ID, TaskID: row.TaskID, AgentID: row.AgentID, Type: row.Type,
+				Message: row.Message, Details: row.Details, CreatedAt: row.CreatedAt}
+			if err := stream.Send(toEvent(event, row.Seq)); err != nil {
+				return err
+			}
+		}
+		if len(rows) == eventBatchSize {
+			continue
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.done:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		}
+	}
+}
+
+// findTask returns the task ref names, by ID or short ID.
+func (s *Server) findTask(ctx context.Context, ref string) (db.Task, error) {
+	if ref == "" {
+		return db.Task{}, status.Error(codes.InvalidArgument, "task is required")
+	}
+	var task db.Task
+	var err error
+	if taskrefs.IsShortID(ref) {
+		task, err = s.store.GetTaskByShortID(ctx, ref)
+	} else {
+		task, err = s.store.GetTask(ctx, ref)
+	}
+	if err != nil {
+		return db.Task{}, status.Error(codes.NotFound, "Task not found")
+	}
+	return task, nil
+}
+
+func listLimit(limit int32) (int, error) {
+	if limit == 0 {
+		return defaultListLimit, nil
+	}
+	if limit < 0 || limit > maxListLimit {
+		return 0, status.Error(codes.InvalidArgument, fmt.Sprintf("limit must be between 1 and %d", maxListLimit))
+	}
+	return int(limit), nil
+}
+
+func toTask(t db.Task) *Task {
+	r := handlers.ToTaskResponse(t)
+	task := &Task{
+		Id:            r.ID,
+		ShortId:       deref(r.ShortID),
+		Title:         r.Title,
+		Description:   deref(r.Description),
+		AgentId:       deref(r.AgentID),
+		ProjectId:     deref(r.ProjectID),
+		ParentTaskId:  deref(r.ParentTaskID),
+		Status:        r.Status,
+		Priority:      int32(r.Priority),
+		FailureReason: deref(r.FailureReason),
+		CreatedAt:     r.CreatedAt,
+		UpdatedAt:     r.UpdatedAt,
+		StartedAt:     deref(r.StartedAt),
+		CompletedAt:   deref(r.CompletedAt),
+	}
+	if r.Progress != nil {
+		p := int32(*r.Progress)
+		task.Progress = &p
+	}
+	return task
+}
+
+func toAgent(a db.Agent) *Agent {
+	r := handlers.ToAgentResponse(a)
+	return &Agent{
+		Id:            r.ID,
+		Name:          r.Name,
+		Description:   deref(r.Description),
+		Status:        r.Status,
+		Model:         deref(r.Model),
+		CurrentTaskId: deref(r.CurrentTaskID),
+		CreatedAt:     r.CreatedAt,
+		UpdatedAt:     r.UpdatedAt,
+	}
+}
+
+func toEvent(e db.Event, seq int64) *Event {
+	event := &Event{
+		Id:      e.ID,
+		TaskId:  e.TaskID.String,
+		AgentId: e.AgentID.String,
+		Type:    e.Type,
+		Message: e.Message,
+		Details: e.Details.String,
+		Seq:     seq,
+	}
+	if e.CreatedAt.Valid {
+		event.CreatedAt = e.CreatedAt.Time.UTC().Format(time.RFC3339)
+	}
+	return event
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,350 @@
+// Package hooks runs site-specific automation at points of a task's life,
+// so it needs no fork of the handlers: executables and HTTP endpoints listed
+// in the hooks file (HOOKS_FILE) are given the task as JSON, on stdin or as
+// the request body, and may answer with a veto of what is about to happen
+// or with changes to the task.
+//
+//	{"hooks": [
+//	  {"name": "license-check", "events": ["pre_dispatch"], "command": ["/opt/hooks/license-check"]},
+//	  {"name": "deploy", "events": ["post_complete"], "url": "https://ci.example.com/hooks/mc", "headers": {"Authorization": "Bearer ..."}, "timeout": "30s"}
+//	]}
+//
+// A hook answers with nothing to let things go ahead unchanged, or with
+//
+//	{"veto": true, "reason": "missing license header"}
+//	{"task": {"title": "...", "description": "...", "priority": 2}, "comment": "..."}
+//
+// A hook that fails (exits non-zero, answers with an HTTP error, times out or
+// writes something other than JSON) is logged and skipped, or vetoes if it is
+// fail_closed.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Events hooks run on.
+const (
+	PreDispatch  = "pre_dispatch"  // before a task is sent to its agent
+	PostComplete = "post_complete" // as a task is done, before it is saved
+	OnFail       = "on_fail"       // as a task fails, before it is saved
+)
+
+var events = map[string]bool{PreDispatch: true, PostComplete: true, OnFail: true}
+
+const (
+	defaultTimeout = 10 * time.Second
+	maxOutput      = 1 << 20 // bytes of a hook's answer read
+	// waitDelay is how long a command's output is waited for once it has
+	// exited or been killed, for processes it started that still hold it
+	waitDelay = time.Second
+)
+
+// Hook is an executable or HTTP endpoint run on events.
+type Hook struct {
+	Name       string            `json:"name"`
+	Events     []string          `json:"events"`
+	Command    []string          `json:"command,omitempty"` // program and arguments, run without a shell
+	URL        string            `json:"url,omitempty"`     // POSTed to instead
+	Headers    map[string]string `json:"headers,omitempty"` // sent to URL
+	Timeout    string            `json:"timeout,omitempty"` // default 10s
+	FailClosed bool              `json:"fail_closed,omitempty"`
+
+	timeout time.Duration
+}
+
+// File is the hooks file.
+type File struct {
+	Hooks []Hook `json:"hooks"`
+}
+
+// Payload is what a hook is given.
+type Payload struct {
+	Event  string      `json:"event"`
+	Task   interface{} `json:"task"`
+	Error  string      `json:"error,omitempty"` // on_fail: why the task failed
+	DryRun bool        `json:"dry_run,omitempty"`
+}
+
+// Changes are fields of the task a hook sets.
+type Changes struct {
+	Title       *string `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Priority    *int    `json:"priority,omitempty"`
+}
+
+// Empty reports whether no field is set.
+func (c Changes) Empty() bool {
+	return c.Title == nil && c.Description == nil && c.Priority == nil
+}
+
+// merge sets the fields other sets.
+func (c *Changes) merge(other Changes) {
+	if other.Title != nil {
+		c.Title = other.Title
+	}
+	if other.Description != nil {
+		c.Description = other.Description
+	}
+	if other.Priority != nil {
+		c.Priority = other.Priority
+	}
+}
+
+// Answer is what a hook answers with.
+type Answer struct {
+	Veto    bool     `json:"veto,omitempty"`
+	Reason  string   `json:"reason,omitempty"`
+	Task    *Changes `json:"task,omitempty"`
+	Comment string   `json:"comment,omitempty"` // added to the task
+}
+
+// Comment is a comment a hook adds to the task.
+type Comment struct {
+	Hook    string
+	Content string
+}
+
+// Result is what the hooks of an event decided, in order: the changes they
+// made, later hooks' over earlier ones', and the veto that stopped them.
+type Result struct {
+	Changes  Changes
+	Comments []Comment
+	Ran      []string // hooks that ran, by name
+	Vetoed   bool
+	VetoedBy string
+	Reason   string
+}
+
+// Load reads the hooks file at path.
+func Load(path string) (*Runner, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse reads a hooks file.
+func Parse(data []byte) (*Runner, error) {
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid hooks file: %w", err)
+	}
+	names := map[string]bool{}
+	for i := range f.Hooks {
+		h := &f.Hooks[i]
+		if strings.TrimSpace(h.Name) == "" {
+			return nil, fmt.Errorf("hook %d has no name", i)
+		}
+		if names[h.Name] {
+			return nil, fmt.Errorf("hook %s is listed twice", h.Name)
+		}
+		names[h.Name] = true
+		if (len(h.Command) == 0) == (h.URL == "") {
+			return nil, fmt.Errorf("hook %s needs either a command or a url", h.Name)
+		}
+		if len(h.Events) == 0 {
+			return nil, fmt.Errorf("hook %s has no events", h.Name)
+		}
+		for _, e := range h.Events {
+			if !events[e] {
+				return nil, fmt.Errorf("hook %s: unknown event %q", h.Name, e)
+			}
+		}
+		h.timeout = defaultTimeout
+		if h.Timeout != "" {
+			d, err := time.ParseDuration(h.Timeout)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("hook %s: invalid timeout %q", h.Name, h.Timeout)
+			}
+			h.timeout = d
+		}
+	}
+	return &Runner{hooks: f.Hooks, http: &http.Client{}}, nil
+}
+
+// Runner runs the hooks of a hooks file.
+type Runner struct {
+	hooks []Hook
+	http  *http.Client
+}
+
+// Hooks returns the hooks, in the order they run.
+func (r *Runner) Hooks() []Hook {
+	return r.hooks
+}
+
+// Has reports whether any hook runs on event.
+func (r *Runner) Has(event string) bool {
+	if r == nil {
+		return false
+	}
+	for _, h := range r.hooks {
+		if h.runsOn(event) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h Hook) runsOn(event string) bool {
+	for _, e := range h.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Run runs the hooks of p.Event in order, until one vetoes. Every hook is
+// given the task as it was before the hooks ran.
+func (r *Runner) Run(ctx context.Context, p Payload) Result {
+	var res Result
+	if r == nil {
+		return res
+	}
+	body, err := json.Marshal(p)
+	if err != nil {
+		log.Printf("[Hooks] Failed to encode %s payload: %v", p.Event, err)
+		return res
+	}
+	for _, h := range r.hooks {
+		if !h.runsOn(p.Event) {
+			continue
+		}
+		res.Ran = append(res.Ran, h.Name)
+		answer, err := r.run(ctx, h, p.Event, body)
+		if err != nil {
+			if !h.FailClosed {
+				log.Printf("[Hooks] Hook %s failed on %s, ignored: %v", h.Name, p.Event, err)
+				continue
+			}
+			log.Printf("[Hooks] Hook %s failed on %s: %v", h.Name, p.Event, err)
+			answer = Answer{Veto: true, Reason: "hook failed: " + err.Error()}
+		}
+		if answer.Comment != "" {
+			res.Comments = append(res.Comments, Comment{Hook: h.Name, Content: answer.Comment})
+		}
+		if answer.Veto {
+			res.Vetoed, res.VetoedBy, res.Reason = true, h.Name, answer.Reason
+			return res
+		}
+		if answer.Task != nil {
+			res.Changes.merge(*answer.Task)
+		}
+	}
+	return res
+}
+
+// run runs one hook and reads its answer.
+func (r *Runner) run(ctx context.Context, h Hook, event string, body []byte) (Answer, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	var out []byte
+	var err error
+	if h.URL != "" {
+		out, err = r.post(ctx, h, body)
+	} else {
+		out, err = runCommand(ctx, h, event, body)
+	}
+	if err != nil {
+		return Answer{}, err
+	}
+	var answer Answer
+	if len(bytes.TrimSpace(out)) == 0 {
+		return answer, nil
+	}
+	if err := json.Unmarshal(out, &answer); err != nil {
+		return Answer{}, fmt.Errorf("invalid answer: %w", err)
+	}
+	return answer, nil
+}
+
+func runCommand(ctx context.Context, h Hook, event string, body []byte) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, h.Command[0], h.Command[1:]...)
+	// A timeout kills what the hook started too, and a process it leaves
+	// in the background (curl ... &) cannot hold its answer up
+	killProcessGroup(cmd)
+	cmd.WaitDelay = waitDelay
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Env = append(os.Environ(), "MC_HOOK_EVENT="+event, "MC_HOOK_NAME="+h.Name)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &limitedWriter{w: &stdout, n: maxOutput}
+	cmd.Stderr = &limitedWriter{w: &stderr, n: maxOutput}
+	err := cmd.Run()
+	if errors.Is(err, exec.ErrWaitDelay) {
+		// The hook exited successfully; what it left running kept its
+		// output open
+		err = nil
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("timed out after %s", h.timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+func (r *Runner) post(ctx context.Context, h Hook, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range h.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := r.http.Do(req)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("timed out after %s", h.timeout)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	out, err := io.ReadAll(io.LimitReader(resp.Body, maxOutput))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%s returned %s", h.URL, resp.Status)
+	}
+	return out, nil
+}
+
+// limitedWriter keeps the first n bytes written to it and drops the rest.
+type limitedWriter struct {
+	w io.Writer
+	n int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if l.n > 0 {
+		keep := p
+		if len(keep) > l.n {
+			keep = keep[:l.n]
+		}
+		l.n -= len(keep)
+		if _, err := l.w.Write(keep); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
@@ -0,0 +1,206 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// shellHook is a hook running script with sh on events.
+func shellHook(name, script string, events ...string) Hook {
+	return Hook{Name: name, Events: events, Command: []string{"sh", "-c", script}}
+}
+
+// runner builds a Runner from hooks as a hooks file lists them.
+func runner(t *testing.T, hooks ...Hook) *Runner {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no sh to run hooks with")
+	}
+	data, err := json.Marshal(File{Hooks: hooks})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+var task = map[string]any{"id": "t1", "title": "Ship it"}
+
+func TestParseRejects(t *testing.T) {
+	for file, want := range map[string]string{
+		`{"hooks": [{"events": ["pre_dispatch"], "command": ["x"]}]}`:                                                                "hook 0 has no name",
+		`{"hooks": [{"name": "a", "events": ["pre_dispatch"], "command": ["x"]}, {"name": "a", "events": ["on_fail"], "url": "u"}]}`: "hook a is listed twice",
+		`{"hooks": [{"name": "a", "events": ["pre_dispatch"]}]}`:                                                                     "hook a needs either a command or a url",
+		`{"hooks": [{"name": "a", "events": ["pre_dispatch"], "command": ["x"], "url": "u"}]}`:                                       "hook a needs either a command or a url",
+		`{"hooks": [{"name": "a", "command": ["x"]}]}`:                                                                               "hook a has no events",
+		`{"hooks": [{"name": "a", "events": ["on_create"], "command": ["x"]}]}`:                                                      `hook a: unknown event "on_create"`,
+		`{"hooks": [{"name": "a", "events": ["on_fail"], "command": ["x"], "timeout": "-1s"}]}`:                                      `hook a: invalid timeout "-1s"`,
+	} {
+		if _, err := Parse([]byte(file)); err == nil || err.Error() != want {
+			t.Errorf("Parse(%s) = %v, want %q", file, err, want)
+		}
+	}
+}
+
+func TestRunGivesTaskAndEvent(t *testing.T) {
+	// The hook comments only on the payload it expects
+	r := runner(t, shellHook("echo", `
+		case "$(cat)" in
+		'{"event":"on_fail","task":{"id":"t1","title":"Ship it"},"error":"tests failed"}')
+			printf '{"comment": "%s from %s"}' "$MC_HOOK_EVENT" "$MC_HOOK_NAME";;
+		esac`, OnFail))
+
+	res := r.Run(context.Background(), Payload{Event: OnFail, Task: task, Error: "tests failed"})
+	if len(res.Comments) != 1 || res.Comments[0].Content != "on_fail from echo" || res.Comments[0].Hook != "echo" {
+		t.Errorf("comments %+v, want the hook's on the payload", res.Comments)
+	}
+}
+
+func TestRunMergesChangesUntilVeto(t *testing.T) {
+	r := runner(t,
+		shellHook("retitle", `echo '{"task": {"title": "First", "priority": 2}}'`, PreDispatch),
+		shellHook("other-event", `echo '{"veto": true}'`, OnFail),
+		shellHook("quiet", `cat > /dev/null`, PreDispatch),
+		shellHook("describe", `echo '{"task": {"title": "Second", "description": "Why"}, "comment": "noted"}'`, PreDispatch),
+	)
+	res := r.Run(context.Background(), Payload{Event: PreDispatch, Task: task})
+	if res.Vetoed {
+		t.Fatalf("vetoed by %s", res.VetoedBy)
+	}
+	if got := strings.Join(res.Ran, ","); got != "retitle,quiet,describe" {
+		t.Errorf("ran %s", got)
+	}
+	c := res.Changes
+	if c.Title == nil || *c.Title != "Second" || c.Description == nil || *c.Description != "Why" || c.Priority == nil || *c.Priority != 2 {
+		t.Errorf("changes %+v: want the later title over the earlier", c)
+	}
+
+	r = runner(t,
+		shellHook("retitle", `echo '{"task": {"title": "First"}}'`, PreDispatch),
+		shellHook("license", `echo '{"veto": true, "reason": "no license", "comment": "see LICENSE"}'`, PreDispatch),
+		shellHook("never", `echo '{"task": {"title": "Never"}}'`, PreDispatch),
+	)
+	res = r.Run(context.Background(), Payload{Event: PreDispatch, Task: task})
+	if !res.Vetoed || res.VetoedBy != "license" || res.Reason != "no license" {
+		t.Fatalf("result %+v, want a veto by license", res)
+	}
+	if got := strings.Join(res.Ran, ","); got != "retitle,license" {
+		t.Errorf("ran %s: hooks after a veto must not run", got)
+	}
+	if len(res.Comments) != 1 || res.Comments[0].Content != "see LICENSE" {
+		t.Errorf("comments %+v", res.Comments)
+	}
+}
+
+func TestRunFailures(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		reason string // of the veto when fail_closed
+	}{
+		{"exit status", `echo 'no such ticket' >&2; exit 3`, "hook failed: exit status 3: no such ticket"},
+		{"invalid answer", `echo 'OK'`, "hook failed: invalid answer: invalid character 'O' looking for beginning of value"},
+	}
+	for _, tt := range tests {
+		h := shellHook("check", tt.script, PostComplete)
+		res := runner(t, h).Run(context.Background(), Payload{Event: PostComplete, Task: task})
+		if res.Vetoed || len(res.Ran) != 1 {
+			t.Errorf("%s: fail-open hook gave %+v, want it skipped", tt.name, res)
+		}
+
+		h.FailClosed = true
+		res = runner(t, h).Run(context.Background(), Payload{Event: PostComplete, Task: task})
+		if !res.Vetoed || res.VetoedBy != "check" || res.Reason != tt.reason {
+			t.Errorf("%s: fail-closed hook gave %+v, want a veto for %q", tt.name, res, tt.reason)
+		}
+	}
+}
+
+func TestRunTimeoutKillsWhatTheHookStarted(t *testing.T) {
+	h := shellHook("slow", `sleep 30 & sleep 30`, PreDispatch)
+	h.Timeout = "200ms"
+	h.FailClosed = true
+	r := runner(t, h)
+
+	start := time.Now()
+	res := r.Run(context.Background(), Payload{Event: PreDispatch, Task: task})
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Run took %v for a 200ms timeout", elapsed)
+	}
+	if !res.Vetoed || res.Reason != "hook failed: timed out after 200ms" {
+		t.Errorf("result %+v, want a veto for the timeout", res)
+	}
+}
+
+func TestRunDoesNotWaitForBackgroundProcesses(t *testing.T) {
+	// The hook answers and exits, leaving a process that holds its stdout
+	r := runner(t, shellHook("notify", `sleep 30 & echo '{"comment": "queued"}'`, PostComplete))
+
+	start := time.Now()
+	res := r.Run(context.Background(), Payload{Event: PostComplete, Task: task})
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Run took %v, waiting on the hook's background process", elapsed)
+	}
+	if len(res.Comments) != 1 || res.Comments[0].Content != "queued" {
+		t.Errorf("result %+v, want the hook's answer", res)
+	}
+}
+
+func TestRunHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/veto":
+			if r.Header.Get("Authorization") != "Bearer s3cret" || r.Header.Get("Content-Type") != "application/json" {
+				t.Errorf("headers %v", r.Header)
+			}
+			if !strings.Contains(string(body), `"event":"pre_dispatch"`) {
+				t.Errorf("body %s", body)
+			}
+			w.Write([]byte(`{"veto": true, "reason": "frozen"}`))
+		case "/down":
+			w.WriteHeader(http.StatusBadGateway)
+		case "/slow":
+			<-r.Context().Done()
+		}
+	}))
+	defer srv.Close()
+
+	res := runner(t, Hook{Name: "freeze", Events: []string{PreDispatch}, URL: srv.URL + "/veto", Headers: map[string]string{"Authorization": "Bearer s3cret"}}).
+		Run(context.Background(), Payload{Event: PreDispatch, Task: task})
+	if !res.Vetoed || res.Reason != "frozen" {
+		t.Errorf("result %+v, want a veto", res)
+	}
+
+	res = runner(t, Hook{Name: "ci", Events: []string{PreDispatch}, URL: srv.URL + "/down", FailClosed: true}).
+		Run(context.Background(), Payload{Event: PreDispatch, Task: task})
+	if want := "hook failed: " + srv.URL + "/down returned 502 Bad Gateway"; !res.Vetoed || res.Reason != want {
+		t.Errorf("result %+v, want a veto for %q", res, want)
+	}
+
+	res = runner(t, Hook{Name: "ci", Events: []string{PreDispatch}, URL: srv.URL + "/slow", Timeout: "100ms", FailClosed: true}).
+		Run(context.Background(), Payload{Event: PreDispatch, Task: task})
+	if !res.Vetoed || res.Reason != "hook failed: timed out after 100ms" {
+		t.Errorf("result %+v, want a veto for the timeout", res)
+	}
+}
+
+func TestNilRunner(t *testing.T) {
+	var r *Runner
+	if r.Has(PreDispatch) {
+		t.Error("a nil runner has hooks")
+	}
+	if res := r.Run(context.Background(), Payload{Event: PreDispatch}); res.Vetoed || len(res.Ran) != 0 {
+		t.Errorf("a nil runner ran hooks: %+v", res)
+	}
+}
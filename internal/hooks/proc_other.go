@@ -0,0 +1,9 @@
+//go:build !unix
+
+package hooks
+
+import "os/exec"
+
+// killProcessGroup leaves cmd as it is: without process groups, its context
+// kills only the command itself.
+func killProcessGroup(cmd *exec.Cmd) {}
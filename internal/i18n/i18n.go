@@ -0,0 +1,156 @@
+// Package i18n localizes user-facing API error messages and selects the
+// locale used for agent notification templates.
+//
+// Catalogs are keyed by the English message (gettext style), so handlers keep
+// returning plain English errors and untranslated messages fall through
+// unchanged. To add a language, add locales/<tag>.json mapping English
+// messages to translations, and optionally templates/<tag>/*.tmpl in the
+// openclaw package for agent prompts.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLocale is the language the source strings are written in.
+const DefaultLocale = "en"
+
+//go:embed locales/*.json
+var localesFS embed.FS
+
+// catalogs maps a normalized locale tag to its message translations.
+var catalogs = loadCatalogs()
+
+func loadCatalogs() map[string]map[string]string {
+	result := map[string]map[string]string{DefaultLocale: {}}
+	entries, err := localesFS.ReadDir("locales")
+	if err != nil {
+		log.Printf("[i18n] Failed to read catalogs: %v", err)
+		return result
+	}
+	for _, e := range entries {
+		content, err := localesFS.ReadFile("locales/" + e.Name())
+		if err != nil {
+			log.Printf("[i18n] Failed to read %s: %v", e.Name(), err)
+			continue
+		}
+		messages := map[string]string{}
+		if err := json.Unmarshal(content, &messages); err != nil {
+			log.Printf("[i18n] Invalid catalog %s: %v", e.Name(), err)
+			continue
+		}
+		result[Normalize(strings.TrimSuffix(e.Name(), path.Ext(e.Name())))] = messages
+	}
+	return result
+}
+
+// Normalize lower-cases a language tag and uses "-" as separator ("pt_BR" -> "pt-br").
+func Normalize(tag string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+}
+
+// Locales returns the supported locale tags, sorted.
+func Locales() []string {
+	result := make([]string, 0, len(catalogs))
+	for tag := range catalogs {
+		result = append(result, tag)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Supported reports whether tag (or its base language) has a catalog.
+func Supported(tag string) bool {
+	return resolve(tag) != ""
+}
+
+// Resolve returns the supported locale for tag, trying the full tag and then
+// its base language ("es-mx" -> "es"), or fallback if neither is supported.
+func Resolve(tag, fallback string) string {
+	if locale := resolve(tag); locale != "" {
+		return locale
+	}
+	return fallback
+}
+
+func resolve(tag string) string {
+	tag = Normalize(tag)
+	if tag == "" {
+		return ""
+	}
+	if _, ok := catalogs[tag]; ok {
+		return tag
+	}
+	if base, _, found := strings.Cut(tag, "-"); found {
+		if _, ok := catalogs[base]; ok {
+			return base
+		}
+	}
+	return ""
+}
+
+// Match picks the best supported locale from an Accept-Language header,
+// honouring q-values, or returns fallback.
+func Match(acceptLanguage, fallback string) string {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{tag: tag, q: q})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	for _, c := range candidates {
+		if locale := resolve(c.tag); locale != "" {
+			return locale
+		}
+	}
+	return fallback
+}
+
+// Translate returns message in locale, or message unchanged when there is no
+// translation.
+func Translate(locale, message string) string {
+	if messages, ok := catalogs[resolve(locale)]; ok {
+		if translated, ok := messages[message]; ok && translated != "" {
+			return translated
+		}
+	}
+	return message
+}
+
+type localeKey struct{}
+
+// WithLocale stores the request locale in ctx.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// FromContext returns the locale stored by WithLocale, or DefaultLocale.
+func FromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeKey{}).(string); ok && locale != "" {
+		return locale
+	}
+	return DefaultLocale
+}
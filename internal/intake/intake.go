@@ -0,0 +1,35 @@
+// Package intake is how tasks from outside the API — JIRA and GitHub
+// issues, emails — are created: the same way as POST /api/v1/tasks, so they
+// are checked, recorded and dispatched like any other. The integrations
+// cannot import the API handlers, which use them, so they create tasks
+// through a Creator the task handler implements.
+package intake
+
+import (
+	"context"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+)
+
+// Task is a task to create from outside the API.
+type Task struct {
+	Title       string
+	Description string
+	ProjectID   string // "" for none
+	Status      string // "" for backlog
+	Priority    int    // 1 is the most urgent; 0 for none
+	Source      string // where it came from, for its task_created event, e.g. "JIRA issue OPS-12"
+}
+
+// Link records where a task came from, e.g. the issue it is linked to. It
+// runs in the transaction that inserts the task, so the task is never left
+// without it.
+type Link func(tx *store.Store, task db.Task) error
+
+// Creator creates tasks from outside the API.
+type Creator interface {
+	// CreateLinkedTask creates t and runs link on it. It refuses t, creating
+	// nothing, where POST /api/v1/tasks would refuse it.
+	CreateLinkedTask(ctx context.Context, t Task, link Link) (db.Task, error)
+}
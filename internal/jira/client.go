@@ -0,0 +1,188 @@
+// Package jira bridges JIRA and Mission Control: issues of a JIRA project are
+// imported as tasks (status, priority and description mapped), re-imports
+// update the same tasks through the jira_links table, and task status
+// changes are pushed back to the issues as workflow transitions.
+package jira
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// searchPageSize is how many issues each search request asks for.
+const searchPageSize = 50
+
+// Issue is the part of a JIRA issue Mission Control uses.
+type Issue struct {
+	Key         string
+	Summary     string
+	Description string
+	Status      string // workflow status name, e.g. "In Progress"
+	Category    string // status category: new | indeterminate | done
+	Priority    string // e.g. "High"; "" if the project has no priorities
+}
+
+// Transition is a workflow transition available on an issue.
+type Transition struct {
+	ID       string
+	Name     string
+	To       string // status it leads to
+	Category string // that status's category
+}
+
+// Client talks to the JIRA REST API (v2).
+type Client struct {
+	baseURL string
+	email   string
+	token   string
+	http    *http.Client
+}
+
+// NewClient creates a client for the JIRA site at baseURL. With an email the
+// token is a JIRA Cloud API token (basic auth); without one it is a personal
+// access token (bearer auth), as used by JIRA Server and Data Center.
+func NewClient(baseURL, email, token string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		email:   email,
+		token:   token,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type issueJSON struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary     string  `json:"summary"`
+		Description *string `json:"description"`
+		Status      struct {
+			Name           string `json:"name"`
+			StatusCategory struct {
+				Key string `json:"key"`
+			} `json:"statusCategory"`
+		} `json:"status"`
+		Priority *struct {
+			Name string `json:"name"`
+		} `json:"priority"`
+	} `json:"fields"`
+}
+
+// Search returns every issue matching jql.
+func (c *Client) Search(ctx context.Context, jql string) ([]Issue, error) {
+	var issues []Issue
+	for {
+		q := url.Values{}
+		q.Set("jql", jql)
+		q.Set("startAt", fmt.Sprint(len(issues)))
+		q.Set("maxResults", fmt.Sprint(searchPageSize))
+		q.Set("fields", "summary,description,status,priority")
+
+		var page struct {
+			Total  int         `json:"total"`
+			Issues []issueJSON `json:"issues"`
+		}
+		if err := c.do(ctx, http.MethodGet, "/rest/api/2/search?"+q.Encode(), nil, &page); err != nil {
+			return nil, err
+		}
+		for _, i := range page.Issues {
+			issue := Issue{
+				Key:      i.Key,
+				Summary:  i.Fields.Summary,
+				Status:   i.Fields.Status.Name,
+				Category: i.Fields.Status.StatusCategory.Key,
+			}
+			if i.Fields.Description != nil {
+				issue.Description = *i.Fields.Description
+			}
+			if i.Fields.Priority != nil {
+				issue.Priority = i.Fields.Priority.Name
+			}
+			issues = append(issues, issue)
+		}
+		if len(page.Issues) == 0 || len(issues) >= page.Total {
+			return issues, nil
+		}
+	}
+}
+
+// Transitions lists the transitions currently available on issue key.
+func (c *Client) Transitions(ctx context.Context, key string) ([]Transition, error) {
+	var resp struct {
+		Transitions []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+			To   struct {
+				Name           string `json:"name"`
+				StatusCategory struct {
+					Key string `json:"key"`
+				} `json:"statusCategory"`
+			} `json:"to"`
+		} `json:"transitions"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/rest/api/2/issue/"+url.PathEscape(key)+"/transitions", nil, &resp); err != nil {
+		return nil, err
+	}
+	transitions := make([]Transition, 0, len(resp.Transitions))
+	for _, t := range resp.Transitions {
+		transitions = append(transitions, Transition{
+			ID:       t.ID,
+			Name:     t.Name,
+			To:       t.To.Name,
+			Category: t.To.StatusCategory.Key,
+		})
+	}
+	return transitions, nil
+}
+
+// Transition moves issue key through the transition with id transitionID.
+func (c *Client) Transition(ctx context.Context, key, transitionID string) error {
+	body := map[string]any{"transition": map[string]string{"id": transitionID}}
+	return c.do(ctx, http.MethodPost, "/rest/api/2/issue/"+url.PathEscape(key)+"/transitions", body, nil)
+}
+
+// do sends a request to path with body as JSON, if any, and decodes the
+// response into out, if any.
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.email != "" {
+		req.SetBasicAuth(c.email, c.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("jira %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("jira %s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
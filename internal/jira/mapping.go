@@ -0,0 +1,89 @@
+package jira
+
+import "strings"
+
+// JIRA status categories.
+const (
+	CategoryNew        = "new"
+	CategoryInProgress = "indeterminate"
+	CategoryDone       = "done"
+)
+
+// TaskStatus maps an issue's status to a task status: done issues are done,
+// issues in a review status are in review, and everything else is backlog,
+// waiting for an agent to be assigned.
+func TaskStatus(issue Issue) string {
+	switch {
+	case issue.Category == CategoryDone:
+		return "done"
+	case isReview(issue.Status):
+		return "review"
+	}
+	return "backlog"
+}
+
+// StatusCategory returns the JIRA status category matching a task status,
+// or "" for failed, which JIRA workflows have no place for.
+func StatusCategory(taskStatus string) string {
+	switch taskStatus {
+	case "backlog", "queued":
+		return CategoryNew
+	case "done":
+		return CategoryDone
+	case "failed":
+		return ""
+	}
+	return CategoryInProgress
+}
+
+// Priority maps a JIRA priority name to a task priority, 1 (highest) to 5,
+// or 0 for none or an unknown name.
+func Priority(name string) int64 {
+	switch strings.ToLower(name) {
+	case "highest", "blocker":
+		return 1
+	case "high", "critical":
+		return 2
+	case "medium", "major":
+		return 3
+	case "low", "minor":
+		return 4
+	case "lowest", "trivial":
+		return 5
+	}
+	return 0
+}
+
+// pickTransition picks the transition that takes an issue to where a task
+// in taskStatus is: a review status for tasks in review, otherwise a status
+// of the matching category, preferring ones that are not a review.
+func pickTransition(transitions []Transition, taskStatus string) (Transition, bool) {
+	category := StatusCategory(taskStatus)
+	wantReview := taskStatus == "review"
+	var fallback *Transition
+	for i, t := range transitions {
+		if t.Category != category {
+			continue
+		}
+		if isReview(t.To) == wantReview {
+			return t, true
+		}
+		if fallback == nil {
+			fallback = &transitions[i]
+		}
+	}
+	if fallback != nil {
+		return *fallback, true
+	}
+	return Transition{}, false
+}
+
+// sameStatus reports whether tasks in statuses a and b sit in the same
+// place on a JIRA board, so moving between them needs no transition.
+func sameStatus(a, b string) bool {
+	return StatusCategory(a) == StatusCategory(b) && (a == "review") == (b == "review")
+}
+
+func isReview(status string) bool {
+	return strings.Contains(strings.ToLower(status), "review")
+}
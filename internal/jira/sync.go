@@ -0,0 +1,289 @@
+package jira
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/intake"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/leader"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+	ws "github.com/abelkuruvilla/claw-agent-mission-control/internal/websocket"
+)
+
+// ImportRequest says which issues to import and where.
+type ImportRequest struct {
+	JiraProject string // JIRA project key, e.g. OPS; imports all its issues
+	JQL         string // overrides JiraProject's issues with a search of its own
+	ProjectID   string // Mission Control project the tasks go into; "" for none
+}
+
+// ImportedIssue is the outcome of importing one issue.
+type ImportedIssue struct {
+	IssueKey string `json:"issue_key"`
+	TaskID   string `json:"task_id"`
+	Outcome  string `json:"outcome"` // created | updated
+}
+
+// ImportResult sums up an import.
+type ImportResult struct {
+	Created int             `json:"created"`
+	Updated int             `json:"updated"`
+	Issues  []ImportedIssue `json:"issues"`
+}
+
+// Syncer imports JIRA issues as tasks and, while running, periodically
+// pushes task status changes back to their issues.
+type Syncer struct {
+	client   *Client
+	tasks    intake.Creator
+	store    *store.Store
+	hub      *ws.Hub
+	leader   *leader.Elector
+	stopChan chan struct{}
+	running  bool
+}
+
+// NewSyncer creates a syncer that creates the tasks of new issues through
+// tasks, as the API does.
+func NewSyncer(client *Client, tasks intake.Creator, st *store.Store, hub *ws.Hub) *Syncer {
+	return &Syncer{
+		client:   client,
+		tasks:    tasks,
+		store:    st,
+		hub:      hub,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Import creates a task for each matching issue not imported before and
+// refreshes the title, description and priority of those that were. An
+// issue's status is only applied to its task when the issue moved in JIRA
+// since the last sync and the task did not, so neither side's changes are
+// lost.
+func (s *Syncer) Import(ctx context.Context, req ImportRequest) (ImportResult, error) {
+	result := ImportResult{Issues: []ImportedIssue{}}
+	jql := req.JQL
+	if jql == "" {
+		jql = fmt.Sprintf("project = %q ORDER BY created ASC", req.JiraProject)
+	}
+	issues, err := s.client.Search(ctx, jql)
+	if err != nil {
+		return result, err
+	}
+
+	for _, issue := range issues {
+		link, err := s.store.GetJiraLinkByIssue(ctx, issue.Key)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			taskID, err := s.createTask(ctx, issue, req.ProjectID)
+			if err != nil {
+				return result, fmt.Errorf("import %s: %w", issue.Key, err)
+			}
+			result.Created++
+			result.Issues = append(result.Issues, ImportedIssue{IssueKey: issue.Key, TaskID: taskID, Outcome: "created"})
+		case err != nil:
+			return result, err
+		default:
+			if err := s.updateTask(ctx, issue, link); err != nil {
+				return result, fmt.Errorf("import %s: %w", issue.Key, err)
+			}
+			result.Updated++
+			result.Issues = append(result.Issues, ImportedIssue{IssueKey: issue.Key, TaskID: link.TaskID, Outcome: "updated"})
+		}
+	}
+	return result, nil
+}
+
+// createTask creates the task for an issue seen for the first time and
+// links the two.
+func (s *Syncer) createTask(ctx context.Context, issue Issue, projectID string) (string, error) {
+	task, err := s.tasks.CreateLinkedTask(ctx, intake.Task{
+		Title:       issue.Summary,
+		Description: issue.Description,
+		ProjectID:   projectID,
+		Status:      TaskStatus(issue),
+		Priority:    int(Priority(issue.Priority)),
+		Source:      "JIRA issue " + issue.Key,
+	}, func(tx *store.Store, task db.Task) error {
+		// Task scripts may have moved the task: the link holds the status
+		// it was created with, so the next push tells JIRA
+		_, err := tx.CreateJiraLink(ctx, db.CreateJiraLinkParams{
+			TaskID:       task.ID,
+			IssueKey:     issue.Key,
+			JiraProject:  issueProject(issue.Key),
+			JiraStatus:   issue.Status,
+			SyncedStatus: TaskStatus(issue),
+		})
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return task.ID, nil
+}
+
+// updateTask refreshes the task linked to an issue imported before.
+func (s *Syncer) updateTask(ctx context.Context, issue Issue, link db.JiraLink) error {
+	task, err := s.store.GetTask(ctx, link.TaskID)
+	if err != nil {
+		return err
+	}
+	if err := s.store.UpdateTaskDetails(ctx, task.ID, issue.Summary, issue.Description, Priority(issue.Priority)); err != nil {
+		return err
+	}
+
+	current := task.Status.String
+	if issue.Status == link.JiraStatus || current != link.SyncedStatus {
+		// Unchanged in JIRA, or the task moved too: its status is pushed
+		// to JIRA instead
+		return nil
+	}
+	status := current
+	if next := TaskStatus(issue); !sameStatus(next, current) {
+		status = next
+		if err := s.store.UpdateTaskStatus(ctx, task.ID, status); err != nil {
+			return err
+		}
+		s.logEvent(ctx, task.ID, "jira_status_pulled",
+			fmt.Sprintf("JIRA issue %s moved to %s; task is now %s", issue.Key, issue.Status, status))
+		if s.hub != nil {
+			s.hub.BroadcastTaskStatus(task.ID, status, 0)
+		}
+	}
+	return s.store.SetJiraLinkSynced(ctx, task.ID, issue.Status, status)
+}
+
+// PushStatuses transitions the issue of every linked task whose status
+// changed since the last sync to match it, and returns how many issues were
+// transitioned. Failures are logged and retried on the next push.
+func (s *Syncer) PushStatuses(ctx context.Context) (int, error) {
+	links, err := s.store.ListJiraLinks(ctx)
+	if err != nil {
+		return 0, err
+	}
+	pushed := 0
+	for _, link := range links {
+		task, err := s.store.GetTask(ctx, link.TaskID)
+		if err != nil {
+			continue
+		}
+		status := task.Status.String
+		if status == link.SyncedStatus {
+			continue
+		}
+		if StatusCategory(status) == "" || sameStatus(status, link.SyncedStatus) {
+			// Nowhere to move the issue to
+			if err := s.store.SetJiraLinkSynced(ctx, task.ID, link.JiraStatus, status); err != nil {
+				log.Printf("[JiraSync] Failed to record sync of task %s: %v", task.ID, err)
+			}
+			continue
+		}
+
+		transitions, err := s.client.Transitions(ctx, link.IssueKey)
+		if err != nil {
+			log.Printf("[JiraSync] Failed to list transitions of %s: %v", link.IssueKey, err)
+			continue
+		}
+		t, ok := pickTransition(transitions, status)
+		if !ok {
+			log.Printf("[JiraSync] No transition takes %s to where a %s task belongs", link.IssueKey, status)
+			if err := s.store.SetJiraLinkSynced(ctx, task.ID, link.JiraStatus, status); err != nil {
+				log.Printf("[JiraSync] Failed to record sync of task %s: %v", task.ID, err)
+			}
+			continue
+		}
+		if err := s.client.Transition(ctx, link.IssueKey, t.ID); err != nil {
+			log.Printf("[JiraSync] Failed to transition %s: %v", link.IssueKey, err)
+			continue
+		}
+		if err := s.store.SetJiraLinkSynced(ctx, task.ID, t.To, status); err != nil {
+			log.Printf("[JiraSync] Failed to record sync of task %s: %v", task.ID, err)
+		}
+		s.logEvent(ctx, task.ID, "jira_status_pushed",
+			fmt.Sprintf("JIRA issue %s moved to %s (task is %s)", link.IssueKey, t.To, status))
+		pushed++
+	}
+	return pushed, nil
+}
+
+func (s *Syncer) logEvent(ctx context.Context, taskID, eventType, message string) {
+	event, _ := s.store.CreateEvent(ctx, db.CreateEventParams{
+		TaskID:  sql.NullString{String: taskID, Valid: true},
+		Type:    eventType,
+		Message: message,
+	})
+	if event.ID != "" && s.hub != nil {
+		s.hub.BroadcastEvent(event)
+	}
+}
+
+// issueProject returns the project key of an issue key (OPS of OPS-12).
+func issueProject(key string) string {
+	if i := strings.LastIndex(key, "-"); i > 0 {
+		return key[:i]
+	}
+	return key
+}
+
+// SetLeader sets the leader elector; status changes are only pushed by the
+// leader.
+func (s *Syncer) SetLeader(l *leader.Elector) {
+	s.leader = l
+}
+
+// Start pushes status changes every interval until ctx is done or Stop is
+// called.
+func (s *Syncer) Start(ctx context.Context, interval time.Duration) {
+	if s.running {
+		log.Println("[JiraSync] Already running")
+		return
+	}
+
+	s.running = true
+	log.Printf("[JiraSync] Pushing task status changes to JIRA every %v", interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if !s.leader.IsLeader() {
+					continue
+				}
+				if n, err := s.PushStatuses(ctx); err != nil {
+					log.Printf("[JiraSync] Push failed: %v", err)
+				} else if n > 0 {
+					log.Printf("[JiraSync] Pushed %d status changes", n)
+				}
+			case <-s.stopChan:
+				log.Println("[JiraSync] Stopping")
+				s.running = false
+				return
+			case <-ctx.Done():
+				s.running = false
+				return
+			}
+		}
+	}()
+}
+
+func (s *Syncer) Stop() {
+	if !s.running {
+		return
+	}
+	close(s.stopChan)
+	s.running = false
+}
+
+// Running reports whether the periodic status push is running.
+func (s *Syncer) Running() bool {
+	return s.running
+}
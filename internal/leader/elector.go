@@ -0,0 +1,236 @@
+// Package leader elects one of the Mission Control instances sharing a
+// database as leader, so background work (queue processor, scheduler,
+// watchdog, sync) runs once rather than on every instance, while all of them
+// serve the API.
+//
+// The leader holds a lease in the database that it renews a few times per
+// lease period. If it stops renewing, because it crashed or lost the
+// database, another instance takes the lease over once it has expired; an
+// instance that shuts down gives the lease up so a successor need not wait.
+// An instance stops acting as leader as soon as its lease runs out, renewed
+// or not, so two instances never lead at once as long as their clocks agree.
+package leader
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+	ws "github.com/abelkuruvilla/claw-agent-mission-control/internal/websocket"
+)
+
+// LeaseName is the lease the leader holds.
+const LeaseName = "leader"
+
+// Elector campaigns for leadership on behalf of one instance. A nil
+// *Elector is always leader, for a single instance without election.
+type Elector struct {
+	store *store.Store
+	hub   *ws.Hub
+	id    string
+	ttl   time.Duration
+
+	mu        sync.RWMutex
+	leading   bool
+	expires   time.Time // when the lease held runs out
+	onElected []func(ctx context.Context)
+	running   bool
+	stopChan  chan struct{}
+	done      chan struct{} // closed once campaigning has stopped
+}
+
+// New returns an elector for the instance id, holding the lease for ttl at
+// a time.
+func New(st *store.Store, hub *ws.Hub, id string, ttl time.Duration) *Elector {
+	return &Elector{
+		store: st,
+		hub:   hub,
+		id:    id,
+		ttl:   ttl,
+	}
+}
+
+// ID returns the instance ID.
+func (e *Elector) ID() string {
+	if e == nil {
+		return ""
+	}
+	return e.id
+}
+
+// IsLeader reports whether this instance is the leader now.
+func (e *Elector) IsLeader() bool {
+	if e == nil {
+		return true
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leading && time.Now().Before(e.expires)
+}
+
+// Leader returns the instance holding the lease, or "" if none does.
+func (e *Elector) Leader(ctx context.Context) (string, error) {
+	lease, err := e.store.GetLease(ctx, LeaseName)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && lease.ExpiresAt.Before(time.Now())) {
+		return "", nil
+	}
+	return lease.Holder, err
+}
+
+// OnElected adds fn to what is run whenever this instance becomes leader,
+// such as settling work the previous leader left in flight. Callbacks run
+// in the order added.
+func (e *Elector) OnElected(fn func(ctx context.Context)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onElected = append(e.onElected, fn)
+}
+
+// Campaign takes or renews the lease once and reports whether this instance
+// became leader by it. A failed attempt keeps the leadership until the lease
+// runs out.
+func (e *Elector) Campaign(ctx context.Context) bool {
+	expires := time.Now().Add(e.ttl)
+	ok, err := e.store.AcquireLease(ctx, LeaseName, e.id, expires)
+
+	e.mu.Lock()
+	wasLeading := e.leading
+	switch {
+	case err != nil:
+		log.Printf("[Leader] Failed to renew lease: %v", err)
+		if e.leading && !time.Now().Before(e.expires) {
+			e.leading = false
+		}
+	case ok:
+		e.leading = true
+		e.expires = expires
+	default:
+		e.leading = false
+	}
+	leading := e.leading
+	e.mu.Unlock()
+
+	switch {
+	case leading && !wasLeading:
+		log.Printf("[Leader] %s is now the leader", e.id)
+		e.logEvent(ctx, "leader_elected", fmt.Sprintf("Instance %s is now the leader", e.id))
+		return true
+	case !leading && wasLeading:
+		log.Printf("[Leader] %s lost the leader lease", e.id)
+		e.logEvent(ctx, "leadership_lost", fmt.Sprintf("Instance %s lost the leader lease", e.id))
+	}
+	return false
+}
+
+// Start campaigns for leadership until ctx is done or Stop is called, and
+// gives up the lease, if held, either way. The first campaign runs before
+// Start returns, together with the OnElected callbacks if it wins, so work
+// that must precede the background workers does; later elections run them
+// in the background.
+func (e *Elector) Start(ctx context.Context) {
+	e.mu.Lock()
+	if e.running {
+		e.mu.Unlock()
+		log.Println("[Leader] Already running")
+		return
+	}
+	e.running = true
+	stop, done := make(chan struct{}), make(chan struct{})
+	e.stopChan, e.done = stop, done
+	e.mu.Unlock()
+	log.Printf("[Leader] Campaigning as %s (lease %v)", e.id, e.ttl)
+
+	if e.Campaign(ctx) {
+		e.elected(ctx)
+	} else {
+		log.Printf("[Leader] %s is a follower; background work runs on the leader", e.id)
+	}
+
+	go func() {
+		defer close(done)
+		// Renew well before the lease runs out, so a slow write or two
+		// does not lose it
+		ticker := time.NewTicker(e.ttl / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if e.Campaign(ctx) {
+					go e.elected(ctx)
+				}
+			case <-stop:
+				log.Println("[Leader] Stopping")
+				e.resign()
+				return
+			case <-ctx.Done():
+				e.resign()
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops campaigning and returns once the lease, if held, is given up,
+// so another instance can take over at once.
+func (e *Elector) Stop() {
+	e.mu.Lock()
+	stop, done := e.stopChan, e.done
+	if e.running {
+		e.running = false
+		close(stop)
+	}
+	e.mu.Unlock()
+	if done != nil {
+		<-done
+	}
+}
+
+// resign stops leading and releases the lease if this instance held it.
+func (e *Elector) resign() {
+	e.mu.Lock()
+	leading := e.leading
+	e.leading = false
+	e.running = false
+	e.mu.Unlock()
+	if !leading {
+		return
+	}
+	// ctx may be what ended campaigning
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := e.store.ReleaseLease(ctx, LeaseName, e.id); err != nil {
+		log.Printf("[Leader] Failed to release lease: %v", err)
+		return
+	}
+	log.Printf("[Leader] %s released the leader lease", e.id)
+}
+
+func (e *Elector) elected(ctx context.Context) {
+	e.mu.RLock()
+	callbacks := e.onElected
+	e.mu.RUnlock()
+	for _, fn := range callbacks {
+		fn(ctx)
+	}
+}
+
+func (e *Elector) logEvent(ctx context.Context, eventType, message string) {
+	event, err := e.store.CreateEvent(ctx, db.CreateEventParams{
+		Type:    eventType,
+		Message: message,
+	})
+	if err != nil {
+		log.Printf("[Leader] Failed to create event (%s): %v", eventType, err)
+		return
+	}
+	if e.hub != nil {
+		e.hub.BroadcastEvent(event)
+	}
+}
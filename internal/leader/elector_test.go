@@ -0,0 +1,82 @@
+package leader
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+)
+
+var dbCounter int
+
+func newStore(t *testing.T) *store.Store {
+	t.Helper()
+	dbCounter++
+	sqlDB, err := sql.Open("sqlite3", fmt.Sprintf("file:leader%d?mode=memory&cache=shared&_foreign_keys=on", dbCounter))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.Migrate(sqlDB); err != nil {
+		t.Fatal(err)
+	}
+	return store.New(sqlDB)
+}
+
+func TestStopReleasesLease(t *testing.T) {
+	st := newStore(t)
+	ctx := context.Background()
+	a := New(st, nil, "a", time.Minute)
+	b := New(st, nil, "b", time.Minute)
+
+	a.Start(ctx)
+	b.Start(ctx)
+	defer b.Stop()
+	if !a.IsLeader() || b.IsLeader() {
+		t.Fatalf("a leads %v, b leads %v; want only a", a.IsLeader(), b.IsLeader())
+	}
+
+	a.Stop()
+	if a.IsLeader() {
+		t.Error("a still leads after Stop")
+	}
+	if holder, err := a.Leader(ctx); err != nil || holder != "" {
+		t.Fatalf("lease held by %q (%v) after Stop", holder, err)
+	}
+	// b need not wait for the lease to run out
+	if !b.Campaign(ctx) {
+		t.Error("b did not take over the released lease")
+	}
+	a.Stop() // a second Stop does nothing
+}
+
+func TestContextEndReleasesLease(t *testing.T) {
+	st := newStore(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	e := New(st, nil, "a", time.Minute)
+
+	e.Start(ctx)
+	if !e.IsLeader() {
+		t.Fatal("not leader")
+	}
+	cancel()
+	// Stop returns once campaigning has ended, however it ended
+	e.Stop()
+	if holder, err := e.Leader(context.Background()); err != nil || holder != "" {
+		t.Fatalf("lease held by %q (%v) after ctx ended", holder, err)
+	}
+
+	// The elector can campaign again
+	e.Start(context.Background())
+	defer e.Stop()
+	if !e.IsLeader() {
+		t.Error("not leader after restarting")
+	}
+}
@@ -0,0 +1,58 @@
+// Package localtime renders stored times for a reader's time zone. Times are
+// stored and returned in UTC; a response may add the same instant in the
+// zone of whoever reads it, chosen per request (?tz= or X-Timezone) or, for a
+// task, by its agent's timezone preference.
+package localtime
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata" // zones are named by clients; don't depend on the host's zoneinfo
+)
+
+// Load returns the location of an IANA zone name such as "Europe/Berlin".
+// Unlike time.LoadLocation, the empty name and "Local" are refused: a
+// preference names a zone.
+func Load(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	return loc, nil
+}
+
+// Valid reports whether name is an IANA zone name.
+func Valid(name string) bool {
+	_, err := Load(name)
+	return err == nil
+}
+
+// Format returns t in loc as RFC3339, e.g. 2026-02-08T11:00:00+01:00.
+func Format(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format(time.RFC3339)
+}
+
+// ParseWall parses a time of day without a zone offset, such as
+// 2026-02-08T10:00:00, as a wall clock time in loc.
+func ParseWall(s string, loc *time.Location) (time.Time, error) {
+	return time.ParseInLocation("2006-01-02T15:04:05", strings.TrimSpace(s), loc)
+}
+
+type locationKey struct{}
+
+// WithLocation stores the reader's time zone in ctx.
+func WithLocation(ctx context.Context, loc *time.Location) context.Context {
+	return context.WithValue(ctx, locationKey{}, loc)
+}
+
+// FromContext returns the time zone stored by WithLocation, or nil.
+func FromContext(ctx context.Context) *time.Location {
+	loc, _ := ctx.Value(locationKey{}).(*time.Location)
+	return loc
+}
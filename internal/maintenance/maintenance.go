@@ -0,0 +1,76 @@
+// Package maintenance is the maintenance mode switch. While it is on,
+// background dispatching (queue processor, scheduler and watchdog) is paused
+// and new tasks are refused, so upgrades and database maintenance run
+// against a quiet system; reads keep working. The state is kept in settings
+// so it survives the restart of an upgrade.
+package maintenance
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// State is whether maintenance mode is on, why and since when.
+type State struct {
+	Enabled bool       `json:"enabled"`
+	Reason  string     `json:"reason,omitempty"`
+	Since   *time.Time `json:"since,omitempty"`
+}
+
+// Mode is the live maintenance switch shared by the API and the background
+// workers. A nil *Mode is always off.
+type Mode struct {
+	mu    sync.RWMutex
+	state State
+}
+
+// New returns a switch in state s, as loaded from settings.
+func New(s State) *Mode {
+	return &Mode{state: s}
+}
+
+// Enabled reports whether maintenance mode is on.
+func (m *Mode) Enabled() bool {
+	if m == nil {
+		return false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state.Enabled
+}
+
+// State returns the current state.
+func (m *Mode) State() State {
+	if m == nil {
+		return State{}
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state
+}
+
+// Set turns maintenance mode on or off and returns the new state. Turning it
+// on while already on keeps the original start time.
+func (m *Mode) Set(enabled bool, reason string) State {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !enabled {
+		m.state = State{}
+		return m.state
+	}
+	since := time.Now().UTC()
+	if m.state.Enabled && m.state.Since != nil {
+		since = *m.state.Since
+	}
+	m.state = State{Enabled: true, Reason: reason, Since: &since}
+	return m.state
+}
+
+// Message explains to a caller that work is refused for maintenance.
+func (m *Mode) Message() string {
+	if reason := m.State().Reason; reason != "" {
+		return fmt.Sprintf("Mission Control is in maintenance mode (%s); try again later", reason)
+	}
+	return "Mission Control is in maintenance mode; try again later"
+}
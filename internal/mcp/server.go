@@ -0,0 +1,188 @@
+// Package mcp exposes Mission Control as a Model Context Protocol server, so
+// agents and IDEs work on tasks through MCP tools rather than curl commands
+// spelled out in their prompts. It speaks the Streamable HTTP transport,
+// answering each JSON-RPC request in the body of its POST.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/api/handlers"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+)
+
+// Path is where the MCP endpoint is served.
+const Path = "/mcp"
+
+// protocolVersions are the MCP revisions the server speaks, newest first.
+var protocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// maxRequestSize bounds a JSON-RPC request body.
+const maxRequestSize = 1 << 20
+
+// JSON-RPC error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// TaskService is the task logic of the API that the task handler
+// implements, so tools have the same effect as the matching API requests.
+type TaskService interface {
+	CreateTask(ctx context.Context, req handlers.CreateTaskRequest) (db.Task, error)
+	SetTaskStatus(ctx context.Context, id, status, errMsg string) (db.Task, error)
+}
+
+// ProgressService is the progress log logic of the API that the reporting
+// handler implements.
+type ProgressService interface {
+	AppendProgress(ctx context.Context, taskID, author, content string) error
+}
+
+// Server is the MCP server.
+type Server struct {
+	tasks    TaskService
+	progress ProgressService
+	store    *store.Store
+	http     *http.Server
+}
+
+func NewServer(tasks TaskService, progress ProgressService, st *store.Store) *Server {
+	s := &Server{tasks: tasks, progress: progress, store: st}
+	mux := http.NewServeMux()
+	mux.Handle(Path, s)
+	s.http = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	return s
+}
+
+// Start serves MCP on addr until Shutdown is called.
+func (s *Server) Start(addr string) error {
+	s.http.Addr = addr
+	err := s.http.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown stops serving, letting requests in progress finish.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.http.Shutdown(ctx)
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"` // absent on notifications
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// ServeHTTP answers a POSTed JSON-RPC message. The server sends nothing
+// unprompted, so there is no event stream to GET.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "MCP messages are POSTed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req request
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, response{JSONRPC: "2.0", ID: json.RawMessage("null"),
+			Error: &rpcError{Code: codeParseError, Message: err.Error()}})
+		return
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		writeJSON(w, http.StatusBadRequest, response{JSONRPC: "2.0", ID: orNull(req.ID),
+			Error: &rpcError{Code: codeInvalidRequest, Message: "not a JSON-RPC 2.0 request"}})
+		return
+	}
+	// Notifications (initialized, cancelled) need no answer
+	if len(req.ID) == 0 {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	result, rpcErr := s.dispatch(r.Context(), req)
+	writeJSON(w, http.StatusOK, response{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rpcErr})
+}
+
+func (s *Server) dispatch(ctx context.Context, req request) (any, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		_ = json.Unmarshal(req.Params, &params)
+		return map[string]any{
+			"protocolVersion": negotiateVersion(params.ProtocolVersion),
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]string{"name": "mission-control", "version": "dev"},
+			"instructions":    "Tools for Mission Control tasks. Tasks are named by ID or short ID (MC-12).",
+		}, nil
+	case "ping":
+		return map[string]any{}, nil
+	case "tools/list":
+		return map[string]any{"tools": toolList()}, nil
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		t, ok := tools[params.Name]
+		if !ok {
+			return nil, &rpcError{Code: codeInvalidParams, Message: "unknown tool " + params.Name}
+		}
+		return s.call(ctx, t, params.Arguments), nil
+	}
+	return nil, &rpcError{Code: codeMethodNotFound, Message: "method not found: " + req.Method}
+}
+
+// negotiateVersion answers a client's protocol version with the same one if
+// the server speaks it, or the newest one it speaks.
+func negotiateVersion(requested string) string {
+	for _, v := range protocolVersions {
+		if v == requested {
+			return v
+		}
+	}
+	return protocolVersions[0]
+}
+
+func orNull(id json.RawMessage) json.RawMessage {
+	if len(id) == 0 {
+		return json.RawMessage("null")
+	}
+	return id
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("[MCP] Failed to write response: %v", err)
+	}
+}
@@ -0,0 +1,299 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/api/handlers"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/taskrefs"
+)
+
+const (
+	defaultTaskListLimit = 50
+	maxTaskListLimit     = 200
+)
+
+// taskStatuses are the statuses update_task_status sets.
+var taskStatuses = []string{"backlog", "planning", "discussing", "executing", "verifying", "review", "done", "failed"}
+
+// tool is an MCP tool: its listing, and run, which returns the tool's result
+// or an error to show the caller.
+type tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+	run         func(s *Server, ctx context.Context, args json.RawMessage) (any, error)
+}
+
+// toolOrder is the order tools are listed in.
+var toolOrder = []string{"list_tasks", "get_task", "update_task_status", "append_progress", "create_subtask"}
+
+var tools = map[string]tool{
+	"list_tasks": {
+		Name:        "list_tasks",
+		Description: "List tasks, newest first, optionally only those with a status, agent or project.",
+		InputSchema: object(map[string]any{
+			"status":     str("Only tasks with this status"),
+			"agent_id":   str("Only tasks assigned to this agent"),
+			"project_id": str("Only tasks in this project"),
+			"limit": map[string]any{"type": "integer", "minimum": 1, "maximum": maxTaskListLimit,
+				"description": fmt.Sprintf("At most this many tasks (default %d)", defaultTaskListLimit)},
+		}),
+		run: (*Server).listTasks,
+	},
+	"get_task": {
+		Name:        "get_task",
+		Description: "Get a task by ID or short ID (MC-12).",
+		InputSchema: object(map[string]any{"task": str("Task ID or short ID")}, "task"),
+		run:         (*Server).getTask,
+	},
+	"update_task_status": {
+		Name:        "update_task_status",
+		Description: "Set the status of a task. A task requiring review goes to review instead of done.",
+		InputSchema: object(map[string]any{
+			"task":   str("Task ID or short ID"),
+			"status": map[string]any{"type": "string", "enum": taskStatuses},
+			"error":  str("Why the task failed, with status failed"),
+		}, "task", "status"),
+		run: (*Server).updateTaskStatus,
+	},
+	"append_progress": {
+		Name:        "append_progress",
+		Description: "Add an entry to a task's progress log.",
+		InputSchema: object(map[string]any{
+			"task":    str("Task ID or short ID"),
+			"content": str("What was done, learned or decided"),
+			"author":  str("Who wrote it; defaults to the task's agent"),
+		}, "task", "content"),
+		run: (*Server).appendProgress,
+	},
+	"create_subtask": {
+		Name:        "create_subtask",
+		Description: "Create a subtask of a task, subject to the task's delegation limits.",
+		InputSchema: object(map[string]any{
+			"parent_task": str("Parent task ID or short ID"),
+			"title":       str("Subtask title"),
+			"description": str("What the subtask is to do"),
+			"agent_id":    str("Agent to assign it to"),
+		}, "parent_task", "title"),
+		run: (*Server).createSubtask,
+	},
+}
+
+func toolList() []tool {
+	list := make([]tool, 0, len(toolOrder))
+	for _, name := range toolOrder {
+		list = append(list, tools[name])
+	}
+	return list
+}
+
+// call runs a tool, reporting its failure in the result as MCP has tools do,
+// so the model calling it sees why.
+func (s *Server) call(ctx context.Context, t tool, args json.RawMessage) any {
+	if len(args) == 0 {
+		args = json.RawMessage("{}")
+	}
+	result, err := t.run(s, ctx, args)
+	if err != nil {
+		return toolResult(errorText(err), true)
+	}
+	text, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return toolResult(err.Error(), true)
+	}
+	return toolResult(string(text), false)
+}
+
+func toolResult(text string, isError bool) map[string]any {
+	return map[string]any{
+		"content": []map[string]string{{"type": "text", "text": text}},
+		"isError": isError,
+	}
+}
+
+func (s *Server) listTasks(ctx context.Context, raw json.RawMessage) (any, error) {
+	var args struct {
+		Status    string `json:"status"`
+		AgentID   string `json:"agent_id"`
+		ProjectID string `json:"project_id"`
+		Limit     int    `json:"limit"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, err
+	}
+	if args.Limit == 0 {
+		args.Limit = defaultTaskListLimit
+	}
+	if args.Limit < 1 || args.Limit > maxTaskListLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d", maxTaskListLimit)
+	}
+
+	var tasks []db.Task
+	var err error
+	if args.Status != "" {
+		tasks, err = s.store.ListTasksByStatus(ctx, args.Status)
+	} else {
+		tasks, err = s.store.ListTasks(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]handlers.TaskResponse, 0)
+	for _, t := range tasks {
+		if args.AgentID != "" && t.AgentID.String != args.AgentID {
+			continue
+		}
+		if args.ProjectID != "" && t.ProjectID.String != args.ProjectID {
+			continue
+		}
+		responses = append(responses, handlers.ToTaskResponse(t))
+		if len(responses) == args.Limit {
+			break
+		}
+	}
+	return responses, nil
+}
+
+func (s *Server) getTask(ctx context.Context, raw json.RawMessage) (any, error) {
+	var args struct {
+		Task string `json:"task"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, err
+	}
+	task, err := s.findTask(ctx, args.Task)
+	if err != nil {
+		return nil, err
+	}
+	return handlers.ToTaskResponse(task), nil
+}
+
+func (s *Server) updateTaskStatus(ctx context.Context, raw json.RawMessage) (any, error) {
+	var args struct {
+		Task   string `json:"task"`
+		Status string `json:"status"`
+		Error  string `json:"error"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, err
+	}
+	if !validStatus(args.Status) {
+		return nil, fmt.Errorf("status must be one of %s", strings.Join(taskStatuses, ", "))
+	}
+	task, err := s.findTask(ctx, args.Task)
+	if err != nil {
+		return nil, err
+	}
+	task, err = s.tasks.SetTaskStatus(ctx, task.ID, args.Status, args.Error)
+	if err != nil {
+		return nil, err
+	}
+	return handlers.ToTaskResponse(task), nil
+}
+
+func (s *Server) appendProgress(ctx context.Context, raw json.RawMessage) (any, error) {
+	var args struct {
+		Task    string `json:"task"`
+		Content string `json:"content"`
+		Author  string `json:"author"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(args.Content) == "" {
+		return nil, errors.New("content is required")
+	}
+	task, err := s.findTask(ctx, args.Task)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.progress.AppendProgress(ctx, task.ID, args.Author, args.Content); err != nil {
+		return nil, err
+	}
+	return map[string]string{"status": "appended"}, nil
+}
+
+func (s *Server) createSubtask(ctx context.Context, raw json.RawMessage) (any, error) {
+	var args struct {
+		ParentTask  string `json:"parent_task"`
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		AgentID     string `json:"agent_id"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(args.Title) == "" {
+		return nil, errors.New("title is required")
+	}
+	parent, err := s.findTask(ctx, args.ParentTask)
+	if err != nil {
+		return nil, err
+	}
+	task, err := s.tasks.CreateTask(ctx, handlers.CreateTaskRequest{
+		Title:        args.Title,
+		Description:  args.Description,
+		AgentID:      args.AgentID,
+		ParentTaskID: parent.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return handlers.ToTaskResponse(task), nil
+}
+
+// findTask returns the task ref names, by ID or short ID.
+func (s *Server) findTask(ctx context.Context, ref string) (db.Task, error) {
+	if ref == "" {
+		return db.Task{}, errors.New("task is required")
+	}
+	var task db.Task
+	var err error
+	if taskrefs.IsShortID(ref) {
+		task, err = s.store.GetTaskByShortID(ctx, ref)
+	} else {
+		task, err = s.store.GetTask(ctx, ref)
+	}
+	if err != nil {
+		return db.Task{}, fmt.Errorf("there is no task %s", ref)
+	}
+	return task, nil
+}
+
+func validStatus(status string) bool {
+	for _, s := range taskStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// object is the JSON Schema of a tool's arguments.
+func object(properties map[string]any, required ...string) map[string]any {
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func str(description string) map[string]any {
+	return map[string]any{"type": "string", "description": description}
+}
+
+// errorText is the message of a service error.
+func errorText(err error) string {
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		return fmt.Sprint(he.Message)
+	}
+	return err.Error()
+}
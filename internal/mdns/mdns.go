@@ -0,0 +1,301 @@
+// Package mdns advertises Mission Control on the LAN over multicast DNS
+// (RFC 6762) as a DNS-SD service (RFC 6763) of type _clawmc._tcp, so agents
+// and companion apps find its API rather than having its address written
+// into their prompts. It answers queries for the service, its instance and
+// the host's IPv4 addresses, announces itself on start and says goodbye on
+// stop.
+package mdns
+
+import (
+	"errors"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// Service is the DNS-SD service type Mission Control is advertised as.
+const Service = "_clawmc._tcp"
+
+// How long answers may be cached, as RFC 6762 suggests for records naming
+// a host (2m) and other records (75m).
+const (
+	hostTTL  = 120
+	otherTTL = 4500
+)
+
+// cacheFlush marks records only this responder answers for (RFC 6762 10.2);
+// in questions the same bit asks for a unicast answer.
+const cacheFlush = 1 << 15
+
+var group = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+
+// Advertiser answers mDNS queries for one instance of the service.
+type Advertiser struct {
+	port int
+	txt  []string // key=value pairs
+	ip   net.IP   // the only address advertised; nil = the host's addresses
+
+	host      dnsmessage.Name // build-box.local.
+	service   dnsmessage.Name // _clawmc._tcp.local.
+	instance  dnsmessage.Name // Mission Control (build-box)._clawmc._tcp.local.
+	enumerate dnsmessage.Name // _services._dns-sd._udp.local.
+
+	mu   sync.Mutex
+	conn *net.UDPConn
+	done chan struct{}
+}
+
+// New returns an advertiser of the service instance named instance on port,
+// with txt, key=value pairs such as version=1.0.0. A specific ip is the only
+// address advertised; otherwise those of the host's interfaces are.
+func New(instance string, port int, txt []string, ip string) (*Advertiser, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+	hostname, _, _ = strings.Cut(hostname, ".")
+	a := &Advertiser{port: port, txt: txt}
+	if parsed := net.ParseIP(ip).To4(); parsed != nil && !parsed.IsUnspecified() {
+		a.ip = parsed
+	}
+	for _, n := range []struct {
+		name *dnsmessage.Name
+		s    string
+	}{
+		{&a.host, hostname + ".local."},
+		{&a.service, Service + ".local."},
+		{&a.instance, instanceLabel(instance) + "." + Service + ".local."},
+		{&a.enumerate, "_services._dns-sd._udp.local."},
+	} {
+		if *n.name, err = dnsmessage.NewName(n.s); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// Start joins the mDNS group, announces the service and answers queries
+// until Stop.
+func (a *Advertiser) Start() error {
+	conn, err := net.ListenMulticastUDP("udp4", nil, group)
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	a.conn = conn
+	a.done = make(chan struct{})
+	a.mu.Unlock()
+
+	go a.serve(conn)
+	go func(done chan struct{}) {
+		// Announce twice, a second apart (RFC 6762 8.3)
+		for i := 0; i < 2; i++ {
+			select {
+			case <-done:
+				return
+			default:
+				a.announce(conn, otherTTL, hostTTL)
+			}
+			select {
+			case <-done:
+				return
+			case <-time.After(time.Second):
+			}
+		}
+	}(a.done)
+	log.Printf("[mDNS] Advertising %s on port %d", a.instance, a.port)
+	return nil
+}
+
+// Stop says goodbye, so the service drops out of caches at once, and stops
+// answering.
+func (a *Advertiser) Stop() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.conn == nil {
+		return
+	}
+	close(a.done)
+	a.announce(a.conn, 0, 0)
+	a.conn.Close()
+	a.conn = nil
+}
+
+// serve answers the queries received on conn.
+func (a *Advertiser) serve(conn *net.UDPConn) {
+	buf := make([]byte, 9000)
+	for {
+		n, from, err := conn.ReadFromUDP(buf)
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			log.Printf("[mDNS] Read failed: %v", err)
+			return
+		}
+		a.answer(conn, buf[:n], from)
+	}
+}
+
+// answer replies to the query in msg from from, if it asks about the
+// service.
+func (a *Advertiser) answer(conn *net.UDPConn, msg []byte, from *net.UDPAddr) {
+	var p dnsmessage.Parser
+	header, err := p.Start(msg)
+	if err != nil || header.Response {
+		return
+	}
+	questions, err := p.AllQuestions()
+	if err != nil {
+		return
+	}
+
+	var answers []dnsmessage.Resource
+	unicast := false
+	for _, q := range questions {
+		if q.Class&cacheFlush != 0 {
+			unicast = true
+		}
+		answers = append(answers, a.records(q.Name, q.Type, otherTTL, hostTTL)...)
+	}
+	if len(answers) == 0 {
+		return
+	}
+
+	// A query from a port other than 5353 is a plain DNS client, which
+	// expects its ID and questions back (RFC 6762 6.7)
+	legacy := from.Port != group.Port
+	reply := dnsmessage.Message{
+		Header:  dnsmessage.Header{Response: true, Authoritative: true},
+		Answers: answers,
+	}
+	if legacy {
+		reply.Header.ID = header.ID
+		reply.Questions = questions
+	}
+	// Records the answers name, so one round trip suffices (RFC 6763 12)
+	if answers[0].Header.Type == dnsmessage.TypePTR {
+		reply.Additionals = append(a.records(a.instance, dnsmessage.TypeSRV, otherTTL, hostTTL),
+			append(a.records(a.instance, dnsmessage.TypeTXT, otherTTL, hostTTL),
+				a.records(a.host, dnsmessage.TypeA, otherTTL, hostTTL)...)...)
+	} else if answers[0].Header.Type == dnsmessage.TypeSRV {
+		reply.Additionals = a.records(a.host, dnsmessage.TypeA, otherTTL, hostTTL)
+	}
+
+	packed, err := reply.Pack()
+	if err != nil {
+		log.Printf("[mDNS] Packing answer failed: %v", err)
+		return
+	}
+	to := group
+	if legacy || unicast {
+		to = from
+	}
+	if _, err := conn.WriteToUDP(packed, to); err != nil {
+		log.Printf("[mDNS] Answer to %s failed: %v", from, err)
+	}
+}
+
+// announce sends every record unasked; TTLs of 0 say goodbye.
+func (a *Advertiser) announce(conn *net.UDPConn, ttl, hostTTL uint32) {
+	reply := dnsmessage.Message{Header: dnsmessage.Header{Response: true, Authoritative: true}}
+	for _, name := range []dnsmessage.Name{a.service, a.instance, a.host} {
+		reply.Answers = append(reply.Answers, a.records(name, dnsmessage.TypeALL, ttl, hostTTL)...)
+	}
+	packed, err := reply.Pack()
+	if err != nil {
+		log.Printf("[mDNS] Packing announcement failed: %v", err)
+		return
+	}
+	if _, err := conn.WriteToUDP(packed, group); err != nil {
+		log.Printf("[mDNS] Announcement failed: %v", err)
+	}
+}
+
+// records returns the records of name of type t (TypeALL for any) this
+// advertiser answers for.
+func (a *Advertiser) records(name dnsmessage.Name, t dnsmessage.Type, ttl, hostTTL uint32) []dnsmessage.Resource {
+	header := func(rt dnsmessage.Type, ttl uint32, unique bool) dnsmessage.ResourceHeader {
+		class := dnsmessage.ClassINET
+		if unique {
+			class |= cacheFlush
+		}
+		return dnsmessage.ResourceHeader{Name: name, Type: rt, Class: class, TTL: ttl}
+	}
+	wants := func(rt dnsmessage.Type) bool { return t == rt || t == dnsmessage.TypeALL }
+
+	var rs []dnsmessage.Resource
+	switch {
+	case equalNames(name, a.enumerate) && wants(dnsmessage.TypePTR):
+		rs = append(rs, dnsmessage.Resource{Header: header(dnsmessage.TypePTR, ttl, false), Body: &dnsmessage.PTRResource{PTR: a.service}})
+	case equalNames(name, a.service) && wants(dnsmessage.TypePTR):
+		rs = append(rs, dnsmessage.Resource{Header: header(dnsmessage.TypePTR, ttl, false), Body: &dnsmessage.PTRResource{PTR: a.instance}})
+	case equalNames(name, a.instance):
+		if wants(dnsmessage.TypeSRV) {
+			rs = append(rs, dnsmessage.Resource{Header: header(dnsmessage.TypeSRV, hostTTL, true), Body: &dnsmessage.SRVResource{Target: a.host, Port: uint16(a.port)}})
+		}
+		if wants(dnsmessage.TypeTXT) {
+			txt := a.txt
+			if len(txt) == 0 {
+				txt = []string{""} // a TXT record holds at least one string
+			}
+			rs = append(rs, dnsmessage.Resource{Header: header(dnsmessage.TypeTXT, ttl, true), Body: &dnsmessage.TXTResource{TXT: txt}})
+		}
+	case equalNames(name, a.host) && wants(dnsmessage.TypeA):
+		for _, ip := range a.addresses() {
+			var addr [4]byte
+			copy(addr[:], ip)
+			rs = append(rs, dnsmessage.Resource{Header: header(dnsmessage.TypeA, hostTTL, true), Body: &dnsmessage.AResource{A: addr}})
+		}
+	}
+	return rs
+}
+
+// addresses returns the IPv4 addresses advertised for the host.
+func (a *Advertiser) addresses() []net.IP {
+	if a.ip != nil {
+		return []net.IP{a.ip}
+	}
+	var ips []net.IP
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.To4() != nil {
+				ips = append(ips, ipNet.IP.To4())
+			}
+		}
+	}
+	return ips
+}
+
+// equalNames compares DNS names case-insensitively.
+func equalNames(x, y dnsmessage.Name) bool {
+	return strings.EqualFold(x.String(), y.String())
+}
+
+// instanceLabel makes an instance name a single DNS label: dots, which
+// would split it, become spaces, and it is cut to the 63 bytes a label holds.
+func instanceLabel(s string) string {
+	s = strings.ReplaceAll(s, ".", " ")
+	for len(s) > 63 {
+		_, size := utf8.DecodeLastRuneInString(s)
+		s = s[:len(s)-size]
+	}
+	return s
+}
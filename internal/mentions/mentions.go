@@ -0,0 +1,47 @@
+// Package mentions finds @-mentions of agents in free text such as task
+// descriptions and comments. An agent is mentioned by "@" and its ID (e.g.
+// @jarvis) or by any of its mention patterns (e.g. "@researcher").
+package mentions
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// A handle follows "@" at the start of text or after a character that could
+// not end an email address, so "ops@example.com" mentions no one.
+var handle = regexp.MustCompile(`(?:^|[^\w@.+-])@([A-Za-z0-9][\w.-]*)`)
+
+// Find returns the distinct handles mentioned in text, lower-cased and
+// without the "@", in order of appearance. Whether they name an agent is up
+// to the caller.
+func Find(text string) []string {
+	seen := make(map[string]bool)
+	var handles []string
+	for _, m := range handle.FindAllStringSubmatch(text, -1) {
+		// Sentence punctuation ends a mention: "ask @jarvis." mentions jarvis
+		h := strings.ToLower(strings.TrimRight(m[1], ".-"))
+		if !seen[h] {
+			seen[h] = true
+			handles = append(handles, h)
+		}
+	}
+	return handles
+}
+
+// Handles returns the handles that mention an agent, lower-cased and
+// without the "@": its ID, and its mention patterns, a JSON array as stored
+// on the agent. Patterns that are not valid JSON are ignored.
+func Handles(agentID, patterns string) []string {
+	handles := []string{strings.ToLower(agentID)}
+	var list []string
+	if patterns != "" && json.Unmarshal([]byte(patterns), &list) == nil {
+		for _, p := range list {
+			if p = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(p), "@")); p != "" {
+				handles = append(handles, p)
+			}
+		}
+	}
+	return handles
+}
@@ -0,0 +1,343 @@
+// Package modelhealth probes the models configured in OpenClaw with a tiny
+// test call through the gateway, so dispatch can steer clear of agents whose
+// models are down. A model that answers slowly, or failed its last probe, is
+// degraded; one that failed twice in a row is unavailable until a probe
+// succeeds again.
+package modelhealth
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/leader"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+	ws "github.com/abelkuruvilla/claw-agent-mission-control/internal/websocket"
+)
+
+// Model states.
+const (
+	StateUnknown     = "unknown" // not probed yet
+	StateAvailable   = "available"
+	StateDegraded    = "degraded"
+	StateUnavailable = "unavailable"
+)
+
+// unavailableAfter is how many probes in a row must fail before a model is
+// unavailable.
+const unavailableAfter = 2
+
+// probePrompt is the test call; any reply will do.
+const probePrompt = "Health check from Mission Control. Reply with the single word OK."
+
+// pollInterval is how often a probe session is checked for its reply.
+const pollInterval = time.Second
+
+// Status is what the latest probes of a model found.
+type Status struct {
+	Model     string     `json:"model"`
+	State     string     `json:"state"`
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+	LatencyMs int64      `json:"latency_ms,omitempty"` // of the last successful probe
+	Failures  int        `json:"failures,omitempty"`   // probes failed in a row
+	Error     string     `json:"error,omitempty"`      // of the last failed probe
+}
+
+// Prober probes the configured models when asked and, while running, every
+// interval. A nil Prober knows nothing, so every model is usable.
+type Prober struct {
+	gateway  openclaw.Gateway
+	models   func() ([]openclaw.ModelConfig, error)
+	store    *store.Store
+	hub      *ws.Hub
+	interval time.Duration // 0 = on demand only
+	timeout  time.Duration // a probe without a reply by then failed
+	slow     time.Duration // a reply slower than this is degraded
+	leader   *leader.Elector
+
+	mu       sync.Mutex
+	statuses map[string]*Status
+	config   []openclaw.ModelConfig // as of the last probe run
+
+	runMu    sync.Mutex // one probe run at a time
+	stopChan chan struct{}
+	running  bool
+}
+
+// NewProber creates a Prober probing the models listed by models through
+// gateway, allowing each probe timeout and calling replies slower than slow
+// degraded.
+func NewProber(gateway openclaw.Gateway, models func() ([]openclaw.ModelConfig, error), st *store.Store, hub *ws.Hub, interval, timeout, slow time.Duration) *Prober {
+	return &Prober{
+		gateway:  gateway,
+		models:   models,
+		store:    st,
+		hub:      hub,
+		interval: interval,
+		timeout:  timeout,
+		slow:     slow,
+		statuses: make(map[string]*Status),
+		stopChan: make(chan struct{}),
+	}
+}
+
+// SetLeader sets the leader elector; scheduled probes only happen on the
+// leader.
+func (p *Prober) SetLeader(l *leader.Elector) {
+	p.leader = l
+}
+
+// Interval returns how often scheduled probes happen, 0 when they don't.
+func (p *Prober) Interval() time.Duration {
+	if p == nil {
+		return 0
+	}
+	return p.interval
+}
+
+// Status returns what is known of model, by ID.
+func (p *Prober) Status(model string) Status {
+	if p == nil {
+		return Status{Model: model, State: StateUnknown}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if st := p.statuses[model]; st != nil {
+		return *st
+	}
+	return Status{Model: model, State: StateUnknown}
+}
+
+// Usable reports whether an agent on model (an ID or alias; "" for the
+// default model) can get work: unless its model and every fallback of the
+// default model are unavailable. It also returns the models found down.
+func (p *Prober) Usable(model string) (bool, []string) {
+	if p == nil {
+		return true, nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var chain []string
+	resolve := func(name string) string {
+		for _, m := range p.config {
+			if m.ID == name || (m.Alias != "" && m.Alias == name) {
+				return m.ID
+			}
+		}
+		return name
+	}
+	if model != "" {
+		chain = append(chain, resolve(model))
+	}
+	for _, m := range p.config {
+		if (m.Default && model == "") || m.Fallback {
+			chain = append(chain, m.ID)
+		}
+	}
+	if len(chain) == 0 {
+		return true, nil
+	}
+	for _, id := range chain {
+		if st := p.statuses[id]; st == nil || st.State != StateUnavailable {
+			return true, nil
+		}
+	}
+	return false, chain
+}
+
+// Probe probes every configured model now, at the same time, and returns
+// their statuses by model ID.
+func (p *Prober) Probe(ctx context.Context) ([]Status, error) {
+	if p.gateway == nil {
+		return nil, errors.New("OpenClaw gateway not configured")
+	}
+	models, err := p.models()
+	if err != nil {
+		return nil, err
+	}
+	p.runMu.Lock()
+	defer p.runMu.Unlock()
+
+	p.mu.Lock()
+	p.config = models
+	p.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, m := range models {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			latency, err := p.probe(ctx, id)
+			p.record(ctx, id, latency, err)
+		}(m.ID)
+	}
+	wg.Wait()
+
+	statuses := make([]Status, len(models))
+	for i, m := range models {
+		statuses[i] = p.Status(m.ID)
+	}
+	return statuses, nil
+}
+
+// probe spawns a one-off session on model and waits for its reply.
+func (p *Prober) probe(ctx context.Context, model string) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	start := time.Now()
+	spawn, err := p.gateway.Spawn(ctx, &openclaw.SpawnRequest{
+		Task:           probePrompt,
+		Label:          fmt.Sprintf("model-probe-%s-%d", strings.ReplaceAll(model, "/", "-"), start.Unix()),
+		Model:          model,
+		Cleanup:        "delete",
+		TimeoutSeconds: int(p.timeout.Seconds()),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("spawn failed: %w", err)
+	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		if history, err := p.gateway.GetSessionHistory(ctx, spawn.ChildSessionKey, 0); err == nil {
+			for _, m := range history.Messages {
+				if m.Role == "assistant" && strings.TrimSpace(m.Content) != "" {
+					return time.Since(start), nil
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return 0, fmt.Errorf("no reply within %s", p.timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// record updates model's status with a probe's outcome, logging a
+// model_status_changed event when its state changes.
+func (p *Prober) record(ctx context.Context, model string, latency time.Duration, probeErr error) {
+	now := time.Now().UTC()
+	p.mu.Lock()
+	st := p.statuses[model]
+	if st == nil {
+		st = &Status{Model: model, State: StateUnknown}
+		p.statuses[model] = st
+	}
+	previous := st.State
+	st.CheckedAt = &now
+	switch {
+	case probeErr != nil:
+		st.Failures++
+		st.Error = probeErr.Error()
+		st.State = StateDegraded
+		if st.Failures >= unavailableAfter {
+			st.State = StateUnavailable
+		}
+	case p.slow > 0 && latency > p.slow:
+		st.Failures, st.Error = 0, ""
+		st.LatencyMs = latency.Milliseconds()
+		st.State = StateDegraded
+	default:
+		st.Failures, st.Error = 0, ""
+		st.LatencyMs = latency.Milliseconds()
+		st.State = StateAvailable
+	}
+	current := *st
+	p.mu.Unlock()
+
+	if probeErr != nil {
+		log.Printf("[ModelHealth] Probe of %s failed (%d in a row): %v", model, current.Failures, probeErr)
+	}
+	if current.State == previous || (previous == StateUnknown && current.State == StateAvailable) {
+		return
+	}
+	message := fmt.Sprintf("Model %s is %s (was %s)", model, current.State, previous)
+	if current.Error != "" {
+		message += ": " + current.Error
+	}
+	details, _ := json.Marshal(map[string]interface{}{
+		"model": model, "state": current.State, "previous": previous,
+		"latency_ms": current.LatencyMs, "error": current.Error,
+	})
+	p.logEvent(context.WithoutCancel(ctx), "model_status_changed", message, string(details))
+}
+
+// Start probes the models every interval until Stop or ctx is done, if an
+// interval is set.
+func (p *Prober) Start(ctx context.Context) {
+	if p.interval <= 0 {
+		return
+	}
+	if p.running {
+		log.Println("[ModelHealth] Already running")
+		return
+	}
+	p.running = true
+	log.Printf("[ModelHealth] Probing models every %s", p.interval)
+
+	go func() {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		p.runScheduled(ctx)
+		for {
+			select {
+			case <-ticker.C:
+				p.runScheduled(ctx)
+			case <-p.stopChan:
+				log.Println("[ModelHealth] Stopping")
+				p.running = false
+				return
+			case <-ctx.Done():
+				p.running = false
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops scheduled probes.
+func (p *Prober) Stop() {
+	if !p.running {
+		return
+	}
+	close(p.stopChan)
+	p.running = false
+}
+
+// Running reports whether the scheduled probing is running.
+func (p *Prober) Running() bool {
+	return p.running
+}
+
+func (p *Prober) runScheduled(ctx context.Context) {
+	if !p.leader.IsLeader() {
+		return
+	}
+	if _, err := p.Probe(ctx); err != nil {
+		log.Printf("[ModelHealth] Probe run failed: %v", err)
+	}
+}
+
+func (p *Prober) logEvent(ctx context.Context, eventType, message, details string) {
+	event, err := p.store.CreateEvent(ctx, db.CreateEventParams{
+		Type:    eventType,
+		Message: message,
+		Details: sql.NullString{String: details, Valid: details != ""},
+	})
+	if err != nil {
+		log.Printf("[ModelHealth] Failed to create event (%s): %v", eventType, err)
+		return
+	}
+	if p.hub != nil {
+		p.hub.BroadcastEvent(event)
+	}
+}
@@ -0,0 +1,80 @@
+// Package notifyprefs describes which notifications Mission Control pushes to
+// an agent. By default every kind is pushed; an agent can turn kinds off, or
+// take no pushes at all and pick up its work on heartbeat instead.
+package notifyprefs
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Notification kinds an agent can opt out of.
+const (
+	TaskAssignment    = "task_assignment"
+	SubtaskCompletion = "subtask_completion"
+	ReviewRequest     = "review_request"
+	Mention           = "mention"
+)
+
+// Prefs are an agent's notification preferences. QueueOnly overrides the
+// rest: assignments are queued for heartbeat pickup and nothing is pushed.
+type Prefs struct {
+	QueueOnly               bool `json:"queue_only"`
+	PushOnAssign            bool `json:"push_on_assign"`
+	PushOnSubtaskCompletion bool `json:"push_on_subtask_completion"`
+	PushOnReviewRequest     bool `json:"push_on_review_request"`
+	PushOnMention           bool `json:"push_on_mention"`
+}
+
+// Default pushes everything.
+func Default() Prefs {
+	return Prefs{
+		PushOnAssign:            true,
+		PushOnSubtaskCompletion: true,
+		PushOnReviewRequest:     true,
+		PushOnMention:           true,
+	}
+}
+
+// Parse decodes stored preferences. Keys that are missing keep their
+// default, and an empty string yields Default().
+func Parse(raw string) (Prefs, error) {
+	return Merge(Default(), raw)
+}
+
+// Merge applies the keys set in raw on top of p, so a partial object changes
+// only what it names. An empty string or null leaves p as is.
+func Merge(p Prefs, raw string) (Prefs, error) {
+	if strings.TrimSpace(raw) == "" || raw == "null" {
+		return p, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &p); err != nil {
+		return p, fmt.Errorf("invalid notification preferences: %w", err)
+	}
+	return p, nil
+}
+
+// Push reports whether notifications of kind are pushed to the agent.
+// Unknown kinds are pushed unless the agent is queue-only.
+func (p Prefs) Push(kind string) bool {
+	if p.QueueOnly {
+		return false
+	}
+	switch kind {
+	case TaskAssignment:
+		return p.PushOnAssign
+	case SubtaskCompletion:
+		return p.PushOnSubtaskCompletion
+	case ReviewRequest:
+		return p.PushOnReviewRequest
+	case Mention:
+		return p.PushOnMention
+	}
+	return true
+}
+
+// IsDefault reports whether p pushes everything, so nothing need be stored.
+func (p Prefs) IsDefault() bool {
+	return p == Default()
+}
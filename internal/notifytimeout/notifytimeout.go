@@ -0,0 +1,117 @@
+// Package notifytimeout decides how long each attempt to deliver a
+// notification to an agent may take. A big planning prompt needs longer than
+// a quick mention, so the timeout can be set per dispatch type, system-wide
+// (settings) and per agent, in seconds:
+//
+//	{"default_seconds": 300, "seconds": {"assignment": 900, "mention": 60}}
+//
+// The most specific setting wins: the agent's for the type, the agent's
+// default, the system's for the type, the system's default, then Fallback.
+package notifytimeout
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Dispatch types a timeout can be set for.
+const (
+	Assignment        = "assignment"         // a task sent to its agent
+	Renotify          = "renotify"           // a stuck task sent again, with its history
+	SubtaskCompletion = "subtask_completion" // a subtask result sent to the orchestrator
+	ReviewRequest     = "review_request"
+	Mention           = "mention"
+)
+
+// Types lists the dispatch types.
+var Types = []string{Assignment, Renotify, SubtaskCompletion, ReviewRequest, Mention}
+
+// Fallback is the timeout when nothing is set.
+const Fallback = 5 * time.Minute
+
+// maxSeconds caps a timeout at a day.
+const maxSeconds = 24 * 60 * 60
+
+// Timeouts are the notification timeouts of the system or an agent. Zero
+// means unset.
+type Timeouts struct {
+	DefaultSeconds int            `json:"default_seconds,omitempty"`
+	Seconds        map[string]int `json:"seconds,omitempty"` // by dispatch type
+}
+
+// Parse decodes and validates stored timeouts. An empty string or null
+// yields empty Timeouts.
+func Parse(raw string) (Timeouts, error) {
+	var t Timeouts
+	if strings.TrimSpace(raw) == "" || raw == "null" {
+		return t, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &t); err != nil {
+		return Timeouts{}, fmt.Errorf("invalid notification timeouts: %w", err)
+	}
+	if err := t.Validate(); err != nil {
+		return Timeouts{}, err
+	}
+	return t, nil
+}
+
+// Validate checks that every type is known and every timeout is between 0
+// (unset) and a day.
+func (t Timeouts) Validate() error {
+	if t.DefaultSeconds < 0 || t.DefaultSeconds > maxSeconds {
+		return fmt.Errorf("default_seconds must be between 0 and %d", maxSeconds)
+	}
+	for typ, s := range t.Seconds {
+		if !known(typ) {
+			return fmt.Errorf("unknown dispatch type %q: use one of %s", typ, strings.Join(Types, ", "))
+		}
+		if s < 0 || s > maxSeconds {
+			return fmt.Errorf("seconds.%s must be between 0 and %d", typ, maxSeconds)
+		}
+	}
+	return nil
+}
+
+func known(typ string) bool {
+	for _, t := range Types {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// Empty reports whether nothing is set, so nothing need be stored.
+func (t Timeouts) Empty() bool {
+	if t.DefaultSeconds != 0 {
+		return false
+	}
+	for _, s := range t.Seconds {
+		if s != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// lookup returns the timeout set for typ, else the default, else 0.
+func (t Timeouts) lookup(typ string) time.Duration {
+	if s := t.Seconds[typ]; s > 0 {
+		return time.Duration(s) * time.Second
+	}
+	return time.Duration(t.DefaultSeconds) * time.Second
+}
+
+// Resolve returns the timeout of a notification of typ to an agent with the
+// agent's and the system's timeouts.
+func Resolve(agent, system Timeouts, typ string) time.Duration {
+	if d := agent.lookup(typ); d > 0 {
+		return d
+	}
+	if d := system.lookup(typ); d > 0 {
+		return d
+	}
+	return Fallback
+}
@@ -0,0 +1,196 @@
+// Package objectstore keeps blobs (database backups, export bundles) on
+// local disk or in an S3-compatible bucket, chosen in settings, and hands out
+// presigned URLs to download them without further credentials.
+package objectstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Backends.
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+// ErrNotFound is returned for a key that holds no object.
+var ErrNotFound = errors.New("object not found")
+
+// DefaultURLTTL is how long download URLs work unless asked otherwise.
+const DefaultURLTTL = 15 * time.Minute
+
+// MaxURLTTL bounds how long download URLs work (the S3 limit).
+const MaxURLTTL = 7 * 24 * time.Hour
+
+// Object describes a stored object.
+type Object struct {
+	Key        string    `json:"key"`
+	Size       int64     `json:"size"`
+	ModifiedAt time.Time `json:"modified_at"`
+}
+
+// Store is a place objects are kept under slash-separated keys, e.g.
+// backups/mission-control-20240501T020000Z.db.
+type Store interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	List(ctx context.Context, prefix string) ([]Object, error)
+	Delete(ctx context.Context, key string) error
+	// URL returns a URL the object can be downloaded from, without other
+	// credentials, until ttl has passed.
+	URL(key string, ttl time.Duration) (string, error)
+	String() string
+}
+
+// Config is the object storage in settings. Credentials for S3 are not kept
+// here but in the environment (S3_ACCESS_KEY_ID, S3_SECRET_ACCESS_KEY).
+type Config struct {
+	Backend  string `json:"backend"`            // local | s3
+	Path     string `json:"path,omitempty"`     // local: directory; empty = objects/ next to the database
+	Endpoint string `json:"endpoint,omitempty"` // s3: e.g. http://minio:9000; empty = AWS S3
+	Region   string `json:"region,omitempty"`   // s3: default us-east-1
+	Bucket   string `json:"bucket,omitempty"`   // s3
+	Prefix   string `json:"prefix,omitempty"`   // s3: prefix of every key, e.g. mission-control/
+}
+
+// Credentials sign requests to S3.
+type Credentials struct {
+	AccessKey string
+	SecretKey string
+}
+
+// Parse reads a config stored as JSON; "" is the default local storage.
+func Parse(s string) (Config, error) {
+	cfg := Config{Backend: BackendLocal}
+	if strings.TrimSpace(s) == "" {
+		return cfg, nil
+	}
+	if err := json.Unmarshal([]byte(s), &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid storage settings: %w", err)
+	}
+	return cfg, cfg.Validate()
+}
+
+// Validate checks the config, defaulting the backend to local.
+func (c *Config) Validate() error {
+	switch c.Backend {
+	case "", BackendLocal:
+		c.Backend = BackendLocal
+	case BackendS3:
+		if c.Bucket == "" {
+			return fmt.Errorf("bucket is required for the s3 backend")
+		}
+		if c.Endpoint != "" && !strings.HasPrefix(c.Endpoint, "http://") && !strings.HasPrefix(c.Endpoint, "https://") {
+			return fmt.Errorf("endpoint must be an http(s) URL")
+		}
+	default:
+		return fmt.Errorf("unknown storage backend %q (want local or s3)", c.Backend)
+	}
+	return nil
+}
+
+// Open returns the storage cfg describes. defaultDir is where local storage
+// without a path keeps objects; signKey signs local download URLs.
+func Open(cfg Config, creds Credentials, defaultDir string, signKey []byte) (Store, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Backend == BackendS3 {
+		if creds.AccessKey == "" || creds.SecretKey == "" {
+			return nil, fmt.Errorf("S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY are required for the s3 backend")
+		}
+		return NewS3(cfg, creds), nil
+	}
+	dir := cfg.Path
+	if dir == "" {
+		dir = defaultDir
+	}
+	return NewLocal(dir, signKey), nil
+}
+
+// Live is the storage currently chosen in settings. It is a Store itself,
+// passing every call to the current backend, so users of the storage keep
+// working when the settings change.
+type Live struct {
+	mu       sync.RWMutex
+	store    Store
+	basePath string // prefixes download URLs on Mission Control itself
+}
+
+func NewLive(s Store) *Live {
+	return &Live{store: s}
+}
+
+// SetBasePath sets the path Mission Control is served under (BASE_PATH), so
+// download URLs of local storage, which are relative to its address, work
+// behind a reverse proxy.
+func (l *Live) SetBasePath(p string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.basePath = p
+}
+
+// Current returns the current backend.
+func (l *Live) Current() Store {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.store
+}
+
+// Swap replaces the backend; objects are not moved.
+func (l *Live) Swap(s Store) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.store = s
+}
+
+func (l *Live) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	return l.Current().Put(ctx, key, data, contentType)
+}
+
+func (l *Live) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return l.Current().Get(ctx, key)
+}
+
+func (l *Live) List(ctx context.Context, prefix string) ([]Object, error) {
+	return l.Current().List(ctx, prefix)
+}
+
+func (l *Live) Delete(ctx context.Context, key string) error {
+	return l.Current().Delete(ctx, key)
+}
+
+func (l *Live) URL(key string, ttl time.Duration) (string, error) {
+	u, err := l.Current().URL(key, ttl)
+	if err != nil || !strings.HasPrefix(u, "/") {
+		return u, err
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.basePath + u, nil
+}
+
+func (l *Live) String() string {
+	return l.Current().String()
+}
+
+// cleanKey rejects keys that could escape the storage root.
+func cleanKey(key string) (string, error) {
+	key = strings.TrimPrefix(key, "/")
+	if key == "" || strings.Contains(key, "\\") {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return "", fmt.Errorf("invalid object key %q", key)
+		}
+	}
+	return key, nil
+}
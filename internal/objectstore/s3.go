@@ -0,0 +1,303 @@
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// unsignedPayload stands in for the body hash of presigned URLs.
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// S3 keeps objects in a bucket of S3 or an S3-compatible store (MinIO, R2,
+// ...), addressed path-style (endpoint/bucket/key), which all of them accept.
+// Requests are signed with AWS Signature Version 4.
+type S3 struct {
+	endpoint string
+	region   string
+	bucket   string
+	prefix   string
+	creds    Credentials
+	client   *http.Client
+}
+
+func NewS3(cfg Config, creds Credentials) *S3 {
+	region := cfg.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	endpoint := strings.TrimSuffix(cfg.Endpoint, "/")
+	if endpoint == "" {
+		endpoint = "https://s3." + region + ".amazonaws.com"
+	}
+	prefix := strings.Trim(cfg.Prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	return &S3{
+		endpoint: endpoint,
+		region:   region,
+		bucket:   cfg.Bucket,
+		prefix:   prefix,
+		creds:    creds,
+		client:   &http.Client{Timeout: 5 * time.Minute},
+	}
+}
+
+func (s *S3) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	req, err := s.request(ctx, http.MethodPut, key, nil, data)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(req, data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := s.request(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	req, err := s.request(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req, nil)
+	if err != nil && err != ErrNotFound {
+		return err
+	}
+	if resp != nil {
+		resp.Body.Close()
+	}
+	return nil
+}
+
+// listResult is the part of a ListObjectsV2 response List reads.
+type listResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func (s *S3) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	query := url.Values{"list-type": {"2"}, "prefix": {s.prefix + prefix}}
+	for {
+		req, err := s.request(ctx, http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := s.do(req, nil)
+		if err != nil {
+			return nil, err
+		}
+		var result listResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("invalid S3 list response: %w", err)
+		}
+		for _, c := range result.Contents {
+			objects = append(objects, Object{Key: strings.TrimPrefix(c.Key, s.prefix), Size: c.Size, ModifiedAt: c.LastModified.UTC()})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+// URL returns a presigned GET URL for the object.
+func (s *S3) URL(key string, ttl time.Duration) (string, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now().UTC()
+	q := url.Values{
+		"X-Amz-Algorithm":     {"AWS4-HMAC-SHA256"},
+		"X-Amz-Credential":    {s.creds.AccessKey + "/" + s.scope(now)},
+		"X-Amz-Date":          {now.Format("20060102T150405Z")},
+		"X-Amz-Expires":       {strconv.Itoa(int(ttl.Seconds()))},
+		"X-Amz-SignedHeaders": {"host"},
+	}
+	u.RawQuery = canonicalQuery(q)
+	signature := s.signature(now, http.MethodGet, u, map[string]string{"host": u.Host}, unsignedPayload)
+	u.RawQuery += "&X-Amz-Signature=" + signature
+	return u.String(), nil
+}
+
+func (s *S3) String() string {
+	return "s3://" + s.bucket + "/" + s.prefix
+}
+
+// objectURL is the URL of key; "" is the bucket itself.
+func (s *S3) objectURL(key string) (*url.URL, error) {
+	if key != "" {
+		var err error
+		if key, err = cleanKey(key); err != nil {
+			return nil, err
+		}
+		key = s.prefix + key
+	}
+	u, err := url.Parse(s.endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint: %w", err)
+	}
+	u.Path += "/" + s.bucket + "/" + key
+	u.RawPath = uriEncode(u.Path, false)
+	return u, nil
+}
+
+func (s *S3) request(ctx context.Context, method, key string, query url.Values, body []byte) (*http.Request, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	if query != nil {
+		u.RawQuery = canonicalQuery(query)
+	}
+	return http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+}
+
+// do signs and sends req, turning error responses into errors.
+func (s *S3) do(req *http.Request, body []byte) (*http.Response, error) {
+	now := time.Now().UTC()
+	payloadHash := hexSHA256(body)
+	req.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(req.Header.Get(name))
+		}
+	}
+	signature := s.signature(now, req.Method, req.URL, headers, payloadHash)
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.creds.AccessKey, s.scope(now), strings.Join(sortedKeys(headers), ";"), signature))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound && req.Method != http.MethodPut {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("S3 returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
+
+func (s *S3) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + s.region + "/s3/aws4_request"
+}
+
+// signature is the Signature Version 4 signature of a request to u with the
+// given signed headers (lower-case names) and payload hash.
+func (s *S3) signature(now time.Time, method string, u *url.URL, headers map[string]string, payloadHash string) string {
+	names := sortedKeys(headers)
+	var canonHeaders strings.Builder
+	for _, name := range names {
+		canonHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	canonical := strings.Join([]string{
+		method,
+		uriEncode(u.Path, false),
+		u.RawQuery,
+		canonHeaders.String(),
+		strings.Join(names, ";"),
+		payloadHash,
+	}, "\n")
+
+	toSign := "AWS4-HMAC-SHA256\n" + now.Format("20060102T150405Z") + "\n" + s.scope(now) + "\n" + hexSHA256([]byte(canonical))
+	key := hmacSHA256([]byte("AWS4"+s.creds.SecretKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, toSign))
+}
+
+// canonicalQuery encodes q sorted by name, as signing requires.
+func canonicalQuery(q url.Values) string {
+	parts := make([]string, 0, len(q))
+	for _, name := range sortedKeys(q) {
+		for _, v := range q[name] {
+			parts = append(parts, uriEncode(name, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode percent-encodes all but unreserved characters, and slashes
+// unless encodeSlash is false.
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,166 @@
+package openclaw
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrWorkspaceExists is returned when cloning into an agent ID whose workspace
+// is already there.
+var ErrWorkspaceExists = errors.New("workspace already exists")
+
+// memoryDirName is the workspace directory of the daily memory notes.
+const memoryDirName = "memory"
+
+// cloneDirs are the workspace directories a clone gets besides the top-level
+// markdown files: the installed ClawHub skills and their lock file.
+var cloneDirs = []string{"skills", ".clawhub"}
+
+// CloneAgentRequest clones the agent whose workspace is SourceWorkspace.
+type CloneAgentRequest struct {
+	SourceID        string
+	SourceWorkspace string
+	ID              string
+	Name            string
+	Model           string
+	// IncludeMemory copies MEMORY.md and the memory/ daily notes; without it
+	// the clone starts with a fresh MEMORY.md
+	IncludeMemory bool
+}
+
+// CloneAgent registers a new agent with a copy of another agent's workspace:
+// its identity and other top-level markdown files, its skills and, if asked
+// for, its memory. Files are copied as they are, nothing is regenerated.
+func (c *AgentCreator) CloneAgent(req *CloneAgentRequest) (*CreatedAgent, error) {
+	workspacePath := filepath.Join(c.openclawDir, "workspace-"+req.ID)
+	agentDirPath := filepath.Join(c.openclawDir, "agents", req.ID, "agent")
+	if _, err := os.Stat(workspacePath); err == nil {
+		return nil, fmt.Errorf("%s: %w", workspacePath, ErrWorkspaceExists)
+	}
+
+	if err := os.MkdirAll(filepath.Join(workspacePath, memoryDirName), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+	if err := copyWorkspace(req.SourceWorkspace, workspacePath, req.IncludeMemory); err != nil {
+		os.RemoveAll(workspacePath)
+		return nil, fmt.Errorf("failed to copy workspace: %w", err)
+	}
+	if !req.IncludeMemory {
+		path := filepath.Join(workspacePath, "MEMORY.md")
+		if err := os.WriteFile(path, []byte(defaultMemoryMD(req.Name)), 0644); err != nil {
+			os.RemoveAll(workspacePath)
+			return nil, fmt.Errorf("failed to write MEMORY.md: %w", err)
+		}
+	}
+
+	agentDir, err := c.addAgent(req.ID, workspacePath, req.Model)
+	if err != nil {
+		os.RemoveAll(workspacePath)
+		return nil, err
+	}
+	if agentDir != "" {
+		agentDirPath = agentDir
+	}
+
+	initGit(workspacePath, fmt.Sprintf("Clone of agent %s via Mission Control", req.SourceID))
+
+	read := func(name string) string {
+		data, _ := os.ReadFile(filepath.Join(workspacePath, name))
+		return string(data)
+	}
+	return &CreatedAgent{
+		ID:            req.ID,
+		WorkspacePath: workspacePath,
+		AgentDirPath:  agentDirPath,
+		SoulMD:        read("SOUL.md"),
+		AgentsMD:      read("AGENTS.md"),
+		IdentityMD:    read("IDENTITY.md"),
+		UserMD:        read("USER.md"),
+		ToolsMD:       read("TOOLS.md"),
+		HeartbeatMD:   read("HEARTBEAT.md"),
+		MemoryMD:      read("MEMORY.md"),
+	}, nil
+}
+
+// copyWorkspace copies what a clone gets from the workspace src to dst.
+func copyWorkspace(src, dst string, includeMemory bool) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		name := e.Name()
+		switch {
+		case e.Type().IsRegular() && strings.HasSuffix(name, ".md"):
+			if name == "MEMORY.md" && !includeMemory {
+				continue
+			}
+			if err := copyFile(filepath.Join(src, name), filepath.Join(dst, name)); err != nil {
+				return err
+			}
+		case e.IsDir() && name == memoryDirName:
+			if includeMemory {
+				if err := copyTree(filepath.Join(src, name), filepath.Join(dst, name)); err != nil {
+					return err
+				}
+			}
+		case e.IsDir():
+			for _, dir := range cloneDirs {
+				if name == dir {
+					if err := copyTree(filepath.Join(src, name), filepath.Join(dst, name)); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// copyTree copies the directory src to dst, with its regular files and
+// subdirectories; symlinks and other special files are left out.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, 0755)
+		case d.Type().IsRegular():
+			return copyFile(path, target)
+		}
+		return nil
+	})
+}
+
+// copyFile copies the regular file src to dst, keeping its permissions.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
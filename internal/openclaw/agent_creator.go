@@ -1,6 +1,7 @@
 package openclaw
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -11,7 +12,8 @@ import (
 )
 
 type AgentCreator struct {
-	openclawDir string
+	openclawDir       string
+	identityGenerator *IdentityGenerator // see SetIdentityGenerator
 }
 
 func NewAgentCreator() *AgentCreator {
@@ -21,6 +23,23 @@ func NewAgentCreator() *AgentCreator {
 	}
 }
 
+// SetIdentityGenerator has identity files generated by the model through g;
+// without one, they come from GenerateIdentityFromDescription's templates.
+func (c *AgentCreator) SetIdentityGenerator(g *IdentityGenerator) {
+	c.identityGenerator = g
+}
+
+// GenerateIdentity generates identity files for req: by the model if there is
+// an identity generator, reporting how in the returned IdentityGeneration,
+// from templates otherwise (and a nil IdentityGeneration).
+func (c *AgentCreator) GenerateIdentity(ctx context.Context, req *GenerateIdentityRequest) (*GeneratedIdentity, *IdentityGeneration) {
+	if c.identityGenerator == nil {
+		return GenerateIdentityFromDescription(req), nil
+	}
+	identity, generation := c.identityGenerator.GenerateIdentity(ctx, req)
+	return identity, &generation
+}
+
 type CreateAgentRequest struct {
 	ID              string   `json:"id"`
 	Name            string   `json:"name"`
@@ -33,6 +52,12 @@ type CreateAgentRequest struct {
 	UserMD          string   `json:"user_md"`
 	ToolsMD         string   `json:"tools_md"`
 	HeartbeatMD     string   `json:"heartbeat_md"`
+	MemoryMD        string   `json:"memory_md"`
+	// ExtraFiles are more markdown files for the workspace, by name, e.g.
+	// from a TemplatePack
+	ExtraFiles map[string]string `json:"extra_files"`
+	// Skills are the ClawHub skills to install; nil = defaultClawHubSkills
+	Skills []string `json:"skills"`
 }
 
 type CreatedAgent struct {
@@ -47,6 +72,9 @@ type CreatedAgent struct {
 	ToolsMD     string `json:"tools_md"`
 	HeartbeatMD string `json:"heartbeat_md"`
 	MemoryMD    string `json:"memory_md"`
+	// IdentityGeneration reports how the identity files were generated; nil
+	// if none were
+	IdentityGeneration *IdentityGeneration `json:"identity_generation,omitempty"`
 }
 
 // Default clawhub skills to install for every new agent
@@ -56,20 +84,24 @@ var defaultClawHubSkills = []string{
 	"deep-research-pro",
 }
 
-func (c *AgentCreator) CreateAgent(req *CreateAgentRequest) (*CreatedAgent, error) {
+func (c *AgentCreator) CreateAgent(ctx context.Context, req *CreateAgentRequest) (*CreatedAgent, error) {
 	// 1. Generate paths
 	workspacePath := filepath.Join(c.openclawDir, "workspace-"+req.ID)
 	agentDirPath := filepath.Join(c.openclawDir, "agents", req.ID, "agent")
 
 	// 2. Generate identity files based on description (if no explicit files provided)
 	var generatedIdentity *GeneratedIdentity
+	var generation *IdentityGeneration
 	if req.Description != "" && req.SoulMD == "" && req.IdentityMD == "" && req.AgentsMD == "" {
 		// Generate custom identity using the description and GSD/Ralph principles
-		generatedIdentity = GenerateIdentityFromDescription(&GenerateIdentityRequest{
+		generatedIdentity, generation = c.GenerateIdentity(ctx, &GenerateIdentityRequest{
 			AgentName:   req.Name,
 			Description: req.Description,
 			Model:       req.Model,
 		})
+		if generation != nil {
+			log.Printf("[AgentCreator] Identity of agent %s: %s in %dms %s", req.ID, generation.Status, generation.DurationMs, generation.Error)
+		}
 	}
 
 	// 3. Determine final content for each file (priority: explicit > generated > default)
@@ -79,47 +111,25 @@ func (c *AgentCreator) CreateAgent(req *CreateAgentRequest) (*CreatedAgent, erro
 	finalUserMD := c.getIdentityContent(req.UserMD, generatedIdentity, "user", req.Name)
 	finalToolsMD := c.getIdentityContent(req.ToolsMD, generatedIdentity, "tools", req.Name)
 	finalHeartbeatMD := c.getIdentityContent(req.HeartbeatMD, generatedIdentity, "heartbeat", req.Name)
-	finalMemoryMD := c.getIdentityContent("", generatedIdentity, "memory", req.Name)
+	finalMemoryMD := c.getIdentityContent(req.MemoryMD, generatedIdentity, "memory", req.Name)
 
 	// 4. Create workspace directory first (openclaw agents add needs it to exist)
 	if err := os.MkdirAll(workspacePath, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create workspace: %w", err)
 	}
 
-	// 5. Use openclaw agents add command
-	args := []string{
-		"agents", "add", req.ID,
-		"--workspace", workspacePath,
-		"--non-interactive",
-		"--json",
-	}
-	if req.Model != "" {
-		args = append(args, "--model", req.Model)
-	}
-
-	cmd := exec.Command("openclaw", args...)
-	output, err := cmd.CombinedOutput()
+	// 5. Register the agent with openclaw agents add
+	agentDir, err := c.addAgent(req.ID, workspacePath, req.Model)
 	if err != nil {
 		// Clean up workspace if command fails
 		os.RemoveAll(workspacePath)
-		return nil, fmt.Errorf("openclaw agents add failed: %s - %w", string(output), err)
-	}
-
-	// 6. Parse the JSON output to get agent dir path
-	var addResult struct {
-		ID        string `json:"id"`
-		Workspace string `json:"workspace"`
-		AgentDir  string `json:"agentDir"`
-	}
-	if err := json.Unmarshal(output, &addResult); err != nil {
-		// If JSON parsing fails, use default paths
-		addResult.AgentDir = agentDirPath
+		return nil, err
 	}
-	if addResult.AgentDir != "" {
-		agentDirPath = addResult.AgentDir
+	if agentDir != "" {
+		agentDirPath = agentDir
 	}
 
-	// 7. Write identity files to workspace
+	// 6. Write identity files to workspace
 	files := map[string]string{
 		"SOUL.md":      finalSoulMD,
 		"AGENTS.md":    finalAgentsMD,
@@ -129,6 +139,11 @@ func (c *AgentCreator) CreateAgent(req *CreateAgentRequest) (*CreatedAgent, erro
 		"HEARTBEAT.md": finalHeartbeatMD,
 		"MEMORY.md":    finalMemoryMD,
 	}
+	for filename, content := range req.ExtraFiles {
+		if _, identity := files[filename]; !identity {
+			files[filename] = content
+		}
+	}
 
 	// Create memory directory
 	memoryDir := filepath.Join(workspacePath, "memory")
@@ -141,11 +156,68 @@ func (c *AgentCreator) CreateAgent(req *CreateAgentRequest) (*CreatedAgent, erro
 		}
 	}
 
-	// 8. Install ClawHub skills into workspace
-	c.installClawHubSkills(workspacePath)
+	// 7. Install ClawHub skills into workspace
+	skills := req.Skills
+	if skills == nil {
+		skills = defaultClawHubSkills
+	}
+	c.installClawHubSkills(workspacePath, skills)
+
+	// 8. Initialize git and commit
+	initGit(workspacePath, "Initial agent setup via Mission Control")
+
+	// 9. Return created agent with final identity content
+	return &CreatedAgent{
+		ID:                 req.ID,
+		WorkspacePath:      workspacePath,
+		AgentDirPath:       agentDirPath,
+		SoulMD:             finalSoulMD,
+		AgentsMD:           finalAgentsMD,
+		IdentityMD:         finalIdentityMD,
+		UserMD:             finalUserMD,
+		ToolsMD:            finalToolsMD,
+		HeartbeatMD:        finalHeartbeatMD,
+		MemoryMD:           finalMemoryMD,
+		IdentityGeneration: generation,
+	}, nil
+}
+
+// addAgent registers the agent id with its workspace through openclaw agents
+// add and returns its agent directory, "" if the output does not tell.
+func (c *AgentCreator) addAgent(id, workspacePath, model string) (string, error) {
+	args := []string{
+		"agents", "add", id,
+		"--workspace", workspacePath,
+		"--non-interactive",
+		"--json",
+	}
+	if model != "" {
+		args = append(args, "--model", model)
+	}
+
+	cmd := exec.Command("openclaw", args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("openclaw agents add failed: %s - %w", string(output), err)
+	}
+
+	// Parse the JSON output to get agent dir path
+	var addResult struct {
+		ID        string `json:"id"`
+		Workspace string `json:"workspace"`
+		AgentDir  string `json:"agentDir"`
+	}
+	if err := json.Unmarshal(output, &addResult); err != nil {
+		// If JSON parsing fails, the default path applies
+		return "", nil
+	}
+	return addResult.AgentDir, nil
+}
 
-	// 9. Initialize git and commit
-	cmd = exec.Command("git", "init")
+// initGit makes the workspace a git repository and commits everything in it;
+// failures are ignored, the workspace works without git.
+func initGit(workspacePath, message string) {
+	cmd := exec.Command("git", "init")
 	cmd.Dir = workspacePath
 	cmd.Run()
 
@@ -153,37 +225,23 @@ func (c *AgentCreator) CreateAgent(req *CreateAgentRequest) (*CreatedAgent, erro
 	cmd.Dir = workspacePath
 	cmd.Run()
 
-	cmd = exec.Command("git", "commit", "-m", "Initial agent setup via Mission Control")
+	cmd = exec.Command("git", "commit", "-m", message)
 	cmd.Dir = workspacePath
 	cmd.Run()
-
-	// 10. Return created agent with final identity content
-	return &CreatedAgent{
-		ID:            req.ID,
-		WorkspacePath: workspacePath,
-		AgentDirPath:  agentDirPath,
-		SoulMD:        finalSoulMD,
-		AgentsMD:      finalAgentsMD,
-		IdentityMD:    finalIdentityMD,
-		UserMD:        finalUserMD,
-		ToolsMD:       finalToolsMD,
-		HeartbeatMD:   finalHeartbeatMD,
-		MemoryMD:      finalMemoryMD,
-	}, nil
 }
 
-// installClawHubSkills installs default skills from ClawHub into the agent workspace.
+// installClawHubSkills installs skills from ClawHub into the agent workspace.
 // Skills are installed sequentially with delays and retries to avoid rate limiting.
-func (c *AgentCreator) installClawHubSkills(workspacePath string) {
+func (c *AgentCreator) installClawHubSkills(workspacePath string, skills []string) {
 	const (
 		maxRetries       = 3
 		initialBackoff   = 5 * time.Second
 		delayBetween     = 3 * time.Second
 	)
 
-	log.Printf("[ClawHub] Starting installation of %d skills into %s", len(defaultClawHubSkills), workspacePath)
+	log.Printf("[ClawHub] Starting installation of %d skills into %s", len(skills), workspacePath)
 
-	for i, skill := range defaultClawHubSkills {
+	for i, skill := range skills {
 		// Delay between successive skill installs to avoid rate limiting
 		if i > 0 {
 			log.Printf("[ClawHub] Waiting %v before next install to avoid rate limits...", delayBetween)
@@ -230,30 +288,50 @@ func (c *AgentCreator) installSkillWithRetry(workspacePath, skill string, maxRet
 	return lastErr
 }
 
-func (c *AgentCreator) DeleteAgent(agentID string) error {
-	// 1. Get workspace path before deletion
+// RemoveAgent removes the agent's OpenClaw config entry (through openclaw
+// agents delete, unless unregister is false because it is gone already), its
+// state directory and, if purgeWorkspace, its workspace; otherwise the
+// workspace is left as it is. Any failure is returned, so that the removal
+// can be retried.
+func (c *AgentCreator) RemoveAgent(agentID string, unregister, purgeWorkspace bool) error {
 	workspacePath := filepath.Join(c.openclawDir, "workspace-"+agentID)
 	agentStatePath := filepath.Join(c.openclawDir, "agents", agentID)
 
-	// 2. Use openclaw agents delete command to remove from config
-	cmd := exec.Command("openclaw", "agents", "delete", agentID, "--force", "--json")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("openclaw agents delete failed: %s - %w", string(output), err)
+	// 1. Use openclaw agents delete command to remove from config
+	if unregister {
+		// openclaw may remove the workspace with the agent; one to keep is
+		// moved out of its way and back
+		kept := ""
+		if !purgeWorkspace {
+			if _, err := os.Stat(workspacePath); err == nil {
+				kept = workspacePath + ".keep"
+				if err := os.Rename(workspacePath, kept); err != nil {
+					return fmt.Errorf("failed to set workspace aside: %w", err)
+				}
+			}
+		}
+		cmd := exec.Command("openclaw", "agents", "delete", agentID, "--force", "--json")
+		output, err := cmd.CombinedOutput()
+		if kept != "" {
+			if renameErr := os.Rename(kept, workspacePath); renameErr != nil {
+				return fmt.Errorf("failed to restore workspace from %s: %w", kept, renameErr)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("openclaw agents delete failed: %s - %w", string(output), err)
+		}
 	}
 
-	// 3. Explicitly remove workspace directory (fallback if openclaw didn't delete it)
-	if _, statErr := os.Stat(workspacePath); statErr == nil {
-		if removeErr := os.RemoveAll(workspacePath); removeErr != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to remove workspace %s: %v\n", workspacePath, removeErr)
+	// 2. Explicitly remove workspace directory (if openclaw didn't delete it)
+	if purgeWorkspace {
+		if err := os.RemoveAll(workspacePath); err != nil {
+			return fmt.Errorf("failed to remove workspace %s: %w", workspacePath, err)
 		}
 	}
 
-	// 4. Explicitly remove agent state directory (fallback if openclaw didn't delete it)
-	if _, statErr := os.Stat(agentStatePath); statErr == nil {
-		if removeErr := os.RemoveAll(agentStatePath); removeErr != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to remove agent state %s: %v\n", agentStatePath, removeErr)
-		}
+	// 3. Explicitly remove agent state directory (if openclaw didn't delete it)
+	if err := os.RemoveAll(agentStatePath); err != nil {
+		return fmt.Errorf("failed to remove agent state %s: %w", agentStatePath, err)
 	}
 
 	return nil
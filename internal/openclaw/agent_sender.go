@@ -1,13 +1,18 @@
 package openclaw
 
 import (
+	"bufio"
+	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os/exec"
 	"strings"
+	"sync/atomic"
 	"time"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/notifytimeout"
 )
 
 // AgentSendResult holds the structured output from `openclaw agent --json`
@@ -16,72 +21,444 @@ type AgentSendResult struct {
 	Media []string `json:"media,omitempty"`
 }
 
-// AgentSender sends messages directly to OpenClaw agents using the CLI.
-// Uses `openclaw agent --agent <id> --message <text>` to push task
-// notifications without polling.
+// AgentSender sends messages directly to OpenClaw agents. By default it uses
+// `openclaw agent --agent <id> --message <text>` to push task notifications
+// without polling; agents routed elsewhere (see SetRouteResolver) are reached
+// through the Transport registered for their delivery method.
 type AgentSender struct {
 	missionControlURL string
-	timeout           time.Duration
+	timeout           time.Duration // per attempt, unless timeoutFor says otherwise
+	timeoutFor        func(agentID, dispatch string) time.Duration
+	dryRun            *atomic.Bool    // global dry-run switch, shared by copies from For
+	forceDryRun       bool            // set on per-request copies returned by For
+	ctx               context.Context // sends run under it; set on copies returned by For
+	outbox            *Outbox
+	templates         *Templates
+	localeFor         func(agentID string) string
+	apiURLFor         func(agentID string) string
+	shortIDFor        func(taskID string) string
+	routeFor          func(agentID string) Route
+	secretsFor        SecretsResolver
+	pathsFor          func(taskID string) []string
+	summaryFor        func(taskID string) string
+	modelFor          func(taskID string) string
+	templateFor       func(taskID string) string
+	resultFor         func(subtaskID string) *SubtaskResult
+	transports        map[string]Transport
+	onSession         SessionObserver
+	limiter           RateLimiter
+	dedupe            *Deduper
+	sizeLimit         *SizeLimit
 }
 
 // AgentSendCallback is called asynchronously when the agent produces a result
-// or an error. Implementations should persist the result (e.g. as a comment
-// or progress update on the task).
+// or an error. Implementations should persist the result (e.g. as a task
+// result or progress update on the task).
 type AgentSendCallback func(taskID, agentID, reply string, err error)
 
 // NewAgentSender creates an AgentSender.
 // missionControlURL is the base URL agents can reach the MC API at
 // (e.g. "http://localhost:8080/api/v1").
 func NewAgentSender(missionControlURL string) *AgentSender {
-	timeout := 5 * time.Minute
 	return &AgentSender{
 		missionControlURL: missionControlURL,
-		timeout:           timeout,
-	}
-}
-
-// buildTaskMessage constructs the message to send to the agent about a new task assignment.
-func buildTaskMessage(taskID, title, description, missionControlURL string) string {
-	var sb strings.Builder
-	sb.WriteString("You have been assigned a new task in Mission Control.\n\n")
-	sb.WriteString("## Task Details\n")
-	sb.WriteString(fmt.Sprintf("- **Task ID:** %s\n", taskID))
-	sb.WriteString(fmt.Sprintf("- **Title:** %s\n", title))
-	if description != "" {
-		sb.WriteString(fmt.Sprintf("- **Description:** %s\n", description))
-	}
-	sb.WriteString("\n## API Endpoint\n")
-	sb.WriteString("Fetch full task details (including phases and stories) from:\n")
-	sb.WriteString(fmt.Sprintf("```\ncurl \"%s/tasks/%s?include=phases,stories\"\n```\n\n", missionControlURL, taskID))
-	sb.WriteString("## Instructions\n")
-	sb.WriteString("1. Read the full task details from the API above.\n")
-	sb.WriteString("2. Follow the GSD protocol to plan the work (Research → Requirements → Roadmap → Stories).\n")
-	sb.WriteString("3. Execute each story using the Ralph Loop (Pick → Implement → Test → Pass/Fail → Learn → Repeat).\n")
-	sb.WriteString("4. Update your task status and progress via the Mission Control API as you work.\n")
-	sb.WriteString(fmt.Sprintf("5. Report progress: `curl -X POST \"%s/tasks/%s/progress-txt\" -H 'Content-Type: application/json' -d '{\"content\": \"[timestamp] your update\"}'`\n", missionControlURL, taskID))
-	sb.WriteString(fmt.Sprintf("6. **CRITICAL — When complete, you MUST update status to `done`**: `curl -X PUT \"%s/tasks/%s/status\" -H 'Content-Type: application/json' -d '{\"status\": \"done\"}'`\n", missionControlURL, taskID))
-	sb.WriteString("   This triggers an automatic notification to the orchestrator agent who delegated this task. If you do not update the status, the orchestrator will never know you finished.\n")
-	return sb.String()
+		timeout:           notifytimeout.Fallback,
+		dryRun:            &atomic.Bool{},
+		outbox:            NewOutbox(defaultOutboxSize),
+		templates:         NewTemplates(""),
+		sizeLimit:         NewSizeLimit(DefaultMaxMessageSize),
+		transports: map[string]Transport{
+			DeliveryCLI:          CLITransport{},
+			DeliveryHTTPCallback: NewHTTPCallbackTransport(nil),
+		},
+	}
+}
+
+// SetTransport sets the transport used for agents whose delivery method is
+// method, e.g. a GatewayTransport for DeliveryGateway (which has none by
+// default). Call it before any notification is sent.
+func (s *AgentSender) SetTransport(method string, t Transport) {
+	s.transports[method] = t
+}
+
+// SetRouteResolver sets how an agent's delivery route is looked up. Without
+// one, every agent is reached through the CLI.
+func (s *AgentSender) SetRouteResolver(fn func(agentID string) Route) {
+	s.routeFor = fn
+}
+
+// agentRoute returns the delivery route for agentID.
+func (s *AgentSender) agentRoute(agentID string) Route {
+	var route Route
+	if s.routeFor != nil {
+		route = s.routeFor(agentID)
+	}
+	if route.Method == "" {
+		route.Method = DeliveryCLI
+	}
+	return route
+}
+
+// SetTimeoutResolver sets how long each attempt to deliver a notification of
+// a dispatch type (see the notifytimeout package) to an agent may take.
+// Without one, or when it returns 0, attempts time out after 5 minutes.
+func (s *AgentSender) SetTimeoutResolver(fn func(agentID, dispatch string) time.Duration) {
+	s.timeoutFor = fn
+}
+
+// attemptTimeout returns the timeout of an attempt to deliver a
+// notification of dispatch type to agentID.
+func (s *AgentSender) attemptTimeout(agentID, dispatch string) time.Duration {
+	if s.timeoutFor != nil {
+		if d := s.timeoutFor(agentID, dispatch); d > 0 {
+			return d
+		}
+	}
+	return s.timeout
+}
+
+// SetTemplates replaces the notification templates (see NewTemplates).
+func (s *AgentSender) SetTemplates(t *Templates) {
+	s.templates = t
+}
+
+// SetLocaleResolver sets how the notification locale for an agent is chosen
+// (typically the agent's configured locale, else the server default).
+func (s *AgentSender) SetLocaleResolver(fn func(agentID string) string) {
+	s.localeFor = fn
+}
+
+// SetAPIURLResolver sets how the API URL an agent reaches Mission Control
+// at is looked up, for agents that reach it by another URL than the one the
+// sender was made with. An empty result means that one.
+func (s *AgentSender) SetAPIURLResolver(fn func(agentID string) string) {
+	s.apiURLFor = fn
+}
+
+// SetShortIDResolver sets how a task's short ID (e.g. MC-142) is looked up
+// for notifications that mention the task.
+func (s *AgentSender) SetShortIDResolver(fn func(taskID string) string) {
+	s.shortIDFor = fn
+}
+
+// taskShortID returns the short ID of taskID, or "" if it is not known.
+func (s *AgentSender) taskShortID(taskID string) string {
+	if s.shortIDFor == nil {
+		return ""
+	}
+	return s.shortIDFor(taskID)
+}
+
+// SetSecretsResolver sets how the secrets injected into a task assignment
+// notification are looked up. Without one, no secrets are injected.
+func (s *AgentSender) SetSecretsResolver(fn SecretsResolver) {
+	s.secretsFor = fn
+}
+
+// taskSecrets returns the secrets to inject into taskID's notification to
+// agentID. Dry runs get none: the outbox must not hold secret values.
+func (s *AgentSender) taskSecrets(agentID, taskID string) []Secret {
+	if s.secretsFor == nil || s.isDryRun() {
+		return nil
+	}
+	return s.secretsFor(agentID, taskID)
+}
+
+// SetPathPolicyResolver sets how the paths an agent may touch for a task are
+// looked up (typically from the task's project). Without one, tasks carry no
+// path policy.
+func (s *AgentSender) SetPathPolicyResolver(fn func(taskID string) []string) {
+	s.pathsFor = fn
+}
+
+// taskAllowedPaths returns the paths the agent may touch for taskID, or nil
+// if it is unrestricted.
+func (s *AgentSender) taskAllowedPaths(taskID string) []string {
+	if s.pathsFor == nil {
+		return nil
+	}
+	return s.pathsFor(taskID)
+}
+
+// SetContextSummaryResolver sets how a task's context summary is looked up.
+// Without one, notifications carry no summary.
+func (s *AgentSender) SetContextSummaryResolver(fn func(taskID string) string) {
+	s.summaryFor = fn
+}
+
+// taskContextSummary returns the context summary of taskID, or "" if it has
+// none.
+func (s *AgentSender) taskContextSummary(taskID string) string {
+	if s.summaryFor == nil {
+		return ""
+	}
+	return s.summaryFor(taskID)
+}
+
+// SetModelResolver sets how the model a task runs on is chosen as it is
+// dispatched. Without one, agents stay on their own model.
+func (s *AgentSender) SetModelResolver(fn func(taskID string) string) {
+	s.modelFor = fn
+}
+
+// taskModel returns the model taskID is to run on, or "" for the agent's own.
+func (s *AgentSender) taskModel(taskID string) string {
+	if s.modelFor == nil {
+		return ""
+	}
+	return s.modelFor(taskID)
+}
+
+// SetAssignmentTemplateResolver sets how the task_assignment template text a
+// task is sent with is looked up, e.g. that of the experiment arm it was
+// assigned to. Without one, or where it returns "", the active template is
+// used.
+func (s *AgentSender) SetAssignmentTemplateResolver(fn func(taskID string) string) {
+	s.templateFor = fn
+}
+
+// taskAssignmentTemplate returns the template text taskID is to be sent
+// with, or "" for the active template.
+func (s *AgentSender) taskAssignmentTemplate(taskID string) string {
+	if s.templateFor == nil {
+		return ""
+	}
+	return s.templateFor(taskID)
+}
+
+// SetSubtaskResultResolver sets how what a specialist produced on a subtask
+// is looked up for the orchestrator's completion notification. Without one,
+// the notification only points at the subtask.
+func (s *AgentSender) SetSubtaskResultResolver(fn func(subtaskID string) *SubtaskResult) {
+	s.resultFor = fn
+}
+
+// subtaskResult returns the result of subtaskID, capped in size, or nil.
+func (s *AgentSender) subtaskResult(subtaskID string) *SubtaskResult {
+	if s.resultFor == nil {
+		return nil
+	}
+	return s.resultFor(subtaskID).capped(subtaskResultMaxBytes)
+}
+
+// SetSessionObserver sets the function told when a send to an agent starts
+// and finishes.
+func (s *AgentSender) SetSessionObserver(fn SessionObserver) {
+	s.onSession = fn
+}
+
+// SetRateLimiter sets the limiter sends wait on while the agent or its model
+// is rate limited, and report their outcome to.
+func (s *AgentSender) SetRateLimiter(l RateLimiter) {
+	s.limiter = l
+}
+
+// SetDeduper sets the Deduper suppressing repeated notifications.
+func (s *AgentSender) SetDeduper(d *Deduper) {
+	s.dedupe = d
+}
+
+// SetSizeLimit sets the SizeLimit keeping messages within a maximum size.
+func (s *AgentSender) SetSizeLimit(l *SizeLimit) {
+	s.sizeLimit = l
+}
+
+// MessageStats returns how many messages were sent to agents, and truncated,
+// since the process started.
+func (s *AgentSender) MessageStats() MessageStats {
+	return s.sizeLimit.Stats()
+}
+
+// waitRateLimit blocks while dispatch to agentID is held back by a rate
+// limit, or until ctx is done.
+func (s *AgentSender) waitRateLimit(ctx context.Context, agentID string) error {
+	if s.limiter == nil {
+		return nil
+	}
+	if until, blocked := s.limiter.BlockedUntil(agentID); blocked {
+		log.Printf("[AgentSender] Agent %s is rate limited, holding send until %s", agentID, until.Format(time.RFC3339))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Until(until)):
+		}
+	}
+	return nil
+}
+
+// observeSession reports the start of a session with agentID and returns the
+// function that reports its end.
+func (s *AgentSender) observeSession(agentID string) func() {
+	if s.onSession == nil {
+		return func() {}
+	}
+	s.onSession(agentID, true)
+	return func() { s.onSession(agentID, false) }
+}
+
+// agentLocale returns the locale to render notifications for agentID in.
+func (s *AgentSender) agentLocale(agentID string) string {
+	if s.localeFor == nil {
+		return ""
+	}
+	return s.localeFor(agentID)
+}
+
+// apiURL returns the API URL agentID reaches Mission Control at.
+func (s *AgentSender) apiURL(agentID string) string {
+	if s.apiURLFor != nil {
+		if url := s.apiURLFor(agentID); url != "" {
+			return url
+		}
+	}
+	return s.missionControlURL
+}
+
+// Templates returns the notification templates used by this sender.
+func (s *AgentSender) Templates() *Templates {
+	return s.templates
+}
+
+// SetDryRun toggles global dry-run mode. While enabled, every notification is
+// recorded to the outbox instead of invoking OpenClaw.
+func (s *AgentSender) SetDryRun(enabled bool) {
+	s.dryRun.Store(enabled)
+}
+
+// DryRun reports whether global dry-run mode is enabled.
+func (s *AgentSender) DryRun() bool {
+	return s.dryRun.Load()
+}
+
+// Outbox returns the log of notifications captured in dry-run mode.
+func (s *AgentSender) Outbox() *Outbox {
+	return s.outbox
+}
+
+// For returns the sender to use on behalf of ctx: a copy whose sends run
+// under ctx, stopping when it is cancelled or its deadline passes, and
+// which always records to the outbox when ctx was marked with WithDryRun.
+func (s *AgentSender) For(ctx context.Context) Sender {
+	bound := *s
+	bound.ctx = ctx
+	bound.forceDryRun = s.forceDryRun || IsDryRun(ctx)
+	return &bound
+}
+
+// context returns the context sends run under.
+func (s *AgentSender) context() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
+// isDryRun reports whether this sender should record instead of sending.
+func (s *AgentSender) isDryRun() bool {
+	return s.forceDryRun || s.dryRun.Load()
+}
+
+// deliver sends message to the agent with retries, each attempt bounded by
+// the timeout of its dispatch type, or records it to the outbox in dry-run
+// mode (returning an empty reply and no error). Messages too long are cut
+// to the size limit; cut is what was already cut from message to fit.
+func (s *AgentSender) deliver(kind, dispatch, agentID, taskID, message string, cut int) (string, error) {
+	if kind != "model_switch" && s.dedupe.Duplicate(kind, agentID, taskID, message) {
+		log.Printf("[AgentSender] Suppressed duplicate %s for agent %s (task %s): the same was just sent", kind, agentID, taskID)
+		return "", ErrDuplicate
+	}
+	message = s.sizeLimit.Fit(kind, message, cut, taskURL(s.apiURL(agentID), taskID))
+	if s.isDryRun() {
+		log.Printf("[AgentSender] Dry run: recording %s for agent %s (task %s) to outbox", kind, agentID, taskID)
+		s.outbox.Add(kind, agentID, taskID, message)
+		return "", nil
+	}
+	route := s.agentRoute(agentID)
+	transport, ok := s.transports[route.Method]
+	if !ok {
+		return "", fmt.Errorf("no transport for delivery method %q of agent %s", route.Method, agentID)
+	}
+	defer s.observeSession(agentID)()
+	return s.sendWithRetry(transport, route, s.attemptTimeout(agentID, dispatch), Delivery{Kind: kind, AgentID: agentID, TaskID: taskID, Message: message})
+}
+
+// buildTaskMessage renders the task_assignment template for a task assignment
+// in the agent's locale, or the task's own template text if it has one;
+// history is set when re-notifying, secretsFile when the secrets' values
+// are in a file rather than the message. It returns the message and how
+// much was cut from a description too long to fit the size limit.
+func (s *AgentSender) buildTaskMessage(agentID, taskID, title, description, model string, secrets []Secret, secretsFile string, history *TaskHistory) (string, int) {
+	return renderTaskAssignment(s.templates, s.taskAssignmentTemplate(taskID), s.agentLocale(agentID), TaskAssignmentData{
+		TaskID:            taskID,
+		ShortID:           s.taskShortID(taskID),
+		Title:             title,
+		Description:       description,
+		MissionControlURL: s.apiURL(agentID),
+		Secrets:           secrets,
+		SecretsFile:       secretsFile,
+		AllowedPaths:      s.taskAllowedPaths(taskID),
+		ContextSummary:    s.taskContextSummary(taskID),
+		Model:             model,
+		History:           history,
+	}, s.sizeLimit)
 }
 
 // newSessionCommand is the command sent to the agent to start a fresh session
 // so that previous task context does not carry over.
 const newSessionCommand = "/new"
 
+// modelCommand, followed by a model, switches the agent's session to that
+// model; it is sent ahead of assignments of tasks routed to one.
+const modelCommand = "/model"
+
 // NotifyAgentAsync sends a task assignment message to the specified agent
 // in a background goroutine. It first sends /new to start a fresh session,
 // then sends the task details. When the agent responds to the task message,
 // the callback is invoked with the reply text (or error). The caller should NOT block on this.
 func (s *AgentSender) NotifyAgentAsync(agentID, taskID, title, description string, callback AgentSendCallback) {
+	s.notifyTaskAsync(agentID, taskID, title, description, nil, callback)
+}
+
+// RenotifyAgentAsync sends a task the agent was already sent again, with the
+// task's history (see NotifyAgentAsync).
+func (s *AgentSender) RenotifyAgentAsync(agentID, taskID, title, description string, history *TaskHistory, callback AgentSendCallback) {
+	s.notifyTaskAsync(agentID, taskID, title, description, history, callback)
+}
+
+// notifyTaskAsync sends a task assignment, with history if not nil.
+func (s *AgentSender) notifyTaskAsync(agentID, taskID, title, description string, history *TaskHistory, callback AgentSendCallback) {
 	go func() {
 		log.Printf("[AgentSender] Sending task %s notification to agent %s", taskID, agentID)
 
 		// Note: /new is NOT sent here to allow the agent to continue from its previous context.
 		// This enables proper retry behavior for failed tasks.
 
-		message := buildTaskMessage(taskID, title, description, s.missionControlURL)
+		dispatch := notifytimeout.Assignment
+		if history != nil {
+			dispatch = notifytimeout.Renotify
+		}
+
+		model := s.taskModel(taskID)
+		if model != "" {
+			if _, err := s.deliver("model_switch", dispatch, agentID, taskID, modelCommand+" "+model, 0); err != nil {
+				log.Printf("[AgentSender] Failed to switch agent %s to model %s for task %s, sending it anyway: %v", agentID, model, taskID, err)
+			}
+		}
 
-		reply, err := s.sendToAgentWithRetry(agentID, message)
+		secrets := s.taskSecrets(agentID, taskID)
+		shown, secretsFile, removeSecrets, err := stageSecrets(s.agentRoute(agentID), secrets)
+		if err != nil {
+			log.Printf("[AgentSender] ERROR sending to agent %s for task %s: %v", agentID, taskID, err)
+			if callback != nil {
+				callback(taskID, agentID, "", err)
+			}
+			return
+		}
+		defer removeSecrets()
+		message, cut := s.buildTaskMessage(agentID, taskID, title, description, model, shown, secretsFile, history)
+
+		reply, err := s.deliver("task_assignment", dispatch, agentID, taskID, message, cut)
+		reply, err = redactSecrets(reply, secrets), redactError(err, secrets)
 		if err != nil {
 			log.Printf("[AgentSender] ERROR sending to agent %s for task %s: %v", agentID, taskID, err)
 		} else {
@@ -94,37 +471,26 @@ func (s *AgentSender) NotifyAgentAsync(agentID, taskID, title, description strin
 	}()
 }
 
-// buildSubtaskCompletionMessage constructs the message sent to the orchestrator
-// when a subtask reaches a terminal status (done/failed).
-func buildSubtaskCompletionMessage(
+// buildSubtaskCompletionMessage renders the subtask_completion template sent
+// to the orchestrator when a subtask reaches a terminal status (done/failed).
+func (s *AgentSender) buildSubtaskCompletionMessage(
+	orchestratorAgentID,
 	subtaskID, subtaskTitle, subtaskStatus,
 	parentTaskID, parentTaskTitle,
-	specialistAgentID, missionControlURL string,
+	specialistAgentID string,
 ) string {
-	var sb strings.Builder
-	sb.WriteString("A subtask you delegated has completed.\n\n")
-	sb.WriteString("## Subtask Result\n")
-	sb.WriteString(fmt.Sprintf("- **Subtask ID:** %s\n", subtaskID))
-	sb.WriteString(fmt.Sprintf("- **Title:** %s\n", subtaskTitle))
-	sb.WriteString(fmt.Sprintf("- **Status:** %s\n", subtaskStatus))
-	sb.WriteString(fmt.Sprintf("- **Completed by:** %s\n", specialistAgentID))
-	sb.WriteString(fmt.Sprintf("- **Parent Task ID:** %s\n", parentTaskID))
-	sb.WriteString(fmt.Sprintf("- **Parent Task Title:** %s\n", parentTaskTitle))
-
-	sb.WriteString("\n## Next Steps\n")
-	sb.WriteString("1. Read the subtask results and progress:\n")
-	sb.WriteString(fmt.Sprintf("```\ncurl \"%s/tasks/%s?include=phases,stories\"\n```\n", missionControlURL, subtaskID))
-	sb.WriteString("2. Check remaining subtasks:\n")
-	sb.WriteString(fmt.Sprintf("```\ncurl \"%s/tasks/%s/subtasks\"\n```\n", missionControlURL, parentTaskID))
-	sb.WriteString("3. Based on the results:\n")
-	if subtaskStatus == "done" {
-		sb.WriteString("   - If more work is needed, create the next subtask and assign to the appropriate agent.\n")
-		sb.WriteString("   - If all subtasks are complete, verify the results and update the parent task to `done`.\n")
-	} else {
-		sb.WriteString("   - Review the failure, re-scope if needed, and create a new subtask or mark the parent task as `failed`.\n")
-	}
-	sb.WriteString(fmt.Sprintf("4. Update parent task status: `curl -X PUT \"%s/tasks/%s/status\" -H 'Content-Type: application/json' -d '{\"status\": \"done\"}'`\n", missionControlURL, parentTaskID))
-	return sb.String()
+	return s.templates.renderOrDefault(TemplateSubtaskCompletion, s.agentLocale(orchestratorAgentID), SubtaskCompletionData{
+		SubtaskID:         subtaskID,
+		SubtaskShortID:    s.taskShortID(subtaskID),
+		SubtaskTitle:      subtaskTitle,
+		SubtaskStatus:     subtaskStatus,
+		ParentTaskID:      parentTaskID,
+		ParentShortID:     s.taskShortID(parentTaskID),
+		ParentTaskTitle:   parentTaskTitle,
+		SpecialistAgentID: specialistAgentID,
+		MissionControlURL: s.apiURL(orchestratorAgentID),
+		Result:            s.subtaskResult(subtaskID),
+	})
 }
 
 // NotifySubtaskCompletionAsync sends a message to the orchestrator agent
@@ -141,13 +507,14 @@ func (s *AgentSender) NotifySubtaskCompletionAsync(
 		log.Printf("[AgentSender] Notifying orchestrator %s: subtask %s (%s) completed with status %s",
 			orchestratorAgentID, subtaskID, subtaskTitle, subtaskStatus)
 
-		message := buildSubtaskCompletionMessage(
+		message := s.buildSubtaskCompletionMessage(
+			orchestratorAgentID,
 			subtaskID, subtaskTitle, subtaskStatus,
 			parentTaskID, parentTaskTitle,
-			specialistAgentID, s.missionControlURL,
+			specialistAgentID,
 		)
 
-		reply, err := s.sendToAgentWithRetry(orchestratorAgentID, message)
+		reply, err := s.deliver("subtask_completion", notifytimeout.SubtaskCompletion, orchestratorAgentID, parentTaskID, message, 0)
 		if err != nil {
 			log.Printf("[AgentSender] ERROR notifying orchestrator %s about subtask %s: %v",
 				orchestratorAgentID, subtaskID, err)
@@ -162,82 +529,254 @@ func (s *AgentSender) NotifySubtaskCompletionAsync(
 	}()
 }
 
+// NotifyReviewRequestAsync sends the review_request message asking
+// reviewerAgentID to review agentID's work on a task, with what it produced.
+func (s *AgentSender) NotifyReviewRequestAsync(reviewerAgentID, taskID, title, description, agentID, gitBranch string, callback AgentSendCallback) {
+	go func() {
+		log.Printf("[AgentSender] Asking agent %s to review task %s by %s", reviewerAgentID, taskID, agentID)
+
+		message := s.templates.renderOrDefault(TemplateReviewRequest, s.agentLocale(reviewerAgentID), ReviewRequestData{
+			TaskID:            taskID,
+			ShortID:           s.taskShortID(taskID),
+			Title:             title,
+			Description:       description,
+			AgentID:           agentID,
+			GitBranch:         gitBranch,
+			MissionControlURL: s.apiURL(reviewerAgentID),
+			Result:            s.subtaskResult(taskID),
+		})
+
+		reply, err := s.deliver("review_request", notifytimeout.ReviewRequest, reviewerAgentID, taskID, message, 0)
+		if err != nil {
+			log.Printf("[AgentSender] ERROR asking agent %s to review task %s: %v", reviewerAgentID, taskID, err)
+		} else {
+			log.Printf("[AgentSender] Agent %s acknowledged review of task %s (reply length: %d)", reviewerAgentID, taskID, len(reply))
+		}
+
+		if callback != nil {
+			callback(taskID, reviewerAgentID, reply, err)
+		}
+	}()
+}
+
+// NotifyMentionAsync sends the mention message telling agentID it was
+// @-mentioned on a task.
+func (s *AgentSender) NotifyMentionAsync(agentID, taskID, title, author, source, text string, callback AgentSendCallback) {
+	go func() {
+		log.Printf("[AgentSender] Telling agent %s of its mention in the %s of task %s", agentID, source, taskID)
+
+		message := s.templates.renderOrDefault(TemplateMention, s.agentLocale(agentID), MentionData{
+			TaskID:            taskID,
+			ShortID:           s.taskShortID(taskID),
+			Title:             title,
+			Author:            author,
+			Source:            source,
+			Text:              truncateText(text, mentionTextMaxBytes),
+			MissionControlURL: s.apiURL(agentID),
+		})
+
+		reply, err := s.deliver("mention", notifytimeout.Mention, agentID, taskID, message, 0)
+		if err != nil {
+			log.Printf("[AgentSender] ERROR telling agent %s of its mention on task %s: %v", agentID, taskID, err)
+		} else {
+			log.Printf("[AgentSender] Agent %s acknowledged its mention on task %s (reply length: %d)", agentID, taskID, len(reply))
+		}
+
+		if callback != nil {
+			callback(taskID, agentID, reply, err)
+		}
+	}()
+}
+
 // isRetryableError returns true if the error is likely transient
-// (session locked, timeout) and the send should be retried.
+// (session locked, timeout, or marked Transient by the transport) and the
+// send should be retried.
 func isRetryableError(err error) bool {
 	if err == nil {
 		return false
 	}
+	var transient *transientError
+	if errors.As(err, &transient) {
+		return true
+	}
 	msg := err.Error()
 	return strings.Contains(msg, "session file locked") ||
 		strings.Contains(msg, "timed out") ||
 		strings.Contains(msg, "All models failed")
 }
 
-// sendToAgentWithRetry wraps sendToAgent with exponential backoff retry.
-func (s *AgentSender) sendToAgentWithRetry(agentID, message string) (string, error) {
-	const maxRetries = 10
-	const initialBackoff = 30 * time.Second
-	const maxBackoff = 5 * time.Minute
+// How sendWithRetry retries a delivery: up to maxRetries attempts, waiting
+// a backoff doubling from initialBackoff up to maxBackoff in between.
+const (
+	maxRetries     = 10
+	initialBackoff = 30 * time.Second
+	maxBackoff     = 5 * time.Minute
+)
 
+// SendBudget is the longest a delivery can take, retries and backoff
+// included, when each attempt may take attempt. Waits for a rate limit to
+// lift are not counted.
+func SendBudget(attempt time.Duration) time.Duration {
+	budget := maxRetries * attempt
+	backoff := initialBackoff
+	for i := 1; i < maxRetries; i++ {
+		budget += backoff
+		backoff = min(backoff*2, maxBackoff)
+	}
+	return budget
+}
+
+// sendWithRetry sends d over transport with exponential backoff retry, each
+// attempt bounded by timeout. Rate-limited sends wait out the limiter's
+// shared cool-down instead.
+func (s *AgentSender) sendWithRetry(transport Transport, route Route, timeout time.Duration, d Delivery) (string, error) {
 	backoff := initialBackoff
 	var lastErr error
+	base := s.context()
 
 	for attempt := 1; attempt <= maxRetries; attempt++ {
-		reply, err := s.sendToAgent(agentID, message)
+		if err := s.waitRateLimit(base, d.AgentID); err != nil {
+			return "", fmt.Errorf("send to agent %s abandoned: %w", d.AgentID, context.Cause(base))
+		}
+		if base.Err() != nil {
+			return "", fmt.Errorf("send to agent %s abandoned: %w", d.AgentID, context.Cause(base))
+		}
+		ctx, cancel := context.WithTimeout(base, timeout)
+		reply, err := transport.Send(ctx, route, d)
+		cancel()
+		if base.Err() != nil {
+			return "", fmt.Errorf("send to agent %s abandoned: %w", d.AgentID, context.Cause(base))
+		}
+		var limitedUntil time.Time
+		var limited bool
+		if s.limiter != nil {
+			limitedUntil, limited = s.limiter.Observe(d.AgentID, err)
+		}
 		if err == nil {
 			if attempt > 1 {
-				log.Printf("[AgentSender] Agent %s succeeded on attempt %d", agentID, attempt)
+				log.Printf("[AgentSender] Agent %s succeeded on attempt %d", d.AgentID, attempt)
 			}
 			return reply, nil
 		}
 
 		lastErr = err
+		if limited {
+			// Wait out the shared cool-down rather than a backoff of our own
+			if attempt < maxRetries {
+				log.Printf("[AgentSender] Agent %s rate limited via %s (attempt %d/%d), retrying after %s",
+					d.AgentID, route.Method, attempt, maxRetries, limitedUntil.Format(time.RFC3339))
+			}
+			continue
+		}
 		if !isRetryableError(err) {
-			log.Printf("[AgentSender] Non-retryable error sending to agent %s: %v", agentID, err)
+			log.Printf("[AgentSender] Non-retryable error sending to agent %s via %s: %v", d.AgentID, route.Method, err)
 			return "", err
 		}
 
 		if attempt < maxRetries {
-			log.Printf("[AgentSender] Agent %s session locked/busy (attempt %d/%d), retrying in %v",
-				agentID, attempt, maxRetries, backoff)
-			time.Sleep(backoff)
+			log.Printf("[AgentSender] Agent %s busy or unreachable via %s (attempt %d/%d), retrying in %v",
+				d.AgentID, route.Method, attempt, maxRetries, backoff)
+			select {
+			case <-time.After(backoff):
+			case <-base.Done():
+			}
 			backoff = min(backoff*2, maxBackoff)
 		}
 	}
 
-	return "", fmt.Errorf("agent %s failed after %d attempts: %w", agentID, maxRetries, lastErr)
+	return "", fmt.Errorf("agent %s failed after %d attempts: %w", d.AgentID, maxRetries, lastErr)
 }
 
-// sendToAgent executes `openclaw agent --agent <id> --message <text> --json`
-// and returns the agent's reply text.
-func (s *AgentSender) sendToAgent(agentID, message string) (string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+// ErrRunNeedsReply is returned for one-shot runs on agents whose delivery
+// method does not carry the agent's reply back (see CanRun).
+var ErrRunNeedsReply = errors.New("delivery method returns no reply, so one-shot runs are not possible")
+
+// CanRun reports whether agents reached by delivery method can take one-shot
+// runs: a run is only useful if its answer comes back, and Gateway
+// deliveries return none.
+func CanRun(method string) bool {
+	return method != DeliveryGateway
+}
+
+// RunCommand sends a one-shot instruction to an agent outside of any task
+// and streams each line of output to onOutput as it arrives. Agents reached
+// through the CLI stream line by line; http_callback agents answer all at
+// once, and Gateway agents cannot be run (ErrRunNeedsReply). Unlike task
+// notifications, runs are not retried: the caller sees the first failure.
+func (s *AgentSender) RunCommand(ctx context.Context, agentID, prompt string, timeout time.Duration, onOutput func(line string)) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	args := []string{
-		"agent",
-		"--agent", agentID,
-		"--message", message,
-		"--json",
+	route := s.agentRoute(agentID)
+	if !CanRun(route.Method) {
+		return "", fmt.Errorf("agent %s is reached through %s: %w", agentID, route.Method, ErrRunNeedsReply)
 	}
 
-	log.Printf("[AgentSender] Executing: openclaw %s", strings.Join(args[:3], " "))
+	if s.isDryRun() {
+		log.Printf("[AgentSender] Dry run: recording one-shot instruction for agent %s to outbox", agentID)
+		s.outbox.Add("agent_run", agentID, "", prompt)
+		return "", nil
+	}
+
+	log.Printf("[AgentSender] Running one-shot instruction on agent %s (timeout %v)", agentID, timeout)
+	defer s.observeSession(agentID)()
 
-	cmd := exec.CommandContext(ctx, "openclaw", args...)
-	output, err := cmd.CombinedOutput()
+	if route.Method != DeliveryCLI {
+		return s.runThroughTransport(ctx, route, agentID, prompt, timeout, onOutput)
+	}
+
+	cmd := exec.CommandContext(ctx, "openclaw", "agent", "--agent", agentID, "--message", prompt)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			return "", fmt.Errorf("agent send timed out after %v: %w", s.timeout, err)
+		return "", fmt.Errorf("failed to open agent output: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("failed to start openclaw agent: %w", err)
+	}
+
+	var output strings.Builder
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		output.WriteString(line)
+		output.WriteString("\n")
+		if onOutput != nil {
+			onOutput(line)
 		}
-		return "", fmt.Errorf("openclaw agent send failed: %s - %w", string(output), err)
 	}
 
-	var result AgentSendResult
-	if err := json.Unmarshal(output, &result); err != nil {
-		log.Printf("[AgentSender] Could not parse JSON response, using raw output (len=%d)", len(output))
-		return strings.TrimSpace(string(output)), nil
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return output.String(), fmt.Errorf("agent run timed out after %v: %w", timeout, err)
+		}
+		return output.String(), fmt.Errorf("openclaw agent run failed: %s - %w", strings.TrimSpace(stderr.String()), err)
 	}
 
-	return result.Reply, nil
+	return strings.TrimSpace(output.String()), nil
+}
+
+// runThroughTransport runs a one-shot instruction on an agent that is not
+// reached through the CLI, passing each line of its reply to onOutput.
+func (s *AgentSender) runThroughTransport(ctx context.Context, route Route, agentID, prompt string, timeout time.Duration, onOutput func(line string)) (string, error) {
+	transport, ok := s.transports[route.Method]
+	if !ok {
+		return "", fmt.Errorf("no transport for delivery method %q of agent %s", route.Method, agentID)
+	}
+	reply, err := transport.Send(ctx, route, Delivery{Kind: "agent_run", AgentID: agentID, Message: prompt})
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("agent run timed out after %v: %w", timeout, err)
+		}
+		return "", fmt.Errorf("agent run failed: %w", err)
+	}
+	reply = strings.TrimSpace(reply)
+	if onOutput != nil && reply != "" {
+		for _, line := range strings.Split(reply, "\n") {
+			onOutput(line)
+		}
+	}
+	return reply, nil
 }
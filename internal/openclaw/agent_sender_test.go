@@ -0,0 +1,100 @@
+package openclaw
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// replyingTransport answers every delivery with reply, keeping the last one.
+type replyingTransport struct {
+	reply string
+	last  Delivery
+}
+
+func (t *replyingTransport) Send(ctx context.Context, route Route, d Delivery) (string, error) {
+	t.last = d
+	return t.reply, nil
+}
+
+func TestRunCommandFollowsRoute(t *testing.T) {
+	callback := &replyingTransport{reply: "two PRs open\nboth green\n"}
+	s := NewAgentSender("http://127.0.0.1:8080/api/v1")
+	s.SetTransport(DeliveryHTTPCallback, callback)
+	s.SetRouteResolver(func(agentID string) Route {
+		if agentID == "remote" {
+			return Route{Method: DeliveryGateway}
+		}
+		return Route{Method: DeliveryHTTPCallback, CallbackURL: "https://agent.example.com/hook"}
+	})
+
+	var lines []string
+	reply, err := s.RunCommand(context.Background(), "builder", "Summarize the open PRs", time.Minute, func(line string) {
+		lines = append(lines, line)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply != "two PRs open\nboth green" || strings.Join(lines, "|") != "two PRs open|both green" {
+		t.Errorf("reply %q streamed as %q", reply, lines)
+	}
+	if callback.last.Kind != "agent_run" || callback.last.Message != "Summarize the open PRs" {
+		t.Errorf("callback sent %+v", callback.last)
+	}
+
+	if _, err := s.RunCommand(context.Background(), "remote", "Summarize the open PRs", time.Minute, nil); !errors.Is(err, ErrRunNeedsReply) {
+		t.Fatalf("gateway run: err = %v, want ErrRunNeedsReply", err)
+	}
+}
+
+// blockedLimiter holds back every send for an hour.
+type blockedLimiter struct{}
+
+func (blockedLimiter) BlockedUntil(agentID string) (time.Time, bool) {
+	return time.Now().Add(time.Hour), true
+}
+
+func (blockedLimiter) Observe(agentID string, err error) (time.Time, bool) {
+	return time.Time{}, false
+}
+
+// countingTransport counts the sends that reach it.
+type countingTransport struct{ sends int }
+
+func (t *countingTransport) Send(ctx context.Context, route Route, d Delivery) (string, error) {
+	t.sends++
+	return "", nil
+}
+
+func TestRateLimitWaitEndsWithContext(t *testing.T) {
+	transport := &countingTransport{}
+	s := NewAgentSender("http://127.0.0.1:8080/api/v1")
+	s.SetTransport(DeliveryCLI, transport)
+	s.SetRateLimiter(blockedLimiter{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	bound := s.For(ctx).(*AgentSender)
+
+	start := time.Now()
+	_, err := bound.sendWithRetry(transport, Route{Method: DeliveryCLI}, time.Minute, Delivery{Kind: "task_assignment", AgentID: "dev"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want the context's deadline", err)
+	}
+	if waited := time.Since(start); waited > 5*time.Second {
+		t.Fatalf("send waited %s for the rate limit after its context ended", waited)
+	}
+	if transport.sends != 0 {
+		t.Fatalf("%d sends reached the transport while rate limited", transport.sends)
+	}
+}
+
+func TestSendBudget(t *testing.T) {
+	// 10 attempts, and 9 backoffs: 30s, 1m, 2m, 4m, then 5m each
+	want := 10*5*time.Minute + 30*time.Second + time.Minute + 2*time.Minute + 4*time.Minute + 5*5*time.Minute
+	if got := SendBudget(5 * time.Minute); got != want {
+		t.Fatalf("SendBudget(5m) = %s, want %s", got, want)
+	}
+}
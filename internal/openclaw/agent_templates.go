@@ -0,0 +1,231 @@
+package openclaw
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// packManifest is the file of a template pack holding everything but its
+// markdown templates.
+const packManifest = "pack.json"
+
+// maxPackFileSize caps each markdown template of a pack.
+const maxPackFileSize = 256 * 1024
+
+var (
+	// ErrPackNotFound is returned for a template pack that does not exist.
+	ErrPackNotFound = errors.New("template pack not found")
+	// ErrBadPackName is returned for a pack name that is not a slug.
+	ErrBadPackName = errors.New("template pack name must be lowercase letters, digits, - and _ (at most 64)")
+)
+
+var packNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// TemplatePack is a named set of workspace files for new agents, e.g.
+// "frontend" or "sre": markdown templates (SOUL.md, AGENTS.md, ... or any
+// other top-level .md file) and the ClawHub skills to install. Templates are
+// Go text/template with the agent's .ID, .Name, .Description and .Model.
+type TemplatePack struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Model       string            `json:"model,omitempty"` // used when the agent asks for none
+	Skills      []string          `json:"skills"`          // installed instead of the default skills; null = the defaults
+	Files       map[string]string `json:"files,omitempty"` // file name -> template
+	FileNames   []string          `json:"file_names"`      // sorted names of Files
+}
+
+// packManifestData is what pack.json holds.
+type packManifestData struct {
+	Description string   `json:"description,omitempty"`
+	Model       string   `json:"model,omitempty"`
+	Skills      []string `json:"skills"`
+}
+
+// TemplateData is what a pack's templates are rendered with.
+type TemplateData struct {
+	ID          string
+	Name        string
+	Description string
+	Model       string
+}
+
+// TemplatePacks keeps template packs as directories of dir:
+// <dir>/<name>/pack.json and <dir>/<name>/*.md.
+type TemplatePacks struct {
+	dir string
+}
+
+// NewTemplatePacks returns the template packs kept in dir.
+func NewTemplatePacks(dir string) *TemplatePacks {
+	return &TemplatePacks{dir: dir}
+}
+
+// Dir returns the directory the packs are kept in.
+func (p *TemplatePacks) Dir() string {
+	return p.dir
+}
+
+// List returns the packs, by name, without their file contents.
+func (p *TemplatePacks) List() ([]TemplatePack, error) {
+	entries, err := os.ReadDir(p.dir)
+	if os.IsNotExist(err) {
+		return []TemplatePack{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	packs := []TemplatePack{}
+	for _, e := range entries {
+		if !e.IsDir() || !packNamePattern.MatchString(e.Name()) {
+			continue
+		}
+		pack, err := p.Get(e.Name())
+		if err != nil {
+			return nil, fmt.Errorf("template pack %s: %w", e.Name(), err)
+		}
+		pack.Files = nil
+		packs = append(packs, pack)
+	}
+	return packs, nil
+}
+
+// Get returns the pack name with its file contents.
+func (p *TemplatePacks) Get(name string) (TemplatePack, error) {
+	if !packNamePattern.MatchString(name) {
+		return TemplatePack{}, ErrBadPackName
+	}
+	dir := filepath.Join(p.dir, name)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return TemplatePack{}, ErrPackNotFound
+	}
+	if err != nil {
+		return TemplatePack{}, err
+	}
+	pack := TemplatePack{Name: name, Files: map[string]string{}, FileNames: []string{}}
+	if data, err := os.ReadFile(filepath.Join(dir, packManifest)); err == nil {
+		var manifest packManifestData
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return TemplatePack{}, fmt.Errorf("invalid %s: %w", packManifest, err)
+		}
+		pack.Description, pack.Model, pack.Skills = manifest.Description, manifest.Model, manifest.Skills
+	} else if !os.IsNotExist(err) {
+		return TemplatePack{}, err
+	}
+	for _, e := range entries {
+		if !e.Type().IsRegular() || !validPackFile(e.Name()) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return TemplatePack{}, err
+		}
+		pack.Files[e.Name()] = string(content)
+		pack.FileNames = append(pack.FileNames, e.Name())
+	}
+	sort.Strings(pack.FileNames)
+	return pack, nil
+}
+
+// Save creates or replaces the pack; files it had before that pack.Files
+// leaves out are removed. It returns the pack as saved.
+func (p *TemplatePacks) Save(pack TemplatePack) (TemplatePack, error) {
+	if err := ValidateTemplatePack(pack); err != nil {
+		return TemplatePack{}, err
+	}
+	dir := filepath.Join(p.dir, pack.Name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return TemplatePack{}, err
+	}
+	manifest, err := json.MarshalIndent(packManifestData{Description: pack.Description, Model: pack.Model, Skills: pack.Skills}, "", "  ")
+	if err != nil {
+		return TemplatePack{}, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, packManifest), append(manifest, '\n'), 0o644); err != nil {
+		return TemplatePack{}, err
+	}
+	for name, content := range pack.Files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			return TemplatePack{}, err
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return TemplatePack{}, err
+	}
+	for _, e := range entries {
+		if _, keep := pack.Files[e.Name()]; !keep && validPackFile(e.Name()) {
+			if err := os.Remove(filepath.Join(dir, e.Name())); err != nil {
+				return TemplatePack{}, err
+			}
+		}
+	}
+	return p.Get(pack.Name)
+}
+
+// Delete removes the pack name.
+func (p *TemplatePacks) Delete(name string) error {
+	if !packNamePattern.MatchString(name) {
+		return ErrBadPackName
+	}
+	dir := filepath.Join(p.dir, name)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return ErrPackNotFound
+	}
+	return os.RemoveAll(dir)
+}
+
+// Render returns the pack's files rendered for an agent.
+func (pack TemplatePack) Render(data TemplateData) (map[string]string, error) {
+	rendered := make(map[string]string, len(pack.Files))
+	for name, content := range pack.Files {
+		tmpl, err := template.New(name).Option("missingkey=error").Parse(content)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		rendered[name] = buf.String()
+	}
+	return rendered, nil
+}
+
+// ValidateTemplatePack checks a pack's name, file names, skills and that its
+// templates render.
+func ValidateTemplatePack(pack TemplatePack) error {
+	if !packNamePattern.MatchString(pack.Name) {
+		return ErrBadPackName
+	}
+	for name, content := range pack.Files {
+		if !validPackFile(name) {
+			return fmt.Errorf("file %q: must be a top-level .md file", name)
+		}
+		if len(content) > maxPackFileSize {
+			return fmt.Errorf("file %q: larger than %d KiB", name, maxPackFileSize/1024)
+		}
+	}
+	for _, skill := range pack.Skills {
+		if strings.TrimSpace(skill) == "" || strings.ContainsAny(skill, " \t\n") || strings.HasPrefix(skill, "-") {
+			return fmt.Errorf("skill %q: not a ClawHub skill name", skill)
+		}
+	}
+	_, err := pack.Render(TemplateData{ID: "agent", Name: "Agent", Description: "An agent", Model: pack.Model})
+	return err
+}
+
+// validPackFile reports whether name can be a file of a pack: a markdown
+// file at the top of the workspace.
+func validPackFile(name string) bool {
+	return strings.HasSuffix(name, ".md") && len(name) > len(".md") &&
+		!strings.ContainsAny(name, `/\`) && !strings.HasPrefix(name, ".")
+}
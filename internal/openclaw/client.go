@@ -57,6 +57,18 @@ func NewClientFromEnv() (*Client, error) {
 	}, nil
 }
 
+// httpBaseURL returns the gateway URL for its HTTP API (ws:// and wss://
+// become http:// and https://).
+func (c *Client) httpBaseURL() string {
+	baseURL := c.gatewayURL
+	if len(baseURL) > 5 && baseURL[:5] == "ws://" {
+		baseURL = "http://" + baseURL[5:]
+	} else if len(baseURL) > 6 && baseURL[:6] == "wss://" {
+		baseURL = "https://" + baseURL[6:]
+	}
+	return baseURL
+}
+
 func loadTokenFromConfig() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -120,13 +132,7 @@ type ToolInvokeResponse struct {
 
 // Spawn creates a new sub-agent session using the /tools/invoke endpoint
 func (c *Client) Spawn(ctx context.Context, req *SpawnRequest) (*SpawnResponse, error) {
-	// OpenClaw uses HTTP API at the gateway URL (convert ws:// to http://)
-	baseURL := c.gatewayURL
-	if len(baseURL) > 5 && baseURL[:5] == "ws://" {
-		baseURL = "http://" + baseURL[5:]
-	} else if len(baseURL) > 6 && baseURL[:6] == "wss://" {
-		baseURL = "https://" + baseURL[6:]
-	}
+	baseURL := c.httpBaseURL()
 
 	// Use /tools/invoke with sessions_spawn tool
 	url := fmt.Sprintf("%s/tools/invoke", baseURL)
@@ -187,10 +193,7 @@ func (c *Client) Spawn(ctx context.Context, req *SpawnRequest) (*SpawnResponse,
 
 // SendMessage sends a message to an existing session using /tools/invoke
 func (c *Client) SendMessage(ctx context.Context, sessionKey, message string) error {
-	baseURL := c.gatewayURL
-	if len(baseURL) > 5 && baseURL[:5] == "ws://" {
-		baseURL = "http://" + baseURL[5:]
-	}
+	baseURL := c.httpBaseURL()
 
 	url := fmt.Sprintf("%s/tools/invoke", baseURL)
 
@@ -252,6 +255,15 @@ type SessionMessage struct {
 	Timestamp int64  `json:"timestamp,omitempty"`
 }
 
+// Time is when the message was sent; Timestamp may be in seconds or
+// milliseconds since the epoch.
+func (m SessionMessage) Time() time.Time {
+	if m.Timestamp > 1e12 {
+		return time.UnixMilli(m.Timestamp)
+	}
+	return time.Unix(m.Timestamp, 0)
+}
+
 // SessionHistoryResponse represents the response from sessions_history
 type SessionHistoryResponse struct {
 	SessionKey string           `json:"sessionKey"`
@@ -260,10 +272,7 @@ type SessionHistoryResponse struct {
 
 // GetSessionHistory retrieves message history for a session using /tools/invoke
 func (c *Client) GetSessionHistory(ctx context.Context, sessionKey string, limit int) (*SessionHistoryResponse, error) {
-	baseURL := c.gatewayURL
-	if len(baseURL) > 5 && baseURL[:5] == "ws://" {
-		baseURL = "http://" + baseURL[5:]
-	}
+	baseURL := c.httpBaseURL()
 
 	url := fmt.Sprintf("%s/tools/invoke", baseURL)
 
@@ -328,10 +337,7 @@ func (c *Client) GetSessionHistory(ctx context.Context, sessionKey string, limit
 
 // GetStatus checks the gateway connection status
 func (c *Client) GetStatus(ctx context.Context) (bool, error) {
-	baseURL := c.gatewayURL
-	if len(baseURL) > 5 && baseURL[:5] == "ws://" {
-		baseURL = "http://" + baseURL[5:]
-	}
+	baseURL := c.httpBaseURL()
 
 	url := fmt.Sprintf("%s/health", baseURL)
 
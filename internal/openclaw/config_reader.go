@@ -5,12 +5,21 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
-// ConfigReader reads OpenClaw configuration and agent workspace files
+// ConfigReader reads OpenClaw configuration and agent workspace files. The
+// config file is read again only when its modification time or size changes.
 type ConfigReader struct {
 	configPath string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	data    []byte
 }
 
 // AgentConfig represents an agent from OpenClaw config with workspace files
@@ -53,9 +62,9 @@ func NewConfigReader(configPath string) *ConfigReader {
 // ReadAgents reads all agents from OpenClaw configuration
 func (r *ConfigReader) ReadAgents() ([]AgentConfig, error) {
 	// Read config file
-	data, err := os.ReadFile(r.configPath)
+	data, err := r.load()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file %s: %w", r.configPath, err)
+		return nil, err
 	}
 	
 	// Parse JSON
@@ -154,43 +163,124 @@ func (r *ConfigReader) GetConfigPath() string {
 	return r.configPath
 }
 
-// ModelConfig represents a model from OpenClaw config
+// load returns the contents of the config file, from the cache while the
+// file's modification time and size are unchanged.
+func (r *ConfigReader) load() ([]byte, error) {
+	info, err := os.Stat(r.configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", r.configPath, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.data != nil && info.ModTime().Equal(r.modTime) && info.Size() == r.size {
+		return r.data, nil
+	}
+	data, err := os.ReadFile(r.configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", r.configPath, err)
+	}
+	r.data, r.modTime, r.size = data, info.ModTime(), info.Size()
+	return data, nil
+}
+
+// ModelConfig represents a model from OpenClaw config: an entry of
+// agents.defaults.models, with what its provider (models.providers) says
+// about it.
 type ModelConfig struct {
-	ID    string `json:"id"`
-	Alias string `json:"alias,omitempty"`
+	ID            string `json:"id"` // provider/model
+	Alias         string `json:"alias,omitempty"`
+	Name          string `json:"name,omitempty"`
+	ContextWindow int    `json:"context_window,omitempty"` // tokens
+	MaxTokens     int    `json:"max_tokens,omitempty"`     // output tokens
+	Reasoning     bool   `json:"reasoning,omitempty"`
+	Default       bool   `json:"default"`            // agents.defaults.model's primary
+	Fallback      bool   `json:"fallback,omitempty"` // one of its fallbacks
 }
 
-// ReadModels reads all configured models from OpenClaw configuration
+// modelSelection is agents.defaults.model: a model ID or alias, or a primary
+// model with fallbacks.
+type modelSelection struct {
+	Primary   string   `json:"primary"`
+	Fallbacks []string `json:"fallbacks"`
+}
+
+func (m *modelSelection) UnmarshalJSON(data []byte) error {
+	var id string
+	if err := json.Unmarshal(data, &id); err == nil {
+		m.Primary = id
+		return nil
+	}
+	type plain modelSelection
+	return json.Unmarshal(data, (*plain)(m))
+}
+
+// ReadModels reads all configured models from OpenClaw configuration, by ID.
+// Models referred to by alias in agents.defaults.model are resolved.
 func (r *ConfigReader) ReadModels() ([]ModelConfig, error) {
-	// Read config file
-	data, err := os.ReadFile(r.configPath)
+	data, err := r.load()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file %s: %w", r.configPath, err)
+		return nil, err
 	}
 
-	// Parse JSON - look for agent.defaults.models path
 	var config struct {
 		Agents struct {
 			Defaults struct {
+				Model  modelSelection `json:"model"`
 				Models map[string]struct {
 					Alias string `json:"alias"`
 				} `json:"models"`
 			} `json:"defaults"`
 		} `json:"agents"`
+		Models struct {
+			Providers map[string]struct {
+				Models []struct {
+					ID            string `json:"id"`
+					Name          string `json:"name"`
+					ContextWindow int    `json:"contextWindow"`
+					MaxTokens     int    `json:"maxTokens"`
+					Reasoning     bool   `json:"reasoning"`
+				} `json:"models"`
+			} `json:"providers"`
+		} `json:"models"`
 	}
-
 	if err := json.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
 
-	// Pull models from agent.defaults.models
-	var models []ModelConfig
+	models := make([]ModelConfig, 0, len(config.Agents.Defaults.Models))
+	index := map[string]int{} // by ID and alias
 	for modelID, modelConfig := range config.Agents.Defaults.Models {
-		models = append(models, ModelConfig{
-			ID:    modelID,
-			Alias: modelConfig.Alias,
-		})
+		models = append(models, ModelConfig{ID: modelID, Alias: modelConfig.Alias})
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+	for i, m := range models {
+		index[m.ID] = i
+		if m.Alias != "" {
+			index[m.Alias] = i
+		}
+	}
+
+	for provider, p := range config.Models.Providers {
+		for _, pm := range p.Models {
+			i, ok := index[provider+"/"+pm.ID]
+			if !ok {
+				continue
+			}
+			models[i].Name = pm.Name
+			models[i].ContextWindow = pm.ContextWindow
+			models[i].MaxTokens = pm.MaxTokens
+			models[i].Reasoning = pm.Reasoning
+		}
 	}
 
+	selection := config.Agents.Defaults.Model
+	if i, ok := index[selection.Primary]; ok {
+		models[i].Default = true
+	}
+	for _, fallback := range selection.Fallbacks {
+		if i, ok := index[fallback]; ok {
+			models[i].Fallback = true
+		}
+	}
 	return models, nil
 }
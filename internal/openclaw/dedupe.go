@@ -0,0 +1,65 @@
+package openclaw
+
+import (
+	"crypto/sha256"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrDuplicate is returned for a send suppressed by the Deduper: the same
+// message went to the agent moments ago, and that send's callback reports
+// how it went.
+var ErrDuplicate = errors.New("duplicate notification suppressed")
+
+// Deduper remembers what was recently sent to whom, so that the same
+// notification triggered twice at once (the queue processor and a heartbeat
+// pickup racing to assign a task) reaches the agent once. A notification is
+// identified by a hash of its agent, task, kind and message; different
+// messages of the same kind, such as two subtask results, are not duplicates.
+// It only sees the sends of this instance.
+type Deduper struct {
+	window time.Duration
+
+	mu   sync.Mutex
+	sent map[[sha256.Size]byte]time.Time // when each was last sent
+}
+
+// NewDeduper returns a Deduper suppressing repeats within window, or nil
+// (suppressing nothing) if window is not positive.
+func NewDeduper(window time.Duration) *Deduper {
+	if window <= 0 {
+		return nil
+	}
+	return &Deduper{window: window, sent: make(map[[sha256.Size]byte]time.Time)}
+}
+
+// Duplicate records a send of message of kind to agentID about taskID and
+// reports whether the same was already sent within the window. A nil
+// Deduper reports no duplicates.
+func (d *Deduper) Duplicate(kind, agentID, taskID, message string) bool {
+	if d == nil {
+		return false
+	}
+	h := sha256.New()
+	for _, part := range []string{agentID, taskID, kind, message} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	var key [sha256.Size]byte
+	h.Sum(key[:0])
+
+	now := time.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for k, at := range d.sent {
+		if now.Sub(at) >= d.window {
+			delete(d.sent, k)
+		}
+	}
+	if _, ok := d.sent[key]; ok {
+		return true
+	}
+	d.sent[key] = now
+	return false
+}
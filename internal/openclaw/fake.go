@@ -0,0 +1,552 @@
+package openclaw
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// fakeMissionControlURL is the API URL rendered into fake notifications.
+const fakeMissionControlURL = "http://mission-control.test/api/v1"
+
+// SentMessage is a notification captured by FakeSender.
+type SentMessage struct {
+	Kind    string // task_assignment | subtask_completion | agent_run
+	AgentID string
+	TaskID  string
+	Message string
+	Method  string // delivery method the agent's route resolved to (runs always use the CLI)
+}
+
+// FakeSender is an in-memory Sender for tests. Notifications are recorded and
+// callbacks are invoked synchronously, so a request that triggers a
+// notification has observed its result by the time it returns.
+type FakeSender struct {
+	// parent is set on the per-request copies returned by For; all state
+	// lives on the root sender.
+	parent      *FakeSender
+	forceDryRun bool
+	ctx         context.Context
+
+	mu          sync.Mutex
+	sent        []SentMessage
+	dryRun      bool
+	outbox      *Outbox
+	templates   *Templates
+	localeFor   func(agentID string) string
+	apiURLFor   func(agentID string) string
+	shortIDFor  func(taskID string) string
+	routeFor    func(agentID string) Route
+	secretsFor  SecretsResolver
+	pathsFor    func(taskID string) []string
+	summaryFor  func(taskID string) string
+	modelFor    func(taskID string) string
+	templateFor func(taskID string) string
+	resultFor   func(subtaskID string) *SubtaskResult
+	onSession   SessionObserver
+	limiter     RateLimiter
+	dedupe      *Deduper
+	sizeLimit   *SizeLimit
+
+	// Reply, if set, produces the agent's reply (or error) for each message.
+	// The default replies with an empty string and no error.
+	Reply func(msg SentMessage) (string, error)
+}
+
+// NewFakeSender creates a FakeSender with an empty outbox.
+func NewFakeSender() *FakeSender {
+	return &FakeSender{outbox: NewOutbox(defaultOutboxSize), templates: NewTemplates("")}
+}
+
+func (f *FakeSender) root() *FakeSender {
+	if f.parent != nil {
+		return f.parent
+	}
+	return f
+}
+
+func (f *FakeSender) record(msg SentMessage) (string, error) {
+	return f.recordCut(msg, 0)
+}
+
+// recordCut records msg, cut bytes of which were already cut to fit the
+// size limit.
+func (f *FakeSender) recordCut(msg SentMessage, cut int) (string, error) {
+	if f.ctx != nil && f.ctx.Err() != nil {
+		return "", fmt.Errorf("send to agent %s abandoned: %w", msg.AgentID, context.Cause(f.ctx))
+	}
+	r := f.root()
+	msg.Method = DeliveryCLI
+	if r.routeFor != nil && msg.Kind != "agent_run" {
+		if method := r.routeFor(msg.AgentID).Method; method != "" {
+			msg.Method = method
+		}
+	}
+	apiURL := f.apiURL(msg.AgentID)
+	r.mu.Lock()
+	dryRun := r.dryRun || f.forceDryRun
+	reply := r.Reply
+	onSession := r.onSession
+	limiter := r.limiter
+	if msg.Kind != "model_switch" && msg.Kind != "agent_run" && r.dedupe.Duplicate(msg.Kind, msg.AgentID, msg.TaskID, msg.Message) {
+		r.mu.Unlock()
+		return "", ErrDuplicate
+	}
+	if msg.Kind != "agent_run" {
+		msg.Message = r.sizeLimit.Fit(msg.Kind, msg.Message, cut, taskURL(apiURL, msg.TaskID))
+	}
+	if !dryRun {
+		r.sent = append(r.sent, msg)
+	}
+	r.mu.Unlock()
+
+	if dryRun {
+		r.outbox.Add(msg.Kind, msg.AgentID, msg.TaskID, msg.Message)
+		return "", nil
+	}
+	if onSession != nil {
+		onSession(msg.AgentID, true)
+		defer onSession(msg.AgentID, false)
+	}
+	if reply == nil {
+		reply = func(SentMessage) (string, error) { return "", nil }
+	}
+	text, err := reply(msg)
+	if f.ctx != nil && f.ctx.Err() != nil {
+		// Cancelled while the agent was replying, as a real send would be
+		return "", fmt.Errorf("send to agent %s abandoned: %w", msg.AgentID, context.Cause(f.ctx))
+	}
+	if limiter != nil && msg.Kind != "agent_run" {
+		// Sends aren't retried, but their outcome is reported like real ones
+		limiter.Observe(msg.AgentID, err)
+	}
+	return text, err
+}
+
+func (f *FakeSender) NotifyAgentAsync(agentID, taskID, title, description string, callback AgentSendCallback) {
+	f.RenotifyAgentAsync(agentID, taskID, title, description, nil, callback)
+}
+
+func (f *FakeSender) RenotifyAgentAsync(agentID, taskID, title, description string, history *TaskHistory, callback AgentSendCallback) {
+	model := f.taskModel(taskID)
+	if model != "" {
+		f.record(SentMessage{Kind: "model_switch", AgentID: agentID, TaskID: taskID, Message: modelCommand + " " + model})
+	}
+	secrets := f.taskSecrets(agentID, taskID)
+	var route Route
+	if f.root().routeFor != nil {
+		route = f.root().routeFor(agentID)
+	}
+	shown, secretsFile, removeSecrets, err := stageSecrets(route, secrets)
+	if err != nil {
+		if callback != nil {
+			callback(taskID, agentID, "", err)
+		}
+		return
+	}
+	defer removeSecrets()
+	message, cut := renderTaskAssignment(f.Templates(), f.taskAssignmentTemplate(taskID), f.agentLocale(agentID), TaskAssignmentData{
+		TaskID:            taskID,
+		ShortID:           f.taskShortID(taskID),
+		Title:             title,
+		Description:       description,
+		MissionControlURL: f.apiURL(agentID),
+		Secrets:           shown,
+		SecretsFile:       secretsFile,
+		AllowedPaths:      f.taskAllowedPaths(taskID),
+		ContextSummary:    f.taskContextSummary(taskID),
+		Model:             model,
+		History:           history,
+	}, f.root().sizeLimit)
+	reply, err := f.recordCut(SentMessage{Kind: "task_assignment", AgentID: agentID, TaskID: taskID, Message: message}, cut)
+	reply, err = redactSecrets(reply, secrets), redactError(err, secrets)
+	if callback != nil {
+		callback(taskID, agentID, reply, err)
+	}
+}
+
+func (f *FakeSender) NotifySubtaskCompletionAsync(
+	orchestratorAgentID,
+	subtaskID, subtaskTitle, subtaskStatus,
+	parentTaskID, parentTaskTitle,
+	specialistAgentID string,
+	callback AgentSendCallback,
+) {
+	message := f.Templates().renderOrDefault(TemplateSubtaskCompletion, f.agentLocale(orchestratorAgentID), SubtaskCompletionData{
+		SubtaskID:         subtaskID,
+		SubtaskShortID:    f.taskShortID(subtaskID),
+		SubtaskTitle:      subtaskTitle,
+		SubtaskStatus:     subtaskStatus,
+		ParentTaskID:      parentTaskID,
+		ParentShortID:     f.taskShortID(parentTaskID),
+		ParentTaskTitle:   parentTaskTitle,
+		SpecialistAgentID: specialistAgentID,
+		MissionControlURL: f.apiURL(orchestratorAgentID),
+		Result:            f.subtaskResult(subtaskID),
+	})
+	reply, err := f.record(SentMessage{Kind: "subtask_completion", AgentID: orchestratorAgentID, TaskID: parentTaskID, Message: message})
+	if callback != nil {
+		callback(parentTaskID, orchestratorAgentID, reply, err)
+	}
+}
+
+func (f *FakeSender) NotifyReviewRequestAsync(reviewerAgentID, taskID, title, description, agentID, gitBranch string, callback AgentSendCallback) {
+	message := f.Templates().renderOrDefault(TemplateReviewRequest, f.agentLocale(reviewerAgentID), ReviewRequestData{
+		TaskID:            taskID,
+		ShortID:           f.taskShortID(taskID),
+		Title:             title,
+		Description:       description,
+		AgentID:           agentID,
+		GitBranch:         gitBranch,
+		MissionControlURL: f.apiURL(reviewerAgentID),
+		Result:            f.subtaskResult(taskID),
+	})
+	reply, err := f.record(SentMessage{Kind: "review_request", AgentID: reviewerAgentID, TaskID: taskID, Message: message})
+	if callback != nil {
+		callback(taskID, reviewerAgentID, reply, err)
+	}
+}
+
+func (f *FakeSender) NotifyMentionAsync(agentID, taskID, title, author, source, text string, callback AgentSendCallback) {
+	message := f.Templates().renderOrDefault(TemplateMention, f.agentLocale(agentID), MentionData{
+		TaskID:            taskID,
+		ShortID:           f.taskShortID(taskID),
+		Title:             title,
+		Author:            author,
+		Source:            source,
+		Text:              truncateText(text, mentionTextMaxBytes),
+		MissionControlURL: f.apiURL(agentID),
+	})
+	reply, err := f.record(SentMessage{Kind: "mention", AgentID: agentID, TaskID: taskID, Message: message})
+	if callback != nil {
+		callback(taskID, agentID, reply, err)
+	}
+}
+
+func (f *FakeSender) RunCommand(ctx context.Context, agentID, prompt string, timeout time.Duration, onOutput func(line string)) (string, error) {
+	if r := f.root(); r.routeFor != nil {
+		if method := r.routeFor(agentID).Method; !CanRun(method) {
+			return "", fmt.Errorf("agent %s is reached through %s: %w", agentID, method, ErrRunNeedsReply)
+		}
+	}
+	reply, err := f.record(SentMessage{Kind: "agent_run", AgentID: agentID, Message: prompt})
+	if err == nil && reply != "" && onOutput != nil {
+		onOutput(reply)
+	}
+	return reply, err
+}
+
+// For mirrors AgentSender.For: sends fail once ctx is done, and a dry-run
+// ctx yields a sender that records to the shared outbox regardless of the
+// global setting.
+func (f *FakeSender) For(ctx context.Context) Sender {
+	return &FakeSender{parent: f.root(), forceDryRun: f.forceDryRun || IsDryRun(ctx), ctx: ctx}
+}
+
+func (f *FakeSender) SetDryRun(enabled bool) {
+	r := f.root()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dryRun = enabled
+}
+
+func (f *FakeSender) DryRun() bool {
+	r := f.root()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.dryRun || f.forceDryRun
+}
+
+func (f *FakeSender) Outbox() *Outbox {
+	return f.root().outbox
+}
+
+func (f *FakeSender) Templates() *Templates {
+	return f.root().templates
+}
+
+func (f *FakeSender) SetLocaleResolver(fn func(agentID string) string) {
+	f.root().localeFor = fn
+}
+
+func (f *FakeSender) agentLocale(agentID string) string {
+	if fn := f.root().localeFor; fn != nil {
+		return fn(agentID)
+	}
+	return ""
+}
+
+func (f *FakeSender) SetAPIURLResolver(fn func(agentID string) string) {
+	f.root().apiURLFor = fn
+}
+
+func (f *FakeSender) apiURL(agentID string) string {
+	if fn := f.root().apiURLFor; fn != nil {
+		if url := fn(agentID); url != "" {
+			return url
+		}
+	}
+	return fakeMissionControlURL
+}
+
+func (f *FakeSender) SetShortIDResolver(fn func(taskID string) string) {
+	f.root().shortIDFor = fn
+}
+
+func (f *FakeSender) taskShortID(taskID string) string {
+	if fn := f.root().shortIDFor; fn != nil {
+		return fn(taskID)
+	}
+	return ""
+}
+
+func (f *FakeSender) SetRouteResolver(fn func(agentID string) Route) {
+	f.root().routeFor = fn
+}
+
+func (f *FakeSender) SetSecretsResolver(fn SecretsResolver) {
+	f.root().secretsFor = fn
+}
+
+func (f *FakeSender) taskSecrets(agentID, taskID string) []Secret {
+	if fn := f.root().secretsFor; fn != nil && !f.DryRun() {
+		return fn(agentID, taskID)
+	}
+	return nil
+}
+
+func (f *FakeSender) SetPathPolicyResolver(fn func(taskID string) []string) {
+	f.root().pathsFor = fn
+}
+
+func (f *FakeSender) taskAllowedPaths(taskID string) []string {
+	if fn := f.root().pathsFor; fn != nil {
+		return fn(taskID)
+	}
+	return nil
+}
+
+func (f *FakeSender) SetContextSummaryResolver(fn func(taskID string) string) {
+	f.root().summaryFor = fn
+}
+
+func (f *FakeSender) taskContextSummary(taskID string) string {
+	if fn := f.root().summaryFor; fn != nil {
+		return fn(taskID)
+	}
+	return ""
+}
+
+func (f *FakeSender) SetModelResolver(fn func(taskID string) string) {
+	f.root().modelFor = fn
+}
+
+func (f *FakeSender) taskModel(taskID string) string {
+	if fn := f.root().modelFor; fn != nil {
+		return fn(taskID)
+	}
+	return ""
+}
+
+func (f *FakeSender) SetAssignmentTemplateResolver(fn func(taskID string) string) {
+	f.root().templateFor = fn
+}
+
+func (f *FakeSender) taskAssignmentTemplate(taskID string) string {
+	if fn := f.root().templateFor; fn != nil {
+		return fn(taskID)
+	}
+	return ""
+}
+
+func (f *FakeSender) SetSubtaskResultResolver(fn func(subtaskID string) *SubtaskResult) {
+	f.root().resultFor = fn
+}
+
+func (f *FakeSender) subtaskResult(subtaskID string) *SubtaskResult {
+	if fn := f.root().resultFor; fn != nil {
+		return fn(subtaskID).capped(subtaskResultMaxBytes)
+	}
+	return nil
+}
+
+func (f *FakeSender) SetSessionObserver(fn SessionObserver) {
+	r := f.root()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onSession = fn
+}
+
+func (f *FakeSender) SetRateLimiter(l RateLimiter) {
+	r := f.root()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.limiter = l
+}
+
+// SetTimeoutResolver does nothing: the fake answers at once, so there is
+// nothing to time out.
+func (f *FakeSender) SetTimeoutResolver(fn func(agentID, dispatch string) time.Duration) {}
+
+// SetSizeLimit sets the SizeLimit recorded messages are cut to; without
+// one, messages are recorded whole.
+func (f *FakeSender) SetSizeLimit(l *SizeLimit) {
+	r := f.root()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sizeLimit = l
+}
+
+// MessageStats returns the counts of the SizeLimit, if one is set.
+func (f *FakeSender) MessageStats() MessageStats {
+	r := f.root()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sizeLimit.Stats()
+}
+
+func (f *FakeSender) SetDeduper(d *Deduper) {
+	r := f.root()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dedupe = d
+}
+
+// SetTemplates replaces the templates used to render fake notifications.
+func (f *FakeSender) SetTemplates(t *Templates) {
+	f.root().templates = t
+}
+
+// Sent returns a copy of every message delivered so far.
+func (f *FakeSender) Sent() []SentMessage {
+	r := f.root()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make([]SentMessage, len(r.sent))
+	copy(result, r.sent)
+	return result
+}
+
+// SentTo returns the messages delivered to a single agent.
+func (f *FakeSender) SentTo(agentID string) []SentMessage {
+	var result []SentMessage
+	for _, msg := range f.Sent() {
+		if msg.AgentID == agentID {
+			result = append(result, msg)
+		}
+	}
+	return result
+}
+
+// Reset forgets all delivered messages.
+func (f *FakeSender) Reset() {
+	r := f.root()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = nil
+}
+
+// FakeGateway is an in-memory Gateway for tests. Sessions are keyed by
+// session key; every message sent, and the task of every spawned session, is
+// answered by Respond (if set).
+type FakeGateway struct {
+	mu       sync.Mutex
+	sessions map[string][]SessionMessage
+	spawned  []SpawnRequest
+	healthy  bool
+
+	// Respond, if set, produces the assistant reply to each sent message.
+	Respond func(sessionKey, message string) string
+}
+
+// NewFakeGateway creates a healthy FakeGateway with no sessions.
+func NewFakeGateway() *FakeGateway {
+	return &FakeGateway{
+		sessions: make(map[string][]SessionMessage),
+		healthy:  true,
+	}
+}
+
+func (g *FakeGateway) Spawn(ctx context.Context, req *SpawnRequest) (*SpawnResponse, error) {
+	g.mu.Lock()
+	g.spawned = append(g.spawned, *req)
+	n := len(g.spawned)
+	key := fmt.Sprintf("agent:%s:subagent:%d", req.AgentID, n)
+	g.sessions[key] = []SessionMessage{{Role: "user", Content: req.Task, Timestamp: time.Now().Unix()}}
+	respond := g.Respond
+	g.mu.Unlock()
+
+	// The spawned session's task is answered like a sent message
+	if respond != nil {
+		if reply := respond(key, req.Task); reply != "" {
+			g.mu.Lock()
+			g.sessions[key] = append(g.sessions[key], SessionMessage{Role: "assistant", Content: reply, Timestamp: time.Now().Unix()})
+			g.mu.Unlock()
+		}
+	}
+	return &SpawnResponse{
+		Status:          "accepted",
+		ChildSessionKey: key,
+		RunID:           fmt.Sprintf("run-%d", n),
+	}, nil
+}
+
+func (g *FakeGateway) SendMessage(ctx context.Context, sessionKey, message string) error {
+	g.mu.Lock()
+	respond := g.Respond
+	g.sessions[sessionKey] = append(g.sessions[sessionKey], SessionMessage{Role: "user", Content: message, Timestamp: time.Now().Unix()})
+	g.mu.Unlock()
+
+	if respond == nil {
+		return nil
+	}
+	reply := respond(sessionKey, message)
+	if reply == "" {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.sessions[sessionKey] = append(g.sessions[sessionKey], SessionMessage{Role: "assistant", Content: reply, Timestamp: time.Now().Unix()})
+	return nil
+}
+
+func (g *FakeGateway) GetSessionHistory(ctx context.Context, sessionKey string, limit int) (*SessionHistoryResponse, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	messages := g.sessions[sessionKey]
+	if limit > 0 && len(messages) > limit {
+		messages = messages[len(messages)-limit:]
+	}
+	result := make([]SessionMessage, len(messages))
+	copy(result, messages)
+	return &SessionHistoryResponse{SessionKey: sessionKey, Messages: result}, nil
+}
+
+func (g *FakeGateway) GetStatus(ctx context.Context) (bool, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.healthy, nil
+}
+
+// SetHealthy controls the result of GetStatus.
+func (g *FakeGateway) SetHealthy(healthy bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.healthy = healthy
+}
+
+// Spawned returns a copy of every spawn request received.
+func (g *FakeGateway) Spawned() []SpawnRequest {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	result := make([]SpawnRequest, len(g.spawned))
+	copy(result, g.spawned)
+	return result
+}
+
+var (
+	_ Sender  = (*FakeSender)(nil)
+	_ Gateway = (*FakeGateway)(nil)
+)
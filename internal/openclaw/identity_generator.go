@@ -3,22 +3,43 @@ package openclaw
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 )
 
+// identityPollInterval is how often an identity generation session is
+// checked for its reply.
+const identityPollInterval = 2 * time.Second
+
+// Identity generation outcomes, see IdentityGeneration.
+const (
+	IdentityGenerated = "generated" // every file written by the model
+	IdentityPartial   = "partial"   // some files written by the model, the rest from templates
+	IdentityFallback  = "fallback"  // templates only: no usable reply from the model
+)
+
 // IdentityGenerator generates agent identity files using the OpenClaw Gateway
 type IdentityGenerator struct {
-	client *Client
+	gateway Gateway
+	timeout time.Duration // how long to wait for the model's reply
+	poll    time.Duration
 }
 
-// NewIdentityGenerator creates a new identity generator
-func NewIdentityGenerator() (*IdentityGenerator, error) {
-	client, err := NewClientFromEnv()
-	if err != nil {
-		return nil, err
-	}
-	return &IdentityGenerator{client: client}, nil
+// NewIdentityGenerator creates an identity generator spawning its sessions on
+// gateway and waiting up to timeout for each reply.
+func NewIdentityGenerator(gateway Gateway, timeout time.Duration) *IdentityGenerator {
+	return &IdentityGenerator{gateway: gateway, timeout: timeout, poll: identityPollInterval}
+}
+
+// IdentityGeneration reports how an agent's identity files were generated.
+type IdentityGeneration struct {
+	Status     string   `json:"status"`
+	Error      string   `json:"error,omitempty"` // why the model's reply was not (fully) used
+	SessionKey string   `json:"session_key,omitempty"`
+	DurationMs int64    `json:"duration_ms"`
+	Templated  []string `json:"templated,omitempty"` // files filled from templates, e.g. "soul_md"
 }
 
 // GeneratedIdentity contains the generated identity files for an agent
@@ -32,6 +53,44 @@ type GeneratedIdentity struct {
 	MemoryMD    string `json:"memory_md"`
 }
 
+// IdentityFile is a workspace file of an agent's identity.
+type IdentityFile struct {
+	Key  string // its GeneratedIdentity JSON field, e.g. soul_md
+	Name string // e.g. SOUL.md
+}
+
+// IdentityFiles are the identity files of an agent's workspace.
+var IdentityFiles = []IdentityFile{
+	{"soul_md", "SOUL.md"},
+	{"identity_md", "IDENTITY.md"},
+	{"agents_md", "AGENTS.md"},
+	{"user_md", "USER.md"},
+	{"tools_md", "TOOLS.md"},
+	{"heartbeat_md", "HEARTBEAT.md"},
+	{"memory_md", "MEMORY.md"},
+}
+
+// File returns the content of the identity file key, e.g. soul_md.
+func (g *GeneratedIdentity) File(key string) string {
+	switch key {
+	case "soul_md":
+		return g.SoulMD
+	case "identity_md":
+		return g.IdentityMD
+	case "agents_md":
+		return g.AgentsMD
+	case "user_md":
+		return g.UserMD
+	case "tools_md":
+		return g.ToolsMD
+	case "heartbeat_md":
+		return g.HeartbeatMD
+	case "memory_md":
+		return g.MemoryMD
+	}
+	return ""
+}
+
 // GenerateIdentityRequest contains the parameters for generating an agent identity
 type GenerateIdentityRequest struct {
 	AgentName   string `json:"agent_name"`
@@ -39,31 +98,96 @@ type GenerateIdentityRequest struct {
 	Model       string `json:"model"`
 }
 
-// GenerateIdentity uses the main OpenClaw agent to generate identity files
-func (g *IdentityGenerator) GenerateIdentity(ctx context.Context, req *GenerateIdentityRequest) (*GeneratedIdentity, error) {
-	// Build the prompt for the main agent
-	prompt := buildIdentityGenerationPrompt(req)
+// GenerateIdentity has the main OpenClaw agent write the identity files and
+// waits for its reply. Files the reply leaves out, or all of them if there is
+// no usable reply in time, come from GenerateIdentityFromDescription; the
+// returned IdentityGeneration says which.
+func (g *IdentityGenerator) GenerateIdentity(ctx context.Context, req *GenerateIdentityRequest) (_ *GeneratedIdentity, generation IdentityGeneration) {
+	start := time.Now()
+	templates := GenerateIdentityFromDescription(req)
+	generation.Status = IdentityFallback
+	defer func() { generation.DurationMs = time.Since(start).Milliseconds() }()
+
+	reply, sessionKey, err := g.await(ctx, req)
+	generation.SessionKey = sessionKey
+	if err != nil {
+		generation.Error = err.Error()
+		return templates, generation
+	}
+	identity, err := ParseGeneratedIdentity(reply)
+	if err != nil {
+		generation.Error = err.Error()
+		return templates, generation
+	}
+
+	fields := []struct {
+		name      string
+		generated *string
+		template  string
+	}{
+		{"soul_md", &identity.SoulMD, templates.SoulMD},
+		{"identity_md", &identity.IdentityMD, templates.IdentityMD},
+		{"agents_md", &identity.AgentsMD, templates.AgentsMD},
+		{"user_md", &identity.UserMD, templates.UserMD},
+		{"tools_md", &identity.ToolsMD, templates.ToolsMD},
+		{"heartbeat_md", &identity.HeartbeatMD, templates.HeartbeatMD},
+		{"memory_md", &identity.MemoryMD, templates.MemoryMD},
+	}
+	for _, f := range fields {
+		if strings.TrimSpace(*f.generated) == "" {
+			*f.generated = f.template
+			generation.Templated = append(generation.Templated, f.name)
+		}
+	}
+	switch {
+	case len(generation.Templated) == len(fields):
+		generation.Error = "reply had none of the identity files"
+	case len(generation.Templated) > 0:
+		generation.Status = IdentityPartial
+	default:
+		generation.Status = IdentityGenerated
+	}
+	return identity, generation
+}
+
+// await spawns the identity generation session and returns its reply and
+// key, waiting up to the generator's timeout.
+func (g *IdentityGenerator) await(ctx context.Context, req *GenerateIdentityRequest) (string, string, error) {
+	if g.gateway == nil {
+		return "", "", errNoGateway
+	}
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
 
-	// Spawn a session to generate the identity
-	spawnResp, err := g.client.Spawn(ctx, &SpawnRequest{
-		Task:           prompt,
+	spawnResp, err := g.gateway.Spawn(ctx, &SpawnRequest{
+		Task:           buildIdentityGenerationPrompt(req),
 		Label:          fmt.Sprintf("identity-gen-%s-%d", req.AgentName, time.Now().Unix()),
+		Model:          req.Model,
 		Cleanup:        "delete",
-		TimeoutSeconds: 120,
+		TimeoutSeconds: int(g.timeout.Seconds()),
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to spawn identity generation session: %w", err)
+		return "", "", fmt.Errorf("failed to spawn identity generation session: %w", err)
 	}
 
-	// For now, return a generated identity based on the description
-	// In a full implementation, we would wait for the session result
-	// and parse the JSON response from the agent
-	identity := GenerateIdentityFromDescription(req)
-
-	// Log the session info for debugging
-	_ = spawnResp
-
-	return identity, nil
+	ticker := time.NewTicker(g.poll)
+	defer ticker.Stop()
+	for {
+		history, err := g.gateway.GetSessionHistory(ctx, spawnResp.ChildSessionKey, 0)
+		if err == nil {
+			for i := len(history.Messages) - 1; i >= 0; i-- {
+				m := history.Messages[i]
+				if m.Role == "assistant" && strings.TrimSpace(m.Content) != "" {
+					return m.Content, spawnResp.ChildSessionKey, nil
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return "", spawnResp.ChildSessionKey, fmt.Errorf("identity generation session did not reply within %s", g.timeout)
+		case <-ticker.C:
+		}
+	}
 }
 
 // buildIdentityGenerationPrompt creates the prompt for identity generation
@@ -340,10 +464,15 @@ func generateMemoryMD(req *GenerateIdentityRequest) string {
 `, req.AgentName, req.Description)
 }
 
-// ParseGeneratedIdentity parses a JSON response from the agent into GeneratedIdentity
+// ParseGeneratedIdentity parses a JSON response from the agent into GeneratedIdentity.
+// Text around the JSON object, such as a markdown code fence, is ignored.
 func ParseGeneratedIdentity(jsonStr string) (*GeneratedIdentity, error) {
+	start, end := strings.Index(jsonStr, "{"), strings.LastIndex(jsonStr, "}")
+	if start < 0 || end < start {
+		return nil, errors.New("failed to parse identity JSON: no JSON object in reply")
+	}
 	var identity GeneratedIdentity
-	if err := json.Unmarshal([]byte(jsonStr), &identity); err != nil {
+	if err := json.Unmarshal([]byte(jsonStr[start:end+1]), &identity); err != nil {
 		return nil, fmt.Errorf("failed to parse identity JSON: %w", err)
 	}
 	return &identity, nil
@@ -0,0 +1,81 @@
+package openclaw
+
+import (
+	"context"
+	"time"
+)
+
+// Sender delivers Mission Control notifications to agents. AgentSender is the
+// production implementation (OpenClaw CLI); FakeSender is used in tests.
+type Sender interface {
+	NotifyAgentAsync(agentID, taskID, title, description string, callback AgentSendCallback)
+	// RenotifyAgentAsync is NotifyAgentAsync for a task the agent was already
+	// sent, embedding what has happened on it so far.
+	RenotifyAgentAsync(agentID, taskID, title, description string, history *TaskHistory, callback AgentSendCallback)
+	NotifySubtaskCompletionAsync(
+		orchestratorAgentID,
+		subtaskID, subtaskTitle, subtaskStatus,
+		parentTaskID, parentTaskTitle,
+		specialistAgentID string,
+		callback AgentSendCallback,
+	)
+	// NotifyReviewRequestAsync asks reviewerAgentID to review agentID's work
+	// on a task and submit a verdict.
+	NotifyReviewRequestAsync(reviewerAgentID, taskID, title, description, agentID, gitBranch string, callback AgentSendCallback)
+	// NotifyMentionAsync tells agentID it was @-mentioned by author in a
+	// task's description or a comment on it (source), quoting text.
+	NotifyMentionAsync(agentID, taskID, title, author, source, text string, callback AgentSendCallback)
+	RunCommand(ctx context.Context, agentID, prompt string, timeout time.Duration, onOutput func(line string)) (string, error)
+
+	// For returns the sender to use on behalf of ctx (see WithDryRun).
+	For(ctx context.Context) Sender
+	SetDryRun(enabled bool)
+	DryRun() bool
+	Outbox() *Outbox
+	Templates() *Templates
+	SetLocaleResolver(fn func(agentID string) string)
+	SetAPIURLResolver(fn func(agentID string) string)
+	SetShortIDResolver(fn func(taskID string) string)
+	SetRouteResolver(fn func(agentID string) Route)
+	SetSecretsResolver(fn SecretsResolver)
+	SetPathPolicyResolver(fn func(taskID string) []string)
+	SetContextSummaryResolver(fn func(taskID string) string)
+	SetModelResolver(fn func(taskID string) string)
+	SetAssignmentTemplateResolver(fn func(taskID string) string)
+	SetSubtaskResultResolver(fn func(subtaskID string) *SubtaskResult)
+	SetSessionObserver(fn SessionObserver)
+	SetRateLimiter(l RateLimiter)
+	SetDeduper(d *Deduper)
+	SetTimeoutResolver(fn func(agentID, dispatch string) time.Duration)
+	SetSizeLimit(l *SizeLimit)
+	MessageStats() MessageStats
+}
+
+// SessionObserver is told when a live session with an agent starts
+// (active=true) and ends, e.g. to track agent availability. Dry-run
+// deliveries open no session and are not reported.
+type SessionObserver func(agentID string, active bool)
+
+// RateLimiter shares the back-off of rate-limited agents between senders
+// and the queue: sends wait while an agent is held back, and report their
+// outcome so a rate limit holds back the others too.
+type RateLimiter interface {
+	BlockedUntil(agentID string) (time.Time, bool)
+	// Observe records the outcome of a send, reporting when dispatch may
+	// resume if err was a rate limit.
+	Observe(agentID string, err error) (time.Time, bool)
+}
+
+// Gateway is the subset of the OpenClaw Gateway API used by Mission Control.
+// Client is the production implementation; FakeGateway is used in tests.
+type Gateway interface {
+	Spawn(ctx context.Context, req *SpawnRequest) (*SpawnResponse, error)
+	SendMessage(ctx context.Context, sessionKey, message string) error
+	GetSessionHistory(ctx context.Context, sessionKey string, limit int) (*SessionHistoryResponse, error)
+	GetStatus(ctx context.Context) (bool, error)
+}
+
+var (
+	_ Sender  = (*AgentSender)(nil)
+	_ Gateway = (*Client)(nil)
+)
@@ -0,0 +1,177 @@
+package openclaw
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultMaxMessageSize is the largest message sent to an agent by default,
+// in bytes: well under the 128 KiB a single command-line argument may take
+// on Linux, which is how the CLI transport passes the message.
+const DefaultMaxMessageSize = 64 * 1024
+
+// SizeLimit keeps messages to agents within a maximum size and counts how
+// often they had to be cut. A long task description is shortened first (see
+// renderTaskAssignment), so the rest of the assignment survives; any message
+// still too long is cut at the end, with a pointer to fetch the task from the
+// API. Copies of a sender returned by For share it.
+type SizeLimit struct {
+	max int
+
+	mu    sync.Mutex
+	since time.Time
+	kinds map[string]*KindMessageStats
+}
+
+// MessageStats counts the messages sent to agents since the process started,
+// and how many of them were truncated.
+type MessageStats struct {
+	MaxMessageSize int                          `json:"max_message_size"` // bytes; 0 = unlimited
+	Since          time.Time                    `json:"since"`
+	Messages       int64                        `json:"messages"`
+	Truncated      int64                        `json:"truncated"`
+	BytesCut       int64                        `json:"bytes_cut"`
+	ByKind         map[string]*KindMessageStats `json:"by_kind"`
+}
+
+// KindMessageStats counts the messages of one kind, e.g. task_assignment.
+type KindMessageStats struct {
+	Messages  int64 `json:"messages"`
+	Truncated int64 `json:"truncated"`
+	BytesCut  int64 `json:"bytes_cut"`
+}
+
+// NewSizeLimit returns a SizeLimit cutting messages to max bytes, or only
+// counting them if max is not positive.
+func NewSizeLimit(max int) *SizeLimit {
+	if max < 0 {
+		max = 0
+	}
+	return &SizeLimit{max: max, since: time.Now().UTC(), kinds: make(map[string]*KindMessageStats)}
+}
+
+// Max returns the maximum message size in bytes, 0 for unlimited. A nil
+// SizeLimit is unlimited.
+func (l *SizeLimit) Max() int {
+	if l == nil {
+		return 0
+	}
+	return l.max
+}
+
+// over returns by how many bytes message exceeds the limit, 0 if it fits.
+func (l *SizeLimit) over(message string) int {
+	if l.Max() == 0 || len(message) <= l.max {
+		return 0
+	}
+	return len(message) - l.max
+}
+
+// Fit counts a message of kind about to be sent, having had cut bytes taken
+// out of it already, and returns it cut to the limit if it is still too long.
+// The cut is marked with how much was left out and, when the message is about
+// a task, where to fetch the task from (taskURL).
+func (l *SizeLimit) Fit(kind, message string, cut int, taskURL string) string {
+	if l == nil {
+		return message
+	}
+	if l.over(message) > 0 {
+		total := len(message) + cut
+		// The note's counts take no more digits than total
+		keep := l.max - len(truncationNote(total, total, taskURL))
+		if keep < 0 {
+			keep = 0
+		}
+		for keep > 0 && !utf8.RuneStart(message[keep]) {
+			keep--
+		}
+		cut += len(message) - keep
+		message = message[:keep] + truncationNote(cut, total, taskURL)
+		log.Printf("[AgentSender] Cut %s message by %d of %d bytes to fit %d bytes", kind, cut, total, l.max)
+	}
+	l.record(kind, cut)
+	return message
+}
+
+// truncationNote marks the end of a message cut short.
+func truncationNote(cut, total int, taskURL string) string {
+	note := fmt.Sprintf("\n\n[Message truncated: %d of %d bytes left out.", cut, total)
+	if taskURL != "" {
+		note += fmt.Sprintf(" Fetch the full task from the API: curl %q", taskURL)
+	}
+	return note + "]"
+}
+
+// taskURL is where the agent can fetch taskID from, "" for no task.
+func taskURL(missionControlURL, taskID string) string {
+	if taskID == "" {
+		return ""
+	}
+	return missionControlURL + "/tasks/" + taskID
+}
+
+// record counts a message of kind with cut bytes left out.
+func (l *SizeLimit) record(kind string, cut int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	k := l.kinds[kind]
+	if k == nil {
+		k = &KindMessageStats{}
+		l.kinds[kind] = k
+	}
+	k.Messages++
+	if cut > 0 {
+		k.Truncated++
+		k.BytesCut += int64(cut)
+	}
+}
+
+// Stats returns the counts so far. A nil SizeLimit has none.
+func (l *SizeLimit) Stats() MessageStats {
+	stats := MessageStats{ByKind: map[string]*KindMessageStats{}}
+	if l == nil {
+		return stats
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	stats.MaxMessageSize, stats.Since = l.max, l.since
+	for kind, k := range l.kinds {
+		c := *k
+		stats.ByKind[kind] = &c
+		stats.Messages += c.Messages
+		stats.Truncated += c.Truncated
+		stats.BytesCut += c.BytesCut
+	}
+	return stats
+}
+
+// renderTaskAssignment renders a task assignment (see Templates.renderWith)
+// within the limit, shortening the description if the whole does not fit.
+// It returns the message and the bytes cut from the description; the agent
+// is told to read the rest from the API.
+func renderTaskAssignment(t *Templates, content, locale string, data TaskAssignmentData, l *SizeLimit) (string, int) {
+	message := t.renderWith(TemplateTaskAssignment, content, locale, data)
+	description := data.Description
+	over := l.over(message)
+	if over == 0 || description == "" {
+		return message, 0
+	}
+	// Twice: the note saying it was shortened takes room as well
+	for i := 0; i < 2 && over > 0; i++ {
+		keep := len(data.Description) - over
+		if keep <= 0 {
+			data.Description = ""
+		} else {
+			data.Description = truncateText(data.Description, keep)
+		}
+		data.DescriptionTruncated = true
+		message = t.renderWith(TemplateTaskAssignment, content, locale, data)
+		over = l.over(message)
+	}
+	cut := len(description) - len(data.Description)
+	log.Printf("[AgentSender] Task %s description cut by %d of %d bytes to fit a %d-byte message", data.TaskID, cut, len(description), l.Max())
+	return message, cut
+}
@@ -0,0 +1,88 @@
+package openclaw
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// defaultOutboxSize is how many dry-run notifications are kept in memory.
+const defaultOutboxSize = 200
+
+// OutboxEntry is a notification that would have been sent to an agent
+// if dry-run mode had not been active.
+type OutboxEntry struct {
+	ID        string    `json:"id"`
+	Kind      string    `json:"kind"` // task_assignment | subtask_completion | review_request | mention | agent_run
+	AgentID   string    `json:"agent_id"`
+	TaskID    string    `json:"task_id,omitempty"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Outbox is a bounded in-memory log of dry-run notifications, newest last.
+type Outbox struct {
+	mu      sync.RWMutex
+	entries []OutboxEntry
+	size    int
+}
+
+// NewOutbox creates an Outbox that keeps the most recent size entries.
+func NewOutbox(size int) *Outbox {
+	if size <= 0 {
+		size = defaultOutboxSize
+	}
+	return &Outbox{size: size}
+}
+
+// Add records a notification and drops the oldest entry when full.
+func (o *Outbox) Add(kind, agentID, taskID, message string) OutboxEntry {
+	entry := OutboxEntry{
+		ID:        uuid.New().String(),
+		Kind:      kind,
+		AgentID:   agentID,
+		TaskID:    taskID,
+		Message:   message,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.entries = append(o.entries, entry)
+	if len(o.entries) > o.size {
+		o.entries = o.entries[len(o.entries)-o.size:]
+	}
+	return entry
+}
+
+// List returns a copy of all recorded entries, oldest first.
+func (o *Outbox) List() []OutboxEntry {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	result := make([]OutboxEntry, len(o.entries))
+	copy(result, o.entries)
+	return result
+}
+
+// Clear removes all recorded entries.
+func (o *Outbox) Clear() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.entries = nil
+}
+
+type dryRunKey struct{}
+
+// WithDryRun marks ctx so that notifications sent on its behalf are recorded
+// to the outbox instead of reaching OpenClaw.
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// IsDryRun reports whether ctx was marked with WithDryRun.
+func IsDryRun(ctx context.Context) bool {
+	v, _ := ctx.Value(dryRunKey{}).(bool)
+	return v
+}
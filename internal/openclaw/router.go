@@ -0,0 +1,107 @@
+package openclaw
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// errNoGateway is returned for calls that resolve to no gateway at all.
+var errNoGateway = errors.New("no OpenClaw Gateway configured")
+
+// GatewayLookup returns the gateway agentID lives on: an ID identifying it
+// and its connection settings, or ok=false for the default gateway.
+type GatewayLookup func(agentID string) (id string, cfg Config, ok bool)
+
+// GatewayRouter is a Gateway that sends each call to the gateway of the agent
+// it concerns: the agent's own gateway when it has one, else the default
+// (env-configured) gateway. Session keys name their agent
+// ("agent:<id>:..."), so session calls route without extra arguments.
+type GatewayRouter struct {
+	fallback Gateway
+	lookup   GatewayLookup
+
+	mu      sync.Mutex
+	clients map[string]routedClient // by gateway ID
+}
+
+type routedClient struct {
+	cfg    Config
+	client *Client
+}
+
+// NewGatewayRouter creates a GatewayRouter over the default gateway fallback
+// (may be nil) and the per-agent lookup.
+func NewGatewayRouter(fallback Gateway, lookup GatewayLookup) *GatewayRouter {
+	return &GatewayRouter{
+		fallback: fallback,
+		lookup:   lookup,
+		clients:  make(map[string]routedClient),
+	}
+}
+
+// For returns the gateway agentID lives on (nil if there is none).
+func (r *GatewayRouter) For(agentID string) Gateway {
+	if r.lookup == nil || agentID == "" {
+		return r.fallback
+	}
+	id, cfg, ok := r.lookup(agentID)
+	if !ok {
+		return r.fallback
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Reuse the client until the gateway's settings change
+	if c, ok := r.clients[id]; ok && c.cfg == cfg {
+		return c.client
+	}
+	client := NewClient(&cfg)
+	r.clients[id] = routedClient{cfg: cfg, client: client}
+	return client
+}
+
+// SessionAgentID returns the agent a session key belongs to, or "" if the
+// key does not name one.
+func SessionAgentID(sessionKey string) string {
+	parts := strings.SplitN(sessionKey, ":", 3)
+	if len(parts) < 2 || parts[0] != "agent" {
+		return ""
+	}
+	return parts[1]
+}
+
+func (r *GatewayRouter) Spawn(ctx context.Context, req *SpawnRequest) (*SpawnResponse, error) {
+	gw := r.For(req.AgentID)
+	if gw == nil {
+		return nil, errNoGateway
+	}
+	return gw.Spawn(ctx, req)
+}
+
+func (r *GatewayRouter) SendMessage(ctx context.Context, sessionKey, message string) error {
+	gw := r.For(SessionAgentID(sessionKey))
+	if gw == nil {
+		return errNoGateway
+	}
+	return gw.SendMessage(ctx, sessionKey, message)
+}
+
+func (r *GatewayRouter) GetSessionHistory(ctx context.Context, sessionKey string, limit int) (*SessionHistoryResponse, error) {
+	gw := r.For(SessionAgentID(sessionKey))
+	if gw == nil {
+		return nil, errNoGateway
+	}
+	return gw.GetSessionHistory(ctx, sessionKey, limit)
+}
+
+// GetStatus reports the status of the default gateway.
+func (r *GatewayRouter) GetStatus(ctx context.Context) (bool, error) {
+	if r.fallback == nil {
+		return false, errNoGateway
+	}
+	return r.fallback.GetStatus(ctx)
+}
+
+var _ Gateway = (*GatewayRouter)(nil)
@@ -0,0 +1,82 @@
+package openclaw
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Secret is a project secret released into one task assignment notification.
+// Secrets reach the agent in the message, or for CLI deliveries in a file
+// the message points to (see stageSecrets): dry-run recordings leave them
+// out, and their values are scrubbed from the reply and error handed to the
+// callback, which may persist them.
+type Secret struct {
+	Name  string
+	Value string
+}
+
+// SecretsResolver returns the secrets to inject into the assignment
+// notification of taskID for agentID. It is called once per notification
+// actually sent, so it can record each release.
+type SecretsResolver func(agentID, taskID string) []Secret
+
+// redactSecrets replaces every secret value in s with [secret NAME].
+func redactSecrets(s string, secrets []Secret) string {
+	for _, secret := range secrets {
+		if secret.Value != "" {
+			s = strings.ReplaceAll(s, secret.Value, "[secret "+secret.Name+"]")
+		}
+	}
+	return s
+}
+
+// redactError returns err with secret values scrubbed from its message.
+func redactError(err error, secrets []Secret) error {
+	if err == nil || len(secrets) == 0 {
+		return err
+	}
+	msg := redactSecrets(err.Error(), secrets)
+	if msg == err.Error() {
+		return err
+	}
+	return errors.New(msg)
+}
+
+// stageSecrets prepares secrets for a delivery by route. The CLI passes the
+// message on the command line, where any local user can read it in the
+// process list, so for CLI deliveries the values go to a file only this
+// user can read, as shell assignments, and the message carries only the
+// names and the file's path. It returns the secrets to render into the
+// message, the file ("" if none) and the function removing it.
+func stageSecrets(route Route, secrets []Secret) ([]Secret, string, func(), error) {
+	if len(secrets) == 0 || (route.Method != DeliveryCLI && route.Method != "") {
+		return secrets, "", func() {}, nil
+	}
+	f, err := os.CreateTemp("", "mission-control-secrets-*.env") // mode 0600
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("write secrets file: %w", err)
+	}
+	remove := func() { os.Remove(f.Name()) }
+	names := make([]Secret, len(secrets))
+	var b strings.Builder
+	for i, secret := range secrets {
+		names[i] = Secret{Name: secret.Name}
+		fmt.Fprintf(&b, "%s=%s\n", secret.Name, shellQuote(secret.Value))
+	}
+	_, err = f.WriteString(b.String())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		remove()
+		return nil, "", nil, fmt.Errorf("write secrets file: %w", err)
+	}
+	return names, f.Name(), remove, nil
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,75 @@
+package openclaw
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestStageSecretsCLI(t *testing.T) {
+	secrets := []Secret{{Name: "DEPLOY_TOKEN", Value: "s3cr'et"}, {Name: "DB_URL", Value: "postgres://u:p@db/x"}}
+	shown, path, remove, err := stageSecrets(Route{Method: DeliveryCLI}, secrets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path == "" {
+		t.Fatal("no secrets file for a CLI delivery")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("secrets file mode %o, want 600", mode)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "DEPLOY_TOKEN='s3cr'\\''et'\nDB_URL='postgres://u:p@db/x'\n"
+	if string(data) != want {
+		t.Errorf("secrets file:\n%s\nwant:\n%s", data, want)
+	}
+	for _, s := range shown {
+		if s.Value != "" {
+			t.Errorf("secret %s keeps its value in the message", s.Name)
+		}
+	}
+
+	message, _ := renderTaskAssignment(NewTemplates(""), "", "", TaskAssignmentData{
+		TaskID:      "task-1",
+		Title:       "Deploy",
+		Secrets:     shown,
+		SecretsFile: path,
+	}, nil)
+	for _, s := range secrets {
+		if strings.Contains(message, s.Value) {
+			t.Errorf("message holds the value of %s:\n%s", s.Name, message)
+		}
+		if !strings.Contains(message, s.Name) {
+			t.Errorf("message does not name %s:\n%s", s.Name, message)
+		}
+	}
+	if !strings.Contains(message, path) {
+		t.Errorf("message does not point to the secrets file:\n%s", message)
+	}
+
+	remove()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("secrets file not removed: %v", err)
+	}
+}
+
+func TestStageSecretsOtherTransports(t *testing.T) {
+	secrets := []Secret{{Name: "DEPLOY_TOKEN", Value: "s3cret"}}
+	for _, method := range []string{DeliveryGateway, DeliveryHTTPCallback} {
+		shown, path, remove, err := stageSecrets(Route{Method: method}, secrets)
+		if err != nil {
+			t.Fatal(err)
+		}
+		remove()
+		if path != "" || len(shown) != 1 || shown[0].Value != "s3cret" {
+			t.Errorf("%s: got %v in %q, want the secrets in the message", method, shown, path)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package openclaw
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// summaryPollInterval is how often a summarization session is checked for
+// its reply.
+const summaryPollInterval = 2 * time.Second
+
+// Summarizer condenses a task's history into a context summary by spawning a
+// one-off session on the OpenClaw Gateway and waiting for its reply.
+type Summarizer struct {
+	gateway Gateway
+	poll    time.Duration
+}
+
+// NewSummarizer creates a Summarizer spawning its sessions on gateway.
+func NewSummarizer(gateway Gateway) *Summarizer {
+	return &Summarizer{gateway: gateway, poll: summaryPollInterval}
+}
+
+// SummaryRequest is the task history to summarize. Progress and Comments are
+// oldest first; with a PreviousSummary they hold only what came after it.
+type SummaryRequest struct {
+	TaskID          string
+	Title           string
+	Description     string
+	PreviousSummary string
+	Progress        []string
+	Comments        []string
+}
+
+// Summarize returns a summary of req. It waits for the session's reply until
+// ctx is done, so callers should give ctx a deadline.
+func (s *Summarizer) Summarize(ctx context.Context, req SummaryRequest) (string, error) {
+	if s.gateway == nil {
+		return "", errNoGateway
+	}
+	spawnResp, err := s.gateway.Spawn(ctx, &SpawnRequest{
+		Task:    buildSummaryPrompt(req),
+		Label:   fmt.Sprintf("context-summary-%s-%d", req.TaskID, time.Now().Unix()),
+		Cleanup: "delete",
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to spawn summarization session: %w", err)
+	}
+
+	ticker := time.NewTicker(s.poll)
+	defer ticker.Stop()
+	for {
+		history, err := s.gateway.GetSessionHistory(ctx, spawnResp.ChildSessionKey, 0)
+		if err == nil {
+			for i := len(history.Messages) - 1; i >= 0; i-- {
+				m := history.Messages[i]
+				if m.Role == "assistant" && strings.TrimSpace(m.Content) != "" {
+					return strings.TrimSpace(m.Content), nil
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("summarization session %s did not reply: %w", spawnResp.ChildSessionKey, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// buildSummaryPrompt creates the prompt for a summarization session.
+func buildSummaryPrompt(req SummaryRequest) string {
+	var b strings.Builder
+	b.WriteString("You are summarizing a Mission Control task so that an agent picking it up again does not start blind.\n\n")
+	fmt.Fprintf(&b, "## Task\n- **Title:** %s\n", req.Title)
+	if req.Description != "" {
+		fmt.Fprintf(&b, "- **Description:** %s\n", req.Description)
+	}
+	if req.PreviousSummary != "" {
+		fmt.Fprintf(&b, "\n## Summary So Far\n%s\n", req.PreviousSummary)
+	}
+	if len(req.Progress) > 0 {
+		b.WriteString("\n## Progress Notes\n")
+		for _, p := range req.Progress {
+			fmt.Fprintf(&b, "- %s\n", p)
+		}
+	}
+	if len(req.Comments) > 0 {
+		b.WriteString("\n## Comments\n")
+		for _, c := range req.Comments {
+			fmt.Fprintf(&b, "- %s\n", c)
+		}
+	}
+	b.WriteString(`
+## Your Task
+Write an updated summary of the task's state that replaces the summary so far (if any):
+what has been done, what was decided and why, what failed, and what remains.
+Keep file paths, commands and identifiers exact. Stay under 400 words.
+
+Return ONLY the summary text.`)
+	return b.String()
+}
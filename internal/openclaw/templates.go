@@ -0,0 +1,440 @@
+package openclaw
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/i18n"
+)
+
+//go:embed templates/*.tmpl templates/*/*.tmpl
+var defaultTemplatesFS embed.FS
+
+// Notification template names. Each maps to <name>.tmpl, either embedded or
+// in the override directory; localized variants live in a <locale>/
+// subdirectory (e.g. es/task_assignment.tmpl).
+const (
+	TemplateTaskAssignment    = "task_assignment"
+	TemplateSubtaskCompletion = "subtask_completion"
+	TemplateReviewRequest     = "review_request"
+	TemplateMention           = "mention"
+)
+
+// TaskAssignmentData is the data passed to the task_assignment template.
+type TaskAssignmentData struct {
+	TaskID               string
+	ShortID              string
+	Title                string
+	Description          string
+	DescriptionTruncated bool // Description was cut short to keep the message within the maximum size
+	MissionControlURL    string
+	Secrets              []Secret     // project secrets released to this task; never persisted
+	SecretsFile          string       // file holding the secrets' values, which Secrets then leaves out (CLI deliveries)
+	AllowedPaths         []string     // paths the task's project lets the agent touch; empty = unrestricted
+	ContextSummary       string       // summary of earlier work on the task, for agents picking it up again
+	Model                string       // model the task was routed to; empty = the agent's own
+	History              *TaskHistory // set on re-notifications only
+}
+
+// TaskHistory is what has happened on a task so far, embedded in
+// re-notifications so the agent does not repeat finished work.
+type TaskHistory struct {
+	Progress []string     // latest progress entries, oldest first
+	Comments []string     // latest comments, oldest first, each prefixed with its author
+	Stories  []StoryState // in sequence order
+}
+
+// StoryState is the current state of one story of a task.
+type StoryState struct {
+	Title      string
+	Passed     bool
+	Iterations int
+	LastError  string
+}
+
+// SubtaskCompletionData is the data passed to the subtask_completion template.
+type SubtaskCompletionData struct {
+	SubtaskID         string
+	SubtaskShortID    string
+	SubtaskTitle      string
+	SubtaskStatus     string
+	ParentTaskID      string
+	ParentShortID     string
+	ParentTaskTitle   string
+	SpecialistAgentID string
+	MissionControlURL string
+	Result            *SubtaskResult // what the specialist produced (nil if unknown)
+}
+
+// SubtaskResult is what a specialist produced on a subtask, for the
+// orchestrator to act on without fetching the subtask first.
+type SubtaskResult struct {
+	FinalReply    string // the specialist's last comment on the subtask
+	StoriesPassed int
+	StoriesTotal  int
+	Progress      []string // latest progress entries, oldest first
+}
+
+// ReviewRequestData is the data passed to the review_request template.
+type ReviewRequestData struct {
+	TaskID            string
+	ShortID           string
+	Title             string
+	Description       string
+	AgentID           string // agent whose work is under review
+	GitBranch         string
+	MissionControlURL string
+	Result            *SubtaskResult // what the agent produced (nil if unknown)
+}
+
+// MentionData is the data passed to the mention template.
+type MentionData struct {
+	TaskID            string
+	ShortID           string
+	Title             string
+	Author            string // who wrote the mention (may be empty)
+	Source            string // description | comment
+	Text              string // the description or comment, capped in size
+	MissionControlURL string
+}
+
+// mentionTextMaxBytes caps the text a mention message quotes.
+const mentionTextMaxBytes = 4000
+
+// Caps on the text a SubtaskResult adds to a subtask completion message, in
+// all and per progress entry; the full result is a curl away.
+const (
+	subtaskResultMaxBytes   = 4000
+	subtaskProgressMaxBytes = 500
+)
+
+// capped returns r with its text cut to fit max bytes: the final reply gets
+// up to half, the newest progress entries (each cut to
+// subtaskProgressMaxBytes) what is left.
+func (r *SubtaskResult) capped(max int) *SubtaskResult {
+	if r == nil {
+		return nil
+	}
+	out := *r
+	out.FinalReply = truncateText(r.FinalReply, max/2)
+	budget := max - len(out.FinalReply)
+	var progress []string
+	for i := len(r.Progress) - 1; i >= 0 && budget > 0; i-- {
+		p := truncateText(r.Progress[i], subtaskProgressMaxBytes)
+		if len(p) > budget {
+			p = truncateText(p, budget)
+		}
+		if p == "" {
+			break
+		}
+		budget -= len(p)
+		progress = append([]string{p}, progress...)
+	}
+	out.Progress = progress
+	return &out
+}
+
+// truncateText cuts s to at most max bytes on a rune boundary, marking the
+// cut with an ellipsis.
+func truncateText(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	const ellipsis = "…"
+	if max <= len(ellipsis) {
+		return ""
+	}
+	cut := max - len(ellipsis)
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + ellipsis
+}
+
+// TemplateVariable documents one field available to a template.
+type TemplateVariable struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// TemplateInfo describes a notification template for listing and preview.
+type TemplateInfo struct {
+	Name      string             `json:"name"`
+	Locale    string             `json:"locale,omitempty"` // set when a localized variant is active
+	Source    string             `json:"source"`           // default | override
+	Path      string             `json:"path,omitempty"`
+	Content   string             `json:"content"`
+	Variables []TemplateVariable `json:"variables"`
+}
+
+// templateVariables documents the fields of each template's data struct.
+var templateVariables = map[string][]TemplateVariable{
+	TemplateTaskAssignment: {
+		{Name: "TaskID", Description: "ID of the assigned task"},
+		{Name: "ShortID", Description: "Short ID of the assigned task, e.g. MC-142 (may be empty)"},
+		{Name: "Title", Description: "Task title"},
+		{Name: "Description", Description: "Task description (may be empty)"},
+		{Name: "DescriptionTruncated", Description: "Set when the description was cut short to keep the message within NOTIFY_MAX_MESSAGE_SIZE"},
+		{Name: "MissionControlURL", Description: "Base API URL agents use, e.g. http://127.0.0.1:8080/api/v1"},
+		{Name: "Secrets", Description: "Project secrets selected for the task, each with Name and Value (may be empty; left out of dry runs)"},
+		{Name: "SecretsFile", Description: "Set for CLI deliveries, whose message is visible in the process list: the file holding the secrets as shell assignments, removed once the agent answers; Value is then empty"},
+		{Name: "AllowedPaths", Description: "Absolute paths (directories or globs) the project lets the agent touch (empty = unrestricted)"},
+		{Name: "ContextSummary", Description: "Summary of earlier work on the task (empty until the task is summarized)"},
+		{Name: "Model", Description: "Model the task was routed to, already switched to before the message is sent (empty = the agent's own)"},
+		{Name: "History", Description: "Set when re-notifying a stuck task: its latest Progress entries and Comments, and Stories with Title, Passed, Iterations and LastError"},
+	},
+	TemplateSubtaskCompletion: {
+		{Name: "SubtaskID", Description: "ID of the subtask that finished"},
+		{Name: "SubtaskShortID", Description: "Short ID of the subtask, e.g. MC-143 (may be empty)"},
+		{Name: "SubtaskTitle", Description: "Subtask title"},
+		{Name: "SubtaskStatus", Description: "Terminal status of the subtask (done or failed)"},
+		{Name: "ParentTaskID", Description: "ID of the parent task owned by the orchestrator"},
+		{Name: "ParentShortID", Description: "Short ID of the parent task (may be empty)"},
+		{Name: "ParentTaskTitle", Description: "Parent task title"},
+		{Name: "SpecialistAgentID", Description: "Agent that worked on the subtask"},
+		{Name: "MissionControlURL", Description: "Base API URL agents use, e.g. http://127.0.0.1:8080/api/v1"},
+		{Name: "Result", Description: "What the specialist produced (may be nil): FinalReply, StoriesPassed, StoriesTotal and the latest Progress entries, capped in size"},
+	},
+	TemplateReviewRequest: {
+		{Name: "TaskID", Description: "ID of the task to review"},
+		{Name: "ShortID", Description: "Short ID of the task, e.g. MC-142 (may be empty)"},
+		{Name: "Title", Description: "Task title"},
+		{Name: "Description", Description: "Task description (may be empty)"},
+		{Name: "AgentID", Description: "Agent whose work is under review"},
+		{Name: "GitBranch", Description: "Git branch of the work (may be empty)"},
+		{Name: "MissionControlURL", Description: "Base API URL agents use, e.g. http://127.0.0.1:8080/api/v1"},
+		{Name: "Result", Description: "What the agent produced (may be nil): FinalReply, StoriesPassed, StoriesTotal and the latest Progress entries, capped in size"},
+	},
+	TemplateMention: {
+		{Name: "TaskID", Description: "ID of the task the mention is on"},
+		{Name: "ShortID", Description: "Short ID of the task, e.g. MC-142 (may be empty)"},
+		{Name: "Title", Description: "Task title"},
+		{Name: "Author", Description: "Who wrote the mention (may be empty)"},
+		{Name: "Source", Description: "Where the mention is: description or comment"},
+		{Name: "Text", Description: "The description or comment holding the mention, capped in size"},
+		{Name: "MissionControlURL", Description: "Base API URL agents use, e.g. http://127.0.0.1:8080/api/v1"},
+	},
+}
+
+// sampleTemplateData is used by previews when the caller supplies no data.
+var sampleTemplateData = map[string]interface{}{
+	TemplateTaskAssignment: TaskAssignmentData{
+		TaskID:            "00000000-0000-0000-0000-000000000001",
+		ShortID:           "MC-1",
+		Title:             "Example task",
+		Description:       "Example description",
+		MissionControlURL: "http://127.0.0.1:8080/api/v1",
+		Secrets:           []Secret{{Name: "DEPLOY_TOKEN", Value: "example-token"}},
+		AllowedPaths:      []string{"/srv/example"},
+		ContextSummary:    "Login form done; session handling still failing its tests.",
+		Model:             "anthropic/claude-sonnet-4-5",
+		History: &TaskHistory{
+			Progress: []string{"Login form renders and submits"},
+			Comments: []string{"jarvis: Use the existing session store"},
+			Stories: []StoryState{
+				{Title: "Login form", Passed: true, Iterations: 1},
+				{Title: "Session handling", Iterations: 2, LastError: "cookie not set"},
+			},
+		},
+	},
+	TemplateSubtaskCompletion: SubtaskCompletionData{
+		SubtaskID:         "00000000-0000-0000-0000-000000000002",
+		SubtaskShortID:    "MC-2",
+		SubtaskTitle:      "Example subtask",
+		SubtaskStatus:     "done",
+		ParentTaskID:      "00000000-0000-0000-0000-000000000001",
+		ParentShortID:     "MC-1",
+		ParentTaskTitle:   "Example task",
+		SpecialistAgentID: "specialist",
+		MissionControlURL: "http://127.0.0.1:8080/api/v1",
+		Result: &SubtaskResult{
+			FinalReply:    "Implemented the login endpoint; all tests pass.",
+			StoriesPassed: 3,
+			StoriesTotal:  3,
+			Progress:      []string{"Added bcrypt password hashing"},
+		},
+	},
+	TemplateReviewRequest: ReviewRequestData{
+		TaskID:            "00000000-0000-0000-0000-000000000001",
+		ShortID:           "MC-1",
+		Title:             "Example task",
+		Description:       "Example description",
+		AgentID:           "specialist",
+		GitBranch:         "feature/login",
+		MissionControlURL: "http://127.0.0.1:8080/api/v1",
+		Result: &SubtaskResult{
+			FinalReply:    "Implemented the login endpoint; all tests pass.",
+			StoriesPassed: 3,
+			StoriesTotal:  3,
+			Progress:      []string{"Added bcrypt password hashing"},
+		},
+	},
+	TemplateMention: MentionData{
+		TaskID:            "00000000-0000-0000-0000-000000000001",
+		ShortID:           "MC-1",
+		Title:             "Example task",
+		Author:            "jarvis",
+		Source:            "comment",
+		Text:              "@researcher can you confirm which OAuth providers we must support?",
+		MissionControlURL: "http://127.0.0.1:8080/api/v1",
+	},
+}
+
+// Templates renders agent notification messages. Defaults are embedded in
+// the binary; a file named <name>.tmpl in dir overrides the default and is
+// re-read on every render, so edits take effect without a restart.
+//
+// For a locale such as "es-mx" the lookup order is: dir/es-mx, embedded
+// es-mx, dir/es, embedded es, then dir and the embedded default.
+type Templates struct {
+	dir string
+}
+
+// NewTemplates creates a Templates that looks for overrides in dir
+// (empty = embedded defaults only).
+func NewTemplates(dir string) *Templates {
+	return &Templates{dir: dir}
+}
+
+// Names returns the known template names, sorted.
+func (t *Templates) Names() []string {
+	names := make([]string, 0, len(templateVariables))
+	for name := range templateVariables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Info returns the active source and documentation for a template in locale
+// ("" = default).
+func (t *Templates) Info(name, locale string) (TemplateInfo, error) {
+	vars, ok := templateVariables[name]
+	if !ok {
+		return TemplateInfo{}, fmt.Errorf("unknown template %q", name)
+	}
+	src, err := t.source(name, locale)
+	if err != nil {
+		return TemplateInfo{}, err
+	}
+	info := TemplateInfo{Name: name, Locale: src.locale, Source: "default", Content: src.content, Variables: vars}
+	if src.path != "" {
+		info.Source = "override"
+		info.Path = src.path
+	}
+	return info, nil
+}
+
+// SampleData returns example data for previewing a template.
+func (t *Templates) SampleData(name string) interface{} {
+	return sampleTemplateData[name]
+}
+
+// Render executes the active template for name in locale ("" = default) with data.
+func (t *Templates) Render(name, locale string, data interface{}) (string, error) {
+	src, err := t.source(name, locale)
+	if err != nil {
+		return "", err
+	}
+	return RenderTemplate(name, src.content, data)
+}
+
+// RenderTemplate executes template text with data. Missing keys are errors so
+// typos in overrides surface in previews rather than in agent prompts.
+func RenderTemplate(name, content string, data interface{}) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("parse template %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("execute template %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+type templateSource struct {
+	content string
+	path    string // override file, "" for embedded
+	locale  string // "" for the unlocalized template
+}
+
+// source returns the active template text for name in locale.
+func (t *Templates) source(name, locale string) (templateSource, error) {
+	if _, ok := templateVariables[name]; !ok {
+		return templateSource{}, fmt.Errorf("unknown template %q", name)
+	}
+	for _, loc := range localeFallbacks(locale) {
+		file := name + ".tmpl"
+		if loc != "" {
+			file = loc + "/" + file
+		}
+		if t != nil && t.dir != "" {
+			path := filepath.Join(t.dir, filepath.FromSlash(file))
+			content, err := os.ReadFile(path)
+			if err == nil {
+				return templateSource{content: string(content), path: path, locale: loc}, nil
+			}
+			if !os.IsNotExist(err) {
+				log.Printf("[Templates] Failed to read override %s, skipping: %v", path, err)
+			}
+		}
+		if content, err := defaultTemplatesFS.ReadFile("templates/" + file); err == nil {
+			return templateSource{content: string(content), locale: loc}, nil
+		}
+	}
+	return templateSource{}, fmt.Errorf("template %q not found", name)
+}
+
+// localeFallbacks returns the locales to try for locale, most specific
+// first, ending with "" (unlocalized).
+func localeFallbacks(locale string) []string {
+	locale = i18n.Normalize(locale)
+	if locale == "" || locale == i18n.DefaultLocale || strings.ContainsAny(locale, `./\`) {
+		return []string{""}
+	}
+	result := []string{locale}
+	if base, _, found := strings.Cut(locale, "-"); found {
+		result = append(result, base)
+	}
+	return append(result, "")
+}
+
+// renderOrDefault renders name with data, falling back to the embedded
+// default if the override is broken so agents are never left without a
+// message.
+func (t *Templates) renderOrDefault(name, locale string, data interface{}) string {
+	message, err := t.Render(name, locale, data)
+	if err == nil {
+		return message
+	}
+	log.Printf("[Templates] %v; falling back to default template", err)
+	message, err = NewTemplates("").Render(name, locale, data)
+	if err != nil {
+		// The embedded defaults are fixed at build time; this is a programming error.
+		panic(err)
+	}
+	return message
+}
+
+// renderWith renders content as template name with data, falling back to
+// the active template for locale if content is "" or fails to render (see
+// renderOrDefault).
+func (t *Templates) renderWith(name, content, locale string, data interface{}) string {
+	if content != "" {
+		message, err := RenderTemplate(name, content, data)
+		if err == nil {
+			return message
+		}
+		log.Printf("[Templates] %v; falling back to the active template", err)
+	}
+	return t.renderOrDefault(name, locale, data)
+}
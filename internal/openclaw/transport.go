@@ -0,0 +1,240 @@
+package openclaw
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Delivery methods: how notifications reach an agent.
+const (
+	DeliveryCLI          = "cli"           // `openclaw agent` on this host (the default)
+	DeliveryGateway      = "gateway"       // sessions_send to the agent's main session on the Gateway
+	DeliveryHTTPCallback = "http_callback" // signed POST to the agent's callback URL
+)
+
+// ValidDeliveryMethod reports whether method names a delivery method.
+func ValidDeliveryMethod(method string) bool {
+	switch method {
+	case DeliveryCLI, DeliveryGateway, DeliveryHTTPCallback:
+		return true
+	}
+	return false
+}
+
+// Route is how to reach one agent.
+type Route struct {
+	Method      string // one of the Delivery* constants; "" means DeliveryCLI
+	CallbackURL string // http_callback only
+	Secret      string // http_callback only: key the payload is signed with
+}
+
+// Delivery is one notification on its way to an agent.
+type Delivery struct {
+	Kind    string // task_assignment | subtask_completion | review_request | mention
+	AgentID string
+	TaskID  string
+	Message string
+}
+
+// MainSessionKey is the key of an agent's main session on the Gateway, where
+// CLI and gateway deliveries to the agent land.
+func MainSessionKey(agentID string) string {
+	return fmt.Sprintf("agent:%s:main", agentID)
+}
+
+// Transport carries a notification to an agent and returns the agent's
+// reply. Failures worth retrying are wrapped with Transient.
+type Transport interface {
+	Send(ctx context.Context, route Route, d Delivery) (string, error)
+}
+
+// transientError marks a delivery failure that may succeed if retried.
+type transientError struct{ err error }
+
+func (e *transientError) Error() string { return e.err.Error() }
+func (e *transientError) Unwrap() error { return e.err }
+
+// Transient marks err as worth retrying (see isRetryableError).
+func Transient(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &transientError{err: err}
+}
+
+// CLITransport delivers through `openclaw agent` on this host; the agent must
+// be in the local OpenClaw config.
+type CLITransport struct{}
+
+// Send executes `openclaw agent --agent <id> --message <text> --json` and
+// returns the agent's reply text.
+func (CLITransport) Send(ctx context.Context, route Route, d Delivery) (string, error) {
+	args := []string{
+		"agent",
+		"--agent", d.AgentID,
+		"--message", d.Message,
+		"--json",
+	}
+
+	log.Printf("[AgentSender] Executing: openclaw %s", strings.Join(args[:3], " "))
+
+	cmd := exec.CommandContext(ctx, "openclaw", args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("agent send timed out: %w", err)
+		}
+		return "", fmt.Errorf("openclaw agent send failed: %s - %w", string(output), err)
+	}
+	return parseReply(output), nil
+}
+
+// GatewayTransport delivers to the agent's main session through the OpenClaw
+// Gateway, for agents the Gateway can reach but the local CLI cannot. The
+// Gateway does not return the agent's reply, so Send always replies "".
+type GatewayTransport struct {
+	gateway Gateway
+}
+
+// NewGatewayTransport creates a GatewayTransport sending through gateway.
+func NewGatewayTransport(gateway Gateway) *GatewayTransport {
+	return &GatewayTransport{gateway: gateway}
+}
+
+func (t *GatewayTransport) Send(ctx context.Context, route Route, d Delivery) (string, error) {
+	if t.gateway == nil {
+		return "", errNoGateway
+	}
+	sessionKey := MainSessionKey(d.AgentID)
+	log.Printf("[AgentSender] Sending %s to agent %s via gateway session %s", d.Kind, d.AgentID, sessionKey)
+	if err := t.gateway.SendMessage(ctx, sessionKey, d.Message); err != nil {
+		return "", fmt.Errorf("gateway send failed: %w", err)
+	}
+	return "", nil
+}
+
+// Headers of http_callback deliveries. The signature is
+// "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body)); receivers
+// should recompute it and reject stale timestamps.
+const (
+	HeaderCallbackEvent     = "X-Mission-Control-Event"
+	HeaderCallbackTimestamp = "X-Mission-Control-Timestamp"
+	HeaderCallbackSignature = "X-Mission-Control-Signature"
+)
+
+// CallbackPayload is the JSON body POSTed to an agent's callback URL.
+type CallbackPayload struct {
+	Kind    string `json:"kind"`
+	AgentID string `json:"agent_id"`
+	TaskID  string `json:"task_id,omitempty"`
+	Message string `json:"message"`
+	SentAt  string `json:"sent_at"` // RFC 3339
+}
+
+// SignCallback returns the signature header value for a callback body sent
+// at timestamp (Unix seconds, as in HeaderCallbackTimestamp).
+func SignCallback(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// HTTPCallbackTransport POSTs notifications to the agent's callback URL,
+// for agents that are not reachable through OpenClaw at all. The agent
+// answers 2xx, optionally with {"reply": "..."}; 5xx, 429 and network errors
+// are retried.
+type HTTPCallbackTransport struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewHTTPCallbackTransport creates an HTTPCallbackTransport using client
+// (http.DefaultClient if nil). Timeouts come from the Send context.
+func NewHTTPCallbackTransport(client *http.Client) *HTTPCallbackTransport {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPCallbackTransport{client: client, now: time.Now}
+}
+
+func (t *HTTPCallbackTransport) Send(ctx context.Context, route Route, d Delivery) (string, error) {
+	if route.CallbackURL == "" {
+		return "", fmt.Errorf("agent %s has no callback URL", d.AgentID)
+	}
+	now := t.now().UTC()
+	body, err := json.Marshal(CallbackPayload{
+		Kind:    d.Kind,
+		AgentID: d.AgentID,
+		TaskID:  d.TaskID,
+		Message: d.Message,
+		SentAt:  now.Format(time.RFC3339),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal callback payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, route.CallbackURL, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create callback request: %w", err)
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderCallbackEvent, d.Kind)
+	req.Header.Set(HeaderCallbackTimestamp, timestamp)
+	if route.Secret != "" {
+		req.Header.Set(HeaderCallbackSignature, SignCallback(route.Secret, timestamp, body))
+	}
+
+	log.Printf("[AgentSender] POSTing %s for agent %s to callback URL", d.Kind, d.AgentID)
+	resp, err := t.client.Do(req)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", Transient(fmt.Errorf("callback timed out: %w", err))
+		}
+		return "", Transient(fmt.Errorf("callback request failed: %w", err))
+	}
+	defer resp.Body.Close()
+
+	output, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err := fmt.Errorf("callback failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(output)))
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+			return "", Transient(err)
+		}
+		return "", err
+	}
+	return parseReply(output), nil
+}
+
+// parseReply extracts the reply from an agent's response: the "reply" field
+// of a JSON result, else the raw text.
+func parseReply(output []byte) string {
+	var result AgentSendResult
+	if err := json.Unmarshal(output, &result); err != nil {
+		if len(bytes.TrimSpace(output)) > 0 {
+			log.Printf("[AgentSender] Could not parse JSON response, using raw output (len=%d)", len(output))
+		}
+		return strings.TrimSpace(string(output))
+	}
+	return result.Reply
+}
+
+var (
+	_ Transport = CLITransport{}
+	_ Transport = (*GatewayTransport)(nil)
+	_ Transport = (*HTTPCallbackTransport)(nil)
+)
@@ -0,0 +1,129 @@
+// Package pathpolicy confines agents to the parts of a machine a project lets
+// them touch. A policy lists allowed paths: directories, which allow
+// everything under them, or glob patterns such as /srv/app/*/src. Agents are
+// told the policy with each task and declare the files they touch; files
+// outside it are violations.
+package pathpolicy
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// What happens when an agent reports files outside the policy.
+const (
+	ActionWarn  = "warn"  // record a policy_violation event (the default)
+	ActionPause = "pause" // also pause the task
+)
+
+// ValidAction reports whether action names a violation action.
+func ValidAction(action string) bool {
+	return action == ActionWarn || action == ActionPause
+}
+
+// Policy is a project's path policy. Relative paths, allowed or reported,
+// are taken relative to Base, the project's location.
+type Policy struct {
+	Allowed []string
+	Base    string
+}
+
+// Parse decodes a stored list of allowed paths. An empty string yields none.
+func Parse(raw string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" || raw == "null" {
+		return nil, nil
+	}
+	var paths []string
+	if err := json.Unmarshal([]byte(raw), &paths); err != nil {
+		return nil, fmt.Errorf("invalid allowed paths: %w", err)
+	}
+	return paths, nil
+}
+
+// Validate checks a list of allowed paths for a project located at base.
+func Validate(paths []string, base string) error {
+	for _, p := range paths {
+		if strings.TrimSpace(p) == "" {
+			return fmt.Errorf("allowed paths must not be empty")
+		}
+		if !path.IsAbs(p) && base == "" {
+			return fmt.Errorf("relative path %q needs a project location", p)
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q", p)
+		}
+	}
+	return nil
+}
+
+// Enabled reports whether the policy restricts anything.
+func (p Policy) Enabled() bool {
+	return len(p.Allowed) > 0
+}
+
+// Resolved returns the allowed paths with relative ones made absolute.
+func (p Policy) Resolved() []string {
+	out := make([]string, 0, len(p.Allowed))
+	for _, a := range p.Allowed {
+		if abs := p.abs(a); abs != "" {
+			out = append(out, abs)
+		}
+	}
+	return out
+}
+
+// Allows reports whether file may be touched. A disabled policy allows
+// everything; a relative file without a Base is never allowed, since where
+// it lives cannot be told.
+func (p Policy) Allows(file string) bool {
+	if !p.Enabled() {
+		return true
+	}
+	f := p.abs(file)
+	if f == "" {
+		return false
+	}
+	allowed := p.Resolved()
+	// A file is allowed if it, or any directory above it, is
+	for candidate := f; ; candidate = path.Dir(candidate) {
+		for _, a := range allowed {
+			if a == candidate {
+				return true
+			}
+			if ok, _ := path.Match(a, candidate); ok {
+				return true
+			}
+		}
+		if candidate == "/" {
+			return false
+		}
+	}
+}
+
+// Violations returns the files the policy does not allow, in order.
+func (p Policy) Violations(files []string) []string {
+	var out []string
+	for _, f := range files {
+		if !p.Allows(f) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// abs returns p cleaned and made absolute against Base, or "" if it cannot be.
+func (p Policy) abs(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ""
+	}
+	if path.IsAbs(name) {
+		return path.Clean(name)
+	}
+	if p.Base == "" || !path.IsAbs(p.Base) {
+		return ""
+	}
+	return path.Join(p.Base, name)
+}
@@ -0,0 +1,294 @@
+// Package planimport reads planning done outside Mission Control, so it can
+// be loaded as a task with its phases and stories: a PRD in the JSON shape
+// Ralph loops use (prd.json), or a markdown roadmap or PRD such as the
+// ROADMAP.md of a GSD run.
+package planimport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Formats a plan can be read from.
+const (
+	FormatPRDJSON  = "prd_json"
+	FormatMarkdown = "markdown"
+)
+
+// ErrEmpty is returned for a plan with neither phases nor stories.
+var ErrEmpty = errors.New("no phases or stories found")
+
+// Plan is a task as planned: what it is, and its phases and stories in order.
+type Plan struct {
+	Title       string
+	Description string
+	GitBranch   string
+	Phases      []Phase
+	Stories     []Story
+}
+
+// Phase is one phase of a roadmap.
+type Phase struct {
+	Title       string
+	Description string
+	Done        bool // checked off in the roadmap
+}
+
+// Story is one user story of a PRD.
+type Story struct {
+	Title              string
+	Description        string
+	Priority           int
+	AcceptanceCriteria []string
+	Passes             bool
+}
+
+// Detect returns the format content is in: PRD JSON if it is a JSON object,
+// markdown otherwise.
+func Detect(content string) string {
+	if strings.HasPrefix(strings.TrimSpace(content), "{") {
+		return FormatPRDJSON
+	}
+	return FormatMarkdown
+}
+
+// Parse reads content in format ("" = detected).
+func Parse(format, content string) (Plan, error) {
+	if format == "" {
+		format = Detect(content)
+	}
+	var plan Plan
+	var err error
+	switch format {
+	case FormatPRDJSON:
+		plan, err = ParsePRD([]byte(content))
+	case FormatMarkdown:
+		plan = ParseMarkdown(content)
+	default:
+		return Plan{}, fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return Plan{}, err
+	}
+	if len(plan.Phases) == 0 && len(plan.Stories) == 0 {
+		return Plan{}, ErrEmpty
+	}
+	return plan, nil
+}
+
+// prd is the prd.json of a Ralph loop.
+type prd struct {
+	Project     string `json:"project"`
+	BranchName  string `json:"branchName"`
+	Description string `json:"description"`
+	UserStories []struct {
+		ID                 string   `json:"id"`
+		Title              string   `json:"title"`
+		Description        string   `json:"description"`
+		AcceptanceCriteria []string `json:"acceptanceCriteria"`
+		Priority           int      `json:"priority"`
+		Passes             bool     `json:"passes"`
+		Notes              string   `json:"notes"`
+	} `json:"userStories"`
+}
+
+// ParsePRD reads a prd.json: the project names the task, userStories become
+// its stories, titled with their IDs, and branchName its git branch.
+func ParsePRD(data []byte) (Plan, error) {
+	var p prd
+	if err := json.Unmarshal(data, &p); err != nil {
+		return Plan{}, fmt.Errorf("invalid PRD JSON: %w", err)
+	}
+	plan := Plan{
+		Title:       strings.TrimSpace(p.Project),
+		Description: strings.TrimSpace(p.Description),
+		GitBranch:   strings.TrimSpace(p.BranchName),
+	}
+	for _, us := range p.UserStories {
+		title := strings.TrimSpace(us.Title)
+		if id := strings.TrimSpace(us.ID); id != "" {
+			title = id + ": " + title
+		}
+		description := strings.TrimSpace(us.Description)
+		if notes := strings.TrimSpace(us.Notes); notes != "" {
+			description = strings.TrimSpace(description + "\n\nNotes: " + notes)
+		}
+		var criteria []string
+		for _, ac := range us.AcceptanceCriteria {
+			if ac = strings.TrimSpace(ac); ac != "" {
+				criteria = append(criteria, ac)
+			}
+		}
+		plan.Stories = append(plan.Stories, Story{
+			Title:              title,
+			Description:        description,
+			Priority:           us.Priority,
+			AcceptanceCriteria: criteria,
+			Passes:             us.Passes,
+		})
+	}
+	return plan, nil
+}
+
+var (
+	headingRe = regexp.MustCompile(`^(#{1,6})\s+(.+?)\s*#*\s*$`)
+	// Phase 1: Foundation, Phase 2.1 - Hotfix, Phase 3. Launch
+	phaseRe = regexp.MustCompile(`(?i)^phase\s+(\d+(?:\.\d+)?)\s*[:.\-–—]\s*(.+)$`)
+	// US-001: Login, Story 2 - Signup
+	storyRe = regexp.MustCompile(`(?i)^((?:[a-z]+-\d+)|(?:(?:user\s+)?story\s+\d+))\s*[:.\-–—]\s*(.+)$`)
+	// - [ ] **Phase 1: Foundation** - Set up the project
+	phaseItemRe = regexp.MustCompile(`^\s*[-*]\s+\[([ xX])\]\s+(.+)$`)
+	listItemRe  = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+(?:\[[ xX]\]\s+)?(.+)$`)
+	criteriaRe  = regexp.MustCompile(`(?i)^\W*acceptance\s+criteria\W*$`)
+	titlePrefix = regexp.MustCompile(`(?i)^(roadmap|prd|product requirements( document)?)\s*[:\-–—]\s*`)
+	blankLines  = regexp.MustCompile(`\n{3,}`)
+)
+
+// section is a heading and the lines under it, up to the next heading.
+type section struct {
+	level int
+	title string
+	lines []string
+}
+
+// ParseMarkdown reads a markdown roadmap or PRD. The first level-1 heading
+// names the task and the text under it describes it. Headings such as
+// "Phase 2: API" become phases, with what is under them up to the next
+// heading of the same or a higher level; roadmaps that only list their
+// phases as "- [ ] **Phase 2: API** - description" items are read from the
+// list. Headings such as "US-003: Login" or "Story 3: Login" become stories,
+// with the list under an "Acceptance Criteria" line as their criteria.
+func ParseMarkdown(content string) Plan {
+	var plan Plan
+	sections := splitSections(content)
+
+	phaseIndex := map[string]int{} // phase number -> index in plan.Phases
+	for i, s := range sections {
+		switch {
+		case s.level == 1 && plan.Title == "":
+			plan.Title = titlePrefix.ReplaceAllString(stripMarkup(s.title), "")
+			plan.Description = strings.TrimSpace(strings.Join(s.lines, "\n"))
+		case phaseRe.MatchString(stripMarkup(s.title)):
+			m := phaseRe.FindStringSubmatch(stripMarkup(s.title))
+			phase := Phase{Title: strings.TrimSpace(m[2]), Description: sectionBody(sections, i)}
+			if j, ok := phaseIndex[m[1]]; ok {
+				phase.Done = plan.Phases[j].Done
+				plan.Phases[j] = phase
+			} else {
+				phaseIndex[m[1]] = len(plan.Phases)
+				plan.Phases = append(plan.Phases, phase)
+			}
+		case storyRe.MatchString(stripMarkup(s.title)):
+			m := storyRe.FindStringSubmatch(stripMarkup(s.title))
+			description, criteria := splitCriteria(sectionBody(sections, i))
+			plan.Stories = append(plan.Stories, Story{
+				Title:              strings.TrimSpace(m[1]) + ": " + strings.TrimSpace(m[2]),
+				Description:        description,
+				AcceptanceCriteria: criteria,
+			})
+		}
+
+		// Phases listed as checklist items, detailed under headings or not
+		for _, line := range s.lines {
+			item := phaseItemRe.FindStringSubmatch(line)
+			if item == nil {
+				continue
+			}
+			text := stripMarkup(item[2])
+			title, description, _ := strings.Cut(text, " - ")
+			m := phaseRe.FindStringSubmatch(strings.TrimSpace(stripMarkup(title)))
+			if m == nil {
+				continue
+			}
+			done := item[1] != " "
+			if j, ok := phaseIndex[m[1]]; ok {
+				plan.Phases[j].Done = plan.Phases[j].Done || done
+				continue
+			}
+			phaseIndex[m[1]] = len(plan.Phases)
+			plan.Phases = append(plan.Phases, Phase{
+				Title:       strings.TrimSpace(m[2]),
+				Description: strings.TrimSpace(description),
+				Done:        done,
+			})
+		}
+	}
+	if plan.Description == "" {
+		for _, s := range sections {
+			if s.level > 1 && strings.EqualFold(stripMarkup(s.title), "overview") {
+				plan.Description = strings.TrimSpace(strings.Join(s.lines, "\n"))
+				break
+			}
+		}
+	}
+	return plan
+}
+
+// splitSections splits markdown at its headings, outside code fences. Lines
+// before the first heading form a section of level 0.
+func splitSections(content string) []section {
+	sections := []section{{}}
+	fenced := false
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fenced = !fenced
+		}
+		if m := headingRe.FindStringSubmatch(line); m != nil && !fenced {
+			sections = append(sections, section{level: len(m[1]), title: m[2]})
+			continue
+		}
+		last := &sections[len(sections)-1]
+		last.lines = append(last.lines, line)
+	}
+	return sections
+}
+
+// sectionBody returns the text under sections[i], its subsections included.
+func sectionBody(sections []section, i int) string {
+	lines := append([]string{}, sections[i].lines...)
+	for _, s := range sections[i+1:] {
+		if s.level <= sections[i].level {
+			break
+		}
+		lines = append(lines, "", strings.Repeat("#", s.level)+" "+s.title)
+		lines = append(lines, s.lines...)
+	}
+	return tidy(strings.Join(lines, "\n"))
+}
+
+// splitCriteria takes the list under an "Acceptance Criteria" line out of
+// a story's text.
+func splitCriteria(body string) (string, []string) {
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		if !criteriaRe.MatchString(stripMarkup(line)) {
+			continue
+		}
+		var criteria []string
+		end := i + 1
+		for ; end < len(lines); end++ {
+			if m := listItemRe.FindStringSubmatch(lines[end]); m != nil {
+				criteria = append(criteria, stripMarkup(m[1]))
+			} else if strings.TrimSpace(lines[end]) != "" || len(criteria) > 0 {
+				break
+			}
+		}
+		rest := append(append([]string{}, lines[:i]...), lines[end:]...)
+		return tidy(strings.Join(rest, "\n")), criteria
+	}
+	return body, nil
+}
+
+// tidy trims text and collapses runs of blank lines to one.
+func tidy(s string) string {
+	return blankLines.ReplaceAllString(strings.TrimSpace(s), "\n\n")
+}
+
+// stripMarkup removes emphasis and heading markers around text.
+func stripMarkup(s string) string {
+	s = strings.TrimSpace(strings.TrimLeft(s, "#"))
+	return strings.TrimSpace(strings.NewReplacer("**", "", "__", "", "`", "").Replace(s))
+}
@@ -2,32 +2,61 @@ package queue
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"log"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/leader"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/maintenance"
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/quiethours"
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/taskctx"
 	ws "github.com/abelkuruvilla/claw-agent-mission-control/internal/websocket"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/workhours"
 )
 
 // AgentQueueProcessor is the interface that the task handler implements
 // for dequeuing and notifying agents about queued tasks.
 type AgentQueueProcessor interface {
 	ProcessAgentQueue(ctx context.Context, agentID string)
+	// IsAgentBusy consults live availability, falling back to the task count.
+	IsAgentBusy(ctx context.Context, agentID string) bool
+	// RateLimitedUntil reports whether dispatch to the agent is held back
+	// by a rate limit of the agent or its model, and until when.
+	RateLimitedUntil(agentID string) (time.Time, bool)
+	// ModelUnavailable reports whether every model the agent could run on
+	// was found down by the model prober, and which.
+	ModelUnavailable(ctx context.Context, agentID string) ([]string, bool)
+	// PushesAssignments reports whether the agent takes pushed assignments
+	// rather than picking up its work on heartbeat.
+	PushesAssignments(ctx context.Context, agentID string) bool
+	// TaskScope opens a task-scoped context for a background send on the
+	// task to the agent, cancelled if the task is stopped, deleted or taken
+	// from the agent; release closes it.
+	TaskScope(ctx context.Context, taskID, agentID string) (scope context.Context, release func())
 }
 
 // Processor periodically checks all agent queues and dispatches
 // queued tasks to agents that have become free.
 type Processor struct {
 	store       *store.Store
-	agentSender *openclaw.AgentSender
+	agentSender openclaw.Sender
 	hub         *ws.Hub
 	handler     AgentQueueProcessor
 	stopChan    chan struct{}
 	running     bool
+	lastRun     atomic.Int64 // when ProcessOnce last checked, unix nanoseconds
+	maintenance *maintenance.Mode
+	leader      *leader.Elector
 }
 
-func NewProcessor(st *store.Store, agentSender *openclaw.AgentSender, hub *ws.Hub, handler AgentQueueProcessor) *Processor {
+func NewProcessor(st *store.Store, agentSender openclaw.Sender, hub *ws.Hub, handler AgentQueueProcessor) *Processor {
 	return &Processor{
 		store:       st,
 		agentSender: agentSender,
@@ -37,9 +66,22 @@ func NewProcessor(st *store.Store, agentSender *openclaw.AgentSender, hub *ws.Hu
 	}
 }
 
-// ProcessScheduledTasks dispatches due scheduled and retry tasks directly to agents.
-// Unlike ProcessAgentQueue which only handles 'queued' tasks, this handles
-// scheduled tasks that have status 'backlog' with a past scheduled_at time.
+// SetMaintenance sets the maintenance switch; while it is on neither queues
+// nor scheduled tasks are processed.
+func (p *Processor) SetMaintenance(m *maintenance.Mode) {
+	p.maintenance = m
+}
+
+// SetLeader sets the leader elector; queues and scheduled tasks are only
+// processed while this instance is the leader.
+func (p *Processor) SetLeader(l *leader.Elector) {
+	p.leader = l
+}
+
+// ProcessScheduledTasks dispatches due scheduled, retry and deferred tasks
+// directly to agents. Unlike ProcessAgentQueue which only handles 'queued'
+// tasks, this handles scheduled tasks that have status 'backlog' with a past
+// scheduled_at time, and tasks whose deferral for working hours has ended.
 func (p *Processor) ProcessScheduledTasks(ctx context.Context) {
 	dueTasks, err := p.store.ListScheduledDueTasks(ctx)
 	if err != nil {
@@ -71,6 +113,8 @@ func (p *Processor) ProcessScheduledTasks(ctx context.Context) {
 				log.Printf("[QueueProcessor] Error clearing retry_at for %s: %v", task.ID, err)
 				continue
 			}
+			p.recordAttempt(ctx, task.ID, store.AttemptScheduledRetry, task.AgentID.String,
+				fmt.Sprintf("retry_at %s reached", task.RetryAt.Time.UTC().Format(time.RFC3339)), store.AttemptSucceeded, "")
 			if task.AgentID.Valid && task.AgentID.String != "" {
 				desc := ""
 				if task.Description.Valid {
@@ -80,35 +124,214 @@ func (p *Processor) ProcessScheduledTasks(ctx context.Context) {
 			}
 		}
 	}
+
+	deferredTasks, err := p.store.ListDeferredDueTasks(ctx)
+	if err != nil {
+		log.Printf("[QueueProcessor] Error listing deferred tasks: %v", err)
+	} else {
+		for _, task := range deferredTasks {
+			log.Printf("[QueueProcessor] Deferral of task %s (%s) has ended — dispatching", task.ID, task.Title)
+			if err := p.store.ClearTaskDeferredUntil(ctx, task.ID); err != nil {
+				log.Printf("[QueueProcessor] Error clearing deferred_until for %s: %v", task.ID, err)
+				continue
+			}
+			if !task.AgentID.Valid || task.AgentID.String == "" {
+				continue
+			}
+			if task.Status.String == "queued" {
+				// Deferred at the head of the agent's queue; resume the queue
+				p.handler.ProcessAgentQueue(ctx, task.AgentID.String)
+				continue
+			}
+			desc := ""
+			if task.Description.Valid {
+				desc = task.Description.String
+			}
+			p.dispatchTaskToAgent(ctx, task.ID, task.AgentID.String, task.Title, desc)
+		}
+	}
+}
+
+// deferIfOffHours postpones dispatch while the agent is outside its working
+// hours, recording deferred_until and a task_deferred event. Reports whether
+// dispatch was deferred.
+func (p *Processor) deferIfOffHours(ctx context.Context, taskID, agentID string) bool {
+	agent, err := p.store.GetAgent(ctx, agentID)
+	if err != nil || !agent.WorkingHours.Valid {
+		return false
+	}
+	sched, err := workhours.Parse(agent.WorkingHours.String)
+	if err != nil {
+		log.Printf("[QueueProcessor] Ignoring working hours of agent %s: %v", agentID, err)
+		return false
+	}
+	until, deferred := sched.Defer(time.Now())
+	if !deferred {
+		return false
+	}
+	if err := p.store.SetTaskDeferredUntil(ctx, taskID, until); err != nil {
+		log.Printf("[QueueProcessor] Error deferring task %s: %v", taskID, err)
+		return true
+	}
+	log.Printf("[QueueProcessor] Agent %s outside working hours, task %s deferred until %s", agentID, taskID, until.Format(time.RFC3339))
+	event, _ := p.store.CreateEvent(ctx, db.CreateEventParams{
+		TaskID:  sql.NullString{String: taskID, Valid: true},
+		AgentID: sql.NullString{String: agentID, Valid: true},
+		Type:    "task_deferred",
+		Message: fmt.Sprintf("Dispatch to agent %s deferred until %s: outside working hours (%s)", agentID, until.Format(time.RFC3339), sched),
+		Details: sql.NullString{String: fmt.Sprintf(`{"reason":"outside_working_hours","deferred_until":%q,"working_hours":%q}`, until.UTC().Format(time.RFC3339), sched.String()), Valid: true},
+	})
+	if event.ID != "" && p.hub != nil {
+		p.hub.BroadcastEvent(event)
+	}
+	return true
+}
+
+// deferIfQuiet postpones dispatch of all but priority 1 tasks during the
+// quiet hours in settings, recording deferred_until and a task_deferred
+// event. Reports whether dispatch was deferred.
+func (p *Processor) deferIfQuiet(ctx context.Context, taskID, agentID string) bool {
+	settings, err := p.store.GetSettings(ctx)
+	if err != nil || !settings.QuietHours.Valid {
+		return false
+	}
+	policy, err := quiethours.Parse(settings.QuietHours.String)
+	if err != nil {
+		log.Printf("[QueueProcessor] Ignoring quiet hours: %v", err)
+		return false
+	}
+	task, err := p.store.GetTask(ctx, taskID)
+	if err != nil {
+		return false
+	}
+	until, quiet := policy.Defer(time.Now(), int(task.Priority.Int64))
+	if !quiet {
+		return false
+	}
+	if err := p.store.SetTaskDeferredUntil(ctx, taskID, until); err != nil {
+		log.Printf("[QueueProcessor] Error deferring task %s: %v", taskID, err)
+		return true
+	}
+	log.Printf("[QueueProcessor] Quiet hours, task %s deferred until %s", taskID, until.Format(time.RFC3339))
+	event, _ := p.store.CreateEvent(ctx, db.CreateEventParams{
+		TaskID:  sql.NullString{String: taskID, Valid: true},
+		AgentID: sql.NullString{String: agentID, Valid: true},
+		Type:    "task_deferred",
+		Message: fmt.Sprintf("Dispatch to agent %s deferred until %s: quiet hours (%s)", agentID, until.Format(time.RFC3339), policy),
+		Details: sql.NullString{String: fmt.Sprintf(`{"reason":"quiet_hours","deferred_until":%q,"quiet_hours":%q}`, until.UTC().Format(time.RFC3339), policy.String()), Valid: true},
+	})
+	if event.ID != "" && p.hub != nil {
+		p.hub.BroadcastEvent(event)
+	}
+	return true
+}
+
+// deferIfRateLimited postpones dispatch while the agent or its model is
+// cooling down from a rate limit, recording deferred_until and a
+// task_deferred event. Reports whether dispatch was deferred.
+func (p *Processor) deferIfRateLimited(ctx context.Context, taskID, agentID string) bool {
+	until, limited := p.handler.RateLimitedUntil(agentID)
+	if !limited {
+		return false
+	}
+	if err := p.store.SetTaskDeferredUntil(ctx, taskID, until); err != nil {
+		log.Printf("[QueueProcessor] Error deferring task %s: %v", taskID, err)
+		return true
+	}
+	log.Printf("[QueueProcessor] Agent %s rate limited, task %s deferred until %s", agentID, taskID, until.Format(time.RFC3339))
+	event, _ := p.store.CreateEvent(ctx, db.CreateEventParams{
+		TaskID:  sql.NullString{String: taskID, Valid: true},
+		AgentID: sql.NullString{String: agentID, Valid: true},
+		Type:    "task_deferred",
+		Message: fmt.Sprintf("Dispatch to agent %s deferred until %s: rate limited", agentID, until.Format(time.RFC3339)),
+		Details: sql.NullString{String: fmt.Sprintf(`{"reason":"rate_limited","deferred_until":%q}`, until.UTC().Format(time.RFC3339)), Valid: true},
+	})
+	if event.ID != "" && p.hub != nil {
+		p.hub.BroadcastEvent(event)
+	}
+	return true
+}
+
+// broadcastQueue broadcasts the depth of agentID's queue after it changed.
+func (p *Processor) broadcastQueue(ctx context.Context, agentID string) {
+	if p.hub == nil {
+		return
+	}
+	depth, err := p.store.CountQueuedTasksByAgent(ctx, agentID)
+	if err != nil {
+		log.Printf("[QueueProcessor] Error counting queue of agent %s: %v", agentID, err)
+		return
+	}
+	p.hub.BroadcastQueueUpdated(agentID, depth)
 }
 
 // dispatchTaskToAgent sends a specific task to an agent.
-// If the agent is busy, the task is queued instead.
+// If the agent is busy, picks up its work on heartbeat or has no available
+// model, the task is queued instead; outside the agent's working hours, during quiet hours or while it
+// is rate limited it is deferred.
 func (p *Processor) dispatchTaskToAgent(ctx context.Context, taskID, agentID, title, description string) {
-	// Check if agent is busy
-	activeCount, err := p.store.CountActiveTasksByAgent(ctx, agentID)
-	if err != nil {
-		log.Printf("[QueueProcessor] Error checking active tasks for agent %s: %v", agentID, err)
+	if p.deferIfOffHours(ctx, taskID, agentID) || p.deferIfQuiet(ctx, taskID, agentID) || p.deferIfRateLimited(ctx, taskID, agentID) {
+		p.recordAttempt(ctx, taskID, store.AttemptSend, agentID, "", store.AttemptDeferred, "")
 		return
 	}
 
-	if activeCount > 0 {
+	if p.handler.IsAgentBusy(ctx, agentID) {
 		// Agent busy - put task in queue
-		log.Printf("[QueueProcessor] Agent %s busy with %d active tasks, queueing task %s", agentID, activeCount, taskID)
+		log.Printf("[QueueProcessor] Agent %s busy, queueing task %s", agentID, taskID)
+		p.recordAttempt(ctx, taskID, store.AttemptSend, agentID, "agent busy", store.AttemptQueued, "")
+		if err := p.store.UpdateTaskStatus(ctx, taskID, "queued"); err != nil {
+			log.Printf("[QueueProcessor] Error queueing task %s: %v", taskID, err)
+		}
+		p.broadcastQueue(ctx, agentID)
+		return
+	}
+	if !p.handler.PushesAssignments(ctx, agentID) {
+		log.Printf("[QueueProcessor] Agent %s takes no pushed assignments, queueing task %s", agentID, taskID)
+		p.recordAttempt(ctx, taskID, store.AttemptSend, agentID, "queue only", store.AttemptQueued, "")
 		if err := p.store.UpdateTaskStatus(ctx, taskID, "queued"); err != nil {
 			log.Printf("[QueueProcessor] Error queueing task %s: %v", taskID, err)
 		}
+		p.broadcastQueue(ctx, agentID)
+		return
+	}
+	if models, down := p.handler.ModelUnavailable(ctx, agentID); down {
+		log.Printf("[QueueProcessor] Agent %s has no available model (%s), queueing task %s", agentID, strings.Join(models, ", "), taskID)
+		p.recordAttempt(ctx, taskID, store.AttemptSend, agentID, "model unavailable", store.AttemptQueued, "")
+		if err := p.store.UpdateTaskStatus(ctx, taskID, "queued"); err != nil {
+			log.Printf("[QueueProcessor] Error queueing task %s: %v", taskID, err)
+		}
+		p.broadcastQueue(ctx, agentID)
 		return
 	}
 
 	// Agent free - notify directly
 	log.Printf("[QueueProcessor] Notifying agent %s about task %s (%s)", agentID, taskID, title)
 
-	p.agentSender.NotifyAgentAsync(agentID, taskID, title, description, func(tID, aID, reply string, err error) {
-		if err != nil {
+	attemptID := p.recordAttempt(ctx, taskID, store.AttemptSend, agentID, "", store.AttemptPending, "")
+	sendCtx, release := p.handler.TaskScope(ctx, taskID, agentID)
+	p.agentSender.For(sendCtx).NotifyAgentAsync(agentID, taskID, title, description, func(tID, aID, reply string, err error) {
+		defer release()
+		ctx, cancel := taskctx.Detach(sendCtx)
+		defer cancel()
+
+		if attemptID != "" {
+			outcome, errMsg := store.AttemptSucceeded, ""
+			if err != nil {
+				outcome, errMsg = store.AttemptFailed, err.Error()
+			}
+			if ferr := p.store.FinishTaskAttempt(ctx, attemptID, outcome, errMsg); ferr != nil {
+				log.Printf("[QueueProcessor] Error finishing attempt %s: %v", attemptID, ferr)
+			}
+		}
+		if taskctx.Cancelled(err) || errors.Is(err, openclaw.ErrDuplicate) {
+			// The task was stopped, deleted or reassigned mid-send, or the
+			// same assignment is already on its way; it is not queued again
+			log.Printf("[QueueProcessor] Notification of agent %s for task %s not sent: %v", agentID, taskID, err)
+		} else if err != nil {
 			log.Printf("[QueueProcessor] Failed to notify agent %s for task %s: %v", agentID, taskID, err)
 			// Put back in queue on failure
 			p.store.UpdateTaskStatus(ctx, taskID, "queued")
+			p.broadcastQueue(ctx, agentID)
 		} else {
 			log.Printf("[QueueProcessor] Agent %s notified for task %s", agentID, taskID)
 			// Update status to 'backlog' since it's now being worked on
@@ -122,7 +345,26 @@ func (p *Processor) dispatchTaskToAgent(ctx context.Context, taskID, agentID, ti
 	})
 }
 
+// recordAttempt adds an entry to the task's retry history and returns its ID,
+// or "" if it could not be recorded.
+func (p *Processor) recordAttempt(ctx context.Context, taskID, kind, agentID, reason, outcome, errMsg string) string {
+	attempt, err := p.store.RecordTaskAttempt(ctx, taskID, kind, agentID, reason, outcome, errMsg)
+	if err != nil {
+		log.Printf("[QueueProcessor] Error recording %s attempt for task %s: %v", kind, taskID, err)
+		return ""
+	}
+	return attempt.ID
+}
+
 func (p *Processor) ProcessOnce(ctx context.Context) {
+	if p.maintenance.Enabled() {
+		log.Println("[QueueProcessor] Maintenance mode, skipping queue and schedule check")
+		return
+	}
+	if !p.leader.IsLeader() {
+		return
+	}
+	p.lastRun.Store(time.Now().UnixNano())
 	p.ProcessScheduledTasks(ctx)
 
 	log.Println("[QueueProcessor] Starting periodic queue check...")
@@ -135,27 +377,41 @@ func (p *Processor) ProcessOnce(ctx context.Context) {
 
 	processed := 0
 	for _, agent := range agents {
-		activeCount, err := p.store.CountActiveTasksByAgent(ctx, agent.ID)
-		if err != nil {
-			log.Printf("[QueueProcessor] Error checking active tasks for agent %s: %v", agent.ID, err)
+		if p.handler.IsAgentBusy(ctx, agent.ID) {
 			continue
 		}
-
-		if activeCount > 0 {
+		if until, limited := p.handler.RateLimitedUntil(agent.ID); limited {
+			log.Printf("[QueueProcessor] Agent %s rate limited until %s, skipping", agent.ID, until.Format(time.RFC3339))
+			continue
+		}
+		if _, down := p.handler.ModelUnavailable(ctx, agent.ID); down {
+			log.Printf("[QueueProcessor] Agent %s has no available model, skipping", agent.ID)
 			continue
 		}
 
-		queued, err := p.store.ListQueuedTasksByAgent(ctx, agent.ID)
+		queued, err := p.store.CountQueuedTasksByAgent(ctx, agent.ID)
 		if err != nil {
 			log.Printf("[QueueProcessor] Error checking queue for agent %s: %v", agent.ID, err)
 			continue
 		}
 
-		if len(queued) == 0 {
+		if queued == 0 {
+			// Free agents also pull from their groups' shared queues
+			groupQueued, err := p.store.CountQueuedGroupTasksForAgent(ctx, agent.ID)
+			if err != nil {
+				log.Printf("[QueueProcessor] Error checking group queues for agent %s: %v", agent.ID, err)
+				continue
+			}
+			if groupQueued == 0 {
+				continue
+			}
+			log.Printf("[QueueProcessor] Agent %s is free with %d tasks in its group queues — dispatching next", agent.ID, groupQueued)
+			p.handler.ProcessAgentQueue(ctx, agent.ID)
+			processed++
 			continue
 		}
 
-		log.Printf("[QueueProcessor] Agent %s is free with %d queued tasks — dispatching next", agent.ID, len(queued))
+		log.Printf("[QueueProcessor] Agent %s is free with %d queued tasks — dispatching next", agent.ID, queued)
 		p.handler.ProcessAgentQueue(ctx, agent.ID)
 		processed++
 	}
@@ -203,3 +459,21 @@ func (p *Processor) Stop() {
 	close(p.stopChan)
 	p.running = false
 }
+
+// Running reports whether the periodic queue check is running.
+func (p *Processor) Running() bool {
+	return p.running
+}
+
+// LastRun returns when the queues were last checked, zero if never.
+func (p *Processor) LastRun() time.Time {
+	return unixTime(p.lastRun.Load())
+}
+
+// unixTime is the time of unix nanoseconds n, zero for 0.
+func unixTime(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
@@ -0,0 +1,267 @@
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+)
+
+// What reconciliation did with an active task.
+const (
+	ReconcileResumed  = "resumed"  // agent re-notified with the task's history
+	ReconcileRequeued = "requeued" // back in its agent's queue, or in the backlog if it has none
+	ReconcileFlagged  = "flagged"  // left for a human to look at
+)
+
+// ReconcileAction is what reconciliation did with one task, and why.
+type ReconcileAction struct {
+	TaskID  string `json:"task_id"`
+	AgentID string `json:"agent_id,omitempty"`
+	Action  string `json:"action"`
+	Reason  string `json:"reason"`
+}
+
+// ReconcileReport sums up a reconciliation pass.
+type ReconcileReport struct {
+	Checked            int               `json:"checked"`
+	Live               int               `json:"live"`
+	Resumed            int               `json:"resumed"`
+	Requeued           int               `json:"requeued"`
+	Flagged            int               `json:"flagged"`
+	GatewayUnreachable bool              `json:"gateway_unreachable,omitempty"`
+	Actions            []ReconcileAction `json:"actions,omitempty"`
+}
+
+// SetGateway sets the gateway Reconcile asks for live sessions.
+func (w *Watchdog) SetGateway(g openclaw.Gateway) {
+	w.gateway = g
+}
+
+// Reconcile is run when an instance becomes leader, at startup or when the
+// previous leader went away, to settle the tasks in active states (planning, discussing, executing, verifying) whose
+// agent no longer has a live session on the gateway. A task is live while
+// it or its agent's session saw activity within the stale threshold, or
+// while its assignment is still being delivered. Of the rest, per agent:
+//   - the most recently updated task is resumed: the agent is re-notified
+//     with the task's history, counting as a retry;
+//   - its other tasks are re-queued behind it;
+//   - tasks whose agent is gone are re-queued to the backlog for reassignment;
+//   - tasks that used up their retries, or whose agent is reached by HTTP
+//     callback and has no session to check, are flagged.
+//
+// Each action is logged on the task, and a reconciliation_report event sums
+// up the pass. If the gateway cannot be reached nothing is changed; the
+// watchdog deals with stale tasks as usual.
+func (w *Watchdog) Reconcile(ctx context.Context) ReconcileReport {
+	var report ReconcileReport
+	if w.maintenance.Enabled() {
+		log.Println("[Reconcile] Maintenance mode, skipping reconciliation")
+		return report
+	}
+	tasks, err := w.store.ListStaleTasks(ctx, time.Now())
+	if err != nil {
+		log.Printf("[Reconcile] Error listing active tasks: %v", err)
+		return report
+	}
+	if len(tasks) == 0 {
+		return report
+	}
+	report.Checked = len(tasks)
+	if ok, err := w.gatewayUp(ctx); !ok {
+		log.Printf("[Reconcile] Gateway unreachable, leaving %d active task(s) to the watchdog: %v", len(tasks), err)
+		report.GatewayUnreachable = true
+		w.logReport(ctx, report)
+		return report
+	}
+
+	cutoff := time.Now().Add(-w.staleThreshold)
+	sessionLive := make(map[string]bool)
+	resumed := make(map[string]bool)
+	// ListStaleTasks returns the least recently updated first; resume the
+	// most recent task of each agent
+	for i := len(tasks) - 1; i >= 0; i-- {
+		task := tasks[i]
+		agentID := task.AgentID.String
+		if (task.UpdatedAt.Valid && task.UpdatedAt.Time.After(cutoff)) || w.delivering(ctx, task.ID) {
+			report.Live++
+			continue
+		}
+		if agentID == "" {
+			w.requeue(ctx, &report, task, "", "no assigned agent")
+			continue
+		}
+		agent, err := w.store.GetAgent(ctx, agentID)
+		if err != nil {
+			w.requeue(ctx, &report, task, "", fmt.Sprintf("agent %s not found", agentID))
+			continue
+		}
+		if agent.DeliveryMethod.String == openclaw.DeliveryHTTPCallback {
+			w.flag(ctx, &report, task, agentID, "agent is reached by HTTP callback; no session to check")
+			continue
+		}
+		live, checked := sessionLive[agentID]
+		if !checked {
+			live = w.sessionActive(ctx, agentID, cutoff)
+			sessionLive[agentID] = live
+		}
+		switch {
+		case live:
+			report.Live++
+		case resumed[agentID]:
+			w.requeue(ctx, &report, task, agentID, "agent is resuming another task")
+		case task.RetryCount >= int64(w.maxRetries):
+			w.flag(ctx, &report, task, agentID, fmt.Sprintf("no live session and %d retries used", task.RetryCount))
+		default:
+			w.resume(ctx, &report, task, agentID)
+			resumed[agentID] = true
+		}
+	}
+	log.Printf("[Reconcile] Checked %d active task(s): %d live, %d resumed, %d re-queued, %d flagged",
+		report.Checked, report.Live, report.Resumed, report.Requeued, report.Flagged)
+	w.logReport(ctx, report)
+	return report
+}
+
+// gatewayUp reports whether the gateway answers.
+func (w *Watchdog) gatewayUp(ctx context.Context) (bool, error) {
+	if w.gateway == nil {
+		return false, fmt.Errorf("no gateway configured")
+	}
+	return w.gateway.GetStatus(ctx)
+}
+
+// sessionActive reports whether the agent's main session saw a message since
+// cutoff. Sessions that cannot be read count as not live.
+func (w *Watchdog) sessionActive(ctx context.Context, agentID string, cutoff time.Time) bool {
+	key := openclaw.MainSessionKey(agentID)
+	history, err := w.gateway.GetSessionHistory(ctx, key, 1)
+	if err != nil {
+		log.Printf("[Reconcile] Could not read session %s: %v", key, err)
+		return false
+	}
+	for _, m := range history.Messages {
+		if m.Time().After(cutoff) {
+			return true
+		}
+	}
+	return false
+}
+
+// delivering reports whether the task's assignment is still on its way to
+// the agent (e.g. just resent by the task handler's Resume).
+func (w *Watchdog) delivering(ctx context.Context, taskID string) bool {
+	deliveries, err := w.store.ListNotificationDeliveriesByTask(ctx, taskID)
+	if err != nil {
+		return false
+	}
+	for _, d := range deliveries {
+		if d.Kind == "task_assignment" && d.Status == "pending" {
+			return true
+		}
+	}
+	return false
+}
+
+// resume re-notifies the agent of a task, with its history.
+func (w *Watchdog) resume(ctx context.Context, report *ReconcileReport, task db.Task, agentID string) {
+	if err := w.store.IncrementTaskRetryCount(ctx, task.ID); err != nil {
+		log.Printf("[Reconcile] Error incrementing retry count for task %s: %v", task.ID, err)
+		return
+	}
+	reason := "no live session after restart"
+	w.recordAttempt(ctx, task.ID, store.AttemptReconcile, agentID, reason, store.AttemptSucceeded)
+	w.act(ctx, report, task, agentID, ReconcileResumed, reason,
+		fmt.Sprintf("[Reconcile] Agent %s had no live session after the restart. Re-notifying it (retry %d/%d).", agentID, task.RetryCount+1, w.maxRetries))
+	w.notifier.NotifyAssignedAgent(agentID, task.ID, task.Title, task.Description.String, true)
+}
+
+// requeue puts a task back in its agent's queue, or with no agent, in the
+// backlog for reassignment.
+func (w *Watchdog) requeue(ctx context.Context, report *ReconcileReport, task db.Task, agentID, reason string) {
+	status := "queued"
+	var err error
+	if agentID == "" {
+		status = "backlog"
+		err = w.store.ResetStuckTask(ctx, task.ID)
+	} else {
+		err = w.store.UpdateTaskStatus(ctx, task.ID, status)
+	}
+	if err != nil {
+		log.Printf("[Reconcile] Error re-queuing task %s: %v", task.ID, err)
+		return
+	}
+	w.recordAttempt(ctx, task.ID, store.AttemptReconcile, agentID, reason, store.AttemptQueued)
+	w.act(ctx, report, task, agentID, ReconcileRequeued, reason,
+		fmt.Sprintf("[Reconcile] Task moved to %s after the restart (%s).", status, reason))
+	if w.hub != nil {
+		w.hub.BroadcastTaskStatus(task.ID, status, 0)
+	}
+}
+
+// flag leaves a task as it is, for a human to look at.
+func (w *Watchdog) flag(ctx context.Context, report *ReconcileReport, task db.Task, agentID, reason string) {
+	w.act(ctx, report, task, agentID, ReconcileFlagged, reason,
+		fmt.Sprintf("[Reconcile] Task needs attention after the restart: %s. Re-assign it or use Retry from the UI.", reason))
+}
+
+// act records what was done with a task in the report, as a
+// task_reconciled event and as a comment on the task.
+func (w *Watchdog) act(ctx context.Context, report *ReconcileReport, task db.Task, agentID, action, reason, comment string) {
+	switch action {
+	case ReconcileResumed:
+		report.Resumed++
+	case ReconcileRequeued:
+		report.Requeued++
+	case ReconcileFlagged:
+		report.Flagged++
+	}
+	report.Actions = append(report.Actions, ReconcileAction{TaskID: task.ID, AgentID: agentID, Action: action, Reason: reason})
+	log.Printf("[Reconcile] Task %s (%s) %s: %s", task.ID, task.Title, action, reason)
+
+	event, _ := w.store.CreateEvent(ctx, db.CreateEventParams{
+		TaskID:  sql.NullString{String: task.ID, Valid: true},
+		AgentID: sql.NullString{String: agentID, Valid: agentID != ""},
+		Type:    "task_reconciled",
+		Message: fmt.Sprintf("Task \"%s\" %s after restart: %s", task.Title, action, reason),
+		Details: sql.NullString{String: fmt.Sprintf(`{"action":%q,"reason":%q}`, action, reason), Valid: true},
+	})
+	if event.ID != "" && w.hub != nil {
+		w.hub.BroadcastEvent(event)
+	}
+	if comment, err := w.store.CreateComment(ctx, db.CreateCommentParams{
+		TaskID:  task.ID,
+		Author:  "system",
+		Content: comment,
+	}); err == nil && w.hub != nil {
+		w.hub.BroadcastComment(comment.TaskID, comment.ID, comment.Author, comment.Content, false)
+	}
+}
+
+// logReport records the reconciliation_report event.
+func (w *Watchdog) logReport(ctx context.Context, report ReconcileReport) {
+	message := fmt.Sprintf("Startup reconciliation: %d active task(s) checked, %d live, %d resumed, %d re-queued, %d flagged",
+		report.Checked, report.Live, report.Resumed, report.Requeued, report.Flagged)
+	if report.GatewayUnreachable {
+		message = fmt.Sprintf("Startup reconciliation skipped: gateway unreachable (%d active task(s) left to the watchdog)", report.Checked)
+	}
+	details, _ := json.Marshal(report)
+	event, err := w.store.CreateEvent(ctx, db.CreateEventParams{
+		Type:    "reconciliation_report",
+		Message: message,
+		Details: sql.NullString{String: string(details), Valid: true},
+	})
+	if err != nil {
+		log.Printf("[Reconcile] Error recording report: %v", err)
+		return
+	}
+	if w.hub != nil {
+		w.hub.BroadcastEvent(event)
+	}
+}
@@ -5,9 +5,14 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"sync/atomic"
 	"time"
 
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/failures"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/leader"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/maintenance"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
 	ws "github.com/abelkuruvilla/claw-agent-mission-control/internal/websocket"
 )
@@ -15,12 +20,16 @@ import (
 // StuckTaskNotifier is implemented by the task handler so the watchdog can
 // re-notify agents and parent orchestrators without duplicating logic.
 type StuckTaskNotifier interface {
-	NotifyAssignedAgent(agentID, taskID, title, description string)
+	NotifyAssignedAgent(agentID, taskID, title, description string, withHistory bool)
 	NotifyParentTaskAgent(ctx context.Context, subtask db.Task, newStatus string)
+	ResendNotification(ctx context.Context, delivery db.NotificationDelivery) bool
 }
 
 // Watchdog periodically finds tasks stuck in active states (executing, planning,
 // discussing, verifying) and either re-notifies the agent or resets the task.
+// It also resends orchestrator notifications and task assignments whose
+// delivery was never confirmed, which can happen while the task itself is
+// healthy.
 type Watchdog struct {
 	store            *store.Store
 	hub              *ws.Hub
@@ -29,6 +38,10 @@ type Watchdog struct {
 	maxRetries       int
 	stopChan         chan struct{}
 	running          bool
+	lastRun          atomic.Int64 // when CheckOnce last checked, unix nanoseconds
+	maintenance      *maintenance.Mode
+	leader           *leader.Elector
+	gateway          openclaw.Gateway
 }
 
 // NewWatchdog creates a Watchdog. staleThreshold is how long without updated_at
@@ -45,9 +58,29 @@ func NewWatchdog(st *store.Store, hub *ws.Hub, notifier StuckTaskNotifier, stale
 	}
 }
 
+// SetMaintenance sets the maintenance switch; checks are skipped while it is on.
+func (w *Watchdog) SetMaintenance(m *maintenance.Mode) {
+	w.maintenance = m
+}
+
+// SetLeader sets the leader elector; checks only run on the leader.
+func (w *Watchdog) SetLeader(l *leader.Elector) {
+	w.leader = l
+}
+
 // CheckOnce finds stale tasks and either re-notifies the agent or resets the task.
 func (w *Watchdog) CheckOnce(ctx context.Context) {
+	if w.maintenance.Enabled() {
+		log.Println("[Watchdog] Maintenance mode, skipping check")
+		return
+	}
+	if !w.leader.IsLeader() {
+		return
+	}
+	w.lastRun.Store(time.Now().UnixNano())
 	cutoff := time.Now().Add(-w.staleThreshold)
+	w.checkNotifications(ctx, cutoff)
+
 	stale, err := w.store.ListStaleTasks(ctx, cutoff)
 	if err != nil {
 		log.Printf("[Watchdog] Error listing stale tasks: %v", err)
@@ -79,6 +112,8 @@ func (w *Watchdog) CheckOnce(ctx context.Context) {
 				log.Printf("[Watchdog] Error incrementing retry count for task %s: %v", taskID, err)
 				continue
 			}
+			w.recordAttempt(ctx, taskID, store.AttemptWatchdogRetry, agentID,
+				fmt.Sprintf("no update for %v", w.staleThreshold), store.AttemptSucceeded)
 			event, _ := w.store.CreateEvent(ctx, db.CreateEventParams{
 				TaskID:  sql.NullString{String: taskID, Valid: true},
 				AgentID: sql.NullString{String: agentID, Valid: true},
@@ -89,13 +124,15 @@ func (w *Watchdog) CheckOnce(ctx context.Context) {
 			if event.ID != "" && w.hub != nil {
 				w.hub.BroadcastEvent(event)
 			}
-			_, _ = w.store.CreateComment(ctx, db.CreateCommentParams{
+			if comment, err := w.store.CreateComment(ctx, db.CreateCommentParams{
 				TaskID:  taskID,
 				Author:  "system",
 				Content: fmt.Sprintf("[Watchdog] Task considered stuck (no update for %v). Re-notifying agent %s (retry %d/%d).", w.staleThreshold, agentID, task.RetryCount+1, w.maxRetries),
-			})
+			}); err == nil && w.hub != nil {
+				w.hub.BroadcastComment(comment.TaskID, comment.ID, comment.Author, comment.Content, false)
+			}
 			log.Printf("[Watchdog] Re-notifying agent %s for stuck task %s (%s)", agentID, taskID, title)
-			w.notifier.NotifyAssignedAgent(agentID, taskID, title, description)
+			w.notifier.NotifyAssignedAgent(agentID, taskID, title, description, true)
 			retried++
 		} else {
 			// Max retries exceeded or no agent — reset to backlog
@@ -107,21 +144,25 @@ func (w *Watchdog) CheckOnce(ctx context.Context) {
 			if agentID == "" {
 				reason = "no assigned agent"
 			}
+			w.recordAttempt(ctx, taskID, store.AttemptWatchdogReset, agentID, reason, store.AttemptReset)
+			failureReason := w.classifyReset(ctx, taskID, agentID != "")
 			event, _ := w.store.CreateEvent(ctx, db.CreateEventParams{
 				TaskID:  sql.NullString{String: taskID, Valid: true},
 				AgentID: sql.NullString{String: agentID, Valid: agentID != ""},
 				Type:    "task_stuck_reset",
 				Message: fmt.Sprintf("Task \"%s\" reset to backlog (%s)", title, reason),
-				Details: sql.NullString{Valid: false},
+				Details: sql.NullString{String: fmt.Sprintf(`{"failure_reason":"%s"}`, failureReason), Valid: true},
 			})
 			if event.ID != "" && w.hub != nil {
 				w.hub.BroadcastEvent(event)
 			}
-			_, _ = w.store.CreateComment(ctx, db.CreateCommentParams{
+			if comment, err := w.store.CreateComment(ctx, db.CreateCommentParams{
 				TaskID:  taskID,
 				Author:  "system",
 				Content: fmt.Sprintf("[Watchdog] Task reset to backlog (%s). You can re-assign or use Retry from the UI.", reason),
-			})
+			}); err == nil && w.hub != nil {
+				w.hub.BroadcastComment(comment.TaskID, comment.ID, comment.Author, comment.Content, false)
+			}
 			if w.hub != nil {
 				w.hub.BroadcastTaskStatus(taskID, "backlog", 0)
 			}
@@ -138,6 +179,69 @@ func (w *Watchdog) CheckOnce(ctx context.Context) {
 	log.Printf("[Watchdog] Check complete: %d re-notified, %d reset", retried, reset)
 }
 
+// classifyReset records why taskID, being reset, failed: what its errors
+// point to, else, if it had an agent, that the agent went silent.
+func (w *Watchdog) classifyReset(ctx context.Context, taskID string, hadAgent bool) string {
+	reason := failures.Classify(failures.Evidence(ctx, w.store, taskID)...)
+	if reason == failures.Unknown && hadAgent {
+		reason = failures.Timeout
+	}
+	if err := w.store.SetTaskFailureReason(ctx, taskID, reason); err != nil {
+		log.Printf("[Watchdog] Error recording failure reason for task %s: %v", taskID, err)
+	}
+	return reason
+}
+
+func (w *Watchdog) recordAttempt(ctx context.Context, taskID, kind, agentID, reason, outcome string) {
+	if _, err := w.store.RecordTaskAttempt(ctx, taskID, kind, agentID, reason, outcome, ""); err != nil {
+		log.Printf("[Watchdog] Error recording %s attempt for task %s: %v", kind, taskID, err)
+	}
+}
+
+// checkNotifications resends notifications that have been
+// pending or failed since cutoff, and abandons those that used up maxRetries.
+func (w *Watchdog) checkNotifications(ctx context.Context, cutoff time.Time) {
+	undelivered, err := w.store.ListUndeliveredNotifications(ctx, cutoff)
+	if err != nil {
+		log.Printf("[Watchdog] Error listing undelivered notifications: %v", err)
+		return
+	}
+	if len(undelivered) == 0 {
+		return
+	}
+
+	resent := 0
+	abandoned := 0
+	for _, d := range undelivered {
+		if d.Attempts <= int64(w.maxRetries) {
+			if w.notifier.ResendNotification(ctx, d) {
+				resent++
+			}
+			continue
+		}
+		if err := w.store.AbandonNotificationDelivery(ctx, d.ID); err != nil {
+			log.Printf("[Watchdog] Error abandoning notification %s: %v", d.ID, err)
+			continue
+		}
+		lastError := "never confirmed"
+		if d.LastError.Valid && d.LastError.String != "" {
+			lastError = d.LastError.String
+		}
+		event, _ := w.store.CreateEvent(ctx, db.CreateEventParams{
+			TaskID:  sql.NullString{String: d.TaskID, Valid: true},
+			AgentID: sql.NullString{String: d.AgentID, Valid: d.AgentID != ""},
+			Type:    "notification_abandoned",
+			Message: fmt.Sprintf("Gave up notifying %s that the task is %s after %d attempt(s): %s", d.AgentID, d.Transition, d.Attempts, lastError),
+			Details: sql.NullString{String: fmt.Sprintf(`{"delivery_id":"%s","attempts":%d}`, d.ID, d.Attempts), Valid: true},
+		})
+		if event.ID != "" && w.hub != nil {
+			w.hub.BroadcastEvent(event)
+		}
+		abandoned++
+	}
+	log.Printf("[Watchdog] Notification check: %d resent, %d abandoned", resent, abandoned)
+}
+
 // Start runs the watchdog periodically. Interval is how often to run CheckOnce.
 func (w *Watchdog) Start(ctx context.Context, interval time.Duration) {
 	if w.running {
@@ -176,3 +280,13 @@ func (w *Watchdog) Stop() {
 	close(w.stopChan)
 	w.running = false
 }
+
+// Running reports whether the periodic check is running.
+func (w *Watchdog) Running() bool {
+	return w.running
+}
+
+// LastRun returns when stuck tasks were last checked for, zero if never.
+func (w *Watchdog) LastRun() time.Time {
+	return unixTime(w.lastRun.Load())
+}
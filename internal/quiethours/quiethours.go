@@ -0,0 +1,112 @@
+// Package quiethours holds the system-wide quiet hours in settings: weekly
+// windows, such as every night 22:00-07:00, and a do-not-disturb switch.
+// While it is quiet, dispatch of all but priority 1 tasks is deferred until
+// the quiet period ends.
+package quiethours
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/workhours"
+)
+
+// ExemptPriority is the task priority dispatched even when it is quiet.
+const ExemptPriority = 1
+
+// maxQuiet bounds how far ahead Quiet looks for the end of a quiet period,
+// so windows covering the whole week defer dispatch a week at a time.
+const maxQuiet = 7 * 24 * time.Hour
+
+// Policy is the quiet hours. Windows use the same form as an agent's working
+// hours (see workhours.Window), but mark when it is quiet rather than open.
+// DoNotDisturbUntil makes it quiet from now until then, windows or not.
+type Policy struct {
+	Timezone          string             `json:"timezone,omitempty"` // IANA name; empty = UTC
+	Windows           []workhours.Window `json:"windows"`
+	DoNotDisturbUntil *time.Time         `json:"do_not_disturb_until,omitempty"`
+
+	sched *workhours.Schedule
+}
+
+// Parse reads a policy stored as JSON; "" is the empty policy.
+func Parse(s string) (Policy, error) {
+	p := Policy{Windows: []workhours.Window{}}
+	if strings.TrimSpace(s) == "" {
+		return p, nil
+	}
+	if err := json.Unmarshal([]byte(s), &p); err != nil {
+		return p, fmt.Errorf("invalid quiet hours: %w", err)
+	}
+	return p, p.Validate()
+}
+
+// Validate checks the time zone and windows, normalizing window days.
+func (p *Policy) Validate() error {
+	if p.Windows == nil {
+		p.Windows = []workhours.Window{}
+	}
+	raw, err := json.Marshal(workhours.Schedule{Timezone: p.Timezone, Windows: p.Windows})
+	if err != nil {
+		return err
+	}
+	sched, err := workhours.Parse(string(raw))
+	if err != nil {
+		return fmt.Errorf("invalid quiet hours: %w", err)
+	}
+	p.sched = sched
+	if sched != nil {
+		p.Windows = sched.Windows
+	}
+	return nil
+}
+
+// Empty reports whether the policy is never quiet from now on.
+func (p Policy) Empty() bool {
+	return len(p.Windows) == 0 && (p.DoNotDisturbUntil == nil || !p.DoNotDisturbUntil.After(time.Now()))
+}
+
+// Quiet reports whether it is quiet at t and, if so, until when: the end of
+// do-not-disturb or of the quiet window t falls in, whichever comes later
+// when one runs into the other.
+func (p Policy) Quiet(t time.Time) (time.Time, bool) {
+	if p.sched == nil && len(p.Windows) > 0 {
+		if err := p.Validate(); err != nil {
+			return time.Time{}, false
+		}
+	}
+	until, quiet := t, false
+	for extended := true; extended && until.Sub(t) < maxQuiet; {
+		extended = false
+		if p.DoNotDisturbUntil != nil && p.DoNotDisturbUntil.After(until) {
+			until, quiet, extended = *p.DoNotDisturbUntil, true, true
+		}
+		if end, in := p.sched.Closes(until); in && end.After(until) {
+			until, quiet, extended = end, true, true
+		}
+	}
+	return until, quiet
+}
+
+// Defer reports whether dispatch of a task of priority at t must wait for
+// the quiet period to end and, if so, until when.
+func (p Policy) Defer(t time.Time, priority int) (time.Time, bool) {
+	if priority == ExemptPriority {
+		return time.Time{}, false
+	}
+	return p.Quiet(t)
+}
+
+// String summarizes the policy for event messages, e.g.
+// "daily 22:00-07:00 (Europe/Berlin)" or "do not disturb".
+func (p Policy) String() string {
+	if p.DoNotDisturbUntil != nil && p.DoNotDisturbUntil.After(time.Now()) {
+		return "do not disturb"
+	}
+	if p.sched == nil {
+		return "never"
+	}
+	return p.sched.String()
+}
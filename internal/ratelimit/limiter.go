@@ -0,0 +1,198 @@
+// Package ratelimit holds dispatch back while a model provider is throttling
+// an agent. A rate-limited send cools down both the agent and every agent on
+// the same model, so tasks wait out one shared window instead of each
+// retrying against the limit on its own schedule.
+package ratelimit
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/failures"
+)
+
+// DefaultCooldown is how long dispatch backs off after a first rate limit
+// when no cool-down is configured.
+const DefaultCooldown = 5 * time.Minute
+
+// maxCooldown caps the cool-down of limits that keep recurring.
+const maxCooldown = time.Hour
+
+// DefaultModel keys agents whose model is not known.
+const DefaultModel = "default"
+
+// Cooldown is dispatch held back for an agent or model.
+type Cooldown struct {
+	Key   string    `json:"key"` // agent:<id> or model:<name>
+	Until time.Time `json:"until"`
+	Hits  int       `json:"hits"` // consecutive rate limits; each doubles the window
+}
+
+type entry struct {
+	until time.Time
+	hits  int
+}
+
+// Limiter tracks the cool-downs of rate-limited agents and models. A nil
+// Limiter never holds anything back.
+type Limiter struct {
+	mu       sync.Mutex
+	cooldown time.Duration
+	now      func() time.Time
+	modelOf  func(agentID string) string
+	entries  map[string]*entry
+}
+
+// NewLimiter creates a Limiter backing off for cooldown (DefaultCooldown if
+// cooldown <= 0) after a first rate limit. modelOf returns the model an agent
+// runs on; with a nil modelOf only the agent itself is held back.
+func NewLimiter(cooldown time.Duration, modelOf func(agentID string) string) *Limiter {
+	if cooldown <= 0 {
+		cooldown = DefaultCooldown
+	}
+	return &Limiter{cooldown: cooldown, now: time.Now, modelOf: modelOf, entries: make(map[string]*entry)}
+}
+
+// IsRateLimit reports whether err says the model provider throttled the agent.
+func IsRateLimit(err error) bool {
+	return err != nil && failures.Classify(err.Error()) == failures.RateLimit
+}
+
+// keys returns the keys agentID is held back by.
+func (l *Limiter) keys(agentID string) []string {
+	keys := []string{"agent:" + agentID}
+	if l.modelOf != nil {
+		model := l.modelOf(agentID)
+		if model == "" {
+			model = DefaultModel
+		}
+		keys = append(keys, "model:"+model)
+	}
+	return keys
+}
+
+// Observe records the outcome of a send to agentID: a rate-limit error starts
+// (or extends) a cool-down of the agent and its model, and a success ends the
+// agent's. Other errors are ignored. Reports when dispatch to the agent may
+// resume if err was a rate limit.
+func (l *Limiter) Observe(agentID string, err error) (time.Time, bool) {
+	if l == nil {
+		return time.Time{}, false
+	}
+	if err == nil {
+		l.Succeeded(agentID)
+		return time.Time{}, false
+	}
+	if !IsRateLimit(err) {
+		return time.Time{}, false
+	}
+	return l.RateLimited(agentID), true
+}
+
+// RateLimited records that a send to agentID was rate limited and returns
+// when dispatch to it may resume. Each consecutive limit doubles the window,
+// up to an hour; limits hit by sends already under way during a cool-down
+// don't extend it.
+func (l *Limiter) RateLimited(agentID string) time.Time {
+	if l == nil {
+		return time.Time{}
+	}
+	keys := l.keys(agentID)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	var until time.Time
+	for _, k := range keys {
+		e := l.entries[k]
+		switch {
+		case e == nil:
+			e = &entry{}
+			l.entries[k] = e
+		case now.Before(e.until):
+			until = later(until, e.until)
+			continue
+		case now.Sub(e.until) > l.cooldown:
+			// The last limit is long over; start again from the base window
+			e.hits = 0
+		}
+		e.hits++
+		window := l.cooldown
+		for i := 1; i < e.hits && window < maxCooldown; i++ {
+			window *= 2
+		}
+		e.until = now.Add(min(window, maxCooldown))
+		until = later(until, e.until)
+	}
+	return until
+}
+
+// Succeeded records that a send to agentID went through, ending the agent's
+// cool-down. Its model's stays, as other agents may still be throttled.
+func (l *Limiter) Succeeded(agentID string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.entries, "agent:"+agentID)
+}
+
+// BlockedUntil reports whether dispatch to agentID is held back, and until
+// when.
+func (l *Limiter) BlockedUntil(agentID string) (time.Time, bool) {
+	if l == nil {
+		return time.Time{}, false
+	}
+	keys := l.keys(agentID)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	var until time.Time
+	for _, k := range keys {
+		if e := l.entries[k]; e != nil && now.Before(e.until) {
+			until = later(until, e.until)
+		}
+	}
+	return until, !until.IsZero()
+}
+
+// Cooldowns returns the cool-downs in effect, soonest to end first.
+func (l *Limiter) Cooldowns() []Cooldown {
+	result := []Cooldown{}
+	if l == nil {
+		return result
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	for k, e := range l.entries {
+		if now.Before(e.until) {
+			result = append(result, Cooldown{Key: k, Until: e.until, Hits: e.hits})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Until.Equal(result[j].Until) {
+			return result[i].Until.Before(result[j].Until)
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+// Reset ends every cool-down, e.g. once an operator has raised the limit.
+func (l *Limiter) Reset() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = make(map[string]*entry)
+}
+
+func later(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
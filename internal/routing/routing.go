@@ -0,0 +1,147 @@
+// Package routing picks the model a task runs on from the routing policy in
+// settings: rules matching the task's priority and size, first match wins,
+// e.g. priority 1 to the strongest model and small priority-4 tasks to the
+// cheapest. A model set on the task itself overrides the policy.
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Task sizes, estimated from the task's description (see SizeOf).
+const (
+	SizeSmall  = "small"
+	SizeMedium = "medium"
+	SizeLarge  = "large"
+)
+
+// Word counts of a description at which a task stops being small, and
+// becomes large.
+const (
+	mediumWords = 50
+	largeWords  = 300
+)
+
+// Priority bounds, as on tasks: 1 is the most urgent.
+const (
+	minPriority = 1
+	maxPriority = 5
+)
+
+// Rule routes the tasks it matches to Model. Empty conditions match any task.
+type Rule struct {
+	Priorities []int    `json:"priorities,omitempty"` // e.g. [1] or [4, 5]
+	Sizes      []string `json:"sizes,omitempty"`      // small | medium | large
+	Model      string   `json:"model"`
+}
+
+// Policy is the routing policy: its rules in order, and the model of tasks
+// none matches ("" leaves them on the agent's own model).
+type Policy struct {
+	Rules   []Rule `json:"rules"`
+	Default string `json:"default,omitempty"`
+}
+
+// Decision is the model chosen for a task and why.
+type Decision struct {
+	Model    string `json:"model"`
+	Source   string `json:"source"`         // task | rule | default | agent
+	Rule     *int   `json:"rule,omitempty"` // index of the matching rule
+	Priority int    `json:"priority"`
+	Size     string `json:"size"`
+}
+
+// Parse reads a policy stored as JSON; "" is the empty policy.
+func Parse(s string) (Policy, error) {
+	p := Policy{Rules: []Rule{}}
+	if strings.TrimSpace(s) == "" {
+		return p, nil
+	}
+	if err := json.Unmarshal([]byte(s), &p); err != nil {
+		return p, fmt.Errorf("invalid routing policy: %w", err)
+	}
+	if p.Rules == nil {
+		p.Rules = []Rule{}
+	}
+	return p, p.Validate()
+}
+
+// Validate checks that every rule names a model and only valid priorities
+// and sizes.
+func (p Policy) Validate() error {
+	for i, r := range p.Rules {
+		if strings.TrimSpace(r.Model) == "" {
+			return fmt.Errorf("rule %d has no model", i)
+		}
+		for _, pr := range r.Priorities {
+			if pr < minPriority || pr > maxPriority {
+				return fmt.Errorf("rule %d: priority %d is not between %d and %d", i, pr, minPriority, maxPriority)
+			}
+		}
+		for _, s := range r.Sizes {
+			if s != SizeSmall && s != SizeMedium && s != SizeLarge {
+				return fmt.Errorf("rule %d: size %q is not small, medium or large", i, s)
+			}
+		}
+	}
+	return nil
+}
+
+// Empty reports whether the policy routes nothing.
+func (p Policy) Empty() bool {
+	return len(p.Rules) == 0 && p.Default == ""
+}
+
+// Route returns the model of a task of the given priority and size: the
+// first matching rule's, else the default. The decision's Model is "" (and
+// Source "agent") when the task stays on the agent's own model.
+func (p Policy) Route(priority int, size string) Decision {
+	d := Decision{Source: "agent", Priority: priority, Size: size}
+	for i, r := range p.Rules {
+		if r.matches(priority, size) {
+			d.Model, d.Source, d.Rule = r.Model, "rule", &i
+			return d
+		}
+	}
+	if p.Default != "" {
+		d.Model, d.Source = p.Default, "default"
+	}
+	return d
+}
+
+func (r Rule) matches(priority int, size string) bool {
+	return (len(r.Priorities) == 0 || containsInt(r.Priorities, priority)) &&
+		(len(r.Sizes) == 0 || containsString(r.Sizes, size))
+}
+
+// SizeOf estimates the size of a task from its description: small under 50
+// words, large from 300.
+func SizeOf(description string) string {
+	switch n := len(strings.Fields(description)); {
+	case n >= largeWords:
+		return SizeLarge
+	case n >= mediumWords:
+		return SizeMedium
+	}
+	return SizeSmall
+}
+
+func containsInt(list []int, v int) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, v string) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
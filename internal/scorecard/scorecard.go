@@ -0,0 +1,210 @@
+// Package scorecard rates agents on their recent work: tasks completed,
+// success rate, cycle time, watchdog resets and change requests over a
+// window, combined into one score. Scores rank agents on the leaderboard and
+// let the best_performer dispatch strategy hand group tasks to the agents
+// doing best.
+package scorecard
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultWindow is the window scorecards cover when none is given.
+const DefaultWindow = 30 * 24 * time.Hour
+
+// MinTasks is how many finished tasks in the window an agent needs to be
+// rated; until then its score is NeutralScore.
+const MinTasks = 3
+
+// NeutralScore is the score of agents without enough finished tasks to be
+// rated, so new agents still get work ahead of poorly performing ones.
+const NeutralScore = 50.0
+
+// Outcome is a task an agent finished, done or failed.
+type Outcome struct {
+	Done      bool
+	CycleTime time.Duration // start (or creation) to completion; 0 if unknown
+}
+
+// Card is an agent's scorecard over a window.
+type Card struct {
+	AgentID             string  `json:"agent_id"`
+	Window              string  `json:"window"`
+	TasksCompleted      int     `json:"tasks_completed"`
+	TasksFailed         int     `json:"tasks_failed"`
+	SuccessRate         float64 `json:"success_rate"` // completed / finished
+	AvgCycleTimeSeconds float64 `json:"avg_cycle_time_seconds"`
+	WatchdogResets      int     `json:"watchdog_resets"`
+	ChangeRequests      int     `json:"change_requests"`
+	ChangeRequestRate   float64 `json:"change_request_rate"` // per completed task
+	Rated               bool    `json:"rated"`               // finished at least MinTasks
+	Score               float64 `json:"score"`               // 0-100
+	Rank                int     `json:"rank,omitempty"`      // on the leaderboard
+}
+
+// tally is a card being built, with the cycle times behind its average.
+type tally struct {
+	Card
+	cycleTotal float64
+	cycleCount int
+}
+
+// ParseWindow parses a window such as "7d", "24h" or "90m"; "" is
+// DefaultWindow and "all" (returned as 0) is no limit.
+func ParseWindow(s string) (time.Duration, error) {
+	switch s = strings.TrimSpace(s); s {
+	case "":
+		return DefaultWindow, nil
+	case "all":
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid window %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid window %q: use e.g. 7d, 24h or all", s)
+	}
+	return d, nil
+}
+
+// FormatWindow formats a window as ParseWindow reads it.
+func FormatWindow(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return "all"
+	case d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return d.String()
+}
+
+// Since returns the start of a window ending now; the zero time for no
+// limit.
+func Since(window time.Duration, now time.Time) time.Time {
+	if window <= 0 {
+		return time.Time{}
+	}
+	return now.Add(-window)
+}
+
+// Builder collects the outcomes, resets and change requests of agents over
+// one window into their cards.
+type Builder struct {
+	window string
+	cards  map[string]*tally
+}
+
+// NewBuilder starts the cards of a window.
+func NewBuilder(window time.Duration) *Builder {
+	return &Builder{window: FormatWindow(window), cards: make(map[string]*tally)}
+}
+
+func (b *Builder) card(agentID string) *tally {
+	c, ok := b.cards[agentID]
+	if !ok {
+		c = &tally{Card: Card{AgentID: agentID, Window: b.window}}
+		b.cards[agentID] = c
+	}
+	return c
+}
+
+// Add records a task agentID finished.
+func (b *Builder) Add(agentID string, o Outcome) {
+	c := b.card(agentID)
+	if !o.Done {
+		c.TasksFailed++
+		return
+	}
+	c.TasksCompleted++
+	if o.CycleTime > 0 {
+		c.cycleTotal += o.CycleTime.Seconds()
+		c.cycleCount++
+	}
+}
+
+// AddResets records n tasks the watchdog took back from agentID.
+func (b *Builder) AddResets(agentID string, n int) {
+	b.card(agentID).WatchdogResets += n
+}
+
+// AddChangeRequests records n change requests on agentID's work.
+func (b *Builder) AddChangeRequests(agentID string, n int) {
+	b.card(agentID).ChangeRequests += n
+}
+
+// Card returns agentID's card; an agent with no recorded work gets an
+// unrated one.
+func (b *Builder) Card(agentID string) Card {
+	c := b.card(agentID)
+	c.finish()
+	return c.Card
+}
+
+// Leaderboard returns the cards of agentIDs, best first: rated agents by
+// score, then unrated ones, ties by tasks completed and then ID.
+func (b *Builder) Leaderboard(agentIDs []string) []Card {
+	cards := make([]Card, 0, len(agentIDs))
+	for _, id := range agentIDs {
+		cards = append(cards, b.Card(id))
+	}
+	sort.SliceStable(cards, func(i, j int) bool {
+		a, c := cards[i], cards[j]
+		if a.Rated != c.Rated {
+			return a.Rated
+		}
+		if a.Score != c.Score {
+			return a.Score > c.Score
+		}
+		if a.TasksCompleted != c.TasksCompleted {
+			return a.TasksCompleted > c.TasksCompleted
+		}
+		return a.AgentID < c.AgentID
+	})
+	for i := range cards {
+		cards[i].Rank = i + 1
+	}
+	return cards
+}
+
+// Scores returns each agent's score, NeutralScore for agents not rated.
+func (b *Builder) Scores(agentIDs []string) map[string]float64 {
+	scores := make(map[string]float64, len(agentIDs))
+	for _, id := range agentIDs {
+		scores[id] = b.Card(id).Score
+	}
+	return scores
+}
+
+// finish derives the rates and score from the counts. The score is the
+// success rate, discounted by up to half for change requests per completed
+// task and by up to half for watchdog resets per finished task.
+func (c *tally) finish() {
+	finished := c.TasksCompleted + c.TasksFailed
+	c.SuccessRate, c.ChangeRequestRate, c.AvgCycleTimeSeconds = 0, 0, 0
+	if finished > 0 {
+		c.SuccessRate = float64(c.TasksCompleted) / float64(finished)
+	}
+	if c.TasksCompleted > 0 {
+		c.ChangeRequestRate = float64(c.ChangeRequests) / float64(c.TasksCompleted)
+	}
+	if c.cycleCount > 0 {
+		c.AvgCycleTimeSeconds = c.cycleTotal / float64(c.cycleCount)
+	}
+	c.Rated = finished >= MinTasks
+	if !c.Rated {
+		c.Score = NeutralScore
+		return
+	}
+	resetRate := float64(c.WatchdogResets) / float64(finished)
+	score := 100 * c.SuccessRate * (1 - min(c.ChangeRequestRate, 1)/2) * (1 - min(resetRate, 1)/2)
+	c.Score = float64(int(score*10+0.5)) / 10
+}
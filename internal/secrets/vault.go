@@ -0,0 +1,78 @@
+// Package secrets seals project secrets (API keys, deploy tokens) for
+// storage. Values are encrypted with AES-256-GCM under a key derived from
+// SECRETS_KEY and bound to the project and name they were stored under, so a
+// sealed value copied to another row does not open.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// namePattern is what a secret may be called: an environment variable name.
+var namePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,63}$`)
+
+// ValidName reports whether name can name a secret.
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+// ErrMalformed is returned by Open for values that were not sealed by a
+// vault with the same key, project and name.
+var ErrMalformed = errors.New("sealed secret is malformed or was sealed with another key")
+
+// Vault seals and opens secret values. A nil Vault has no key: the secrets
+// store is disabled.
+type Vault struct {
+	aead cipher.AEAD
+}
+
+// NewVault creates a Vault keyed by key (any length; it is hashed to 32
+// bytes). It returns nil for an empty key.
+func NewVault(key string) *Vault {
+	if key == "" {
+		return nil
+	}
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		panic(err) // a 32-byte key is always valid
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(err)
+	}
+	return &Vault{aead: aead}
+}
+
+// Seal encrypts value for storage as projectID's secret name.
+func (v *Vault) Seal(projectID, name, value string) string {
+	nonce := make([]byte, v.aead.NonceSize())
+	rand.Read(nonce) // never fails since Go 1.24
+	sealed := v.aead.Seal(nonce, nonce, []byte(value), additionalData(projectID, name))
+	return base64.StdEncoding.EncodeToString(sealed)
+}
+
+// Open decrypts a value sealed for projectID's secret name.
+func (v *Vault) Open(projectID, name, sealed string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(raw) < v.aead.NonceSize() {
+		return "", ErrMalformed
+	}
+	nonce, ciphertext := raw[:v.aead.NonceSize()], raw[v.aead.NonceSize():]
+	value, err := v.aead.Open(nil, nonce, ciphertext, additionalData(projectID, name))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	return string(value), nil
+}
+
+func additionalData(projectID, name string) []byte {
+	return []byte(projectID + "/" + name)
+}
@@ -0,0 +1,463 @@
+// Package standup posts a daily standup without a meeting: for each agent,
+// the tasks it finished in the last 24 hours, what it is working on, the
+// progress it reported and what blocked it (stories and tasks that failed),
+// optionally with a short status in the agent's own words. Each agent's
+// summary is a standup event.
+package standup
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/leader"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/maintenance"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+	ws "github.com/abelkuruvilla/claw-agent-mission-control/internal/websocket"
+)
+
+// Window is how far back a standup looks.
+const Window = 24 * time.Hour
+
+const (
+	maxHighlights = 5    // progress entries per agent, the latest
+	maxLine       = 200  // characters of a progress entry or error
+	maxStatus     = 2000 // characters of an agent's own status
+)
+
+// TaskRef names a task.
+type TaskRef struct {
+	ID      string `json:"id"`
+	ShortID string `json:"short_id,omitempty"`
+	Title   string `json:"title"`
+}
+
+// Highlight is a progress entry an agent reported.
+type Highlight struct {
+	TaskRef
+	Content string    `json:"content"`
+	At      time.Time `json:"at"`
+}
+
+// Blocker is a story, or a whole task, that failed.
+type Blocker struct {
+	TaskRef
+	Story string `json:"story,omitempty"` // empty when the task failed
+	Error string `json:"error"`
+}
+
+// Entry is an agent's part of the standup.
+type Entry struct {
+	AgentID     string      `json:"agent_id"`
+	AgentName   string      `json:"agent_name"`
+	Completed   []TaskRef   `json:"completed"`
+	Active      []TaskRef   `json:"active"`
+	Progress    []Highlight `json:"progress"`
+	Blockers    []Blocker   `json:"blockers"`
+	Status      string      `json:"status,omitempty"`       // in the agent's own words, when asked
+	StatusError string      `json:"status_error,omitempty"` // why asking the agent failed
+	Summary     string      `json:"summary"`                // all of the above as text
+}
+
+// Report is a standup: the agents that did or are doing something, by name.
+type Report struct {
+	Since  time.Time `json:"since"`
+	Until  time.Time `json:"until"`
+	Agents []Entry   `json:"agents"`
+	Posted bool      `json:"posted"`
+}
+
+// Generator compiles standups when asked and, while running, posts one
+// every day at a set time (UTC).
+type Generator struct {
+	store       *store.Store
+	hub         *ws.Hub
+	at          time.Duration // time of day, since midnight UTC; < 0 = on demand only
+	sender      openclaw.Sender
+	askTimeout  time.Duration // 0 = agents are not asked
+	maintenance *maintenance.Mode
+	leader      *leader.Elector
+
+	mu sync.Mutex // one standup posted at a time
+
+	stopChan chan struct{}
+	running  bool
+}
+
+func NewGenerator(st *store.Store, hub *ws.Hub, at time.Duration) *Generator {
+	return &Generator{
+		store:    st,
+		hub:      hub,
+		at:       at,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// SetAskAgents has each agent in a posted standup asked for a short status
+// through sender, waiting up to timeout for its answer.
+func (g *Generator) SetAskAgents(sender openclaw.Sender, timeout time.Duration) {
+	g.sender = sender
+	g.askTimeout = timeout
+}
+
+// AsksAgents reports whether agents are asked for their status.
+func (g *Generator) AsksAgents() bool {
+	return g.sender != nil && g.askTimeout > 0
+}
+
+// AskTimeout returns how long an agent has to give its status.
+func (g *Generator) AskTimeout() time.Duration {
+	return g.askTimeout
+}
+
+// SetMaintenance sets the maintenance switch; scheduled standups are
+// skipped while it is on.
+func (g *Generator) SetMaintenance(m *maintenance.Mode) {
+	g.maintenance = m
+}
+
+// SetLeader sets the leader elector; scheduled standups are only posted by
+// the leader.
+func (g *Generator) SetLeader(l *leader.Elector) {
+	g.leader = l
+}
+
+// Schedule returns the time of day (UTC) standups are posted at, e.g.
+// "09:00", or "" when they are not scheduled.
+func (g *Generator) Schedule() string {
+	if g.at < 0 {
+		return ""
+	}
+	return time.Time{}.Add(g.at).Format("15:04")
+}
+
+// Compile gathers the standup for the Window before until, without asking
+// the agents or posting it.
+func (g *Generator) Compile(ctx context.Context, until time.Time) (Report, error) {
+	until = until.UTC()
+	since := until.Add(-Window)
+	report := Report{Since: since, Until: until, Agents: []Entry{}}
+
+	agents, err := g.store.ListAgents(ctx)
+	if err != nil {
+		return report, err
+	}
+	tasks, err := g.store.ListTasks(ctx)
+	if err != nil {
+		return report, err
+	}
+	progress, err := g.store.ListProgressEntriesSince(ctx, since)
+	if err != nil {
+		return report, err
+	}
+	stories, err := g.store.ListFailedStoriesSince(ctx, since)
+	if err != nil {
+		return report, err
+	}
+
+	entries := make(map[string]*Entry, len(agents))
+	for _, a := range agents {
+		entries[a.ID] = &Entry{
+			AgentID:   a.ID,
+			AgentName: a.Name,
+			Completed: []TaskRef{},
+			Active:    []TaskRef{},
+			Progress:  []Highlight{},
+			Blockers:  []Blocker{},
+		}
+	}
+	taskByID := make(map[string]db.Task, len(tasks))
+	for _, t := range tasks {
+		taskByID[t.ID] = t
+		e := entries[t.AgentID.String]
+		if e == nil {
+			continue
+		}
+		switch t.Status.String {
+		case "done":
+			if within(t.CompletedAt, since, until) {
+				e.Completed = append(e.Completed, taskRef(t))
+			}
+		case "failed":
+			if within(t.UpdatedAt, since, until) {
+				e.Blockers = append(e.Blockers, Blocker{TaskRef: taskRef(t), Error: oneLine(t.FailureReason.String)})
+			}
+		case "planning", "discussing", "executing", "verifying", "review":
+			e.Active = append(e.Active, taskRef(t))
+		}
+	}
+	for _, p := range progress {
+		t, ok := taskByID[p.TaskID]
+		e := entries[t.AgentID.String]
+		if !ok || e == nil || !within(p.CreatedAt, since, until) {
+			continue
+		}
+		e.Progress = append(e.Progress, Highlight{TaskRef: taskRef(t), Content: oneLine(p.Content), At: p.CreatedAt.Time.UTC()})
+		if len(e.Progress) > maxHighlights {
+			e.Progress = e.Progress[1:]
+		}
+	}
+	for _, s := range stories {
+		t, ok := taskByID[s.TaskID]
+		e := entries[t.AgentID.String]
+		if !ok || e == nil || !within(s.UpdatedAt, since, until) {
+			continue
+		}
+		e.Blockers = append(e.Blockers, Blocker{TaskRef: taskRef(t), Story: s.Title, Error: oneLine(s.LastError.String)})
+	}
+
+	for _, a := range agents {
+		e := entries[a.ID]
+		if len(e.Completed)+len(e.Active)+len(e.Progress)+len(e.Blockers) == 0 {
+			continue
+		}
+		e.Summary = summarize(*e)
+		report.Agents = append(report.Agents, *e)
+	}
+	sort.Slice(report.Agents, func(i, j int) bool {
+		return strings.ToLower(report.Agents[i].AgentName) < strings.ToLower(report.Agents[j].AgentName)
+	})
+	return report, nil
+}
+
+// Post compiles the standup for the last Window, asks each agent in it for
+// its status if set to, and posts it: a standup event per agent, then a
+// standup_posted event.
+func (g *Generator) Post(ctx context.Context) (Report, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	report, err := g.Compile(ctx, time.Now())
+	if err != nil {
+		return report, err
+	}
+	if g.AsksAgents() {
+		g.ask(ctx, report.Agents)
+	}
+
+	completed, blockers := 0, 0
+	for _, e := range report.Agents {
+		completed += len(e.Completed)
+		blockers += len(e.Blockers)
+		details, _ := json.Marshal(e)
+		g.logEvent(ctx, e.AgentID, "standup",
+			fmt.Sprintf("Standup for %s: %d task(s) done, %d in progress, %d progress update(s), %d blocker(s)",
+				e.AgentName, len(e.Completed), len(e.Active), len(e.Progress), len(e.Blockers)),
+			string(details))
+	}
+	report.Posted = true
+	log.Printf("[Standup] Posted the standup of %d agent(s)", len(report.Agents))
+	g.logEvent(ctx, "", "standup_posted",
+		fmt.Sprintf("Standup posted: %d agent(s), %d task(s) done, %d blocker(s)", len(report.Agents), completed, blockers),
+		fmt.Sprintf(`{"since":%q,"until":%q,"agents":%d,"completed":%d,"blockers":%d}`,
+			report.Since.Format(time.RFC3339), report.Until.Format(time.RFC3339), len(report.Agents), completed, blockers))
+	return report, nil
+}
+
+// ask asks the agents of entries for their status, all at once, and adds
+// their answers to their summaries.
+func (g *Generator) ask(ctx context.Context, entries []Entry) {
+	sender := g.sender.For(ctx)
+	var wg sync.WaitGroup
+	for i := range entries {
+		wg.Add(1)
+		go func(e *Entry) {
+			defer wg.Done()
+			reply, err := sender.RunCommand(ctx, e.AgentID, Prompt(*e), g.askTimeout, nil)
+			if err != nil {
+				log.Printf("[Standup] Agent %s gave no status: %v", e.AgentID, err)
+				e.StatusError = err.Error()
+				return
+			}
+			e.Status = truncate(strings.TrimSpace(reply), maxStatus)
+			e.Summary = summarize(*e)
+		}(&entries[i])
+	}
+	wg.Wait()
+}
+
+// Prompt is the message asking an agent for its status, given what was
+// recorded of its day.
+func Prompt(e Entry) string {
+	var b strings.Builder
+	b.WriteString("It's standup time in Mission Control. This is what was recorded of your last 24 hours:\n\n")
+	b.WriteString(e.Summary)
+	b.WriteString("\n\nReply with a short status, two or three sentences and nothing else: what you got done, what you are doing next, and anything blocking you.")
+	return b.String()
+}
+
+// summarize writes e as text.
+func summarize(e Entry) string {
+	var b strings.Builder
+	section := func(title string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(title + ":\n")
+		for _, l := range lines {
+			b.WriteString("- " + l + "\n")
+		}
+	}
+
+	var lines []string
+	for _, t := range e.Completed {
+		lines = append(lines, t.String())
+	}
+	section("Done", lines)
+	lines = nil
+	for _, t := range e.Active {
+		lines = append(lines, t.String())
+	}
+	section("In progress", lines)
+	lines = nil
+	for _, h := range e.Progress {
+		lines = append(lines, fmt.Sprintf("%s: %s", h.TaskRef, h.Content))
+	}
+	section("Progress", lines)
+	lines = nil
+	for _, bl := range e.Blockers {
+		what := bl.TaskRef.String()
+		if bl.Story != "" {
+			what = fmt.Sprintf("story %q of %s", bl.Story, bl.TaskRef)
+		}
+		if bl.Error != "" {
+			what += ": " + bl.Error
+		}
+		lines = append(lines, what)
+	}
+	section("Blockers", lines)
+	if e.Status != "" {
+		section("Status", []string{strings.Join(strings.Fields(e.Status), " ")})
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func (t TaskRef) String() string {
+	if t.ShortID != "" {
+		return fmt.Sprintf("%s %s", t.ShortID, t.Title)
+	}
+	return t.Title
+}
+
+func taskRef(t db.Task) TaskRef {
+	return TaskRef{ID: t.ID, ShortID: t.ShortID.String, Title: t.Title}
+}
+
+func within(t sql.NullTime, since, until time.Time) bool {
+	return t.Valid && !t.Time.Before(since) && !t.Time.After(until)
+}
+
+// oneLine is the first line of s, shortened to maxLine characters.
+func oneLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	return truncate(s, maxLine)
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
+
+// Start posts a standup every day at the set time until Stop is called or
+// ctx is done. It does nothing when standups are not scheduled.
+func (g *Generator) Start(ctx context.Context) {
+	if g.at < 0 {
+		return
+	}
+	if g.running {
+		log.Println("[Standup] Already running")
+		return
+	}
+	g.running = true
+	log.Printf("[Standup] Posting the standup daily at %s UTC", g.Schedule())
+
+	go func() {
+		timer := time.NewTimer(time.Until(g.next(time.Now())))
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-timer.C:
+				g.runScheduled(ctx)
+				timer.Reset(time.Until(g.next(time.Now())))
+			case <-g.stopChan:
+				log.Println("[Standup] Stopping")
+				g.running = false
+				return
+			case <-ctx.Done():
+				g.running = false
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the daily standups.
+func (g *Generator) Stop() {
+	if !g.running {
+		return
+	}
+	close(g.stopChan)
+	g.running = false
+}
+
+// Running reports whether the daily standup is running.
+func (g *Generator) Running() bool {
+	return g.running
+}
+
+func (g *Generator) runScheduled(ctx context.Context) {
+	if g.maintenance.Enabled() {
+		log.Println("[Standup] Maintenance mode, skipping standup")
+		return
+	}
+	if !g.leader.IsLeader() {
+		return
+	}
+	if _, err := g.Post(ctx); err != nil {
+		log.Printf("[Standup] Standup failed: %v", err)
+		g.logEvent(ctx, "", "standup_failed", fmt.Sprintf("Standup failed: %v", err), "")
+	}
+}
+
+// next is the first scheduled time after now.
+func (g *Generator) next(now time.Time) time.Time {
+	now = now.UTC()
+	t := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).Add(g.at)
+	if !t.After(now) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+func (g *Generator) logEvent(ctx context.Context, agentID, eventType, message, details string) {
+	event, err := g.store.CreateEvent(ctx, db.CreateEventParams{
+		AgentID: sql.NullString{String: agentID, Valid: agentID != ""},
+		Type:    eventType,
+		Message: message,
+		Details: sql.NullString{String: details, Valid: details != ""},
+	})
+	if err != nil {
+		log.Printf("[Standup] Failed to create event (%s): %v", eventType, err)
+		return
+	}
+	if g.hub != nil {
+		g.hub.BroadcastEvent(event)
+	}
+}
@@ -0,0 +1,362 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+)
+
+// Per-domain views of Store. Handlers depend on these rather than *Store so
+// they can be unit tested against the mocks in internal/store/storemock.
+// When adding a Store method that handlers need, add it to the matching
+// interface below and run `make mocks`.
+
+type AgentStore interface {
+	CreateAgent(ctx context.Context, params db.CreateAgentParams) (db.Agent, error)
+	GetAgent(ctx context.Context, id string) (db.Agent, error)
+	ListAgents(ctx context.Context) ([]db.Agent, error)
+	ListAgentsByIDs(ctx context.Context, ids []string) ([]db.Agent, error)
+	UpdateAgent(ctx context.Context, params db.UpdateAgentParams) (db.Agent, error)
+	DeleteAgent(ctx context.Context, id string) error
+	BeginAgentDeletion(ctx context.Context, id string, purgeWorkspace bool, tasks AgentTaskStrategy) (db.AgentDeletion, []db.Task, error)
+	GetAgentDeletion(ctx context.Context, id string) (db.AgentDeletion, error)
+	ListAgentDeletions(ctx context.Context) ([]db.AgentDeletion, error)
+	RecordAgentDeletionFailure(ctx context.Context, id, errMsg string) error
+	PurgeAgent(ctx context.Context, id string) error
+	UpdateAgentStatus(ctx context.Context, id, status string) error
+	UpdateAgentLocale(ctx context.Context, id, locale string) error
+	UpdateAgentMemory(ctx context.Context, id, memory string) error
+	UpdateAgentTimezone(ctx context.Context, id, timezone string) error
+	UpdateAgentWorkingHours(ctx context.Context, id, workingHours string) error
+	UpdateAgentNotificationPrefs(ctx context.Context, id, prefs string) error
+	UpdateAgentNotificationTimeouts(ctx context.Context, id, timeouts string) error
+	UpdateAgentPublicAPIURL(ctx context.Context, id, url string) error
+	UpdateAgentDelivery(ctx context.Context, id, method, callbackURL, secret string) error
+	SetAgentGateway(ctx context.Context, id, gatewayID string) error
+	RegisterExternalAgent(ctx context.Context, params db.CreateAgentParams, callbackURL string, then func(tx *Store, agent db.Agent) error) (db.Agent, bool, error)
+}
+
+type TaskStore interface {
+	CreateTask(ctx context.Context, params db.CreateTaskParams) (db.Task, error)
+	CreateTaskWith(ctx context.Context, params db.CreateTaskParams, then func(tx *Store, task db.Task) error) (db.Task, error)
+	GetTask(ctx context.Context, id string) (db.Task, error)
+	GetTaskByShortID(ctx context.Context, shortID string) (db.Task, error)
+	ListTasks(ctx context.Context) ([]db.Task, error)
+	ListTasksByStatus(ctx context.Context, status string) ([]db.Task, error)
+	ListTasksByAgent(ctx context.Context, agentID string) ([]db.Task, error)
+	ListTasksByIDs(ctx context.Context, ids []string) ([]db.Task, error)
+	ListTasksByParentIDs(ctx context.Context, parentIDs []string) ([]db.Task, error)
+	ListTasksByAgentIDs(ctx context.Context, agentIDs []string) ([]db.Task, error)
+	UpdateTask(ctx context.Context, params db.UpdateTaskParams) (db.Task, error)
+	UpdateTaskStatus(ctx context.Context, id, status string) error
+	DeleteTask(ctx context.Context, id string) error
+	ListQueuedTasksByAgent(ctx context.Context, agentID string) ([]db.Task, error)
+	CountActiveTasksByAgent(ctx context.Context, agentID string) (int64, error)
+	CountOpenTasksByAgent(ctx context.Context, agentID string) (int64, error)
+	CountQueuedTasksByAgent(ctx context.Context, agentID string) (int64, error)
+	CountQueuedTasksByGroup(ctx context.Context, groupID string) (int64, error)
+	CountQueuedGroupTasksForAgent(ctx context.Context, agentID string) (int64, error)
+	ListStaleTasks(ctx context.Context, cutoff time.Time) ([]db.Task, error)
+	IncrementTaskRetryCount(ctx context.Context, taskID string) error
+	ResetStuckTask(ctx context.Context, taskID string) error
+	ResetTaskRetryCount(ctx context.Context, taskID string) error
+	AppendProgressTxt(ctx context.Context, taskID, content string) error
+	ListSubtasks(ctx context.Context, parentTaskID sql.NullString) ([]db.Task, error)
+	SetTaskScheduledAt(ctx context.Context, id string, t time.Time) error
+	SetTaskRetryAt(ctx context.Context, id string, t time.Time) error
+	ClearTaskScheduledAt(ctx context.Context, id string) error
+	ClearTaskRetryAt(ctx context.Context, id string) error
+	ListScheduledDueTasks(ctx context.Context) ([]db.Task, error)
+	ListRetryDueTasks(ctx context.Context) ([]db.Task, error)
+	ListCalendarTasks(ctx context.Context) ([]db.Task, error)
+	UpdateTaskDetails(ctx context.Context, id, title, description string, priority int64) error
+	SetTaskDeferredUntil(ctx context.Context, id string, t time.Time) error
+	ClearTaskDeferredUntil(ctx context.Context, id string) error
+	SetTaskSecretNames(ctx context.Context, id string, names []string) error
+	SetTaskProgress(ctx context.Context, id string, percent int, explicit bool) error
+	ClearTaskProgress(ctx context.Context, id string) error
+	SetTaskContextSummary(ctx context.Context, id, summary string) error
+	SetTaskDelegationLimits(ctx context.Context, id string, maxDepth, maxConcurrent sql.NullInt64) error
+	SetTaskFailureReason(ctx context.Context, id, reason string) error
+	CountTaskFailures(ctx context.Context) ([]db.CountTaskFailuresRow, error)
+	CountQueuedTasks(ctx context.Context) ([]db.CountQueuedTasksRow, error)
+	CountTasksByStatus(ctx context.Context) ([]db.CountTasksByStatusRow, error)
+	ListAgentTaskOutcomes(ctx context.Context, since time.Time) ([]db.ListAgentTaskOutcomesRow, error)
+	SetTaskModel(ctx context.Context, id, model string) error
+	SetTaskRoutedModel(ctx context.Context, id, model string) error
+	SetTaskReview(ctx context.Context, id string, required bool, reviewerAgentID, reviewerUser string) error
+	ListDeferredDueTasks(ctx context.Context) ([]db.Task, error)
+	SetTaskQueuePositions(ctx context.Context, taskIDs []string) error
+	TransferTask(ctx context.Context, id, fromStatus, agentID, status string) (db.Task, error)
+	ListQueuedTasksByGroup(ctx context.Context, groupID string) ([]db.Task, error)
+	ListQueuedGroupTasksForAgent(ctx context.Context, agentID string) ([]db.Task, error)
+	AssignTaskToGroup(ctx context.Context, taskID, groupID string) (db.Task, error)
+	ClaimGroupTask(ctx context.Context, taskID, agentID string) (db.Task, error)
+	AddTaskPlan(ctx context.Context, taskID string, plan TaskPlan) (db.Task, error)
+	SplitTask(ctx context.Context, parentID string, splits []TaskSplit) ([]db.Task, error)
+	MergeTasks(ctx context.Context, survivorID string, duplicateIDs []string) error
+	GetTaskRedirect(ctx context.Context, fromTaskID string) (db.TaskRedirect, error)
+}
+
+type AgentGroupStore interface {
+	CreateAgentGroup(ctx context.Context, params db.CreateAgentGroupParams) (db.AgentGroup, error)
+	GetAgentGroup(ctx context.Context, id string) (db.AgentGroup, error)
+	ListAgentGroups(ctx context.Context) ([]db.AgentGroup, error)
+	UpdateAgentGroup(ctx context.Context, params db.UpdateAgentGroupParams) (db.AgentGroup, error)
+	DeleteAgentGroup(ctx context.Context, id string) error
+	AddAgentGroupMember(ctx context.Context, groupID, agentID string, skills []string) error
+	RemoveAgentGroupMember(ctx context.Context, groupID, agentID string) error
+	ListAgentGroupMembers(ctx context.Context, groupID string) ([]db.Agent, error)
+	ListAgentGroupMemberships(ctx context.Context, groupID string) ([]db.AgentGroupMember, error)
+	SetAgentGroupLastDispatched(ctx context.Context, groupID, agentID string) error
+	ListAgentGroupsByAgent(ctx context.Context, agentID string) ([]db.AgentGroup, error)
+}
+
+type NotificationStore interface {
+	CreateNotificationDelivery(ctx context.Context, kind, taskID, transition, agentID, sessionKey string) (db.NotificationDelivery, error)
+	GetNotificationDelivery(ctx context.Context, id string) (db.NotificationDelivery, error)
+	MarkNotificationDelivered(ctx context.Context, id string) error
+	MarkNotificationFailed(ctx context.Context, id, lastError string) error
+	RetryNotificationDelivery(ctx context.Context, id, agentID string) error
+	AbandonNotificationDelivery(ctx context.Context, id string) error
+	ListUndeliveredNotifications(ctx context.Context, cutoff time.Time) ([]db.NotificationDelivery, error)
+	AbandonUndeliveredNotifications(ctx context.Context, taskID, kind string) error
+	ListNotificationDeliveriesByTask(ctx context.Context, taskID string) ([]db.NotificationDelivery, error)
+}
+
+type TaskAttemptStore interface {
+	RecordTaskAttempt(ctx context.Context, taskID, kind, agentID, reason, outcome, errMsg string) (db.TaskAttempt, error)
+	FinishTaskAttempt(ctx context.Context, id, outcome, errMsg string) error
+	ListTaskAttemptsByTask(ctx context.Context, taskID string) ([]db.TaskAttempt, error)
+	CountWatchdogResetsByAgent(ctx context.Context, since time.Time) ([]db.CountWatchdogResetsByAgentRow, error)
+	InterruptPendingTaskAttempts(ctx context.Context, errMsg string) (int64, error)
+}
+
+type TaskResultStore interface {
+	AddTaskResult(ctx context.Context, taskID, agentID, kind, body string, metadata map[string]string) (db.TaskResult, error)
+	ListTaskResults(ctx context.Context, taskID string, limit int64) ([]db.TaskResult, error)
+	ListLatestTaskResults(ctx context.Context, taskIDs []string) ([]db.ListLatestTaskResultsRow, error)
+}
+
+type TaskTriageStore interface {
+	StartTaskTriage(ctx context.Context, taskID, agentID string) (db.TaskTriage, error)
+	GetTaskTriage(ctx context.Context, taskID string) (db.TaskTriage, error)
+	ListTaskTriage(ctx context.Context, status string) ([]db.TaskTriage, error)
+	SetTaskTriageResult(ctx context.Context, taskID, status, suggestion, errMsg string) error
+	DecideTaskTriage(ctx context.Context, taskID, status, decidedBy string) error
+	SetTaskLabels(ctx context.Context, taskID string, labels []string) error
+	ListTaskLabels(ctx context.Context, taskIDs []string) (map[string][]string, error)
+}
+
+type TaskLinkStore interface {
+	SetTaskLinks(ctx context.Context, sourceTaskID, sourceKind, sourceID string, targetIDs []string) error
+	DeleteTaskLinksBySource(ctx context.Context, sourceKind, sourceID string) error
+	ListTaskLinks(ctx context.Context, taskID string) ([]db.ListTaskLinksRow, error)
+	ListTaskBacklinks(ctx context.Context, taskID string) ([]db.ListTaskBacklinksRow, error)
+	ResolveTaskPrefix(ctx context.Context, prefix string) (string, error)
+}
+
+type PhaseStore interface {
+	CreatePhase(ctx context.Context, params db.CreatePhaseParams) (db.Phase, error)
+	AppendPhase(ctx context.Context, params db.AppendPhaseParams) (db.Phase, error)
+	GetPhase(ctx context.Context, id string) (db.Phase, error)
+	ListPhasesByTask(ctx context.Context, taskID string) ([]db.Phase, error)
+	UpdatePhase(ctx context.Context, params db.UpdatePhaseParams) (db.Phase, error)
+	UpdatePhaseStatus(ctx context.Context, id, status string) error
+	DeletePhase(ctx context.Context, id string) error
+}
+
+type StoryStore interface {
+	CreateStory(ctx context.Context, params db.CreateStoryParams) (db.Story, error)
+	AppendStory(ctx context.Context, params db.AppendStoryParams) (db.Story, error)
+	GetStory(ctx context.Context, id string) (db.Story, error)
+	ListStoriesByTask(ctx context.Context, taskID string) ([]db.Story, error)
+	ListFailedStoriesSince(ctx context.Context, since time.Time) ([]db.Story, error)
+	GetNextPendingStory(ctx context.Context, taskID string) (db.Story, error)
+	UpdateStory(ctx context.Context, params db.UpdateStoryParams) (db.Story, error)
+	MarkStoryPassed(ctx context.Context, id string) error
+	MarkStoryFailed(ctx context.Context, id, lastError string) error
+	DeleteStory(ctx context.Context, id string) error
+	GetStoryProgress(ctx context.Context, taskID string) (passed, total int64, err error)
+}
+
+type SubAgentStore interface {
+	CreateSubAgent(ctx context.Context, params db.CreateSubAgentParams) (db.SubAgent, error)
+	GetSubAgent(ctx context.Context, id string) (db.SubAgent, error)
+	ListSubAgentsByOrchestrator(ctx context.Context, orchestratorID string) ([]db.SubAgent, error)
+	ListSubAgentsByTask(ctx context.Context, taskID string) ([]db.SubAgent, error)
+	UpdateSubAgentStatus(ctx context.Context, id, status, output, errMsg string) error
+}
+
+type EventStore interface {
+	CreateEvent(ctx context.Context, params db.CreateEventParams) (db.Event, error)
+	ListEvents(ctx context.Context, limit int64) ([]db.Event, error)
+	ListEventsByTask(ctx context.Context, taskID string, limit int64) ([]db.Event, error)
+	ListEventsByTaskIDs(ctx context.Context, taskIDs []string) ([]db.Event, error)
+	ListEventsByAgent(ctx context.Context, agentID string, limit int64) ([]db.Event, error)
+	CountEventsByAgent(ctx context.Context, eventType string, since time.Time) ([]db.CountEventsByAgentRow, error)
+	ListEventsAfter(ctx context.Context, seq, limit int64) ([]db.ListEventsAfterRow, error)
+	ListEventsFiltered(ctx context.Context, f EventFilter) ([]db.ListEventsAfterRow, error)
+	GetLatestEventSeq(ctx context.Context) (int64, error)
+}
+
+type SettingsStore interface {
+	GetSettings(ctx context.Context) (db.Setting, error)
+	UpdateSettings(ctx context.Context, params db.UpdateSettingsParams) (db.Setting, error)
+	SetModelRouting(ctx context.Context, policy string) error
+	SetQuietHours(ctx context.Context, policy string) error
+	SetNotificationTimeouts(ctx context.Context, timeouts string) error
+	SetTaskScripts(ctx context.Context, scripts string) error
+	SetMaintenance(ctx context.Context, since time.Time, reason string) error
+	SetAnalyticsExport(ctx context.Context, seq int64, at time.Time) error
+	SetStorage(ctx context.Context, cfg string) error
+}
+
+type BackupStore interface {
+	Backup(ctx context.Context, path string) error
+	DBStats(ctx context.Context) (DBStats, error)
+	Optimize(ctx context.Context) (OptimizeResult, error)
+}
+
+type LeaseStore interface {
+	AcquireLease(ctx context.Context, name, holder string, expiresAt time.Time) (bool, error)
+	GetLease(ctx context.Context, name string) (db.Lease, error)
+	ReleaseLease(ctx context.Context, name, holder string) error
+}
+
+type SecretStore interface {
+	ListProjectSecrets(ctx context.Context, projectID string) ([]db.ProjectSecret, error)
+	GetProjectSecret(ctx context.Context, projectID, name string) (db.ProjectSecret, error)
+	SetProjectSecret(ctx context.Context, projectID, name, sealedValue string) (db.ProjectSecret, error)
+	DeleteProjectSecret(ctx context.Context, projectID, name string) (bool, error)
+}
+
+type ProgressEntryStore interface {
+	AddProgressEntry(ctx context.Context, taskID, author, content string) (db.ProgressEntry, error)
+	ListProgressEntries(ctx context.Context, taskID string, before, limit int64) ([]db.ProgressEntry, error)
+	ListProgressEntriesSince(ctx context.Context, since time.Time) ([]db.ProgressEntry, error)
+}
+
+type GatewayStore interface {
+	CreateGateway(ctx context.Context, params db.CreateGatewayParams) (db.Gateway, error)
+	GetGateway(ctx context.Context, id string) (db.Gateway, error)
+	ListGateways(ctx context.Context) ([]db.Gateway, error)
+	UpdateGateway(ctx context.Context, params db.UpdateGatewayParams) (db.Gateway, error)
+	DeleteGateway(ctx context.Context, id string) error
+}
+
+type ExperimentStore interface {
+	CreateExperiment(ctx context.Context, params db.CreateExperimentParams) (db.Experiment, error)
+	GetExperiment(ctx context.Context, id string) (db.Experiment, error)
+	ListExperiments(ctx context.Context) ([]db.Experiment, error)
+	ListActiveExperiments(ctx context.Context) ([]db.Experiment, error)
+	UpdateExperiment(ctx context.Context, params db.UpdateExperimentParams) (db.Experiment, error)
+	DeleteExperiment(ctx context.Context, id string) error
+	AddExperimentTask(ctx context.Context, taskID, experimentID, arm string) error
+	GetExperimentTask(ctx context.Context, taskID string) (db.ExperimentTask, error)
+	ListExperimentOutcomes(ctx context.Context, experimentID string) ([]db.ListExperimentOutcomesRow, error)
+}
+
+type ChangeRequestStore interface {
+	CreateChangeRequest(ctx context.Context, params db.CreateChangeRequestParams) (db.ChangeRequest, error)
+	GetChangeRequest(ctx context.Context, id string) (db.ChangeRequest, error)
+	ListChangeRequestsByTask(ctx context.Context, taskID string) ([]db.ChangeRequest, error)
+	ResolveChangeRequest(ctx context.Context, id, commit, resolution, addressedBy string) (db.ChangeRequest, error)
+	CountOpenChangeRequests(ctx context.Context, taskID string) (int64, error)
+}
+
+type JiraLinkStore interface {
+	CreateJiraLink(ctx context.Context, params db.CreateJiraLinkParams) (db.JiraLink, error)
+	GetJiraLinkByIssue(ctx context.Context, issueKey string) (db.JiraLink, error)
+	ListJiraLinks(ctx context.Context) ([]db.JiraLink, error)
+	SetJiraLinkSynced(ctx context.Context, taskID, jiraStatus, taskStatus string) error
+}
+
+type GitHubIssueLinkStore interface {
+	CreateGitHubIssueLink(ctx context.Context, params db.CreateGithubIssueLinkParams) (db.GithubIssueLink, error)
+	GetGitHubIssueLinkByIssue(ctx context.Context, repo string, number int64) (db.GithubIssueLink, error)
+	GetGitHubIssueLinkByTask(ctx context.Context, taskID string) (db.GithubIssueLink, error)
+	ListGitHubIssueLinksByProject(ctx context.Context, projectID string) ([]db.GithubIssueLink, error)
+	SetGitHubIssueLinkState(ctx context.Context, taskID, state string) error
+}
+
+type InboundEmailStore interface {
+	CreateInboundEmail(ctx context.Context, params db.CreateInboundEmailParams) (db.InboundEmail, error)
+	GetInboundEmailByMessageID(ctx context.Context, messageID string) (db.InboundEmail, error)
+	ListInboundEmails(ctx context.Context, limit int64) ([]db.InboundEmail, error)
+}
+
+type ProjectStore interface {
+	CreateProject(ctx context.Context, params db.CreateProjectParams) (db.Project, error)
+	GetProject(ctx context.Context, id string) (db.Project, error)
+	ListProjects(ctx context.Context) ([]db.Project, error)
+	ListProjectsByIDs(ctx context.Context, ids []string) ([]db.Project, error)
+	ListProjectsByStatus(ctx context.Context, status sql.NullString) ([]db.Project, error)
+	ListProjectsWithStats(ctx context.Context, status string) ([]db.ListProjectsWithStatsRow, error)
+	GetProjectWithStats(ctx context.Context, id string) (db.GetProjectWithStatsRow, error)
+	UpdateProject(ctx context.Context, params db.UpdateProjectParams) (db.Project, error)
+	DeleteProject(ctx context.Context, id string) error
+	GetProjectDeletionImpact(ctx context.Context, id string) (db.GetProjectDeletionImpactRow, error)
+	DeleteProjectWithTasks(ctx context.Context, id, mode string) ([]db.Task, error)
+	SetProjectPathPolicy(ctx context.Context, id string, allowed []string, action string) error
+	SetProjectDelegationLimits(ctx context.Context, id string, maxDepth, maxConcurrent sql.NullInt64) error
+	SetProjectGitHub(ctx context.Context, id, repo, label string) error
+	ListProjectsByGitHubRepo(ctx context.Context, repo string) ([]db.Project, error)
+	GetProjectTaskCount(ctx context.Context, projectID sql.NullString) (int64, error)
+	GetProjectDoneTaskCount(ctx context.Context, projectID sql.NullString) (int64, error)
+	ListTasksByProject(ctx context.Context, projectID sql.NullString) ([]db.Task, error)
+	ListTasksByProjectIDs(ctx context.Context, projectIDs []string) ([]db.Task, error)
+}
+
+type TrashStore interface {
+	ListTrashItems(ctx context.Context, kind string) ([]db.ListTrashItemsRow, error)
+	GetTrashItem(ctx context.Context, id string) (db.TrashItem, error)
+	RestoreTrashItem(ctx context.Context, id string) (db.TrashItem, error)
+	DeleteTrashItem(ctx context.Context, id string) error
+	TrashRetention() time.Duration
+}
+
+type CommentStore interface {
+	CreateComment(ctx context.Context, params db.CreateCommentParams) (db.Comment, error)
+	GetComment(ctx context.Context, id string) (db.Comment, error)
+	ListCommentsByTask(ctx context.Context, taskID string) ([]db.Comment, error)
+	ListCommentsPage(ctx context.Context, taskID string, p CommentPage) ([]db.Comment, int64, error)
+	DeleteComment(ctx context.Context, id string) error
+}
+
+type ChatStore interface {
+	CreateChatSession(ctx context.Context, params db.CreateChatSessionParams) (db.ChatSession, error)
+	GetChatSession(ctx context.Context, id string) (db.ChatSession, error)
+	ListChatSessionsByAgent(ctx context.Context, agentID string) ([]db.ChatSession, error)
+	EndChatSession(ctx context.Context, id string) error
+	UpdateMessageCount(ctx context.Context, id string) error
+	CreateChatMessage(ctx context.Context, params db.CreateChatMessageParams) (db.ChatMessage, error)
+	ListMessagesBySession(ctx context.Context, sessionID string) ([]db.ChatMessage, error)
+}
+
+var (
+	_ AgentStore           = (*Store)(nil)
+	_ TaskStore            = (*Store)(nil)
+	_ AgentGroupStore      = (*Store)(nil)
+	_ PhaseStore           = (*Store)(nil)
+	_ StoryStore           = (*Store)(nil)
+	_ SubAgentStore        = (*Store)(nil)
+	_ EventStore           = (*Store)(nil)
+	_ SettingsStore        = (*Store)(nil)
+	_ BackupStore          = (*Store)(nil)
+	_ LeaseStore           = (*Store)(nil)
+	_ GatewayStore         = (*Store)(nil)
+	_ ExperimentStore      = (*Store)(nil)
+	_ ChangeRequestStore   = (*Store)(nil)
+	_ TaskTriageStore      = (*Store)(nil)
+	_ JiraLinkStore        = (*Store)(nil)
+	_ GitHubIssueLinkStore = (*Store)(nil)
+	_ InboundEmailStore    = (*Store)(nil)
+	_ SecretStore          = (*Store)(nil)
+	_ ProgressEntryStore   = (*Store)(nil)
+	_ ProjectStore         = (*Store)(nil)
+	_ CommentStore         = (*Store)(nil)
+	_ ChatStore            = (*Store)(nil)
+)
@@ -3,15 +3,28 @@ package store
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/taskrefs"
 	"github.com/google/uuid"
 )
 
 type Store struct {
 	db      *sql.DB
 	queries *db.Queries
+
+	eventSink func(db.Event)
+	txEvents  *[]db.Event // events created in a transaction, passed to eventSink once it commits
+
+	trashRetention time.Duration // how long deleted tasks, comments and projects are kept in the trash; 0 = not at all
 }
 
 func New(database *sql.DB) *Store {
@@ -28,9 +41,14 @@ func (s *Store) WithTx(ctx context.Context, fn func(*Store) error) error {
 		return err
 	}
 
+	var events []db.Event
 	txStore := &Store{
-		db:      s.db,
-		queries: db.New(tx),
+		db:        s.db,
+		queries:   db.New(tx),
+		eventSink: s.eventSink,
+		txEvents:  &events,
+
+		trashRetention: s.trashRetention,
 	}
 
 	if err := fn(txStore); err != nil {
@@ -38,7 +56,29 @@ func (s *Store) WithTx(ctx context.Context, fn func(*Store) error) error {
 		return err
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	for _, e := range events {
+		s.sinkEvent(e)
+	}
+	return nil
+}
+
+// SetEventSink sets a function every event is passed to once it is recorded,
+// e.g. to archive it outside SQLite. Events created in a transaction are
+// passed on when it commits.
+func (s *Store) SetEventSink(sink func(db.Event)) {
+	s.eventSink = sink
+}
+
+func (s *Store) sinkEvent(e db.Event) {
+	switch {
+	case s.txEvents != nil:
+		*s.txEvents = append(*s.txEvents, e)
+	case s.eventSink != nil:
+		s.eventSink(e)
+	}
 }
 
 // ============ Agents ============
@@ -58,6 +98,11 @@ func (s *Store) ListAgents(ctx context.Context) ([]db.Agent, error) {
 	return s.queries.ListAgents(ctx)
 }
 
+// ListAgentsByIDs returns the agents with the given IDs, in one query.
+func (s *Store) ListAgentsByIDs(ctx context.Context, ids []string) ([]db.Agent, error) {
+	return s.queries.ListAgentsByIDs(ctx, ids)
+}
+
 func (s *Store) UpdateAgent(ctx context.Context, params db.UpdateAgentParams) (db.Agent, error) {
 	return s.queries.UpdateAgent(ctx, params)
 }
@@ -66,6 +111,128 @@ func (s *Store) DeleteAgent(ctx context.Context, id string) error {
 	return s.queries.DeleteAgent(ctx, id)
 }
 
+// AgentStatusDeleting is the status of an agent being removed; see
+// BeginAgentDeletion.
+const AgentStatusDeleting = "deleting"
+
+// What BeginAgentDeletion does with the open tasks (neither done, failed nor
+// cancelled) of the agent it deactivates.
+const (
+	AgentTasksBlock    = "block"    // refuse with ErrAgentHasOpenTasks
+	AgentTasksBacklog  = "backlog"  // unassign them, back to the backlog
+	AgentTasksReassign = "reassign" // assign them to another agent
+)
+
+var (
+	// ErrAgentHasOpenTasks is returned for an agent with open tasks that are
+	// not to be moved.
+	ErrAgentHasOpenTasks = errors.New("agent has open tasks")
+	// ErrBadReassignTarget is returned for tasks to be reassigned to the
+	// agent itself or to one that does not exist or is being removed.
+	ErrBadReassignTarget = errors.New("tasks must be reassigned to another agent that is not being removed")
+)
+
+// AgentTaskStrategy is what is done with the open tasks of an agent being
+// removed.
+type AgentTaskStrategy struct {
+	Mode       string // AgentTasksBlock, AgentTasksBacklog or AgentTasksReassign
+	ReassignTo string // the agent taking the tasks, for AgentTasksReassign
+}
+
+// BeginAgentDeletion is the first phase of removing an agent: it deactivates
+// the agent (status AgentStatusDeleting), moves its open tasks as tasks says
+// and records the removal, so it can be finished with PurgeAgent once the
+// agent's workspace and config entry are gone, or retried. Beginning it again
+// updates purgeWorkspace. It returns the open tasks as they were before they
+// were moved; with ErrAgentHasOpenTasks nothing is changed.
+func (s *Store) BeginAgentDeletion(ctx context.Context, id string, purgeWorkspace bool, tasks AgentTaskStrategy) (db.AgentDeletion, []db.Task, error) {
+	var deletion db.AgentDeletion
+	var open []db.Task
+	err := s.WithTx(ctx, func(tx *Store) error {
+		agent, err := tx.queries.GetAgent(ctx, id)
+		if err != nil {
+			return err
+		}
+		agentID := sql.NullString{String: id, Valid: true}
+		if open, err = tx.queries.ListOpenTasksByAgent(ctx, agentID); err != nil {
+			return err
+		}
+		if len(open) > 0 {
+			switch tasks.Mode {
+			case AgentTasksBacklog:
+				err = tx.queries.UnassignOpenTasksByAgent(ctx, agentID)
+			case AgentTasksReassign:
+				target, targetErr := tx.queries.GetAgent(ctx, tasks.ReassignTo)
+				if targetErr != nil || target.ID == id || target.Status.String == AgentStatusDeleting {
+					return ErrBadReassignTarget
+				}
+				err = tx.queries.ReassignOpenTasksByAgent(ctx, db.ReassignOpenTasksByAgentParams{
+					NewAgentID: sql.NullString{String: target.ID, Valid: true},
+					AgentID:    agentID,
+				})
+			default:
+				return ErrAgentHasOpenTasks
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		previous := agent.Status
+		if existing, err := tx.queries.GetAgentDeletion(ctx, id); err == nil {
+			previous = existing.PreviousStatus
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		deletion, err = tx.queries.CreateAgentDeletion(ctx, db.CreateAgentDeletionParams{
+			AgentID:        id,
+			PurgeWorkspace: purgeWorkspace,
+			PreviousStatus: previous,
+		})
+		if err != nil {
+			return err
+		}
+		return tx.UpdateAgentStatus(ctx, id, AgentStatusDeleting)
+	})
+	return deletion, open, err
+}
+
+// GetAgentDeletion returns the pending removal of the agent.
+func (s *Store) GetAgentDeletion(ctx context.Context, id string) (db.AgentDeletion, error) {
+	return s.queries.GetAgentDeletion(ctx, id)
+}
+
+// ListAgentDeletions returns the pending agent removals, oldest first.
+func (s *Store) ListAgentDeletions(ctx context.Context) ([]db.AgentDeletion, error) {
+	return s.queries.ListAgentDeletions(ctx)
+}
+
+// RecordAgentDeletionFailure counts a failed attempt at removing the agent's
+// workspace and config entry; the agent stays deactivated.
+func (s *Store) RecordAgentDeletionFailure(ctx context.Context, id, errMsg string) error {
+	return s.queries.RecordAgentDeletionFailure(ctx, db.RecordAgentDeletionFailureParams{
+		LastError: sql.NullString{String: errMsg, Valid: errMsg != ""},
+		AgentID:   id,
+	})
+}
+
+// PurgeAgent is the last phase of removing an agent: it deletes the agent and
+// its pending removal together. The agent's events are kept, without it.
+func (s *Store) PurgeAgent(ctx context.Context, id string) error {
+	return s.WithTx(ctx, func(tx *Store) error {
+		if err := tx.queries.DetachAgentEvents(ctx, sql.NullString{String: id, Valid: true}); err != nil {
+			return err
+		}
+		if err := tx.queries.DeleteSubAgentsByOrchestrator(ctx, id); err != nil {
+			return err
+		}
+		if err := tx.queries.DeleteAgent(ctx, id); err != nil {
+			return err
+		}
+		return tx.queries.DeleteAgentDeletion(ctx, id)
+	})
+}
+
 func (s *Store) UpdateAgentStatus(ctx context.Context, id, status string) error {
 	return s.queries.UpdateAgentStatus(ctx, db.UpdateAgentStatusParams{
 		Status: sql.NullString{String: status, Valid: true},
@@ -73,19 +240,212 @@ func (s *Store) UpdateAgentStatus(ctx context.Context, id, status string) error
 	})
 }
 
+// UpdateAgentTimezone sets the agent's time zone ("" = none, times in UTC only).
+func (s *Store) UpdateAgentTimezone(ctx context.Context, id, timezone string) error {
+	return s.queries.UpdateAgentTimezone(ctx, db.UpdateAgentTimezoneParams{
+		Timezone: sql.NullString{String: timezone, Valid: timezone != ""},
+		ID:       id,
+	})
+}
+
+// UpdateAgentLocale sets the agent's notification locale ("" = server default).
+func (s *Store) UpdateAgentLocale(ctx context.Context, id, locale string) error {
+	return s.queries.UpdateAgentLocale(ctx, db.UpdateAgentLocaleParams{
+		Locale: sql.NullString{String: locale, Valid: locale != ""},
+		ID:     id,
+	})
+}
+
+// UpdateAgentMemory sets the agent's copy of its MEMORY.md.
+func (s *Store) UpdateAgentMemory(ctx context.Context, id, memory string) error {
+	return s.queries.UpdateAgentMemory(ctx, db.UpdateAgentMemoryParams{
+		MemoryMd: sql.NullString{String: memory, Valid: memory != ""},
+		ID:       id,
+	})
+}
+
+// UpdateAgentWorkingHours stores the agent's working hours as JSON
+// ("" = always available).
+func (s *Store) UpdateAgentWorkingHours(ctx context.Context, id, workingHours string) error {
+	return s.queries.UpdateAgentWorkingHours(ctx, db.UpdateAgentWorkingHoursParams{
+		WorkingHours: sql.NullString{String: workingHours, Valid: workingHours != ""},
+		ID:           id,
+	})
+}
+
+// UpdateAgentNotificationPrefs stores the agent's notification preferences
+// as JSON ("" = push everything).
+func (s *Store) UpdateAgentNotificationPrefs(ctx context.Context, id, prefs string) error {
+	return s.queries.UpdateAgentNotificationPrefs(ctx, db.UpdateAgentNotificationPrefsParams{
+		NotificationPrefs: sql.NullString{String: prefs, Valid: prefs != ""},
+		ID:                id,
+	})
+}
+
+// UpdateAgentNotificationTimeouts stores the agent's notification timeouts
+// as JSON (see package notifytimeout; "" = the system's).
+func (s *Store) UpdateAgentNotificationTimeouts(ctx context.Context, id, timeouts string) error {
+	return s.queries.UpdateAgentNotificationTimeouts(ctx, db.UpdateAgentNotificationTimeoutsParams{
+		NotificationTimeouts: sql.NullString{String: timeouts, Valid: timeouts != ""},
+		ID:                   id,
+	})
+}
+
+// UpdateAgentPublicAPIURL sets the base URL of the API as the agent reaches
+// it ("" = the server's PUBLIC_API_URL).
+func (s *Store) UpdateAgentPublicAPIURL(ctx context.Context, id, url string) error {
+	return s.queries.UpdateAgentPublicAPIURL(ctx, db.UpdateAgentPublicAPIURLParams{
+		PublicAPIURL: sql.NullString{String: url, Valid: url != ""},
+		ID:           id,
+	})
+}
+
+// UpdateAgentDelivery sets how notifications reach the agent, with the
+// callback URL and signing secret used by http_callback ("" = none, and a
+// method of "" means the CLI).
+func (s *Store) UpdateAgentDelivery(ctx context.Context, id, method, callbackURL, secret string) error {
+	return s.queries.UpdateAgentDelivery(ctx, db.UpdateAgentDeliveryParams{
+		DeliveryMethod: sql.NullString{String: method, Valid: method != ""},
+		CallbackURL:    sql.NullString{String: callbackURL, Valid: callbackURL != ""},
+		CallbackSecret: sql.NullString{String: secret, Valid: secret != ""},
+		ID:             id,
+	})
+}
+
+// SetAgentGateway sets the gateway the agent lives on ("" = the default).
+func (s *Store) SetAgentGateway(ctx context.Context, id, gatewayID string) error {
+	return s.queries.SetAgentGateway(ctx, db.SetAgentGatewayParams{
+		GatewayID: sql.NullString{String: gatewayID, Valid: gatewayID != ""},
+		ID:        id,
+	})
+}
+
+// ErrAgentManagedByConfig is returned by RegisterExternalAgent when the ID
+// belongs to an agent synced from the OpenClaw config.
+var ErrAgentManagedByConfig = errors.New("agent is managed by the OpenClaw config")
+
+// RegisterExternalAgent creates a self-registered agent, or refreshes the
+// name, description, model and callback URL of one that registered before,
+// then, if given, runs then on the agent, all in one transaction, so that a
+// registration is applied in full or not at all. created reports whether
+// the agent is new; the agent is returned as then left it.
+func (s *Store) RegisterExternalAgent(ctx context.Context, params db.CreateAgentParams, callbackURL string, then func(tx *Store, agent db.Agent) error) (agent db.Agent, created bool, err error) {
+	err = s.WithTx(ctx, func(tx *Store) error {
+		existing, err := tx.queries.GetAgent(ctx, params.ID)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			created = true
+			if _, err := tx.queries.CreateAgent(ctx, params); err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		case !existing.ManagedExternally:
+			return ErrAgentManagedByConfig
+		default:
+			if _, err := tx.queries.UpdateAgent(ctx, db.UpdateAgentParams{
+				ID:               existing.ID,
+				Name:             params.Name,
+				Description:      params.Description,
+				Status:           existing.Status,
+				Model:            params.Model,
+				MentionPatterns:  existing.MentionPatterns,
+				SoulMd:           existing.SoulMd,
+				AgentsMd:         existing.AgentsMd,
+				IdentityMd:       existing.IdentityMd,
+				UserMd:           existing.UserMd,
+				ToolsMd:          existing.ToolsMd,
+				HeartbeatMd:      existing.HeartbeatMd,
+				ActiveSessionKey: existing.ActiveSessionKey,
+				CurrentTaskID:    existing.CurrentTaskID,
+			}); err != nil {
+				return err
+			}
+		}
+		if err := tx.queries.SetAgentExternal(ctx, db.SetAgentExternalParams{
+			CallbackURL: sql.NullString{String: callbackURL, Valid: callbackURL != ""},
+			ID:          params.ID,
+		}); err != nil {
+			return err
+		}
+		if agent, err = tx.queries.GetAgent(ctx, params.ID); err != nil || then == nil {
+			return err
+		}
+		if err := then(tx, agent); err != nil {
+			return err
+		}
+		agent, err = tx.queries.GetAgent(ctx, params.ID)
+		return err
+	})
+	return agent, created, err
+}
+
 // ============ Tasks ============
 
+// CreateTask creates a task, numbering it with the next short ID of its
+// project in the same transaction.
 func (s *Store) CreateTask(ctx context.Context, params db.CreateTaskParams) (db.Task, error) {
+	return s.CreateTaskWith(ctx, params, nil)
+}
+
+// CreateTaskWith is CreateTask, running then, if given, on the new task in
+// the same transaction: to set what the insert leaves out, or record where
+// the task came from, so that the task is created in full or not at all.
+// It returns the task as then left it.
+func (s *Store) CreateTaskWith(ctx context.Context, params db.CreateTaskParams, then func(tx *Store, task db.Task) error) (db.Task, error) {
 	if params.ID == "" {
 		params.ID = uuid.New().String()
 	}
-	return s.queries.CreateTask(ctx, params)
+	var task db.Task
+	err := s.WithTx(ctx, func(tx *Store) error {
+		if err := tx.assignShortID(ctx, &params); err != nil {
+			return err
+		}
+		var err error
+		if task, err = tx.queries.CreateTask(ctx, params); err != nil || then == nil {
+			return err
+		}
+		if err := then(tx, task); err != nil {
+			return err
+		}
+		task, err = tx.queries.GetTask(ctx, task.ID)
+		return err
+	})
+	return task, err
+}
+
+// assignShortID sets params.ShortID to the next number under the key of the
+// task's project (taskrefs.DefaultPrefix when there is no project or key).
+// Call it in the transaction that creates the task, so a failed insert does
+// not use up a number.
+func (s *Store) assignShortID(ctx context.Context, params *db.CreateTaskParams) error {
+	prefix := taskrefs.DefaultPrefix
+	if params.ProjectID.Valid {
+		project, err := s.queries.GetProject(ctx, params.ProjectID.String)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		if project.Key.Valid && project.Key.String != "" {
+			prefix = project.Key.String
+		}
+	}
+	n, err := s.queries.NextTaskNumber(ctx, prefix)
+	if err != nil {
+		return err
+	}
+	params.ShortID = sql.NullString{String: taskrefs.FormatShortID(prefix, n), Valid: true}
+	return nil
 }
 
 func (s *Store) GetTask(ctx context.Context, id string) (db.Task, error) {
 	return s.queries.GetTask(ctx, id)
 }
 
+// GetTaskByShortID returns the task with the given short ID, in any case.
+func (s *Store) GetTaskByShortID(ctx context.Context, shortID string) (db.Task, error) {
+	return s.queries.GetTaskByShortID(ctx, sql.NullString{String: taskrefs.NormalizeShortID(shortID), Valid: true})
+}
+
 func (s *Store) ListTasks(ctx context.Context) ([]db.Task, error) {
 	return s.queries.ListTasks(ctx)
 }
@@ -98,29 +458,97 @@ func (s *Store) ListTasksByAgent(ctx context.Context, agentID string) ([]db.Task
 	return s.queries.ListTasksByAgent(ctx, sql.NullString{String: agentID, Valid: true})
 }
 
+// ListTasksByIDs returns the tasks with the given IDs, in one query.
+func (s *Store) ListTasksByIDs(ctx context.Context, ids []string) ([]db.Task, error) {
+	return s.queries.ListTasksByIDs(ctx, ids)
+}
+
+// ListTasksByParentIDs returns the subtasks of the given tasks, in one query.
+func (s *Store) ListTasksByParentIDs(ctx context.Context, parentIDs []string) ([]db.Task, error) {
+	return s.queries.ListTasksByParentIDs(ctx, nullStrings(parentIDs))
+}
+
+// ListTasksByAgentIDs returns the tasks of the given agents, in one query.
+func (s *Store) ListTasksByAgentIDs(ctx context.Context, agentIDs []string) ([]db.Task, error) {
+	return s.queries.ListTasksByAgentIDs(ctx, nullStrings(agentIDs))
+}
+
 func (s *Store) UpdateTask(ctx context.Context, params db.UpdateTaskParams) (db.Task, error) {
-	return s.queries.UpdateTask(ctx, params)
+	task, err := s.queries.UpdateTask(ctx, params)
+	if err != nil {
+		return task, err
+	}
+	if err := s.queries.StampTaskTimes(ctx, task.ID); err != nil {
+		return task, err
+	}
+	return s.queries.GetTask(ctx, task.ID)
 }
 
+// UpdateTaskStatus sets a task's status. started_at is set the first time
+// it leaves backlog/queued, and completed_at while it is done, failed or
+// cancelled.
 func (s *Store) UpdateTaskStatus(ctx context.Context, id, status string) error {
-	return s.queries.UpdateTaskStatus(ctx, db.UpdateTaskStatusParams{
+	if err := s.queries.UpdateTaskStatus(ctx, db.UpdateTaskStatusParams{
 		Status: sql.NullString{String: status, Valid: true},
 		ID:     id,
-	})
+	}); err != nil {
+		return err
+	}
+	return s.queries.StampTaskTimes(ctx, id)
 }
 
+// DeleteTask deletes a task with what belongs to it, into the trash if
+// there is one (see SetTrashRetention).
 func (s *Store) DeleteTask(ctx context.Context, id string) error {
-	return s.queries.DeleteTask(ctx, id)
+	if s.trashRetention <= 0 {
+		return s.queries.DeleteTask(ctx, id)
+	}
+	return s.WithTx(ctx, func(tx *Store) error {
+		task, err := tx.queries.GetTask(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		snap, err := tx.queries.DeleteWithSnapshot(ctx, "tasks", "id", id)
+		if err != nil {
+			return err
+		}
+		return tx.trash(ctx, TrashTask, id, task.Title, snap)
+	})
 }
 
 func (s *Store) ListQueuedTasksByAgent(ctx context.Context, agentID string) ([]db.Task, error) {
 	return s.queries.ListQueuedTasksByAgent(ctx, sql.NullString{String: agentID, Valid: true})
 }
 
+// CountOpenTasksByAgent counts the agent's tasks that are not finished
+// (anything but done, failed and cancelled). Used as load for dispatch.
+func (s *Store) CountOpenTasksByAgent(ctx context.Context, agentID string) (int64, error) {
+	return s.queries.CountOpenTasksByAgent(ctx, sql.NullString{String: agentID, Valid: true})
+}
+
 func (s *Store) CountActiveTasksByAgent(ctx context.Context, agentID string) (int64, error) {
 	return s.queries.CountActiveTasksByAgent(ctx, sql.NullString{String: agentID, Valid: true})
 }
 
+// CountQueuedTasksByAgent returns the depth of an agent's own queue.
+func (s *Store) CountQueuedTasksByAgent(ctx context.Context, agentID string) (int64, error) {
+	return s.queries.CountQueuedTasksByAgent(ctx, sql.NullString{String: agentID, Valid: true})
+}
+
+// CountQueuedTasksByGroup returns the depth of a group's shared queue.
+func (s *Store) CountQueuedTasksByGroup(ctx context.Context, groupID string) (int64, error) {
+	return s.queries.CountQueuedTasksByGroup(ctx, sql.NullString{String: groupID, Valid: true})
+}
+
+// CountQueuedGroupTasksForAgent returns how many unclaimed tasks wait in the
+// shared queues of the agent's groups.
+func (s *Store) CountQueuedGroupTasksForAgent(ctx context.Context, agentID string) (int64, error) {
+	return s.queries.CountQueuedGroupTasksForAgent(ctx, agentID)
+}
+
 // ListStaleTasks returns tasks in active status (executing, planning, discussing, verifying)
 // whose updated_at is older than the given cutoff (or NULL). Used by the stuck-task watchdog.
 func (s *Store) ListStaleTasks(ctx context.Context, cutoff time.Time) ([]db.Task, error) {
@@ -134,7 +562,10 @@ func (s *Store) IncrementTaskRetryCount(ctx context.Context, taskID string) erro
 
 // ResetStuckTask sets status to backlog, clears agent_id and retry_count (watchdog after max retries).
 func (s *Store) ResetStuckTask(ctx context.Context, taskID string) error {
-	return s.queries.ResetStuckTask(ctx, taskID)
+	if err := s.queries.ResetStuckTask(ctx, taskID); err != nil {
+		return err
+	}
+	return s.queries.StampTaskTimes(ctx, taskID)
 }
 
 // ResetTaskRetryCount clears retry_count for a task (on normal status transition).
@@ -142,12 +573,10 @@ func (s *Store) ResetTaskRetryCount(ctx context.Context, taskID string) error {
 	return s.queries.ResetTaskRetryCount(ctx, taskID)
 }
 
-// AppendProgressTxt appends content to a task's progress_txt and sets updated_at to now.
+// AppendProgressTxt records content as a progress entry with no author.
 func (s *Store) AppendProgressTxt(ctx context.Context, taskID, content string) error {
-	return s.queries.AppendProgressTxt(ctx, db.AppendProgressTxtParams{
-		ProgressTxt: sql.NullString{String: content, Valid: true},
-		ID:          taskID,
-	})
+	_, err := s.AddProgressEntry(ctx, taskID, "", content)
+	return err
 }
 
 // ============ Phases ============
@@ -159,6 +588,16 @@ func (s *Store) CreatePhase(ctx context.Context, params db.CreatePhaseParams) (d
 	return s.queries.CreatePhase(ctx, params)
 }
 
+// AppendPhase creates a phase numbered after the task's last one. The
+// sequence is taken in the same statement as the insert, so phases created
+// at once don't share one.
+func (s *Store) AppendPhase(ctx context.Context, params db.AppendPhaseParams) (db.Phase, error) {
+	if params.ID == "" {
+		params.ID = uuid.New().String()
+	}
+	return s.queries.AppendPhase(ctx, params)
+}
+
 func (s *Store) GetPhase(ctx context.Context, id string) (db.Phase, error) {
 	return s.queries.GetPhase(ctx, id)
 }
@@ -191,10 +630,26 @@ func (s *Store) CreateStory(ctx context.Context, params db.CreateStoryParams) (d
 	return s.queries.CreateStory(ctx, params)
 }
 
+// AppendStory creates a story numbered after the task's last one, as
+// AppendPhase does for phases.
+func (s *Store) AppendStory(ctx context.Context, params db.AppendStoryParams) (db.Story, error) {
+	if params.ID == "" {
+		params.ID = uuid.New().String()
+	}
+	return s.queries.AppendStory(ctx, params)
+}
+
 func (s *Store) GetStory(ctx context.Context, id string) (db.Story, error) {
 	return s.queries.GetStory(ctx, id)
 }
 
+// ListFailedStoriesSince returns the stories that last failed at or after
+// since, with the error they failed with, oldest first.
+func (s *Store) ListFailedStoriesSince(ctx context.Context, since time.Time) ([]db.Story, error) {
+	// as CURRENT_TIMESTAMP stores updated_at
+	return s.queries.ListFailedStoriesSince(ctx, since.UTC().Format("2006-01-02 15:04:05"))
+}
+
 func (s *Store) ListStoriesByTask(ctx context.Context, taskID string) ([]db.Story, error) {
 	return s.queries.ListStoriesByTask(ctx, taskID)
 }
@@ -267,7 +722,12 @@ func (s *Store) CreateEvent(ctx context.Context, params db.CreateEventParams) (d
 	if params.ID == "" {
 		params.ID = uuid.New().String()
 	}
-	return s.queries.CreateEvent(ctx, params)
+	event, err := s.queries.CreateEvent(ctx, params)
+	if err != nil {
+		return event, err
+	}
+	s.sinkEvent(event)
+	return event, nil
 }
 
 func (s *Store) ListEvents(ctx context.Context, limit int64) ([]db.Event, error) {
@@ -281,6 +741,12 @@ func (s *Store) ListEventsByTask(ctx context.Context, taskID string, limit int64
 	})
 }
 
+// ListEventsByTaskIDs returns the events of the given tasks, newest first, in
+// one query.
+func (s *Store) ListEventsByTaskIDs(ctx context.Context, taskIDs []string) ([]db.Event, error) {
+	return s.queries.ListEventsByTaskIDs(ctx, nullStrings(taskIDs))
+}
+
 func (s *Store) ListEventsByAgent(ctx context.Context, agentID string, limit int64) ([]db.Event, error) {
 	return s.queries.ListEventsByAgent(ctx, db.ListEventsByAgentParams{
 		AgentID: sql.NullString{String: agentID, Valid: true},
@@ -288,6 +754,82 @@ func (s *Store) ListEventsByAgent(ctx context.Context, agentID string, limit int
 	})
 }
 
+// CountEventsByAgent counts events of eventType per agent since since.
+func (s *Store) CountEventsByAgent(ctx context.Context, eventType string, since time.Time) ([]db.CountEventsByAgentRow, error) {
+	return s.queries.CountEventsByAgent(ctx, db.CountEventsByAgentParams{
+		Type:  eventType,
+		Since: sql.NullTime{Time: since.UTC(), Valid: true},
+	})
+}
+
+// ListEventsAfter returns up to limit events recorded after the one numbered
+// seq, oldest first; event numbers only grow, so seq works as a cursor.
+func (s *Store) ListEventsAfter(ctx context.Context, seq, limit int64) ([]db.ListEventsAfterRow, error) {
+	return s.queries.ListEventsAfter(ctx, db.ListEventsAfterParams{Seq: seq, Limit: limit})
+}
+
+// EventFilter selects events for ListEventsFiltered. Zero fields don't
+// filter.
+type EventFilter struct {
+	TaskID  string
+	AgentID string
+	Types   []string  // any of these types
+	Since   time.Time // recorded at or after
+	After   int64     // numbered above this (see ListEventsAfter)
+	Before  int64     // numbered below this
+	Limit   int64
+	// OldestFirst returns the events after a cursor in the order they were
+	// recorded, for polling; otherwise newest first, for scrolling back
+	OldestFirst bool
+}
+
+// ListEventsFiltered returns the events matching f with their numbers.
+func (s *Store) ListEventsFiltered(ctx context.Context, f EventFilter) ([]db.ListEventsAfterRow, error) {
+	before := f.Before
+	if before <= 0 {
+		before = math.MaxInt64
+	}
+	since := ""
+	if !f.Since.IsZero() {
+		// as CURRENT_TIMESTAMP stores created_at
+		since = f.Since.UTC().Format("2006-01-02 15:04:05")
+	}
+	params := db.ListEventsFilteredDescParams{
+		After:   f.After,
+		Before:  before,
+		TaskID:  f.TaskID,
+		AgentID: f.AgentID,
+		Types:   strings.Join(f.Types, ","),
+		Since:   since,
+		Limit:   f.Limit,
+	}
+	if f.OldestFirst {
+		rows, err := s.queries.ListEventsFilteredAsc(ctx, db.ListEventsFilteredAscParams(params))
+		if err != nil {
+			return nil, err
+		}
+		events := make([]db.ListEventsAfterRow, len(rows))
+		for i, r := range rows {
+			events[i] = db.ListEventsAfterRow(r)
+		}
+		return events, nil
+	}
+	rows, err := s.queries.ListEventsFilteredDesc(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]db.ListEventsAfterRow, len(rows))
+	for i, r := range rows {
+		events[i] = db.ListEventsAfterRow(r)
+	}
+	return events, nil
+}
+
+// GetLatestEventSeq returns the number of the latest event, 0 if there are none.
+func (s *Store) GetLatestEventSeq(ctx context.Context) (int64, error) {
+	return s.queries.GetLatestEventSeq(ctx)
+}
+
 // ============ Settings ============
 
 func (s *Store) GetSettings(ctx context.Context) (db.Setting, error) {
@@ -298,140 +840,1730 @@ func (s *Store) UpdateSettings(ctx context.Context, params db.UpdateSettingsPara
 	return s.queries.UpdateSettings(ctx, params)
 }
 
-// ============ Projects ============
+// SetModelRouting stores the model routing policy (JSON, see package
+// routing); "" removes it.
+func (s *Store) SetModelRouting(ctx context.Context, policy string) error {
+	return s.queries.SetModelRouting(ctx, sql.NullString{String: policy, Valid: policy != ""})
+}
 
-func (s *Store) CreateProject(ctx context.Context, params db.CreateProjectParams) (db.Project, error) {
-	if params.ID == "" {
-		params.ID = uuid.New().String()
-	}
-	return s.queries.CreateProject(ctx, params)
+// SetQuietHours stores the system-wide quiet hours (JSON, see package
+// quiethours); "" removes them.
+func (s *Store) SetQuietHours(ctx context.Context, policy string) error {
+	return s.queries.SetQuietHours(ctx, sql.NullString{String: policy, Valid: policy != ""})
 }
 
-func (s *Store) GetProject(ctx context.Context, id string) (db.Project, error) {
-	return s.queries.GetProject(ctx, id)
+// SetNotificationTimeouts stores the system-wide notification timeouts
+// (JSON, see package notifytimeout); "" removes them.
+func (s *Store) SetNotificationTimeouts(ctx context.Context, timeouts string) error {
+	return s.queries.SetNotificationTimeouts(ctx, sql.NullString{String: timeouts, Valid: timeouts != ""})
 }
 
-func (s *Store) ListProjects(ctx context.Context) ([]db.Project, error) {
-	return s.queries.ListProjects(ctx)
+// SetTaskScripts stores the scripts run on new tasks (JSON, see package
+// taskscript); "" removes them.
+func (s *Store) SetTaskScripts(ctx context.Context, scripts string) error {
+	return s.queries.SetTaskScripts(ctx, sql.NullString{String: scripts, Valid: scripts != ""})
 }
 
-func (s *Store) ListProjectsByStatus(ctx context.Context, status sql.NullString) ([]db.Project, error) {
-	return s.queries.ListProjectsByStatus(ctx, status)
+// SetMaintenance stores the maintenance mode: on since since, for reason; a
+// zero since turns it off.
+func (s *Store) SetMaintenance(ctx context.Context, since time.Time, reason string) error {
+	return s.queries.SetMaintenance(ctx, db.SetMaintenanceParams{
+		MaintenanceSince:  sql.NullTime{Time: since, Valid: !since.IsZero()},
+		MaintenanceReason: sql.NullString{String: reason, Valid: !since.IsZero() && reason != ""},
+	})
 }
 
-func (s *Store) UpdateProject(ctx context.Context, params db.UpdateProjectParams) (db.Project, error) {
-	return s.queries.UpdateProject(ctx, params)
+// SetStorage stores the object storage config (JSON, see package
+// objectstore); "" reverts to local storage.
+func (s *Store) SetStorage(ctx context.Context, cfg string) error {
+	return s.queries.SetStorage(ctx, sql.NullString{String: cfg, Valid: cfg != ""})
 }
 
-func (s *Store) DeleteProject(ctx context.Context, id string) error {
-	return s.queries.DeleteProject(ctx, id)
+// SetAnalyticsExport records an analytics export that finished at at, with
+// events up to seq.
+func (s *Store) SetAnalyticsExport(ctx context.Context, seq int64, at time.Time) error {
+	return s.queries.SetAnalyticsExport(ctx, db.SetAnalyticsExportParams{
+		AnalyticsExportSeq:  seq,
+		AnalyticsExportedAt: sql.NullTime{Time: at, Valid: true},
+	})
 }
 
-func (s *Store) GetProjectTaskCount(ctx context.Context, projectID sql.NullString) (int64, error) {
-	return s.queries.GetProjectTaskCount(ctx, projectID)
+// ============ Backups ============
+
+// Backup writes a consistent copy of the database to path, which must not
+// exist yet.
+func (s *Store) Backup(ctx context.Context, path string) error {
+	_, err := s.db.ExecContext(ctx, "VACUUM INTO ?", path)
+	return err
 }
 
-func (s *Store) GetProjectDoneTaskCount(ctx context.Context, projectID sql.NullString) (int64, error) {
-	return s.queries.GetProjectDoneTaskCount(ctx, projectID)
+// ============ Database Maintenance ============
+
+// DBStats is the size of the database and of its write-ahead log.
+type DBStats struct {
+	PageSize  int64 `json:"page_size"`
+	Pages     int64 `json:"pages"`
+	FreePages int64 `json:"free_pages"` // unused pages a vacuum gives back
+	SizeBytes int64 `json:"size_bytes"`
+	FreeBytes int64 `json:"free_bytes"`
+	WALBytes  int64 `json:"wal_bytes"` // 0 when there is no -wal file
 }
 
-func (s *Store) ListTasksByProject(ctx context.Context, projectID sql.NullString) ([]db.Task, error) {
-	return s.queries.ListTasksByProject(ctx, projectID)
+// DBStats returns the size of the database now.
+func (s *Store) DBStats(ctx context.Context) (DBStats, error) {
+	var st DBStats
+	for _, p := range []struct {
+		pragma string
+		dest   *int64
+	}{{"page_size", &st.PageSize}, {"page_count", &st.Pages}, {"freelist_count", &st.FreePages}} {
+		if err := s.db.QueryRowContext(ctx, "PRAGMA "+p.pragma).Scan(p.dest); err != nil {
+			return st, fmt.Errorf("%s: %w", p.pragma, err)
+		}
+	}
+	st.SizeBytes, st.FreeBytes = st.Pages*st.PageSize, st.FreePages*st.PageSize
+
+	var seq int
+	var name, file string
+	if err := s.db.QueryRowContext(ctx, "PRAGMA database_list").Scan(&seq, &name, &file); err != nil {
+		return st, fmt.Errorf("database_list: %w", err)
+	}
+	if file != "" {
+		if info, err := os.Stat(file + "-wal"); err == nil {
+			st.WALBytes = info.Size()
+		}
+	}
+	return st, nil
+}
+
+// OptimizeResult is what Optimize did.
+type OptimizeResult struct {
+	// Vacuum is "incremental", or "none" for a database not set up for
+	// incremental auto-vacuum (see db.EnableIncrementalVacuum)
+	Vacuum string `json:"vacuum"`
+	// CheckpointBusy is set when readers kept the write-ahead log from being
+	// emptied; it is truncated by a later checkpoint
+	CheckpointBusy bool `json:"checkpoint_busy"`
+}
+
+// Optimize refreshes the query planner's statistics (ANALYZE), gives free
+// pages back to the file system and empties the write-ahead log into the
+// database (wal_checkpoint(TRUNCATE)). Free pages are given back by an
+// incremental vacuum, which needs the database switched to incremental
+// auto-vacuum at startup; Optimize never rewrites the whole file.
+func (s *Store) Optimize(ctx context.Context) (OptimizeResult, error) {
+	var res OptimizeResult
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return res, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "ANALYZE"); err != nil {
+		return res, fmt.Errorf("analyze: %w", err)
+	}
+	var autoVacuum int
+	if err := conn.QueryRowContext(ctx, "PRAGMA auto_vacuum").Scan(&autoVacuum); err != nil {
+		return res, fmt.Errorf("auto_vacuum: %w", err)
+	}
+	res.Vacuum = "none"
+	if autoVacuum == 2 { // incremental
+		res.Vacuum = "incremental"
+		if _, err := conn.ExecContext(ctx, "PRAGMA incremental_vacuum"); err != nil {
+			return res, fmt.Errorf("incremental vacuum: %w", err)
+		}
+	}
+	var busy, logPages, checkpointed int
+	if err := conn.QueryRowContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)").Scan(&busy, &logPages, &checkpointed); err != nil {
+		return res, fmt.Errorf("wal checkpoint: %w", err)
+	}
+	res.CheckpointBusy = busy != 0
+	return res, nil
 }
 
-// ============ Comments ============
+// ============ Leases ============
 
-func (s *Store) CreateComment(ctx context.Context, params db.CreateCommentParams) (db.Comment, error) {
+// AcquireLease takes or renews the named lease for holder until expiresAt,
+// and reports whether holder has it: it fails while another holder's lease
+// has not expired.
+func (s *Store) AcquireLease(ctx context.Context, name, holder string, expiresAt time.Time) (bool, error) {
+	n, err := s.queries.AcquireLease(ctx, db.AcquireLeaseParams{
+		Name:      name,
+		Holder:    holder,
+		ExpiresAt: expiresAt.UTC(),
+		Now:       time.Now().UTC(),
+	})
+	return n > 0, err
+}
+
+func (s *Store) GetLease(ctx context.Context, name string) (db.Lease, error) {
+	return s.queries.GetLease(ctx, name)
+}
+
+// ReleaseLease gives up holder's lease, if it still has it.
+func (s *Store) ReleaseLease(ctx context.Context, name, holder string) error {
+	return s.queries.ReleaseLease(ctx, db.ReleaseLeaseParams{Name: name, Holder: holder})
+}
+
+// ============ Project Secrets ============
+
+// ListProjectSecrets returns the project's secrets, values still sealed.
+func (s *Store) ListProjectSecrets(ctx context.Context, projectID string) ([]db.ProjectSecret, error) {
+	return s.queries.ListProjectSecrets(ctx, projectID)
+}
+
+func (s *Store) GetProjectSecret(ctx context.Context, projectID, name string) (db.ProjectSecret, error) {
+	return s.queries.GetProjectSecret(ctx, db.GetProjectSecretParams{ProjectID: projectID, Name: name})
+}
+
+// SetProjectSecret stores a sealed secret value, replacing any value the
+// project had under the same name.
+func (s *Store) SetProjectSecret(ctx context.Context, projectID, name, sealedValue string) (db.ProjectSecret, error) {
+	return s.queries.UpsertProjectSecret(ctx, db.UpsertProjectSecretParams{
+		ProjectID:   projectID,
+		Name:        name,
+		SealedValue: sealedValue,
+	})
+}
+
+// DeleteProjectSecret removes a secret, reporting whether it existed.
+func (s *Store) DeleteProjectSecret(ctx context.Context, projectID, name string) (bool, error) {
+	n, err := s.queries.DeleteProjectSecret(ctx, db.DeleteProjectSecretParams{ProjectID: projectID, Name: name})
+	return n > 0, err
+}
+
+// ============ Gateways ============
+
+func (s *Store) CreateGateway(ctx context.Context, params db.CreateGatewayParams) (db.Gateway, error) {
 	if params.ID == "" {
 		params.ID = uuid.New().String()
 	}
-	return s.queries.CreateComment(ctx, params)
+	return s.queries.CreateGateway(ctx, params)
 }
 
-func (s *Store) GetComment(ctx context.Context, id string) (db.Comment, error) {
-	return s.queries.GetComment(ctx, id)
+func (s *Store) GetGateway(ctx context.Context, id string) (db.Gateway, error) {
+	return s.queries.GetGateway(ctx, id)
 }
 
-func (s *Store) ListCommentsByTask(ctx context.Context, taskID string) ([]db.Comment, error) {
-	return s.queries.ListCommentsByTask(ctx, taskID)
+func (s *Store) ListGateways(ctx context.Context) ([]db.Gateway, error) {
+	return s.queries.ListGateways(ctx)
 }
 
-func (s *Store) DeleteComment(ctx context.Context, id string) error {
-	return s.queries.DeleteComment(ctx, id)
+func (s *Store) UpdateGateway(ctx context.Context, params db.UpdateGatewayParams) (db.Gateway, error) {
+	return s.queries.UpdateGateway(ctx, params)
 }
 
-// ============ Chat Sessions ============
+// DeleteGateway removes a gateway; its agents fall back to the default one.
+func (s *Store) DeleteGateway(ctx context.Context, id string) error {
+	return s.queries.DeleteGateway(ctx, id)
+}
 
-func (s *Store) CreateChatSession(ctx context.Context, params db.CreateChatSessionParams) (db.ChatSession, error) {
+// ============ Experiments ============
+
+func (s *Store) CreateExperiment(ctx context.Context, params db.CreateExperimentParams) (db.Experiment, error) {
 	if params.ID == "" {
 		params.ID = uuid.New().String()
 	}
-	return s.queries.CreateChatSession(ctx, params)
+	return s.queries.CreateExperiment(ctx, params)
 }
 
-func (s *Store) GetChatSession(ctx context.Context, id string) (db.ChatSession, error) {
-	return s.queries.GetChatSession(ctx, id)
+func (s *Store) GetExperiment(ctx context.Context, id string) (db.Experiment, error) {
+	return s.queries.GetExperiment(ctx, id)
 }
 
-func (s *Store) ListChatSessionsByAgent(ctx context.Context, agentID string) ([]db.ChatSession, error) {
-	return s.queries.ListChatSessionsByAgent(ctx, agentID)
+func (s *Store) ListExperiments(ctx context.Context) ([]db.Experiment, error) {
+	return s.queries.ListExperiments(ctx)
 }
 
-func (s *Store) EndChatSession(ctx context.Context, id string) error {
-	return s.queries.EndChatSession(ctx, id)
+// ListActiveExperiments returns the experiments still enrolling tasks, oldest
+// first.
+func (s *Store) ListActiveExperiments(ctx context.Context) ([]db.Experiment, error) {
+	return s.queries.ListActiveExperiments(ctx)
 }
 
-func (s *Store) UpdateMessageCount(ctx context.Context, id string) error {
-	return s.queries.UpdateMessageCount(ctx, id)
+func (s *Store) UpdateExperiment(ctx context.Context, params db.UpdateExperimentParams) (db.Experiment, error) {
+	return s.queries.UpdateExperiment(ctx, params)
 }
 
-// ============ Chat Messages ============
+// DeleteExperiment removes an experiment and the record of its arms; its
+// tasks are kept.
+func (s *Store) DeleteExperiment(ctx context.Context, id string) error {
+	return s.queries.DeleteExperiment(ctx, id)
+}
 
-func (s *Store) CreateChatMessage(ctx context.Context, params db.CreateChatMessageParams) (db.ChatMessage, error) {
+// AddExperimentTask records the arm of experimentID taskID was assigned to. A
+// task takes part in one experiment at most; later additions are ignored.
+func (s *Store) AddExperimentTask(ctx context.Context, taskID, experimentID, arm string) error {
+	return s.queries.AddExperimentTask(ctx, db.AddExperimentTaskParams{
+		TaskID:       taskID,
+		ExperimentID: experimentID,
+		Arm:          arm,
+	})
+}
+
+// GetExperimentTask returns the experiment arm taskID was assigned to.
+func (s *Store) GetExperimentTask(ctx context.Context, taskID string) (db.ExperimentTask, error) {
+	return s.queries.GetExperimentTask(ctx, taskID)
+}
+
+// ListExperimentOutcomes returns the arm and current status and timings of
+// every task taking part in experimentID.
+func (s *Store) ListExperimentOutcomes(ctx context.Context, experimentID string) ([]db.ListExperimentOutcomesRow, error) {
+	return s.queries.ListExperimentOutcomes(ctx, experimentID)
+}
+
+// ============ Change Requests ============
+
+func (s *Store) CreateChangeRequest(ctx context.Context, params db.CreateChangeRequestParams) (db.ChangeRequest, error) {
 	if params.ID == "" {
 		params.ID = uuid.New().String()
 	}
-	return s.queries.CreateChatMessage(ctx, params)
+	return s.queries.CreateChangeRequest(ctx, params)
 }
 
-func (s *Store) ListMessagesBySession(ctx context.Context, sessionID string) ([]db.ChatMessage, error) {
-	return s.queries.ListMessagesBySession(ctx, sessionID)
+func (s *Store) GetChangeRequest(ctx context.Context, id string) (db.ChangeRequest, error) {
+	return s.queries.GetChangeRequest(ctx, id)
 }
 
-// ============ Task Dependencies ============
-
-func (s *Store) ListSubtasks(ctx context.Context, parentTaskID sql.NullString) ([]db.Task, error) {
-	return s.queries.ListSubtasks(ctx, parentTaskID)
+// ListChangeRequestsByTask returns the change requests of taskID, oldest
+// first.
+func (s *Store) ListChangeRequestsByTask(ctx context.Context, taskID string) ([]db.ChangeRequest, error) {
+	return s.queries.ListChangeRequestsByTask(ctx, taskID)
 }
 
-// ============ Task Scheduling ============
-
-func (s *Store) SetTaskScheduledAt(ctx context.Context, id string, t time.Time) error {
-	return s.queries.SetTaskScheduledAt(ctx, db.SetTaskScheduledAtParams{
-		ScheduledAt: sql.NullTime{Time: t, Valid: true},
+// ResolveChangeRequest marks a change request addressed by addressedBy,
+// optionally in commit and with a note on how ("" for none).
+func (s *Store) ResolveChangeRequest(ctx context.Context, id, commit, resolution, addressedBy string) (db.ChangeRequest, error) {
+	return s.queries.ResolveChangeRequest(ctx, db.ResolveChangeRequestParams{
+		CommitSha:   sql.NullString{String: commit, Valid: commit != ""},
+		Resolution:  sql.NullString{String: resolution, Valid: resolution != ""},
+		AddressedBy: sql.NullString{String: addressedBy, Valid: addressedBy != ""},
 		ID:          id,
 	})
 }
 
-func (s *Store) SetTaskRetryAt(ctx context.Context, id string, t time.Time) error {
-	return s.queries.SetTaskRetryAt(ctx, db.SetTaskRetryAtParams{
-		RetryAt: sql.NullTime{Time: t, Valid: true},
-		ID:      id,
+// CountOpenChangeRequests counts the change requests of taskID not yet
+// addressed.
+func (s *Store) CountOpenChangeRequests(ctx context.Context, taskID string) (int64, error) {
+	return s.queries.CountOpenChangeRequests(ctx, taskID)
+}
+
+// ============ JIRA Links ============
+
+// CreateJiraLink records that a task was imported from a JIRA issue.
+func (s *Store) CreateJiraLink(ctx context.Context, params db.CreateJiraLinkParams) (db.JiraLink, error) {
+	return s.queries.CreateJiraLink(ctx, params)
+}
+
+func (s *Store) GetJiraLinkByIssue(ctx context.Context, issueKey string) (db.JiraLink, error) {
+	return s.queries.GetJiraLinkByIssue(ctx, issueKey)
+}
+
+func (s *Store) ListJiraLinks(ctx context.Context) ([]db.JiraLink, error) {
+	return s.queries.ListJiraLinks(ctx)
+}
+
+// SetJiraLinkSynced records the statuses of a linked issue and its task as
+// of a sync.
+func (s *Store) SetJiraLinkSynced(ctx context.Context, taskID, jiraStatus, taskStatus string) error {
+	return s.queries.SetJiraLinkSynced(ctx, db.SetJiraLinkSyncedParams{
+		JiraStatus:   jiraStatus,
+		SyncedStatus: taskStatus,
+		TaskID:       taskID,
 	})
 }
 
-func (s *Store) ClearTaskScheduledAt(ctx context.Context, id string) error {
-	return s.queries.ClearTaskScheduledAt(ctx, id)
+// ============ GitHub Issue Links ============
+
+// CreateGitHubIssueLink records that a task was created from a GitHub issue.
+func (s *Store) CreateGitHubIssueLink(ctx context.Context, params db.CreateGithubIssueLinkParams) (db.GithubIssueLink, error) {
+	return s.queries.CreateGithubIssueLink(ctx, params)
 }
 
-func (s *Store) ClearTaskRetryAt(ctx context.Context, id string) error {
-	return s.queries.ClearTaskRetryAt(ctx, id)
+func (s *Store) GetGitHubIssueLinkByIssue(ctx context.Context, repo string, number int64) (db.GithubIssueLink, error) {
+	return s.queries.GetGithubIssueLinkByIssue(ctx, db.GetGithubIssueLinkByIssueParams{Repo: repo, IssueNumber: number})
 }
 
-func (s *Store) ListScheduledDueTasks(ctx context.Context) ([]db.Task, error) {
-	return s.queries.ListScheduledDueTasks(ctx)
+func (s *Store) GetGitHubIssueLinkByTask(ctx context.Context, taskID string) (db.GithubIssueLink, error) {
+	return s.queries.GetGithubIssueLinkByTask(ctx, taskID)
 }
 
-func (s *Store) ListRetryDueTasks(ctx context.Context) ([]db.Task, error) {
-	return s.queries.ListRetryDueTasks(ctx)
+func (s *Store) ListGitHubIssueLinksByProject(ctx context.Context, projectID string) ([]db.GithubIssueLink, error) {
+	return s.queries.ListGithubIssueLinksByProject(ctx, projectID)
+}
+
+// SetGitHubIssueLinkState records the state (open or closed) of a task's
+// issue as last seen.
+func (s *Store) SetGitHubIssueLinkState(ctx context.Context, taskID, state string) error {
+	return s.queries.SetGithubIssueLinkState(ctx, db.SetGithubIssueLinkStateParams{State: state, TaskID: taskID})
+}
+
+// ============ Inbound Emails ============
+
+// CreateInboundEmail records that a task was created from an email.
+func (s *Store) CreateInboundEmail(ctx context.Context, params db.CreateInboundEmailParams) (db.InboundEmail, error) {
+	return s.queries.CreateInboundEmail(ctx, params)
+}
+
+func (s *Store) GetInboundEmailByMessageID(ctx context.Context, messageID string) (db.InboundEmail, error) {
+	return s.queries.GetInboundEmailByMessageID(ctx, sql.NullString{String: messageID, Valid: true})
+}
+
+func (s *Store) ListInboundEmails(ctx context.Context, limit int64) ([]db.InboundEmail, error) {
+	return s.queries.ListInboundEmails(ctx, limit)
+}
+
+// ============ Projects ============
+
+func (s *Store) CreateProject(ctx context.Context, params db.CreateProjectParams) (db.Project, error) {
+	if params.ID == "" {
+		params.ID = uuid.New().String()
+	}
+	return s.queries.CreateProject(ctx, params)
+}
+
+func (s *Store) GetProject(ctx context.Context, id string) (db.Project, error) {
+	return s.queries.GetProject(ctx, id)
+}
+
+func (s *Store) ListProjects(ctx context.Context) ([]db.Project, error) {
+	return s.queries.ListProjects(ctx)
+}
+
+// ListProjectsByIDs returns the projects with the given IDs, in one query.
+func (s *Store) ListProjectsByIDs(ctx context.Context, ids []string) ([]db.Project, error) {
+	return s.queries.ListProjectsByIDs(ctx, ids)
+}
+
+func (s *Store) ListProjectsByStatus(ctx context.Context, status sql.NullString) ([]db.Project, error) {
+	return s.queries.ListProjectsByStatus(ctx, status)
+}
+
+// ListProjectsWithStats returns the projects, only those with status if it
+// is set, each with its task counts and the last time one of its tasks
+// changed, in one query.
+func (s *Store) ListProjectsWithStats(ctx context.Context, status string) ([]db.ListProjectsWithStatsRow, error) {
+	if status == "" {
+		return s.queries.ListProjectsWithStats(ctx)
+	}
+	rows, err := s.queries.ListProjectsWithStatsByStatus(ctx, sql.NullString{String: status, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	projects := make([]db.ListProjectsWithStatsRow, len(rows))
+	for i, r := range rows {
+		projects[i] = db.ListProjectsWithStatsRow(r)
+	}
+	return projects, nil
+}
+
+// GetProjectWithStats returns a project with its task counts and the last
+// time one of its tasks changed.
+func (s *Store) GetProjectWithStats(ctx context.Context, id string) (db.GetProjectWithStatsRow, error) {
+	return s.queries.GetProjectWithStats(ctx, id)
+}
+
+func (s *Store) UpdateProject(ctx context.Context, params db.UpdateProjectParams) (db.Project, error) {
+	return s.queries.UpdateProject(ctx, params)
+}
+
+func (s *Store) DeleteProject(ctx context.Context, id string) error {
+	return s.queries.DeleteProject(ctx, id)
+}
+
+// What DeleteProjectWithTasks does with the project's tasks.
+const (
+	ProjectTasksOrphan  = "orphan"  // keep them, without a project
+	ProjectTasksArchive = "archive" // cancel the open ones and keep them all, without a project
+	ProjectTasksDelete  = "delete"  // delete them; their events are kept, without them
+)
+
+// GetProjectDeletionImpact counts what deleting the project affects: its
+// tasks and the secrets, experiments and GitHub issue links that go with it.
+func (s *Store) GetProjectDeletionImpact(ctx context.Context, id string) (db.GetProjectDeletionImpactRow, error) {
+	return s.queries.GetProjectDeletionImpact(ctx, id)
+}
+
+// DeleteProjectWithTasks deletes the project, doing with its tasks what mode
+// (ProjectTasksOrphan, ProjectTasksArchive or ProjectTasksDelete) says, in
+// one transaction. With a trash (see SetTrashRetention) the project goes
+// there with its deleted tasks; restoring it gives the tasks kept their
+// project back, archived ones stay cancelled. It returns the project's tasks
+// as they were before.
+func (s *Store) DeleteProjectWithTasks(ctx context.Context, id, mode string) ([]db.Task, error) {
+	var tasks []db.Task
+	err := s.WithTx(ctx, func(tx *Store) error {
+		project, err := tx.queries.GetProject(ctx, id)
+		if err != nil {
+			return err
+		}
+		projectID := sql.NullString{String: id, Valid: true}
+		if tasks, err = tx.queries.ListTasksByProject(ctx, projectID); err != nil {
+			return err
+		}
+		var deletedTasks db.Snapshot
+		switch {
+		case mode == ProjectTasksArchive:
+			err = tx.queries.CancelOpenTasksByProject(ctx, projectID)
+		case mode == ProjectTasksDelete && tx.trashRetention > 0:
+			deletedTasks, err = tx.queries.DeleteWithSnapshot(ctx, "tasks", "project_id", id)
+		case mode == ProjectTasksDelete:
+			if err = tx.queries.DetachProjectTaskEvents(ctx, projectID); err != nil {
+				return err
+			}
+			if err = tx.queries.DeleteSubAgentsByProject(ctx, projectID); err != nil {
+				return err
+			}
+			err = tx.queries.DeleteTasksByProject(ctx, projectID)
+		}
+		if err != nil {
+			return err
+		}
+		// The tasks left lose the project (ON DELETE SET NULL)
+		if tx.trashRetention <= 0 {
+			return tx.queries.DeleteProject(ctx, id)
+		}
+		snap, err := tx.queries.DeleteWithSnapshot(ctx, "projects", "id", id)
+		if err != nil {
+			return err
+		}
+		snap.Add(deletedTasks)
+		return tx.trash(ctx, TrashProject, id, project.Name, snap)
+	})
+	return tasks, err
+}
+
+// SetProjectPathPolicy sets the paths agents may touch in the project's tasks
+// (unrestricted if allowed is empty) and what a violation does ("" = warn).
+func (s *Store) SetProjectPathPolicy(ctx context.Context, id string, allowed []string, action string) error {
+	param := sql.NullString{}
+	if len(allowed) > 0 {
+		encoded, err := json.Marshal(allowed)
+		if err != nil {
+			return err
+		}
+		param = sql.NullString{String: string(encoded), Valid: true}
+	}
+	return s.queries.SetProjectPathPolicy(ctx, db.SetProjectPathPolicyParams{
+		AllowedPaths: param,
+		PolicyAction: sql.NullString{String: action, Valid: action != ""},
+		ID:           id,
+	})
+}
+
+// SetProjectDelegationLimits sets the delegation limits of the project's
+// tasks: how deep subtasks may nest and how many subtasks a task may have
+// unfinished at once. A null limit defers to the server default.
+func (s *Store) SetProjectDelegationLimits(ctx context.Context, id string, maxDepth, maxConcurrent sql.NullInt64) error {
+	return s.queries.SetProjectDelegationLimits(ctx, db.SetProjectDelegationLimitsParams{
+		MaxSubtaskDepth:       maxDepth,
+		MaxConcurrentSubtasks: maxConcurrent,
+		ID:                    id,
+	})
+}
+
+// SetProjectGitHub sets the GitHub repository (owner/name) whose issues
+// labelled label become the project's tasks; an empty repo turns sync off.
+func (s *Store) SetProjectGitHub(ctx context.Context, id, repo, label string) error {
+	return s.queries.SetProjectGithub(ctx, db.SetProjectGithubParams{
+		GithubRepo:  sql.NullString{String: repo, Valid: repo != ""},
+		GithubLabel: sql.NullString{String: label, Valid: label != ""},
+		ID:          id,
+	})
+}
+
+// ListProjectsByGitHubRepo returns the projects synced with repo.
+func (s *Store) ListProjectsByGitHubRepo(ctx context.Context, repo string) ([]db.Project, error) {
+	return s.queries.ListProjectsByGithubRepo(ctx, sql.NullString{String: repo, Valid: true})
+}
+
+func (s *Store) GetProjectTaskCount(ctx context.Context, projectID sql.NullString) (int64, error) {
+	return s.queries.GetProjectTaskCount(ctx, projectID)
+}
+
+func (s *Store) GetProjectDoneTaskCount(ctx context.Context, projectID sql.NullString) (int64, error) {
+	return s.queries.GetProjectDoneTaskCount(ctx, projectID)
+}
+
+// ListTasksByProjectIDs returns the tasks of the given projects, in one query.
+func (s *Store) ListTasksByProjectIDs(ctx context.Context, projectIDs []string) ([]db.Task, error) {
+	return s.queries.ListTasksByProjectIDs(ctx, nullStrings(projectIDs))
+}
+
+func (s *Store) ListTasksByProject(ctx context.Context, projectID sql.NullString) ([]db.Task, error) {
+	return s.queries.ListTasksByProject(ctx, projectID)
+}
+
+// ============ Comments ============
+
+// CommentPreviewSize is how many characters of a comment are listed; longer
+// comments are listed by their preview and fetched whole by ID.
+const CommentPreviewSize = 1000
+
+// CommentPreview returns the preview stored with a comment of content: its
+// first CommentPreviewSize characters, or NULL if it is no longer than that.
+func CommentPreview(content string) sql.NullString {
+	if utf8.RuneCountInString(content) <= CommentPreviewSize {
+		return sql.NullString{}
+	}
+	n := 0
+	for i := range content {
+		if n == CommentPreviewSize {
+			return sql.NullString{String: content[:i], Valid: true}
+		}
+		n++
+	}
+	return sql.NullString{}
+}
+
+// CreateComment adds a comment, with its preview.
+func (s *Store) CreateComment(ctx context.Context, params db.CreateCommentParams) (db.Comment, error) {
+	if params.ID == "" {
+		params.ID = uuid.New().String()
+	}
+	params.Preview = CommentPreview(params.Content)
+	return s.queries.CreateComment(ctx, params)
+}
+
+func (s *Store) GetComment(ctx context.Context, id string) (db.Comment, error) {
+	return s.queries.GetComment(ctx, id)
+}
+
+func (s *Store) ListCommentsByTask(ctx context.Context, taskID string) ([]db.Comment, error) {
+	return s.queries.ListCommentsByTask(ctx, taskID)
+}
+
+// CommentPage selects a page of a task's comments for ListCommentsPage.
+type CommentPage struct {
+	Author      string // only this author's; "" = everyone's
+	Limit       int64  // 0 = all
+	Offset      int64
+	NewestFirst bool
+}
+
+// ListCommentsPage returns a page of a task's comments, oldest first unless
+// p.NewestFirst, and how many comments there are in all (by p.Author).
+func (s *Store) ListCommentsPage(ctx context.Context, taskID string, p CommentPage) ([]db.Comment, int64, error) {
+	total, err := s.queries.CountCommentsByTask(ctx, db.CountCommentsByTaskParams{TaskID: taskID, Author: p.Author})
+	if err != nil {
+		return nil, 0, err
+	}
+	limit := p.Limit
+	if limit <= 0 {
+		limit = -1 // no limit
+	}
+	params := db.ListCommentsByTaskAscParams{TaskID: taskID, Author: p.Author, Limit: limit, Offset: p.Offset}
+	var comments []db.Comment
+	if p.NewestFirst {
+		comments, err = s.queries.ListCommentsByTaskDesc(ctx, db.ListCommentsByTaskDescParams(params))
+	} else {
+		comments, err = s.queries.ListCommentsByTaskAsc(ctx, params)
+	}
+	return comments, total, err
+}
+
+// DeleteComment deletes a comment, into the trash if there is one (see
+// SetTrashRetention).
+func (s *Store) DeleteComment(ctx context.Context, id string) error {
+	if s.trashRetention <= 0 {
+		return s.queries.DeleteComment(ctx, id)
+	}
+	return s.WithTx(ctx, func(tx *Store) error {
+		comment, err := tx.queries.GetComment(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		snap, err := tx.queries.DeleteWithSnapshot(ctx, "comments", "id", id)
+		if err != nil {
+			return err
+		}
+		return tx.trash(ctx, TrashComment, id, comment.Content, snap)
+	})
+}
+
+// ============ Chat Sessions ============
+
+func (s *Store) CreateChatSession(ctx context.Context, params db.CreateChatSessionParams) (db.ChatSession, error) {
+	if params.ID == "" {
+		params.ID = uuid.New().String()
+	}
+	return s.queries.CreateChatSession(ctx, params)
+}
+
+func (s *Store) GetChatSession(ctx context.Context, id string) (db.ChatSession, error) {
+	return s.queries.GetChatSession(ctx, id)
+}
+
+func (s *Store) ListChatSessionsByAgent(ctx context.Context, agentID string) ([]db.ChatSession, error) {
+	return s.queries.ListChatSessionsByAgent(ctx, agentID)
+}
+
+func (s *Store) EndChatSession(ctx context.Context, id string) error {
+	return s.queries.EndChatSession(ctx, id)
+}
+
+func (s *Store) UpdateMessageCount(ctx context.Context, id string) error {
+	return s.queries.UpdateMessageCount(ctx, id)
+}
+
+// ============ Chat Messages ============
+
+func (s *Store) CreateChatMessage(ctx context.Context, params db.CreateChatMessageParams) (db.ChatMessage, error) {
+	if params.ID == "" {
+		params.ID = uuid.New().String()
+	}
+	return s.queries.CreateChatMessage(ctx, params)
+}
+
+func (s *Store) ListMessagesBySession(ctx context.Context, sessionID string) ([]db.ChatMessage, error) {
+	return s.queries.ListMessagesBySession(ctx, sessionID)
+}
+
+// ============ Task Dependencies ============
+
+func (s *Store) ListSubtasks(ctx context.Context, parentTaskID sql.NullString) ([]db.Task, error) {
+	return s.queries.ListSubtasks(ctx, parentTaskID)
+}
+
+// ============ Task Scheduling ============
+
+func (s *Store) SetTaskScheduledAt(ctx context.Context, id string, t time.Time) error {
+	return s.queries.SetTaskScheduledAt(ctx, db.SetTaskScheduledAtParams{
+		ScheduledAt: sql.NullTime{Time: t, Valid: true},
+		ID:          id,
+	})
+}
+
+func (s *Store) SetTaskRetryAt(ctx context.Context, id string, t time.Time) error {
+	if err := s.queries.SetTaskRetryAt(ctx, db.SetTaskRetryAtParams{
+		RetryAt: sql.NullTime{Time: t, Valid: true},
+		ID:      id,
+	}); err != nil {
+		return err
+	}
+	return s.queries.StampTaskTimes(ctx, id)
+}
+
+func (s *Store) ClearTaskScheduledAt(ctx context.Context, id string) error {
+	return s.queries.ClearTaskScheduledAt(ctx, id)
+}
+
+func (s *Store) ClearTaskRetryAt(ctx context.Context, id string) error {
+	return s.queries.ClearTaskRetryAt(ctx, id)
+}
+
+func (s *Store) ListScheduledDueTasks(ctx context.Context) ([]db.Task, error) {
+	return s.queries.ListScheduledDueTasks(ctx)
+}
+
+func (s *Store) ListRetryDueTasks(ctx context.Context) ([]db.Task, error) {
+	return s.queries.ListRetryDueTasks(ctx)
+}
+
+// ListCalendarTasks returns tasks with a scheduled start or retry pending,
+// soonest first.
+func (s *Store) ListCalendarTasks(ctx context.Context) ([]db.Task, error) {
+	return s.queries.ListCalendarTasks(ctx)
+}
+
+// UpdateTaskDetails sets a task's title, description ("" for none) and
+// priority.
+func (s *Store) UpdateTaskDetails(ctx context.Context, id, title, description string, priority int64) error {
+	return s.queries.UpdateTaskDetails(ctx, db.UpdateTaskDetailsParams{
+		Title:       title,
+		Description: sql.NullString{String: description, Valid: description != ""},
+		Priority:    sql.NullInt64{Int64: priority, Valid: true},
+		ID:          id,
+	})
+}
+
+// SetTaskDeferredUntil postpones dispatch of a task until t (stored in UTC so
+// it compares correctly with CURRENT_TIMESTAMP).
+func (s *Store) SetTaskDeferredUntil(ctx context.Context, id string, t time.Time) error {
+	return s.queries.SetTaskDeferredUntil(ctx, db.SetTaskDeferredUntilParams{
+		DeferredUntil: sql.NullTime{Time: t.UTC(), Valid: true},
+		ID:            id,
+	})
+}
+
+func (s *Store) ClearTaskDeferredUntil(ctx context.Context, id string) error {
+	return s.queries.ClearTaskDeferredUntil(ctx, id)
+}
+
+func (s *Store) ListDeferredDueTasks(ctx context.Context) ([]db.Task, error) {
+	return s.queries.ListDeferredDueTasks(ctx)
+}
+
+// SetTaskSecretNames sets which project secrets are injected into the task's
+// assignment notification (none if names is empty).
+func (s *Store) SetTaskSecretNames(ctx context.Context, id string, names []string) error {
+	param := sql.NullString{}
+	if len(names) > 0 {
+		encoded, err := json.Marshal(names)
+		if err != nil {
+			return err
+		}
+		param = sql.NullString{String: string(encoded), Valid: true}
+	}
+	return s.queries.SetTaskSecretNames(ctx, db.SetTaskSecretNamesParams{SecretNames: param, ID: id})
+}
+
+// SetTaskProgress records the task's progress as a percentage. An explicit
+// value stops progress being derived from the task's stories and phases.
+func (s *Store) SetTaskProgress(ctx context.Context, id string, percent int, explicit bool) error {
+	return s.queries.SetTaskProgress(ctx, db.SetTaskProgressParams{
+		Progress:         sql.NullInt64{Int64: int64(percent), Valid: true},
+		ProgressExplicit: explicit,
+		ID:               id,
+	})
+}
+
+// ClearTaskProgress forgets the task's progress, so it is derived again.
+func (s *Store) ClearTaskProgress(ctx context.Context, id string) error {
+	return s.queries.SetTaskProgress(ctx, db.SetTaskProgressParams{ID: id})
+}
+
+// SetTaskContextSummary stores the task's rolling context summary. It leaves
+// updated_at alone: a summary is not progress, and must not hide a stuck task
+// from the watchdog.
+func (s *Store) SetTaskContextSummary(ctx context.Context, id, summary string) error {
+	return s.queries.SetTaskContextSummary(ctx, db.SetTaskContextSummaryParams{
+		ContextSummary: sql.NullString{String: summary, Valid: summary != ""},
+		ID:             id,
+	})
+}
+
+// SetTaskDelegationLimits sets how deep subtasks may nest below the task and
+// how many of its subtasks may be unfinished at once. A null limit defers to
+// the task's project.
+func (s *Store) SetTaskDelegationLimits(ctx context.Context, id string, maxDepth, maxConcurrent sql.NullInt64) error {
+	return s.queries.SetTaskDelegationLimits(ctx, db.SetTaskDelegationLimitsParams{
+		MaxSubtaskDepth:       maxDepth,
+		MaxConcurrentSubtasks: maxConcurrent,
+		ID:                    id,
+	})
+}
+
+// SetTaskFailureReason records why the task last failed or was reset (see
+// package failures). Like a context summary, it leaves updated_at alone.
+func (s *Store) SetTaskFailureReason(ctx context.Context, id, reason string) error {
+	return s.queries.SetTaskFailureReason(ctx, db.SetTaskFailureReasonParams{
+		FailureReason: sql.NullString{String: reason, Valid: reason != ""},
+		ID:            id,
+	})
+}
+
+// CountTaskFailures counts the tasks with a failure reason, per project,
+// agent and reason.
+func (s *Store) CountTaskFailures(ctx context.Context) ([]db.CountTaskFailuresRow, error) {
+	return s.queries.CountTaskFailures(ctx)
+}
+
+// CountQueuedTasks counts the queued tasks per agent and group; group
+// tasks not yet claimed have no agent.
+func (s *Store) CountQueuedTasks(ctx context.Context) ([]db.CountQueuedTasksRow, error) {
+	return s.queries.CountQueuedTasks(ctx)
+}
+
+// CountTasksByStatus counts the tasks in each status.
+func (s *Store) CountTasksByStatus(ctx context.Context) ([]db.CountTasksByStatusRow, error) {
+	return s.queries.CountTasksByStatus(ctx)
+}
+
+// ListAgentTaskOutcomes returns the agent, status and timings of assigned
+// tasks that finished (done or failed) since since.
+func (s *Store) ListAgentTaskOutcomes(ctx context.Context, since time.Time) ([]db.ListAgentTaskOutcomesRow, error) {
+	return s.queries.ListAgentTaskOutcomes(ctx, sql.NullTime{Time: since.UTC(), Valid: true})
+}
+
+// SetTaskModel pins the model the task runs on, overriding the routing
+// policy; "" defers to the policy again.
+func (s *Store) SetTaskModel(ctx context.Context, id, model string) error {
+	return s.queries.SetTaskModel(ctx, db.SetTaskModelParams{
+		Model: sql.NullString{String: model, Valid: model != ""},
+		ID:    id,
+	})
+}
+
+// SetTaskRoutedModel records the model chosen for the task at dispatch
+// ("" if none was). It leaves updated_at alone.
+func (s *Store) SetTaskRoutedModel(ctx context.Context, id, model string) error {
+	return s.queries.SetTaskRoutedModel(ctx, db.SetTaskRoutedModelParams{
+		RoutedModel: sql.NullString{String: model, Valid: model != ""},
+		ID:          id,
+	})
+}
+
+// SetTaskReview sets whether the task needs review before it is done and who
+// reviews it: an agent or a human user ("" for neither).
+func (s *Store) SetTaskReview(ctx context.Context, id string, required bool, reviewerAgentID, reviewerUser string) error {
+	return s.queries.SetTaskReview(ctx, db.SetTaskReviewParams{
+		RequiresReview:  required,
+		ReviewerAgentID: sql.NullString{String: reviewerAgentID, Valid: reviewerAgentID != ""},
+		ReviewerUser:    sql.NullString{String: reviewerUser, Valid: reviewerUser != ""},
+		ID:              id,
+	})
+}
+
+// ============ Progress Entries ============
+
+// progressDigestSize is how many of the latest entries tasks.progress_txt
+// keeps, for clients that still read the task's progress as one text.
+const progressDigestSize = 20
+
+// AddProgressEntry records a progress note on a task and refreshes the
+// task's progress_txt digest of its latest notes, in one transaction.
+func (s *Store) AddProgressEntry(ctx context.Context, taskID, author, content string) (db.ProgressEntry, error) {
+	var entry db.ProgressEntry
+	err := s.WithTx(ctx, func(tx *Store) error {
+		var err error
+		entry, err = tx.queries.CreateProgressEntry(ctx, db.CreateProgressEntryParams{
+			TaskID:  taskID,
+			Author:  sql.NullString{String: author, Valid: author != ""},
+			Content: content,
+		})
+		if err != nil {
+			return err
+		}
+		return tx.queries.RefreshProgressTxt(ctx, db.RefreshProgressTxtParams{TaskID: taskID, Limit: progressDigestSize, ID: taskID})
+	})
+	return entry, err
+}
+
+// ListProgressEntries returns up to limit progress entries of a task, newest
+// first, starting below the entry ID before (0 = from the newest).
+func (s *Store) ListProgressEntries(ctx context.Context, taskID string, before, limit int64) ([]db.ProgressEntry, error) {
+	if before <= 0 {
+		before = math.MaxInt64
+	}
+	return s.queries.ListProgressEntries(ctx, db.ListProgressEntriesParams{TaskID: taskID, Before: before, Limit: limit})
+}
+
+// ListProgressEntriesSince returns the progress entries of all tasks added
+// at or after since, oldest first.
+func (s *Store) ListProgressEntriesSince(ctx context.Context, since time.Time) ([]db.ProgressEntry, error) {
+	// as CURRENT_TIMESTAMP stores created_at
+	return s.queries.ListProgressEntriesSince(ctx, since.UTC().Format("2006-01-02 15:04:05"))
+}
+
+// ============ Task Queue Ordering ============
+
+// SetTaskQueuePositions pins the given tasks to positions 1..n of their
+// agent's queue, in order, in a single transaction.
+func (s *Store) SetTaskQueuePositions(ctx context.Context, taskIDs []string) error {
+	return s.WithTx(ctx, func(tx *Store) error {
+		for i, id := range taskIDs {
+			if err := tx.queries.SetTaskQueuePosition(ctx, db.SetTaskQueuePositionParams{
+				QueuePosition: sql.NullInt64{Int64: int64(i + 1), Valid: true},
+				ID:            id,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ============ Task Transfer ============
+
+// TransferTask reassigns a task to agentID with the given status and clears
+// its retry state, but only if the task is still in fromStatus. It returns
+// sql.ErrNoRows if the task changed state in the meantime.
+func (s *Store) TransferTask(ctx context.Context, id, fromStatus, agentID, status string) (db.Task, error) {
+	return s.queries.TransferTask(ctx, db.TransferTaskParams{
+		AgentID:  sql.NullString{String: agentID, Valid: true},
+		Status:   sql.NullString{String: status, Valid: true},
+		ID:       id,
+		Status_2: sql.NullString{String: fromStatus, Valid: true},
+	})
+}
+
+// ============ Task Cloning ============
+
+// CopyTaskPlan copies srcID's phases and stories, if asked, onto the task
+// dstID, e.g. a clone being created in the same transaction. Copies start
+// over: phases are pending and stories unpassed, with no session or
+// recorded output.
+func (s *Store) CopyTaskPlan(ctx context.Context, srcID, dstID string, withPhases, withStories bool) error {
+	if withPhases {
+		phases, err := s.queries.ListPhasesByTask(ctx, srcID)
+		if err != nil {
+			return err
+		}
+		for _, p := range phases {
+			if _, err := s.queries.CreatePhase(ctx, db.CreatePhaseParams{
+				ID:          uuid.New().String(),
+				TaskID:      dstID,
+				Sequence:    p.Sequence,
+				Title:       p.Title,
+				Description: p.Description,
+				Status:      sql.NullString{String: "pending", Valid: true},
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	if withStories {
+		stories, err := s.queries.ListStoriesByTask(ctx, srcID)
+		if err != nil {
+			return err
+		}
+		for _, st := range stories {
+			if _, err := s.queries.CreateStory(ctx, db.CreateStoryParams{
+				ID:                 uuid.New().String(),
+				TaskID:             dstID,
+				Sequence:           st.Sequence,
+				Title:              st.Title,
+				Description:        st.Description,
+				Priority:           st.Priority,
+				AcceptanceCriteria: st.AcceptanceCriteria,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// TaskPlan is planning done outside Mission Control, added to a task: the
+// document it came from, kept as the task's roadmap_md or prd_json, and
+// its phases and stories.
+type TaskPlan struct {
+	RoadmapMD string
+	PrdJSON   string
+	Phases    []db.AppendPhaseParams
+	Stories   []PlannedStory
+}
+
+// PlannedStory is a story of a TaskPlan; Passes marks it passed already.
+type PlannedStory struct {
+	db.AppendStoryParams
+	Passes bool
+}
+
+// AddTaskPlan appends plan's phases and stories to the task, after any it
+// has, and stores the document they came from, all in one transaction.
+func (s *Store) AddTaskPlan(ctx context.Context, taskID string, plan TaskPlan) (db.Task, error) {
+	var task db.Task
+	err := s.WithTx(ctx, func(tx *Store) error {
+		var err error
+		if task, err = tx.queries.GetTask(ctx, taskID); err != nil {
+			return err
+		}
+		if plan.RoadmapMD != "" || plan.PrdJSON != "" {
+			params := db.UpdateTaskParams{
+				Title:          task.Title,
+				Description:    task.Description,
+				AgentID:        task.AgentID,
+				ProjectID:      task.ProjectID,
+				Status:         task.Status,
+				Priority:       task.Priority,
+				ProjectMd:      task.ProjectMd,
+				RequirementsMd: task.RequirementsMd,
+				RoadmapMd:      task.RoadmapMd,
+				StateMd:        task.StateMd,
+				PrdJson:        task.PrdJson,
+				ProgressTxt:    task.ProgressTxt,
+				GitBranch:      task.GitBranch,
+				QualityChecks:  task.QualityChecks,
+				DelegationMode: task.DelegationMode,
+				ScheduledAt:    task.ScheduledAt,
+				RetryAt:        task.RetryAt,
+				ID:             task.ID,
+			}
+			if plan.RoadmapMD != "" {
+				params.RoadmapMd = sql.NullString{String: plan.RoadmapMD, Valid: true}
+			}
+			if plan.PrdJSON != "" {
+				params.PrdJson = sql.NullString{String: plan.PrdJSON, Valid: true}
+			}
+			if _, err := tx.queries.UpdateTask(ctx, params); err != nil {
+				return err
+			}
+		}
+		for _, p := range plan.Phases {
+			p.ID = uuid.New().String()
+			p.TaskID = taskID
+			if _, err := tx.queries.AppendPhase(ctx, p); err != nil {
+				return err
+			}
+		}
+		for _, st := range plan.Stories {
+			st.ID = uuid.New().String()
+			st.TaskID = taskID
+			story, err := tx.queries.AppendStory(ctx, st.AppendStoryParams)
+			if err != nil {
+				return err
+			}
+			if st.Passes {
+				if err := tx.queries.MarkStoryPassed(ctx, story.ID); err != nil {
+					return err
+				}
+			}
+		}
+		task, err = tx.queries.GetTask(ctx, taskID)
+		return err
+	})
+	return task, err
+}
+
+// ============ Task Merge & Split ============
+
+// ErrStoryNotOnTask is returned by SplitTask when a story to move does not
+// belong to the task being split.
+var ErrStoryNotOnTask = errors.New("story does not belong to the task")
+
+// TaskSplit is one subtask to create when splitting a task, with the IDs of
+// the parent's stories to move onto it (in their new order).
+type TaskSplit struct {
+	Params   db.CreateTaskParams
+	StoryIDs []string
+}
+
+// SplitTask creates a subtask of parentID for each split and moves the
+// selected stories onto it, in one transaction. The parent's remaining
+// stories are renumbered.
+func (s *Store) SplitTask(ctx context.Context, parentID string, splits []TaskSplit) ([]db.Task, error) {
+	subtasks := make([]db.Task, 0, len(splits))
+	err := s.WithTx(ctx, func(tx *Store) error {
+		for _, split := range splits {
+			params := split.Params
+			if params.ID == "" {
+				params.ID = uuid.New().String()
+			}
+			params.ParentTaskID = sql.NullString{String: parentID, Valid: true}
+			if err := tx.assignShortID(ctx, &params); err != nil {
+				return err
+			}
+			task, err := tx.queries.CreateTask(ctx, params)
+			if err != nil {
+				return err
+			}
+			for i, storyID := range split.StoryIDs {
+				n, err := tx.queries.MoveStory(ctx, db.MoveStoryParams{
+					TaskID:   task.ID,
+					Sequence: int64(i + 1),
+					ID:       storyID,
+					TaskID_2: parentID,
+				})
+				if err != nil {
+					return err
+				}
+				if n == 0 {
+					return fmt.Errorf("%w: %s", ErrStoryNotOnTask, storyID)
+				}
+			}
+			subtasks = append(subtasks, task)
+		}
+
+		remaining, err := tx.queries.ListStoriesByTask(ctx, parentID)
+		if err != nil {
+			return err
+		}
+		for i, st := range remaining {
+			if err := tx.queries.SetStorySequence(ctx, db.SetStorySequenceParams{Sequence: int64(i + 1), ID: st.ID}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return subtasks, nil
+}
+
+// MergeTasks folds each duplicate into survivorID in one transaction: their
+// comments, events, stories (appended after the survivor's), subtasks,
+// sub-agents, retry history, notification deliveries and task links move to
+// the survivor, a redirect is recorded, and the duplicate is deleted along with
+// anything left (its phases).
+func (s *Store) MergeTasks(ctx context.Context, survivorID string, duplicateIDs []string) error {
+	survivor := sql.NullString{String: survivorID, Valid: true}
+	return s.WithTx(ctx, func(tx *Store) error {
+		for _, dupID := range duplicateIDs {
+			dup := sql.NullString{String: dupID, Valid: true}
+			if err := tx.queries.MoveCommentsToTask(ctx, db.MoveCommentsToTaskParams{TaskID: survivorID, TaskID_2: dupID}); err != nil {
+				return err
+			}
+			if err := tx.queries.MoveEventsToTask(ctx, db.MoveEventsToTaskParams{TaskID: survivor, TaskID_2: dup}); err != nil {
+				return err
+			}
+			if err := tx.queries.MoveSubAgentsToTask(ctx, db.MoveSubAgentsToTaskParams{TaskID: survivor, TaskID_2: dup}); err != nil {
+				return err
+			}
+			if err := tx.queries.MoveTaskAttemptsToTask(ctx, db.MoveTaskAttemptsToTaskParams{TaskID: survivorID, TaskID_2: dupID}); err != nil {
+				return err
+			}
+			if err := tx.queries.MoveNotificationDeliveriesToTask(ctx, db.MoveNotificationDeliveriesToTaskParams{TaskID: survivorID, TaskID_2: dupID}); err != nil {
+				return err
+			}
+			offset, err := tx.queries.GetMaxStorySequence(ctx, survivorID)
+			if err != nil {
+				return err
+			}
+			if err := tx.queries.MoveStoriesToTask(ctx, db.MoveStoriesToTaskParams{TaskID: survivorID, Sequence: offset, TaskID_2: dupID}); err != nil {
+				return err
+			}
+			if err := tx.queries.ReparentSubtasks(ctx, db.ReparentSubtasksParams{ParentTaskID: survivor, ParentTaskID_2: dup, ID: survivorID}); err != nil {
+				return err
+			}
+			if err := tx.queries.RetargetTaskRedirects(ctx, db.RetargetTaskRedirectsParams{ToTaskID: survivorID, ToTaskID_2: dupID}); err != nil {
+				return err
+			}
+			if err := tx.queries.DeleteTaskLinksBySource(ctx, db.DeleteTaskLinksBySourceParams{SourceKind: LinkFromDescription, SourceID: dupID}); err != nil {
+				return err
+			}
+			if err := tx.queries.MoveTaskLinkSources(ctx, db.MoveTaskLinkSourcesParams{SourceTaskID: survivorID, SourceTaskID_2: dupID}); err != nil {
+				return err
+			}
+			if err := tx.queries.RetargetTaskLinks(ctx, db.RetargetTaskLinksParams{TargetTaskID: survivorID, TargetTaskID_2: dupID}); err != nil {
+				return err
+			}
+			if err := tx.queries.DeleteTask(ctx, dupID); err != nil {
+				return err
+			}
+			if err := tx.queries.CreateTaskRedirect(ctx, db.CreateTaskRedirectParams{FromTaskID: dupID, ToTaskID: survivorID}); err != nil {
+				return err
+			}
+		}
+		// References between the merged tasks now point at the survivor itself
+		return tx.queries.DeleteSelfTaskLinks(ctx)
+	})
+}
+
+// GetTaskRedirect returns where a merged task's ID now points.
+func (s *Store) GetTaskRedirect(ctx context.Context, fromTaskID string) (db.TaskRedirect, error) {
+	return s.queries.GetTaskRedirect(ctx, fromTaskID)
+}
+
+// ============ Task Links ============
+
+// Sources of task links.
+const (
+	LinkFromDescription = "description"
+	LinkFromComment     = "comment"
+)
+
+// SetTaskLinks replaces the links written by one description or comment
+// (sourceKind, sourceID) with links to targetIDs. Links from a task to itself
+// are skipped.
+func (s *Store) SetTaskLinks(ctx context.Context, sourceTaskID, sourceKind, sourceID string, targetIDs []string) error {
+	return s.WithTx(ctx, func(tx *Store) error {
+		if err := tx.queries.DeleteTaskLinksBySource(ctx, db.DeleteTaskLinksBySourceParams{SourceKind: sourceKind, SourceID: sourceID}); err != nil {
+			return err
+		}
+		for _, target := range targetIDs {
+			if target == sourceTaskID {
+				continue
+			}
+			if err := tx.queries.CreateTaskLink(ctx, db.CreateTaskLinkParams{
+				SourceTaskID: sourceTaskID,
+				TargetTaskID: target,
+				SourceKind:   sourceKind,
+				SourceID:     sourceID,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Store) DeleteTaskLinksBySource(ctx context.Context, sourceKind, sourceID string) error {
+	return s.queries.DeleteTaskLinksBySource(ctx, db.DeleteTaskLinksBySourceParams{SourceKind: sourceKind, SourceID: sourceID})
+}
+
+// ListTaskLinks returns the tasks a task refers to.
+func (s *Store) ListTaskLinks(ctx context.Context, taskID string) ([]db.ListTaskLinksRow, error) {
+	return s.queries.ListTaskLinks(ctx, taskID)
+}
+
+// ListTaskBacklinks returns the tasks that refer to a task.
+func (s *Store) ListTaskBacklinks(ctx context.Context, taskID string) ([]db.ListTaskBacklinksRow, error) {
+	return s.queries.ListTaskBacklinks(ctx, taskID)
+}
+
+// ResolveTaskPrefix returns the ID of the only task whose ID starts with
+// prefix, or "" if there is none or more than one.
+func (s *Store) ResolveTaskPrefix(ctx context.Context, prefix string) (string, error) {
+	ids, err := s.queries.ListTaskIDsByPrefix(ctx, prefix)
+	if err != nil || len(ids) != 1 {
+		return "", err
+	}
+	return ids[0], nil
+}
+
+// ============ Agent Groups ============
+
+func (s *Store) CreateAgentGroup(ctx context.Context, params db.CreateAgentGroupParams) (db.AgentGroup, error) {
+	if params.ID == "" {
+		params.ID = uuid.New().String()
+	}
+	return s.queries.CreateAgentGroup(ctx, params)
+}
+
+func (s *Store) GetAgentGroup(ctx context.Context, id string) (db.AgentGroup, error) {
+	return s.queries.GetAgentGroup(ctx, id)
+}
+
+func (s *Store) ListAgentGroups(ctx context.Context) ([]db.AgentGroup, error) {
+	return s.queries.ListAgentGroups(ctx)
+}
+
+func (s *Store) UpdateAgentGroup(ctx context.Context, params db.UpdateAgentGroupParams) (db.AgentGroup, error) {
+	return s.queries.UpdateAgentGroup(ctx, params)
+}
+
+func (s *Store) DeleteAgentGroup(ctx context.Context, id string) error {
+	return s.queries.DeleteAgentGroup(ctx, id)
+}
+
+// AddAgentGroupMember adds an agent to a group, or updates its skills if it
+// is already a member.
+func (s *Store) AddAgentGroupMember(ctx context.Context, groupID, agentID string, skills []string) error {
+	params := db.AddAgentGroupMemberParams{GroupID: groupID, AgentID: agentID}
+	if len(skills) > 0 {
+		encoded, err := json.Marshal(skills)
+		if err != nil {
+			return err
+		}
+		params.Skills = sql.NullString{String: string(encoded), Valid: true}
+	}
+	return s.queries.AddAgentGroupMember(ctx, params)
+}
+
+func (s *Store) RemoveAgentGroupMember(ctx context.Context, groupID, agentID string) error {
+	return s.queries.RemoveAgentGroupMember(ctx, db.RemoveAgentGroupMemberParams{GroupID: groupID, AgentID: agentID})
+}
+
+func (s *Store) ListAgentGroupMembers(ctx context.Context, groupID string) ([]db.Agent, error) {
+	return s.queries.ListAgentGroupMembers(ctx, groupID)
+}
+
+func (s *Store) ListAgentGroupMemberships(ctx context.Context, groupID string) ([]db.AgentGroupMember, error) {
+	return s.queries.ListAgentGroupMemberships(ctx, groupID)
+}
+
+// SetAgentGroupLastDispatched records the member chosen by the latest dispatch.
+func (s *Store) SetAgentGroupLastDispatched(ctx context.Context, groupID, agentID string) error {
+	return s.queries.SetAgentGroupLastDispatched(ctx, db.SetAgentGroupLastDispatchedParams{
+		LastDispatchedAgentID: sql.NullString{String: agentID, Valid: agentID != ""},
+		ID:                    groupID,
+	})
+}
+
+func (s *Store) ListAgentGroupsByAgent(ctx context.Context, agentID string) ([]db.AgentGroup, error) {
+	return s.queries.ListAgentGroupsByAgent(ctx, agentID)
+}
+
+// ListQueuedTasksByGroup returns the unclaimed tasks in a group's shared queue.
+func (s *Store) ListQueuedTasksByGroup(ctx context.Context, groupID string) ([]db.Task, error) {
+	return s.queries.ListQueuedTasksByGroup(ctx, sql.NullString{String: groupID, Valid: true})
+}
+
+// ListQueuedGroupTasksForAgent returns the unclaimed tasks in the shared
+// queues of every group the agent belongs to, in dispatch order.
+func (s *Store) ListQueuedGroupTasksForAgent(ctx context.Context, agentID string) ([]db.Task, error) {
+	return s.queries.ListQueuedGroupTasksForAgent(ctx, agentID)
+}
+
+// AssignTaskToGroup puts a task in a group's shared queue, clearing any
+// individual assignee.
+func (s *Store) AssignTaskToGroup(ctx context.Context, taskID, groupID string) (db.Task, error) {
+	return s.queries.AssignTaskToGroup(ctx, db.AssignTaskToGroupParams{
+		GroupID: sql.NullString{String: groupID, Valid: true},
+		ID:      taskID,
+	})
+}
+
+// ClaimGroupTask assigns an unclaimed group task to agentID and moves it to
+// backlog. It returns sql.ErrNoRows if another member claimed it first.
+func (s *Store) ClaimGroupTask(ctx context.Context, taskID, agentID string) (db.Task, error) {
+	return s.queries.ClaimGroupTask(ctx, db.ClaimGroupTaskParams{
+		AgentID: sql.NullString{String: agentID, Valid: true},
+		ID:      taskID,
+	})
+}
+
+// ============ Notification Deliveries ============
+
+// CreateNotificationDelivery records a pending notification to agentID about
+// taskID reaching transition (attempt 1), sent to the gateway session
+// sessionKey ("" if it goes elsewhere).
+func (s *Store) CreateNotificationDelivery(ctx context.Context, kind, taskID, transition, agentID, sessionKey string) (db.NotificationDelivery, error) {
+	return s.queries.CreateNotificationDelivery(ctx, db.CreateNotificationDeliveryParams{
+		ID:         uuid.New().String(),
+		Kind:       kind,
+		TaskID:     taskID,
+		Transition: transition,
+		AgentID:    agentID,
+		SessionKey: sql.NullString{String: sessionKey, Valid: sessionKey != ""},
+	})
+}
+
+func (s *Store) GetNotificationDelivery(ctx context.Context, id string) (db.NotificationDelivery, error) {
+	return s.queries.GetNotificationDelivery(ctx, id)
+}
+
+func (s *Store) MarkNotificationDelivered(ctx context.Context, id string) error {
+	return s.queries.MarkNotificationDelivered(ctx, id)
+}
+
+// MarkNotificationFailed records a failed attempt; a delivery already
+// confirmed stays delivered, and one abandoned meanwhile stays abandoned.
+func (s *Store) MarkNotificationFailed(ctx context.Context, id, lastError string) error {
+	return s.queries.MarkNotificationFailed(ctx, db.MarkNotificationFailedParams{
+		LastError: sql.NullString{String: lastError, Valid: lastError != ""},
+		ID:        id,
+	})
+}
+
+// RetryNotificationDelivery marks a delivery pending again for another
+// attempt, to agentID (the recipient may have changed since).
+func (s *Store) RetryNotificationDelivery(ctx context.Context, id, agentID string) error {
+	return s.queries.RetryNotificationDelivery(ctx, db.RetryNotificationDeliveryParams{
+		AgentID: agentID,
+		ID:      id,
+	})
+}
+
+func (s *Store) AbandonNotificationDelivery(ctx context.Context, id string) error {
+	return s.queries.AbandonNotificationDelivery(ctx, id)
+}
+
+// ListUndeliveredNotifications returns pending or failed deliveries not
+// touched since cutoff. Used by the watchdog.
+func (s *Store) ListUndeliveredNotifications(ctx context.Context, cutoff time.Time) ([]db.NotificationDelivery, error) {
+	return s.queries.ListUndeliveredNotifications(ctx, sql.NullTime{Time: cutoff.UTC(), Valid: true})
+}
+
+// AbandonUndeliveredNotifications abandons the pending or failed deliveries
+// of kind about taskID, superseded by a new one.
+func (s *Store) AbandonUndeliveredNotifications(ctx context.Context, taskID, kind string) error {
+	return s.queries.AbandonUndeliveredNotifications(ctx, db.AbandonUndeliveredNotificationsParams{
+		TaskID: taskID,
+		Kind:   kind,
+	})
+}
+
+func (s *Store) ListNotificationDeliveriesByTask(ctx context.Context, taskID string) ([]db.NotificationDelivery, error) {
+	return s.queries.ListNotificationDeliveriesByTask(ctx, taskID)
+}
+
+// ============ Task Attempts ============
+
+// Attempt kinds recorded in a task's retry history.
+const (
+	AttemptSend           = "send" // a notification to the assigned agent
+	AttemptWatchdogRetry  = "watchdog_retry"
+	AttemptWatchdogReset  = "watchdog_reset"
+	AttemptManualRetry    = "manual_retry"
+	AttemptScheduledRetry = "scheduled_retry"
+	AttemptReconcile      = "reconcile" // startup reconciliation after a restart
+)
+
+// Attempt outcomes. Sends start pending and are finished by their callback;
+// other attempts are recorded with their final outcome.
+const (
+	AttemptPending   = "pending"
+	AttemptSucceeded = "succeeded"
+	AttemptFailed    = "failed"
+	AttemptDeferred  = "deferred" // outside the agent's working hours
+	AttemptQueued    = "queued"   // agent busy
+	AttemptScheduled = "scheduled"
+	AttemptReset     = "reset"  // task returned to backlog
+	AttemptVetoed    = "vetoed" // by a pre_dispatch hook
+)
+
+// RecordTaskAttempt appends to a task's retry history, stamped with the
+// task's current retry_count. reason says why the attempt was made. Attempts
+// recorded with an outcome other than AttemptPending are finished
+// immediately.
+func (s *Store) RecordTaskAttempt(ctx context.Context, taskID, kind, agentID, reason, outcome, errMsg string) (db.TaskAttempt, error) {
+	var retryCount int64
+	if task, err := s.queries.GetTask(ctx, taskID); err == nil {
+		retryCount = task.RetryCount
+	}
+	params := db.CreateTaskAttemptParams{
+		ID:         uuid.New().String(),
+		TaskID:     taskID,
+		Kind:       kind,
+		AgentID:    sql.NullString{String: agentID, Valid: agentID != ""},
+		Reason:     sql.NullString{String: reason, Valid: reason != ""},
+		Outcome:    outcome,
+		Error:      sql.NullString{String: errMsg, Valid: errMsg != ""},
+		RetryCount: retryCount,
+	}
+	if outcome != AttemptPending {
+		params.FinishedAt = sql.NullTime{Time: time.Now().UTC(), Valid: true}
+	}
+	return s.queries.CreateTaskAttempt(ctx, params)
+}
+
+// FinishTaskAttempt records the outcome of a pending attempt.
+func (s *Store) FinishTaskAttempt(ctx context.Context, id, outcome, errMsg string) error {
+	return s.queries.FinishTaskAttempt(ctx, db.FinishTaskAttemptParams{
+		Outcome: outcome,
+		Error:   sql.NullString{String: errMsg, Valid: errMsg != ""},
+		ID:      id,
+	})
+}
+
+func (s *Store) ListTaskAttemptsByTask(ctx context.Context, taskID string) ([]db.TaskAttempt, error) {
+	return s.queries.ListTaskAttemptsByTask(ctx, taskID)
+}
+
+// InterruptPendingTaskAttempts fails the sends left pending by a process that
+// stopped before they returned, and returns how many there were.
+func (s *Store) InterruptPendingTaskAttempts(ctx context.Context, errMsg string) (int64, error) {
+	return s.queries.InterruptPendingTaskAttempts(ctx, sql.NullString{String: errMsg, Valid: errMsg != ""})
+}
+
+// CountWatchdogResetsByAgent counts, per agent, the tasks the watchdog took
+// back from it since since.
+func (s *Store) CountWatchdogResetsByAgent(ctx context.Context, since time.Time) ([]db.CountWatchdogResetsByAgentRow, error) {
+	return s.queries.CountWatchdogResetsByAgent(ctx, sql.NullTime{Time: since.UTC(), Valid: true})
+}
+
+// ============ Task Results ============
+
+// Result kinds: the notification an agent's reply answered.
+const (
+	ResultAssignment        = "assignment"
+	ResultRenotify          = "renotify"
+	ResultSubtaskCompletion = "subtask_completion" // the orchestrator's reply to a subtask result
+	ResultReviewRequest     = "review_request"
+	ResultMention           = "mention"
+	ResultChangeRequest     = "change_request"
+)
+
+// resultSummarySize is how many characters of a result's first line are kept
+// as its summary.
+const resultSummarySize = 200
+
+// ResultSummary returns the summary of a result body: its first non-empty
+// line, without Markdown heading marks, cut to 200 characters.
+func ResultSummary(body string) string {
+	line := ""
+	for _, l := range strings.Split(body, "\n") {
+		if l = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(l), "#")); l != "" {
+			line = l
+			break
+		}
+	}
+	if utf8.RuneCountInString(line) <= resultSummarySize {
+		return line
+	}
+	return string([]rune(line)[:resultSummarySize-1]) + "…"
+}
+
+// AddTaskResult keeps an agent's reply to a notification about a task, of
+// kind, with metadata (e.g. the subtask it is about) if any.
+func (s *Store) AddTaskResult(ctx context.Context, taskID, agentID, kind, body string, metadata map[string]string) (db.TaskResult, error) {
+	params := db.CreateTaskResultParams{
+		ID:      uuid.New().String(),
+		TaskID:  taskID,
+		AgentID: sql.NullString{String: agentID, Valid: agentID != ""},
+		Kind:    kind,
+		Summary: ResultSummary(body),
+		Body:    body,
+	}
+	if len(metadata) > 0 {
+		b, err := json.Marshal(metadata)
+		if err != nil {
+			return db.TaskResult{}, err
+		}
+		params.Metadata = sql.NullString{String: string(b), Valid: true}
+	}
+	return s.queries.CreateTaskResult(ctx, params)
+}
+
+// ListTaskResults returns up to limit results of a task, newest first.
+func (s *Store) ListTaskResults(ctx context.Context, taskID string, limit int64) ([]db.TaskResult, error) {
+	return s.queries.ListTaskResultsByTask(ctx, db.ListTaskResultsByTaskParams{TaskID: taskID, Limit: limit})
+}
+
+// ListLatestTaskResults returns the summary of the latest result of each of
+// the given tasks that has one, in one query.
+func (s *Store) ListLatestTaskResults(ctx context.Context, taskIDs []string) ([]db.ListLatestTaskResultsRow, error) {
+	return s.queries.ListLatestTaskResults(ctx, taskIDs)
+}
+
+// ============ Triage ============
+
+// Triage statuses.
+const (
+	TriagePending   = "pending"   // asked, no answer yet
+	TriageStaged    = "staged"    // answered, waiting to be accepted or dismissed
+	TriageApplied   = "applied"   // applied on arrival or accepted
+	TriageDismissed = "dismissed" // turned down
+	TriageFailed    = "failed"    // no usable answer
+)
+
+// StartTaskTriage records that agentID was asked to triage taskID, dropping
+// what an earlier triage of the task suggested.
+func (s *Store) StartTaskTriage(ctx context.Context, taskID, agentID string) (db.TaskTriage, error) {
+	return s.queries.StartTaskTriage(ctx, db.StartTaskTriageParams{TaskID: taskID, AgentID: agentID})
+}
+
+func (s *Store) GetTaskTriage(ctx context.Context, taskID string) (db.TaskTriage, error) {
+	return s.queries.GetTaskTriage(ctx, taskID)
+}
+
+// ListTaskTriage returns the triages with status, most recently updated
+// first.
+func (s *Store) ListTaskTriage(ctx context.Context, status string) ([]db.TaskTriage, error) {
+	return s.queries.ListTaskTriageByStatus(ctx, status)
+}
+
+// SetTaskTriageResult records the triage agent's answer (suggestion, JSON)
+// or why there is none (errMsg).
+func (s *Store) SetTaskTriageResult(ctx context.Context, taskID, status, suggestion, errMsg string) error {
+	return s.queries.SetTaskTriageResult(ctx, db.SetTaskTriageResultParams{
+		Status:     status,
+		Suggestion: sql.NullString{String: suggestion, Valid: suggestion != ""},
+		Error:      sql.NullString{String: errMsg, Valid: errMsg != ""},
+		TaskID:     taskID,
+	})
+}
+
+// DecideTaskTriage records that decidedBy applied or dismissed the
+// suggestion (status).
+func (s *Store) DecideTaskTriage(ctx context.Context, taskID, status, decidedBy string) error {
+	return s.queries.DecideTaskTriage(ctx, db.DecideTaskTriageParams{
+		Status:    status,
+		DecidedBy: sql.NullString{String: decidedBy, Valid: decidedBy != ""},
+		TaskID:    taskID,
+	})
+}
+
+// SetTaskLabels replaces the labels of taskID.
+func (s *Store) SetTaskLabels(ctx context.Context, taskID string, labels []string) error {
+	return s.WithTx(ctx, func(tx *Store) error {
+		if err := tx.queries.DeleteTaskLabels(ctx, taskID); err != nil {
+			return err
+		}
+		for _, label := range labels {
+			if err := tx.queries.AddTaskLabel(ctx, db.AddTaskLabelParams{TaskID: taskID, Label: label}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListTaskLabels returns the labels of the given tasks, by task, in one
+// query.
+func (s *Store) ListTaskLabels(ctx context.Context, taskIDs []string) (map[string][]string, error) {
+	rows, err := s.queries.ListTaskLabels(ctx, taskIDs)
+	if err != nil {
+		return nil, err
+	}
+	labels := make(map[string][]string)
+	for _, r := range rows {
+		labels[r.TaskID] = append(labels[r.TaskID], r.Label)
+	}
+	return labels, nil
+}
+
+// ============ Trash ============
+
+// What the items in the trash are.
+const (
+	TrashTask    = "task"
+	TrashComment = "comment"
+	TrashProject = "project"
+)
+
+// trashTitleSize is how many characters of its title or content name a trash
+// item.
+const trashTitleSize = 100
+
+// SetTrashRetention sets how long deleted tasks, comments and projects stay
+// in the trash, restorable; 0 deletes them for good at once.
+func (s *Store) SetTrashRetention(d time.Duration) {
+	s.trashRetention = d
+}
+
+// TrashRetention returns how long deleted items stay in the trash.
+func (s *Store) TrashRetention() time.Duration {
+	return s.trashRetention
+}
+
+// trash keeps snap, the rows of the deleted item of kind, in the trash until
+// the retention ends, and drops the items whose retention has ended.
+func (s *Store) trash(ctx context.Context, kind, itemID, title string, snap db.Snapshot) error {
+	if _, err := s.queries.DeleteExpiredTrashItems(ctx); err != nil {
+		return err
+	}
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	title = strings.TrimSpace(title)
+	if i := strings.IndexByte(title, '\n'); i >= 0 {
+		title = title[:i]
+	}
+	if utf8.RuneCountInString(title) > trashTitleSize {
+		title = string([]rune(title)[:trashTitleSize])
+	}
+	_, err = s.queries.CreateTrashItem(ctx, db.CreateTrashItemParams{
+		ID:        uuid.New().String(),
+		Kind:      kind,
+		ItemID:    itemID,
+		Title:     title,
+		Snapshot:  string(data),
+		ExpiresAt: time.Now().UTC().Add(s.trashRetention).Truncate(time.Second),
+	})
+	return err
+}
+
+// ListTrashItems returns the items in the trash, of kind if not "", most
+// recently deleted first.
+func (s *Store) ListTrashItems(ctx context.Context, kind string) ([]db.ListTrashItemsRow, error) {
+	return s.queries.ListTrashItems(ctx, kind)
+}
+
+// GetTrashItem returns an item in the trash; expired ones are gone.
+func (s *Store) GetTrashItem(ctx context.Context, id string) (db.TrashItem, error) {
+	return s.queries.GetTrashItem(ctx, id)
+}
+
+// RestoreTrashItem puts a deleted item back as it was deleted, with what was
+// deleted along with it and the references to it, and takes it out of the
+// trash. See db.RestoreSnapshot for what can no longer be restored.
+func (s *Store) RestoreTrashItem(ctx context.Context, id string) (db.TrashItem, error) {
+	var item db.TrashItem
+	err := s.WithTx(ctx, func(tx *Store) error {
+		var err error
+		if item, err = tx.queries.GetTrashItem(ctx, id); err != nil {
+			return err
+		}
+		snap, err := db.ParseSnapshot(item.Snapshot)
+		if err != nil {
+			return err
+		}
+		if err := tx.queries.RestoreSnapshot(ctx, snap); err != nil {
+			return err
+		}
+		return tx.queries.DeleteTrashItem(ctx, id)
+	})
+	return item, err
+}
+
+// DeleteTrashItem deletes an item in the trash for good.
+func (s *Store) DeleteTrashItem(ctx context.Context, id string) error {
+	return s.queries.DeleteTrashItem(ctx, id)
+}
+
+// nullStrings converts ids to the parameters of a query on a nullable column.
+func nullStrings(ids []string) []sql.NullString {
+	params := make([]sql.NullString, len(ids))
+	for i, id := range ids {
+		params[i] = sql.NullString{String: id, Valid: true}
+	}
+	return params
 }
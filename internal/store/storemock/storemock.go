@@ -0,0 +1,72 @@
+// Package storemock provides mocks of the store interfaces for handler unit
+// tests. mocks.go is generated from internal/store/interfaces.go.
+package storemock
+
+//go:generate go run ./gen -src ../interfaces.go -out mocks.go
+
+// Store bundles one mock per domain so a single value satisfies any
+// combination of store interfaces a handler asks for. Configure behaviour
+// through the embedded mocks, e.g. m.TaskStore.GetTaskFunc = ...; call counts
+// are per domain, e.g. m.TaskStore.Calls("GetTask").
+type Store struct {
+	*AgentStore
+	*TaskStore
+	*AgentGroupStore
+	*NotificationStore
+	*TaskAttemptStore
+	*TaskResultStore
+	*TaskTriageStore
+	*TaskLinkStore
+	*PhaseStore
+	*StoryStore
+	*SubAgentStore
+	*EventStore
+	*SettingsStore
+	*BackupStore
+	*LeaseStore
+	*SecretStore
+	*ProgressEntryStore
+	*GatewayStore
+	*ExperimentStore
+	*ChangeRequestStore
+	*JiraLinkStore
+	*GitHubIssueLinkStore
+	*InboundEmailStore
+	*ProjectStore
+	*TrashStore
+	*CommentStore
+	*ChatStore
+}
+
+// New returns a Store with every domain mock allocated.
+func New() *Store {
+	return &Store{
+		AgentStore:           &AgentStore{},
+		TaskStore:            &TaskStore{},
+		AgentGroupStore:      &AgentGroupStore{},
+		NotificationStore:    &NotificationStore{},
+		TaskAttemptStore:     &TaskAttemptStore{},
+		TaskResultStore:      &TaskResultStore{},
+		TaskTriageStore:      &TaskTriageStore{},
+		TaskLinkStore:        &TaskLinkStore{},
+		PhaseStore:           &PhaseStore{},
+		StoryStore:           &StoryStore{},
+		SubAgentStore:        &SubAgentStore{},
+		EventStore:           &EventStore{},
+		SettingsStore:        &SettingsStore{},
+		BackupStore:          &BackupStore{},
+		LeaseStore:           &LeaseStore{},
+		SecretStore:          &SecretStore{},
+		ProgressEntryStore:   &ProgressEntryStore{},
+		GatewayStore:         &GatewayStore{},
+		ExperimentStore:      &ExperimentStore{},
+		ChangeRequestStore:   &ChangeRequestStore{},
+		JiraLinkStore:        &JiraLinkStore{},
+		GitHubIssueLinkStore: &GitHubIssueLinkStore{},
+		InboundEmailStore:    &InboundEmailStore{},
+		ProjectStore:         &ProjectStore{},
+		TrashStore:           &TrashStore{},
+		CommentStore:         &CommentStore{},
+		ChatStore:            &ChatStore{},
+	}
+}
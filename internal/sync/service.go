@@ -6,9 +6,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"sync/atomic"
 	"time"
 
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/leader"
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/openclaw"
 	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
 )
@@ -17,8 +19,10 @@ import (
 type SyncService struct {
 	store        *store.Store
 	configReader *openclaw.ConfigReader
+	leader       *leader.Elector
 	stopChan     chan struct{}
 	running      bool
+	lastRun      atomic.Int64 // when SyncOnce last started, unix nanoseconds
 }
 
 // NewSyncService creates a new sync service
@@ -33,6 +37,7 @@ func NewSyncService(st *store.Store, configReader *openclaw.ConfigReader) *SyncS
 // SyncOnce performs a one-time sync of agents from OpenClaw config to database
 func (s *SyncService) SyncOnce(ctx context.Context) error {
 	log.Println("Starting agent sync from OpenClaw config...")
+	s.lastRun.Store(time.Now().UnixNano())
 	
 	// Read agents from OpenClaw config
 	agents, err := s.configReader.ReadAgents()
@@ -61,6 +66,19 @@ func (s *SyncService) SyncOnce(ctx context.Context) error {
 	for _, agentConfig := range agents {
 		existing, exists := existingMap[agentConfig.ID]
 		
+		// A self-registered agent owns its record; the config must not overwrite it
+		if exists && existing.ManagedExternally {
+			log.Printf("⚠ Agent %s is in OpenClaw config but registered itself; skipping", agentConfig.ID)
+			delete(existingMap, agentConfig.ID)
+			continue
+		}
+		
+		// An agent being removed stays as it is until the removal is done
+		if exists && existing.Status.String == store.AgentStatusDeleting {
+			delete(existingMap, agentConfig.ID)
+			continue
+		}
+		
 		if !exists {
 			// Create new agent
 			if err := s.createAgent(ctx, agentConfig); err != nil {
@@ -89,6 +107,9 @@ func (s *SyncService) SyncOnce(ctx context.Context) error {
 	
 	// Mark orphaned agents (exist in DB but not in config)
 	for _, orphan := range existingMap {
+		if orphan.ManagedExternally {
+			continue
+		}
 		log.Printf("⚠ Agent %s exists in DB but not in OpenClaw config (orphaned)", orphan.ID)
 		// Optionally mark as orphaned or delete
 		// For now, we just log it
@@ -143,7 +164,7 @@ func (s *SyncService) updateAgent(ctx context.Context, agentConfig openclaw.Agen
 	}
 	
 	// UpdateAgentParams doesn't include workspace_path, agent_dir_path, or memory_md
-	// These are set during creation and shouldn't change during sync
+	// The paths are set during creation and shouldn't change during sync
 	// We update the fields that can change via UpdateAgent
 	_, err = s.store.UpdateAgent(ctx, db.UpdateAgentParams{
 		ID:               agentConfig.ID,
@@ -162,10 +183,17 @@ func (s *SyncService) updateAgent(ctx context.Context, agentConfig openclaw.Agen
 		CurrentTaskID:    existing.CurrentTaskID,    // Preserve existing task
 	})
 	
-	// Note: memory_md is not updated via UpdateAgent - it's only set during creation
-	// This is intentional to avoid overwriting runtime memory
+	if err != nil {
+		return err
+	}
 	
-	return err
+	// memory_md is a copy of the workspace's MEMORY.md, which the agent and
+	// the memory endpoints write; follow the file, never the other way round
+	if existing.MemoryMd.String != agentConfig.MemoryMD {
+		return s.store.UpdateAgentMemory(ctx, agentConfig.ID, agentConfig.MemoryMD)
+	}
+	
+	return nil
 }
 
 // needsUpdate checks if an agent needs to be updated
@@ -215,6 +243,11 @@ func (s *SyncService) needsUpdate(existing db.Agent, config openclaw.AgentConfig
 	return false
 }
 
+// SetLeader sets the leader elector; periodic syncs only run on the leader.
+func (s *SyncService) SetLeader(l *leader.Elector) {
+	s.leader = l
+}
+
 // StartPeriodicSync starts periodic syncing in the background
 func (s *SyncService) StartPeriodicSync(ctx context.Context, interval time.Duration) {
 	if s.running {
@@ -232,6 +265,9 @@ func (s *SyncService) StartPeriodicSync(ctx context.Context, interval time.Durat
 		for {
 			select {
 			case <-ticker.C:
+				if !s.leader.IsLeader() {
+					continue
+				}
 				if err := s.SyncOnce(ctx); err != nil {
 					log.Printf("Periodic sync error: %v", err)
 				}
@@ -257,6 +293,19 @@ func (s *SyncService) StopPeriodicSync() {
 	s.running = false
 }
 
+// Running reports whether the periodic sync is running.
+func (s *SyncService) Running() bool {
+	return s.running
+}
+
+// LastRun returns when agents were last synced, zero if never.
+func (s *SyncService) LastRun() time.Time {
+	if n := s.lastRun.Load(); n != 0 {
+		return time.Unix(0, n)
+	}
+	return time.Time{}
+}
+
 // Helper function to convert string to sql.NullString
 func toNullString(s string) sql.NullString {
 	if s == "" {
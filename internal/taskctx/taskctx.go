@@ -0,0 +1,116 @@
+// Package taskctx hands out task-scoped contexts for work that outlives the
+// request that started it, such as agent notifications sent in the
+// background and the callbacks run when they return. A scope keeps the
+// values of the context it was begun from (dry run, locale, ...) but not its
+// cancellation, so the work survives the request; it carries a deadline of
+// its own, and all scopes of a task are cancelled together when the task is
+// stopped or deleted, and those of an agent when the task is taken from it.
+package taskctx
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Causes a task's scopes are cancelled with; context.Cause tells them apart.
+var (
+	ErrTaskStopped    = errors.New("task stopped")
+	ErrTaskDeleted    = errors.New("task deleted")
+	ErrTaskReassigned = errors.New("task reassigned")
+)
+
+// Cancelled reports whether err is, or wraps, one of the causes above: the
+// work it came from was cancelled with its task's scopes rather than failing.
+func Cancelled(err error) bool {
+	return errors.Is(err, ErrTaskStopped) || errors.Is(err, ErrTaskDeleted) || errors.Is(err, ErrTaskReassigned)
+}
+
+// callbackTimeout bounds the work of a Detach context.
+const callbackTimeout = 30 * time.Second
+
+// Detach returns a context for recording the outcome of work done under ctx,
+// such as a send's callback: ctx's values, without its cancellation and with
+// a short deadline of its own, so the outcome of cancelled work is recorded
+// too.
+func Detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), callbackTimeout)
+}
+
+// Registry tracks the open scopes of each task.
+type Registry struct {
+	mu    sync.Mutex
+	tasks map[string]map[*scope]struct{}
+}
+
+type scope struct {
+	agentID string
+	cancel  context.CancelCauseFunc
+}
+
+// New returns an empty registry.
+func New() *Registry {
+	return &Registry{tasks: make(map[string]map[*scope]struct{})}
+}
+
+// Begin opens a scope for work on taskID for agentID, derived from parent,
+// that times out after timeout. release ends it and must be called once the
+// work is done.
+func (r *Registry) Begin(parent context.Context, taskID, agentID string, timeout time.Duration) (ctx context.Context, release func()) {
+	ctx, cancelCause := context.WithCancelCause(context.WithoutCancel(parent))
+	ctx, cancelTimeout := context.WithTimeout(ctx, timeout)
+	s := &scope{agentID: agentID, cancel: cancelCause}
+
+	r.mu.Lock()
+	if r.tasks[taskID] == nil {
+		r.tasks[taskID] = make(map[*scope]struct{})
+	}
+	r.tasks[taskID][s] = struct{}{}
+	r.mu.Unlock()
+
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			r.mu.Lock()
+			delete(r.tasks[taskID], s)
+			if len(r.tasks[taskID]) == 0 {
+				delete(r.tasks, taskID)
+			}
+			r.mu.Unlock()
+			cancelTimeout()
+			cancelCause(context.Canceled)
+		})
+	}
+}
+
+// Cancel cancels the open scopes of taskID for agentID, or for any agent if
+// agentID is "", with cause. It returns how many it cancelled per agent.
+func (r *Registry) Cancel(taskID, agentID string, cause error) map[string]int {
+	var cancelled []*scope
+	r.mu.Lock()
+	for s := range r.tasks[taskID] {
+		if agentID == "" || s.agentID == agentID {
+			cancelled = append(cancelled, s)
+			delete(r.tasks[taskID], s)
+		}
+	}
+	if len(r.tasks[taskID]) == 0 {
+		delete(r.tasks, taskID)
+	}
+	r.mu.Unlock()
+
+	counts := make(map[string]int)
+	for _, s := range cancelled {
+		s.cancel(cause)
+		counts[s.agentID]++
+	}
+	return counts
+}
+
+// Open returns how many scopes taskID has open.
+func (r *Registry) Open(taskID string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.tasks[taskID])
+}
@@ -0,0 +1,143 @@
+// Package taskrefs finds references to other tasks in free text such as task
+// descriptions and comments, and knows the formats tasks can be referred to
+// by. A reference is a full task ID; a short code, "#" followed by the first 8
+// characters of a task ID (e.g. #3f2a9c1d); or a short ID, the per-project
+// sequential identifier Mission Control gives every task (e.g. MC-142).
+package taskrefs
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ShortCodeLen is how many leading characters of a task ID make a short code.
+const ShortCodeLen = 8
+
+// DefaultPrefix prefixes the short IDs of tasks whose project has no key.
+const DefaultPrefix = "MC"
+
+var (
+	fullID    = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)
+	shortCode = regexp.MustCompile(`(?i)(?:^|[^\w&/])#([0-9a-f]{8})\b`)
+	// In text only upper-case prefixes count, so prose like "e-1" is left alone.
+	shortIDRef = regexp.MustCompile(`\b[A-Z][A-Z0-9]{1,9}-[1-9][0-9]*\b`)
+	shortID    = regexp.MustCompile(`(?i)^[a-z][a-z0-9]{1,9}-[1-9][0-9]*$`)
+	projectKey = regexp.MustCompile(`^[A-Z][A-Z0-9]{1,9}$`)
+)
+
+// Kind is the form a reference takes.
+type Kind int
+
+const (
+	RefID        Kind = iota // full task ID
+	RefShortCode             // #3f2a9c1d
+	RefShortID               // MC-142
+)
+
+// Ref is one reference found in text.
+type Ref struct {
+	// Value is a full task ID, an ID prefix (RefShortCode, without the "#")
+	// or a short ID, normalized for lookup.
+	Value string
+	Kind  Kind
+}
+
+// Find returns the distinct references in text, in order of appearance
+// (full IDs first, then short codes, then short IDs). Whether they name an
+// existing task is up to the caller.
+func Find(text string) []Ref {
+	seen := make(map[string]bool)
+	var refs []Ref
+	for _, m := range fullID.FindAllString(text, -1) {
+		id := strings.ToLower(m)
+		if !seen[id] {
+			seen[id] = true
+			refs = append(refs, Ref{Value: id})
+		}
+	}
+	for _, m := range shortCode.FindAllStringSubmatch(text, -1) {
+		code := strings.ToLower(m[1])
+		if !seen[code] && !coveredBy(code, seen) {
+			seen[code] = true
+			refs = append(refs, Ref{Value: code, Kind: RefShortCode})
+		}
+	}
+	// Blank out full IDs first: their all-digit groups would read as short IDs.
+	rest := fullID.ReplaceAllStringFunc(text, func(m string) string { return strings.Repeat(" ", len(m)) })
+	for _, m := range shortIDRef.FindAllString(rest, -1) {
+		if !seen[m] {
+			seen[m] = true
+			refs = append(refs, Ref{Value: m, Kind: RefShortID})
+		}
+	}
+	return refs
+}
+
+// ShortCode returns the short code for a task ID, without the leading "#".
+func ShortCode(taskID string) string {
+	if len(taskID) < ShortCodeLen {
+		return taskID
+	}
+	return taskID[:ShortCodeLen]
+}
+
+// IsShortID reports whether s has the form of a short ID, in any case.
+func IsShortID(s string) bool {
+	return shortID.MatchString(s)
+}
+
+// NormalizeShortID returns s in the stored form of short IDs (upper case).
+func NormalizeShortID(s string) string {
+	return strings.ToUpper(s)
+}
+
+// FormatShortID returns the short ID numbered n under prefix, e.g. MC-142.
+func FormatShortID(prefix string, n int64) string {
+	return prefix + "-" + strconv.FormatInt(n, 10)
+}
+
+// ValidKey reports whether key can prefix short IDs: 2-10 upper-case letters
+// and digits, starting with a letter.
+func ValidKey(key string) bool {
+	return projectKey.MatchString(key)
+}
+
+// DeriveKey suggests a project key from a project name: the initials of its
+// words ("Mission Control" -> MC), or the start of a single-word name
+// ("Payments" -> PAY). It returns "" when the name yields no valid key.
+func DeriveKey(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r))
+	})
+	var key string
+	if len(words) > 1 {
+		for _, w := range words {
+			key += w[:1]
+		}
+	} else if len(words) == 1 {
+		key = words[0]
+		if len(key) > 3 {
+			key = key[:3]
+		}
+	}
+	if len(key) > 10 {
+		key = key[:10]
+	}
+	key = strings.ToUpper(key)
+	if !ValidKey(key) {
+		return ""
+	}
+	return key
+}
+
+// coveredBy reports whether a full ID already found starts with code.
+func coveredBy(code string, seen map[string]bool) bool {
+	for id := range seen {
+		if len(id) > ShortCodeLen && strings.HasPrefix(id, code) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package taskscript
+
+import "sync"
+
+// Cache holds the scripts in settings compiled, so they are compiled when
+// saved rather than for every task. It is keyed by the stored scripts, so
+// scripts saved by another instance are compiled the first time they're run
+// here. A nil Cache compiles every time.
+type Cache struct {
+	mu       sync.Mutex
+	source   string
+	compiled *Compiled
+}
+
+// NewCache returns an empty cache.
+func NewCache() *Cache {
+	return &Cache{}
+}
+
+// Store records c as the compiled form of the scripts stored as source.
+func (c *Cache) Store(source string, compiled *Compiled) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.source, c.compiled = source, compiled
+}
+
+// Load returns the scripts stored as source compiled, compiling them if they
+// aren't what was last stored or loaded. Scripts that don't compile are
+// reported as failures when run; the error is for source that isn't scripts
+// at all.
+func (c *Cache) Load(source string) (*Compiled, error) {
+	if c != nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.compiled != nil && c.source == source {
+			return c.compiled, nil
+		}
+	}
+	set, err := Parse(source)
+	if err != nil {
+		return nil, err
+	}
+	compiled := set.compile()
+	if c != nil {
+		c.source, c.compiled = source, compiled
+	}
+	return compiled, nil
+}
@@ -0,0 +1,556 @@
+package taskscript
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxSource is the longest script accepted, in bytes.
+const maxSource = 64 << 10
+
+// Pos is a position in a script, 1-based.
+type Pos struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// SyntaxError is an error in the syntax of a script, or a name it doesn't
+// know.
+type SyntaxError struct {
+	Message string
+	Pos     Pos
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s (line %d, column %d)", e.Message, e.Pos.Line, e.Pos.Column)
+}
+
+// Token kinds.
+const (
+	tokEOF = iota
+	tokNewline
+	tokIdent
+	tokString
+	tokInt
+	tokPunct
+)
+
+type token struct {
+	kind int
+	text string // an identifier or punctuation as written, a string unquoted
+	pos  Pos
+}
+
+// lex splits src into tokens. Newlines end statements, except inside
+// parentheses and brackets; # and // start comments.
+func lex(src string) ([]token, error) {
+	var toks []token
+	line, col := 1, 1
+	depth := 0
+	i := 0
+	for i < len(src) {
+		r, _ := utf8.DecodeRuneInString(src[i:])
+		pos := Pos{line, col}
+		advance := func(n int) {
+			i += n
+			col += n
+		}
+		switch {
+		case r == '\n':
+			if depth == 0 {
+				toks = append(toks, token{kind: tokNewline, pos: pos})
+			}
+			i++
+			line, col = line+1, 1
+		case r == ' ' || r == '\t' || r == '\r':
+			advance(1)
+		case r == '#' || strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case r == '"' || r == '\'':
+			s, n, err := lexString(src[i:], r)
+			if err != nil {
+				return nil, &SyntaxError{err.Error(), pos}
+			}
+			toks = append(toks, token{kind: tokString, text: s, pos: pos})
+			i += n
+			col += utf8.RuneCountInString(src[i-n : i])
+		case r >= '0' && r <= '9':
+			j := i
+			for j < len(src) && src[j] >= '0' && src[j] <= '9' {
+				j++
+			}
+			toks = append(toks, token{kind: tokInt, text: src[i:j], pos: pos})
+			advance(j - i)
+		case r == '_' || unicode.IsLetter(r):
+			j := i
+			for j < len(src) {
+				r, size := utf8.DecodeRuneInString(src[j:])
+				if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				j += size
+			}
+			toks = append(toks, token{kind: tokIdent, text: src[i:j], pos: pos})
+			col += utf8.RuneCountInString(src[i:j])
+			i = j
+		default:
+			op := ""
+			for _, p := range []string{"==", "!=", "<=", ">=", "&&", "||"} {
+				if strings.HasPrefix(src[i:], p) {
+					op = p
+					break
+				}
+			}
+			if op == "" && strings.ContainsRune("(){}[],;=<>+-*!", r) {
+				op = string(r)
+			}
+			if op == "" {
+				return nil, &SyntaxError{fmt.Sprintf("unexpected %q", r), pos}
+			}
+			switch op {
+			case "(", "[":
+				depth++
+			case ")", "]":
+				if depth > 0 {
+					depth--
+				}
+			}
+			toks = append(toks, token{kind: tokPunct, text: op, pos: pos})
+			advance(len(op))
+		}
+	}
+	return append(toks, token{kind: tokEOF, pos: Pos{line, col}}), nil
+}
+
+// lexString reads a string literal quoted by quote at the start of src and
+// returns it unquoted, with the bytes it took.
+func lexString(src string, quote rune) (string, int, error) {
+	var b strings.Builder
+	for i := 1; i < len(src); {
+		r, size := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case r == quote:
+			return b.String(), i + size, nil
+		case r == '\n':
+			return "", 0, fmt.Errorf("unterminated string")
+		case r == '\\' && i+1 < len(src):
+			switch src[i+1] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case '\\', '"', '\'':
+				b.WriteByte(src[i+1])
+			default:
+				return "", 0, fmt.Errorf("unknown escape \\%c", src[i+1])
+			}
+			i += 2
+			continue
+		default:
+			b.WriteRune(r)
+		}
+		i += size
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+// Statements.
+type (
+	ifStmt struct {
+		cond      expr
+		then, els []stmt
+	}
+	assignStmt struct {
+		field string
+		value expr
+		pos   Pos
+	}
+	rejectStmt struct {
+		message expr
+		pos     Pos
+	}
+	stopStmt struct{}
+)
+
+type stmt interface{}
+
+// Expressions.
+type (
+	literal struct {
+		value value
+	}
+	ident struct {
+		name string
+		pos  Pos
+	}
+	unary struct {
+		op  string
+		x   expr
+		pos Pos
+	}
+	binary struct {
+		op   string
+		x, y expr
+		re   *regexp.Regexp // of matches with a literal pattern
+		pos  Pos
+	}
+	call struct {
+		fn   string
+		args []expr
+		pos  Pos
+	}
+	listExpr struct {
+		items []expr
+	}
+)
+
+type expr interface{}
+
+// functions are the functions scripts can call, by their number of
+// arguments.
+var functions = map[string]int{"lower": 1, "upper": 1, "trim": 1, "len": 1}
+
+var keywords = map[string]bool{
+	"if": true, "else": true, "reject": true, "stop": true,
+	"and": true, "or": true, "not": true, "in": true,
+	"contains": true, "startsWith": true, "endsWith": true, "matches": true,
+	"true": true, "false": true,
+}
+
+type parser struct {
+	toks []token
+	i    int
+}
+
+// Program is a compiled script.
+type Program struct {
+	stmts []stmt
+}
+
+// Compile parses a script, checking that it only reads and sets fields of
+// the task and calls known functions.
+func Compile(src string) (*Program, error) {
+	if len(src) > maxSource {
+		return nil, fmt.Errorf("script is longer than %d bytes", maxSource)
+	}
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	stmts, err := p.block(false)
+	if err != nil {
+		return nil, err
+	}
+	return &Program{stmts: stmts}, nil
+}
+
+func (p *parser) peek() token { return p.toks[p.i] }
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) is(text string) bool {
+	t := p.peek()
+	return (t.kind == tokPunct || t.kind == tokIdent) && t.text == text
+}
+
+func (p *parser) expect(text string) error {
+	if !p.is(text) {
+		return p.errorf("expected %q, found %s", text, describe(p.peek()))
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{fmt.Sprintf(format, args...), p.peek().pos}
+}
+
+func describe(t token) string {
+	switch t.kind {
+	case tokEOF:
+		return "end of script"
+	case tokNewline:
+		return "end of line"
+	case tokString:
+		return strconv.Quote(t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+func (p *parser) skipSeparators() {
+	for p.peek().kind == tokNewline || p.is(";") {
+		p.next()
+	}
+}
+
+// block parses statements up to the end of the script or, in braces, the
+// closing brace.
+func (p *parser) block(braced bool) ([]stmt, error) {
+	var stmts []stmt
+	for {
+		p.skipSeparators()
+		if braced && p.is("}") {
+			p.next()
+			return stmts, nil
+		}
+		if p.peek().kind == tokEOF {
+			if braced {
+				return nil, p.errorf("expected \"}\", found end of script")
+			}
+			return stmts, nil
+		}
+		s, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, s)
+		if t := p.peek(); t.kind != tokNewline && t.kind != tokEOF && !p.is(";") && !(braced && p.is("}")) {
+			return nil, p.errorf("expected end of statement, found %s", describe(t))
+		}
+	}
+}
+
+func (p *parser) statement() (stmt, error) {
+	t := p.peek()
+	switch {
+	case p.is("if"):
+		return p.ifStatement()
+	case p.is("reject"):
+		p.next()
+		msg, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		return &rejectStmt{message: msg, pos: t.pos}, nil
+	case p.is("stop"):
+		p.next()
+		return &stopStmt{}, nil
+	case t.kind == tokIdent && !keywords[t.text]:
+		p.next()
+		if !p.is("=") {
+			return nil, p.errorf("expected \"=\" after %s", t.text)
+		}
+		p.next()
+		f, ok := fields[t.text]
+		if !ok {
+			return nil, &SyntaxError{fmt.Sprintf("unknown field %s", t.text), t.pos}
+		}
+		if f.readOnly {
+			return nil, &SyntaxError{fmt.Sprintf("%s cannot be set", t.text), t.pos}
+		}
+		value, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		return &assignStmt{field: t.text, value: value, pos: t.pos}, nil
+	}
+	return nil, p.errorf("expected a statement, found %s", describe(t))
+}
+
+func (p *parser) ifStatement() (stmt, error) {
+	p.next()
+	cond, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	then, err := p.block(true)
+	if err != nil {
+		return nil, err
+	}
+	s := &ifStmt{cond: cond, then: then}
+	// else may start the next line
+	at := p.i
+	for p.peek().kind == tokNewline {
+		p.next()
+	}
+	if !p.is("else") {
+		p.i = at
+		return s, nil
+	}
+	p.next()
+	if p.is("if") {
+		elseIf, err := p.ifStatement()
+		if err != nil {
+			return nil, err
+		}
+		s.els = []stmt{elseIf}
+		return s, nil
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	if s.els, err = p.block(true); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Operators by precedence, lowest first.
+var precedence = [][]string{
+	{"or", "||"},
+	{"and", "&&"},
+	{"==", "!=", "<", "<=", ">", ">=", "in", "contains", "startsWith", "endsWith", "matches"},
+	{"+", "-"},
+	{"*"},
+}
+
+func (p *parser) expr() (expr, error) {
+	return p.binary(0)
+}
+
+func (p *parser) binary(level int) (expr, error) {
+	if level == len(precedence) {
+		return p.unary()
+	}
+	x, err := p.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := ""
+		for _, o := range precedence[level] {
+			if p.is(o) {
+				op = o
+				break
+			}
+		}
+		if op == "" {
+			return x, nil
+		}
+		pos := p.next().pos
+		y, err := p.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case "||":
+			op = "or"
+		case "&&":
+			op = "and"
+		}
+		b := &binary{op: op, x: x, y: y, pos: pos}
+		if lit, ok := y.(*literal); ok && op == "matches" {
+			pattern, ok := lit.value.(string)
+			if !ok {
+				return nil, &SyntaxError{"matches takes a string pattern", pos}
+			}
+			if b.re, err = regexp.Compile(pattern); err != nil {
+				return nil, &SyntaxError{"invalid pattern: " + err.Error(), pos}
+			}
+		}
+		x = b
+	}
+}
+
+func (p *parser) unary() (expr, error) {
+	if p.is("!") || p.is("not") || p.is("-") {
+		t := p.next()
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		op := t.text
+		if op == "not" {
+			op = "!"
+		}
+		return &unary{op: op, x: x, pos: t.pos}, nil
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokString:
+		return &literal{value: t.text}, nil
+	case tokInt:
+		n, err := strconv.ParseInt(t.text, 10, 64)
+		if err != nil {
+			return nil, &SyntaxError{"number out of range", t.pos}
+		}
+		return &literal{value: n}, nil
+	case tokIdent:
+		switch {
+		case t.text == "true" || t.text == "false":
+			return &literal{value: t.text == "true"}, nil
+		case p.is("("):
+			arity, ok := functions[t.text]
+			if !ok {
+				return nil, &SyntaxError{fmt.Sprintf("unknown function %s", t.text), t.pos}
+			}
+			args, err := p.list("(", ")")
+			if err != nil {
+				return nil, err
+			}
+			if len(args) != arity {
+				return nil, &SyntaxError{fmt.Sprintf("%s takes %d argument(s)", t.text, arity), t.pos}
+			}
+			return &call{fn: t.text, args: args, pos: t.pos}, nil
+		case keywords[t.text]:
+			return nil, &SyntaxError{fmt.Sprintf("unexpected %s", t.text), t.pos}
+		}
+		if _, ok := fields[t.text]; !ok {
+			return nil, &SyntaxError{fmt.Sprintf("unknown field %s", t.text), t.pos}
+		}
+		return &ident{name: t.text, pos: t.pos}, nil
+	case tokPunct:
+		switch t.text {
+		case "(":
+			x, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return x, nil
+		case "[":
+			p.i--
+			items, err := p.list("[", "]")
+			if err != nil {
+				return nil, err
+			}
+			return &listExpr{items: items}, nil
+		}
+	}
+	return nil, &SyntaxError{fmt.Sprintf("expected a value, found %s", describe(t)), t.pos}
+}
+
+// list parses expressions separated by commas between open and close.
+func (p *parser) list(open, close string) ([]expr, error) {
+	if err := p.expect(open); err != nil {
+		return nil, err
+	}
+	var items []expr
+	for !p.is(close) {
+		x, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, x)
+		if !p.is(",") {
+			break
+		}
+		p.next()
+	}
+	if err := p.expect(close); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,688 @@
+// Package taskscript runs the scripts admins write to route and check tasks
+// as they are created, kept in settings. A script reads and sets fields of
+// the new task, and may reject it:
+//
+//	# Infrastructure work goes to the ops agent, urgently
+//	if lower(title) contains "infra" {
+//	    agent = "ops-agent"
+//	    priority = 1
+//	} else if title matches "^(fix|bug)[: ]" {
+//	    group = "bugfixers"
+//	}
+//	if len(description) < 20 and parent == "" {
+//	    reject "Describe the task in a sentence or two"
+//	}
+//
+// Scripts have no loops and cannot reach anything but the task, and each
+// runs under a time and step limit, so a script cannot hold up task
+// creation.
+package taskscript
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// Timeout is how long one script may run.
+	Timeout = 50 * time.Millisecond
+	// maxSteps is how many expressions and statements one script may
+	// evaluate.
+	maxSteps = 10000
+	// maxString is the longest string a script may build, in bytes.
+	maxString = 1 << 20
+	// maxPattern is the longest pattern matches takes from a field.
+	maxPattern = 1024
+)
+
+// Task is a task being created, as scripts see it.
+type Task struct {
+	Title          string `json:"title"`
+	Description    string `json:"description"`
+	Priority       int    `json:"priority"` // 1 is the most urgent; 0 = not set
+	AgentID        string `json:"agent_id"`
+	GroupID        string `json:"group_id"`
+	ProjectID      string `json:"project_id"`
+	ParentTaskID   string `json:"parent_task_id"`
+	GitBranch      string `json:"git_branch"`
+	Model          string `json:"model"`
+	RequiresReview bool   `json:"requires_review"`
+	Reviewer       string `json:"reviewer_agent_id"`
+}
+
+// field is a field of the task scripts name.
+type field struct {
+	get      func(t *Task) value
+	set      func(t *Task, v value) error
+	readOnly bool
+}
+
+func stringField(p func(t *Task) *string) field {
+	return field{
+		get: func(t *Task) value { return *p(t) },
+		set: func(t *Task, v value) error {
+			s, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("needs a string, not %s", typeName(v))
+			}
+			*p(t) = s
+			return nil
+		},
+	}
+}
+
+// fields are the fields of the task scripts read and set, by name. Setting
+// agent takes the task off a group's queue, and setting group unassigns it.
+var fields = map[string]field{
+	"title":       stringField(func(t *Task) *string { return &t.Title }),
+	"description": stringField(func(t *Task) *string { return &t.Description }),
+	"project":     stringField(func(t *Task) *string { return &t.ProjectID }),
+	"git_branch":  stringField(func(t *Task) *string { return &t.GitBranch }),
+	"model":       stringField(func(t *Task) *string { return &t.Model }),
+	"reviewer":    stringField(func(t *Task) *string { return &t.Reviewer }),
+	"agent": {
+		get: func(t *Task) value { return t.AgentID },
+		set: func(t *Task, v value) error {
+			if err := stringField(func(t *Task) *string { return &t.AgentID }).set(t, v); err != nil {
+				return err
+			}
+			if t.AgentID != "" {
+				t.GroupID = ""
+			}
+			return nil
+		},
+	},
+	"group": {
+		get: func(t *Task) value { return t.GroupID },
+		set: func(t *Task, v value) error {
+			if err := stringField(func(t *Task) *string { return &t.GroupID }).set(t, v); err != nil {
+				return err
+			}
+			if t.GroupID != "" {
+				t.AgentID = ""
+			}
+			return nil
+		},
+	},
+	"priority": {
+		get: func(t *Task) value { return int64(t.Priority) },
+		set: func(t *Task, v value) error {
+			n, ok := v.(int64)
+			if !ok {
+				return fmt.Errorf("needs a number, not %s", typeName(v))
+			}
+			if n < 1 || n > 5 {
+				return fmt.Errorf("must be between 1 and 5, not %d", n)
+			}
+			t.Priority = int(n)
+			return nil
+		},
+	},
+	"requires_review": {
+		get: func(t *Task) value { return t.RequiresReview },
+		set: func(t *Task, v value) error {
+			b, ok := v.(bool)
+			if !ok {
+				return fmt.Errorf("needs true or false, not %s", typeName(v))
+			}
+			t.RequiresReview = b
+			return nil
+		},
+	},
+	"parent": {get: func(t *Task) value { return t.ParentTaskID }, readOnly: true},
+}
+
+// Script is a script as kept in settings. Scripts run in order; disabled
+// ones are kept but skipped.
+type Script struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	Source  string `json:"source"`
+}
+
+// Set is the scripts of the system, in the order they run.
+type Set struct {
+	Scripts []Script `json:"scripts"`
+}
+
+// Parse reads scripts stored as JSON; "" is no scripts.
+func Parse(s string) (Set, error) {
+	set := Set{Scripts: []Script{}}
+	if strings.TrimSpace(s) == "" {
+		return set, nil
+	}
+	if err := json.Unmarshal([]byte(s), &set); err != nil {
+		return set, fmt.Errorf("invalid task scripts: %w", err)
+	}
+	if set.Scripts == nil {
+		set.Scripts = []Script{}
+	}
+	return set, nil
+}
+
+// Validate checks that every script has a unique name and compiles.
+func (s Set) Validate() error {
+	_, err := s.Compile()
+	return err
+}
+
+// Compile checks the set as Validate does and compiles its enabled scripts,
+// once for all the tasks they are run on.
+func (s Set) Compile() (*Compiled, error) {
+	c := &Compiled{}
+	names := map[string]bool{}
+	for i, sc := range s.Scripts {
+		if strings.TrimSpace(sc.Name) == "" {
+			return nil, fmt.Errorf("script %d has no name", i)
+		}
+		if names[sc.Name] {
+			return nil, fmt.Errorf("script %s is listed twice", sc.Name)
+		}
+		names[sc.Name] = true
+		prog, err := Compile(sc.Source)
+		if err != nil {
+			return nil, fmt.Errorf("script %s: %w", sc.Name, err)
+		}
+		if sc.Enabled {
+			c.scripts = append(c.scripts, compiledScript{name: sc.Name, prog: prog})
+		}
+	}
+	return c, nil
+}
+
+// compile compiles the enabled scripts, keeping the errors of those that
+// don't compile to report when the set is run.
+func (s Set) compile() *Compiled {
+	c := &Compiled{}
+	for _, sc := range s.Scripts {
+		if sc.Enabled {
+			prog, err := Compile(sc.Source)
+			c.scripts = append(c.scripts, compiledScript{name: sc.Name, prog: prog, err: err})
+		}
+	}
+	return c
+}
+
+// Empty reports whether there are no scripts.
+func (s Set) Empty() bool {
+	return len(s.Scripts) == 0
+}
+
+// Change is a script that changed the task, and the fields it set.
+type Change struct {
+	Script string   `json:"script"`
+	Fields []string `json:"fields"`
+}
+
+// Rejection is a script that rejected the task, and why.
+type Rejection struct {
+	Script  string `json:"script"`
+	Message string `json:"message"`
+}
+
+// Failure is a script that failed, and how. Its changes are dropped.
+type Failure struct {
+	Script string `json:"script"`
+	Error  string `json:"error"`
+}
+
+// Outcome is what the scripts made of a task.
+type Outcome struct {
+	Task     Task       `json:"task"`
+	Changes  []Change   `json:"changes"`
+	Rejected *Rejection `json:"rejected,omitempty"`
+	Failures []Failure  `json:"failures"`
+}
+
+// Run compiles the enabled scripts and runs them on task, as Compiled.Run.
+// A script that fails to compile is skipped.
+func (s Set) Run(ctx context.Context, task Task) Outcome {
+	return s.compile().Run(ctx, task)
+}
+
+// Compiled is the enabled scripts of a Set, compiled.
+type Compiled struct {
+	scripts []compiledScript
+}
+
+type compiledScript struct {
+	name string
+	prog *Program
+	err  error // compiling it
+}
+
+// Empty reports whether there are no scripts to run.
+func (c *Compiled) Empty() bool {
+	return len(c.scripts) == 0
+}
+
+// Run runs the scripts on task in order, each on the task as the ones before
+// left it, until one rejects it or stops. A script that fails is skipped,
+// its changes dropped.
+func (c *Compiled) Run(ctx context.Context, task Task) Outcome {
+	out := Outcome{Task: task, Changes: []Change{}, Failures: []Failure{}}
+	for _, sc := range c.scripts {
+		if sc.err != nil {
+			out.Failures = append(out.Failures, Failure{Script: sc.name, Error: sc.err.Error()})
+			continue
+		}
+		res, err := sc.prog.Run(ctx, out.Task)
+		if err != nil {
+			out.Failures = append(out.Failures, Failure{Script: sc.name, Error: err.Error()})
+			continue
+		}
+		if res.Rejected {
+			out.Rejected = &Rejection{Script: sc.name, Message: res.Message}
+			return out
+		}
+		out.Task = res.Task
+		if len(res.Set) > 0 {
+			out.Changes = append(out.Changes, Change{Script: sc.name, Fields: res.Set})
+		}
+		if res.Stopped {
+			break
+		}
+	}
+	return out
+}
+
+// Result is the outcome of one script.
+type Result struct {
+	Task     Task
+	Set      []string // fields set, in the order first set
+	Rejected bool
+	Message  string // why it was rejected
+	Stopped  bool   // later scripts are not to run
+}
+
+// RuntimeError is an error running a script.
+type RuntimeError struct {
+	Message string
+	Pos     Pos
+}
+
+func (e *RuntimeError) Error() string {
+	if e.Pos.Line == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%s (line %d, column %d)", e.Message, e.Pos.Line, e.Pos.Column)
+}
+
+// errTimeout is returned for a script that ran out of time or steps.
+var errTimeout = errors.New("script took too long")
+
+// value is a string, int64, bool or []value.
+type value interface{}
+
+type machine struct {
+	ctx   context.Context
+	task  Task
+	set   []string
+	steps int
+}
+
+// signals ending a script early
+type (
+	rejected struct{ message string }
+	stopped  struct{}
+)
+
+// Run runs the program on task, with Timeout.
+func (p *Program) Run(ctx context.Context, task Task) (Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+	m := &machine{ctx: ctx, task: task}
+	err := m.block(p.stmts)
+	res := Result{Task: m.task, Set: m.set}
+	switch e := err.(type) {
+	case nil:
+	case *rejected:
+		res.Rejected, res.Message = true, e.message
+	case *stopped:
+		res.Stopped = true
+	default:
+		return Result{Task: task}, err
+	}
+	return res, nil
+}
+
+func (e *rejected) Error() string { return "rejected: " + e.message }
+func (e *stopped) Error() string  { return "stopped" }
+
+func (m *machine) step() error {
+	m.steps++
+	if m.steps > maxSteps {
+		return errTimeout
+	}
+	if m.steps%64 == 0 && m.ctx.Err() != nil {
+		return errTimeout
+	}
+	return nil
+}
+
+func (m *machine) block(stmts []stmt) error {
+	for _, s := range stmts {
+		if err := m.stmt(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *machine) stmt(s stmt) error {
+	if err := m.step(); err != nil {
+		return err
+	}
+	switch s := s.(type) {
+	case *ifStmt:
+		v, err := m.eval(s.cond)
+		if err != nil {
+			return err
+		}
+		b, ok := v.(bool)
+		if !ok {
+			return &RuntimeError{fmt.Sprintf("if needs true or false, not %s", typeName(v)), posOf(s.cond)}
+		}
+		if b {
+			return m.block(s.then)
+		}
+		return m.block(s.els)
+	case *assignStmt:
+		v, err := m.eval(s.value)
+		if err != nil {
+			return err
+		}
+		if err := fields[s.field].set(&m.task, v); err != nil {
+			return &RuntimeError{s.field + " " + err.Error(), s.pos}
+		}
+		for _, f := range m.set {
+			if f == s.field {
+				return nil
+			}
+		}
+		m.set = append(m.set, s.field)
+		return nil
+	case *rejectStmt:
+		v, err := m.eval(s.message)
+		if err != nil {
+			return err
+		}
+		msg, ok := v.(string)
+		if !ok {
+			return &RuntimeError{fmt.Sprintf("reject needs a string, not %s", typeName(v)), s.pos}
+		}
+		return &rejected{message: msg}
+	case *stopStmt:
+		return &stopped{}
+	}
+	return fmt.Errorf("unknown statement %T", s)
+}
+
+func (m *machine) eval(e expr) (value, error) {
+	if err := m.step(); err != nil {
+		return nil, err
+	}
+	switch e := e.(type) {
+	case *literal:
+		return e.value, nil
+	case *ident:
+		return fields[e.name].get(&m.task), nil
+	case *listExpr:
+		items := make([]value, len(e.items))
+		for i, x := range e.items {
+			v, err := m.eval(x)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = v
+		}
+		return items, nil
+	case *unary:
+		v, err := m.eval(e.x)
+		if err != nil {
+			return nil, err
+		}
+		switch e.op {
+		case "!":
+			if b, ok := v.(bool); ok {
+				return !b, nil
+			}
+		case "-":
+			if n, ok := v.(int64); ok {
+				return -n, nil
+			}
+		}
+		return nil, &RuntimeError{fmt.Sprintf("cannot apply %s to %s", e.op, typeName(v)), e.pos}
+	case *call:
+		v, err := m.eval(e.args[0])
+		if err != nil {
+			return nil, err
+		}
+		return callFunction(e, v)
+	case *binary:
+		return m.binary(e)
+	}
+	return nil, fmt.Errorf("unknown expression %T", e)
+}
+
+func callFunction(e *call, v value) (value, error) {
+	if e.fn == "len" {
+		switch v := v.(type) {
+		case string:
+			return int64(utf8.RuneCountInString(v)), nil
+		case []value:
+			return int64(len(v)), nil
+		}
+	} else if s, ok := v.(string); ok {
+		switch e.fn {
+		case "lower":
+			return strings.ToLower(s), nil
+		case "upper":
+			return strings.ToUpper(s), nil
+		case "trim":
+			return strings.TrimSpace(s), nil
+		}
+	}
+	return nil, &RuntimeError{fmt.Sprintf("%s cannot take %s", e.fn, typeName(v)), e.pos}
+}
+
+func (m *machine) binary(e *binary) (value, error) {
+	x, err := m.eval(e.x)
+	if err != nil {
+		return nil, err
+	}
+	// and, or: the right side only if needed
+	if e.op == "and" || e.op == "or" {
+		a, ok := x.(bool)
+		if !ok {
+			return nil, &RuntimeError{fmt.Sprintf("%s needs true or false, not %s", e.op, typeName(x)), e.pos}
+		}
+		if a == (e.op == "or") {
+			return a, nil
+		}
+		y, err := m.eval(e.y)
+		if err != nil {
+			return nil, err
+		}
+		b, ok := y.(bool)
+		if !ok {
+			return nil, &RuntimeError{fmt.Sprintf("%s needs true or false, not %s", e.op, typeName(y)), e.pos}
+		}
+		return b, nil
+	}
+	y, err := m.eval(e.y)
+	if err != nil {
+		return nil, err
+	}
+	mismatch := func() error {
+		return &RuntimeError{fmt.Sprintf("cannot use %s with %s and %s", e.op, typeName(x), typeName(y)), e.pos}
+	}
+
+	switch e.op {
+	case "==", "!=":
+		eq, ok := equal(x, y)
+		if !ok {
+			return nil, mismatch()
+		}
+		return eq == (e.op == "=="), nil
+	case "<", "<=", ">", ">=":
+		c, ok := compare(x, y)
+		if !ok {
+			return nil, mismatch()
+		}
+		switch e.op {
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		}
+		return c >= 0, nil
+	case "+":
+		switch a := x.(type) {
+		case int64:
+			if b, ok := y.(int64); ok {
+				return a + b, nil
+			}
+		case string:
+			if b, ok := y.(string); ok {
+				if len(a)+len(b) > maxString {
+					return nil, &RuntimeError{"string too long", e.pos}
+				}
+				return a + b, nil
+			}
+		}
+		return nil, mismatch()
+	case "-", "*":
+		a, ok1 := x.(int64)
+		b, ok2 := y.(int64)
+		if !ok1 || !ok2 {
+			return nil, mismatch()
+		}
+		if e.op == "-" {
+			return a - b, nil
+		}
+		return a * b, nil
+	case "contains":
+		return contains(x, y, mismatch)
+	case "in":
+		return contains(y, x, mismatch)
+	case "startsWith", "endsWith":
+		a, ok1 := x.(string)
+		b, ok2 := y.(string)
+		if !ok1 || !ok2 {
+			return nil, mismatch()
+		}
+		if e.op == "startsWith" {
+			return strings.HasPrefix(a, b), nil
+		}
+		return strings.HasSuffix(a, b), nil
+	case "matches":
+		s, ok1 := x.(string)
+		pattern, ok2 := y.(string)
+		if !ok1 || !ok2 {
+			return nil, mismatch()
+		}
+		re := e.re
+		if re == nil {
+			if len(pattern) > maxPattern {
+				return nil, &RuntimeError{"pattern too long", e.pos}
+			}
+			if re, err = regexp.Compile(pattern); err != nil {
+				return nil, &RuntimeError{"invalid pattern: " + err.Error(), e.pos}
+			}
+		}
+		return re.MatchString(s), nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", e.op)
+}
+
+// contains reports whether haystack, a string or list, contains needle.
+func contains(haystack, needle value, mismatch func() error) (value, error) {
+	switch h := haystack.(type) {
+	case string:
+		if n, ok := needle.(string); ok {
+			return strings.Contains(h, n), nil
+		}
+	case []value:
+		for _, item := range h {
+			if eq, ok := equal(item, needle); ok && eq {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return nil, mismatch()
+}
+
+// equal compares values of the same type; ok is false for different types.
+func equal(x, y value) (eq, ok bool) {
+	switch a := x.(type) {
+	case string:
+		b, ok := y.(string)
+		return ok && a == b, ok
+	case int64:
+		b, ok := y.(int64)
+		return ok && a == b, ok
+	case bool:
+		b, ok := y.(bool)
+		return ok && a == b, ok
+	}
+	return false, false
+}
+
+// compare orders numbers or strings; ok is false for anything else.
+func compare(x, y value) (int, bool) {
+	switch a := x.(type) {
+	case int64:
+		if b, ok := y.(int64); ok {
+			switch {
+			case a < b:
+				return -1, true
+			case a > b:
+				return 1, true
+			}
+			return 0, true
+		}
+	case string:
+		if b, ok := y.(string); ok {
+			return strings.Compare(a, b), true
+		}
+	}
+	return 0, false
+}
+
+func typeName(v value) string {
+	switch v.(type) {
+	case string:
+		return "a string"
+	case int64:
+		return "a number"
+	case bool:
+		return "true or false"
+	case []value:
+		return "a list"
+	}
+	return "nothing"
+}
+
+func posOf(e expr) Pos {
+	switch e := e.(type) {
+	case *ident:
+		return e.pos
+	case *unary:
+		return e.pos
+	case *binary:
+		return e.pos
+	case *call:
+		return e.pos
+	}
+	return Pos{}
+}
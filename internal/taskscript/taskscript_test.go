@@ -0,0 +1,298 @@
+package taskscript
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// run compiles src and runs it on task.
+func run(t *testing.T, src string, task Task) (Result, error) {
+	t.Helper()
+	prog, err := Compile(src)
+	if err != nil {
+		t.Fatalf("Compile(%q): %v", src, err)
+	}
+	return prog.Run(context.Background(), task)
+}
+
+func TestRunRoutes(t *testing.T) {
+	src := `
+		# Infrastructure work goes to the ops agent, urgently
+		if lower(title) contains "infra" {
+			agent = "ops"
+			priority = 1
+		} else if title matches "^(fix|bug)[: ]" {
+			group = "bugfixers"
+		}
+		else {
+			description = description + " (triaged)"; requires_review = true
+		}
+		// a comment
+		if len(description) < 20 and parent == "" {
+			reject "Describe the task in a sentence or two"
+		}`
+	tests := []struct {
+		task     Task
+		want     Task
+		set      string
+		rejected string
+	}{
+		{
+			task: Task{Title: "Resize the INFRA cluster", Description: "The nodes run out of memory", GroupID: "all"},
+			want: Task{Title: "Resize the INFRA cluster", Description: "The nodes run out of memory", AgentID: "ops", Priority: 1},
+			set:  "agent,priority",
+		},
+		{
+			task: Task{Title: "fix: login", Description: "Fails for SSO users", ParentTaskID: "p1", AgentID: "dev"},
+			want: Task{Title: "fix: login", Description: "Fails for SSO users", ParentTaskID: "p1", GroupID: "bugfixers"},
+			set:  "group",
+		},
+		{
+			task: Task{Title: "Write docs", Description: "For the API"},
+			want: Task{Title: "Write docs", Description: "For the API (triaged)", RequiresReview: true},
+			set:  "description,requires_review",
+		},
+		{
+			task:     Task{Title: "fix: it", Description: "Broken"},
+			rejected: "Describe the task in a sentence or two",
+		},
+	}
+	for _, tt := range tests {
+		res, err := run(t, src, tt.task)
+		if err != nil {
+			t.Errorf("%q: %v", tt.task.Title, err)
+			continue
+		}
+		if tt.rejected != "" {
+			if !res.Rejected || res.Message != tt.rejected {
+				t.Errorf("%q: %+v, want rejected with %q", tt.task.Title, res, tt.rejected)
+			}
+			continue
+		}
+		if res.Rejected || res.Task != tt.want || strings.Join(res.Set, ",") != tt.set {
+			t.Errorf("%q: %+v setting %v, want %+v setting %s", tt.task.Title, res.Task, res.Set, tt.want, tt.set)
+		}
+	}
+}
+
+func TestRunExpressions(t *testing.T) {
+	task := Task{Title: "  Deploy v2  ", Priority: 3, Model: "opus"}
+	for _, cond := range []string{
+		`trim(title) == "Deploy v2"`,
+		`upper(model) == "OPUS"`,
+		`priority * 2 - 1 == 5`,
+		`priority + 1 > 3 && priority <= 3`,
+		`-priority < 0`,
+		`not (priority == 1) or false`,
+		`!requires_review`,
+		`model in ["sonnet", "opus"]`,
+		`["a", "b"] contains "b"`,
+		`trim(title) startsWith "Dep" and trim(title) endsWith "v2"`,
+		`"a" + "b" == "ab"`,
+		`"b" > "a"`,
+		`len(["x", 1, true]) == 3`,
+		`len("é€") == 2`,
+		`'single' == "single"`,
+		`"tab\there" contains "\t"`,
+		`model matches "^op"`,
+		`(priority == 3 or priority ==
+			4) and true`,
+	} {
+		res, err := run(t, `if `+cond+` { stop }`, task)
+		if err != nil || !res.Stopped {
+			t.Errorf("%s: %+v, %v; want true", cond, res, err)
+		}
+	}
+}
+
+func TestRunOnlyEvaluatesWhatItNeeds(t *testing.T) {
+	// The right side of and/or would fail if evaluated
+	res, err := run(t, `if false and len(1) == 1 { stop }
+		if true or len(1) == 1 { priority = 2 }`, Task{})
+	if err != nil || res.Task.Priority != 2 {
+		t.Errorf("%+v, %v", res, err)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{`title = `, "expected a value, found end of script (line 1, column 9)"},
+		{`owner = "me"`, "unknown field owner (line 1, column 1)"},
+		{`parent = "p1"`, "parent cannot be set (line 1, column 1)"},
+		{`if size > 3 { stop }`, "unknown field size (line 1, column 4)"},
+		{`if title { stop`, `expected "}", found end of script (line 1, column 16)`},
+		{`if true stop`, `expected "{", found "stop" (line 1, column 9)`},
+		{`title`, `expected "=" after title (line 1, column 6)`},
+		{`stop stop`, `expected end of statement, found "stop" (line 1, column 6)`},
+		{`title = shout(title)`, "unknown function shout (line 1, column 9)"},
+		{`title = lower(title, 1)`, "lower takes 1 argument(s) (line 1, column 9)"},
+		{`title = "open`, "unterminated string (line 1, column 9)"},
+		{`title = "\q"`, `unknown escape \q (line 1, column 9)`},
+		{`title = title @ 1`, `unexpected '@' (line 1, column 15)`},
+		{`priority = 99999999999999999999`, "number out of range (line 1, column 12)"},
+		{`if title matches 1 { stop }`, "matches takes a string pattern (line 1, column 10)"},
+		{`if title matches "(" { stop }`, "invalid pattern: error parsing regexp: missing closing ): `(` (line 1, column 10)"},
+		{"if true {\n  else = 1\n}", `expected a statement, found "else" (line 2, column 3)`},
+		{`title = and`, "unexpected and (line 1, column 9)"},
+		{strings.Repeat(" ", maxSource+1), "script is longer than 65536 bytes"},
+	}
+	for _, tt := range tests {
+		_, err := Compile(tt.src)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("Compile(%q) = %v, want %q", tt.src, err, tt.want)
+		}
+	}
+}
+
+func TestRunTypeErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{`priority = "high"`, "priority needs a number, not a string (line 1, column 1)"},
+		{`priority = 9`, "priority must be between 1 and 5, not 9 (line 1, column 1)"},
+		{`title = priority`, "title needs a string, not a number (line 1, column 1)"},
+		{`requires_review = "yes"`, "requires_review needs true or false, not a string (line 1, column 1)"},
+		{`if title { stop }`, "if needs true or false, not a string (line 1, column 4)"},
+		{`if title == 1 { stop }`, "cannot use == with a string and a number (line 1, column 10)"},
+		{`if title and true { stop }`, "and needs true or false, not a string (line 1, column 10)"},
+		{`if priority < "2" { stop }`, "cannot use < with a number and a string (line 1, column 13)"},
+		{`title = title - "x"`, "cannot use - with a string and a string (line 1, column 15)"},
+		{`if -title == "" { stop }`, "cannot apply - to a string (line 1, column 4)"},
+		{`if len(priority) > 1 { stop }`, "len cannot take a number (line 1, column 4)"},
+		{`reject priority`, "reject needs a string, not a number (line 1, column 1)"},
+		{`if title matches model { stop }`, "invalid pattern: error parsing regexp: missing closing ): `(` (line 1, column 10)"},
+	}
+	for _, tt := range tests {
+		_, err := run(t, tt.src, Task{Title: "T", Priority: 2, Model: "("})
+		var re *RuntimeError
+		if !errors.As(err, &re) || err.Error() != tt.want {
+			t.Errorf("%s: %v, want %q", tt.src, err, tt.want)
+		}
+	}
+}
+
+func TestRunLimits(t *testing.T) {
+	// Every statement and expression is a step: 2 to each line
+	_, err := run(t, strings.Repeat("priority=1\n", maxSteps/2+1), Task{})
+	if !errors.Is(err, errTimeout) {
+		t.Errorf("%d steps: %v, want %v", maxSteps+2, err, errTimeout)
+	}
+	if _, err := run(t, strings.Repeat("priority=1\n", maxSteps/2), Task{}); err != nil {
+		t.Errorf("%d steps: %v", maxSteps, err)
+	}
+
+	// Doubling the title from 1 byte passes maxString on the 21st line
+	_, err = run(t, strings.Repeat("title = title + title\n", 21), Task{Title: "x"})
+	if err == nil || err.Error() != "string too long (line 21, column 15)" {
+		t.Errorf("building a long string: %v", err)
+	}
+
+	if _, err := run(t, `if title matches model { stop }`, Task{Model: strings.Repeat("a", maxPattern+1)}); err == nil || !strings.HasPrefix(err.Error(), "pattern too long") {
+		t.Errorf("a long pattern: %v", err)
+	}
+
+	// A script out of time stops at the next check
+	prog, err := Compile(strings.Repeat("priority=1\n", 100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := prog.Run(ctx, Task{}); !errors.Is(err, errTimeout) {
+		t.Errorf("out of time: %v, want %v", err, errTimeout)
+	}
+}
+
+func TestSetRunsInOrder(t *testing.T) {
+	set := Set{Scripts: []Script{
+		{Name: "route", Enabled: true, Source: `agent = "ops"; title = "[ops] " + title`},
+		{Name: "off", Enabled: false, Source: `reject "disabled"`},
+		{Name: "broken", Enabled: true, Source: `priority = 1; title = 1`},
+		{Name: "urgent", Enabled: true, Source: `if title startsWith "[ops]" { priority = 1; stop }`},
+		{Name: "never", Enabled: true, Source: `reject "after stop"`},
+	}}
+	out := set.Run(context.Background(), Task{Title: "Deploy", Priority: 3})
+	if out.Rejected != nil {
+		t.Fatalf("rejected by %+v", out.Rejected)
+	}
+	if want := (Task{Title: "[ops] Deploy", AgentID: "ops", Priority: 1}); out.Task != want {
+		t.Errorf("task %+v, want %+v", out.Task, want)
+	}
+	if len(out.Changes) != 2 || out.Changes[0].Script != "route" || strings.Join(out.Changes[0].Fields, ",") != "agent,title" ||
+		out.Changes[1].Script != "urgent" {
+		t.Errorf("changes %+v", out.Changes)
+	}
+	// A failing script's changes are dropped
+	if len(out.Failures) != 1 || out.Failures[0].Script != "broken" {
+		t.Errorf("failures %+v", out.Failures)
+	}
+
+	out = Set{Scripts: []Script{
+		{Name: "retitle", Enabled: true, Source: `title = "Kept?"`},
+		{Name: "gate", Enabled: true, Source: `reject "frozen"`},
+	}}.Run(context.Background(), Task{Title: "Deploy"})
+	if out.Rejected == nil || *out.Rejected != (Rejection{Script: "gate", Message: "frozen"}) {
+		t.Errorf("rejected %+v", out.Rejected)
+	}
+}
+
+func TestSetCompile(t *testing.T) {
+	for _, tt := range []struct {
+		set  Set
+		want string
+	}{
+		{Set{Scripts: []Script{{Name: " ", Source: "stop"}}}, "script 0 has no name"},
+		{Set{Scripts: []Script{{Name: "a", Source: "stop"}, {Name: "a", Source: "stop"}}}, "script a is listed twice"},
+		// Disabled scripts must compile too, to be enabled later
+		{Set{Scripts: []Script{{Name: "a", Source: "title ="}}}, "script a: expected a value, found end of script (line 1, column 8)"},
+	} {
+		if _, err := tt.set.Compile(); err == nil || err.Error() != tt.want {
+			t.Errorf("Compile(%+v) = %v, want %q", tt.set, err, tt.want)
+		}
+	}
+
+	c, err := Set{Scripts: []Script{{Name: "a", Source: "stop"}}}.Compile()
+	if err != nil || !c.Empty() {
+		t.Errorf("only disabled scripts: %v, empty %v", err, c != nil && c.Empty())
+	}
+}
+
+func TestCacheCompilesOnce(t *testing.T) {
+	const stored = `{"scripts": [{"name": "a", "enabled": true, "source": "priority = 2"}]}`
+	c := NewCache()
+	first, err := c.Load(stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := c.Load(stored); again != first {
+		t.Error("the same scripts were compiled again")
+	}
+	if out := first.Run(context.Background(), Task{}); out.Task.Priority != 2 {
+		t.Errorf("ran to %+v", out.Task)
+	}
+
+	// Scripts saved here are stored compiled
+	saved := &Compiled{}
+	c.Store(`{"scripts": []}`, saved)
+	if got, _ := c.Load(`{"scripts": []}`); got != saved {
+		t.Error("saved scripts were compiled again")
+	}
+	// and others, as saved by another instance, compiled when loaded
+	if got, _ := c.Load(stored); got == saved || got.Empty() {
+		t.Error("changed scripts were not compiled")
+	}
+
+	if _, err := c.Load("not json"); err == nil {
+		t.Error("no error loading invalid scripts")
+	}
+	var none *Cache
+	if got, err := none.Load(stored); err != nil || got.Empty() {
+		t.Errorf("a nil cache: %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+// Package textdiff renders line diffs of small texts, such as an agent's
+// identity files, in unified diff format.
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxCells caps the size of the comparison table; texts larger than that are
+// diffed as a whole replacement.
+const maxCells = 4 << 20
+
+// op is one line of an edit script.
+type op struct {
+	kind byte // ' ' kept, '-' removed, '+' added
+	line string
+}
+
+// Unified returns the unified diff turning a into b, with context lines
+// around each change and the file names oldName and newName; "" if a and b
+// are the same.
+func Unified(oldName, newName, a, b string, context int) string {
+	if a == b {
+		return ""
+	}
+	ops := script(splitLines(a), splitLines(b))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		// A hunk runs from context lines before this change to context lines
+		// after the last change no more than 2*context lines further on
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				end = j
+			} else if j-end > 2*context {
+				break
+			}
+		}
+		stop := end + context + 1
+		if stop > len(ops) {
+			stop = len(ops)
+		}
+		oldStart, newStart := position(ops, start)
+		var oldCount, newCount int
+		for _, o := range ops[start:stop] {
+			if o.kind != '+' {
+				oldCount++
+			}
+			if o.kind != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", span(oldStart, oldCount), span(newStart, newCount))
+		for _, o := range ops[start:stop] {
+			out.WriteByte(o.kind)
+			out.WriteString(o.line)
+			out.WriteByte('\n')
+		}
+		i = stop
+	}
+	return out.String()
+}
+
+// Stats counts the lines added and removed turning a into b.
+func Stats(a, b string) (added, removed int) {
+	if a == b {
+		return 0, 0
+	}
+	for _, o := range script(splitLines(a), splitLines(b)) {
+		switch o.kind {
+		case '+':
+			added++
+		case '-':
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// script returns the shortest edit script turning a into b, from their
+// longest common subsequence.
+func script(a, b []string) []op {
+	if len(a)*len(b) > maxCells {
+		ops := make([]op, 0, len(a)+len(b))
+		for _, l := range a {
+			ops = append(ops, op{'-', l})
+		}
+		for _, l := range b {
+			ops = append(ops, op{'+', l})
+		}
+		return ops
+	}
+	// lcs[i][j] is the length of the LCS of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var ops []op
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, op{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, op{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, op{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, op{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, op{'+', b[j]})
+	}
+	return ops
+}
+
+// position returns the 1-based old and new line numbers ops[at] is on.
+func position(ops []op, at int) (int, int) {
+	oldLine, newLine := 1, 1
+	for _, o := range ops[:at] {
+		if o.kind != '+' {
+			oldLine++
+		}
+		if o.kind != '-' {
+			newLine++
+		}
+	}
+	return oldLine, newLine
+}
+
+// span formats a hunk range the way diff -u does.
+func span(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start-1)
+	case 1:
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// splitLines splits s into lines without their line breaks.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,175 @@
+// Package triage asks the triage agent (TRIAGE_AGENT_ID) what to do with a
+// task nobody was assigned: its priority and labels, the agent to work on
+// it, and whether to split it. The agent answers with JSON:
+//
+//	{
+//	  "priority": 2,
+//	  "labels": ["backend", "bug"],
+//	  "agent_id": "backend-dev",
+//	  "split": [{"title": "Add the migration", "description": "..."}],
+//	  "rationale": "A schema change and an API change; backend-dev owns both."
+//	}
+//
+// Every field may be left out.
+package triage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	maxLabels      = 10
+	maxLabelLength = 50
+	maxSplit       = 10
+)
+
+// Task is the task being triaged.
+type Task struct {
+	ID          string
+	Title       string
+	Description string
+	Priority    int
+	ProjectID   string
+}
+
+// Agent is an agent the task may go to.
+type Agent struct {
+	ID          string
+	Name        string
+	Description string
+}
+
+// Suggestion is what the triage agent suggests for a task.
+type Suggestion struct {
+	Priority  int       `json:"priority,omitempty"` // 1-5; 0 = keep
+	Labels    []string  `json:"labels,omitempty"`
+	AgentID   string    `json:"agent_id,omitempty"`
+	Split     []Subtask `json:"split,omitempty"`
+	Rationale string    `json:"rationale,omitempty"`
+}
+
+// Subtask is a subtask the task should be split into.
+type Subtask struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	AgentID     string `json:"agent_id,omitempty"` // default: the task's agent
+	Priority    int    `json:"priority,omitempty"` // default: the task's priority
+}
+
+// Empty reports whether nothing is suggested.
+func (s Suggestion) Empty() bool {
+	return s.Priority == 0 && len(s.Labels) == 0 && s.AgentID == "" && len(s.Split) == 0
+}
+
+// Prompt is the message asking the triage agent about task, which may go to
+// one of agents.
+func Prompt(task Task, agents []Agent) string {
+	var b strings.Builder
+	b.WriteString("You are triaging a new task in Mission Control. Nobody has been assigned to it yet.\n\n")
+	fmt.Fprintf(&b, "Task ID: %s\nTitle: %s\n", task.ID, task.Title)
+	if task.ProjectID != "" {
+		fmt.Fprintf(&b, "Project: %s\n", task.ProjectID)
+	}
+	if task.Priority > 0 {
+		fmt.Fprintf(&b, "Priority: %d\n", task.Priority)
+	}
+	if d := strings.TrimSpace(task.Description); d != "" {
+		fmt.Fprintf(&b, "\nDescription:\n%s\n", d)
+	}
+
+	b.WriteString("\nAgents who can take it:\n")
+	if len(agents) == 0 {
+		b.WriteString("- (none)\n")
+	}
+	for _, a := range agents {
+		fmt.Fprintf(&b, "- %s", a.ID)
+		if a.Name != "" && a.Name != a.ID {
+			fmt.Fprintf(&b, " (%s)", a.Name)
+		}
+		if d := strings.TrimSpace(a.Description); d != "" {
+			fmt.Fprintf(&b, ": %s", strings.Join(strings.Fields(d), " "))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(`
+Suggest a priority (1 is the most urgent, 5 the least), a few short labels, the agent best suited to the task, and, only if it is clearly too big for one agent, the subtasks to split it into. Answer with a single JSON object and nothing else:
+
+{"priority": 2, "labels": ["backend"], "agent_id": "<one of the agents above>", "split": [{"title": "...", "description": "...", "agent_id": "..."}], "rationale": "<one or two sentences>"}
+
+Leave out what you have no suggestion for.`)
+	return b.String()
+}
+
+// Parse reads the triage agent's reply, ignoring any text around the JSON
+// object. Agents not among agents, priorities out of range and labels
+// beyond the first ten are dropped.
+func Parse(reply string, agents []Agent) (Suggestion, error) {
+	var s Suggestion
+	start, end := strings.Index(reply, "{"), strings.LastIndex(reply, "}")
+	if start < 0 || end < start {
+		if strings.TrimSpace(reply) == "" {
+			return s, errors.New("the triage agent gave no answer")
+		}
+		return s, errors.New("no JSON object in the triage agent's answer")
+	}
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &s); err != nil {
+		return Suggestion{}, fmt.Errorf("invalid answer from the triage agent: %w", err)
+	}
+
+	known := make(map[string]bool, len(agents))
+	for _, a := range agents {
+		known[a.ID] = true
+	}
+	s.Priority = validPriority(s.Priority)
+	if !known[s.AgentID] {
+		s.AgentID = ""
+	}
+	s.Labels = cleanLabels(s.Labels)
+	s.Rationale = strings.TrimSpace(s.Rationale)
+
+	var split []Subtask
+	for _, sub := range s.Split {
+		sub.Title = strings.TrimSpace(sub.Title)
+		if sub.Title == "" {
+			continue
+		}
+		if !known[sub.AgentID] {
+			sub.AgentID = ""
+		}
+		sub.Priority = validPriority(sub.Priority)
+		if split = append(split, sub); len(split) == maxSplit {
+			break
+		}
+	}
+	s.Split = split
+	return s, nil
+}
+
+func validPriority(p int) int {
+	if p < 1 || p > 5 {
+		return 0
+	}
+	return p
+}
+
+// cleanLabels lowercases and trims labels, dropping empty, overlong and
+// repeated ones.
+func cleanLabels(labels []string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, l := range labels {
+		l = strings.ToLower(strings.TrimSpace(l))
+		if l == "" || len(l) > maxLabelLength || seen[l] {
+			continue
+		}
+		seen[l] = true
+		if out = append(out, l); len(out) == maxLabels {
+			break
+		}
+	}
+	return out
+}
@@ -0,0 +1,235 @@
+// Package updatecheck looks up the latest Mission Control release on GitHub
+// now and then and tells whether it is newer than the running version.
+package updatecheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// apiURL is the GitHub REST API.
+const apiURL = "https://api.github.com"
+
+// checkTimeout is how long a check may take.
+const checkTimeout = 30 * time.Second
+
+// Status is what the last check found.
+type Status struct {
+	CurrentVersion  string     `json:"current_version"`
+	LatestVersion   string     `json:"latest_version,omitempty"`
+	UpdateAvailable bool       `json:"update_available"`
+	ReleaseURL      string     `json:"release_url,omitempty"`
+	PublishedAt     *time.Time `json:"published_at,omitempty"`
+	CheckedAt       *time.Time `json:"checked_at,omitempty"` // nil until the first check
+	Error           string     `json:"error,omitempty"`      // of the last check
+}
+
+// Checker checks for a newer release when asked and, while running, every
+// interval.
+type Checker struct {
+	repo     string // owner/name
+	current  string
+	interval time.Duration
+	client   *http.Client
+
+	mu     sync.RWMutex
+	status Status
+
+	stopChan chan struct{}
+	running  bool
+}
+
+// New returns a checker comparing version, the running one, against the
+// latest release of repo (owner/name).
+func New(repo, version string, interval time.Duration) *Checker {
+	return &Checker{
+		repo:     repo,
+		current:  version,
+		interval: interval,
+		client:   &http.Client{Timeout: checkTimeout},
+		status:   Status{CurrentVersion: version},
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Status returns what the last check found.
+func (c *Checker) Status() Status {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.status
+}
+
+// release is a GitHub release, as far as a check needs it.
+type release struct {
+	TagName     string    `json:"tag_name"`
+	HTMLURL     string    `json:"html_url"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// Check looks up the latest release now.
+func (c *Checker) Check(ctx context.Context) (Status, error) {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	rel, err := c.latest(ctx)
+
+	now := time.Now().UTC()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status.CheckedAt = &now
+	if err != nil {
+		c.status.Error = err.Error()
+		return c.status, err
+	}
+	latest := strings.TrimPrefix(rel.TagName, "v")
+	published := rel.PublishedAt
+	c.status = Status{
+		CurrentVersion:  c.current,
+		LatestVersion:   latest,
+		UpdateAvailable: Newer(latest, c.current),
+		ReleaseURL:      rel.HTMLURL,
+		PublishedAt:     &published,
+		CheckedAt:       &now,
+	}
+	if c.status.UpdateAvailable {
+		log.Printf("[UpdateCheck] Mission Control %s is available (running %s): %s", latest, c.current, rel.HTMLURL)
+	}
+	return c.status, nil
+}
+
+// latest fetches the latest release of the repository, which GitHub takes
+// to be the newest that is neither a draft nor a pre-release.
+func (c *Checker) latest(ctx context.Context) (release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/repos/%s/releases/latest", apiURL, c.repo), nil)
+	if err != nil {
+		return release{}, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return release{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return release{}, fmt.Errorf("no releases found for %s", c.repo)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return release{}, fmt.Errorf("GitHub returned %s", resp.Status)
+	}
+	var rel release
+	if err := json.NewDecoder(resp.Body).Decode(&rel); err != nil {
+		return release{}, fmt.Errorf("invalid release: %w", err)
+	}
+	if rel.TagName == "" {
+		return release{}, fmt.Errorf("release without a tag")
+	}
+	return rel, nil
+}
+
+// Start checks now and then every interval until Stop is called or ctx is
+// done.
+func (c *Checker) Start(ctx context.Context) {
+	if c.running {
+		log.Println("[UpdateCheck] Already running")
+		return
+	}
+	c.running = true
+	log.Printf("[UpdateCheck] Checking %s for new releases every %s", c.repo, c.interval)
+
+	go func() {
+		c.runScheduled(ctx)
+
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				c.runScheduled(ctx)
+			case <-c.stopChan:
+				log.Println("[UpdateCheck] Stopping")
+				c.running = false
+				return
+			case <-ctx.Done():
+				c.running = false
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic checks.
+func (c *Checker) Stop() {
+	if !c.running {
+		return
+	}
+	close(c.stopChan)
+	c.running = false
+}
+
+// Running reports whether the periodic checks are running.
+func (c *Checker) Running() bool {
+	return c.running
+}
+
+// LastRun returns when the last check was, zero if none was.
+func (c *Checker) LastRun() time.Time {
+	if at := c.Status().CheckedAt; at != nil {
+		return *at
+	}
+	return time.Time{}
+}
+
+func (c *Checker) runScheduled(ctx context.Context) {
+	if _, err := c.Check(ctx); err != nil {
+		log.Printf("[UpdateCheck] Check failed: %v", err)
+	}
+}
+
+// Newer reports whether version a is newer than b, both major.minor.patch
+// with an optional leading v. A version with a pre-release suffix
+// (1.2.0-rc1) is older than the release. Versions that are not numbered
+// this way, such as dev builds, are never newer nor older.
+func Newer(a, b string) bool {
+	av, apre, ok := parse(a)
+	if !ok {
+		return false
+	}
+	bv, bpre, ok := parse(b)
+	if !ok {
+		return false
+	}
+	for i := range av {
+		if av[i] != bv[i] {
+			return av[i] > bv[i]
+		}
+	}
+	return apre == "" && bpre != ""
+}
+
+// parse splits a version into its numbers and pre-release suffix.
+func parse(v string) ([3]int, string, bool) {
+	var nums [3]int
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	v, _, _ = strings.Cut(v, "+") // build metadata doesn't order
+	v, pre, _ := strings.Cut(v, "-")
+	parts := strings.Split(v, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return nums, "", false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nums, "", false
+		}
+		nums[i] = n
+	}
+	return nums, pre, true
+}
@@ -0,0 +1,218 @@
+// Package validation checks API request bodies against the `validate` tags
+// of their fields, as echo's Validator, and reports every invalid field at
+// once:
+//
+//	{"errors": [{"field": "title", "message": "is required"}]}
+//
+// Fields are named by their JSON name. Rules are separated by commas:
+//
+//	required      not empty (strings: not blank); for pointers, not nil
+//	omitempty     skip the other rules when the field is empty
+//	oneof=a b c   one of the values listed (strings and ints)
+//	min=n, max=n  numbers: at least/most n; strings and slices: length
+//	rfc3339       a timestamp such as 2026-02-08T10:00:00Z
+//
+// Pointers are checked by what they point to, so a nil pointer, a field left
+// unchanged by an update, only fails required. Nested structs are not
+// descended into.
+package validation
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// FieldError is one invalid field of a request.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Errors lists the invalid fields of a request.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	parts := make([]string, len(e))
+	for i, fe := range e {
+		parts[i] = fe.Field + " " + fe.Message
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Response is the body of the 400 response to an invalid request.
+type Response struct {
+	Errors Errors `json:"errors"`
+}
+
+// Validator is the echo.Validator of the API.
+type Validator struct{}
+
+// New returns a Validator.
+func New() *Validator {
+	return &Validator{}
+}
+
+// Validate checks i, a struct or a pointer to one, and returns a 400
+// *echo.HTTPError with a Response if any field is invalid. Anything else,
+// such as a map, is valid.
+func (v *Validator) Validate(i interface{}) error {
+	if errs := Check(i); len(errs) > 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, Response{Errors: errs})
+	}
+	return nil
+}
+
+// Check returns the invalid fields of i, a struct or a pointer to one.
+func Check(i interface{}) Errors {
+	rv := reflect.ValueOf(i)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var errs Errors
+	rt := rv.Type()
+	for n := 0; n < rt.NumField(); n++ {
+		sf := rt.Field(n)
+		tag := sf.Tag.Get("validate")
+		if tag == "" || tag == "-" || !sf.IsExported() {
+			continue
+		}
+		if msg := checkField(rv.Field(n), strings.Split(tag, ",")); msg != "" {
+			errs = append(errs, FieldError{Field: fieldName(sf), Message: msg})
+		}
+	}
+	return errs
+}
+
+// fieldName is the JSON name of a field.
+func fieldName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return sf.Name
+	}
+	return name
+}
+
+// checkField applies rules to a field and returns what is wrong with it, or
+// "" if nothing is.
+func checkField(fv reflect.Value, rules []string) string {
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			for _, rule := range rules {
+				if rule == "required" {
+					return "is required"
+				}
+			}
+			return ""
+		}
+		fv = fv.Elem()
+	}
+
+	for _, rule := range rules {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "omitempty":
+			if isEmpty(fv) {
+				return ""
+			}
+		case "required":
+			if isEmpty(fv) {
+				return "is required"
+			}
+		case "oneof":
+			if msg := checkOneOf(fv, strings.Fields(arg)); msg != "" {
+				return msg
+			}
+		case "min", "max":
+			if msg := checkBound(fv, name, arg); msg != "" {
+				return msg
+			}
+		case "rfc3339":
+			if fv.Kind() == reflect.String {
+				if _, err := time.Parse(time.RFC3339, fv.String()); err != nil {
+					return "must be an RFC3339 timestamp, e.g. 2026-02-08T10:00:00Z"
+				}
+			}
+		default:
+			panic(fmt.Sprintf("validation: unknown rule %q", rule))
+		}
+	}
+	return ""
+}
+
+func isEmpty(fv reflect.Value) bool {
+	switch fv.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(fv.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return fv.Len() == 0
+	default:
+		return fv.IsZero()
+	}
+}
+
+func checkOneOf(fv reflect.Value, allowed []string) string {
+	var value string
+	switch fv.Kind() {
+	case reflect.String:
+		value = fv.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = strconv.FormatInt(fv.Int(), 10)
+	default:
+		return ""
+	}
+	for _, a := range allowed {
+		if value == a {
+			return ""
+		}
+	}
+	return "must be one of: " + strings.Join(allowed, ", ")
+}
+
+func checkBound(fv reflect.Value, name, arg string) string {
+	bound, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		panic(fmt.Sprintf("validation: invalid %s=%s", name, arg))
+	}
+	var value float64
+	unit := ""
+	switch fv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = float64(fv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value = float64(fv.Uint())
+	case reflect.Float32, reflect.Float64:
+		value = fv.Float()
+	case reflect.String:
+		value, unit = float64(len([]rune(fv.String()))), " characters"
+	case reflect.Slice, reflect.Map:
+		value, unit = float64(fv.Len()), " items"
+	default:
+		return ""
+	}
+	switch {
+	case name == "min" && value < bound:
+		if unit != "" {
+			return fmt.Sprintf("must have at least %s%s", arg, unit)
+		}
+		return "must be at least " + arg
+	case name == "max" && value > bound:
+		if unit != "" {
+			return fmt.Sprintf("must have at most %s%s", arg, unit)
+		}
+		return "must be at most " + arg
+	}
+	return ""
+}
@@ -0,0 +1,406 @@
+// Package warehouse exports denormalized snapshots of tasks, agents and
+// events as CSV, nightly, to object storage (local disk or an S3-compatible
+// bucket), so dashboards can be built without reading the production SQLite
+// file.
+//
+// Each run writes analytics/<YYYY-MM-DD>/tasks.csv and agents.csv, full
+// snapshots, and events-<first>-<last>.csv with the events recorded since the
+// previous run, named by their sequence numbers.
+package warehouse
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/db"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/leader"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/maintenance"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/objectstore"
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/store"
+	ws "github.com/abelkuruvilla/claw-agent-mission-control/internal/websocket"
+)
+
+// eventPage is how many events are read at a time.
+const eventPage = 1000
+
+// Prefix is the object storage folder exports are written under.
+const Prefix = "analytics/"
+
+// Report sums up an export.
+type Report struct {
+	Folder     string    `json:"folder"`
+	Files      []string  `json:"files"` // object keys written
+	Tasks      int       `json:"tasks"`
+	Agents     int       `json:"agents"`
+	Events     int       `json:"events"`
+	LastEvent  int64     `json:"last_event_seq"`
+	ExportedAt time.Time `json:"exported_at"`
+}
+
+// Exporter writes exports to its destination and, while running, once a
+// day at a set time (UTC).
+type Exporter struct {
+	store       *store.Store
+	hub         *ws.Hub
+	dest        objectstore.Store
+	at          time.Duration // time of day, since midnight UTC
+	maintenance *maintenance.Mode
+	leader      *leader.Elector
+
+	stopChan chan struct{}
+	running  bool
+}
+
+func NewExporter(st *store.Store, hub *ws.Hub, dest objectstore.Store, at time.Duration) *Exporter {
+	return &Exporter{
+		store:    st,
+		hub:      hub,
+		dest:     dest,
+		at:       at,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// ParseTimeOfDay reads a time of day such as "02:30" as the time since
+// midnight.
+func ParseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, want HH:MM", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// SetMaintenance sets the maintenance switch; scheduled exports are skipped
+// while it is on.
+func (e *Exporter) SetMaintenance(m *maintenance.Mode) {
+	e.maintenance = m
+}
+
+// SetLeader sets the leader elector; scheduled exports only run on the
+// leader.
+func (e *Exporter) SetLeader(l *leader.Elector) {
+	e.leader = l
+}
+
+// Destination returns where exports are written.
+func (e *Exporter) Destination() objectstore.Store {
+	return e.dest
+}
+
+// Schedule returns the time of day (UTC) exports run at, e.g. "02:00".
+func (e *Exporter) Schedule() string {
+	return time.Time{}.Add(e.at).Format("15:04")
+}
+
+// Export writes an export now. The event position is only advanced once all
+// files are written, so a failed export is repeated in full by the next.
+func (e *Exporter) Export(ctx context.Context) (Report, error) {
+	now := time.Now().UTC()
+	report := Report{Folder: Prefix + now.Format("2006-01-02"), Files: []string{}, ExportedAt: now}
+	settings, err := e.store.GetSettings(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	agents, err := e.store.ListAgents(ctx)
+	if err != nil {
+		return report, err
+	}
+	projects, err := e.store.ListProjects(ctx)
+	if err != nil {
+		return report, err
+	}
+	tasks, err := e.store.ListTasks(ctx)
+	if err != nil {
+		return report, err
+	}
+	agentNames := make(map[string]string, len(agents))
+	for _, a := range agents {
+		agentNames[a.ID] = a.Name
+	}
+	projectNames := make(map[string]string, len(projects))
+	for _, p := range projects {
+		projectNames[p.ID] = p.Name
+	}
+	taskByID := make(map[string]db.Task, len(tasks))
+	for _, t := range tasks {
+		taskByID[t.ID] = t
+	}
+
+	if report.Tasks, err = e.put(ctx, &report, "tasks.csv", func(w *csv.Writer) (int, error) {
+		return WriteTasks(w, tasks, agentNames, projectNames)
+	}); err != nil {
+		return report, err
+	}
+	if report.Agents, err = e.put(ctx, &report, "agents.csv", func(w *csv.Writer) (int, error) {
+		return writeAgents(w, agents, tasks)
+	}); err != nil {
+		return report, err
+	}
+
+	// Named after the events they hold, so exports run the same day add to
+	// the day's events instead of replacing them
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	report.Events, report.LastEvent, err = e.writeEvents(ctx, w, settings.AnalyticsExportSeq, taskByID, agentNames)
+	if err == nil {
+		w.Flush()
+		err = w.Error()
+	}
+	if err != nil {
+		return report, fmt.Errorf("events: %w", err)
+	}
+	if report.Events > 0 {
+		name := fmt.Sprintf("events-%d-%d.csv", settings.AnalyticsExportSeq+1, report.LastEvent)
+		key := path.Join(report.Folder, name)
+		if err := e.dest.Put(ctx, key, buf.Bytes(), "text/csv"); err != nil {
+			return report, fmt.Errorf("%s: %w", name, err)
+		}
+		report.Files = append(report.Files, key)
+	}
+
+	if err := e.store.SetAnalyticsExport(ctx, report.LastEvent, now); err != nil {
+		return report, err
+	}
+	log.Printf("[Warehouse] Exported %d task(s), %d agent(s) and %d event(s) to %s in %s",
+		report.Tasks, report.Agents, report.Events, report.Folder, e.dest)
+	e.logEvent(ctx, "analytics_exported",
+		fmt.Sprintf("Analytics export: %d task(s), %d agent(s), %d new event(s) written to %s in %s",
+			report.Tasks, report.Agents, report.Events, report.Folder, e.dest),
+		fmt.Sprintf(`{"folder":%q,"tasks":%d,"agents":%d,"events":%d,"last_event_seq":%d}`,
+			report.Folder, report.Tasks, report.Agents, report.Events, report.LastEvent))
+	return report, nil
+}
+
+// Start runs an export every day at the set time until Stop is called or
+// ctx is done.
+func (e *Exporter) Start(ctx context.Context) {
+	if e.running {
+		log.Println("[Warehouse] Already running")
+		return
+	}
+	e.running = true
+	log.Printf("[Warehouse] Exporting analytics to %s daily at %s UTC", e.dest, e.Schedule())
+
+	go func() {
+		timer := time.NewTimer(time.Until(e.next(time.Now())))
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-timer.C:
+				e.runScheduled(ctx)
+				timer.Reset(time.Until(e.next(time.Now())))
+			case <-e.stopChan:
+				log.Println("[Warehouse] Stopping")
+				e.running = false
+				return
+			case <-ctx.Done():
+				e.running = false
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the daily exports.
+func (e *Exporter) Stop() {
+	if !e.running {
+		return
+	}
+	close(e.stopChan)
+	e.running = false
+}
+
+// Running reports whether the daily export is running.
+func (e *Exporter) Running() bool {
+	return e.running
+}
+
+func (e *Exporter) runScheduled(ctx context.Context) {
+	if e.maintenance.Enabled() {
+		log.Println("[Warehouse] Maintenance mode, skipping export")
+		return
+	}
+	if !e.leader.IsLeader() {
+		return
+	}
+	if _, err := e.Export(ctx); err != nil {
+		log.Printf("[Warehouse] Export failed: %v", err)
+		e.logEvent(ctx, "analytics_export_failed", fmt.Sprintf("Analytics export to %s failed: %v", e.dest, err), "")
+	}
+}
+
+// next is the first scheduled time after now.
+func (e *Exporter) next(now time.Time) time.Time {
+	now = now.UTC()
+	t := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).Add(e.at)
+	if !t.After(now) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+// put writes a CSV file of the report's folder and returns how many rows it
+// has.
+func (e *Exporter) put(ctx context.Context, report *Report, name string, write func(*csv.Writer) (int, error)) (int, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	n, err := write(w)
+	if err == nil {
+		w.Flush()
+		err = w.Error()
+	}
+	key := path.Join(report.Folder, name)
+	if err == nil {
+		err = e.dest.Put(ctx, key, buf.Bytes(), "text/csv")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	report.Files = append(report.Files, key)
+	return n, nil
+}
+
+func (e *Exporter) logEvent(ctx context.Context, eventType, message, details string) {
+	event, err := e.store.CreateEvent(ctx, db.CreateEventParams{
+		Type:    eventType,
+		Message: message,
+		Details: sql.NullString{String: details, Valid: details != ""},
+	})
+	if err != nil {
+		log.Printf("[Warehouse] Failed to create event (%s): %v", eventType, err)
+		return
+	}
+	if e.hub != nil {
+		e.hub.BroadcastEvent(event)
+	}
+}
+
+// WriteTasks writes tasks as CSV, a header and a row per task, named by
+// agentNames and projectNames (ID -> name), and returns how many it wrote.
+// GET /api/v1/tasks/export writes the same columns.
+func WriteTasks(w *csv.Writer, tasks []db.Task, agentNames, projectNames map[string]string) (int, error) {
+	if err := w.Write([]string{
+		"id", "short_id", "title", "status", "priority", "project_id", "project_name", "agent_id", "agent_name",
+		"parent_task_id", "group_id", "model", "routed_model", "retry_count", "progress", "failure_reason",
+		"requires_review", "created_at", "updated_at", "started_at", "completed_at", "cycle_time_seconds",
+	}); err != nil {
+		return 0, err
+	}
+	for _, t := range tasks {
+		cycle := ""
+		if t.StartedAt.Valid && t.CompletedAt.Valid {
+			cycle = strconv.FormatInt(int64(t.CompletedAt.Time.Sub(t.StartedAt.Time).Seconds()), 10)
+		}
+		if err := w.Write([]string{
+			t.ID, t.ShortID.String, t.Title, t.Status.String, nullInt(t.Priority),
+			t.ProjectID.String, projectNames[t.ProjectID.String], t.AgentID.String, agentNames[t.AgentID.String],
+			t.ParentTaskID.String, t.GroupID.String, t.Model.String, t.RoutedModel.String,
+			strconv.FormatInt(t.RetryCount, 10), nullInt(t.Progress), t.FailureReason.String,
+			strconv.FormatBool(t.RequiresReview), nullTime(t.CreatedAt), nullTime(t.UpdatedAt),
+			nullTime(t.StartedAt), nullTime(t.CompletedAt), cycle,
+		}); err != nil {
+			return 0, err
+		}
+	}
+	return len(tasks), nil
+}
+
+func writeAgents(w *csv.Writer, agents []db.Agent, tasks []db.Task) (int, error) {
+	type counts struct{ total, active, done, failed int }
+	byAgent := make(map[string]*counts)
+	for _, t := range tasks {
+		if !t.AgentID.Valid {
+			continue
+		}
+		c := byAgent[t.AgentID.String]
+		if c == nil {
+			c = &counts{}
+			byAgent[t.AgentID.String] = c
+		}
+		c.total++
+		switch t.Status.String {
+		case "done":
+			c.done++
+		case "failed":
+			c.failed++
+		case "planning", "discussing", "executing", "verifying":
+			c.active++
+		}
+	}
+	if err := w.Write([]string{
+		"id", "name", "status", "model", "delivery_method", "managed_externally", "created_at", "updated_at",
+		"tasks_total", "tasks_active", "tasks_done", "tasks_failed",
+	}); err != nil {
+		return 0, err
+	}
+	for _, a := range agents {
+		c := byAgent[a.ID]
+		if c == nil {
+			c = &counts{}
+		}
+		if err := w.Write([]string{
+			a.ID, a.Name, a.Status.String, a.Model.String, a.DeliveryMethod.String,
+			strconv.FormatBool(a.ManagedExternally), nullTime(a.CreatedAt), nullTime(a.UpdatedAt),
+			strconv.Itoa(c.total), strconv.Itoa(c.active), strconv.Itoa(c.done), strconv.Itoa(c.failed),
+		}); err != nil {
+			return 0, err
+		}
+	}
+	return len(agents), nil
+}
+
+// writeEvents writes the events after seq and returns how many there were
+// and the last one's seq.
+func (e *Exporter) writeEvents(ctx context.Context, w *csv.Writer, seq int64, tasks map[string]db.Task, agentNames map[string]string) (int, int64, error) {
+	if err := w.Write([]string{
+		"seq", "id", "created_at", "type", "task_id", "task_title", "project_id", "agent_id", "agent_name", "message", "details",
+	}); err != nil {
+		return 0, seq, err
+	}
+	n := 0
+	for {
+		events, err := e.store.ListEventsAfter(ctx, seq, eventPage)
+		if err != nil {
+			return n, seq, err
+		}
+		for _, ev := range events {
+			task := tasks[ev.TaskID.String]
+			if err := w.Write([]string{
+				strconv.FormatInt(ev.Seq, 10), ev.ID, nullTime(ev.CreatedAt), ev.Type,
+				ev.TaskID.String, task.Title, task.ProjectID.String, ev.AgentID.String, agentNames[ev.AgentID.String],
+				ev.Message, ev.Details.String,
+			}); err != nil {
+				return n, seq, err
+			}
+			seq = ev.Seq
+			n++
+		}
+		if len(events) < eventPage {
+			return n, seq, nil
+		}
+	}
+}
+
+func nullTime(t sql.NullTime) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.UTC().Format(time.RFC3339)
+}
+
+func nullInt(i sql.NullInt64) string {
+	if !i.Valid {
+		return ""
+	}
+	return strconv.FormatInt(i.Int64, 10)
+}
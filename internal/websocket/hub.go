@@ -1,11 +1,14 @@
 package websocket
 
 import (
+	"context"
 	"encoding/json"
 	"log"
 	"sync"
 
 	"github.com/gorilla/websocket"
+
+	"github.com/abelkuruvilla/claw-agent-mission-control/internal/broker"
 )
 
 // Event types
@@ -16,6 +19,12 @@ const (
 	EventStoryUpdated = "story.updated"
 	EventNewEvent     = "event.new"
 	EventExecutionLog = "execution.log"
+	EventAgentRun     = "agent.run"
+
+	EventCommentNew      = "comment.new"
+	EventQueueUpdated    = "queue.updated"
+	EventApprovalPending = "approval.pending"
+	EventAgentHeartbeat  = "agent.heartbeat"
 )
 
 type Message struct {
@@ -35,6 +44,18 @@ type Hub struct {
 	register   chan *Client
 	unregister chan *Client
 	mu         sync.RWMutex
+
+	// Broadcasts are also sent to other instances through the broker
+	broker   broker.Broker
+	origin   string
+	outbound chan []byte
+}
+
+// envelope is a broadcast as sent through the broker, with the instance it
+// came from so that instance can skip its own.
+type envelope struct {
+	Origin  string          `json:"origin"`
+	Message json.RawMessage `json:"message"`
 }
 
 func NewHub() *Hub {
@@ -79,6 +100,25 @@ func (h *Hub) Run() {
 	}
 }
 
+// ClientCount returns how many WebSocket clients are connected to this
+// instance.
+func (h *Hub) ClientCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
+// SetBroker bridges the hub to the other instances through b until ctx is
+// done: broadcasts are published for them, and theirs are sent to this
+// hub's clients. origin tells this instance's broadcasts apart.
+func (h *Hub) SetBroker(ctx context.Context, b broker.Broker, origin string) {
+	h.broker = b
+	h.origin = origin
+	h.outbound = make(chan []byte, 256)
+	go h.publish(ctx)
+	go b.Subscribe(ctx, h.receive)
+}
+
 // Broadcast sends a message to all connected clients
 func (h *Hub) Broadcast(msg *Message) {
 	data, err := json.Marshal(msg)
@@ -87,6 +127,50 @@ func (h *Hub) Broadcast(msg *Message) {
 		return
 	}
 	h.broadcast <- data
+	if h.outbound != nil {
+		select {
+		case h.outbound <- data:
+		default:
+			log.Println("[Broker] Outbound queue full, broadcast not sent to other instances")
+		}
+	}
+}
+
+// publish sends queued broadcasts through the broker.
+func (h *Hub) publish(ctx context.Context) {
+	failing := false
+	for {
+		select {
+		case data := <-h.outbound:
+			env, err := json.Marshal(envelope{Origin: h.origin, Message: data})
+			if err != nil {
+				continue
+			}
+			// Log the first failure of a run only, not every broadcast
+			err = h.broker.Publish(ctx, env)
+			if err != nil && !failing {
+				log.Printf("[Broker] Failed to publish to %s: %v", h.broker, err)
+			} else if err == nil && failing {
+				log.Printf("[Broker] Publishing to %s again", h.broker)
+			}
+			failing = err != nil
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// receive sends a broadcast of another instance to this hub's clients.
+func (h *Hub) receive(data []byte) {
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		log.Printf("[Broker] Ignoring malformed message: %v", err)
+		return
+	}
+	if env.Origin == h.origin || len(env.Message) == 0 {
+		return
+	}
+	h.broadcast <- env.Message
 }
 
 // BroadcastAgentStatus sends agent status update
@@ -121,6 +205,72 @@ func (h *Hub) BroadcastEvent(event interface{}) {
 	})
 }
 
+// BroadcastAgentRun sends a chunk of output (or a final status) for a one-shot agent run
+func (h *Hub) BroadcastAgentRun(runID, agentID, status, output string) {
+	h.Broadcast(&Message{
+		Type: EventAgentRun,
+		Payload: map[string]interface{}{
+			"run_id":   runID,
+			"agent_id": agentID,
+			"status":   status,
+			"output":   output,
+		},
+	})
+}
+
+// BroadcastComment sends a new comment on a task; content is cut to the
+// comment's preview when truncated
+func (h *Hub) BroadcastComment(taskID, commentID, author, content string, truncated bool) {
+	h.Broadcast(&Message{
+		Type: EventCommentNew,
+		Payload: map[string]interface{}{
+			"task_id":    taskID,
+			"comment_id": commentID,
+			"author":     author,
+			"content":    content,
+			"truncated":  truncated,
+		},
+	})
+}
+
+// BroadcastQueueUpdated sends the depth of an agent's queue after it changed
+func (h *Hub) BroadcastQueueUpdated(agentID string, depth int64) {
+	h.Broadcast(&Message{
+		Type: EventQueueUpdated,
+		Payload: map[string]interface{}{
+			"agent_id": agentID,
+			"depth":    depth,
+		},
+	})
+}
+
+// BroadcastApprovalPending sends that a task waits on a person: kind is
+// "review" for a task awaiting its reviewer, "delegation" for a subtask of a
+// manually delegated task awaiting approval
+func (h *Hub) BroadcastApprovalPending(kind, taskID, parentTaskID, reviewer string) {
+	h.Broadcast(&Message{
+		Type: EventApprovalPending,
+		Payload: map[string]interface{}{
+			"kind":           kind,
+			"task_id":        taskID,
+			"parent_task_id": parentTaskID,
+			"reviewer":       reviewer,
+		},
+	})
+}
+
+// BroadcastAgentHeartbeat sends an agent's heartbeat
+func (h *Hub) BroadcastAgentHeartbeat(agentID, status string, taskID *string) {
+	h.Broadcast(&Message{
+		Type: EventAgentHeartbeat,
+		Payload: map[string]interface{}{
+			"agent_id": agentID,
+			"status":   status,
+			"task_id":  taskID,
+		},
+	})
+}
+
 // Client methods
 func (c *Client) readPump() {
 	defer func() {
@@ -0,0 +1,222 @@
+// Package workhours describes when an agent may be given work. A schedule is
+// a time zone plus weekly windows such as mon-fri 09:00-17:00; outside those
+// windows dispatch to the agent is deferred until the next window opens.
+package workhours
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata" // schedules name IANA zones; don't depend on the host's zoneinfo
+)
+
+// Window is a recurring block of working time. Days lists weekday
+// abbreviations (mon..sun); empty means every day. Start and End are HH:MM
+// in the schedule's time zone; End may be "24:00", and an End before Start
+// makes the window run past midnight into the next day.
+type Window struct {
+	Days  []string `json:"days,omitempty"`
+	Start string   `json:"start"`
+	End   string   `json:"end"`
+}
+
+// Schedule is an agent's working hours. A nil schedule, or one without
+// windows, is always open.
+type Schedule struct {
+	Timezone string   `json:"timezone,omitempty"` // IANA name; empty = UTC
+	Windows  []Window `json:"windows"`
+
+	loc *time.Location
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Parse decodes and validates a stored schedule. An empty string yields a
+// nil schedule.
+func Parse(raw string) (*Schedule, error) {
+	if strings.TrimSpace(raw) == "" || raw == "null" {
+		return nil, nil
+	}
+	var s Schedule
+	if err := json.Unmarshal([]byte(raw), &s); err != nil {
+		return nil, fmt.Errorf("invalid working hours: %w", err)
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (s *Schedule) validate() error {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return fmt.Errorf("unknown timezone %q", s.Timezone)
+	}
+	s.loc = loc
+	for i := range s.Windows {
+		w := &s.Windows[i]
+		for j, d := range w.Days {
+			d = strings.ToLower(strings.TrimSpace(d))
+			if len(d) > 3 {
+				d = d[:3]
+			}
+			if _, ok := weekdays[d]; !ok {
+				return fmt.Errorf("window %d: unknown day %q", i+1, w.Days[j])
+			}
+			w.Days[j] = d
+		}
+		start, err := parseClock(w.Start)
+		if err != nil {
+			return fmt.Errorf("window %d: start: %w", i+1, err)
+		}
+		end, err := parseClock(w.End)
+		if err != nil {
+			return fmt.Errorf("window %d: end: %w", i+1, err)
+		}
+		if start == end {
+			return fmt.Errorf("window %d: start and end are equal", i+1)
+		}
+	}
+	return nil
+}
+
+// parseClock parses HH:MM into an offset from midnight.
+func parseClock(v string) (time.Duration, error) {
+	var h, m int
+	if _, err := fmt.Sscanf(v, "%d:%d", &h, &m); err != nil || len(v) != 5 {
+		return 0, fmt.Errorf("%q is not HH:MM", v)
+	}
+	if h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("%q is not a valid time of day", v)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+func (s *Schedule) location() *time.Location {
+	if s.loc == nil {
+		s.loc = time.UTC
+		if loc, err := time.LoadLocation(s.Timezone); err == nil {
+			s.loc = loc
+		}
+	}
+	return s.loc
+}
+
+// interval is one concrete occurrence of a window.
+type interval struct{ start, end time.Time }
+
+// intervals returns every window occurrence starting on the day before t
+// through the following week, which covers overnight windows and the
+// longest possible gap between windows.
+func (s *Schedule) intervals(t time.Time) []interval {
+	loc := s.location()
+	local := t.In(loc)
+	var out []interval
+	for offset := -1; offset <= 7; offset++ {
+		day := time.Date(local.Year(), local.Month(), local.Day()+offset, 0, 0, 0, 0, loc)
+		for _, w := range s.Windows {
+			if !w.on(day.Weekday()) {
+				continue
+			}
+			start, _ := parseClock(w.Start)
+			end, _ := parseClock(w.End)
+			if end < start {
+				end += 24 * time.Hour
+			}
+			// Build from wall-clock fields so DST changes shift the window, not the hours.
+			out = append(out, interval{
+				start: time.Date(day.Year(), day.Month(), day.Day(), 0, int(start/time.Minute), 0, 0, loc),
+				end:   time.Date(day.Year(), day.Month(), day.Day(), 0, int(end/time.Minute), 0, 0, loc),
+			})
+		}
+	}
+	return out
+}
+
+func (w Window) on(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if weekdays[d] == day {
+			return true
+		}
+	}
+	return false
+}
+
+// Open reports whether t falls inside a working window.
+func (s *Schedule) Open(t time.Time) bool {
+	if s == nil || len(s.Windows) == 0 {
+		return true
+	}
+	for _, iv := range s.intervals(t) {
+		if !t.Before(iv.start) && t.Before(iv.end) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOpen returns t if the schedule is open at t, otherwise the start of
+// the next working window.
+func (s *Schedule) NextOpen(t time.Time) time.Time {
+	if s.Open(t) {
+		return t
+	}
+	var next time.Time
+	for _, iv := range s.intervals(t) {
+		if iv.start.After(t) && (next.IsZero() || iv.start.Before(next)) {
+			next = iv.start
+		}
+	}
+	return next
+}
+
+// Closes returns when the window t falls in ends, running on through any
+// windows that overlap or adjoin it, and false if t is outside every window.
+func (s *Schedule) Closes(t time.Time) (time.Time, bool) {
+	if s == nil || len(s.Windows) == 0 {
+		return time.Time{}, false
+	}
+	ivs := s.intervals(t)
+	end, inside := t, false
+	for extended := true; extended; {
+		extended = false
+		for _, iv := range ivs {
+			if !end.Before(iv.start) && end.Before(iv.end) {
+				end, inside, extended = iv.end, true, true
+			}
+		}
+	}
+	return end, inside
+}
+
+// Defer reports whether dispatch at t must wait and, if so, until when.
+func (s *Schedule) Defer(t time.Time) (time.Time, bool) {
+	if s.Open(t) {
+		return time.Time{}, false
+	}
+	return s.NextOpen(t), true
+}
+
+// String summarizes the schedule for event messages, e.g.
+// "mon,tue,wed,thu,fri 09:00-17:00 (America/New_York)".
+func (s *Schedule) String() string {
+	if s == nil || len(s.Windows) == 0 {
+		return "always"
+	}
+	parts := make([]string, len(s.Windows))
+	for i, w := range s.Windows {
+		days := "daily"
+		if len(w.Days) > 0 {
+			days = strings.Join(w.Days, ",")
+		}
+		parts[i] = fmt.Sprintf("%s %s-%s", days, w.Start, w.End)
+	}
+	return fmt.Sprintf("%s (%s)", strings.Join(parts, "; "), s.location())
+}
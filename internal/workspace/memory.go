@@ -0,0 +1,261 @@
+package workspace
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MemoryFile is the agent's long-term memory, at the workspace root; daily
+// notes are memory/YYYY-MM-DD.md.
+const MemoryFile = "MEMORY.md"
+
+// memoryDir holds the daily notes.
+const memoryDir = "memory"
+
+// dateLayout is the date a daily note is named after.
+const dateLayout = "2006-01-02"
+
+// commitIdentity is who authors and commits the edits made through Mission
+// Control.
+var commitIdentity = []string{
+	"GIT_AUTHOR_NAME=Mission Control", "GIT_AUTHOR_EMAIL=mission-control@localhost",
+	"GIT_COMMITTER_NAME=Mission Control", "GIT_COMMITTER_EMAIL=mission-control@localhost",
+}
+
+var (
+	// ErrBadName is returned for a memory name that is neither MEMORY.md nor
+	// a YYYY-MM-DD date.
+	ErrBadName = errors.New("memory must be MEMORY.md or a YYYY-MM-DD daily note")
+	// ErrConflict is returned when a memory file changed since the revision
+	// an edit was based on.
+	ErrConflict = errors.New("memory file changed since it was read")
+	// ErrTooLarge is returned for content over MaxFileSize.
+	ErrTooLarge = errors.New("content too large")
+)
+
+// Memory is a memory file of a workspace, without its content.
+type Memory struct {
+	Name       string    `json:"name"` // MEMORY.md or the note's date
+	Path       string    `json:"path"`
+	Size       int64     `json:"size"`
+	ModifiedAt time.Time `json:"modified_at"`
+}
+
+// MemoryContent is a memory file with its content. Revision identifies the
+// content, to base an edit on.
+type MemoryContent struct {
+	Memory
+	Content  string `json:"content"`
+	Revision string `json:"revision"` // "" for a file that does not exist yet
+}
+
+// MemoryPath returns the workspace-relative path of the memory name:
+// MEMORY.md, or a daily note by its date, with or without the .md.
+func MemoryPath(name string) (string, error) {
+	if name == MemoryFile {
+		return MemoryFile, nil
+	}
+	date := strings.TrimSuffix(name, ".md")
+	if _, err := time.Parse(dateLayout, date); err != nil {
+		return "", ErrBadName
+	}
+	return memoryDir + "/" + date + ".md", nil
+}
+
+// Revision returns the revision of content: a hash of it.
+func Revision(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:8])
+}
+
+// ListMemory returns the MEMORY.md of the workspace at root, nil if it has
+// none, and its daily notes, newest first.
+func ListMemory(root string) (*Memory, []Memory, error) {
+	var longTerm *Memory
+	if info, err := os.Stat(filepath.Join(root, MemoryFile)); err == nil && info.Mode().IsRegular() {
+		longTerm = &Memory{Name: MemoryFile, Path: MemoryFile, Size: info.Size(), ModifiedAt: info.ModTime().UTC()}
+	}
+	notes := []Memory{}
+	entries, err := os.ReadDir(filepath.Join(root, memoryDir))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, err
+	}
+	for _, e := range entries {
+		date := strings.TrimSuffix(e.Name(), ".md")
+		if !e.Type().IsRegular() || date == e.Name() {
+			continue
+		}
+		if _, err := time.Parse(dateLayout, date); err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		notes = append(notes, Memory{Name: date, Path: memoryDir + "/" + e.Name(), Size: info.Size(), ModifiedAt: info.ModTime().UTC()})
+	}
+	sort.Slice(notes, func(i, j int) bool { return notes[i].Name > notes[j].Name })
+	return longTerm, notes, nil
+}
+
+// ReadMemory returns the memory name of the workspace at root, whole. It
+// returns ErrNotFound if there is no such file.
+func ReadMemory(root, name string) (MemoryContent, error) {
+	rel, err := MemoryPath(name)
+	if err != nil {
+		return MemoryContent{}, err
+	}
+	path, err := writablePath(root, rel)
+	if err != nil {
+		return MemoryContent{}, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return MemoryContent{}, ErrNotFound
+	}
+	if err != nil {
+		return MemoryContent{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return MemoryContent{}, err
+	}
+	return MemoryContent{
+		Memory:   Memory{Name: memoryName(rel), Path: rel, Size: info.Size(), ModifiedAt: info.ModTime().UTC()},
+		Content:  string(data),
+		Revision: Revision(data),
+	}, nil
+}
+
+// WriteMemory replaces the memory name of the workspace at root with
+// content, creating it and memory/ as needed. The file must still be at
+// revision, "" meaning it must not exist yet, or ErrConflict is returned so
+// the edit does not clobber what the agent wrote in the meantime. The file is
+// replaced atomically.
+func WriteMemory(root, name, content, revision string) (MemoryContent, error) {
+	if len(content) > MaxFileSize {
+		return MemoryContent{}, ErrTooLarge
+	}
+	rel, err := MemoryPath(name)
+	if err != nil {
+		return MemoryContent{}, err
+	}
+	path, err := writablePath(root, rel)
+	if err != nil {
+		return MemoryContent{}, err
+	}
+	current, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		if revision != "" {
+			return MemoryContent{}, ErrConflict
+		}
+	case err != nil:
+		return MemoryContent{}, err
+	case Revision(current) != revision:
+		return MemoryContent{}, ErrConflict
+	}
+
+	if err := writeAtomic(path, content); err != nil {
+		return MemoryContent{}, err
+	}
+	return ReadMemory(root, name)
+}
+
+// WriteFile replaces the file at rel of the workspace at root with content,
+// atomically, creating its directory as needed.
+func WriteFile(root, rel, content string) error {
+	if rel == "" || filepath.IsAbs(rel) || strings.Contains(filepath.ToSlash(rel), "..") {
+		return ErrOutside
+	}
+	path, err := writablePath(root, rel)
+	if err != nil {
+		return err
+	}
+	return writeAtomic(path, content)
+}
+
+// writeAtomic writes content to a temporary file next to path and renames it
+// over path, so readers never see a partial file.
+func writeAtomic(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// CommitFiles commits the files at rels of the workspace repository at root, and
+// only them, with message, as Mission Control. It returns the commit hash;
+// "" if root is not a git repository or the files were unchanged.
+func CommitFiles(ctx context.Context, root, message string, rels ...string) (string, error) {
+	if _, err := os.Stat(filepath.Join(root, ".git")); err != nil {
+		return "", nil
+	}
+	paths := append([]string{"--"}, rels...)
+	if _, err := git(ctx, root, append([]string{"add"}, paths...)...); err != nil {
+		return "", err
+	}
+	if status, err := git(ctx, root, append([]string{"status", "--porcelain"}, paths...)...); err != nil || status == "" {
+		return "", err
+	}
+	if _, err := gitEnv(ctx, root, commitIdentity, append([]string{"commit", "--quiet", "--only", "-m", message}, paths...)...); err != nil {
+		return "", err
+	}
+	return git(ctx, root, "rev-parse", "HEAD")
+}
+
+// writablePath returns the path of the file at rel, making sure neither its
+// directory nor the file is a symlink out of the workspace.
+func writablePath(root, rel string) (string, error) {
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", ErrNotFound
+	}
+	path := filepath.Join(realRoot, filepath.FromSlash(rel))
+	for _, p := range []string{filepath.Dir(path), path} {
+		real, err := filepath.EvalSymlinks(p)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if !inside(realRoot, real) {
+			return "", ErrOutside
+		}
+	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return "", ErrIsDir
+	}
+	return path, nil
+}
+
+// memoryName is the name of the memory file at rel.
+func memoryName(rel string) string {
+	if rel == MemoryFile {
+		return MemoryFile
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(rel, memoryDir+"/"), ".md")
+}
@@ -0,0 +1,303 @@
+// Package workspace lets operators look into an agent's workspace on disk:
+// its file tree, the text files in it (MEMORY.md, daily notes under memory/)
+// and the state of its git repository. Reads stay inside the workspace and
+// are size-limited.
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultDepth is how many directory levels a listing goes down by default.
+	DefaultDepth = 4
+	// MaxDepth caps the depth a listing may ask for.
+	MaxDepth = 10
+	// MaxEntries caps the entries of a listing; the rest is left out.
+	MaxEntries = 2000
+	// MaxFileSize is how many bytes of a file are returned at most.
+	MaxFileSize = 1 << 20
+	// DefaultLogSize is how many commits of the workspace repo are returned
+	// by default.
+	DefaultLogSize = 10
+	// MaxLogSize caps the commits returned.
+	MaxLogSize = 100
+)
+
+// gitTimeout bounds each git command run on a workspace.
+const gitTimeout = 10 * time.Second
+
+// TextExtensions are the extensions of the files whose content can be read.
+var TextExtensions = []string{".md", ".txt", ".json", ".jsonl", ".yaml", ".yml", ".toml", ".log", ".csv"}
+
+var (
+	// ErrNotFound is returned for a workspace or file that does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrOutside is returned for a path that leaves the workspace.
+	ErrOutside = errors.New("path must be inside the workspace")
+	// ErrNotText is returned for a file whose extension is not in TextExtensions.
+	ErrNotText = errors.New("not a text file")
+	// ErrIsDir is returned when a directory is read as a file.
+	ErrIsDir = errors.New("path is a directory")
+)
+
+// Entry is a file or directory of a workspace.
+type Entry struct {
+	Path       string    `json:"path"` // relative to the workspace, with forward slashes
+	Type       string    `json:"type"` // file | dir | symlink
+	Size       int64     `json:"size,omitempty"`
+	ModifiedAt time.Time `json:"modified_at"`
+	Readable   bool      `json:"readable,omitempty"` // its content can be fetched
+}
+
+// Tree is the listing of a workspace.
+type Tree struct {
+	Entries   []Entry `json:"entries"`
+	Truncated bool    `json:"truncated"` // MaxEntries reached
+}
+
+// File is the content of a workspace file.
+type File struct {
+	Path       string    `json:"path"`
+	Size       int64     `json:"size"`
+	ModifiedAt time.Time `json:"modified_at"`
+	Content    string    `json:"content"`
+	Truncated  bool      `json:"truncated"` // only the first MaxFileSize bytes are in content
+}
+
+// Resolve returns the absolute path of a workspace directory, expanding a
+// leading ~ to the home directory. It returns ErrNotFound if there is no
+// such directory.
+func Resolve(path string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(abs)
+	if err != nil || !info.IsDir() {
+		return "", ErrNotFound
+	}
+	return abs, nil
+}
+
+// Readable reports whether the content of the file name can be read.
+func Readable(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, e := range TextExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// List returns the entries of the workspace at root down to depth levels,
+// at most MaxEntries of them, in lexical order. The .git directory is left
+// out; symlinks are listed but not followed.
+func List(root string, depth int) (Tree, error) {
+	tree := Tree{Entries: []Entry{}}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if path == root {
+			return err
+		}
+		if err != nil {
+			// Unreadable entries are skipped, not fatal
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		if d.IsDir() && d.Name() == ".git" {
+			return fs.SkipDir
+		}
+		if len(tree.Entries) >= MaxEntries {
+			tree.Truncated = true
+			return fs.SkipAll
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		entry := Entry{Path: filepath.ToSlash(rel), ModifiedAt: info.ModTime().UTC()}
+		switch {
+		case d.Type()&fs.ModeSymlink != 0:
+			entry.Type = "symlink"
+		case d.IsDir():
+			entry.Type = "dir"
+		default:
+			entry.Type = "file"
+			entry.Size = info.Size()
+			entry.Readable = Readable(d.Name())
+		}
+		tree.Entries = append(tree.Entries, entry)
+		if d.IsDir() && strings.Count(entry.Path, "/")+1 >= depth {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	return tree, err
+}
+
+// ReadFile returns the content of the file at rel in the workspace at root,
+// up to MaxFileSize bytes. Only files with a TextExtensions extension can be
+// read, and the path, symlinks resolved, must stay inside the workspace.
+func ReadFile(root, rel string) (File, error) {
+	if rel == "" || filepath.IsAbs(rel) {
+		return File{}, ErrOutside
+	}
+	path := filepath.Join(root, filepath.FromSlash(rel))
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return File{}, ErrNotFound
+	}
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		if !inside(root, path) {
+			return File{}, ErrOutside
+		}
+		return File{}, ErrNotFound
+	}
+	if !inside(realRoot, real) {
+		return File{}, ErrOutside
+	}
+	info, err := os.Stat(real)
+	if err != nil {
+		return File{}, ErrNotFound
+	}
+	if info.IsDir() {
+		return File{}, ErrIsDir
+	}
+	if !Readable(real) {
+		return File{}, ErrNotText
+	}
+
+	f, err := os.Open(real)
+	if err != nil {
+		return File{}, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, MaxFileSize))
+	if err != nil {
+		return File{}, err
+	}
+	relPath, _ := filepath.Rel(realRoot, real)
+	return File{
+		Path:       filepath.ToSlash(relPath),
+		Size:       info.Size(),
+		ModifiedAt: info.ModTime().UTC(),
+		Content:    string(data),
+		Truncated:  info.Size() > MaxFileSize,
+	}, nil
+}
+
+// inside reports whether path is root or below it.
+func inside(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// Commit is a commit of a workspace repository.
+type Commit struct {
+	Hash    string    `json:"hash"`
+	Author  string    `json:"author"`
+	Date    time.Time `json:"date"`
+	Subject string    `json:"subject"`
+}
+
+// Change is a file changed in a workspace repository's working tree.
+type Change struct {
+	Path   string `json:"path"`
+	Status string `json:"status"` // git's two-letter porcelain code, e.g. " M" or "??"
+}
+
+// GitState is the state of a workspace repository.
+type GitState struct {
+	Branch  string   `json:"branch"`
+	Clean   bool     `json:"clean"`
+	Changes []Change `json:"changes"`
+	Log     []Commit `json:"log"`
+}
+
+// Git returns the status of the repository at root and its latest commits,
+// logSize of them; nil if root is not a git repository.
+func Git(ctx context.Context, root string, logSize int) (*GitState, error) {
+	if _, err := os.Stat(filepath.Join(root, ".git")); err != nil {
+		return nil, nil
+	}
+	status, err := git(ctx, root, "status", "--porcelain=v1", "--branch", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+	state := &GitState{Changes: []Change{}, Log: []Commit{}}
+	for _, line := range strings.Split(status, "\n") {
+		switch {
+		case strings.HasPrefix(line, "## "):
+			branch := strings.TrimPrefix(line, "## ")
+			branch = strings.TrimPrefix(branch, "No commits yet on ")
+			if i := strings.Index(branch, "..."); i >= 0 {
+				branch = branch[:i]
+			}
+			state.Branch, _, _ = strings.Cut(branch, " ")
+		case len(line) > 3:
+			state.Changes = append(state.Changes, Change{Path: line[3:], Status: line[:2]})
+		}
+	}
+	state.Clean = len(state.Changes) == 0
+
+	log, err := git(ctx, root, "log", fmt.Sprintf("--max-count=%d", logSize), "--pretty=format:%H%x1f%an%x1f%aI%x1f%s")
+	if err != nil {
+		// A repository without commits has no log
+		return state, nil
+	}
+	for _, line := range strings.Split(log, "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, fields[2])
+		state.Log = append(state.Log, Commit{Hash: fields[0], Author: fields[1], Date: date.UTC(), Subject: fields[3]})
+	}
+	return state, nil
+}
+
+// git runs a git command in dir and returns its output.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	return gitEnv(ctx, dir, nil, args...)
+}
+
+// gitEnv runs a git command in dir with env added to the environment and
+// returns its output.
+func gitEnv(ctx context.Context, dir string, env []string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, gitTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
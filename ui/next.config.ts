@@ -1,8 +1,17 @@
 import type { NextConfig } from "next";
 
+// Production builds are made for a placeholder base path, which the Go
+// server replaces with BASE_PATH as it serves the files, so one build works
+// at the root and under any sub-path behind a reverse proxy.
+const basePath = process.env.NODE_ENV === 'production' ? '/__MC_BASE_PATH__' : '';
+
 const nextConfig: NextConfig = {
   output: 'export', // Required for Go binary embedding
   trailingSlash: true, // Better compatibility with static hosting
+  basePath,
+  env: {
+    NEXT_PUBLIC_BASE_PATH: basePath, // for the API and WebSocket URLs
+  },
   images: {
     unoptimized: true,
   },
@@ -11,7 +11,9 @@ import { TaskModal } from '@/components/tasks/TaskModal';
 import { TaskDetailModal } from '@/components/tasks/TaskDetailModal';
 import { useTasksStore, useAgentsStore, useProjectsStore } from '@/stores';
 import { useIsMobile } from '@/hooks/useIsMobile';
-import type { Task, TaskStatus, Project } from '@/types';
+import { trashApi } from '@/services/api';
+import { toast } from 'sonner';
+import type { Task, TaskStatus, Project, TrashItem } from '@/types';
 
 const ITEM_TYPE = 'TASK';
 
@@ -347,7 +349,22 @@ export default function TasksPage() {
       console.error('Failed to delete task:', error);
       throw error;
     }
-  }, [deleteTask]);
+    // Offer to take it back out of the trash, if there is one
+    const { items } = await trashApi.list('task').catch(() => ({ items: [] as TrashItem[] }));
+    const trashed = items.find((item) => item.item_id === id);
+    if (trashed) {
+      toast('Task deleted', {
+        action: {
+          label: 'Undo',
+          onClick: () => {
+            trashApi.restore(trashed.id)
+              .then(() => fetchTasks())
+              .catch((err) => toast.error(err instanceof Error ? err.message : 'Failed to restore task'));
+          },
+        },
+      });
+    }
+  }, [deleteTask, fetchTasks]);
 
   const toggleSection = useCallback((status: TaskStatus) => {
     setExpandedSections(prev => {
@@ -17,6 +17,7 @@ import { Textarea } from '@/components/ui/textarea';
 import { ScrollArea } from '@/components/ui/scroll-area';
 import { Edit, Trash2, Bot, Save, X } from 'lucide-react';
 import { formatDistanceToNow } from 'date-fns';
+import { toast } from 'sonner';
 import { getAgentStatusColor } from '@/lib/status-utils';
 import type { Agent } from '@/types';
 import { AgentChat } from './AgentChat';
@@ -43,9 +44,17 @@ export function AgentDetailSheet({ agent, isOpen, onClose, onEdit }: AgentDetail
     : null;
 
   const handleDelete = async () => {
-    if (confirm(`Are you sure you want to delete ${agent.name}?`)) {
-      await deleteAgent(agent.id);
-      onClose();
+    const openTasks = agentTasks.filter(t => !['done', 'failed', 'cancelled'].includes(t.status));
+    const message = openTasks.length > 0
+      ? `Are you sure you want to delete ${agent.name}? Its ${openTasks.length} open task(s) will be unassigned and moved to the backlog.`
+      : `Are you sure you want to delete ${agent.name}?`;
+    if (confirm(message)) {
+      try {
+        await deleteAgent(agent.id, openTasks.length > 0 ? 'backlog' : 'block');
+        onClose();
+      } catch (err) {
+        toast.error(err instanceof Error ? err.message : 'Failed to delete agent');
+      }
     }
   };
 
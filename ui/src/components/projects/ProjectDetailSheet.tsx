@@ -17,6 +17,8 @@ import { ScrollArea } from '@/components/ui/scroll-area';
 import { Trash2, FolderKanban, Folder, Edit } from 'lucide-react';
 import { formatDistanceToNow } from 'date-fns';
 import { getProjectStatusColor } from '@/lib/status-utils';
+import { projectsApi } from '@/services/api';
+import { toast } from 'sonner';
 import type { Project } from '@/types';
 
 interface ProjectDetailSheetProps {
@@ -35,9 +37,25 @@ export function ProjectDetailSheet({ project, isOpen, onClose, onEdit }: Project
   const projectTasks = tasks.filter(t => t.project_id === project.id);
 
   const handleDelete = async () => {
-    if (confirm(`Are you sure you want to delete ${project.name}?`)) {
-      await deleteProject(project.id);
-      onClose();
+    try {
+      const impact = await projectsApi.deletionImpact(project.id);
+      const lost = [
+        impact.secrets > 0 && `${impact.secrets} secret(s)`,
+        impact.experiments > 0 && `${impact.experiments} experiment(s)`,
+      ].filter(Boolean);
+      let message = `Are you sure you want to delete ${project.name}?`;
+      if (impact.task_count > 0) {
+        message += ` Its ${impact.task_count} task(s) will be kept without a project.`;
+      }
+      if (lost.length > 0) {
+        message += ` Its ${lost.join(' and ')} will be deleted.`;
+      }
+      if (confirm(message)) {
+        await deleteProject(project.id, impact.tasks, impact.confirm_token);
+        onClose();
+      }
+    } catch (err) {
+      toast.error(err instanceof Error ? err.message : 'Failed to delete project');
     }
   };
 
@@ -50,6 +50,7 @@ import { TaskModal } from './TaskModal';
 import { useAgentsStore, useEventsStore } from '@/stores';
 import { useTasksStore } from '@/stores';
 import { tasksApi, commentsApi } from '@/services/api';
+import { COMMENT_EVENT } from '@/hooks/useWebSocket';
 import { SchedulePicker } from '@/components/ui/SchedulePicker';
 import { calculateProgress } from '@/lib/task-utils';
 import type { Task, Agent, TaskStatus, Comment } from '@/types';
@@ -295,6 +296,17 @@ export const TaskDetailModal: React.FC<TaskDetailModalProps> = ({
     }
   }, []);
 
+  // Refresh the comments of the open subtask as new ones come in
+  useEffect(() => {
+    if (!expandedSubtask) return;
+    const onComment = (e: Event) => {
+      const { task_id } = (e as CustomEvent<{ task_id?: string }>).detail ?? {};
+      if (task_id === expandedSubtask) fetchComments(expandedSubtask);
+    };
+    window.addEventListener(COMMENT_EVENT, onComment);
+    return () => window.removeEventListener(COMMENT_EVENT, onComment);
+  }, [expandedSubtask, fetchComments]);
+
   const handleApprove = async (subtaskId: string) => {
     setActionLoading(subtaskId);
     try {
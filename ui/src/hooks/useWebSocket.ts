@@ -4,6 +4,7 @@ import { useEffect, useRef, useCallback, useState } from 'react';
 import { useAgentsStore } from '@/stores/agents';
 import { useTasksStore } from '@/stores/tasks';
 import { useEventsStore } from '@/stores/events';
+import { BASE_PATH } from '@/services/api';
 
 interface WebSocketMessage {
   type: string;
@@ -11,6 +12,9 @@ interface WebSocketMessage {
   timestamp: string;
 }
 
+/** DOM event dispatched on window for each comment.new message */
+export const COMMENT_EVENT = 'mc:comment.new';
+
 /** Minimum interval (ms) between re-fetches for the same resource type */
 const DEBOUNCE_MS = 1000;
 
@@ -83,11 +87,20 @@ export function useWebSocket() {
         case 'agent.status':
           debouncedFetch('agents', () => fetchAgentsRef.current());
           break;
+        case 'agent.heartbeat':
+          debouncedFetch('agents', () => fetchAgentsRef.current());
+          break;
         case 'task.status':
         case 'phase.updated':
         case 'story.updated':
+        case 'queue.updated':
+        case 'approval.pending':
           debouncedFetch('tasks', () => fetchTasksRef.current());
           break;
+        case 'comment.new':
+          // Open views of the task pick the comment up themselves
+          window.dispatchEvent(new CustomEvent(COMMENT_EVENT, { detail: message.payload }));
+          break;
         case 'event.new': {
           // Events are lightweight - add directly without debounce
           const payload = message.payload as { type?: string; [k: string]: unknown };
@@ -111,7 +124,7 @@ export function useWebSocket() {
     if (wsRef.current?.readyState === WebSocket.OPEN) return;
 
     const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
-    const wsUrl = `${protocol}//${window.location.host}/ws`;
+    const wsUrl = `${protocol}//${window.location.host}${BASE_PATH}/ws`;
 
     try {
       console.log('[WebSocket] Connecting...');
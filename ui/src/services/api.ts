@@ -1,9 +1,12 @@
 import type { 
-  Agent, Task, Event, Settings, Project, Phase, Story,
+  Agent, Task, Event, Settings, Project, ProjectDeletion, TrashItem, Phase, Story,
   ApiResponse, ApiError, ChatSession, ChatMessage, Comment
 } from '@/types';
 
-const API_BASE = '/api/v1';
+/** Path the app is served under (BASE_PATH), '' at the root */
+export const BASE_PATH = process.env.NEXT_PUBLIC_BASE_PATH ?? '';
+
+const API_BASE = `${BASE_PATH}/api/v1`;
 
 async function handleResponse<T>(response: Response): Promise<T> {
   if (!response.ok) {
@@ -44,8 +47,13 @@ export const agentsApi = {
     return handleResponse<Agent>(res);
   },
   
-  delete: async (id: string): Promise<void> => {
-    await fetch(`${API_BASE}/agents/${id}`, { method: 'DELETE' });
+  // tasks: what happens to the agent's open tasks ('block' refuses to delete)
+  delete: async (id: string, tasks: 'block' | 'backlog' = 'block'): Promise<void> => {
+    const res = await fetch(`${API_BASE}/agents/${id}?tasks=${tasks}`, { method: 'DELETE' });
+    if (!res.ok) {
+      const error: ApiError = await res.json();
+      throw new Error(error.error?.message || 'API Error');
+    }
   },
 
   getQueue: async (id: string): Promise<{ agent_id: string; queue_depth: number; tasks: Task[] }> => {
@@ -231,8 +239,19 @@ export const projectsApi = {
     return handleResponse<Project>(res);
   },
   
-  delete: async (id: string): Promise<void> => {
-    await fetch(`${API_BASE}/projects/${id}`, { method: 'DELETE' });
+  deletionImpact: async (id: string, tasks: ProjectDeletion['tasks'] = 'orphan'): Promise<ProjectDeletion> => {
+    const res = await fetch(`${API_BASE}/projects/${id}?tasks=${tasks}&dry_run=true`, { method: 'DELETE' });
+    return handleResponse<ProjectDeletion>(res);
+  },
+
+  delete: async (id: string, tasks: ProjectDeletion['tasks'] = 'orphan', confirm?: string): Promise<void> => {
+    const params = new URLSearchParams({ tasks });
+    if (confirm) params.set('confirm', confirm);
+    const res = await fetch(`${API_BASE}/projects/${id}?${params}`, { method: 'DELETE' });
+    if (!res.ok) {
+      const error: ApiError = await res.json();
+      throw new Error(error.error?.message || 'API Error');
+    }
   },
 };
 
@@ -244,7 +263,7 @@ export const statusApi = {
   },
   
   health: async () => {
-    const res = await fetch('/health');
+    const res = await fetch(`${BASE_PATH}/health`);
     return handleResponse(res);
   },
 };
@@ -300,6 +319,23 @@ export const chatApi = {
   },
 };
 
+// Trash API: deleted tasks, comments and projects
+export const trashApi = {
+  list: async (kind?: TrashItem['kind']): Promise<{ items: TrashItem[]; retention_hours: number }> => {
+    const res = await fetch(`${API_BASE}/trash${kind ? `?kind=${kind}` : ''}`);
+    return handleResponse<{ items: TrashItem[]; retention_hours: number }>(res);
+  },
+
+  restore: async (id: string): Promise<TrashItem> => {
+    const res = await fetch(`${API_BASE}/trash/${id}/restore`, { method: 'POST' });
+    return handleResponse<TrashItem>(res);
+  },
+
+  delete: async (id: string): Promise<void> => {
+    await fetch(`${API_BASE}/trash/${id}`, { method: 'DELETE' });
+  },
+};
+
 // Consolidated API export
 export const api = {
   agents: agentsApi,
@@ -311,4 +347,5 @@ export const api = {
   status: statusApi,
   models: modelsApi,
   chat: chatApi,
+  trash: trashApi,
 };
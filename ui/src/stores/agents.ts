@@ -14,7 +14,7 @@ interface AgentsState {
   fetchAgent: (id: string) => Promise<void>;
   createAgent: (agent: Partial<Agent>) => Promise<Agent>;
   updateAgent: (id: string, updates: Partial<Agent>) => Promise<void>;
-  deleteAgent: (id: string) => Promise<void>;
+  deleteAgent: (id: string, tasks?: 'block' | 'backlog') => Promise<void>;
   setSelectedAgent: (agent: Agent | null) => void;
 }
 
@@ -71,8 +71,8 @@ export const useAgentsStore = create<AgentsState>((set, get) => ({
     }));
   },
 
-  deleteAgent: async (id: string) => {
-    await agentsApi.delete(id);
+  deleteAgent: async (id: string, tasks?: 'block' | 'backlog') => {
+    await agentsApi.delete(id, tasks);
     set((state) => ({
       agents: state.agents.filter((a) => a.id !== id),
       selectedAgent: state.selectedAgent?.id === id ? null : state.selectedAgent,
@@ -1,6 +1,6 @@
 import { create } from 'zustand';
 import { api } from '@/services/api';
-import type { Project } from '@/types';
+import type { Project, ProjectDeletion } from '@/types';
 
 interface ProjectsState {
   projects: Project[];
@@ -12,7 +12,7 @@ interface ProjectsState {
   fetchProject: (id: string) => Promise<void>;
   createProject: (project: Partial<Project>) => Promise<Project>;
   updateProject: (id: string, updates: Partial<Project>) => Promise<void>;
-  deleteProject: (id: string) => Promise<void>;
+  deleteProject: (id: string, tasks?: ProjectDeletion['tasks'], confirm?: string) => Promise<void>;
   setSelectedProject: (project: Project | null) => void;
 }
 
@@ -56,8 +56,8 @@ export const useProjectsStore = create<ProjectsState>((set, get) => ({
     }));
   },
   
-  deleteProject: async (id: string) => {
-    await api.projects.delete(id);
+  deleteProject: async (id, tasks, confirm) => {
+    await api.projects.delete(id, tasks, confirm);
     set((state) => ({
       projects: state.projects.filter((p) => p.id !== id),
       selectedProject: state.selectedProject?.id === id ? null : state.selectedProject,
@@ -63,6 +63,10 @@ export interface Task {
   completed_at?: string;
   scheduled_at?: string;
   retry_at?: string;
+  // The times above in the reader's or agent's zone, when one is known
+  timezone?: string;
+  scheduled_at_local?: string;
+  retry_at_local?: string;
   // Story progress for UI display
   stories_total?: number;
   stories_passed?: number;
@@ -130,6 +134,28 @@ export interface Project {
   updated_at: string;
 }
 
+// What deleting a project affects, as a dry run reports it
+export interface ProjectDeletion {
+  project_id: string;
+  tasks: 'orphan' | 'archive' | 'delete';
+  task_count: number;
+  open_tasks: number;
+  secrets: number;
+  experiments: number;
+  github_issues: number;
+  confirm_token?: string;
+}
+
+// A deleted task, comment or project, restorable until it expires
+export interface TrashItem {
+  id: string;
+  kind: 'task' | 'comment' | 'project';
+  item_id: string;
+  title: string;
+  deleted_at: string;
+  expires_at: string;
+}
+
 export interface Settings {
   id: string;
   openclaw_gateway_url: string;
@@ -165,6 +191,7 @@ export interface ApiError {
   error: {
     code: string;
     message: string;
+    fields?: { field: string; message: string }[];
     details?: Record<string, unknown>;
   };
 }